@@ -1,6 +1,7 @@
 package agentic
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/types"
+	"github.com/user/terminal-intelligence/internal/validation"
 )
 
 var projectNameRe = regexp.MustCompile(`(?im)project\s*name\s*[:\-]?\s*` + `\**` + "`?" + `([a-zA-Z0-9\-_]+)` + "`?" + `\**`)
@@ -49,6 +51,9 @@ type AutonomousCreator struct {
 	// Callbacks for UI interactions
 	OpenFileCallback func(filePath string) error
 
+	// Validation pipeline that compile-checks generated files (optional, nil = skip validation)
+	Validator *validation.Pipeline
+
 	// Running process (for web servers)
 	RunningProcess *exec.Cmd
 	ServerURL      string // URL of the running server
@@ -62,10 +67,15 @@ type AutonomousCreator struct {
 	fixer *AgenticProjectFixer
 	// Logger for fallback progress messages (optional, nil = skip logging)
 	logger *ActionLogger
+
+	// ctx is cancelled by Cancel to abort in-flight AI calls and fallback fixes
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewAutonomousCreator initializes a new creator flow.
 func NewAutonomousCreator(client ai.AIClient, model, workspace, desc string, fixer *AgenticProjectFixer, logger *ActionLogger) *AutonomousCreator {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &AutonomousCreator{
 		AIClient:    client,
 		Model:       model,
@@ -75,7 +85,26 @@ func NewAutonomousCreator(client ai.AIClient, model, workspace, desc string, fix
 		FilesToMake: make(map[string]string),
 		fixer:       fixer,
 		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Cancel stops the creator: any in-flight AI call returns the text streamed
+// so far and the next Step fails with an *ai.CancelledError.
+func (c *AutonomousCreator) Cancel() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// context returns the creator's cancellation context, falling back to
+// context.Background for creators built without NewAutonomousCreator.
+func (c *AutonomousCreator) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
 	}
+	return c.ctx
 }
 
 // extractFileFromError extracts the first Go compiler file reference from error
@@ -127,7 +156,7 @@ func (c *AutonomousCreator) fallbackFix(errorOutput, errorType, failedCmd string
 		}
 	}
 
-	result, err := c.fixer.ProcessFixCommand(request, statusCallback)
+	result, err := c.fixer.ProcessFixCommandContext(c.context(), request, statusCallback)
 	if err != nil {
 		if c.logger != nil {
 			c.logger.Log("Fallback fix cycle failed with error: %v", err)
@@ -150,6 +179,10 @@ func (c *AutonomousCreator) fallbackFix(errorOutput, errorType, failedCmd string
 
 // Emulate a state machine step
 func (c *AutonomousCreator) Step() (string, error) {
+	if err := c.context().Err(); err != nil {
+		return "", &ai.CancelledError{Cause: err}
+	}
+
 	switch c.State {
 	case StatePlanning:
 		return c.doPlanning()
@@ -387,6 +420,7 @@ Only return the file paths and code blocks. No other text.`, c.Plan)
 	if missingResult != "" {
 		createdFiles = c.getFileList()
 	}
+	c.queueValidation(createdFiles)
 
 	var resultMsg strings.Builder
 	resultMsg.WriteString(fmt.Sprintf("ai-assist %s\nGenerated and saved %d files:\n- %s\n", getCurrentTime(), len(createdFiles), strings.Join(createdFiles, "\n- ")))
@@ -468,6 +502,16 @@ func (c *AutonomousCreator) writeFilesToDisk() []string {
 	return createdFiles
 }
 
+// queueValidation hands files of the project, relative to ProjectDir, to
+// the validation pipeline.
+func (c *AutonomousCreator) queueValidation(relPaths []string) {
+	paths := make([]string, 0, len(relPaths))
+	for _, relPath := range relPaths {
+		paths = append(paths, filepath.Join(c.ProjectDir, relPath))
+	}
+	queueValidation(c.Validator, paths)
+}
+
 // getFileList returns a sorted list of all file paths in FilesToMake.
 func (c *AutonomousCreator) getFileList() []string {
 	list := make([]string, 0, len(c.FilesToMake))
@@ -802,36 +846,38 @@ func runScriptWithFallback(scriptPath, dir string) ([]byte, error, string) {
 }
 
 func aicall(client ai.AIClient, model, prompt string) (string, error) {
-	resp, usage, err := aicallWithTokens(client, model, prompt)
+	resp, usage, err := aicallWithTokens(context.Background(), client, model, prompt)
 	_ = usage
 	return resp, err
 }
 
 // aicallAndTrack calls the AI and accumulates token usage on the creator.
+// If the creator is cancelled mid-stream, the partial response is logged so
+// it isn't lost and the cancellation error is returned.
 func (c *AutonomousCreator) aicallAndTrack(prompt string) (string, error) {
-	resp, usage, err := aicallWithTokens(c.AIClient, c.Model, prompt)
+	resp, usage, err := aicallWithTokens(c.context(), c.AIClient, c.Model, prompt)
 	c.InputTokens += usage.InputTokens
 	c.OutputTokens += usage.OutputTokens
 	c.TotalTokens += usage.InputTokens + usage.OutputTokens
+	if ai.IsCancelled(err) && c.logger != nil && strings.TrimSpace(resp) != "" {
+		c.logger.Log("Partial AI response before stop:\n%s", resp)
+	}
 	return resp, err
 }
 
-func aicallWithTokens(client ai.AIClient, model, prompt string) (string, types.TokenUsage, error) {
+func aicallWithTokens(ctx context.Context, client ai.AIClient, model, prompt string) (string, types.TokenUsage, error) {
 	var tokenUsage types.TokenUsage
 	onTokenUsage := func(usage types.TokenUsage) {
 		tokenUsage = usage
 	}
 
-	ch, err := client.Generate(prompt, model, nil, onTokenUsage)
+	ch, err := ai.GenerateContext(ctx, client, prompt, model, onTokenUsage)
 	if err != nil {
 		return "", tokenUsage, err
 	}
 
-	var sb strings.Builder
-	for chunk := range ch {
-		sb.WriteString(chunk)
-	}
-	return sb.String(), tokenUsage, nil
+	resp, err := ai.Collect(ctx, ch)
+	return resp, tokenUsage, err
 }
 
 func extractProjectName(plan string) string {
@@ -1268,6 +1314,7 @@ Rules:
 					cleanContent := cleanAIResponse(content.String())
 					if err := os.WriteFile(absPath, []byte(cleanContent), 0644); err == nil {
 						c.FilesToMake[filePath] = cleanContent
+						c.queueValidation([]string{filePath})
 						fixesApplied++
 						result.WriteString(fmt.Sprintf("Fixed file: %s\n", filePath))
 					}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/user/terminal-intelligence/internal/ai"
 )
 
 func TestDetectProjectType(t *testing.T) {
@@ -531,3 +533,19 @@ func mkdirAll(path string) error {
 func writeFile(path, content string) error {
 	return os.WriteFile(path, []byte(content), 0644)
 }
+
+// TestAutonomousCreator_CancelStopsStep verifies that Step refuses to run after
+// Cancel and reports the cancellation as an ai.CancelledError.
+func TestAutonomousCreator_CancelStopsStep(t *testing.T) {
+	stubClient := &stubAIClient{response: "plan"}
+	creator := NewAutonomousCreator(stubClient, "model", t.TempDir(), "desc", nil, nil)
+	creator.Cancel()
+
+	_, err := creator.Step()
+	if !ai.IsCancelled(err) {
+		t.Fatalf("Step() after Cancel() error = %v, want cancellation", err)
+	}
+	if creator.State != StatePlanning {
+		t.Errorf("State = %v, want StatePlanning (unchanged)", creator.State)
+	}
+}
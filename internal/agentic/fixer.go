@@ -1,10 +1,12 @@
 package agentic
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
+	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/types"
 )
 
@@ -419,6 +421,18 @@ func (f *AgenticCodeFixer) ProcessMessage(
 	fileContent string,
 	filePath string,
 	fileType string,
+) (*FixResult, error) {
+	return f.ProcessMessageContext(context.Background(), message, fileContent, filePath, fileType)
+}
+
+// ProcessMessageContext is ProcessMessage with cancellation. When ctx is done
+// the in-flight AI call is stopped and an ai.CancelledError is returned.
+func (f *AgenticCodeFixer) ProcessMessageContext(
+	ctx context.Context,
+	message string,
+	fileContent string,
+	filePath string,
+	fileType string,
 ) (*FixResult, error) {
 	f.logInfo("Processing message for file: %s (type: %s)", filePath, fileType)
 	f.logDebug("File content length: %d bytes", len(fileContent))
@@ -508,7 +522,7 @@ func (f *AgenticCodeFixer) ProcessMessage(
 	f.logInfo("Edit intent classified: %s (confidence: %.2f)", intent.OperationType, intent.Confidence)
 
 	// Step 7: Route to fix generation handler
-	return f.GenerateFix(request, intent)
+	return f.GenerateFixContext(ctx, request, intent)
 }
 
 // GenerateFix generates a code fix using the AI model
@@ -524,6 +538,12 @@ func (f *AgenticCodeFixer) ProcessMessage(
 //
 // Requirements: 2.1, 3.1, 3.2, 3.4
 func (f *AgenticCodeFixer) GenerateFix(request *FixRequest, intent EditIntent) (*FixResult, error) {
+	return f.GenerateFixContext(context.Background(), request, intent)
+}
+
+// GenerateFixContext is GenerateFix with cancellation. When ctx is done the
+// AI call is stopped and an ai.CancelledError is returned.
+func (f *AgenticCodeFixer) GenerateFixContext(ctx context.Context, request *FixRequest, intent EditIntent) (*FixResult, error) {
 	f.logInfo("Generating fix for file: %s", request.FilePath)
 
 	// Step 1: Check if AI service is available (Requirement 7.2)
@@ -552,7 +572,10 @@ func (f *AgenticCodeFixer) GenerateFix(request *FixRequest, intent EditIntent) (
 	onTokenUsage := func(usage types.TokenUsage) {
 		tokenUsage = usage
 	}
-	responseChan, err := f.aiClient.Generate(prompt, f.model, nil, onTokenUsage)
+	responseChan, err := ai.GenerateContext(ctx, f.aiClient, prompt, f.model, onTokenUsage)
+	if ai.IsCancelled(err) {
+		return nil, err
+	}
 	if err != nil {
 		f.logError("Failed to generate fix: %v", err)
 		return &FixResult{
@@ -566,12 +589,11 @@ func (f *AgenticCodeFixer) GenerateFix(request *FixRequest, intent EditIntent) (
 
 	// Step 4: Collect the streaming response
 	f.logDebug("Collecting streaming response from AI")
-	var fullResponse strings.Builder
-	for chunk := range responseChan {
-		fullResponse.WriteString(chunk)
+	responseText, err := ai.Collect(ctx, responseChan)
+	if err != nil {
+		f.logInfo("Fix generation stopped: %v", err)
+		return nil, err
 	}
-
-	responseText := fullResponse.String()
 	f.logDebug("AI response received (length: %d chars)", len(responseText))
 
 	// Check if we got a response
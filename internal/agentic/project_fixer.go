@@ -2,6 +2,7 @@ package agentic
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	iofs "io/fs"
@@ -10,8 +11,8 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/user/terminal-intelligence/internal/executor"
 	"github.com/user/terminal-intelligence/internal/types"
+	"github.com/user/terminal-intelligence/internal/validation"
 )
 
 // skipDirs is the set of directory names that fileScanner will never descend into.
@@ -431,14 +432,15 @@ func parseRankedPaths(response string) []string {
 
 // ─── MultiFileEditor ──────────────────────────────────────────────────────────
 
-// multiFileEditor reads multiple files, assembles a consolidated AI prompt,
-// parses the AI response into per-file patches, validates and applies them.
+// multiFileEditor reads multiple files, assembles a consolidated AI prompt and
+// lets the model edit them through the agent tools.
 type multiFileEditor struct {
 	aiClient  AIClient
 	model     string
 	fixParser *FixParser
 	root      string // absolute project root
 	preview   bool
+	logger    *ActionLogger // optional; receives every tool invocation
 }
 
 // newMultiFileEditor creates a multiFileEditor with the given dependencies.
@@ -452,25 +454,22 @@ func newMultiFileEditor(aiClient AIClient, model string, fixParser *FixParser, r
 	}
 }
 
-// edit reads each file (up to 2000 lines), builds a consolidated prompt, calls
-// the AI, splits the response on "=== FILE:" boundaries, validates and applies
-// patches, and returns per-file results alongside any execution command.
+// edit reads each file (up to 2000 lines), builds a consolidated prompt and
+// runs the tool-using agent on it. The model patches files, runs commands and
+// checks its work through the tools; in preview mode nothing is written or run.
 //
-// Returns: (modified []FileResult, failures []PatchFailure, unreadable []string, outOfScope []string, execCmd string, error)
+// Returns: (modified []FileResult, failures []PatchFailure, unreadable []string, outOfScope []string, error)
 func (me *multiFileEditor) edit(
 	paths []string,
 	request string,
-) ([]FileResult, []PatchFailure, []string, []string, string, error) {
-	var modified []FileResult
-	var failures []PatchFailure
+) ([]FileResult, []PatchFailure, []string, []string, error) {
 	var unreadable []string
 	var outOfScope []string
-	var execCmd string
 
 	// ── Step 1: Resolve root to absolute path ────────────────────────────────
 	absRoot, err := filepath.Abs(me.root)
 	if err != nil {
-		return nil, nil, nil, nil, "", fmt.Errorf("cannot resolve project root %q: %w", me.root, err)
+		return nil, nil, nil, nil, fmt.Errorf("cannot resolve project root %q: %w", me.root, err)
 	}
 	// Ensure root ends with separator for unambiguous prefix checks.
 	rootPrefix := absRoot
@@ -483,11 +482,9 @@ func (me *multiFileEditor) edit(
 
 	for _, p := range paths {
 		// 4.6 Path safety check.
-		safe, absP, reason := me.checkPathSafety(p, absRoot, rootPrefix)
+		safe, absP, _ := me.checkPathSafety(p, absRoot, rootPrefix)
 		if !safe {
 			outOfScope = append(outOfScope, p)
-			_ = reason
-			_ = absP
 			continue
 		}
 
@@ -519,176 +516,23 @@ func (me *multiFileEditor) edit(
 
 	if len(entries) == 0 {
 		// Nothing to edit — all paths were out-of-scope or unreadable.
-		return modified, failures, unreadable, outOfScope, "", nil
+		return nil, nil, unreadable, outOfScope, nil
 	}
 
 	// ── Step 3: Build consolidated prompt ────────────────────────────────────
 	prompt := me.buildEditPrompt(entries, request)
 
-	// ── Step 4: Call AI ───────────────────────────────────────────────────────
-	var tokenUsage types.TokenUsage
-	onTokenUsage := func(usage types.TokenUsage) {
-		tokenUsage = usage
-	}
-	responseChan, err := me.aiClient.Generate(prompt, me.model, nil, onTokenUsage)
+	// ── Step 4: Run the agent ─────────────────────────────────────────────────
+	tools, err := newAgentToolbox(absRoot, me.preview, me.logger)
 	if err != nil {
-		return nil, nil, unreadable, outOfScope, "", fmt.Errorf("AI generate call failed: %w", err)
+		return nil, nil, unreadable, outOfScope, err
 	}
-	var sb strings.Builder
-	for chunk := range responseChan {
-		sb.WriteString(chunk)
-	}
-	aiResponse := sb.String()
-	_ = tokenUsage // token usage available for future aggregation
-
-	execCmd = extractExecuteCommand(aiResponse)
-
-	// ── Step 5: Split response on "=== FILE:" boundaries ─────────────────────
-	fileSections := splitOnFileHeaders(aiResponse)
-
-	// Build a lookup from relPath → entry for fast matching.
-	entryByRel := make(map[string]mfeFileEntry, len(entries))
-	for _, e := range entries {
-		entryByRel[e.relPath] = e
-		// Also index by absolute path in case AI returns absolute paths.
-		entryByRel[e.absPath] = e
+	agent := newToolAgent(me.aiClient, me.model, tools)
+	if _, err := agent.run(context.Background(), prompt); err != nil {
+		return nil, nil, unreadable, outOfScope, fmt.Errorf("AI generate call failed: %w", err)
 	}
 
-	// ── Step 6: Per-file: validate + apply patches ────────────────────────────
-	for filePath, patchText := range fileSections {
-		// Normalise the path the AI returned.
-		filePath = strings.TrimSpace(filePath)
-
-		// Parse patches from the section text.
-		patches := parseSearchReplace(patchText)
-		if len(patches) == 0 {
-			// No patches found for this file — not an error, just nothing to do.
-			continue
-		}
-
-		// Try to find the matching entry.
-		entry, ok := entryByRel[filePath]
-		if !ok {
-			// Try joining with root in case AI returned a relative path.
-			candidate := filepath.Join(absRoot, filePath)
-			absCandidate, absErr := filepath.Abs(candidate)
-			if absErr == nil {
-				relCandidate, _ := filepath.Rel(absRoot, absCandidate)
-				entry, ok = entryByRel[relCandidate]
-				if !ok {
-					entry, ok = entryByRel[absCandidate]
-				}
-			}
-
-			// If not found, check if it's a new file
-			if !ok {
-				hasNewFile := false
-				for _, p := range patches {
-					if p.isNewFile {
-						hasNewFile = true
-						break
-					}
-				}
-				if hasNewFile {
-					safe, absP, _ := me.checkPathSafety(filePath, absRoot, rootPrefix)
-					if safe {
-						relCandidate, _ := filepath.Rel(absRoot, absP)
-						entry = mfeFileEntry{
-							absPath:  absP,
-							relPath:  relCandidate,
-							content:  "",
-							origPerm: 0644,
-						}
-						ok = true
-					}
-				}
-			}
-		}
-		if !ok {
-			// AI hallucinated a path not in our entry set — skip silently.
-			continue
-		}
-
-		// 4.5 Duplicate ~~~SEARCH marker detection.
-		if hasDuplicateSearchMarkers(patchText) {
-			failures = append(failures, PatchFailure{
-				Path:   entry.relPath,
-				Reason: "duplicate ~~~SEARCH markers detected in patch",
-			})
-			continue
-		}
-
-		// Apply patches sequentially to the file content.
-		currentContent := entry.content
-		// Strip any truncation notice before applying patches.
-		currentContent = strings.TrimSuffix(currentContent, "\n[TRUNCATED: file exceeds 2000 lines; only the first 2000 lines are shown]")
-
-		applyFailed := false
-		for _, patch := range patches {
-			var newContent string
-			var applyErr error
-			if patch.isNewFile {
-				newContent = patch.replace
-				if !strings.HasSuffix(newContent, "\n") {
-					newContent += "\n"
-				}
-			} else {
-				newContent, applyErr = applySearchReplace(currentContent, patch.search, patch.replace)
-			}
-			if applyErr != nil {
-				failures = append(failures, PatchFailure{
-					Path:   entry.relPath,
-					Reason: fmt.Sprintf("patch apply failed: %s", applyErr.Error()),
-				})
-				applyFailed = true
-				break
-			}
-			currentContent = newContent
-		}
-		if applyFailed {
-			continue
-		}
-
-		// Count lines added/removed.
-		origLines := strings.Split(entry.content, "\n")
-		newLines := strings.Split(currentContent, "\n")
-		linesAdded := 0
-		linesRemoved := 0
-		if len(newLines) > len(origLines) {
-			linesAdded = len(newLines) - len(origLines)
-		} else {
-			linesRemoved = len(origLines) - len(newLines)
-		}
-
-		// 4.7 / 4.8 Write file (or skip in preview mode).
-		if !me.preview {
-			if err := os.MkdirAll(filepath.Dir(entry.absPath), 0755); err != nil {
-				failures = append(failures, PatchFailure{
-					Path:   entry.relPath,
-					Reason: fmt.Sprintf("mkdir failed: %s", err.Error()),
-				})
-				continue
-			}
-
-			writeErr := os.WriteFile(entry.absPath, []byte(currentContent), entry.origPerm)
-			if writeErr != nil {
-				failures = append(failures, PatchFailure{
-					Path:   entry.relPath,
-					Reason: fmt.Sprintf("write failed: %s", writeErr.Error()),
-				})
-				continue
-			}
-		}
-
-		modified = append(modified, FileResult{
-			Path:         entry.absPath,
-			RelPath:      entry.relPath,
-			LinesAdded:   linesAdded,
-			LinesRemoved: linesRemoved,
-		})
-	}
-
-	return modified, failures, unreadable, outOfScope, execCmd, nil
+	return tools.modifiedFiles(), tools.failures, unreadable, outOfScope, nil
 }
 
 // mfeFileEntry holds the data for a single file being edited.
@@ -703,29 +547,12 @@ type mfeFileEntry struct {
 func (me *multiFileEditor) buildEditPrompt(entries []mfeFileEntry, request string) string {
 	var sb strings.Builder
 
-	sb.WriteString("You are a code assistant. Apply the following changes to the files listed below.\n\n")
+	sb.WriteString("You are a code assistant. Apply the following changes to the project.\n\n")
 	sb.WriteString("User request: ")
 	sb.WriteString(request)
 	sb.WriteString("\n\n")
-	sb.WriteString("For each file that needs modification, output a section in this exact format:\n\n")
-	sb.WriteString("=== FILE: <relative/path/to/file> ===\n")
-	sb.WriteString("~~~SEARCH\n")
-	sb.WriteString("<exact lines to find>\n")
-	sb.WriteString("~~~REPLACE\n")
-	sb.WriteString("<replacement lines>\n")
-	sb.WriteString("~~~END\n\n")
-	sb.WriteString("To create a completely new file, use this format instead:\n")
-	sb.WriteString("=== FILE: <relative/path/to/new_file> ===\n")
-	sb.WriteString("~~~NEWFILE\n")
-	sb.WriteString("<entire file content>\n")
-	sb.WriteString("~~~END\n\n")
-	sb.WriteString("If you want to run a command to verify your changes (e.g., go test, python tests), output it OUTSIDE of any FILE blocks as:\n")
-	sb.WriteString("~~~EXECUTE\n")
-	sb.WriteString("<command>\n")
-	sb.WriteString("~~~END\n\n")
-	sb.WriteString("You may include multiple SEARCH/REPLACE blocks per file. ")
-	sb.WriteString("Only output sections for files that need changes or creation. ")
-	sb.WriteString("Do not output anything outside the === FILE: === sections, EXCEPT for an optional ~~~EXECUTE block at the end.\n\n")
+	writeToolInstructions(&sb, me.preview)
+	sb.WriteString("\nThe files most relevant to the request are shown below.\n\n")
 	sb.WriteString("=== FILES TO CONSIDER ===\n\n")
 
 	for _, e := range entries {
@@ -739,8 +566,7 @@ func (me *multiFileEditor) buildEditPrompt(entries []mfeFileEntry, request strin
 		sb.WriteString("\n")
 	}
 
-	sb.WriteString("=== END OF FILES ===\n\n")
-	sb.WriteString("Now output the modifications using the === FILE: === / ~~~SEARCH / ~~~REPLACE / ~~~END or ~~~NEWFILE format described above.\n")
+	sb.WriteString("=== END OF FILES ===\n")
 
 	return sb.String()
 }
@@ -748,6 +574,12 @@ func (me *multiFileEditor) buildEditPrompt(entries []mfeFileEntry, request strin
 // checkPathSafety resolves a path to absolute form, resolves symlinks, and
 // verifies it is under the project root. Returns (safe, absPath, reason).
 func (me *multiFileEditor) checkPathSafety(p, absRoot, rootPrefix string) (bool, string, string) {
+	return checkPathSafety(p, absRoot, rootPrefix)
+}
+
+// checkPathSafety is the implementation of multiFileEditor.checkPathSafety,
+// shared with the agent toolbox.
+func checkPathSafety(p, absRoot, rootPrefix string) (bool, string, string) {
 	// Make absolute.
 	absP, err := filepath.Abs(p)
 	if err != nil {
@@ -964,7 +796,8 @@ type ProjectFixer struct {
 	aiClient  AIClient
 	model     string
 	fixParser *FixParser
-	executor  *executor.CommandExecutor
+	logger    *ActionLogger
+	validator *validation.Pipeline
 }
 
 // NewProjectFixer creates a new ProjectFixer with the given AI client and model.
@@ -973,10 +806,20 @@ func NewProjectFixer(aiClient AIClient, model string) *ProjectFixer {
 		aiClient:  aiClient,
 		model:     model,
 		fixParser: NewFixParser(),
-		executor:  executor.NewCommandExecutor(),
 	}
 }
 
+// SetLogger sets the ActionLogger that receives the agent's tool invocations.
+func (pf *ProjectFixer) SetLogger(logger *ActionLogger) {
+	pf.logger = logger
+}
+
+// SetValidator sets the validation pipeline that compile-checks the files
+// written by /project edits. A nil pipeline disables validation.
+func (pf *ProjectFixer) SetValidator(pipeline *validation.Pipeline) {
+	pf.validator = pipeline
+}
+
 // ProcessProjectMessage is the single entry point called by AIChatPane.
 // It parses the /preview and /project prefixes, validates inputs, and runs the
 // scan → rank → edit pipeline, returning a ChangeReport.
@@ -1049,44 +892,15 @@ func (pf *ProjectFixer) ProcessProjectMessage(
 		return report, nil
 	}
 
-	// ── Step 5: Edit and Execute Loop (Req 1.4) ────────────────────────────────────────────────
-	var modified []FileResult
-	var failures []PatchFailure
-	var unreadable []string
-	var outOfScope []string
+	// ── Step 5: Edit (Req 1.4) ───────────────────────────────────────────────
+	// Verification commands are run by the model through the agent tools.
+	callStatus(statusUpdate, "modifying")
+	editor := newMultiFileEditor(pf.aiClient, pf.model, pf.fixParser, projectRoot, previewMode)
+	editor.logger = pf.logger
 
-	maxIterations := 3
-	for i := 0; i < maxIterations; i++ {
-		callStatus(statusUpdate, fmt.Sprintf("modifying (iteration %d/%d)", i+1, maxIterations))
-		editor := newMultiFileEditor(pf.aiClient, pf.model, pf.fixParser, projectRoot, previewMode)
-
-		mod, fail, unread, outScope, execCmd, editErr := editor.edit(ranked, requestText)
-		if editErr != nil {
-			return nil, fmt.Errorf("multi-file edit failed: %w", editErr)
-		}
-
-		// Accumulate results
-		modified = append(modified, mod...)
-		failures = append(failures, fail...)
-		unreadable = append(unreadable, unread...)
-		outOfScope = append(outOfScope, outScope...)
-
-		// Execute if requested
-		if execCmd != "" && !previewMode {
-			callStatus(statusUpdate, fmt.Sprintf("executing verification: %s", execCmd))
-			cmdResult, _ := pf.executor.ExecuteCommand(execCmd, projectRoot)
-
-			if cmdResult != nil && cmdResult.ExitCode != 0 {
-				// Execution failed, feedback to AI
-				errorFeedback := fmt.Sprintf("\n\nI ran your verification command `%s` but it failed with exit code %d.\nStdout:\n%s\nStderr:\n%s\nPlease fix the issue and try again.",
-					execCmd, cmdResult.ExitCode, cmdResult.Stdout, cmdResult.Stderr)
-				requestText += errorFeedback
-				continue // loop to try again
-			}
-		}
-
-		// If no execution command or execution succeeded, we are done
-		break
+	modified, failures, unreadable, outOfScope, editErr := editor.edit(ranked, requestText)
+	if editErr != nil {
+		return nil, fmt.Errorf("multi-file edit failed: %w", editErr)
 	}
 
 	report.FilesModified = modified
@@ -1094,6 +908,10 @@ func (pf *ProjectFixer) ProcessProjectMessage(
 	report.FilesUnreadable = unreadable
 	report.OutOfScopePaths = outOfScope
 
+	if !previewMode {
+		queueValidation(pf.validator, modifiedPaths(modified))
+	}
+
 	return report, nil
 }
 
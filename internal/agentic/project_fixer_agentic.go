@@ -1,14 +1,16 @@
 package agentic
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/executor"
-	"github.com/user/terminal-intelligence/internal/types"
+	"github.com/user/terminal-intelligence/internal/validation"
 )
 
 // AgenticProjectFixer orchestrates the project-wide agentic fixing workflow.
@@ -25,6 +27,7 @@ type AgenticProjectFixer struct {
 	testRunner       *TestRunner
 	langRegistry     map[string]LanguageConfig
 	intentClassifier *IntentClassifier
+	validator        *validation.Pipeline
 }
 
 // NewAgenticProjectFixer creates a new AgenticProjectFixer with all internal
@@ -50,10 +53,17 @@ func NewAgenticProjectFixer(aiClient AIClient, model string, logger *ActionLogge
 	}
 }
 
+// SetValidator sets the validation pipeline that compile-checks the files
+// left modified by a successful /fix session. A nil pipeline disables
+// validation.
+func (apf *AgenticProjectFixer) SetValidator(pipeline *validation.Pipeline) {
+	apf.validator = pipeline
+}
+
 // buildAgenticPrompt composes the AI prompt for a fix attempt.
-// It includes system instructions, the original ask, file contents (up to 2000
-// lines per file), prior attempt summaries, current test failures, an
-// instruction to try a different strategy, and the expected output format.
+// It includes system instructions, the original ask, language server
+// diagnostics, file contents (up to 2000 lines per file), prior attempt summaries, current test failures, an
+// instruction to try a different strategy, and how to use the agent tools.
 func (apf *AgenticProjectFixer) buildAgenticPrompt(
 	session *FixSession,
 	rankedFiles []string,
@@ -62,13 +72,21 @@ func (apf *AgenticProjectFixer) buildAgenticPrompt(
 	var sb strings.Builder
 
 	// 1. System instructions
-	sb.WriteString("You are an agentic code fixer. Your job is to analyze code issues and generate SEARCH/REPLACE patches to fix them.\n\n")
+	sb.WriteString("You are an agentic code fixer. Your job is to analyze code issues and fix them using the tools provided.\n\n")
 
 	// 2. Original ask (always included, never changes)
 	sb.WriteString("=== ORIGINAL ASK ===\n")
 	sb.WriteString(session.OriginalAsk)
 	sb.WriteString("\n\n")
 
+	// 2b. Language server diagnostics from when the session started
+	if strings.TrimSpace(session.Diagnostics) != "" {
+		sb.WriteString("=== LANGUAGE SERVER DIAGNOSTICS ===\n")
+		sb.WriteString("Reported by the editor's language servers before the first attempt:\n")
+		sb.WriteString(strings.TrimRight(session.Diagnostics, "\n"))
+		sb.WriteString("\n\n")
+	}
+
 	// 3. File contents: read up to 2000 lines per ranked file
 	if len(rankedFiles) > 0 {
 		sb.WriteString("=== FILES ===\n\n")
@@ -125,21 +143,8 @@ func (apf *AgenticProjectFixer) buildAgenticPrompt(
 	// 6. Instruction to try different strategy
 	sb.WriteString("Try a DIFFERENT approach than any previously attempted strategies.\n\n")
 
-	// 7. Output format instructions (SEARCH/REPLACE format)
-	sb.WriteString("For each file that needs modification, output a section in this exact format:\n\n")
-	sb.WriteString("=== FILE: <relative/path/to/file> ===\n")
-	sb.WriteString("~~~SEARCH\n")
-	sb.WriteString("<exact lines to find>\n")
-	sb.WriteString("~~~REPLACE\n")
-	sb.WriteString("<replacement lines>\n")
-	sb.WriteString("~~~END\n\n")
-	sb.WriteString("To create a completely new file, use this format instead:\n")
-	sb.WriteString("=== FILE: <relative/path/to/new_file> ===\n")
-	sb.WriteString("~~~NEWFILE\n")
-	sb.WriteString("<entire file content>\n")
-	sb.WriteString("~~~END\n\n")
-	sb.WriteString("You may include multiple SEARCH/REPLACE blocks per file. ")
-	sb.WriteString("Only output sections for files that need changes or creation.\n")
+	// 7. Tool instructions
+	writeToolInstructions(&sb, false)
 
 	return sb.String()
 }
@@ -153,6 +158,18 @@ func (apf *AgenticProjectFixer) buildAgenticPrompt(
 func (apf *AgenticProjectFixer) ProcessFixCommand(
 	request *FixSessionRequest,
 	statusUpdate func(phase string),
+) (*FixSessionResult, error) {
+	return apf.ProcessFixCommandContext(context.Background(), request, statusUpdate)
+}
+
+// ProcessFixCommandContext is ProcessFixCommand with cancellation. When ctx is
+// done the in-flight AI call is stopped, its partial response is recorded on
+// the final attempt, modified files are restored and the result is returned
+// with Cancelled set.
+func (apf *AgenticProjectFixer) ProcessFixCommandContext(
+	ctx context.Context,
+	request *FixSessionRequest,
+	statusUpdate func(phase string),
 ) (*FixSessionResult, error) {
 	// ── Step 1: Set defaults ─────────────────────────────────────────────────
 	if request.MaxAttempts <= 0 {
@@ -170,6 +187,7 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 	// ── Step 3: Create session ───────────────────────────────────────────────
 	session := &FixSession{
 		OriginalAsk:    request.Message,
+		Diagnostics:    request.Diagnostics,
 		StartTime:      time.Now(),
 		Attempts:       []FixAttempt{},
 		Snapshots:      make(map[string][]byte),
@@ -278,6 +296,11 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 	var sessionInputTokens, sessionOutputTokens int
 
 	for attempt := 1; attempt <= request.MaxAttempts; attempt++ {
+		// (a0) Stop before starting another attempt if the user cancelled.
+		if ctx.Err() != nil {
+			return apf.cancelledResult(session, sessionInputTokens, sessionOutputTokens), nil
+		}
+
 		// (a) Check AI availability (Req 10.4, 10.5).
 		available, aiErr := apf.aiClient.IsAvailable()
 		if aiErr != nil || !available {
@@ -302,27 +325,49 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 		// (c) Build prompt.
 		prompt := apf.buildAgenticPrompt(session, ranked, lastTestResult)
 
-		// (c2) For append intent, add instruction to generate SEARCH/REPLACE patches
-		// that append content rather than replace entire files (Req 5.2).
+		// (c2) For append intent, add instruction to generate patches that
+		// append content rather than replace entire files (Req 5.2).
 		if intent.OperationType == "append" {
 			prompt += "\nIMPORTANT: The user wants to ADD content to existing files, not replace them. " +
-				"Generate SEARCH/REPLACE patches that append new content after existing content. " +
+				"Use apply_patch edits that append new content after existing content. " +
 				"Do NOT remove or replace existing code — only add new code.\n"
 		}
 
-		// (d) Call AI to generate response.
-		var attemptTokens types.TokenUsage
-		onTokenUsage := func(usage types.TokenUsage) {
-			attemptTokens = usage
+		// (d) Run the tool-using agent; it reads, patches and verifies files
+		// through the agent tools.
+		tools, toolsErr := newAgentToolbox(request.ProjectRoot, false, apf.logger)
+		if toolsErr != nil {
+			return nil, toolsErr
+		}
+		tools.snapshots = apf.snapshots
+		agent := newToolAgent(apf.aiClient, apf.model, tools)
+		run, genErr := agent.run(ctx, prompt)
+
+		// Accumulate token usage from this attempt.
+		sessionInputTokens += run.Usage.InputTokens
+		sessionOutputTokens += run.Usage.OutputTokens
+		aiResponse := run.Transcript
+
+		// (d2) Cancelled mid-turn: keep the partial response for the record.
+		if ai.IsCancelled(genErr) {
+			apf.logger.Log("Attempt %d: generation stopped by user", attempt)
+			fa := FixAttempt{
+				Number:    attempt,
+				Cycle:     session.CurrentCycle,
+				Strategy:  Strategy{Description: "Generation stopped by user", Prompt: prompt, AIResponse: aiResponse},
+				Timestamp: time.Now(),
+			}
+			session.Attempts = append(session.Attempts, fa)
+			apf.tracker.Record(fa)
+			return apf.cancelledResult(session, sessionInputTokens, sessionOutputTokens), nil
 		}
-		responseChan, genErr := apf.aiClient.Generate(prompt, apf.model, nil, onTokenUsage)
 		if genErr != nil {
 			apf.logger.Log("AI generation failed: %s", genErr.Error())
 			// Record failed attempt and continue.
 			fa := FixAttempt{
 				Number:    attempt,
 				Cycle:     session.CurrentCycle,
-				Strategy:  Strategy{Description: "AI generation failed", Prompt: prompt, AIResponse: ""},
+				Strategy:  Strategy{Description: "AI generation failed", Prompt: prompt, AIResponse: aiResponse},
 				Timestamp: time.Now(),
 			}
 			session.Attempts = append(session.Attempts, fa)
@@ -335,18 +380,8 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 			continue
 		}
 
-		var sb strings.Builder
-		for chunk := range responseChan {
-			sb.WriteString(chunk)
-		}
-		aiResponse := sb.String()
-
-		// Accumulate token usage from this attempt.
-		sessionInputTokens += attemptTokens.InputTokens
-		sessionOutputTokens += attemptTokens.OutputTokens
-
 		// (e) Handle empty AI response.
-		if strings.TrimSpace(aiResponse) == "" {
+		if strings.TrimSpace(aiResponse) == "" && run.ToolCalls == 0 {
 			apf.logger.Log("AI returned empty response")
 			fa := FixAttempt{
 				Number:    attempt,
@@ -364,120 +399,22 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 			continue
 		}
 
-		// (f) Parse response into file sections.
-		fileSections := splitOnFileHeaders(aiResponse)
-		apf.logger.Log("Attempt %d: AI proposed changes to %d file(s)", attempt, len(fileSections))
-
-		// Log a brief summary of what the AI is trying to do (first 200 chars of response).
-		responseSummary := strings.TrimSpace(aiResponse)
+		// (f) Log a brief summary of what the AI did (first 200 chars of its answer).
+		responseSummary := strings.TrimSpace(run.Final)
+		if responseSummary == "" {
+			responseSummary = strings.TrimSpace(aiResponse)
+		}
 		if len(responseSummary) > 200 {
 			responseSummary = responseSummary[:200] + "..."
 		}
-		apf.logger.Log("Attempt %d: AI strategy summary: %s", attempt, responseSummary)
-
-		// Resolve project root for path operations.
-		absRoot, _ := filepath.Abs(request.ProjectRoot)
-		rootPrefix := absRoot
-		if !strings.HasSuffix(rootPrefix, string(filepath.Separator)) {
-			rootPrefix += string(filepath.Separator)
-		}
-
-		// (g) Apply patches per file.
-		var modified []FileResult
-		var patchesApplied []searchReplacePatch
-		var failures []PatchFailure
-
-		for filePath, patchText := range fileSections {
-			filePath = strings.TrimSpace(filePath)
-			patches := parseSearchReplace(patchText)
-			if len(patches) == 0 {
-				apf.logger.Log("Attempt %d: no valid patches found for file: %s", attempt, filePath)
-				continue
-			}
-			apf.logger.Log("Attempt %d: applying %d patch(es) to %s", attempt, len(patches), filePath)
-
-			// Resolve the file path.
-			absP := filePath
-			if !filepath.IsAbs(absP) {
-				absP = filepath.Join(absRoot, absP)
-			}
-			absP, _ = filepath.Abs(absP)
-
-			// Validate path is within project root (Req 2.5).
-			if !strings.HasPrefix(absP+string(filepath.Separator), rootPrefix) &&
-				absP != strings.TrimSuffix(rootPrefix, string(filepath.Separator)) {
-				continue
-			}
-
-			// Read current file content.
-			content, _, readErr := readUpToNLines(absP, 2000)
-			if readErr != nil {
-				apf.logger.Log("Attempt %d: could not read file %s: %s", attempt, filePath, readErr.Error())
-				failures = append(failures, PatchFailure{Path: filePath, Reason: readErr.Error()})
-				continue
-			}
-
-			// Capture snapshot if not already captured.
-			if !apf.snapshots.HasSnapshot(absP) {
-				_ = apf.snapshots.Capture([]string{absP})
-			}
-
-			// Apply patches sequentially.
-			currentContent := content
-			applyFailed := false
-			for _, patch := range patches {
-				var newContent string
-				var applyErr error
-				if patch.isNewFile {
-					newContent = patch.replace
-					if !strings.HasSuffix(newContent, "\n") {
-						newContent += "\n"
-					}
-				} else {
-					newContent, applyErr = applySearchReplace(currentContent, patch.search, patch.replace)
-				}
-				if applyErr != nil {
-					apf.logger.Log("Attempt %d: patch failed on %s: %s", attempt, filePath, applyErr.Error())
-					failures = append(failures, PatchFailure{Path: filePath, Reason: applyErr.Error()})
-					applyFailed = true
-					break
-				}
-				currentContent = newContent
-				patchesApplied = append(patchesApplied, patch)
-			}
-			if applyFailed {
-				continue
-			}
-
-			// Write modified content to disk.
-			if err := os.MkdirAll(filepath.Dir(absP), 0755); err != nil {
-				failures = append(failures, PatchFailure{Path: filePath, Reason: err.Error()})
-				continue
-			}
-			if err := os.WriteFile(absP, []byte(currentContent), 0644); err != nil {
-				failures = append(failures, PatchFailure{Path: filePath, Reason: err.Error()})
-				continue
-			}
-
-			relPath, _ := filepath.Rel(absRoot, absP)
-			origLines := strings.Split(content, "\n")
-			newLines := strings.Split(currentContent, "\n")
-			linesAdded := 0
-			linesRemoved := 0
-			if len(newLines) > len(origLines) {
-				linesAdded = len(newLines) - len(origLines)
-			} else {
-				linesRemoved = len(origLines) - len(newLines)
-			}
-
-			fr := FileResult{
-				Path:         absP,
-				RelPath:      relPath,
-				LinesAdded:   linesAdded,
-				LinesRemoved: linesRemoved,
-			}
-			modified = append(modified, fr)
-			apf.logger.Log("Modified file: %s (+%d -%d)", relPath, linesAdded, linesRemoved)
+		apf.logger.Log("Attempt %d: %d tool call(s); AI strategy summary: %s", attempt, run.ToolCalls, responseSummary)
+
+		// (g) Collect the files the agent modified.
+		modified := tools.modifiedFiles()
+		patchesApplied := tools.patches
+		failures := tools.failures
+		for _, fr := range modified {
+			apf.logger.Log("Modified file: %s (+%d -%d)", fr.RelPath, fr.LinesAdded, fr.LinesRemoved)
 		}
 
 		lastModified = modified
@@ -591,6 +528,7 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 			FilesRead:     ranked,
 			FilesModified: lastModified,
 		}
+		queueValidation(apf.validator, modifiedPaths(lastModified))
 	} else {
 		// Restore files on failure (Req 8.2).
 		failed := apf.snapshots.Restore()
@@ -606,6 +544,28 @@ func (apf *AgenticProjectFixer) ProcessFixCommand(
 	return result, nil
 }
 
+// cancelledResult restores all snapshotted files and builds the result for a
+// session stopped by the user.
+func (apf *AgenticProjectFixer) cancelledResult(session *FixSession, inputTokens, outputTokens int) *FixSessionResult {
+	failed := apf.snapshots.Restore()
+	for _, f := range failed {
+		apf.logger.Log("Failed to restore file: %s", f)
+	}
+	apf.logger.Log("Fix session cancelled by user after %d attempts", len(session.Attempts))
+
+	return &FixSessionResult{
+		Success:       false,
+		Cancelled:     true,
+		TotalAttempts: len(session.Attempts),
+		TotalCycles:   session.CurrentCycle + 1,
+		Attempts:      session.Attempts,
+		ErrorMessage:  "fix session cancelled by user",
+		InputTokens:   inputTokens,
+		OutputTokens:  outputTokens,
+		TotalTokens:   inputTokens + outputTokens,
+	}
+}
+
 // handleResetCycle performs a reset cycle: restores snapshots, increments cycle,
 // resets attempt-in-cycle counter, and logs the event.
 func (apf *AgenticProjectFixer) handleResetCycle(
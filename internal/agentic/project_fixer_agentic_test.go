@@ -0,0 +1,123 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// cancellingAIClient answers the relevance ranking call normally and, on the
+// first fix attempt, streams a partial response before cancelling the session.
+type cancellingAIClient struct {
+	rankResponse string
+	partial      string
+	cancel       context.CancelFunc
+	calls        int
+}
+
+func (c *cancellingAIClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *cancellingAIClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	c.calls++
+	if c.calls == 1 {
+		ch := make(chan string, 1)
+		ch <- c.rankResponse
+		close(ch)
+		return ch, nil
+	}
+	ch := make(chan string)
+	go func() {
+		ch <- c.partial
+		c.cancel()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (c *cancellingAIClient) ListModels() ([]string, error) { return []string{"stub-model"}, nil }
+
+func TestProcessFixCommandContext_CancelKeepsPartialAndRestores(t *testing.T) {
+	root := t.TempDir()
+	mainPath := filepath.Join(root, "main.go")
+	original := "package main\n\nfunc main() {}\n"
+	createFile(t, mainPath, original)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &cancellingAIClient{rankResponse: "main.go", partial: "=== FILE: main.go", cancel: cancel}
+	fixer := NewAgenticProjectFixer(client, "model", NewActionLogger(func(msg string) {}))
+
+	result, err := fixer.ProcessFixCommandContext(ctx, &FixSessionRequest{
+		Message:     "fix the main function",
+		ProjectRoot: root,
+		MaxAttempts: 3,
+		MaxCycles:   1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("ProcessFixCommandContext() error = %v", err)
+	}
+
+	if !result.Cancelled || result.Success {
+		t.Fatalf("expected a cancelled, unsuccessful result; got %+v", result)
+	}
+	if result.TotalAttempts != 1 {
+		t.Errorf("TotalAttempts = %d, want 1", result.TotalAttempts)
+	}
+	if got := result.Attempts[0].Strategy.AIResponse; got != client.partial {
+		t.Errorf("partial response = %q, want %q", got, client.partial)
+	}
+
+	data, readErr := os.ReadFile(mainPath)
+	if readErr != nil {
+		t.Fatalf("ReadFile: %v", readErr)
+	}
+	if string(data) != original {
+		t.Errorf("main.go was modified: %q", data)
+	}
+}
+
+func TestProcessFixCommandContext_AlreadyCancelled(t *testing.T) {
+	root := t.TempDir()
+	createFile(t, filepath.Join(root, "main.go"), "package main\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &stubAIClient{response: "main.go"}
+	fixer := NewAgenticProjectFixer(client, "model", NewActionLogger(func(msg string) {}))
+
+	result, err := fixer.ProcessFixCommandContext(ctx, &FixSessionRequest{
+		Message:     "fix it",
+		ProjectRoot: root,
+		MaxAttempts: 3,
+		MaxCycles:   1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("ProcessFixCommandContext() error = %v", err)
+	}
+	if !result.Cancelled {
+		t.Fatalf("expected Cancelled result; got %+v", result)
+	}
+	if result.TotalAttempts != 0 {
+		t.Errorf("TotalAttempts = %d, want 0", result.TotalAttempts)
+	}
+}
+
+func TestBuildAgenticPrompt_LanguageServerDiagnostics(t *testing.T) {
+	fixer := NewAgenticProjectFixer(&stubAIClient{}, "model", NewActionLogger(func(msg string) {}))
+	session := &FixSession{OriginalAsk: "fix the build", Diagnostics: "main.go:3:2: error: undefined: x\n"}
+
+	prompt := fixer.buildAgenticPrompt(session, nil, nil)
+	want := "=== LANGUAGE SERVER DIAGNOSTICS ===\nReported by the editor's language servers before the first attempt:\nmain.go:3:2: error: undefined: x\n\n"
+	if !strings.Contains(prompt, want) {
+		t.Errorf("expected the diagnostics section, got:\n%s", prompt)
+	}
+
+	session.Diagnostics = ""
+	if prompt := fixer.buildAgenticPrompt(session, nil, nil); strings.Contains(prompt, "LANGUAGE SERVER DIAGNOSTICS") {
+		t.Error("expected no diagnostics section without diagnostics")
+	}
+}
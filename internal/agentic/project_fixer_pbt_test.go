@@ -300,7 +300,7 @@ func TestPropPathSafetyNoOutOfScope(t *testing.T) {
 			true, // preview — we don't want actual writes
 		)
 
-		_, _, _, outOfScope, _, err := editor.edit(allPaths, "patch files")
+		_, _, _, outOfScope, err := editor.edit(allPaths, "patch files")
 		if err != nil {
 			t.Fatalf("edit() error: %v", err)
 		}
@@ -483,7 +483,7 @@ func TestPropFailedPatchPreservesContent(t *testing.T) {
 		)
 
 		absFilePath, _ := filepath.Abs(filePath)
-		_, failures, _, _, _, err := editor.edit([]string{absFilePath}, "patch file")
+		_, failures, _, _, err := editor.edit([]string{absFilePath}, "patch file")
 		if err != nil {
 			t.Fatalf("edit() error: %v", err)
 		}
@@ -572,7 +572,7 @@ func TestPropChangeReportCompleteness(t *testing.T) {
 			true, // preview
 		)
 
-		modified, failures, unreadable, outOfScope, _, err := editor.edit(allPaths, "patch files")
+		modified, failures, unreadable, outOfScope, err := editor.edit(allPaths, "patch files")
 		if err != nil {
 			t.Fatalf("edit() error: %v", err)
 		}
@@ -682,7 +682,7 @@ func TestPropPreviewModeWritesNothing(t *testing.T) {
 			true, // PREVIEW MODE
 		)
 
-		_, _, _, _, _, err = editor.edit(absPaths, "patch files")
+		_, _, _, _, err = editor.edit(absPaths, "patch files")
 		if err != nil {
 			t.Fatalf("edit() error: %v", err)
 		}
@@ -748,7 +748,7 @@ func TestPropDuplicateSearchRejected(t *testing.T) {
 		)
 
 		absFilePath, _ := filepath.Abs(filePath)
-		_, failures, _, _, _, err := editor.edit([]string{absFilePath}, "patch file")
+		_, failures, _, _, err := editor.edit([]string{absFilePath}, "patch file")
 		if err != nil {
 			t.Fatalf("edit() error: %v", err)
 		}
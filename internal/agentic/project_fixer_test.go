@@ -287,7 +287,7 @@ return "world"
 	)
 
 	absFilePath, _ := filepath.Abs(filePath)
-	_, _, _, _, _, err := editor.edit([]string{absFilePath}, "change hello to world")
+	_, _, _, _, err := editor.edit([]string{absFilePath}, "change hello to world")
 	if err != nil {
 		t.Fatalf("edit() error: %v", err)
 	}
@@ -0,0 +1,160 @@
+package agentic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// defaultAgentMaxSteps bounds the number of model turns in one agent run.
+const defaultAgentMaxSteps = 12
+
+// toolAgent drives a tool-using conversation: the model is asked to act on a
+// prompt, the tools it calls are executed and their results sent back, until
+// the model gives a final answer or the step limit is reached.
+type toolAgent struct {
+	aiClient AIClient
+	model    string
+	tools    *agentToolbox
+	maxSteps int
+}
+
+// agentRun is the outcome of a toolAgent run.
+type agentRun struct {
+	Final      string           // the model's final answer
+	Transcript string           // model text and tool calls of every turn
+	Usage      types.TokenUsage // summed over all turns
+	ToolCalls  int              // number of tool calls executed
+}
+
+// newToolAgent creates a toolAgent with the default step limit.
+func newToolAgent(aiClient AIClient, model string, tools *agentToolbox) *toolAgent {
+	return &toolAgent{
+		aiClient: aiClient,
+		model:    model,
+		tools:    tools,
+		maxSteps: defaultAgentMaxSteps,
+	}
+}
+
+// run executes the agent loop for prompt. On error the returned agentRun holds
+// everything up to the failure; when ctx is cancelled mid-turn the partial
+// model output is appended to the transcript and an *ai.CancelledError is
+// returned.
+func (ta *toolAgent) run(ctx context.Context, prompt string) (*agentRun, error) {
+	result := &agentRun{}
+	specs := agentToolSpecs()
+	messages := []ai.ToolMessage{{Role: "user", Content: prompt}}
+	var transcript strings.Builder
+
+	for step := 1; step <= ta.maxSteps; step++ {
+		reply, err := ai.ChatTools(ctx, ta.aiClient, messages, specs, ta.model)
+		if err != nil {
+			var cancelled *ai.CancelledError
+			if errors.As(err, &cancelled) {
+				transcript.WriteString(cancelled.Partial)
+			}
+			result.Transcript = transcript.String()
+			return result, err
+		}
+		result.Usage.InputTokens += reply.Usage.InputTokens
+		result.Usage.OutputTokens += reply.Usage.OutputTokens
+		result.Usage.TotalTokens += reply.Usage.TotalTokens
+
+		if reply.Content != "" {
+			transcript.WriteString(reply.Content)
+			transcript.WriteString("\n")
+		}
+
+		// Models that ignore the tools and answer in the SEARCH/REPLACE
+		// format are still honoured
+		calls := reply.ToolCalls
+		legacy := false
+		if len(calls) == 0 {
+			calls = legacyToolCalls(reply.Content)
+			legacy = len(calls) > 0
+		}
+		if len(calls) == 0 {
+			result.Final = strings.TrimSpace(reply.Content)
+			result.Transcript = transcript.String()
+			return result, nil
+		}
+
+		results := make([]ai.ToolResult, len(calls))
+		commandFailed := false
+		for i, call := range calls {
+			if !legacy {
+				transcript.WriteString(fmt.Sprintf("→ %s(%s)\n", call.Name, summarizeToolArgs(call.Arguments)))
+			}
+			results[i] = ta.tools.execute(call)
+			result.ToolCalls++
+			if results[i].IsError && call.Name == toolRunCommand {
+				commandFailed = true
+			}
+		}
+		messages = append(messages,
+			ai.ToolMessage{Role: "assistant", Content: reply.Content, ToolCalls: calls},
+			ai.ToolMessage{Role: "tool", Results: results},
+		)
+
+		// A legacy reply is a complete answer; only a failing verification
+		// command is worth another turn
+		if legacy && !commandFailed {
+			result.Final = strings.TrimSpace(reply.Content)
+			result.Transcript = transcript.String()
+			return result, nil
+		}
+	}
+
+	ta.tools.log("Stopped after %d steps without a final answer", ta.maxSteps)
+	result.Transcript = transcript.String()
+	return result, nil
+}
+
+// legacyToolCalls converts a reply written in the "=== FILE:" SEARCH/REPLACE
+// format (with an optional ~~~EXECUTE block) into the equivalent tool calls.
+func legacyToolCalls(text string) []ai.ToolCall {
+	sections := splitOnFileHeaders(text)
+	paths := make([]string, 0, len(sections))
+	for p := range sections {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var calls []ai.ToolCall
+	add := func(name string, args interface{}) {
+		data, _ := json.Marshal(args)
+		calls = append(calls, ai.ToolCall{ID: fmt.Sprintf("call_%d", len(calls)+1), Name: name, Arguments: data})
+	}
+
+	for _, p := range paths {
+		patches := parseSearchReplace(sections[p])
+		if len(patches) == 0 {
+			continue
+		}
+		args := map[string]interface{}{"path": strings.TrimSpace(p)}
+		var edits []map[string]string
+		for _, patch := range patches {
+			if patch.isNewFile {
+				args["content"] = patch.replace
+				continue
+			}
+			edits = append(edits, map[string]string{"search": patch.search, "replace": patch.replace})
+		}
+		if len(edits) > 0 {
+			args["edits"] = edits
+		}
+		add(toolApplyPatch, args)
+	}
+
+	if cmd := extractExecuteCommand(text); cmd != "" {
+		add(toolRunCommand, map[string]string{"command": cmd})
+	}
+	return calls
+}
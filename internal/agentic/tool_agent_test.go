@@ -0,0 +1,151 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// scriptedToolClient is a native tool client that returns its replies in
+// order and records the conversation of every turn.
+type scriptedToolClient struct {
+	replies []*ai.ToolReply
+	seen    [][]ai.ToolMessage
+}
+
+func (c *scriptedToolClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *scriptedToolClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	ch := make(chan string)
+	close(ch)
+	return ch, nil
+}
+
+func (c *scriptedToolClient) ListModels() ([]string, error) { return nil, nil }
+
+func (c *scriptedToolClient) ChatTools(ctx context.Context, messages []ai.ToolMessage, tools []ai.ToolSpec, model string) (*ai.ToolReply, error) {
+	c.seen = append(c.seen, append([]ai.ToolMessage(nil), messages...))
+	if len(c.replies) == 0 {
+		return &ai.ToolReply{Content: "done"}, nil
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return reply, nil
+}
+
+func TestToolAgent_ExecutesCallsUntilFinalAnswer(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	createFile(t, path, "package main\n\nconst greeting = \"hello\"\n")
+
+	client := &scriptedToolClient{replies: []*ai.ToolReply{
+		{ToolCalls: []ai.ToolCall{toolCall(toolReadFile, map[string]string{"path": "main.go"})}, Usage: types.TokenUsage{InputTokens: 10, OutputTokens: 2, TotalTokens: 12}},
+		{ToolCalls: []ai.ToolCall{toolCall(toolApplyPatch, map[string]interface{}{
+			"path":  "main.go",
+			"edits": []map[string]string{{"search": `"hello"`, "replace": `"world"`}},
+		})}, Usage: types.TokenUsage{InputTokens: 20, OutputTokens: 3, TotalTokens: 23}},
+		{Content: "Changed the greeting.", Usage: types.TokenUsage{InputTokens: 30, OutputTokens: 4, TotalTokens: 34}},
+	}}
+
+	tb := newTestToolbox(t, root, false)
+	run, err := newToolAgent(client, "model", tb).run(context.Background(), "change the greeting")
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+
+	if run.Final != "Changed the greeting." {
+		t.Errorf("unexpected final answer: %q", run.Final)
+	}
+	if run.ToolCalls != 2 {
+		t.Errorf("ToolCalls = %d, want 2", run.ToolCalls)
+	}
+	if run.Usage.InputTokens != 60 || run.Usage.OutputTokens != 9 || run.Usage.TotalTokens != 69 {
+		t.Errorf("unexpected usage: %+v", run.Usage)
+	}
+	if !strings.Contains(run.Transcript, "→ apply_patch(") {
+		t.Errorf("transcript missing tool call: %q", run.Transcript)
+	}
+
+	// The second turn must carry the read_file result back to the model.
+	second := client.seen[1]
+	if len(second) != 3 || second[2].Role != "tool" || !strings.Contains(second[2].Results[0].Content, "const greeting") {
+		t.Errorf("read_file result not sent back to the model: %+v", second)
+	}
+
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), `"world"`) {
+		t.Errorf("file not patched: %q", got)
+	}
+}
+
+func TestToolAgent_StopsAtStepLimit(t *testing.T) {
+	root := t.TempDir()
+	client := &scriptedToolClient{}
+	for i := 0; i < 5; i++ {
+		client.replies = append(client.replies, &ai.ToolReply{ToolCalls: []ai.ToolCall{toolCall(toolListDir, map[string]string{})}})
+	}
+
+	agent := newToolAgent(client, "model", newTestToolbox(t, root, false))
+	agent.maxSteps = 3
+	run, err := agent.run(context.Background(), "loop")
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if len(client.seen) != 3 || run.ToolCalls != 3 || run.Final != "" {
+		t.Errorf("expected 3 turns without a final answer, got turns=%d calls=%d final=%q", len(client.seen), run.ToolCalls, run.Final)
+	}
+}
+
+func TestToolAgent_AcceptsSearchReplaceReplies(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "hello.go")
+	createFile(t, path, "package main\n\nfunc hello() string {\n\treturn \"hello\"\n}\n")
+
+	response := "=== FILE: hello.go ===\n~~~SEARCH\nreturn \"hello\"\n~~~REPLACE\nreturn \"world\"\n~~~END\n"
+	tb := newTestToolbox(t, root, false)
+	run, err := newToolAgent(&stubAIClient{response: response}, "stub", tb).run(context.Background(), "change hello")
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if run.ToolCalls != 1 {
+		t.Errorf("ToolCalls = %d, want 1", run.ToolCalls)
+	}
+
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), `return "world"`) {
+		t.Errorf("SEARCH/REPLACE reply not applied: %q", got)
+	}
+}
+
+func TestLegacyToolCalls(t *testing.T) {
+	response := strings.Join([]string{
+		"=== FILE: b.go ===",
+		"~~~SEARCH", "old", "~~~REPLACE", "new", "~~~END",
+		"=== FILE: a.go ===",
+		"~~~NEWFILE", "package a", "~~~END",
+		"~~~EXECUTE", "go test ./...", "~~~END",
+	}, "\n")
+
+	calls := legacyToolCalls(response)
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d: %+v", len(calls), calls)
+	}
+	if calls[0].Name != toolApplyPatch || string(calls[0].Arguments) != `{"content":"package a","path":"a.go"}` {
+		t.Errorf("unexpected new-file call: %s %s", calls[0].Name, calls[0].Arguments)
+	}
+	if calls[1].Name != toolApplyPatch || string(calls[1].Arguments) != `{"edits":[{"replace":"new","search":"old"}],"path":"b.go"}` {
+		t.Errorf("unexpected patch call: %s %s", calls[1].Name, calls[1].Arguments)
+	}
+	if calls[2].Name != toolRunCommand || string(calls[2].Arguments) != `{"command":"go test ./..."}` {
+		t.Errorf("unexpected command call: %s %s", calls[2].Name, calls[2].Arguments)
+	}
+
+	if calls := legacyToolCalls("All good, nothing to change."); len(calls) != 0 {
+		t.Errorf("expected no calls for prose, got %+v", calls)
+	}
+}
@@ -0,0 +1,637 @@
+package agentic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/executor"
+)
+
+// Names of the tools exposed to the model by the agent loop.
+const (
+	toolReadFile   = "read_file"
+	toolListDir    = "list_dir"
+	toolSearch     = "search"
+	toolApplyPatch = "apply_patch"
+	toolRunCommand = "run_command"
+	toolRunTests   = "run_tests"
+)
+
+// maxToolOutput caps the size of a single tool result sent back to the model.
+const maxToolOutput = 8000
+
+// maxSearchMatches caps the number of lines returned by the search tool.
+const maxSearchMatches = 100
+
+// agentToolSpecs returns the tool definitions offered to the model.
+func agentToolSpecs() []ai.ToolSpec {
+	str := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "description": description}
+	}
+	object := func(properties map[string]interface{}, required ...string) map[string]interface{} {
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+
+	return []ai.ToolSpec{
+		{
+			Name:        toolReadFile,
+			Description: "Read a file of the project. Returns the exact file text (at most 2000 lines).",
+			Parameters: object(map[string]interface{}{
+				"path":       str("file path relative to the project root"),
+				"start_line": map[string]interface{}{"type": "integer", "description": "first line to return (1-based, optional)"},
+				"end_line":   map[string]interface{}{"type": "integer", "description": "last line to return (inclusive, optional)"},
+			}, "path"),
+		},
+		{
+			Name:        toolListDir,
+			Description: "List the entries of a project directory. Directories end with a slash.",
+			Parameters: object(map[string]interface{}{
+				"path": str("directory path relative to the project root (default: the root)"),
+			}),
+		},
+		{
+			Name:        toolSearch,
+			Description: "Search project text files for a regular expression. Returns matching lines as path:line: text.",
+			Parameters: object(map[string]interface{}{
+				"pattern": str("regular expression (RE2 syntax) to look for"),
+				"path":    str("directory to search, relative to the project root (default: the root)"),
+			}, "pattern"),
+		},
+		{
+			Name: toolApplyPatch,
+			Description: "Modify or create a file. Each edit replaces the first occurrence of its exact search text. " +
+				"To create a file or replace it entirely, pass content instead of edits. All edits are applied or none are.",
+			Parameters: object(map[string]interface{}{
+				"path": str("file path relative to the project root"),
+				"edits": map[string]interface{}{
+					"type": "array",
+					"items": object(map[string]interface{}{
+						"search":  str("exact text to find, including whitespace"),
+						"replace": str("replacement text"),
+					}, "search", "replace"),
+				},
+				"content": str("entire new file content (for new files)"),
+			}, "path"),
+		},
+		{
+			Name:        toolRunCommand,
+			Description: "Run a shell command in the project root and return its exit code and output.",
+			Parameters: object(map[string]interface{}{
+				"command": str("command line to run"),
+			}, "command"),
+		},
+		{
+			Name:        toolRunTests,
+			Description: "Run the project's tests. Uses the test command of the detected language unless one is given.",
+			Parameters: object(map[string]interface{}{
+				"command": str("test command to run instead of the default (optional)"),
+			}),
+		},
+	}
+}
+
+// writeToolInstructions appends the prompt section describing how to work
+// with the tools.
+func writeToolInstructions(sb *strings.Builder, preview bool) {
+	sb.WriteString("Use the tools to inspect and change the project:\n")
+	sb.WriteString("- read_file, list_dir and search to look at code you have not been shown\n")
+	sb.WriteString("- apply_patch to modify files (exact search/replace edits) or create new ones\n")
+	sb.WriteString("- run_command and run_tests to verify your changes\n")
+	if preview {
+		sb.WriteString("This is a PREVIEW run: patches are recorded but not written, and commands are not run.\n")
+	}
+	sb.WriteString("Only change files that need changes. When you are done, reply with a short summary of what you changed.\n")
+}
+
+// agentToolbox executes the agent's tool calls inside a project root. It
+// records every file it modifies and every patch that fails, and in preview
+// mode keeps patched content in memory instead of writing it to disk.
+type agentToolbox struct {
+	absRoot    string
+	rootPrefix string // absRoot with a trailing separator
+	preview    bool
+	logger     *ActionLogger
+	executor   *executor.CommandExecutor
+	testRunner *TestRunner
+	snapshots  *FileSnapshotManager // optional; files are captured before their first write
+
+	overlay  map[string]string // absPath → content after the patches of this run
+	original map[string]string // absPath → content before the first patch ("" for new files)
+	order    []string          // modified absPaths in order of first modification
+	patches  []searchReplacePatch
+	failures []PatchFailure
+}
+
+// newAgentToolbox creates a toolbox bound to root.
+func newAgentToolbox(root string, preview bool, logger *ActionLogger) (*agentToolbox, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve project root %q: %w", root, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(absRoot); err == nil {
+		absRoot = resolved
+	}
+	rootPrefix := absRoot
+	if !strings.HasSuffix(rootPrefix, string(filepath.Separator)) {
+		rootPrefix += string(filepath.Separator)
+	}
+	return &agentToolbox{
+		absRoot:    absRoot,
+		rootPrefix: rootPrefix,
+		preview:    preview,
+		logger:     logger,
+		executor:   executor.NewCommandExecutor(),
+		testRunner: NewTestRunner(),
+		overlay:    make(map[string]string),
+		original:   make(map[string]string),
+	}, nil
+}
+
+// execute runs a single tool call and returns its result for the model.
+func (tb *agentToolbox) execute(call ai.ToolCall) ai.ToolResult {
+	tb.log("Tool %s(%s)", call.Name, summarizeToolArgs(call.Arguments))
+
+	var content string
+	var err error
+	switch call.Name {
+	case toolReadFile:
+		content, err = tb.readFile(call.Arguments)
+	case toolListDir:
+		content, err = tb.listDir(call.Arguments)
+	case toolSearch:
+		content, err = tb.search(call.Arguments)
+	case toolApplyPatch:
+		content, err = tb.applyPatch(call.Arguments)
+	case toolRunCommand:
+		content, err = tb.runCommand(call.Arguments)
+	case toolRunTests:
+		content, err = tb.runTests(call.Arguments)
+	default:
+		err = fmt.Errorf("unknown tool %q", call.Name)
+	}
+
+	result := ai.ToolResult{CallID: call.ID, Name: call.Name, Content: truncateToolOutput(content)}
+	if err != nil {
+		result.IsError = true
+		result.Content = truncateToolOutput(err.Error())
+		tb.log("Tool %s failed: %s", call.Name, err.Error())
+	}
+	return result
+}
+
+// modifiedFiles returns a FileResult for every file changed so far, in the
+// order the files were first modified.
+func (tb *agentToolbox) modifiedFiles() []FileResult {
+	results := make([]FileResult, 0, len(tb.order))
+	for _, absP := range tb.order {
+		origLines := strings.Split(tb.original[absP], "\n")
+		newLines := strings.Split(tb.overlay[absP], "\n")
+		linesAdded := 0
+		linesRemoved := 0
+		if len(newLines) > len(origLines) {
+			linesAdded = len(newLines) - len(origLines)
+		} else {
+			linesRemoved = len(origLines) - len(newLines)
+		}
+		results = append(results, FileResult{
+			Path:         absP,
+			RelPath:      tb.rel(absP),
+			LinesAdded:   linesAdded,
+			LinesRemoved: linesRemoved,
+			OldContent:   tb.original[absP],
+			NewContent:   tb.overlay[absP],
+		})
+	}
+	return results
+}
+
+// resolve maps a model-supplied path onto an absolute path inside the root.
+func (tb *agentToolbox) resolve(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		p = "."
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(tb.absRoot, p)
+	}
+	safe, absP, reason := checkPathSafety(p, tb.absRoot, tb.rootPrefix)
+	if !safe {
+		return "", fmt.Errorf("%s", reason)
+	}
+	return absP, nil
+}
+
+// rel returns absP relative to the project root.
+func (tb *agentToolbox) rel(absP string) string {
+	rel, err := filepath.Rel(tb.absRoot, absP)
+	if err != nil {
+		return absP
+	}
+	return rel
+}
+
+// content returns the current text of a file, including patches not yet
+// written to disk.
+func (tb *agentToolbox) content(absP string) (string, error) {
+	if text, ok := tb.overlay[absP]; ok {
+		return text, nil
+	}
+	data, err := os.ReadFile(absP)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (tb *agentToolbox) readFile(raw json.RawMessage) (string, error) {
+	var args struct {
+		Path      string `json:"path"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return "", err
+	}
+	absP, err := tb.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+	text, err := tb.content(absP)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", args.Path, err)
+	}
+
+	lines := strings.Split(text, "\n")
+	start, end := 1, len(lines)
+	if args.StartLine > 0 {
+		start = args.StartLine
+	}
+	if args.EndLine > 0 && args.EndLine < end {
+		end = args.EndLine
+	}
+	if start > end {
+		return "", fmt.Errorf("line range %d-%d is outside the file (%d lines)", args.StartLine, args.EndLine, len(lines))
+	}
+	truncated := false
+	if end-start+1 > 2000 {
+		end = start + 1999
+		truncated = true
+	}
+	out := strings.Join(lines[start-1:end], "\n")
+	if truncated {
+		out += fmt.Sprintf("\n[TRUNCATED: showing lines %d-%d of %d; use start_line/end_line to read more]", start, end, len(lines))
+	}
+	return out, nil
+}
+
+func (tb *agentToolbox) listDir(raw json.RawMessage) (string, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return "", err
+	}
+	absDir, err := tb.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		return "", fmt.Errorf("cannot list %s: %w", args.Path, err)
+	}
+
+	ignore := newGitIgnoreMatcher(tb.absRoot)
+	var names []string
+	for _, e := range entries {
+		rel := tb.rel(filepath.Join(absDir, e.Name()))
+		if ignore.matches(rel) || (e.IsDir() && skipDirs[e.Name()]) {
+			continue
+		}
+		if e.IsDir() {
+			names = append(names, e.Name()+"/")
+		} else {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "(empty directory)", nil
+	}
+	return strings.Join(names, "\n"), nil
+}
+
+func (tb *agentToolbox) search(raw json.RawMessage) (string, error) {
+	var args struct {
+		Pattern string `json:"pattern"`
+		Path    string `json:"path"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return "", err
+	}
+	if args.Pattern == "" {
+		return "", fmt.Errorf("pattern is required")
+	}
+	re, err := regexp.Compile(args.Pattern)
+	if err != nil {
+		// Models often pass plain text with unescaped metacharacters
+		re = regexp.MustCompile(regexp.QuoteMeta(args.Pattern))
+	}
+	absDir, err := tb.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	paths, _, err := newFileScanner(tb.absRoot, 0).scan()
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, p := range paths {
+		if p != absDir && !strings.HasPrefix(p, absDir+string(filepath.Separator)) {
+			continue
+		}
+		text, err := tb.content(p)
+		if err != nil {
+			continue
+		}
+		for i, line := range strings.Split(text, "\n") {
+			if !re.MatchString(line) {
+				continue
+			}
+			line = truncateRunes(line, 200)
+			matches = append(matches, fmt.Sprintf("%s:%d: %s", tb.rel(p), i+1, line))
+			if len(matches) >= maxSearchMatches {
+				matches = append(matches, fmt.Sprintf("[stopped after %d matches]", maxSearchMatches))
+				return strings.Join(matches, "\n"), nil
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return "no matches", nil
+	}
+	return strings.Join(matches, "\n"), nil
+}
+
+// patchArgs are the arguments of the apply_patch tool.
+type patchArgs struct {
+	Path  string `json:"path"`
+	Edits []struct {
+		Search  string `json:"search"`
+		Replace string `json:"replace"`
+	} `json:"edits"`
+	Content *string `json:"content"`
+}
+
+// applyPatch applies the edits of one apply_patch call. A failed patch is
+// recorded in tb.failures and leaves the file untouched.
+func (tb *agentToolbox) applyPatch(raw json.RawMessage) (string, error) {
+	var args patchArgs
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return "", err
+	}
+	fail := func(path, reason string) (string, error) {
+		tb.failures = append(tb.failures, PatchFailure{Path: path, Reason: reason})
+		return "", fmt.Errorf("%s: %s", path, reason)
+	}
+
+	absP, err := tb.resolve(args.Path)
+	if err != nil {
+		return fail(args.Path, err.Error())
+	}
+	relPath := tb.rel(absP)
+
+	var patches []searchReplacePatch
+	if args.Content != nil {
+		patches = append(patches, searchReplacePatch{replace: *args.Content, isNewFile: true})
+	}
+	seen := make(map[string]bool, len(args.Edits))
+	for _, e := range args.Edits {
+		if seen[e.Search] {
+			return fail(relPath, "duplicate search blocks in patch")
+		}
+		seen[e.Search] = true
+		patches = append(patches, searchReplacePatch{search: e.Search, replace: e.Replace})
+	}
+	if len(patches) == 0 {
+		return fail(relPath, "patch has no edits and no content")
+	}
+
+	current, readErr := tb.content(absP)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return fail(relPath, fmt.Sprintf("cannot read file: %s", readErr.Error()))
+	}
+	exists := readErr == nil
+	if !exists && args.Content == nil {
+		return fail(relPath, "file does not exist; pass content to create it")
+	}
+
+	updated := current
+	for i, patch := range patches {
+		if patch.isNewFile {
+			updated = patch.replace
+			if !strings.HasSuffix(updated, "\n") {
+				updated += "\n"
+			}
+			continue
+		}
+		updated, err = applySearchReplace(updated, patch.search, patch.replace)
+		if err != nil {
+			return fail(relPath, fmt.Sprintf("patch apply failed: edit %d: %s", i+1, err.Error()))
+		}
+	}
+
+	if !tb.preview {
+		perm := os.FileMode(0644)
+		if info, err := os.Stat(absP); err == nil {
+			perm = info.Mode().Perm()
+			if tb.snapshots != nil && !tb.snapshots.HasSnapshot(absP) {
+				_ = tb.snapshots.Capture([]string{absP})
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(absP), 0755); err != nil {
+			return fail(relPath, fmt.Sprintf("mkdir failed: %s", err.Error()))
+		}
+		if err := os.WriteFile(absP, []byte(updated), perm); err != nil {
+			return fail(relPath, fmt.Sprintf("write failed: %s", err.Error()))
+		}
+	}
+
+	if _, ok := tb.original[absP]; !ok {
+		tb.original[absP] = current
+		tb.order = append(tb.order, absP)
+	}
+	tb.overlay[absP] = updated
+	tb.patches = append(tb.patches, patches...)
+
+	status := "applied"
+	if tb.preview {
+		status = "recorded (preview, not written)"
+	}
+	tb.log("Patched %s: %d edit(s) %s", relPath, len(patches), status)
+	return fmt.Sprintf("%d edit(s) %s to %s", len(patches), status, relPath), nil
+}
+
+func (tb *agentToolbox) runCommand(raw json.RawMessage) (string, error) {
+	var args struct {
+		Command string `json:"command"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(args.Command) == "" {
+		return "", fmt.Errorf("command is required")
+	}
+	if tb.preview {
+		return "", fmt.Errorf("skipped in preview mode: %s", args.Command)
+	}
+
+	result, err := tb.executor.ExecuteCommand(args.Command, tb.absRoot)
+	if result == nil {
+		return "", fmt.Errorf("command failed: %v", err)
+	}
+	tb.log("Command exited with code %d", result.ExitCode)
+	out := formatCommandOutput(result.ExitCode, result.Stdout, result.Stderr)
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("%s", out)
+	}
+	return out, nil
+}
+
+func (tb *agentToolbox) runTests(raw json.RawMessage) (string, error) {
+	var args struct {
+		Command string `json:"command"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return "", err
+	}
+	command := strings.TrimSpace(args.Command)
+	if command == "" {
+		command = tb.defaultTestCommand()
+	}
+	if command == "" {
+		return "", fmt.Errorf("no test command available for this project; pass one explicitly")
+	}
+	if tb.preview {
+		return "", fmt.Errorf("skipped in preview mode: %s", command)
+	}
+
+	result := tb.testRunner.Run(command, tb.absRoot)
+	tb.log("Tests (%s) exited with code %d", command, result.ExitCode)
+	out := formatCommandOutput(result.ExitCode, result.Stdout, result.Stderr)
+	if result.TimedOut {
+		out += "\n(test execution timed out)"
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("%s", out)
+	}
+	return out, nil
+}
+
+// defaultTestCommand picks the test command of the language of the modified
+// files, or of the whole project when nothing has been modified yet.
+func (tb *agentToolbox) defaultTestCommand() string {
+	lang := detectLanguage(tb.order)
+	if lang == "" {
+		paths, _, err := newFileScanner(tb.absRoot, 0).scan()
+		if err == nil {
+			lang = detectLanguage(paths)
+		}
+	}
+	return getTestCommand(lang)
+}
+
+// log writes to the action logger when one is configured.
+func (tb *agentToolbox) log(format string, args ...interface{}) {
+	if tb.logger != nil {
+		tb.logger.Log(format, args...)
+	}
+}
+
+// decodeToolArgs unmarshals tool arguments, treating empty input as {}.
+func decodeToolArgs(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// summarizeToolArgs renders tool arguments on one line for the action log.
+func summarizeToolArgs(raw json.RawMessage) string {
+	var args map[string]interface{}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var value string
+		switch v := args[k].(type) {
+		case string:
+			value = fmt.Sprintf("%q", truncateRunes(strings.ReplaceAll(v, "\n", " "), 60))
+		case []interface{}:
+			value = fmt.Sprintf("[%d item(s)]", len(v))
+		default:
+			value = fmt.Sprintf("%v", v)
+		}
+		parts = append(parts, k+"="+value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatCommandOutput renders a command's exit code and output for the model.
+func formatCommandOutput(exitCode int, stdout, stderr string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("exit code %d\n", exitCode))
+	if stdout != "" {
+		sb.WriteString("stdout:\n")
+		sb.WriteString(stdout)
+		if !strings.HasSuffix(stdout, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if stderr != "" {
+		sb.WriteString("stderr:\n")
+		sb.WriteString(stderr)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// truncateToolOutput keeps tool results within maxToolOutput bytes, keeping
+// the end of the output where errors usually are. The cut never splits a
+// UTF-8 character.
+func truncateToolOutput(s string) string {
+	if len(s) <= maxToolOutput {
+		return s
+	}
+	start := len(s) - maxToolOutput
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return "[output truncated]\n..." + s[start:]
+}
+
+// truncateRunes cuts s to at most n characters, marking the cut with "...".
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n]) + "..."
+	}
+	return s
+}
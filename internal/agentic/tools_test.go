@@ -0,0 +1,267 @@
+package agentic
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+)
+
+// newTestToolbox creates a toolbox over root, failing the test on error.
+func newTestToolbox(t *testing.T, root string, preview bool) *agentToolbox {
+	t.Helper()
+	tb, err := newAgentToolbox(root, preview, nil)
+	if err != nil {
+		t.Fatalf("newAgentToolbox: %v", err)
+	}
+	return tb
+}
+
+// toolCall builds an ai.ToolCall with JSON-encoded arguments.
+func toolCall(name string, args interface{}) ai.ToolCall {
+	data, _ := json.Marshal(args)
+	return ai.ToolCall{ID: "call_1", Name: name, Arguments: data}
+}
+
+func TestToolbox_ApplyPatchWritesFile(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	createFile(t, path, "package main\n\nfunc hello() string {\n\treturn \"hello\"\n}\n")
+
+	tb := newTestToolbox(t, root, false)
+	result := tb.execute(toolCall(toolApplyPatch, map[string]interface{}{
+		"path":  "main.go",
+		"edits": []map[string]string{{"search": `return "hello"`, "replace": `return "world"`}},
+	}))
+	if result.IsError {
+		t.Fatalf("apply_patch failed: %s", result.Content)
+	}
+
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), `return "world"`) {
+		t.Errorf("file not patched: %q", got)
+	}
+	modified := tb.modifiedFiles()
+	if len(modified) != 1 || modified[0].RelPath != "main.go" {
+		t.Errorf("unexpected modified files: %+v", modified)
+	}
+}
+
+func TestToolbox_ApplyPatchCreatesFile(t *testing.T) {
+	root := t.TempDir()
+	tb := newTestToolbox(t, root, false)
+
+	result := tb.execute(toolCall(toolApplyPatch, map[string]interface{}{
+		"path":    "pkg/util.go",
+		"content": "package pkg",
+	}))
+	if result.IsError {
+		t.Fatalf("apply_patch failed: %s", result.Content)
+	}
+
+	got, err := os.ReadFile(filepath.Join(root, "pkg", "util.go"))
+	if err != nil {
+		t.Fatalf("new file not written: %v", err)
+	}
+	if string(got) != "package pkg\n" {
+		t.Errorf("unexpected content: %q", got)
+	}
+	if modified := tb.modifiedFiles(); len(modified) != 1 || modified[0].LinesAdded != 1 {
+		t.Errorf("unexpected modified files: %+v", modified)
+	}
+}
+
+func TestToolbox_FailedPatchLeavesFileUntouched(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	original := "line one\nline two\n"
+	createFile(t, path, original)
+
+	tb := newTestToolbox(t, root, false)
+	result := tb.execute(toolCall(toolApplyPatch, map[string]interface{}{
+		"path": "main.go",
+		"edits": []map[string]string{
+			{"search": "line one", "replace": "first"},
+			{"search": "line three", "replace": "third"},
+		},
+	}))
+	if !result.IsError {
+		t.Fatal("expected apply_patch to fail")
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != original {
+		t.Errorf("file changed after failed patch: %q", got)
+	}
+	if len(tb.failures) != 1 || tb.failures[0].Path != "main.go" {
+		t.Errorf("unexpected failures: %+v", tb.failures)
+	}
+	if len(tb.modifiedFiles()) != 0 {
+		t.Errorf("failed patch should not be reported as a modification")
+	}
+}
+
+func TestToolbox_DuplicateSearchRejected(t *testing.T) {
+	root := t.TempDir()
+	createFile(t, filepath.Join(root, "a.go"), "x\n")
+
+	tb := newTestToolbox(t, root, false)
+	tb.execute(toolCall(toolApplyPatch, map[string]interface{}{
+		"path":  "a.go",
+		"edits": []map[string]string{{"search": "x", "replace": "y"}, {"search": "x", "replace": "z"}},
+	}))
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0].Reason, "duplicate") {
+		t.Errorf("expected a duplicate failure, got %+v", tb.failures)
+	}
+}
+
+func TestToolbox_RejectsPathsOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	tb := newTestToolbox(t, root, false)
+
+	for _, call := range []ai.ToolCall{
+		toolCall(toolReadFile, map[string]string{"path": "../secret.txt"}),
+		toolCall(toolListDir, map[string]string{"path": "/"}),
+		toolCall(toolApplyPatch, map[string]interface{}{"path": "../evil.go", "content": "x"}),
+	} {
+		if result := tb.execute(call); !result.IsError || !strings.Contains(result.Content, "outside project root") {
+			t.Errorf("%s: expected out-of-root error, got %+v", call.Name, result)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "evil.go")); err == nil {
+		t.Error("file outside root was created")
+	}
+}
+
+func TestToolbox_PreviewKeepsChangesInMemory(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	createFile(t, path, "old\n")
+
+	tb := newTestToolbox(t, root, true)
+	if result := tb.execute(toolCall(toolApplyPatch, map[string]interface{}{
+		"path":  "main.go",
+		"edits": []map[string]string{{"search": "old", "replace": "new"}},
+	})); result.IsError {
+		t.Fatalf("apply_patch failed: %s", result.Content)
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != "old\n" {
+		t.Errorf("preview wrote to disk: %q", got)
+	}
+	if read := tb.execute(toolCall(toolReadFile, map[string]string{"path": "main.go"})); read.Content != "new\n" {
+		t.Errorf("read_file should see the previewed patch, got %q", read.Content)
+	}
+	modified := tb.modifiedFiles()
+	if len(modified) != 1 {
+		t.Fatalf("previewed patch should be reported as a modification")
+	}
+	if modified[0].OldContent != "old\n" || modified[0].NewContent != "new\n" {
+		t.Errorf("preview should record both versions for review, got %q -> %q", modified[0].OldContent, modified[0].NewContent)
+	}
+
+	for _, call := range []ai.ToolCall{
+		toolCall(toolRunCommand, map[string]string{"command": "touch ran"}),
+		toolCall(toolRunTests, map[string]string{"command": "touch ran"}),
+	} {
+		if result := tb.execute(call); !result.IsError || !strings.Contains(result.Content, "skipped in preview mode") {
+			t.Errorf("%s: expected preview skip, got %+v", call.Name, result)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "ran")); err == nil {
+		t.Error("command was run in preview mode")
+	}
+}
+
+func TestToolbox_ReadListAndSearch(t *testing.T) {
+	root := t.TempDir()
+	createFile(t, filepath.Join(root, "main.go"), "package main\n// TODO: tidy\n")
+	createFile(t, filepath.Join(root, "pkg", "util.go"), "package pkg\n")
+	createFile(t, filepath.Join(root, "node_modules", "x.js"), "// TODO: vendored\n")
+
+	tb := newTestToolbox(t, root, false)
+
+	read := tb.execute(toolCall(toolReadFile, map[string]interface{}{"path": "main.go", "start_line": 2, "end_line": 2}))
+	if read.IsError || read.Content != "// TODO: tidy" {
+		t.Errorf("unexpected read_file result: %+v", read)
+	}
+
+	list := tb.execute(toolCall(toolListDir, map[string]string{}))
+	if list.IsError || list.Content != "main.go\npkg/" {
+		t.Errorf("unexpected list_dir result: %q", list.Content)
+	}
+
+	search := tb.execute(toolCall(toolSearch, map[string]string{"pattern": "TODO"}))
+	if search.IsError || search.Content != "main.go:2: // TODO: tidy" {
+		t.Errorf("unexpected search result: %q", search.Content)
+	}
+
+	if missing := tb.execute(toolCall(toolReadFile, map[string]string{"path": "nope.go"})); !missing.IsError {
+		t.Error("expected error reading a missing file")
+	}
+	if unknown := tb.execute(toolCall("delete_everything", map[string]string{})); !unknown.IsError {
+		t.Error("expected error for an unknown tool")
+	}
+}
+
+func TestToolbox_RunCommand(t *testing.T) {
+	root := t.TempDir()
+	tb := newTestToolbox(t, root, false)
+
+	ok := tb.execute(toolCall(toolRunCommand, map[string]string{"command": "echo hi"}))
+	if ok.IsError || !strings.Contains(ok.Content, "exit code 0") || !strings.Contains(ok.Content, "hi") {
+		t.Errorf("unexpected run_command result: %+v", ok)
+	}
+
+	failed := tb.execute(toolCall(toolRunCommand, map[string]string{"command": "exit 3"}))
+	if !failed.IsError || !strings.Contains(failed.Content, "exit code 3") {
+		t.Errorf("expected failing command to be an error result, got %+v", failed)
+	}
+}
+
+func TestToolbox_LogsEveryInvocation(t *testing.T) {
+	root := t.TempDir()
+	var logged []string
+	tb, err := newAgentToolbox(root, false, NewActionLogger(func(msg string) { logged = append(logged, msg) }))
+	if err != nil {
+		t.Fatalf("newAgentToolbox: %v", err)
+	}
+
+	tb.execute(toolCall(toolListDir, map[string]string{"path": "."}))
+	tb.execute(toolCall(toolReadFile, map[string]string{"path": "missing.go"}))
+
+	joined := strings.Join(logged, "\n")
+	for _, want := range []string{`Tool list_dir(path=".")`, `Tool read_file(path="missing.go")`, "Tool read_file failed"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("log missing %q:\n%s", want, joined)
+		}
+	}
+}
+
+func TestTruncation_KeepsCharactersWhole(t *testing.T) {
+	line := strings.Repeat("é", 250)
+	if got := truncateRunes(line, 200); got != strings.Repeat("é", 200)+"..." {
+		t.Errorf("truncateRunes cut %d bytes: %q", len(got), got)
+	}
+	if got := truncateRunes("short", 200); got != "short" {
+		t.Errorf("truncateRunes changed a short string: %q", got)
+	}
+
+	summary := summarizeToolArgs(json.RawMessage(`{"content":"` + strings.Repeat("日本", 40) + `"}`))
+	if !utf8.ValidString(summary) || !strings.Contains(summary, strings.Repeat("日本", 30)+"...") {
+		t.Errorf("unexpected argument summary: %s", summary)
+	}
+
+	// One of the two cuts falls inside a character
+	for _, prefix := range []string{"", "x"} {
+		got := truncateToolOutput(prefix + strings.Repeat("é", maxToolOutput))
+		if !utf8.ValidString(got) || !strings.HasPrefix(got, "[output truncated]\n...é") {
+			t.Errorf("truncateToolOutput split a character: %q", got[:30])
+		}
+	}
+}
@@ -165,6 +165,8 @@ type FileResult struct {
 	RelPath      string
 	LinesAdded   int
 	LinesRemoved int
+	OldContent   string // content before the edit ("" for new files)
+	NewContent   string // content after the edit
 }
 
 // PatchFailure records a file whose patch could not be applied.
@@ -202,6 +204,7 @@ type ProjectFixRequest struct {
 // - Snapshots must not be nil
 type FixSession struct {
 	OriginalAsk    string
+	Diagnostics    string // Language server diagnostics when the session started
 	StartTime      time.Time
 	Attempts       []FixAttempt
 	Snapshots      map[string][]byte
@@ -256,6 +259,7 @@ type FixSessionRequest struct {
 	Message      string
 	ProjectRoot  string
 	OpenFilePath string
+	Diagnostics  string // Language server errors and warnings, one per line; may be empty
 	MaxAttempts  int
 	MaxCycles    int
 }
@@ -280,6 +284,7 @@ func (r *FixSessionRequest) Validate() error {
 // FixSessionResult is the output from the agentic fixer.
 type FixSessionResult struct {
 	Success       bool
+	Cancelled     bool // true when the session was stopped by the user
 	TotalAttempts int
 	TotalCycles   int
 	Attempts      []FixAttempt
@@ -0,0 +1,23 @@
+package agentic
+
+import (
+	"github.com/user/terminal-intelligence/internal/validation"
+)
+
+// queueValidation hands files written by an agent to the validation pipeline.
+// It does nothing when no pipeline is set.
+func queueValidation(pipeline *validation.Pipeline, paths []string) {
+	if pipeline == nil || len(paths) == 0 {
+		return
+	}
+	pipeline.ValidateChanges(paths)
+}
+
+// modifiedPaths returns the absolute paths of the given file results.
+func modifiedPaths(files []FileResult) []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
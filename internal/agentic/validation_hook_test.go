@@ -0,0 +1,38 @@
+package agentic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/validation"
+)
+
+func TestQueueValidation_NilPipeline(t *testing.T) {
+	// Must not panic when validation is disabled
+	queueValidation(nil, []string{"/tmp/main.go"})
+}
+
+func TestQueueValidation_ValidatesModifiedFiles(t *testing.T) {
+	pipeline := validation.NewPipeline()
+	defer pipeline.Shutdown()
+
+	done := make(chan *validation.ValidationSession, 1)
+	pipeline.OnValidationComplete(func(session *validation.ValidationSession) {
+		done <- session
+	})
+
+	files := []FileResult{
+		{Path: "/work/lib/tool.rb", RelPath: "lib/tool.rb"},
+		{Path: "/work/README.md", RelPath: "README.md"},
+	}
+	queueValidation(pipeline, modifiedPaths(files))
+
+	select {
+	case session := <-done:
+		if len(session.Files) != 1 || session.Files[0] != "/work/lib/tool.rb" {
+			t.Errorf("expected only /work/lib/tool.rb to be validated, got %v", session.Files)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected modified files to be validated")
+	}
+}
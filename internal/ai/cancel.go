@@ -0,0 +1,144 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// stopGrace is how long Collect keeps reading after cancellation to pick up
+// chunks that were already in flight
+const stopGrace = 100 * time.Millisecond
+
+// CancelledError is returned when generation stopped before the model
+// finished, either because the user cancelled it or a deadline passed.
+// Partial holds the text streamed before the stop.
+type CancelledError struct {
+	Partial string
+	Cause   error // context.Canceled or context.DeadlineExceeded
+}
+
+// Error implements the error interface
+func (e *CancelledError) Error() string {
+	if errors.Is(e.Cause, context.DeadlineExceeded) {
+		return "generation timed out"
+	}
+	return "generation cancelled by user"
+}
+
+// Unwrap returns the underlying context error
+func (e *CancelledError) Unwrap() error {
+	return e.Cause
+}
+
+// IsCancelled reports whether err is (or wraps) a CancelledError
+func IsCancelled(err error) bool {
+	var cancelled *CancelledError
+	return errors.As(err, &cancelled)
+}
+
+// GenerateContext starts a streaming generation that stops when ctx is done.
+// Clients implementing ContextClient abort the underlying request; for other
+// clients the returned channel is closed on cancellation and the remainder of
+// the stream is drained in the background.
+func GenerateContext(ctx context.Context, client AIClient, prompt string, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &CancelledError{Cause: err}
+	}
+	if ctxClient, ok := client.(ContextClient); ok {
+		return ctxClient.GenerateContext(ctx, prompt, model, onTokenUsage)
+	}
+	ch, err := client.Generate(prompt, model, nil, onTokenUsage)
+	if err != nil {
+		return nil, err
+	}
+	return forwardUntilDone(ctx, ch), nil
+}
+
+// Collect reads the stream until it closes. If ctx is done first, it returns
+// the text received so far together with a *CancelledError.
+func Collect(ctx context.Context, ch <-chan string) (string, error) {
+	return CollectFunc(ctx, ch, nil)
+}
+
+// CollectFunc is like Collect but also calls onChunk (when non-nil) with each
+// chunk as it arrives, so callers can render the response incrementally.
+func CollectFunc(ctx context.Context, ch <-chan string, onChunk func(chunk string)) (string, error) {
+	var sb strings.Builder
+	write := func(chunk string) {
+		if chunk == "" {
+			return
+		}
+		sb.WriteString(chunk)
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+	}
+	for {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				// Providers close the stream early when ctx is done
+				if err := ctx.Err(); err != nil {
+					return sb.String(), &CancelledError{Partial: sb.String(), Cause: err}
+				}
+				return sb.String(), nil
+			}
+			write(chunk)
+		case <-ctx.Done():
+			// Streams close promptly once ctx is done; wait briefly so chunks
+			// already in flight are kept
+			grace := time.After(stopGrace)
+			for open := true; open; {
+				select {
+				case chunk, ok := <-ch:
+					open = ok
+					write(chunk)
+				case <-grace:
+					open = false
+				}
+			}
+			return sb.String(), &CancelledError{Partial: sb.String(), Cause: ctx.Err()}
+		}
+	}
+}
+
+// forwardUntilDone relays chunks from src until it closes or ctx is done
+func forwardUntilDone(ctx context.Context, src <-chan string) <-chan string {
+	out := make(chan string, 10)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case chunk, ok := <-src:
+				if !ok {
+					return
+				}
+				select {
+				case out <- chunk:
+					continue
+				default:
+				}
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					go drain(src)
+					return
+				}
+			case <-ctx.Done():
+				go drain(src)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// drain discards the rest of a stream so its producer can finish
+func drain(ch <-chan string) {
+	for range ch {
+	}
+}
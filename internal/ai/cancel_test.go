@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// blockingClient streams one chunk and then blocks until released
+type blockingClient struct {
+	release chan struct{}
+}
+
+func (c *blockingClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *blockingClient) ListModels() ([]string, error) { return nil, nil }
+
+func (c *blockingClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		ch <- "partial "
+		<-c.release
+		ch <- "late"
+	}()
+	return ch, nil
+}
+
+func TestCollect_ReturnsPartialOnCancel(t *testing.T) {
+	client := &blockingClient{release: make(chan struct{})}
+	defer close(client.release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := GenerateContext(ctx, client, "prompt", "model", nil)
+	if err != nil {
+		t.Fatalf("GenerateContext returned error: %v", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	text, err := Collect(ctx, ch)
+	if !IsCancelled(err) {
+		t.Fatalf("expected CancelledError, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error to wrap context.Canceled, got %v", err)
+	}
+	if text != "partial " {
+		t.Errorf("expected partial text to be kept, got %q", text)
+	}
+	var cancelled *CancelledError
+	errors.As(err, &cancelled)
+	if cancelled.Partial != "partial " {
+		t.Errorf("expected Partial on error, got %q", cancelled.Partial)
+	}
+	if cancelled.Error() != "generation cancelled by user" {
+		t.Errorf("unexpected message: %q", cancelled.Error())
+	}
+}
+
+func TestCollect_DeadlineReportsTimeout(t *testing.T) {
+	client := &blockingClient{release: make(chan struct{})}
+	defer close(client.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	ch, err := GenerateContext(ctx, client, "prompt", "model", nil)
+	if err != nil {
+		t.Fatalf("GenerateContext returned error: %v", err)
+	}
+
+	_, err = Collect(ctx, ch)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if err.Error() != "generation timed out" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
+
+func TestGenerateContext_AlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GenerateContext(ctx, &blockingClient{release: make(chan struct{})}, "prompt", "model", nil)
+	if !IsCancelled(err) {
+		t.Fatalf("expected CancelledError, got %v", err)
+	}
+}
+
+func TestCollectFunc_ReportsEachChunk(t *testing.T) {
+	ch := make(chan string, 3)
+	ch <- "Hello"
+	ch <- ""
+	ch <- ", world"
+	close(ch)
+
+	var chunks []string
+	text, err := CollectFunc(context.Background(), ch, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("CollectFunc returned error: %v", err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("expected 'Hello, world', got %q", text)
+	}
+	if len(chunks) != 2 || chunks[0] != "Hello" || chunks[1] != ", world" {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+}
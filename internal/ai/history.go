@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// DefaultHistoryTokenBudget is the approximate number of tokens of conversation
+// history sent with each chat request
+const DefaultHistoryTokenBudget = 8000
+
+// summaryLineChars caps the length, in characters, of each dropped turn in
+// the history summary
+const summaryLineChars = 160
+
+// EstimateTokens returns a rough token count for text (about 4 characters per token)
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// BuildHistory converts chat pane messages into a conversation suitable for
+// ChatClient.Chat. Notifications are excluded, hidden ContextContent is
+// inlined into its user turn (older duplicates of the same context are
+// dropped), consecutive turns with the same role are merged and the result
+// always starts with a user turn.
+//
+// When the conversation exceeds budget tokens, the most recent turns are kept
+// and the older ones are condensed into a short summary prepended to the
+// window. The latest turn is always kept in full.
+func BuildHistory(messages []types.ChatMessage, budget int) []Message {
+	// Walk backwards so the most recent copy of a repeated context wins
+	seenContext := make(map[string]bool)
+	reversed := make([]Message, 0, len(messages))
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.IsNotification {
+			continue
+		}
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		content := msg.Content
+		if msg.Role == "user" && msg.ContextContent != "" && !seenContext[msg.ContextContent] {
+			seenContext[msg.ContextContent] = true
+			content = "Here is the current code:\n\n```\n" + msg.ContextContent + "\n```\n\n" + content
+		}
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		reversed = append(reversed, Message{Role: msg.Role, Content: content})
+	}
+
+	var history []Message
+	for i := len(reversed) - 1; i >= 0; i-- {
+		msg := reversed[i]
+		if len(history) == 0 && msg.Role != "user" {
+			continue
+		}
+		if n := len(history); n > 0 && history[n-1].Role == msg.Role {
+			history[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		history = append(history, msg)
+	}
+
+	return windowHistory(history, budget)
+}
+
+// windowHistory keeps the most recent turns that fit in budget tokens and
+// summarises the rest
+func windowHistory(history []Message, budget int) []Message {
+	if budget <= 0 || len(history) <= 1 {
+		return history
+	}
+
+	total := 0
+	for _, msg := range history {
+		total += EstimateTokens(msg.Content)
+	}
+	if total <= budget {
+		return history
+	}
+
+	// Reserve a fifth of the budget for the summary of dropped turns
+	summaryBudget := budget / 5
+	windowBudget := budget - summaryBudget
+
+	start := len(history) - 1
+	used := EstimateTokens(history[start].Content)
+	for start > 0 {
+		cost := EstimateTokens(history[start-1].Content)
+		if used+cost > windowBudget {
+			break
+		}
+		used += cost
+		start--
+	}
+	if start == 0 {
+		return history
+	}
+
+	summary := summarizeTurns(history[:start], summaryBudget)
+	window := append([]Message(nil), history[start:]...)
+	if window[0].Role == "user" {
+		window[0].Content = summary + "\n\n" + window[0].Content
+		return window
+	}
+	return append([]Message{{Role: "user", Content: summary}}, window...)
+}
+
+// summarizeTurns condenses dropped turns into one line each, keeping the most
+// recent lines that fit in budget tokens
+func summarizeTurns(turns []Message, budget int) string {
+	header := "Summary of earlier conversation (older turns condensed):"
+	used := EstimateTokens(header)
+
+	var lines []string
+	for i := len(turns) - 1; i >= 0; i-- {
+		text := strings.Join(strings.Fields(turns[i].Content), " ")
+		if runes := []rune(text); len(runes) > summaryLineChars {
+			text = string(runes[:summaryLineChars]) + "..."
+		}
+		line := fmt.Sprintf("- %s: %s", turns[i].Role, text)
+		cost := EstimateTokens(line)
+		if used+cost > budget {
+			break
+		}
+		used += cost
+		lines = append([]string{line}, lines...)
+	}
+
+	if omitted := len(turns) - len(lines); omitted > 0 {
+		lines = append([]string{fmt.Sprintf("- (%d earlier turns omitted)", omitted)}, lines...)
+	}
+	return header + "\n" + strings.Join(lines, "\n")
+}
+
+// FlattenHistory renders a conversation as a single prompt for providers that
+// only implement AIClient.Generate. A single-turn conversation is returned
+// unchanged.
+func FlattenHistory(messages []Message) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	if len(messages) == 1 {
+		return messages[0].Content
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Conversation so far:\n\n")
+	for _, msg := range messages[:len(messages)-1] {
+		role := "User"
+		if msg.Role == "assistant" {
+			role = "Assistant"
+		}
+		sb.WriteString(role + ": " + msg.Content + "\n\n")
+	}
+	sb.WriteString("Reply to the latest user message:\n\n")
+	sb.WriteString(messages[len(messages)-1].Content)
+	return sb.String()
+}
+
+// Converse sends a conversation to client, using its native multi-message
+// format when it implements ChatClient and a flattened prompt otherwise.
+// Generation stops when ctx is done.
+func Converse(ctx context.Context, client AIClient, messages []Message, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	if chatClient, ok := client.(ChatClient); ok {
+		if err := ctx.Err(); err != nil {
+			return nil, &CancelledError{Cause: err}
+		}
+		return chatClient.Chat(ctx, messages, model, onTokenUsage)
+	}
+	return GenerateContext(ctx, client, FlattenHistory(messages), model, onTokenUsage)
+}
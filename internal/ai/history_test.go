@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+func TestBuildHistory_ExcludesNotificationsAndInlinesContext(t *testing.T) {
+	messages := []types.ChatMessage{
+		{Role: "user", Content: "explain this", ContextContent: "package main"},
+		{Role: "assistant", Content: "It is a Go file."},
+		{Role: "assistant", Content: "Fix applied", IsNotification: true},
+		{Role: "user", Content: "thanks"},
+	}
+
+	history := BuildHistory(messages, DefaultHistoryTokenBudget)
+
+	if len(history) != 3 {
+		t.Fatalf("expected 3 turns, got %d: %+v", len(history), history)
+	}
+	if !strings.Contains(history[0].Content, "package main") || !strings.HasSuffix(history[0].Content, "explain this") {
+		t.Errorf("expected context inlined into first user turn, got %q", history[0].Content)
+	}
+	for _, msg := range history {
+		if strings.Contains(msg.Content, "Fix applied") {
+			t.Errorf("notification leaked into history: %q", msg.Content)
+		}
+	}
+}
+
+func TestBuildHistory_MergesSameRoleAndStartsWithUser(t *testing.T) {
+	messages := []types.ChatMessage{
+		{Role: "assistant", Content: "Welcome"},
+		{Role: "user", Content: "first"},
+		{Role: "user", Content: "second"},
+		{Role: "assistant", Content: "reply"},
+	}
+
+	history := BuildHistory(messages, DefaultHistoryTokenBudget)
+
+	if len(history) != 2 {
+		t.Fatalf("expected 2 turns, got %d: %+v", len(history), history)
+	}
+	if history[0].Role != "user" || history[0].Content != "first\n\nsecond" {
+		t.Errorf("unexpected first turn: %+v", history[0])
+	}
+	if history[1].Role != "assistant" {
+		t.Errorf("expected assistant turn second, got %q", history[1].Role)
+	}
+}
+
+func TestBuildHistory_DropsOlderDuplicateContext(t *testing.T) {
+	messages := []types.ChatMessage{
+		{Role: "user", Content: "one", ContextContent: "same file"},
+		{Role: "assistant", Content: "ok"},
+		{Role: "user", Content: "two", ContextContent: "same file"},
+	}
+
+	history := BuildHistory(messages, DefaultHistoryTokenBudget)
+
+	if history[0].Content != "one" {
+		t.Errorf("expected older duplicate context to be dropped, got %q", history[0].Content)
+	}
+	if !strings.Contains(history[2].Content, "same file") {
+		t.Errorf("expected latest turn to keep context, got %q", history[2].Content)
+	}
+}
+
+func TestBuildHistory_WindowsAndSummarisesLongConversations(t *testing.T) {
+	var messages []types.ChatMessage
+	for i := 0; i < 40; i++ {
+		messages = append(messages,
+			types.ChatMessage{Role: "user", Content: strings.Repeat("question ", 50)},
+			types.ChatMessage{Role: "assistant", Content: strings.Repeat("answer ", 50)},
+		)
+	}
+	messages = append(messages, types.ChatMessage{Role: "user", Content: "latest question"})
+
+	budget := 1000
+	history := BuildHistory(messages, budget)
+
+	total := 0
+	for _, msg := range history {
+		total += EstimateTokens(msg.Content)
+	}
+	if total > budget {
+		t.Errorf("expected history within %d tokens, got %d", budget, total)
+	}
+	if history[0].Role != "user" || !strings.HasPrefix(history[0].Content, "Summary of earlier conversation") {
+		t.Errorf("expected summary in first user turn, got %q", history[0].Content)
+	}
+	last := history[len(history)-1]
+	if last.Role != "user" || last.Content != "latest question" {
+		t.Errorf("expected latest turn kept in full, got %+v", last)
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].Role == history[i-1].Role {
+			t.Fatalf("roles must alternate, turns %d and %d are both %q", i-1, i, history[i].Role)
+		}
+	}
+}
+
+func TestSummarizeTurns_TruncatesByCharacter(t *testing.T) {
+	turns := []Message{{Role: "user", Content: strings.Repeat("é", summaryLineChars+10)}}
+
+	summary := summarizeTurns(turns, 1000)
+	if !utf8.ValidString(summary) {
+		t.Fatalf("expected valid UTF-8, got %q", summary)
+	}
+	if want := "- user: " + strings.Repeat("é", summaryLineChars) + "..."; !strings.Contains(summary, want) {
+		t.Errorf("expected the turn cut at %d characters, got %q", summaryLineChars, summary)
+	}
+}
+
+func TestFlattenHistory(t *testing.T) {
+	if got := FlattenHistory([]Message{{Role: "user", Content: "hello"}}); got != "hello" {
+		t.Errorf("expected single turn unchanged, got %q", got)
+	}
+
+	got := FlattenHistory([]Message{
+		{Role: "user", Content: "My name is Ada."},
+		{Role: "assistant", Content: "Hi Ada."},
+		{Role: "user", Content: "What is my name?"},
+	})
+	for _, want := range []string{"User: My name is Ada.", "Assistant: Hi Ada.", "What is my name?"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected flattened prompt to contain %q, got %q", want, got)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package ai
 
-import "github.com/user/terminal-intelligence/internal/types"
+import (
+	"context"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
 
 // AIClient is an interface for AI service providers
 type AIClient interface {
@@ -13,3 +17,29 @@ type AIClient interface {
 	// ListModels lists available models
 	ListModels() ([]string, error)
 }
+
+// ContextClient is implemented by providers whose generation can be aborted
+// through a context.Context (user cancellation or deadline). When ctx is
+// done the provider stops reading the stream and closes the channel.
+type ContextClient interface {
+	AIClient
+
+	// GenerateContext generates AI response with streaming until ctx is done
+	GenerateContext(ctx context.Context, prompt string, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error)
+}
+
+// Message is a single turn of a multi-turn conversation
+type Message struct {
+	Role    string // "user" or "assistant"
+	Content string
+}
+
+// ChatClient is implemented by providers that accept a full conversation
+// history in their native multi-message format
+type ChatClient interface {
+	AIClient
+
+	// Chat generates an AI response with streaming for the given conversation
+	// until ctx is done. The last message is expected to be the user's latest turn.
+	Chat(ctx context.Context, messages []Message, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error)
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultPromptTokenBudget is the approximate number of tokens of material,
+// such as a diff or a list of commits, included in a one-shot prompt.
+// Material beyond it is truncated.
+const DefaultPromptTokenBudget = 4000
+
+// ErrNoClient is returned when a generation is requested but no AI provider
+// is configured.
+var ErrNoClient = errors.New("no AI provider is configured")
+
+// FitBudget returns how many of parts, taken in order, fit within about
+// budget tokens.
+func FitBudget(parts []string, budget int) int {
+	for i, part := range parts {
+		cost := EstimateTokens(part)
+		if cost > budget {
+			return i
+		}
+		budget -= cost
+	}
+	return len(parts)
+}
+
+// GenerateText sends a one-shot prompt to the model and returns the reply
+// passed through clean. what names the expected reply, e.g. "commit
+// message", in the error returned when nothing is left after cleaning.
+func GenerateText(ctx context.Context, client AIClient, model, prompt string, clean func(string) string, what string) (string, error) {
+	if client == nil {
+		return "", ErrNoClient
+	}
+
+	ch, err := GenerateContext(ctx, client, prompt, model, nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := Collect(ctx, ch)
+	if err != nil {
+		return "", err
+	}
+	text := clean(response)
+	if text == "" {
+		return "", fmt.Errorf("the model returned no %s", what)
+	}
+	return text, nil
+}
+
+// CleanReply tidies a model reply: a code fence around the whole reply is
+// removed and trailing whitespace is trimmed from each line.
+func CleanReply(reply string) string {
+	text := strings.TrimSpace(reply)
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") && len(text) > 6 {
+		text = strings.TrimSuffix(text, "```")
+		if nl := strings.Index(text, "\n"); nl >= 0 {
+			text = text[nl+1:]
+		} else {
+			text = ""
+		}
+		text = strings.TrimSpace(text)
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// Property: the parts that fit stay within the budget, and the next part,
+// if any, would not have fit.
+func TestProperty_FitBudget(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		budget := rapid.IntRange(0, 300).Draw(t, "budget")
+		count := rapid.IntRange(0, 40).Draw(t, "parts")
+
+		var parts []string
+		for i := 0; i < count; i++ {
+			words := rapid.IntRange(0, 20).Draw(t, fmt.Sprintf("words%d", i))
+			parts = append(parts, strings.Repeat("word ", words))
+		}
+
+		n := FitBudget(parts, budget)
+		tokens := 0
+		for _, part := range parts[:n] {
+			tokens += EstimateTokens(part)
+		}
+		if tokens > budget {
+			t.Fatalf("%d parts use %d tokens, budget is %d", n, tokens, budget)
+		}
+		if n < len(parts) && tokens+EstimateTokens(parts[n]) <= budget {
+			t.Fatalf("part %d would still fit", n)
+		}
+	})
+}
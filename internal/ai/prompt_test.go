@@ -0,0 +1,39 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateText(t *testing.T) {
+	ctx := context.Background()
+
+	text, err := GenerateText(ctx, &textClient{response: "```\nhello  \n```"}, "model", "prompt", CleanReply, "greeting")
+	if err != nil || text != "hello" {
+		t.Errorf("GenerateText() = %q, %v", text, err)
+	}
+
+	if _, err := GenerateText(ctx, nil, "model", "prompt", CleanReply, "greeting"); !errors.Is(err, ErrNoClient) {
+		t.Errorf("expected ErrNoClient, got %v", err)
+	}
+	if _, err := GenerateText(ctx, &textClient{response: " \n```\n```"}, "model", "prompt", CleanReply, "greeting"); err == nil || !strings.Contains(err.Error(), "no greeting") {
+		t.Errorf("expected an empty reply error, got %v", err)
+	}
+}
+
+func TestCleanReply(t *testing.T) {
+	tests := map[string]string{
+		"# v1  \n\n- fix\t\n":           "# v1\n\n- fix",
+		"```markdown\n# v1\n- fix\n```": "# v1\n- fix",
+		"# v1\n\n```go\nx := 1\n```\n":  "# v1\n\n```go\nx := 1\n```",
+		"```\n```":                      "",
+		"  \n":                          "",
+	}
+	for reply, want := range tests {
+		if got := CleanReply(reply); got != want {
+			t.Errorf("CleanReply(%q) = %q, want %q", reply, got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Settings holds a provider's configuration values keyed by their config.json
+// key (e.g. "ollama_url")
+type Settings map[string]string
+
+// Get returns the value for key, or "" when unset
+func (s Settings) Get(key string) string {
+	return s[key]
+}
+
+// ConfigField describes one provider setting in config.json and the /config
+// editor
+type ConfigField struct {
+	Key         string // config.json key, e.g. "gemini_api"
+	Description string // short help text
+	Default     string // value used when the setting is empty
+	Secret      bool   // masked when displayed
+	Required    bool   // must be non-empty when the provider is active
+}
+
+// Capabilities describes what a provider supports beyond the AIClient basics
+type Capabilities struct {
+	Chat        bool // native multi-turn conversations (ChatClient)
+	Cancel      bool // generation can be aborted mid-stream (ContextClient)
+	ListModels  bool // ListModels queries the server rather than a fixed list
+	VerifyModel bool // availability check should confirm the configured model exists
+	Tools       bool // native function calling (ToolClient)
+}
+
+// ProviderSpec registers an AI backend: how to configure it and how to build
+// its client
+type ProviderSpec struct {
+	Name         string        // value of "agent" in config.json
+	Description  string        // one-line summary for /model and /config
+	ModelKey     string        // key in Fields that holds the model name
+	DefaultModel string        // model used when ModelKey is empty
+	Fields       []ConfigField // settings, including ModelKey
+	Capabilities Capabilities
+
+	// Validate performs provider-specific checks beyond Required fields (optional)
+	Validate func(settings Settings) error
+
+	// New builds a client from the provider's settings
+	New func(settings Settings) (AIClient, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]ProviderSpec)
+)
+
+// RegisterProvider makes a provider available by name. Provider packages call
+// it from init. It panics if the spec is incomplete or the name is taken.
+func RegisterProvider(spec ProviderSpec) {
+	if spec.Name == "" || spec.New == nil {
+		panic("ai: RegisterProvider requires a name and a New function")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[spec.Name]; dup {
+		panic("ai: RegisterProvider called twice for provider " + spec.Name)
+	}
+	registry[spec.Name] = spec
+}
+
+// LookupProvider returns the registered provider with the given name
+func LookupProvider(name string) (ProviderSpec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	spec, ok := registry[name]
+	return spec, ok
+}
+
+// Providers returns all registered providers sorted by name
+func Providers() []ProviderSpec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	specs := make([]ProviderSpec, 0, len(registry))
+	for _, spec := range registry {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// ProviderNames returns the names of all registered providers, sorted
+func ProviderNames() []string {
+	specs := Providers()
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+// ProviderFields returns the settings of every registered provider in a
+// stable order, without duplicates
+func ProviderFields() []ConfigField {
+	var fields []ConfigField
+	seen := make(map[string]bool)
+	for _, spec := range Providers() {
+		for _, f := range spec.Fields {
+			if !seen[f.Key] {
+				seen[f.Key] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// ValidateSettings checks that name is a registered provider and that its
+// settings are complete and valid
+func ValidateSettings(name string, settings Settings) error {
+	spec, ok := LookupProvider(name)
+	if !ok {
+		quoted := make([]string, 0)
+		for _, n := range ProviderNames() {
+			quoted = append(quoted, fmt.Sprintf("%q", n))
+		}
+		return fmt.Errorf("invalid agent: must be one of %s", strings.Join(quoted, ", "))
+	}
+
+	for _, f := range spec.Fields {
+		if f.Required && settings.Get(f.Key) == "" {
+			return fmt.Errorf("%s is required when agent is %q", f.Key, name)
+		}
+	}
+	if spec.Validate != nil {
+		return spec.Validate(settings)
+	}
+	return nil
+}
+
+// NewClient builds a client for the named provider. Empty settings fall back
+// to the field defaults declared by the provider.
+func NewClient(name string, settings Settings) (AIClient, error) {
+	spec, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider %q", name)
+	}
+	return spec.New(spec.WithDefaults(settings))
+}
+
+// WithDefaults returns a copy of settings with empty fields set to their
+// declared defaults
+func (spec ProviderSpec) WithDefaults(settings Settings) Settings {
+	out := make(Settings, len(settings))
+	for k, v := range settings {
+		out[k] = v
+	}
+	for _, f := range spec.Fields {
+		if out[f.Key] == "" && f.Default != "" {
+			out[f.Key] = f.Default
+		}
+	}
+	return out
+}
+
+// Model returns the configured model name, or the provider's default
+func (spec ProviderSpec) Model(settings Settings) string {
+	if model := settings.Get(spec.ModelKey); model != "" {
+		return model
+	}
+	return spec.DefaultModel
+}
+
+// MaskSecret hides all but the last four characters of a secret value
+func MaskSecret(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 4) + value[len(value)-4:]
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// registerTestProvider registers a provider whose client records the settings
+// it was built with
+func registerTestProvider(t *testing.T, name string) *Settings {
+	t.Helper()
+	var built Settings
+	RegisterProvider(ProviderSpec{
+		Name:         name,
+		Description:  "test provider",
+		ModelKey:     name + "_model",
+		DefaultModel: "tiny",
+		Fields: []ConfigField{
+			{Key: name + "_model"},
+			{Key: name + "_url", Default: "http://localhost:1"},
+			{Key: name + "_key", Secret: true, Required: true},
+		},
+		Validate: func(s Settings) error {
+			if s.Get(name+"_url") == "bad" {
+				return errors.New(name + "_url is malformed")
+			}
+			return nil
+		},
+		New: func(s Settings) (AIClient, error) {
+			built = s
+			return &blockingClient{}, nil
+		},
+	})
+	return &built
+}
+
+func TestRegistry_LookupAndNewClientAppliesDefaults(t *testing.T) {
+	built := registerTestProvider(t, "regtest")
+
+	spec, ok := LookupProvider("regtest")
+	if !ok || spec.Description != "test provider" {
+		t.Fatalf("LookupProvider(regtest) = %+v, %v", spec, ok)
+	}
+
+	client, err := NewClient("regtest", Settings{"regtest_key": "secret"})
+	if err != nil || client == nil {
+		t.Fatalf("NewClient() = %v, %v", client, err)
+	}
+	if got := built.Get("regtest_url"); got != "http://localhost:1" {
+		t.Errorf("default url not applied, got %q", got)
+	}
+	if got := built.Get("regtest_key"); got != "secret" {
+		t.Errorf("explicit setting lost, got %q", got)
+	}
+
+	if got := spec.Model(Settings{}); got != "tiny" {
+		t.Errorf("Model() fallback = %q, want tiny", got)
+	}
+	if got := spec.Model(Settings{"regtest_model": "big"}); got != "big" {
+		t.Errorf("Model() = %q, want big", got)
+	}
+
+	if _, err := NewClient("no-such-provider", nil); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+func TestRegistry_ValidateSettings(t *testing.T) {
+	registerTestProvider(t, "valtest")
+
+	if err := ValidateSettings("valtest", Settings{"valtest_key": "k"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := ValidateSettings("valtest", Settings{})
+	if err == nil || !strings.Contains(err.Error(), "valtest_key is required") {
+		t.Errorf("expected required-field error, got %v", err)
+	}
+
+	err = ValidateSettings("valtest", Settings{"valtest_key": "k", "valtest_url": "bad"})
+	if err == nil || !strings.Contains(err.Error(), "malformed") {
+		t.Errorf("expected provider validation error, got %v", err)
+	}
+
+	err = ValidateSettings("nope", Settings{})
+	if err == nil || !strings.Contains(err.Error(), `"valtest"`) {
+		t.Errorf("expected invalid agent error listing providers, got %v", err)
+	}
+}
+
+func TestRegistry_ProviderFieldsAndNames(t *testing.T) {
+	registerTestProvider(t, "fieldtest")
+
+	names := ProviderNames()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Fatalf("ProviderNames() not sorted: %v", names)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range ProviderFields() {
+		if seen[f.Key] {
+			t.Errorf("duplicate field %q", f.Key)
+		}
+		seen[f.Key] = true
+	}
+	for _, key := range []string{"fieldtest_model", "fieldtest_url", "fieldtest_key"} {
+		if !seen[key] {
+			t.Errorf("ProviderFields() missing %q", key)
+		}
+	}
+}
+
+func TestRegisterProvider_PanicsOnDuplicate(t *testing.T) {
+	registerTestProvider(t, "duptest")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	registerTestProvider(t, "duptest")
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := map[string]string{
+		"":                "",
+		"abc":             "***",
+		"sk-1234567890ab": "****90ab",
+	}
+	for in, want := range tests {
+		if got := MaskSecret(in); got != want {
+			t.Errorf("MaskSecret(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
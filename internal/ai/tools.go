@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// ToolSpec describes a tool the model may call
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON Schema object describing the arguments
+}
+
+// ToolCall is a model's request to run a tool
+type ToolCall struct {
+	ID        string          // provider-assigned id, echoed back in the result
+	Name      string          // tool name
+	Arguments json.RawMessage // JSON object matching the tool's Parameters
+}
+
+// ToolResult is the output of a tool call, returned to the model
+type ToolResult struct {
+	CallID  string // ID of the ToolCall this answers
+	Name    string // tool name
+	Content string // tool output or error message
+	IsError bool   // whether the tool failed
+}
+
+// ToolMessage is a single turn of a tool-using conversation
+type ToolMessage struct {
+	Role      string       // "user", "assistant" or "tool"
+	Content   string       // text of the turn (may be empty for tool turns)
+	ToolCalls []ToolCall   // assistant turns: tools the model asked to run
+	Results   []ToolResult // tool turns: outputs of those tools
+}
+
+// ToolReply is the model's answer in a tool-using conversation. When
+// ToolCalls is empty, Content is the final answer.
+type ToolReply struct {
+	Content   string
+	ToolCalls []ToolCall
+	Usage     types.TokenUsage
+}
+
+// ErrToolsUnsupported is wrapped by ToolClient errors when the configured
+// model cannot use native tools; ChatTools then falls back to the JSON protocol
+var ErrToolsUnsupported = errors.New("model does not support tool calling")
+
+// ToolClient is implemented by providers with native function calling
+type ToolClient interface {
+	AIClient
+
+	// ChatTools sends the conversation with the available tools and returns
+	// the model's reply once it is complete
+	ChatTools(ctx context.Context, messages []ToolMessage, tools []ToolSpec, model string) (*ToolReply, error)
+}
+
+// ChatTools runs one model turn of a tool-using conversation. Providers that
+// implement ToolClient use native function calling; for the others (and for
+// models that reject native tools) the tools are described in the prompt and
+// the model answers with a JSON object (see ParseToolReply).
+func ChatTools(ctx context.Context, client AIClient, messages []ToolMessage, tools []ToolSpec, model string) (*ToolReply, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &CancelledError{Cause: err}
+	}
+	if toolClient, ok := client.(ToolClient); ok {
+		reply, err := toolClient.ChatTools(ctx, messages, tools, model)
+		if err != nil && ctx.Err() != nil {
+			return nil, &CancelledError{Cause: ctx.Err()}
+		}
+		if !errors.Is(err, ErrToolsUnsupported) {
+			return reply, err
+		}
+	}
+
+	var usage types.TokenUsage
+	ch, err := GenerateContext(ctx, client, BuildToolPrompt(messages, tools), model, func(u types.TokenUsage) {
+		usage = u
+	})
+	if err != nil {
+		return nil, err
+	}
+	text, err := Collect(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := ParseToolReply(text)
+	reply.Usage = usage
+	return reply, nil
+}
+
+// BuildToolPrompt renders a tool-using conversation as a single prompt for
+// providers without native function calling. The prompt lists the tools and
+// asks the model to answer with the JSON protocol understood by ParseToolReply.
+func BuildToolPrompt(messages []ToolMessage, tools []ToolSpec) string {
+	var sb strings.Builder
+
+	sb.WriteString("You can use the following tools:\n\n")
+	for _, tool := range tools {
+		params, _ := json.Marshal(tool.Parameters)
+		sb.WriteString(fmt.Sprintf("- %s: %s\n  arguments (JSON Schema): %s\n", tool.Name, tool.Description, params))
+	}
+	sb.WriteString("\nTo call tools, reply with ONLY a JSON object of this form and nothing else:\n")
+	sb.WriteString(`{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments>}}]}` + "\n")
+	sb.WriteString("You will receive the tool results and can then call more tools.\n")
+	sb.WriteString("When you are finished, reply with:\n")
+	sb.WriteString(`{"final": "<short summary of what you did>"}` + "\n\n")
+
+	sb.WriteString("=== CONVERSATION ===\n\n")
+	for _, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			sb.WriteString("ASSISTANT:\n")
+			if msg.Content != "" {
+				sb.WriteString(msg.Content)
+				sb.WriteString("\n")
+			}
+			if len(msg.ToolCalls) > 0 {
+				sb.WriteString(formatToolCalls(msg.ToolCalls))
+				sb.WriteString("\n")
+			}
+		case "tool":
+			for _, result := range msg.Results {
+				status := "ok"
+				if result.IsError {
+					status = "error"
+				}
+				sb.WriteString(fmt.Sprintf("TOOL RESULT (%s, %s):\n%s\n", result.Name, status, result.Content))
+			}
+		default:
+			sb.WriteString("USER:\n")
+			sb.WriteString(msg.Content)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("ASSISTANT:\n")
+
+	return sb.String()
+}
+
+// jsonToolCall is a tool call in the JSON protocol
+type jsonToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// jsonToolReply is a model reply in the JSON protocol
+type jsonToolReply struct {
+	ToolCalls []jsonToolCall `json:"tool_calls"`
+	Final     *string        `json:"final"`
+}
+
+// formatToolCalls renders tool calls in the JSON protocol
+func formatToolCalls(calls []ToolCall) string {
+	reply := jsonToolReply{ToolCalls: make([]jsonToolCall, len(calls))}
+	for i, call := range calls {
+		reply.ToolCalls[i] = jsonToolCall{Name: call.Name, Arguments: call.Arguments}
+	}
+	data, _ := json.Marshal(reply)
+	return string(data)
+}
+
+// ParseToolReply interprets a model's text reply in the JSON protocol. The
+// JSON object may be wrapped in a code fence or surrounded by prose. A reply
+// that is not a valid tool call is returned as the final answer.
+func ParseToolReply(text string) *ToolReply {
+	reply := &ToolReply{Content: strings.TrimSpace(text)}
+
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start == -1 || end < start {
+		return reply
+	}
+
+	var parsed jsonToolReply
+	if err := json.Unmarshal([]byte(text[start:end+1]), &parsed); err != nil {
+		return reply
+	}
+
+	for i, call := range parsed.ToolCalls {
+		if call.Name == "" {
+			continue
+		}
+		args := call.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+		reply.ToolCalls = append(reply.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d", i+1),
+			Name:      call.Name,
+			Arguments: args,
+		})
+	}
+
+	switch {
+	case parsed.Final != nil:
+		reply.Content = *parsed.Final
+	case len(reply.ToolCalls) > 0:
+		reply.Content = ""
+	}
+	return reply
+}
@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// textClient answers every prompt with a fixed text and records the prompt
+type textClient struct {
+	response string
+	prompt   string
+}
+
+func (c *textClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *textClient) ListModels() ([]string, error) { return nil, nil }
+
+func (c *textClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	c.prompt = prompt
+	if onTokenUsage != nil {
+		onTokenUsage(types.TokenUsage{InputTokens: 3, OutputTokens: 2, TotalTokens: 5})
+	}
+	ch := make(chan string, 1)
+	ch <- c.response
+	close(ch)
+	return ch, nil
+}
+
+// nativeToolClient implements ToolClient with a fixed reply or error
+type nativeToolClient struct {
+	textClient
+	reply *ToolReply
+	err   error
+	calls int
+}
+
+func (c *nativeToolClient) ChatTools(ctx context.Context, messages []ToolMessage, tools []ToolSpec, model string) (*ToolReply, error) {
+	c.calls++
+	return c.reply, c.err
+}
+
+var testTools = []ToolSpec{{
+	Name:        "read_file",
+	Description: "Read a file",
+	Parameters:  map[string]interface{}{"type": "object"},
+}}
+
+func TestParseToolReply_ToolCalls(t *testing.T) {
+	reply := ParseToolReply("Sure.\n```json\n{\"tool_calls\": [{\"name\": \"read_file\", \"arguments\": {\"path\": \"main.go\"}}, {\"name\": \"list_dir\"}]}\n```")
+
+	if len(reply.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(reply.ToolCalls))
+	}
+	if reply.ToolCalls[0].ID != "call_1" || reply.ToolCalls[0].Name != "read_file" {
+		t.Errorf("unexpected first call: %+v", reply.ToolCalls[0])
+	}
+	if string(reply.ToolCalls[0].Arguments) != `{"path": "main.go"}` {
+		t.Errorf("unexpected arguments: %s", reply.ToolCalls[0].Arguments)
+	}
+	if string(reply.ToolCalls[1].Arguments) != "{}" {
+		t.Errorf("missing arguments should default to {}, got %s", reply.ToolCalls[1].Arguments)
+	}
+	if reply.Content != "" {
+		t.Errorf("expected no content alongside tool calls, got %q", reply.Content)
+	}
+}
+
+func TestParseToolReply_Final(t *testing.T) {
+	reply := ParseToolReply(`{"final": "Renamed the function."}`)
+	if len(reply.ToolCalls) != 0 {
+		t.Fatalf("expected no tool calls, got %d", len(reply.ToolCalls))
+	}
+	if reply.Content != "Renamed the function." {
+		t.Errorf("unexpected content: %q", reply.Content)
+	}
+}
+
+func TestParseToolReply_PlainTextIsFinal(t *testing.T) {
+	text := "func main() { fmt.Println(\"hi\") }"
+	reply := ParseToolReply(text)
+	if len(reply.ToolCalls) != 0 {
+		t.Fatalf("expected no tool calls, got %d", len(reply.ToolCalls))
+	}
+	if reply.Content != text {
+		t.Errorf("expected raw text as content, got %q", reply.Content)
+	}
+}
+
+func TestChatTools_FallbackUsesJSONProtocol(t *testing.T) {
+	client := &textClient{response: `{"tool_calls": [{"name": "read_file", "arguments": {"path": "a.go"}}]}`}
+	messages := []ToolMessage{
+		{Role: "user", Content: "fix a.go"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "read_file", Arguments: []byte(`{"path":"b.go"}`)}}},
+		{Role: "tool", Results: []ToolResult{{CallID: "call_1", Name: "read_file", Content: "package b"}}},
+	}
+
+	reply, err := ChatTools(context.Background(), client, messages, testTools, "model")
+	if err != nil {
+		t.Fatalf("ChatTools returned error: %v", err)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].Name != "read_file" {
+		t.Fatalf("unexpected tool calls: %+v", reply.ToolCalls)
+	}
+	if reply.Usage.TotalTokens != 5 {
+		t.Errorf("expected usage to be reported, got %+v", reply.Usage)
+	}
+
+	for _, want := range []string{"- read_file: Read a file", "fix a.go", `"tool_calls"`, "TOOL RESULT (read_file, ok):\npackage b"} {
+		if !strings.Contains(client.prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, client.prompt)
+		}
+	}
+}
+
+func TestChatTools_UsesNativeToolClient(t *testing.T) {
+	client := &nativeToolClient{reply: &ToolReply{Content: "done"}}
+
+	reply, err := ChatTools(context.Background(), client, []ToolMessage{{Role: "user", Content: "hi"}}, testTools, "model")
+	if err != nil {
+		t.Fatalf("ChatTools returned error: %v", err)
+	}
+	if client.calls != 1 || reply.Content != "done" {
+		t.Errorf("expected native reply, got calls=%d reply=%+v", client.calls, reply)
+	}
+	if client.prompt != "" {
+		t.Errorf("native client should not fall back to Generate")
+	}
+}
+
+func TestChatTools_FallsBackWhenToolsUnsupported(t *testing.T) {
+	client := &nativeToolClient{err: fmt.Errorf("%w: llama2", ErrToolsUnsupported)}
+	client.response = `{"final": "nothing to do"}`
+
+	reply, err := ChatTools(context.Background(), client, []ToolMessage{{Role: "user", Content: "hi"}}, testTools, "model")
+	if err != nil {
+		t.Fatalf("ChatTools returned error: %v", err)
+	}
+	if reply.Content != "nothing to do" {
+		t.Errorf("expected fallback reply, got %+v", reply)
+	}
+}
+
+func TestChatTools_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ChatTools(ctx, &textClient{response: "x"}, nil, testTools, "model")
+	if !IsCancelled(err) {
+		t.Fatalf("expected CancelledError, got %v", err)
+	}
+}
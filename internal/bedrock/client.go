@@ -14,6 +14,7 @@ import (
 	awsbedrock "github.com/aws/aws-sdk-go-v2/service/bedrock"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/user/terminal-intelligence/internal/ai"
 	apptypes "github.com/user/terminal-intelligence/internal/types"
 )
 
@@ -201,6 +202,28 @@ func buildRequestBody(prompt string) anthropicRequest {
 	}
 }
 
+// buildMessagesBody constructs the request body for a multi-turn conversation
+// with Claude models on AWS Bedrock
+// Args:
+//
+//	messages: []ai.Message - conversation history, oldest first, starting with a user turn
+//
+// Returns: anthropicRequest struct ready to be marshaled to JSON
+func buildMessagesBody(messages []ai.Message) anthropicRequest {
+	request := anthropicRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        4096,
+		Messages:         make([]anthropicMessage, len(messages)),
+	}
+	for i, msg := range messages {
+		request.Messages[i] = anthropicMessage{
+			Role:    msg.Role,
+			Content: msg.Content,
+		}
+	}
+	return request
+}
+
 // IsAvailable checks if Bedrock API is available
 // Returns: true if Bedrock is reachable, error if not
 func (bc *BedrockClient) IsAvailable() (bool, error) {
@@ -229,6 +252,20 @@ func (bc *BedrockClient) IsAvailable() (bool, error) {
 //
 // Returns: channel for streaming response chunks, error if request fails
 func (bc *BedrockClient) Generate(prompt string, model string, context []int, onTokenUsage func(apptypes.TokenUsage)) (<-chan string, error) {
+	return bc.GenerateContext(stdcontext.Background(), prompt, model, onTokenUsage)
+}
+
+// GenerateContext generates AI response with streaming until ctx is done.
+// Cancelling ctx aborts the event stream and closes the returned channel.
+// Args:
+//
+//	ctx: context.Context - cancellation and deadline for the request
+//	prompt: string - user's prompt to the AI
+//	model: string - model name to use (default: "us.anthropic.claude-haiku-4-5-v1:0")
+//	onTokenUsage: func(apptypes.TokenUsage) - optional callback to receive actual token usage from the API response
+//
+// Returns: channel for streaming response chunks, error if request fails
+func (bc *BedrockClient) GenerateContext(ctx stdcontext.Context, prompt string, model string, onTokenUsage func(apptypes.TokenUsage)) (<-chan string, error) {
 	// Default model to Claude Haiku 4.5 if empty
 	if model == "" {
 		model = "us.anthropic.claude-haiku-4-5-v1:0"
@@ -238,8 +275,31 @@ func (bc *BedrockClient) Generate(prompt string, model string, context []int, on
 	model = convertToInferenceProfile(model, bc.region)
 
 	// Construct request body using anthropicRequest struct
-	requestBody := buildRequestBody(prompt)
+	return bc.invokeStream(ctx, buildRequestBody(prompt), model, onTokenUsage)
+}
 
+// Chat generates AI response with streaming for a multi-turn conversation
+// until ctx is done
+// Args:
+//
+//	ctx: context.Context - cancellation and deadline for the request
+//	messages: []ai.Message - conversation history, oldest first
+//	model: string - model name to use (default: "us.anthropic.claude-haiku-4-5-v1:0")
+//	onTokenUsage: func(apptypes.TokenUsage) - optional callback to receive actual token usage from the API response
+//
+// Returns: channel for streaming response chunks, error if request fails
+func (bc *BedrockClient) Chat(ctx stdcontext.Context, messages []ai.Message, model string, onTokenUsage func(apptypes.TokenUsage)) (<-chan string, error) {
+	if model == "" {
+		model = "us.anthropic.claude-haiku-4-5-v1:0"
+	}
+	model = convertToInferenceProfile(model, bc.region)
+
+	return bc.invokeStream(ctx, buildMessagesBody(messages), model, onTokenUsage)
+}
+
+// invokeStream sends requestBody to InvokeModelWithResponseStream and streams
+// the response text through the returned channel
+func (bc *BedrockClient) invokeStream(ctx stdcontext.Context, requestBody anthropicRequest, model string, onTokenUsage func(apptypes.TokenUsage)) (<-chan string, error) {
 	// Marshal request body to JSON
 	requestBodyJSON, err := json.Marshal(requestBody)
 	if err != nil {
@@ -247,7 +307,6 @@ func (bc *BedrockClient) Generate(prompt string, model string, context []int, on
 	}
 
 	// Call InvokeModelWithResponseStream API
-	ctx := stdcontext.Background()
 	output, err := bc.client.InvokeModelWithResponseStream(ctx, &bedrockruntime.InvokeModelWithResponseStreamInput{
 		ModelId:     &model,
 		Body:        requestBodyJSON,
@@ -315,7 +374,11 @@ func (bc *BedrockClient) Generate(prompt string, model string, context []int, on
 					if delta, ok := response["delta"].(map[string]any); ok {
 						// Extract text from delta
 						if text, ok := delta["text"].(string); ok && text != "" {
-							responseChan <- text
+							select {
+							case responseChan <- text:
+							case <-ctx.Done():
+								return
+							}
 						}
 					}
 				}
@@ -349,7 +412,8 @@ func (bc *BedrockClient) Generate(prompt string, model string, context []int, on
 
 		// Check for stream errors after processing completes
 		// This catches errors that occurred during streaming but weren't event parsing errors
-		if processingErr == nil {
+		// Errors caused by cancelling ctx are expected and not reported
+		if processingErr == nil && ctx.Err() == nil {
 			if err := output.GetStream().Err(); err != nil {
 				formattedErr := formatAWSError(err, "streaming")
 				responseChan <- fmt.Sprintf("Stream error: %v", formattedErr)
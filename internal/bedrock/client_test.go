@@ -8,6 +8,7 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
+	"github.com/user/terminal-intelligence/internal/ai"
 )
 
 // TestNewBedrockClient_WithValidInputs tests constructor with valid API key and region
@@ -251,6 +252,30 @@ func TestBuildRequestBody_CorrectFormat(t *testing.T) {
 	}
 }
 
+// TestBuildMessagesBody_PreservesConversation tests that multi-turn history is
+// sent as alternating Anthropic messages in order
+func TestBuildMessagesBody_PreservesConversation(t *testing.T) {
+	messages := []ai.Message{
+		{Role: "user", Content: "My name is Ada."},
+		{Role: "assistant", Content: "Nice to meet you, Ada."},
+		{Role: "user", Content: "What is my name?"},
+	}
+
+	req := buildMessagesBody(messages)
+
+	if req.AnthropicVersion != "bedrock-2023-05-31" {
+		t.Errorf("Expected anthropic_version 'bedrock-2023-05-31', got '%s'", req.AnthropicVersion)
+	}
+	if len(req.Messages) != len(messages) {
+		t.Fatalf("Expected %d messages, got %d", len(messages), len(req.Messages))
+	}
+	for i, msg := range messages {
+		if req.Messages[i].Role != msg.Role || req.Messages[i].Content != msg.Content {
+			t.Errorf("Message %d: expected %+v, got %+v", i, msg, req.Messages[i])
+		}
+	}
+}
+
 // TestBuildRequestBody_JSONMarshaling tests that the request body can be marshaled to JSON
 // **Validates: Requirements 2.3**
 func TestBuildRequestBody_JSONMarshaling(t *testing.T) {
@@ -1463,3 +1488,51 @@ func TestConvertToInferenceProfile(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildToolsBody_ContentBlocks tests conversion of a tool conversation to Messages API blocks
+func TestBuildToolsBody_ContentBlocks(t *testing.T) {
+	body := buildToolsBody([]ai.ToolMessage{
+		{Role: "user", Content: "fix main.go"},
+		{Role: "assistant", Content: "Let me look.", ToolCalls: []ai.ToolCall{{ID: "toolu_1", Name: "read_file", Arguments: json.RawMessage(`{"path":"main.go"}`)}}},
+		{Role: "tool", Results: []ai.ToolResult{{CallID: "toolu_1", Name: "read_file", Content: "package main", IsError: false}}},
+	}, []ai.ToolSpec{{Name: "read_file", Description: "Read a file", Parameters: map[string]interface{}{"type": "object"}}})
+
+	if body.AnthropicVersion != "bedrock-2023-05-31" {
+		t.Errorf("unexpected anthropic_version: %s", body.AnthropicVersion)
+	}
+	if len(body.Tools) != 1 || body.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("unexpected tools: %+v", body.Tools)
+	}
+	if len(body.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(body.Messages))
+	}
+	assistant := body.Messages[1]
+	if len(assistant.Content) != 2 || assistant.Content[1].Type != "tool_use" || assistant.Content[1].ID != "toolu_1" {
+		t.Errorf("unexpected assistant blocks: %+v", assistant.Content)
+	}
+	result := body.Messages[2]
+	if result.Role != "user" || result.Content[0].Type != "tool_result" || result.Content[0].ToolUseID != "toolu_1" {
+		t.Errorf("expected tool_result in a user turn, got %+v", result)
+	}
+}
+
+// TestParseToolsResponse_ToolUse tests extraction of text, tool_use blocks and usage
+func TestParseToolsResponse_ToolUse(t *testing.T) {
+	reply, err := parseToolsResponse([]byte(`{"content": [{"type": "text", "text": "Checking."}, {"type": "tool_use", "id": "toolu_2", "name": "run_tests", "input": {}}], "usage": {"input_tokens": 20, "output_tokens": 6}}`))
+	if err != nil {
+		t.Fatalf("parseToolsResponse returned error: %v", err)
+	}
+	if reply.Content != "Checking." {
+		t.Errorf("unexpected content: %q", reply.Content)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].ID != "toolu_2" || reply.ToolCalls[0].Name != "run_tests" {
+		t.Fatalf("unexpected tool calls: %+v", reply.ToolCalls)
+	}
+	if reply.Usage.TotalTokens != 26 {
+		t.Errorf("unexpected token usage: %+v", reply.Usage)
+	}
+
+	if _, err := parseToolsResponse([]byte("not json")); err == nil {
+		t.Error("expected error for invalid response body")
+	}
+}
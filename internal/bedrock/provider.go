@@ -0,0 +1,25 @@
+package bedrock
+
+import "github.com/user/terminal-intelligence/internal/ai"
+
+func init() {
+	ai.RegisterProvider(ai.ProviderSpec{
+		Name:         "bedrock",
+		Description:  "Anthropic Claude models on AWS Bedrock",
+		ModelKey:     "bedrock_model",
+		DefaultModel: "us.anthropic.claude-haiku-4-5-v1:0",
+		Fields: []ai.ConfigField{
+			{Key: "bedrock_model", Description: "Bedrock model identifier"},
+			{Key: "bedrock_api", Description: "AWS credentials as ACCESS_KEY_ID:SECRET_ACCESS_KEY", Secret: true, Required: true},
+			{Key: "bedrock_region", Description: "AWS region", Default: "us-east-1"},
+		},
+		Capabilities: ai.Capabilities{Chat: true, Cancel: true, Tools: true},
+		New: func(settings ai.Settings) (ai.AIClient, error) {
+			client, err := NewBedrockClient(settings.Get("bedrock_api"), settings.Get("bedrock_region"))
+			if err != nil {
+				return nil, err
+			}
+			return client, nil
+		},
+	})
+}
@@ -0,0 +1,149 @@
+package bedrock
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/user/terminal-intelligence/internal/ai"
+	apptypes "github.com/user/terminal-intelligence/internal/types"
+)
+
+// anthropicTool describes a tool in the Anthropic Messages API format
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// contentBlock is a typed block of message content: text, a tool_use request
+// from the model or a tool_result sent back to it
+type contentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// blockMessage is a message whose content is a list of blocks
+type blockMessage struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+// toolsRequest is a Messages API request with tools
+type toolsRequest struct {
+	AnthropicVersion string          `json:"anthropic_version"`
+	MaxTokens        int             `json:"max_tokens"`
+	Messages         []blockMessage  `json:"messages"`
+	Tools            []anthropicTool `json:"tools"`
+}
+
+// toolsResponse is the (non-streaming) Messages API response
+type toolsResponse struct {
+	Content []contentBlock `json:"content"`
+	Usage   struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// buildToolsBody constructs a Messages API request for a tool-using
+// conversation. Tool results are sent as tool_result blocks in a user turn.
+func buildToolsBody(messages []ai.ToolMessage, tools []ai.ToolSpec) toolsRequest {
+	request := toolsRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        4096,
+	}
+	for _, t := range tools {
+		request.Tools = append(request.Tools, anthropicTool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters})
+	}
+
+	for _, msg := range messages {
+		m := blockMessage{Role: msg.Role}
+		if msg.Role == "tool" {
+			m.Role = "user"
+		}
+		if msg.Content != "" {
+			m.Content = append(m.Content, contentBlock{Type: "text", Text: msg.Content})
+		}
+		for _, call := range msg.ToolCalls {
+			m.Content = append(m.Content, contentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: call.Arguments})
+		}
+		for _, result := range msg.Results {
+			m.Content = append(m.Content, contentBlock{Type: "tool_result", ToolUseID: result.CallID, Content: result.Content, IsError: result.IsError})
+		}
+		if len(m.Content) > 0 {
+			request.Messages = append(request.Messages, m)
+		}
+	}
+	return request
+}
+
+// parseToolsResponse converts a Messages API response body into an ai.ToolReply
+func parseToolsResponse(body []byte) (*ai.ToolReply, error) {
+	var response toolsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	reply := &ai.ToolReply{
+		Usage: apptypes.TokenUsage{
+			InputTokens:  response.Usage.InputTokens,
+			OutputTokens: response.Usage.OutputTokens,
+			TotalTokens:  response.Usage.InputTokens + response.Usage.OutputTokens,
+		},
+	}
+	for _, block := range response.Content {
+		switch block.Type {
+		case "text":
+			reply.Content += block.Text
+		case "tool_use":
+			input := block.Input
+			if len(input) == 0 {
+				input = json.RawMessage("{}")
+			}
+			reply.ToolCalls = append(reply.ToolCalls, ai.ToolCall{ID: block.ID, Name: block.Name, Arguments: input})
+		}
+	}
+	return reply, nil
+}
+
+// ChatTools sends a tool-using conversation to InvokeModel with native tool
+// definitions (Anthropic models) and returns the model's reply
+// Args:
+//
+//	ctx: context.Context - cancellation and deadline for the request
+//	messages: []ai.ToolMessage - conversation, including earlier tool calls and results
+//	tools: []ai.ToolSpec - tools the model may call
+//	model: string - model name to use (default: "us.anthropic.claude-haiku-4-5-v1:0")
+//
+// Returns: the model's reply, error if request fails
+func (bc *BedrockClient) ChatTools(ctx stdcontext.Context, messages []ai.ToolMessage, tools []ai.ToolSpec, model string) (*ai.ToolReply, error) {
+	if model == "" {
+		model = "us.anthropic.claude-haiku-4-5-v1:0"
+	}
+	model = convertToInferenceProfile(model, bc.region)
+
+	requestBodyJSON, err := json.Marshal(buildToolsBody(messages, tools))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	output, err := bc.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     &model,
+		Body:        requestBodyJSON,
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return nil, formatAWSError(err, "ChatTools")
+	}
+
+	return parseToolsResponse(output.Body)
+}
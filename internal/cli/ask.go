@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// askResult is the JSON output of ti ask
+type askResult struct {
+	Response string      `json:"response"`
+	Provider string      `json:"provider"`
+	Model    string      `json:"model"`
+	Tokens   tokenCounts `json:"tokens"`
+}
+
+// runAsk sends a single question to the AI and prints the answer, streaming
+// it to stdout unless JSON output was requested
+func runAsk(e *env, args []string) int {
+	var common commonFlags
+	fs := newFlagSet(e, &common)
+	file := fs.String("file", "", "include the content of this file as context")
+	if ok, code := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	question := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if question == "" {
+		data, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return fail(e, fmt.Errorf("failed to read question: %w", err))
+		}
+		question = strings.TrimSpace(string(data))
+	}
+	if question == "" {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	s, err := newSession(&common)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	msg := types.ChatMessage{Role: "user", Content: question, Timestamp: time.Now()}
+	if *file != "" {
+		path := *file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.cfg.WorkspaceDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fail(e, fmt.Errorf("failed to read %s: %w", *file, err))
+		}
+		msg.ContextContent = string(content)
+		msg.ContextIncluded = true
+	}
+
+	var usage types.TokenUsage
+	history := ai.BuildHistory([]types.ChatMessage{msg}, ai.DefaultHistoryTokenBudget)
+	ch, err := ai.Converse(e.ctx, s.client, history, s.cfg.DefaultModel, func(u types.TokenUsage) { usage = u })
+	if err != nil {
+		return fail(e, err)
+	}
+
+	var onChunk func(string)
+	if !common.json {
+		onChunk = func(chunk string) { fmt.Fprint(e.stdout, chunk) }
+	}
+	response, err := ai.CollectFunc(e.ctx, ch, onChunk)
+	if !common.json && response != "" && !strings.HasSuffix(response, "\n") {
+		fmt.Fprintln(e.stdout)
+	}
+	if err != nil {
+		return fail(e, err)
+	}
+
+	if common.json {
+		if err := writeJSON(e.stdout, askResult{
+			Response: response,
+			Provider: s.cfg.Provider,
+			Model:    s.cfg.DefaultModel,
+			Tokens:   tokenCounts{Input: usage.InputTokens, Output: usage.OutputTokens, Total: usage.TotalTokens},
+		}); err != nil {
+			return fail(e, err)
+		}
+	}
+	return ExitOK
+}
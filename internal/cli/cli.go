@@ -0,0 +1,238 @@
+// Package cli implements the non-interactive ti subcommands (ask, fix, create
+// and doc). They drive the same engines as the TUI but print plain text or
+// JSON to stdout, log progress to stderr and report the outcome through the
+// process exit code.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/config"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK        = 0
+	ExitFailure   = 1
+	ExitUsage     = 2
+	ExitCancelled = 130
+)
+
+// env carries the standard streams and cancellation context of one invocation
+type env struct {
+	cmd    command
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a single subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(e *env, args []string) int
+}
+
+var commands = []command{
+	{"ask", "ask [flags] [question]", "Ask a question; reads the question from stdin when none is given", runAsk},
+	{"fix", "fix [flags] <request>", "Fix or change the project with the agentic fixer", runFix},
+	{"create", "create [flags] <description>", "Create a new application from a description", runCreate},
+	{"doc", "doc [flags] <request>", "Generate documentation, e.g. \"ti doc api\"", runDoc},
+}
+
+// IsCommand reports whether name is a CLI subcommand
+func IsCommand(name string) bool {
+	_, ok := lookupCommand(name)
+	return ok
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit
+// code. Cancelling ctx stops the running engine and returns ExitCancelled.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return ExitUsage
+	}
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "Unknown command: %s\n\n", args[0])
+		printUsage(stderr)
+		return ExitUsage
+	}
+	return cmd.run(&env{cmd: cmd, ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}, args[1:])
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ti <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run \"ti <command> -h\" for the flags of a command.")
+	fmt.Fprintln(w, "Without a command ti starts the interactive editor.")
+}
+
+// commonFlags are accepted by every subcommand
+type commonFlags struct {
+	provider  string
+	model     string
+	workspace string
+	json      bool
+}
+
+// newFlagSet creates the flag set of the running command with the common
+// flags registered
+func newFlagSet(e *env, common *commonFlags) *flag.FlagSet {
+	cmd := e.cmd
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: ti %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&common.provider, "provider", "", "AI provider to use instead of the configured one")
+	fs.StringVar(&common.model, "model", "", "model to use instead of the provider's configured model")
+	fs.StringVar(&common.workspace, "workspace", "", "project directory (default: current directory)")
+	fs.BoolVar(&common.json, "json", false, "print the result as JSON")
+	return fs
+}
+
+// parseFlags parses args into fs. It returns false with the exit code to use
+// when parsing failed or help was requested.
+func parseFlags(fs *flag.FlagSet, args []string) (bool, int) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, ExitOK
+		}
+		return false, ExitUsage
+	}
+	return true, ExitOK
+}
+
+// session is the resolved configuration and AI client of one invocation
+type session struct {
+	cfg    *types.AppConfig
+	client ai.AIClient
+}
+
+// newSession loads ~/.ti/config.json (without creating or updating it),
+// applies the command-line overrides and builds the AI client
+func newSession(common *commonFlags) (*session, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if common.provider != "" {
+		spec, ok := ai.LookupProvider(common.provider)
+		if !ok {
+			return nil, fmt.Errorf("unknown provider %q (available: %s)", common.provider, strings.Join(ai.ProviderNames(), ", "))
+		}
+		cfg.Provider = spec.Name
+		cfg.DefaultModel = spec.Model(config.ProviderSettings(cfg))
+	}
+	if common.model != "" {
+		cfg.DefaultModel = common.model
+	}
+
+	workspace := common.workspace
+	if workspace == "" {
+		if workspace, err = os.Getwd(); err != nil {
+			return nil, fmt.Errorf("failed to determine current directory: %w", err)
+		}
+	}
+	if workspace, err = filepath.Abs(workspace); err != nil {
+		return nil, fmt.Errorf("invalid workspace: %w", err)
+	}
+	if info, err := os.Stat(workspace); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("workspace is not a directory: %s", workspace)
+	}
+	cfg.WorkspaceDir = workspace
+
+	client, err := ai.NewClient(cfg.Provider, config.ProviderSettings(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return &session{cfg: cfg, client: client}, nil
+}
+
+// loadConfig returns the default configuration merged with the config file,
+// if one exists
+func loadConfig() (*types.AppConfig, error) {
+	cfg := types.DefaultConfig()
+
+	configPath, err := config.ConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+
+	jcfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	if err := config.Validate(jcfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	config.ApplyToAppConfig(jcfg, cfg)
+	return cfg, nil
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// tokenCounts is the token usage reported in JSON output
+type tokenCounts struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+	Total  int `json:"total"`
+}
+
+// fail prints err to stderr and returns the matching exit code
+func fail(e *env, err error) int {
+	if ai.IsCancelled(err) {
+		fmt.Fprintln(e.stderr, "Cancelled")
+		return ExitCancelled
+	}
+	fmt.Fprintf(e.stderr, "Error: %v\n", err)
+	return ExitFailure
+}
+
+// stderrLogger returns a notify function that prints log lines to stderr
+func stderrLogger(e *env) func(string) {
+	return func(msg string) {
+		fmt.Fprintln(e.stderr, msg)
+	}
+}
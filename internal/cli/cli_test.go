@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// stubClient answers every prompt with a fixed response
+type stubClient struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+var stubResponse = "Project Name: demo\n\nThe answer is 42."
+
+func (c *stubClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *stubClient) ListModels() ([]string, error) { return []string{"stub-model"}, nil }
+
+func (c *stubClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, prompt)
+	c.mu.Unlock()
+	if onTokenUsage != nil {
+		onTokenUsage(types.TokenUsage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10})
+	}
+	ch := make(chan string, 1)
+	ch <- stubResponse
+	close(ch)
+	return ch, nil
+}
+
+// lastStub is the client most recently built by the clitest provider
+var lastStub *stubClient
+
+func init() {
+	ai.RegisterProvider(ai.ProviderSpec{
+		Name:         "clitest",
+		ModelKey:     "clitest_model",
+		DefaultModel: "stub-model",
+		Fields:       []ai.ConfigField{{Key: "clitest_model", Description: "Model name"}},
+		New: func(settings ai.Settings) (ai.AIClient, error) {
+			lastStub = &stubClient{}
+			return lastStub, nil
+		},
+	})
+}
+
+// runCLI runs args with an isolated home directory and returns the exit code,
+// stdout and stderr
+func runCLI(t *testing.T, ctx context.Context, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	var stdout, stderr bytes.Buffer
+	code := Run(ctx, args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	if code, _, stderr := runCLI(t, context.Background(), ""); code != ExitUsage || !strings.Contains(stderr, "Commands:") {
+		t.Errorf("no command: code=%d stderr=%q", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, context.Background(), "", "frobnicate"); code != ExitUsage || !strings.Contains(stderr, "Unknown command: frobnicate") {
+		t.Errorf("unknown command: code=%d stderr=%q", code, stderr)
+	}
+	if code, _, _ := runCLI(t, context.Background(), "", "ask", "--bogus"); code != ExitUsage {
+		t.Errorf("unknown flag: code=%d, want %d", code, ExitUsage)
+	}
+	if code, _, stderr := runCLI(t, context.Background(), "", "fix", "-h"); code != ExitOK || !strings.Contains(stderr, "-max-attempts") {
+		t.Errorf("help: code=%d stderr=%q", code, stderr)
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for _, name := range []string{"ask", "fix", "create", "doc"} {
+		if !IsCommand(name) {
+			t.Errorf("IsCommand(%q) = false", name)
+		}
+	}
+	if IsCommand("--version") {
+		t.Error("IsCommand(--version) = true")
+	}
+}
+
+func TestAsk_JSON(t *testing.T) {
+	workspace := t.TempDir()
+	code, stdout, stderr := runCLI(t, context.Background(), "", "ask", "--provider", "clitest", "--workspace", workspace, "--json", "what", "is", "it?")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+
+	var result askResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if result.Response != stubResponse || result.Provider != "clitest" || result.Model != "stub-model" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Tokens.Total != 10 {
+		t.Errorf("expected token usage to be reported, got %+v", result.Tokens)
+	}
+	if len(lastStub.prompts) != 1 || !strings.Contains(lastStub.prompts[0], "what is it?") {
+		t.Errorf("question not sent: %q", lastStub.prompts)
+	}
+}
+
+func TestAsk_StdinAndFileContext(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main // marker"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI(t, context.Background(), "explain this\n",
+		"ask", "--provider", "clitest", "--model", "other-model", "--workspace", workspace, "--file", "main.go")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if stdout != stubResponse+"\n" {
+		t.Errorf("unexpected output: %q", stdout)
+	}
+	prompt := lastStub.prompts[0]
+	if !strings.Contains(prompt, "explain this") || !strings.Contains(prompt, "package main // marker") {
+		t.Errorf("prompt missing question or file context: %q", prompt)
+	}
+}
+
+func TestAsk_Errors(t *testing.T) {
+	if code, _, stderr := runCLI(t, context.Background(), "", "ask", "--provider", "nope", "hi"); code != ExitFailure || !strings.Contains(stderr, `unknown provider "nope"`) {
+		t.Errorf("unknown provider: code=%d stderr=%q", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, context.Background(), "", "ask", "--provider", "clitest", "--workspace", "/does/not/exist", "hi"); code != ExitFailure || !strings.Contains(stderr, "workspace") {
+		t.Errorf("missing workspace: code=%d stderr=%q", code, stderr)
+	}
+	if code, _, _ := runCLI(t, context.Background(), "", "ask", "--provider", "clitest"); code != ExitUsage {
+		t.Errorf("empty question: code=%d, want %d", code, ExitUsage)
+	}
+}
+
+func TestFix_ExitCodeFollowsSuccess(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stub never names a relevant file, so the session fails
+	code, stdout, stderr := runCLI(t, context.Background(), "", "fix", "--provider", "clitest", "--workspace", workspace, "--json", "--project", "rename main")
+	if code != ExitFailure {
+		t.Fatalf("exit code %d, want %d; stderr: %s", code, ExitFailure, stderr)
+	}
+
+	var result fixResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if result.Success || result.Cancelled || !strings.Contains(result.Error, "no relevant files") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.FilesModified == nil {
+		t.Error("files_modified should be an empty list, not null")
+	}
+	if !strings.Contains(stderr, "Fix session started: rename main") {
+		t.Errorf("fixer log not written to stderr: %q", stderr)
+	}
+}
+
+func TestCreate_DeclinedPlanAborts(t *testing.T) {
+	workspace := t.TempDir()
+	code, stdout, stderr := runCLI(t, context.Background(), "n\n", "create", "--provider", "clitest", "--workspace", workspace, "a demo app")
+	if code != ExitFailure {
+		t.Fatalf("exit code %d, want %d; stderr: %s", code, ExitFailure, stderr)
+	}
+	if !strings.Contains(stdout, "Plan generated") || !strings.Contains(stderr, "Proceed with this plan?") {
+		t.Errorf("plan not shown before asking: stdout=%q stderr=%q", stdout, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "demo")); !os.IsNotExist(err) {
+		t.Errorf("project directory created without approval")
+	}
+}
+
+func TestDoc_WritesAndSkipsExisting(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI(t, context.Background(), "", "doc", "--provider", "clitest", "--workspace", workspace, "api")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	path := filepath.Join(workspace, "API_REFERENCE.md")
+	if !strings.Contains(stdout, "Wrote "+path) {
+		t.Errorf("unexpected output: %q", stdout)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("documentation not written: %v", err)
+	}
+
+	code, stdout, _ = runCLI(t, context.Background(), "", "doc", "--provider", "clitest", "--workspace", workspace, "--json", "api")
+	if code != ExitOK {
+		t.Fatalf("second run exit code %d", code)
+	}
+	var result docResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Written || !result.Files[0].Existed {
+		t.Errorf("expected existing file to be skipped, got %+v", result.Files)
+	}
+}
+
+func TestDoc_ReportsWhyFilesWereNotWritten(t *testing.T) {
+	workspace := t.TempDir()
+	// A directory in the way makes the write fail even with --overwrite
+	if err := os.Mkdir(filepath.Join(workspace, "API_REFERENCE.md"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := runCLI(t, context.Background(), "", "doc", "--provider", "clitest", "--workspace", workspace, "--overwrite", "api")
+	if code != ExitFailure {
+		t.Errorf("expected ExitFailure, got %d", code)
+	}
+	path := filepath.Join(workspace, "API_REFERENCE.md")
+	if !strings.Contains(stdout, "Failed "+path+": failed to write file") || strings.Contains(stdout, "already exists") {
+		t.Errorf("expected the write error, got %q", stdout)
+	}
+
+	code, stdout, _ = runCLI(t, context.Background(), "", "doc", "--provider", "clitest", "--workspace", workspace, "--overwrite", "--json", "api")
+	if code != ExitFailure {
+		t.Errorf("expected ExitFailure with --json, got %d", code)
+	}
+	var result docResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil || len(result.Files) != 1 || result.Files[0].Error == "" {
+		t.Errorf("expected the write error in the JSON, got %q (%v)", stdout, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if code, _, stderr := runCLI(t, ctx, "", "doc", "--provider", "clitest", "--workspace", workspace, "api"); code != ExitCancelled {
+		t.Errorf("expected ExitCancelled, got %d (stderr %q)", code, stderr)
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/agentic"
+)
+
+// createResult is the JSON output of ti create
+type createResult struct {
+	Success    bool        `json:"success"`
+	Project    string      `json:"project"`
+	ProjectDir string      `json:"project_dir"`
+	Plan       string      `json:"plan"`
+	Steps      []string    `json:"steps"`
+	ServerURL  string      `json:"server_url,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Tokens     tokenCounts `json:"tokens"`
+}
+
+// runCreate drives an AutonomousCreator from planning to completion. The plan
+// must be approved on stdin unless --yes is given.
+func runCreate(e *env, args []string) int {
+	var common commonFlags
+	fs := newFlagSet(e, &common)
+	yes := fs.Bool("yes", false, "approve the generated plan without asking")
+	if ok, code := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	description := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if description == "" {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	s, err := newSession(&common)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	logger := agentic.NewActionLogger(stderrLogger(e))
+	fixer := agentic.NewAgenticProjectFixer(s.client, s.cfg.DefaultModel, logger)
+	creator := agentic.NewAutonomousCreator(s.client, s.cfg.DefaultModel, s.cfg.WorkspaceDir, description, fixer, logger)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-e.ctx.Done():
+			creator.Cancel()
+		case <-stop:
+		}
+	}()
+
+	out := createResult{Steps: []string{}}
+	finish := func(code int, stepErr error) int {
+		out.Project = creator.ProjectName
+		out.ProjectDir = creator.ProjectDir
+		out.Plan = creator.Plan
+		out.ServerURL = creator.ServerURL
+		out.Tokens = tokenCounts{Input: creator.InputTokens, Output: creator.OutputTokens, Total: creator.TotalTokens}
+		out.Success = code == ExitOK
+		if stepErr != nil {
+			out.Error = stepErr.Error()
+		}
+		if common.json {
+			if err := writeJSON(e.stdout, out); err != nil {
+				return fail(e, err)
+			}
+		}
+		if stepErr != nil {
+			return fail(e, stepErr)
+		}
+		return code
+	}
+
+	approval := bufio.NewReader(e.stdin)
+	for creator.State != agentic.StateDone {
+		if creator.State == agentic.StateWaitingApproval {
+			if !*yes && common.json {
+				// stdout is reserved for the JSON result
+				fmt.Fprintf(e.stderr, "%s\n\n", creator.Plan)
+			}
+			if !*yes && !confirm(e, approval, "Proceed with this plan? [y/N] ") {
+				fmt.Fprintln(e.stderr, "Aborted")
+				return finish(ExitFailure, nil)
+			}
+			creator.State = agentic.StateSetup
+			continue
+		}
+
+		msg, err := creator.Step()
+		if err != nil {
+			return finish(ExitFailure, err)
+		}
+		if common.json {
+			out.Steps = append(out.Steps, msg)
+		} else {
+			fmt.Fprintln(e.stdout, msg)
+			fmt.Fprintln(e.stdout)
+		}
+	}
+	return finish(ExitOK, nil)
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from r. End
+// of input counts as no.
+func confirm(e *env, r *bufio.Reader, question string) bool {
+	fmt.Fprint(e.stderr, question)
+	answer, err := r.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(e.stderr)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/docgen"
+)
+
+// docFile is one document in the JSON output of ti doc
+type docFile struct {
+	Filename string `json:"filename"`
+	Path     string `json:"path"`
+	Existed  bool   `json:"existed"`
+	Written  bool   `json:"written"`
+	Error    string `json:"error,omitempty"` // Why the file was not written
+}
+
+// docResult is the JSON output of ti doc
+type docResult struct {
+	Files []docFile `json:"files"`
+}
+
+// stderrChatPane is the docgen chat pane of the CLI: notifications go to
+// stderr and there is no editor to open files in
+type stderrChatPane struct {
+	e *env
+}
+
+func (p *stderrChatPane) DisplayNotification(notification string) {
+	fmt.Fprintln(p.e.stderr, notification)
+}
+
+func (p *stderrChatPane) OpenFileInEditor(filePath string) error {
+	return nil
+}
+
+// runDoc generates documentation through the docgen pipeline, e.g.
+// "ti doc api" or "ti doc user manual for the parser"
+func runDoc(e *env, args []string) int {
+	var common commonFlags
+	fs := newFlagSet(e, &common)
+	overwrite := fs.Bool("overwrite", false, "replace documentation files that already exist")
+	if ok, code := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	request := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if request == "" {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	s, err := newSession(&common)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	pipeline := docgen.NewPipeline(s.cfg.WorkspaceDir, s.client, s.cfg.DefaultModel, &stderrChatPane{e: e})
+	results, err := pipeline.Generate(e.ctx, "/doc "+request, *overwrite)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	// Files skipped because they exist are not failures
+	code := ExitOK
+	out := docResult{Files: []docFile{}}
+	for _, r := range results {
+		f := docFile{Filename: r.Filename, Path: r.Path, Existed: r.Existed, Written: r.Written}
+		if r.Err != nil {
+			f.Error = r.Err.Error()
+			if !errors.Is(r.Err, docgen.ErrFileExists) {
+				code = ExitFailure
+			}
+		}
+		out.Files = append(out.Files, f)
+	}
+
+	if common.json {
+		if err := writeJSON(e.stdout, out); err != nil {
+			return fail(e, err)
+		}
+	} else {
+		for _, r := range results {
+			switch {
+			case r.Written:
+				fmt.Fprintf(e.stdout, "Wrote %s\n", r.Path)
+			case errors.Is(r.Err, docgen.ErrFileExists):
+				fmt.Fprintf(e.stdout, "Skipped %s (already exists; use --overwrite)\n", r.Path)
+			default:
+				fmt.Fprintf(e.stdout, "Failed %s: %v\n", r.Path, r.Err)
+			}
+		}
+	}
+	return code
+}
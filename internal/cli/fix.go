@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/agentic"
+)
+
+// fixResult is the JSON output of ti fix
+type fixResult struct {
+	Success       bool        `json:"success"`
+	Cancelled     bool        `json:"cancelled"`
+	Attempts      int         `json:"attempts"`
+	Cycles        int         `json:"cycles"`
+	Error         string      `json:"error,omitempty"`
+	FilesModified []string    `json:"files_modified"`
+	Tokens        tokenCounts `json:"tokens"`
+}
+
+// runFix runs the agentic project fixer on a request. The exit code follows
+// FixSessionResult.Success.
+func runFix(e *env, args []string) int {
+	var common commonFlags
+	fs := newFlagSet(e, &common)
+	fs.Bool("project", true, "fix across the whole project (the only mode; accepted for symmetry with /fix /project)")
+	file := fs.String("file", "", "file the request is about, ranked first when choosing what to edit")
+	maxAttempts := fs.Int("max-attempts", 9, "maximum number of fix attempts")
+	maxCycles := fs.Int("max-cycles", 3, "maximum number of reset cycles")
+	if ok, code := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	message := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if message == "" {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	s, err := newSession(&common)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	request := &agentic.FixSessionRequest{
+		Message:     message,
+		ProjectRoot: s.cfg.WorkspaceDir,
+		MaxAttempts: *maxAttempts,
+		MaxCycles:   *maxCycles,
+	}
+	if *file != "" {
+		request.OpenFilePath = *file
+		if !filepath.IsAbs(*file) {
+			request.OpenFilePath = filepath.Join(s.cfg.WorkspaceDir, *file)
+		}
+	}
+
+	logger := agentic.NewActionLogger(stderrLogger(e))
+	fixer := agentic.NewAgenticProjectFixer(s.client, s.cfg.DefaultModel, logger)
+	result, err := fixer.ProcessFixCommandContext(e.ctx, request, nil)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	out := fixResult{
+		Success:       result.Success,
+		Cancelled:     result.Cancelled,
+		Attempts:      result.TotalAttempts,
+		Cycles:        result.TotalCycles,
+		Error:         result.ErrorMessage,
+		FilesModified: []string{},
+		Tokens:        tokenCounts{Input: result.InputTokens, Output: result.OutputTokens, Total: result.TotalTokens},
+	}
+	if result.FinalReport != nil {
+		for _, f := range result.FinalReport.FilesModified {
+			out.FilesModified = append(out.FilesModified, f.RelPath)
+		}
+	}
+
+	if common.json {
+		if err := writeJSON(e.stdout, out); err != nil {
+			return fail(e, err)
+		}
+	} else {
+		printFixSummary(e, out)
+	}
+
+	switch {
+	case result.Cancelled:
+		return ExitCancelled
+	case result.Success:
+		return ExitOK
+	default:
+		return ExitFailure
+	}
+}
+
+// printFixSummary prints a plain-text summary of a fix session
+func printFixSummary(e *env, out fixResult) {
+	switch {
+	case out.Cancelled:
+		fmt.Fprintln(e.stdout, "Fix cancelled; modified files were restored.")
+	case out.Success:
+		fmt.Fprintf(e.stdout, "Fix succeeded after %d attempt(s).\n", out.Attempts)
+	default:
+		fmt.Fprintf(e.stdout, "Fix failed after %d attempt(s) in %d cycle(s).\n", out.Attempts, out.Cycles)
+	}
+	if out.Error != "" {
+		fmt.Fprintf(e.stdout, "Error: %s\n", out.Error)
+	}
+	if len(out.FilesModified) > 0 {
+		fmt.Fprintln(e.stdout, "Files modified:")
+		for _, f := range out.FilesModified {
+			fmt.Fprintf(e.stdout, "  %s\n", f)
+		}
+	}
+	fmt.Fprintf(e.stdout, "Tokens: %d input, %d output, %d total\n", out.Tokens.Input, out.Tokens.Output, out.Tokens.Total)
+}
@@ -0,0 +1,179 @@
+// Package commitmsg suggests commit messages for staged changes by sending
+// their diff to the configured AI model with a Conventional Commits prompt.
+package commitmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/diff"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// ErrNothingStaged is returned when there are no staged changes to describe.
+var ErrNothingStaged = errors.New("nothing is staged; stage changes before asking for a commit message")
+
+// instructions asks for a Conventional Commits message; the changed files
+// and their diff follow it in the prompt.
+const instructions = `Write a git commit message for the staged changes below.
+
+Use the Conventional Commits format:
+<type>(<optional scope>): <subject>
+
+<optional body>
+
+Rules:
+- type is one of feat, fix, docs, style, refactor, perf, test, build, ci, chore
+- the subject is in the imperative mood, at most 72 characters, without a trailing period
+- add a body only if the change needs explaining; wrap it at 72 characters
+- describe what changed and why, not how the diff looks
+
+Reply with the commit message only, without code fences, quotes or commentary.
+`
+
+// BuildPrompt returns the prompt asking for a commit message for files. The
+// diff section holds at most about budget tokens: files are included in
+// order until the budget is used up, the last one possibly cut short, and
+// the remaining files are only listed.
+func BuildPrompt(files []git.FileDiff, budget int) string {
+	var sb strings.Builder
+	sb.WriteString(instructions)
+
+	sb.WriteString("\nChanged files:\n")
+	for _, f := range files {
+		added, deleted := countLines(f.Hunks)
+		if f.Binary {
+			sb.WriteString(fmt.Sprintf("  %s %s (binary)\n", f.Action, f.Path))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s %s (+%d -%d)\n", f.Action, f.Path, added, deleted))
+		}
+	}
+
+	sb.WriteString("\nDiff:\n")
+	sb.WriteString(truncateDiff(files, budget))
+	return sb.String()
+}
+
+// truncateDiff renders the unified diffs of files within about budget tokens.
+func truncateDiff(files []git.FileDiff, budget int) string {
+	// The diff lines of all files, and the index of the file of each
+	var lines []string
+	var owners []int
+	for i, f := range files {
+		if f.Binary || len(f.Hunks) == 0 {
+			continue
+		}
+		for _, line := range strings.SplitAfter(diff.Unified("a/"+f.Path, "b/"+f.Path, f.Hunks), "\n") {
+			lines = append(lines, line)
+			owners = append(owners, i)
+		}
+	}
+
+	n := ai.FitBudget(lines, budget)
+	var sb strings.Builder
+	for _, line := range lines[:n] {
+		sb.WriteString(line)
+	}
+	if n < len(lines) {
+		cut := owners[n]
+		rest := 0
+		for j := n; j < len(lines) && owners[j] == cut; j++ {
+			rest++
+		}
+		sb.WriteString(fmt.Sprintf("[... %d more lines of %s truncated]\n", rest, files[cut].Path))
+		if omitted := countDiffs(files[cut+1:]); omitted > 0 {
+			sb.WriteString(fmt.Sprintf("[... diffs of %d more files omitted]\n", omitted))
+		}
+	}
+	return sb.String()
+}
+
+// countDiffs returns the number of files that have a text diff.
+func countDiffs(files []git.FileDiff) int {
+	n := 0
+	for _, f := range files {
+		if !f.Binary && len(f.Hunks) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// countLines returns the number of added and deleted lines in hunks.
+func countLines(hunks []diff.Hunk) (added, deleted int) {
+	for _, h := range hunks {
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case diff.Insert:
+				added++
+			case diff.Delete:
+				deleted++
+			}
+		}
+	}
+	return added, deleted
+}
+
+// Generate asks the model for a commit message describing files and returns
+// it cleaned up. ErrNothingStaged is returned when files is empty.
+func Generate(ctx context.Context, client ai.AIClient, model string, files []git.FileDiff, budget int) (string, error) {
+	if len(files) == 0 {
+		return "", ErrNothingStaged
+	}
+	return ai.GenerateText(ctx, client, model, BuildPrompt(files, budget), Clean, "commit message")
+}
+
+// Clean extracts the commit message from a model reply: besides the fence
+// and whitespace ai.CleanReply removes, a fenced block inside the reply,
+// surrounding quotes and a leading "Commit message:" label are removed, and
+// blank lines are collapsed.
+func Clean(reply string) string {
+	text := ai.CleanReply(reply)
+
+	// Keep only the content of a fenced block
+	if start := strings.Index(text, "```"); start >= 0 {
+		rest := text[start+3:]
+		if nl := strings.Index(rest, "\n"); nl >= 0 {
+			rest = rest[nl+1:]
+		}
+		if end := strings.Index(rest, "```"); end >= 0 {
+			rest = rest[:end]
+		}
+		text = strings.TrimSpace(rest)
+	}
+
+	for _, label := range []string{"commit message:", "message:"} {
+		if len(text) >= len(label) && strings.EqualFold(text[:len(label)], label) {
+			text = strings.TrimSpace(text[len(label):])
+		}
+	}
+	for _, quote := range []string{`"`, "'", "`"} {
+		if len(text) >= 2 && strings.HasPrefix(text, quote) && strings.HasSuffix(text, quote) {
+			text = strings.TrimSpace(text[1 : len(text)-1])
+		}
+	}
+
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Split separates a commit message into its subject line and body.
+func Split(message string) (subject, body string) {
+	subject, body, _ = strings.Cut(message, "\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body)
+}
@@ -0,0 +1,157 @@
+package commitmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/diff"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// mockClient is an AIClient that records the prompt and replies with a fixed
+// response.
+type mockClient struct {
+	reply  string
+	err    error
+	prompt string
+}
+
+func (m *mockClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	m.prompt = prompt
+	if m.err != nil {
+		return nil, m.err
+	}
+	ch := make(chan string, 1)
+	ch <- m.reply
+	close(ch)
+	return ch, nil
+}
+
+func (m *mockClient) IsAvailable() (bool, error)    { return true, nil }
+func (m *mockClient) ListModels() ([]string, error) { return nil, nil }
+
+// modifiedFile returns a FileDiff of path changing old into new.
+func modifiedFile(path, old, new string) git.FileDiff {
+	return git.FileDiff{Path: path, Action: "modified", Hunks: diff.Compute(old, new, 3)}
+}
+
+func TestBuildPrompt_IncludesFilesAndDiff(t *testing.T) {
+	files := []git.FileDiff{
+		modifiedFile("main.go", "a\nb\n", "a\nc\nd\n"),
+		{Path: "logo.png", Action: "added", Binary: true},
+	}
+	prompt := BuildPrompt(files, ai.DefaultPromptTokenBudget)
+
+	for _, want := range []string{
+		"Conventional Commits",
+		"modified main.go (+2 -1)",
+		"added logo.png (binary)",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"-b\n",
+		"+c\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "truncated") {
+		t.Error("a small diff should not be truncated")
+	}
+}
+
+func TestBuildPrompt_TruncatesLargeDiffs(t *testing.T) {
+	var old, new strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&old, "line %d\n", i)
+		fmt.Fprintf(&new, "changed line %d\n", i)
+	}
+	files := []git.FileDiff{
+		modifiedFile("big.txt", old.String(), new.String()),
+		modifiedFile("small.txt", "x\n", "y\n"),
+	}
+
+	prompt := BuildPrompt(files, 200)
+	if !strings.Contains(prompt, "more lines of big.txt truncated") {
+		t.Error("expected the large diff to be truncated")
+	}
+	if !strings.Contains(prompt, "diffs of 1 more files omitted") {
+		t.Error("expected the following diff to be omitted")
+	}
+	if !strings.Contains(prompt, "modified small.txt (+1 -1)") {
+		t.Error("omitted files should still be listed")
+	}
+	if strings.Contains(prompt, "+++ b/small.txt") {
+		t.Error("the omitted diff should not be in the prompt")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	client := &mockClient{reply: "```\nfeat: add greeting\n\nSay hello on startup.\n```"}
+	files := []git.FileDiff{modifiedFile("main.go", "a\n", "b\n")}
+
+	message, err := Generate(context.Background(), client, "model", files, ai.DefaultPromptTokenBudget)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if message != "feat: add greeting\n\nSay hello on startup." {
+		t.Errorf("unexpected message %q", message)
+	}
+	if !strings.Contains(client.prompt, "main.go") {
+		t.Error("the staged diff should be sent to the model")
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	files := []git.FileDiff{modifiedFile("main.go", "a\n", "b\n")}
+	ctx := context.Background()
+
+	if _, err := Generate(ctx, &mockClient{reply: "x"}, "model", nil, ai.DefaultPromptTokenBudget); !errors.Is(err, ErrNothingStaged) {
+		t.Errorf("expected ErrNothingStaged, got %v", err)
+	}
+	if _, err := Generate(ctx, nil, "model", files, ai.DefaultPromptTokenBudget); err == nil {
+		t.Error("expected an error without a client")
+	}
+	if _, err := Generate(ctx, &mockClient{err: errors.New("offline")}, "model", files, ai.DefaultPromptTokenBudget); err == nil {
+		t.Error("expected the client error to be returned")
+	}
+	if _, err := Generate(ctx, &mockClient{reply: "  \n```\n```"}, "model", files, ai.DefaultPromptTokenBudget); err == nil {
+		t.Error("expected an error for an empty reply")
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"plain", "fix: handle nil config", "fix: handle nil config"},
+		{"fenced", "Here you go:\n```text\nfix: x\n```\nHope it helps", "fix: x"},
+		{"label", "Commit message: docs: update README", "docs: update README"},
+		{"quoted", `"chore: bump deps"`, "chore: bump deps"},
+		{"blank lines", "feat: y\n\n\n\nbody line  \n", "feat: y\n\nbody line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clean(tt.reply); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	subject, body := Split("feat: a\n\nline one\nline two")
+	if subject != "feat: a" || body != "line one\nline two" {
+		t.Errorf("got %q / %q", subject, body)
+	}
+	if subject, body := Split("fix: b"); subject != "fix: b" || body != "" {
+		t.Errorf("got %q / %q", subject, body)
+	}
+}
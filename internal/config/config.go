@@ -5,11 +5,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/user/terminal-intelligence/internal/ai"
+	_ "github.com/user/terminal-intelligence/internal/providers" // register the built-in AI providers
+	"github.com/user/terminal-intelligence/internal/syntax"
 	"github.com/user/terminal-intelligence/internal/types"
 )
 
+// DefaultProvider is the AI provider used when none (or an unknown one) is configured.
+const DefaultProvider = "ollama"
+
 // JSONConfig represents the JSON config file schema.
+//
+// Settings of the built-in providers have dedicated fields for backward
+// compatibility. Settings of any other registered provider are kept in
+// Settings and stored as top-level keys of the same JSON object.
 type JSONConfig struct {
 	Agent         string `json:"agent"`
 	Model         string `json:"model"`
@@ -20,7 +31,11 @@ type JSONConfig struct {
 	BedrockModel  string `json:"bedrock_model"`
 	BedrockRegion string `json:"bedrock_region"`
 	Workspace     string `json:"workspace"`
-	Autonomous    string `json:"autonomous"` // Using string "true"/"false" for UI config compatibility
+	Autonomous    string `json:"autonomous"`   // Using string "true"/"false" for UI config compatibility
+	Validation    string `json:"validation"`   // "false" disables compile checks after saves and AI edits
+	EditorTheme   string `json:"editor_theme"` // Syntax highlighting theme, "none" for plain text
+
+	Settings map[string]string `json:"-"` // Provider settings without a dedicated field
 }
 
 // LoadFromFile reads and parses a JSON config file at the given path.
@@ -48,31 +63,16 @@ func ToJSON(cfg *JSONConfig) ([]byte, error) {
 
 // EnsureAllFields ensures all fields in JSONConfig are populated.
 // If a field is empty, it sets a default value so the config editor
-// shows all available options to the user.
+// shows all available options to the user. Provider settings get the
+// defaults declared in the provider registry.
 func EnsureAllFields(cfg *JSONConfig) {
 	if cfg.Agent == "" {
-		cfg.Agent = "ollama"
-	}
-	if cfg.Model == "" {
-		cfg.Model = ""
-	}
-	if cfg.GModel == "" {
-		cfg.GModel = ""
-	}
-	if cfg.BedrockModel == "" {
-		cfg.BedrockModel = ""
+		cfg.Agent = DefaultProvider
 	}
-	if cfg.OllamaURL == "" {
-		cfg.OllamaURL = "http://localhost:11434"
-	}
-	if cfg.GeminiAPI == "" {
-		cfg.GeminiAPI = ""
-	}
-	if cfg.BedrockAPI == "" {
-		cfg.BedrockAPI = ""
-	}
-	if cfg.BedrockRegion == "" {
-		cfg.BedrockRegion = ""
+	for _, field := range ai.ProviderFields() {
+		if cfg.Setting(field.Key) == "" {
+			cfg.SetSetting(field.Key, field.Default)
+		}
 	}
 	if cfg.Workspace == "" {
 		homeDir, _ := os.UserHomeDir()
@@ -81,71 +81,57 @@ func EnsureAllFields(cfg *JSONConfig) {
 	if cfg.Autonomous == "" {
 		cfg.Autonomous = "false"
 	}
+	if cfg.Validation == "" {
+		cfg.Validation = "true"
+	}
+	if cfg.EditorTheme == "" {
+		cfg.EditorTheme = syntax.DefaultTheme
+	}
 }
 
 // Validate checks that the JSONConfig has valid field values.
+// The agent must be a registered provider and its settings must pass the
+// provider's validation. The editor theme, when set, must be a built-in one.
 func Validate(cfg *JSONConfig) error {
-	if cfg.Agent != "gemini" && cfg.Agent != "ollama" && cfg.Agent != "bedrock" {
-		return fmt.Errorf("invalid agent: must be \"gemini\", \"ollama\", or \"bedrock\"")
-	}
-	if cfg.Agent == "gemini" && cfg.GeminiAPI == "" {
-		return fmt.Errorf("gemini_api is required when agent is \"gemini\"")
-	}
-	if cfg.Agent == "bedrock" && cfg.BedrockAPI == "" {
-		return fmt.Errorf("bedrock_api is required when agent is \"bedrock\"")
+	if cfg.EditorTheme != "" && !syntax.IsTheme(cfg.EditorTheme) {
+		return fmt.Errorf("unknown editor_theme %q (available: %s)", cfg.EditorTheme, strings.Join(syntax.ThemeNames(), ", "))
 	}
-	return nil
+	return ai.ValidateSettings(cfg.Agent, cfg.ProviderSettings())
 }
 
 // ApplyToAppConfig merges a JSONConfig into an existing AppConfig,
-// setting only the fields that are non-empty in the JSONConfig.
+// setting only the fields that are non-empty in the JSONConfig. Secret
+// settings are the exception: an empty API key clears the stored one.
 func ApplyToAppConfig(jcfg *JSONConfig, appCfg *types.AppConfig) {
 	if jcfg.Agent != "" {
 		appCfg.Provider = jcfg.Agent
 	}
 
-	// Update stored models if present in config
-	if jcfg.GModel != "" {
-		appCfg.GeminiModel = jcfg.GModel
-	}
-	if jcfg.Model != "" {
-		appCfg.OllamaModel = jcfg.Model
-	}
-	if jcfg.BedrockModel != "" {
-		appCfg.BedrockModel = jcfg.BedrockModel
+	secret := make(map[string]bool)
+	for _, field := range ai.ProviderFields() {
+		secret[field.Key] = field.Secret
 	}
 
-	// Set active model based on provider
-	if appCfg.Provider == "gemini" {
-		if appCfg.GeminiModel != "" {
-			appCfg.DefaultModel = appCfg.GeminiModel
-		}
-	} else if appCfg.Provider == "bedrock" {
-		if appCfg.BedrockModel != "" {
-			appCfg.DefaultModel = appCfg.BedrockModel
-		}
-	} else {
-		// Default to ollama
-		if appCfg.OllamaModel != "" {
-			appCfg.DefaultModel = appCfg.OllamaModel
+	// Update stored provider settings (models, URLs, keys) if present in config
+	for key, value := range jcfg.ProviderSettings() {
+		if value != "" || secret[key] {
+			setAppSetting(appCfg, key, value)
 		}
 	}
 
-	if jcfg.OllamaURL != "" {
-		appCfg.OllamaURL = jcfg.OllamaURL
-	}
-	if jcfg.GeminiAPI != "" {
-		appCfg.GeminiAPIKey = jcfg.GeminiAPI
-	}
-	if jcfg.BedrockAPI != "" {
-		appCfg.BedrockAPIKey = jcfg.BedrockAPI
-	}
-	if jcfg.BedrockRegion != "" {
-		appCfg.BedrockRegion = jcfg.BedrockRegion
-	} else if appCfg.Provider == "bedrock" && appCfg.BedrockRegion == "" {
-		// Default to us-east-1 if bedrock is the provider and region is not set
-		appCfg.BedrockRegion = "us-east-1"
+	// Set active model based on provider and fill in the provider's defaults
+	// (e.g. the Bedrock region) for settings that are still empty
+	spec := activeProvider(appCfg.Provider)
+	settings := ProviderSettings(appCfg)
+	if model := settings.Get(spec.ModelKey); model != "" {
+		appCfg.DefaultModel = model
+	}
+	for _, field := range spec.Fields {
+		if field.Default != "" && settings.Get(field.Key) == "" {
+			setAppSetting(appCfg, field.Key, field.Default)
+		}
 	}
+
 	if jcfg.Workspace != "" {
 		appCfg.WorkspaceDir = jcfg.Workspace
 	}
@@ -154,40 +140,47 @@ func ApplyToAppConfig(jcfg *JSONConfig, appCfg *types.AppConfig) {
 	} else if jcfg.Autonomous == "false" {
 		appCfg.Autonomous = false
 	}
+	if jcfg.Validation == "true" {
+		appCfg.Validation = true
+	} else if jcfg.Validation == "false" {
+		appCfg.Validation = false
+	}
+	if jcfg.EditorTheme != "" {
+		appCfg.EditorTheme = jcfg.EditorTheme
+	}
 }
 
 // AppConfigToJSONConfig converts an AppConfig into a JSONConfig for serialization.
 func AppConfigToJSONConfig(appCfg *types.AppConfig) *JSONConfig {
 	jcfg := &JSONConfig{
-		Agent:         appCfg.Provider,
-		OllamaURL:     appCfg.OllamaURL,
-		GeminiAPI:     appCfg.GeminiAPIKey,
-		BedrockAPI:    appCfg.BedrockAPIKey,
-		BedrockRegion: appCfg.BedrockRegion,
-		Workspace:     appCfg.WorkspaceDir,
-		Autonomous:    fmt.Sprintf("%t", appCfg.Autonomous),
-		Model:         appCfg.OllamaModel,
-		GModel:        appCfg.GeminiModel,
-		BedrockModel:  appCfg.BedrockModel,
+		Agent:       appCfg.Provider,
+		Workspace:   appCfg.WorkspaceDir,
+		Autonomous:  fmt.Sprintf("%t", appCfg.Autonomous),
+		Validation:  fmt.Sprintf("%t", appCfg.Validation),
+		EditorTheme: appCfg.EditorTheme,
+	}
+	for key, value := range ProviderSettings(appCfg) {
+		jcfg.SetSetting(key, value)
 	}
 
 	// Ensure active model is synced to the correct field if stored model is empty
-	if appCfg.Provider == "gemini" {
-		if jcfg.GModel == "" {
-			jcfg.GModel = appCfg.DefaultModel
-		}
-	} else if appCfg.Provider == "bedrock" {
-		if jcfg.BedrockModel == "" {
-			jcfg.BedrockModel = appCfg.DefaultModel
-		}
-	} else {
-		if jcfg.Model == "" {
-			jcfg.Model = appCfg.DefaultModel
-		}
+	spec := activeProvider(appCfg.Provider)
+	if jcfg.Setting(spec.ModelKey) == "" {
+		jcfg.SetSetting(spec.ModelKey, appCfg.DefaultModel)
 	}
 	return jcfg
 }
 
+// activeProvider returns the registry entry for provider, falling back to
+// DefaultProvider for unknown names
+func activeProvider(provider string) ai.ProviderSpec {
+	if spec, ok := ai.LookupProvider(provider); ok {
+		return spec
+	}
+	spec, _ := ai.LookupProvider(DefaultProvider)
+	return spec
+}
+
 // ConfigFilePath returns the expected config file path in the user's home directory.
 // Returns ~/.ti/config.json on Linux/macOS or %USERPROFILE%\.ti\config.json on Windows.
 func ConfigFilePath() (string, error) {
@@ -260,13 +253,15 @@ func CreateDefaultConfig() (string, error) {
 	// Create default config with example values
 	homeDir, _ := os.UserHomeDir()
 	defaultConfig := &JSONConfig{
-		Agent:      "ollama",
-		Model:      "llama2",
-		GModel:     "gemini-3-pro-preview",
-		OllamaURL:  "http://localhost:11434",
-		GeminiAPI:  "",
-		Workspace:  filepath.Join(homeDir, "ti-workspace"),
-		Autonomous: "false",
+		Agent:       "ollama",
+		Model:       "llama2",
+		GModel:      "gemini-3-pro-preview",
+		OllamaURL:   "http://localhost:11434",
+		GeminiAPI:   "",
+		Workspace:   filepath.Join(homeDir, "ti-workspace"),
+		Autonomous:  "false",
+		Validation:  "true",
+		EditorTheme: syntax.DefaultTheme,
 	}
 
 	// Marshal to pretty JSON
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -80,7 +81,7 @@ func TestToJSON_RoundTrip(t *testing.T) {
 	if err != nil {
 		t.Fatalf("FromJSON error: %v", err)
 	}
-	if *original != *restored {
+	if !reflect.DeepEqual(original, restored) {
 		t.Errorf("round-trip mismatch: %+v != %+v", original, restored)
 	}
 }
@@ -113,6 +114,22 @@ func TestApplyToAppConfig_SetsNonEmptyFields(t *testing.T) {
 	}
 }
 
+func TestApplyToAppConfig_EmptyAPIKeyClearsKey(t *testing.T) {
+	appCfg := types.DefaultConfig()
+	ApplyToAppConfig(&JSONConfig{Agent: "openai", Settings: map[string]string{"openai_api": "sk-old", "openai_model": "m"}}, appCfg)
+	if got := ProviderSettings(appCfg).Get("openai_api"); got != "sk-old" {
+		t.Fatalf("openai_api: got %q, want %q", got, "sk-old")
+	}
+
+	ApplyToAppConfig(&JSONConfig{Agent: "openai"}, appCfg)
+	if got := ProviderSettings(appCfg).Get("openai_api"); got != "" {
+		t.Errorf("expected the empty key to clear openai_api, got %q", got)
+	}
+	if appCfg.DefaultModel != "m" {
+		t.Errorf("expected the empty model to keep %q, got %q", "m", appCfg.DefaultModel)
+	}
+}
+
 func TestConfigFilePath_ReturnsHomeDirPath(t *testing.T) {
 	p, err := ConfigFilePath()
 	if err != nil {
@@ -254,7 +271,7 @@ func TestReq3_InvalidJSON_ReturnsError(t *testing.T) {
 
 func TestReq3_UnrecognizedGenAIType_ReturnsError(t *testing.T) {
 	// AC 3.2: Unrecognized agent returns error
-	testCases := []string{"openai", "anthropic", "", "GEMINI", "Ollama"}
+	testCases := []string{"mistral", "anthropic", "", "GEMINI", "Ollama"}
 	for _, provider := range testCases {
 		cfg := &JSONConfig{Agent: provider}
 		err := Validate(cfg)
@@ -741,7 +758,7 @@ func TestValidate_BedrockAgent(t *testing.T) {
 		{
 			name: "invalid agent",
 			config: &JSONConfig{
-				Agent: "openai",
+				Agent: "mistral",
 			},
 			expectError: true,
 			errorMsg:    "invalid agent",
@@ -767,6 +784,64 @@ func TestValidate_BedrockAgent(t *testing.T) {
 	}
 }
 
+func TestValidate_OpenAIAgent(t *testing.T) {
+	// API key is optional: local llama.cpp / vLLM / LM Studio servers don't need one
+	cfg := &JSONConfig{Agent: "openai"}
+	cfg.SetSetting("openai_url", "http://localhost:8080/v1")
+	if err := Validate(cfg); err != nil {
+		t.Errorf("expected no error for openai without API key, got %v", err)
+	}
+	if err := Validate(&JSONConfig{Agent: "openai"}); err != nil {
+		t.Errorf("expected no error for openai with default URL, got %v", err)
+	}
+
+	cfg.SetSetting("openai_url", "localhost:8080")
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "openai_url") {
+		t.Errorf("expected openai_url error for URL without scheme, got %v", err)
+	}
+}
+
+func TestOpenAIFields_RoundTrip(t *testing.T) {
+	data := []byte(`{
+		"agent": "openai",
+		"model": "llama3",
+		"openai_url": "http://gpu-box:8000/v1",
+		"openai_api": "sk-local",
+		"openai_model": "qwen2.5-coder-32b"
+	}`)
+	jcfg, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON error: %v", err)
+	}
+
+	appCfg := types.DefaultConfig()
+	ApplyToAppConfig(jcfg, appCfg)
+
+	if appCfg.Provider != "openai" || appCfg.DefaultModel != "qwen2.5-coder-32b" {
+		t.Errorf("expected openai provider with openai_model active, got %q / %q", appCfg.Provider, appCfg.DefaultModel)
+	}
+	settings := ProviderSettings(appCfg)
+	if settings.Get("openai_url") != "http://gpu-box:8000/v1" || settings.Get("openai_api") != "sk-local" {
+		t.Errorf("openai connection settings not applied: %v", settings)
+	}
+
+	back := AppConfigToJSONConfig(appCfg)
+	out, err := ToJSON(back)
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	restored, err := FromJSON(out)
+	if err != nil {
+		t.Fatalf("FromJSON error: %v", err)
+	}
+	for _, key := range []string{"openai_url", "openai_api", "openai_model"} {
+		if restored.Setting(key) != jcfg.Setting(key) {
+			t.Errorf("%s lost in round trip: got %q, want %q", key, restored.Setting(key), jcfg.Setting(key))
+		}
+	}
+}
+
 // =============================================================================
 // Provider Persistence Tests (Bug Fix)
 // =============================================================================
@@ -917,6 +992,9 @@ func TestEnsureAllFields_EmptyConfig(t *testing.T) {
 	if cfg.Autonomous != "false" {
 		t.Errorf("expected Autonomous to be 'false', got '%s'", cfg.Autonomous)
 	}
+	if cfg.Validation != "true" {
+		t.Errorf("expected Validation to be 'true', got '%s'", cfg.Validation)
+	}
 	if cfg.Workspace == "" {
 		t.Error("expected Workspace to be set to default path")
 	}
@@ -1002,3 +1080,58 @@ func TestUpdateWorkspace_UpdatesExistingConfig(t *testing.T) {
 		t.Errorf("Expected Autonomous 'true', got '%s'", cfg.Autonomous)
 	}
 }
+
+// TestApplyToAppConfig_Validation verifies that the validation switch maps
+// onto AppConfig and survives a round trip.
+func TestApplyToAppConfig_Validation(t *testing.T) {
+	appCfg := types.DefaultConfig()
+	if !appCfg.Validation {
+		t.Fatal("expected validation to be enabled by default")
+	}
+
+	ApplyToAppConfig(&JSONConfig{Validation: "false"}, appCfg)
+	if appCfg.Validation {
+		t.Error("expected validation to be disabled")
+	}
+	if got := AppConfigToJSONConfig(appCfg).Validation; got != "false" {
+		t.Errorf("expected round-tripped Validation 'false', got '%s'", got)
+	}
+
+	// Unset or unrecognised values leave the setting alone
+	ApplyToAppConfig(&JSONConfig{Validation: ""}, appCfg)
+	if appCfg.Validation {
+		t.Error("expected empty value to leave validation disabled")
+	}
+	ApplyToAppConfig(&JSONConfig{Validation: "true"}, appCfg)
+	if !appCfg.Validation {
+		t.Error("expected validation to be enabled")
+	}
+}
+
+// TestEditorTheme verifies the editor_theme default, its mapping onto
+// AppConfig and the rejection of unknown themes.
+func TestEditorTheme(t *testing.T) {
+	cfg := &JSONConfig{}
+	EnsureAllFields(cfg)
+	if cfg.EditorTheme != "monokai" {
+		t.Errorf("expected EditorTheme to default to 'monokai', got '%s'", cfg.EditorTheme)
+	}
+
+	appCfg := types.DefaultConfig()
+	ApplyToAppConfig(&JSONConfig{EditorTheme: "dracula"}, appCfg)
+	if appCfg.EditorTheme != "dracula" {
+		t.Errorf("expected EditorTheme 'dracula', got '%s'", appCfg.EditorTheme)
+	}
+	ApplyToAppConfig(&JSONConfig{}, appCfg)
+	if got := AppConfigToJSONConfig(appCfg).EditorTheme; got != "dracula" {
+		t.Errorf("expected round-tripped EditorTheme 'dracula', got '%s'", got)
+	}
+
+	if err := Validate(&JSONConfig{Agent: "ollama", EditorTheme: "none"}); err != nil {
+		t.Errorf("expected the none theme to be valid, got %v", err)
+	}
+	err := Validate(&JSONConfig{Agent: "ollama", EditorTheme: "neon"})
+	if err == nil || !strings.Contains(err.Error(), "solarized-dark") {
+		t.Errorf("expected an error listing the themes, got %v", err)
+	}
+}
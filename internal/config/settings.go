@@ -0,0 +1,168 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// jsonFields maps the JSON keys of JSONConfig's dedicated fields to their
+// struct field index
+var jsonFields = sync.OnceValue(func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(JSONConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		if tag != "" && tag != "-" && t.Field(i).Type.Kind() == reflect.String {
+			fields[tag] = i
+		}
+	}
+	return fields
+})
+
+// Setting returns the value stored under a config.json key, whether it has a
+// dedicated field or lives in Settings.
+func (c *JSONConfig) Setting(key string) string {
+	if i, ok := jsonFields()[key]; ok {
+		return reflect.ValueOf(c).Elem().Field(i).String()
+	}
+	return c.Settings[key]
+}
+
+// SetSetting stores value under a config.json key, using the dedicated field
+// when there is one.
+func (c *JSONConfig) SetSetting(key, value string) {
+	if i, ok := jsonFields()[key]; ok {
+		reflect.ValueOf(c).Elem().Field(i).SetString(value)
+		return
+	}
+	if c.Settings == nil {
+		c.Settings = make(map[string]string)
+	}
+	c.Settings[key] = value
+}
+
+// ProviderSettings returns the settings of every registered provider plus
+// any other extra keys found in the file.
+func (c *JSONConfig) ProviderSettings() ai.Settings {
+	settings := make(ai.Settings, len(c.Settings))
+	for key, value := range c.Settings {
+		settings[key] = value
+	}
+	for _, field := range ai.ProviderFields() {
+		settings[field.Key] = c.Setting(field.Key)
+	}
+	return settings
+}
+
+// MarshalJSON writes the dedicated fields in declaration order followed by
+// the entries of Settings, sorted by key.
+func (c JSONConfig) MarshalJSON() ([]byte, error) {
+	type plain JSONConfig
+	data, err := json.Marshal(plain(c))
+	if err != nil || len(c.Settings) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(c.Settings))
+	for key := range c.Settings {
+		if _, dedicated := jsonFields()[key]; !dedicated {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for i, key := range keys {
+		if i > 0 || len(data) > 2 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(c.Settings[key])
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads the dedicated fields and collects every other string
+// value into Settings.
+func (c *JSONConfig) UnmarshalJSON(data []byte) error {
+	type plain JSONConfig
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		if _, dedicated := jsonFields()[key]; dedicated {
+			continue
+		}
+		var s string
+		if json.Unmarshal(value, &s) != nil {
+			continue
+		}
+		if c.Settings == nil {
+			c.Settings = make(map[string]string)
+		}
+		c.Settings[key] = s
+	}
+	return nil
+}
+
+// appConfigFields maps config.json keys of the built-in providers to their
+// dedicated AppConfig fields; other providers' settings live in
+// AppConfig.ProviderSettings
+var appConfigFields = map[string]func(*types.AppConfig) *string{
+	"model":          func(c *types.AppConfig) *string { return &c.OllamaModel },
+	"gmodel":         func(c *types.AppConfig) *string { return &c.GeminiModel },
+	"bedrock_model":  func(c *types.AppConfig) *string { return &c.BedrockModel },
+	"ollama_url":     func(c *types.AppConfig) *string { return &c.OllamaURL },
+	"gemini_api":     func(c *types.AppConfig) *string { return &c.GeminiAPIKey },
+	"bedrock_api":    func(c *types.AppConfig) *string { return &c.BedrockAPIKey },
+	"bedrock_region": func(c *types.AppConfig) *string { return &c.BedrockRegion },
+}
+
+// ProviderSettings returns the provider settings held by an AppConfig, keyed
+// by config.json key, for building clients through the provider registry.
+func ProviderSettings(appCfg *types.AppConfig) ai.Settings {
+	settings := make(ai.Settings, len(appCfg.ProviderSettings))
+	for key, value := range appCfg.ProviderSettings {
+		settings[key] = value
+	}
+	for _, field := range ai.ProviderFields() {
+		settings[field.Key] = appSetting(appCfg, field.Key)
+	}
+	return settings
+}
+
+// appSetting returns the AppConfig value stored under a config.json key
+func appSetting(appCfg *types.AppConfig, key string) string {
+	if field, ok := appConfigFields[key]; ok {
+		return *field(appCfg)
+	}
+	return appCfg.ProviderSettings[key]
+}
+
+// setAppSetting stores an AppConfig value under a config.json key
+func setAppSetting(appCfg *types.AppConfig, key, value string) {
+	if field, ok := appConfigFields[key]; ok {
+		*field(appCfg) = value
+		return
+	}
+	if appCfg.ProviderSettings == nil {
+		appCfg.ProviderSettings = make(map[string]string)
+	}
+	appCfg.ProviderSettings[key] = value
+}
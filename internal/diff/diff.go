@@ -0,0 +1,303 @@
+// Package diff computes line-based differences between two texts and groups
+// them into hunks that can be reviewed, edited and applied one by one.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind tells which side of a diff a line belongs to.
+type Kind int
+
+const (
+	Equal  Kind = iota // Line is in both texts
+	Delete             // Line is only in the old text
+	Insert             // Line is only in the new text
+)
+
+// maxCells bounds the size of the LCS table. Larger changed regions are
+// reported as a whole-region replacement.
+const maxCells = 4_000_000
+
+// Line is one line of a diff. Text keeps its line terminator, so joining
+// the lines of one side reproduces that side exactly.
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Hunk is a run of changes with the context lines around them. OldStart and
+// NewStart are 0-based indexes of the first line of the hunk in the old and
+// new text.
+type Hunk struct {
+	OldStart int
+	NewStart int
+	Lines    []Line
+}
+
+// OldLines returns the lines of the hunk as they are in the old text.
+func (h Hunk) OldLines() []string {
+	return h.side(Insert)
+}
+
+// NewLines returns the lines of the hunk as they are in the new text.
+func (h Hunk) NewLines() []string {
+	return h.side(Delete)
+}
+
+func (h Hunk) side(skip Kind) []string {
+	var lines []string
+	for _, l := range h.Lines {
+		if l.Kind != skip {
+			lines = append(lines, l.Text)
+		}
+	}
+	return lines
+}
+
+// Header returns the unified diff header of the hunk, e.g. "@@ -3,7 +3,8 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, len(h.OldLines())), hunkRange(h.NewStart, len(h.NewLines())))
+}
+
+// hunkRange formats a 0-based start and a length as a 1-based unified diff
+// range. An empty range names the line before it.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// WithNewLines returns a copy of h whose new side is replaced by lines. The
+// old side is unchanged, so the hunk still applies to the same text.
+func (h Hunk) WithNewLines(lines []string) Hunk {
+	return Hunk{
+		OldStart: h.OldStart,
+		NewStart: h.NewStart,
+		Lines:    diffLines(h.OldLines(), lines),
+	}
+}
+
+// SplitLines splits text into lines that keep their "\n" terminator. The
+// last line has none when text does not end with a newline.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Compute returns the hunks that turn oldText into newText, each with up to
+// context unchanged lines before and after its changes. Changes closer than
+// 2*context lines share a hunk.
+func Compute(oldText, newText string, context int) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+	lines := diffLines(SplitLines(oldText), SplitLines(newText))
+
+	var hunks []Hunk
+	i := 0
+	for i < len(lines) {
+		if lines[i].Kind == Equal {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is within reach
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Kind != Equal {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		stop := end + context
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+
+		oldStart, newStart := position(lines[:start])
+		hunks = append(hunks, Hunk{
+			OldStart: oldStart,
+			NewStart: newStart,
+			Lines:    append([]Line(nil), lines[start:stop]...),
+		})
+		i = stop
+	}
+	return hunks
+}
+
+// position returns the number of old and new lines in lines
+func position(lines []Line) (int, int) {
+	oldN, newN := 0, 0
+	for _, l := range lines {
+		if l.Kind != Insert {
+			oldN++
+		}
+		if l.Kind != Delete {
+			newN++
+		}
+	}
+	return oldN, newN
+}
+
+// Apply applies hunks to oldText and returns the result. Hunks must be in
+// order and must not overlap; lines of oldText outside the hunks are kept.
+// An error is returned when a hunk does not match oldText.
+func Apply(oldText string, hunks []Hunk) (string, error) {
+	old := SplitLines(oldText)
+
+	var sb strings.Builder
+	pos := 0
+	for _, h := range hunks {
+		oldLines := h.OldLines()
+		if h.OldStart < pos || h.OldStart+len(oldLines) > len(old) {
+			return "", fmt.Errorf("hunk %s is out of range", h.Header())
+		}
+		for k, line := range oldLines {
+			if old[h.OldStart+k] != line {
+				return "", fmt.Errorf("hunk %s does not match the original text", h.Header())
+			}
+		}
+
+		for _, line := range old[pos:h.OldStart] {
+			sb.WriteString(line)
+		}
+		for _, line := range h.NewLines() {
+			sb.WriteString(line)
+		}
+		pos = h.OldStart + len(oldLines)
+	}
+	for _, line := range old[pos:] {
+		sb.WriteString(line)
+	}
+	return sb.String(), nil
+}
+
+// Unified formats hunks as a unified diff between oldName and newName.
+func Unified(oldName, newName string, hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		sb.WriteString(h.Header())
+		sb.WriteString("\n")
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case Delete:
+				sb.WriteString("-")
+			case Insert:
+				sb.WriteString("+")
+			default:
+				sb.WriteString(" ")
+			}
+			sb.WriteString(l.Text)
+			if !strings.HasSuffix(l.Text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// diffLines returns a line diff of a and b: the common prefix and suffix are
+// kept, and the region between them is diffed by longest common subsequence.
+func diffLines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Kind: Equal, Text: text})
+	}
+	lines = append(lines, lcsDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Kind: Equal, Text: text})
+	}
+	return lines
+}
+
+// lcsDiff diffs a and b with a longest common subsequence table, listing
+// deletions before insertions within each change.
+func lcsDiff(a, b []string) []Line {
+	n, m := len(a), len(b)
+	var lines []Line
+	if n == 0 || m == 0 || n*m > maxCells {
+		for _, text := range a {
+			lines = append(lines, Line{Kind: Delete, Text: text})
+		}
+		for _, text := range b {
+			lines = append(lines, Line{Kind: Insert, Text: text})
+		}
+		return lines
+	}
+
+	// lcs[i*(m+1)+j] is the LCS length of a[i:] and b[j:]
+	width := m + 1
+	lcs := make([]int32, (n+1)*width)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else if lcs[(i+1)*width+j] >= lcs[i*width+j+1] {
+				lcs[i*width+j] = lcs[(i+1)*width+j]
+			} else {
+				lcs[i*width+j] = lcs[i*width+j+1]
+			}
+		}
+	}
+
+	var inserts []Line
+	flush := func() {
+		lines = append(lines, inserts...)
+		inserts = inserts[:0]
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			flush()
+			lines = append(lines, Line{Kind: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			lines = append(lines, Line{Kind: Delete, Text: a[i]})
+			i++
+		default:
+			inserts = append(inserts, Line{Kind: Insert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, Line{Kind: Delete, Text: a[i]})
+	}
+	flush()
+	for ; j < m; j++ {
+		lines = append(lines, Line{Kind: Insert, Text: b[j]})
+	}
+	return lines
+}
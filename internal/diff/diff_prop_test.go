@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// genText generates texts built from a small alphabet of lines so that
+// generated pairs share lines often.
+func genText() gopter.Gen {
+	return gen.SliceOf(gen.OneConstOf("a\n", "b\n", "c\n", "\n", "d")).Map(func(lines []string) string {
+		return strings.Join(lines, "")
+	})
+}
+
+// For any two texts, applying every hunk of their diff to the first text
+// produces the second.
+func TestProperty_ApplyAllHunksReproducesNewText(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("Apply(old, Compute(old, new)) == new", prop.ForAll(
+		func(old, new string, context int) bool {
+			got, err := Apply(old, Compute(old, new, context))
+			return err == nil && got == new
+		},
+		genText(),
+		genText(),
+		gen.IntRange(0, 4),
+	))
+
+	properties.TestingRun(t)
+}
+
+// For any two texts, applying no hunks keeps the first text, and every hunk
+// applies on its own.
+func TestProperty_HunksApplyIndependently(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("each hunk applies alone", prop.ForAll(
+		func(old, new string) bool {
+			if got, err := Apply(old, nil); err != nil || got != old {
+				return false
+			}
+			for _, h := range Compute(old, new, 2) {
+				if _, err := Apply(old, []Hunk{h}); err != nil {
+					return false
+				}
+			}
+			return true
+		},
+		genText(),
+		genText(),
+	))
+
+	properties.TestingRun(t)
+}
+
+// For any base and one changed side, merging takes the changed side, and
+// merging two identical sides takes that side, without conflicts.
+func TestProperty_MergeOneSidedChanges(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("one-sided and identical changes merge cleanly", prop.ForAll(
+		func(base, changed string) bool {
+			if got, n := Merge(base, changed, base, "ours", "theirs"); n != 0 || got != changed {
+				return false
+			}
+			if got, n := Merge(base, base, changed, "ours", "theirs"); n != 0 || got != changed {
+				return false
+			}
+			got, n := Merge(base, changed, changed, "ours", "theirs")
+			return n == 0 && got == changed
+		},
+		genText(),
+		genText(),
+	))
+
+	properties.TestingRun(t)
+}
+
+// For any three texts, every conflict reported by Merge is found again by
+// ParseConflicts, and resolving them all leaves no markers behind.
+func TestProperty_MergeConflictsParse(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("conflicts round-trip through ParseConflicts", prop.ForAll(
+		func(base, ours, theirs string, resolution int) bool {
+			merged, n := Merge(base, ours, theirs, "ours", "theirs")
+			conflicts := ParseConflicts(merged)
+			if len(conflicts) != n {
+				return false
+			}
+			for i := len(conflicts) - 1; i >= 0; i-- {
+				merged = Resolve(merged, conflicts[i], Resolution(resolution))
+			}
+			return !HasConflicts(merged)
+		},
+		genText(),
+		genText(),
+		genText(),
+		gen.IntRange(0, 2),
+	))
+
+	properties.TestingRun(t)
+}
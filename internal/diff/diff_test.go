@@ -0,0 +1,164 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\n\nb\n", []string{"a\n", "\n", "b\n"}},
+	}
+	for _, tt := range tests {
+		got := SplitLines(tt.text)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCompute_IdenticalTexts(t *testing.T) {
+	if hunks := Compute("a\nb\n", "a\nb\n", 3); len(hunks) != 0 {
+		t.Errorf("expected no hunks, got %d", len(hunks))
+	}
+}
+
+func TestCompute_SingleChange(t *testing.T) {
+	hunks := Compute("a\nb\nc\nd\ne\n", "a\nb\nC\nd\ne\n", 1)
+	if len(hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(hunks))
+	}
+	h := hunks[0]
+	if h.OldStart != 1 || h.NewStart != 1 {
+		t.Errorf("unexpected start: old=%d new=%d", h.OldStart, h.NewStart)
+	}
+	if got := strings.Join(h.OldLines(), ""); got != "b\nc\nd\n" {
+		t.Errorf("old side = %q", got)
+	}
+	if got := strings.Join(h.NewLines(), ""); got != "b\nC\nd\n" {
+		t.Errorf("new side = %q", got)
+	}
+	if h.Header() != "@@ -2,3 +2,3 @@" {
+		t.Errorf("header = %q", h.Header())
+	}
+}
+
+func TestCompute_SplitsDistantChanges(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	new := "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n"
+
+	if hunks := Compute(old, new, 2); len(hunks) != 2 {
+		t.Errorf("context 2: expected 2 hunks, got %d", len(hunks))
+	}
+	if hunks := Compute(old, new, 5); len(hunks) != 1 {
+		t.Errorf("context 5: expected 1 hunk, got %d", len(hunks))
+	}
+}
+
+func TestApply_AllHunks(t *testing.T) {
+	old := "package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n"
+	new := "package main\n\nimport \"fmt\"\n\nfunc a() {}\n\nfunc c() { fmt.Println() }\n"
+
+	got, err := Apply(old, Compute(old, new, 0))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got != new {
+		t.Errorf("Apply = %q, want %q", got, new)
+	}
+}
+
+func TestApply_SelectedHunks(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\n"
+	new := "A\nb\nc\nd\ne\nf\nG\n"
+
+	hunks := Compute(old, new, 1)
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(hunks))
+	}
+
+	got, err := Apply(old, hunks[1:])
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got != "a\nb\nc\nd\ne\nf\nG\n" {
+		t.Errorf("Apply = %q", got)
+	}
+
+	got, err = Apply(old, nil)
+	if err != nil || got != old {
+		t.Errorf("Apply with no hunks = %q, %v", got, err)
+	}
+}
+
+func TestApply_MismatchedText(t *testing.T) {
+	hunks := Compute("a\nb\n", "a\nB\n", 0)
+	if _, err := Apply("x\ny\n", hunks); err == nil {
+		t.Error("expected error when hunk does not match")
+	}
+	if _, err := Apply("a\n", hunks); err == nil {
+		t.Error("expected error when hunk is out of range")
+	}
+}
+
+func TestApply_NoTrailingNewline(t *testing.T) {
+	old := "a\nb"
+	new := "a\nb\nc"
+	got, err := Apply(old, Compute(old, new, 3))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got != new {
+		t.Errorf("Apply = %q, want %q", got, new)
+	}
+}
+
+func TestHunk_WithNewLines(t *testing.T) {
+	old := "a\nb\nc\n"
+	hunks := Compute(old, "a\nB\nc\n", 1)
+	if len(hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(hunks))
+	}
+
+	edited := hunks[0].WithNewLines([]string{"a\n", "edited\n", "c\n"})
+	if strings.Join(edited.OldLines(), "") != strings.Join(hunks[0].OldLines(), "") {
+		t.Error("editing must keep the old side")
+	}
+	got, err := Apply(old, []Hunk{edited})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got != "a\nedited\nc\n" {
+		t.Errorf("Apply = %q", got)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	old := "a\nb\nc"
+	new := "a\nB\nc"
+	got := Unified("a/f.txt", "b/f.txt", Compute(old, new, 1))
+	want := "--- a/f.txt\n+++ b/f.txt\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n\\ No newline at end of file\n"
+	if got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+	if Unified("a", "b", nil) != "" {
+		t.Error("expected empty diff for no hunks")
+	}
+}
+
+func TestHunk_HeaderForPureInsertion(t *testing.T) {
+	hunks := Compute("", "a\nb\n", 3)
+	if len(hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(hunks))
+	}
+	if hunks[0].Header() != "@@ -0,0 +1,2 @@" {
+		t.Errorf("header = %q", hunks[0].Header())
+	}
+}
@@ -0,0 +1,265 @@
+package diff
+
+import "strings"
+
+// Conflict markers, as written by git with the diff3 conflict style.
+const (
+	MarkerOurs   = "<<<<<<<"
+	MarkerBase   = "|||||||"
+	MarkerSplit  = "======="
+	MarkerTheirs = ">>>>>>>"
+)
+
+// change is a run of changed lines of one side against the base: the base
+// lines [start, end) are replaced by lines.
+type change struct {
+	start, end int
+	lines      []string
+}
+
+// changes returns the changes that turn base into other, in order.
+func changes(base, other []string) []change {
+	var result []change
+	pos := 0
+	var current *change
+	for _, l := range diffLines(base, other) {
+		if l.Kind == Equal {
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			pos++
+			continue
+		}
+		if current == nil {
+			current = &change{start: pos, end: pos}
+		}
+		if l.Kind == Delete {
+			pos++
+			current.end = pos
+		} else {
+			current.lines = append(current.lines, l.Text)
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result
+}
+
+// applyChanges returns base[lo:hi] with changes, which all lie within it,
+// applied.
+func applyChanges(base []string, lo, hi int, changes []change) []string {
+	var lines []string
+	pos := lo
+	for _, c := range changes {
+		lines = append(lines, base[pos:c.start]...)
+		lines = append(lines, c.lines...)
+		pos = c.end
+	}
+	return append(lines, base[pos:hi]...)
+}
+
+// Merge combines the changes that ours and theirs each made to base. Changes
+// to different parts of the text are both kept; where the two sides changed
+// the same or adjacent lines differently, the region is written with conflict
+// markers showing ours, the base and theirs, labelled with oursLabel and
+// theirsLabel. It returns the merged text and the number of conflicts.
+func Merge(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	baseLines := SplitLines(base)
+	oursChanges := changes(baseLines, SplitLines(ours))
+	theirsChanges := changes(baseLines, SplitLines(theirs))
+
+	var sb strings.Builder
+	conflicts := 0
+	pos := 0
+	i, j := 0, 0
+	for i < len(oursChanges) || j < len(theirsChanges) {
+		// Start a region with the earliest change and grow it while changes
+		// of either side overlap or touch it
+		var lo, hi int
+		if j >= len(theirsChanges) || (i < len(oursChanges) && oursChanges[i].start <= theirsChanges[j].start) {
+			lo, hi = oursChanges[i].start, oursChanges[i].end
+		} else {
+			lo, hi = theirsChanges[j].start, theirsChanges[j].end
+		}
+		oi, tj := i, j
+		for {
+			if i < len(oursChanges) && oursChanges[i].start <= hi {
+				hi = max(hi, oursChanges[i].end)
+				i++
+			} else if j < len(theirsChanges) && theirsChanges[j].start <= hi {
+				hi = max(hi, theirsChanges[j].end)
+				j++
+			} else {
+				break
+			}
+		}
+
+		writeLines(&sb, baseLines[pos:lo])
+		pos = hi
+
+		oursRegion := applyChanges(baseLines, lo, hi, oursChanges[oi:i])
+		theirsRegion := applyChanges(baseLines, lo, hi, theirsChanges[tj:j])
+		switch {
+		case tj == j:
+			writeLines(&sb, oursRegion)
+		case oi == i:
+			writeLines(&sb, theirsRegion)
+		case equalLines(oursRegion, theirsRegion):
+			writeLines(&sb, oursRegion)
+		default:
+			conflicts++
+			writeMarker(&sb, MarkerOurs, oursLabel)
+			writeSide(&sb, oursRegion)
+			writeMarker(&sb, MarkerBase, "base")
+			writeSide(&sb, baseLines[lo:hi])
+			writeMarker(&sb, MarkerSplit, "")
+			writeSide(&sb, theirsRegion)
+			writeMarker(&sb, MarkerTheirs, theirsLabel)
+		}
+	}
+	writeLines(&sb, baseLines[pos:])
+	return sb.String(), conflicts
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+}
+
+// writeSide writes the lines of one side of a conflict, ending the last one
+// with a newline so the next marker starts on its own line.
+func writeSide(sb *strings.Builder, lines []string) {
+	writeLines(sb, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		sb.WriteString("\n")
+	}
+}
+
+func writeMarker(sb *strings.Builder, marker, label string) {
+	sb.WriteString(marker)
+	if label != "" {
+		sb.WriteString(" ")
+		sb.WriteString(label)
+	}
+	sb.WriteString("\n")
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Conflict is one block of conflict markers in a text. Start and End are the
+// 0-based indexes of its "<<<<<<<" line and of the line after its ">>>>>>>"
+// line. Each side keeps its line terminators; Base is nil when the block has
+// no base section.
+type Conflict struct {
+	Start, End int
+	Ours       []string
+	Base       []string
+	Theirs     []string
+}
+
+// Resolution chooses how a conflict is resolved.
+type Resolution int
+
+const (
+	TakeOurs   Resolution = iota // Keep our side
+	TakeTheirs                   // Keep their side
+	TakeBoth                     // Keep our side followed by theirs
+)
+
+// isMarker reports whether line is the conflict marker marker, optionally
+// followed by a label.
+func isMarker(line, marker string) bool {
+	line = strings.TrimRight(line, "\r\n")
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+// HasConflicts reports whether text contains a complete conflict block.
+func HasConflicts(text string) bool {
+	return strings.Contains(text, MarkerOurs) && len(ParseConflicts(text)) > 0
+}
+
+// ParseConflicts returns the conflict blocks of text in order. Incomplete
+// blocks are ignored.
+func ParseConflicts(text string) []Conflict {
+	lines := SplitLines(text)
+	var conflicts []Conflict
+	for i := 0; i < len(lines); i++ {
+		if !isMarker(lines[i], MarkerOurs) {
+			continue
+		}
+		c := Conflict{Start: i}
+		section := &c.Ours
+		closed := false
+		j := i + 1
+		for ; j < len(lines); j++ {
+			line := lines[j]
+			switch {
+			case isMarker(line, MarkerOurs):
+				// A new block starts before this one ended
+			case isMarker(line, MarkerBase) && section == &c.Ours:
+				c.Base = []string{}
+				section = &c.Base
+				continue
+			case isMarker(line, MarkerSplit) && section != &c.Theirs:
+				section = &c.Theirs
+				continue
+			case isMarker(line, MarkerTheirs) && section == &c.Theirs:
+				closed = true
+			default:
+				*section = append(*section, line)
+				continue
+			}
+			break
+		}
+		if closed {
+			c.End = j + 1
+			conflicts = append(conflicts, c)
+			i = j
+		} else {
+			i = j - 1
+		}
+	}
+	return conflicts
+}
+
+// Resolve replaces conflict c of text, as returned by ParseConflicts, with
+// the lines chosen by resolution.
+func Resolve(text string, c Conflict, resolution Resolution) string {
+	lines := SplitLines(text)
+	if c.Start < 0 || c.End > len(lines) || c.Start >= c.End {
+		return text
+	}
+
+	var chosen []string
+	switch resolution {
+	case TakeOurs:
+		chosen = c.Ours
+	case TakeTheirs:
+		chosen = c.Theirs
+	case TakeBoth:
+		chosen = append(append([]string{}, c.Ours...), c.Theirs...)
+	}
+
+	var sb strings.Builder
+	writeLines(&sb, lines[:c.Start])
+	if c.End < len(lines) {
+		writeSide(&sb, chosen)
+	} else {
+		writeLines(&sb, chosen)
+	}
+	writeLines(&sb, lines[c.End:])
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const mergeBase = "one\ntwo\nthree\nfour\nfive\nsix\n"
+
+func TestMerge_NonOverlappingChanges(t *testing.T) {
+	ours := strings.Replace(mergeBase, "one\n", "ONE\n", 1)
+	theirs := strings.Replace(mergeBase, "six\n", "SIX\nseven\n", 1)
+
+	merged, conflicts := Merge(mergeBase, ours, theirs, "HEAD", "feature")
+	if conflicts != 0 {
+		t.Fatalf("expected no conflicts, got %d:\n%s", conflicts, merged)
+	}
+	want := "ONE\ntwo\nthree\nfour\nfive\nSIX\nseven\n"
+	if merged != want {
+		t.Errorf("got %q, want %q", merged, want)
+	}
+}
+
+func TestMerge_SameChangeOnBothSides(t *testing.T) {
+	changed := strings.Replace(mergeBase, "three\n", "3\n", 1)
+	merged, conflicts := Merge(mergeBase, changed, changed, "HEAD", "feature")
+	if conflicts != 0 || merged != changed {
+		t.Errorf("expected the shared change, got %d conflicts:\n%s", conflicts, merged)
+	}
+}
+
+func TestMerge_ConflictingChanges(t *testing.T) {
+	ours := strings.Replace(mergeBase, "three\n", "ours\n", 1)
+	theirs := strings.Replace(strings.Replace(mergeBase, "three\n", "theirs\n", 1), "six\n", "6\n", 1)
+
+	merged, conflicts := Merge(mergeBase, ours, theirs, "HEAD", "feature")
+	if conflicts != 1 {
+		t.Fatalf("expected 1 conflict, got %d", conflicts)
+	}
+	want := "one\ntwo\n" +
+		"<<<<<<< HEAD\nours\n" +
+		"||||||| base\nthree\n" +
+		"=======\ntheirs\n" +
+		">>>>>>> feature\n" +
+		"four\nfive\n6\n"
+	if merged != want {
+		t.Errorf("got:\n%s\nwant:\n%s", merged, want)
+	}
+}
+
+func TestMerge_AddedOnBothSides(t *testing.T) {
+	merged, conflicts := Merge("", "a\n", "b", "HEAD", "feature")
+	if conflicts != 1 {
+		t.Fatalf("expected 1 conflict, got %d", conflicts)
+	}
+	// The side without a final newline still gets its marker on a new line
+	if !strings.Contains(merged, "=======\nb\n>>>>>>> feature\n") {
+		t.Errorf("unexpected result:\n%s", merged)
+	}
+}
+
+func TestParseConflicts(t *testing.T) {
+	text := "start\n" +
+		"<<<<<<< HEAD\nours\n||||||| base\nbase\n=======\ntheirs\n>>>>>>> feature\n" +
+		"middle\n" +
+		"<<<<<<< HEAD\nx\n=======\ny\nz\n>>>>>>> other\n" +
+		"<<<<<<< unfinished\nend\n"
+
+	conflicts := ParseConflicts(text)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	first := conflicts[0]
+	if first.Start != 1 || first.End != 8 {
+		t.Errorf("unexpected range %d-%d", first.Start, first.End)
+	}
+	if strings.Join(first.Ours, "") != "ours\n" || strings.Join(first.Base, "") != "base\n" || strings.Join(first.Theirs, "") != "theirs\n" {
+		t.Errorf("unexpected sides %+v", first)
+	}
+	second := conflicts[1]
+	if second.Base != nil || strings.Join(second.Theirs, "") != "y\nz\n" {
+		t.Errorf("unexpected second conflict %+v", second)
+	}
+	if !HasConflicts(text) || HasConflicts("<<<<<<< HEAD\nno end\n") {
+		t.Error("HasConflicts should only report complete blocks")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	text := "a\n<<<<<<< HEAD\nours\n||||||| base\nbase\n=======\ntheirs\n>>>>>>> feature\nb\n"
+	c := ParseConflicts(text)[0]
+
+	tests := []struct {
+		resolution Resolution
+		want       string
+	}{
+		{TakeOurs, "a\nours\nb\n"},
+		{TakeTheirs, "a\ntheirs\nb\n"},
+		{TakeBoth, "a\nours\ntheirs\nb\n"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(text, c, tt.resolution); got != tt.want {
+			t.Errorf("Resolve(%d) = %q, want %q", tt.resolution, got, tt.want)
+		}
+	}
+}
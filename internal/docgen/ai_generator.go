@@ -1,6 +1,7 @@
 package docgen
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -154,24 +155,30 @@ func ParsePromptMetadata(prompt string) (DocumentationType, error) {
 // If the AI client returns an error, feedback.NotifyError is called and the
 // error is returned.
 func (g *AIGenerator) Generate(result *AnalysisResult, docType DocumentationType) (*GeneratedDoc, error) {
+	return g.GenerateContext(context.Background(), result, docType)
+}
+
+// GenerateContext is Generate with a context: cancelling ctx stops the
+// generation and returns an *ai.CancelledError.
+func (g *AIGenerator) GenerateContext(ctx context.Context, result *AnalysisResult, docType DocumentationType) (*GeneratedDoc, error) {
 	prompt := g.BuildPrompt(result, docType)
 
 	g.feedback.NotifyProgress("AI generating...", docType.String())
 
-	ch, err := g.client.Generate(prompt, g.model, nil, nil)
+	ch, err := ai.GenerateContext(ctx, g.client, prompt, g.model, nil)
 	if err != nil {
 		g.feedback.NotifyError(err)
 		return nil, err
 	}
 
-	var sb strings.Builder
-	for chunk := range ch {
-		sb.WriteString(chunk)
+	content, err := ai.Collect(ctx, ch)
+	if err != nil {
+		return nil, err
 	}
 
 	return &GeneratedDoc{
 		Type:     docType,
-		Content:  sb.String(),
+		Content:  content,
 		Filename: docType.Filename(),
 	}, nil
 }
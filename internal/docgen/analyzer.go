@@ -341,6 +341,7 @@ func (a *ProjectAnalyzer) AnalyzeGoFile(filePath string) (*CodeStructure, error)
 		switch d := decl.(type) {
 		case *ast.FuncDecl:
 			funcInfo := a.extractFunctionInfo(d, pkgInfo.Name)
+			funcInfo.Line = fset.Position(d.Pos()).Line
 			structure.Functions = append(structure.Functions, funcInfo)
 
 			// Add to exports if exported
@@ -353,7 +354,7 @@ func (a *ProjectAnalyzer) AnalyzeGoFile(filePath string) (*CodeStructure, error)
 			}
 
 		case *ast.GenDecl:
-			a.extractGenDeclInfo(d, pkgInfo.Name, structure)
+			a.extractGenDeclInfo(fset, d, pkgInfo.Name, structure)
 		}
 	}
 
@@ -391,6 +392,11 @@ func (a *ProjectAnalyzer) extractFunctionInfo(funcDecl *ast.FuncDecl, pkgName st
 		funcInfo.Comment = funcDecl.Doc.Text()
 	}
 
+	// Extract receiver type of methods
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		funcInfo.Receiver = receiverTypeName(funcDecl.Recv.List[0].Type)
+	}
+
 	// Extract parameters
 	if funcDecl.Type.Params != nil {
 		for _, field := range funcDecl.Type.Params.List {
@@ -441,14 +447,36 @@ func (a *ProjectAnalyzer) extractFunctionInfo(funcDecl *ast.FuncDecl, pkgName st
 	return funcInfo
 }
 
+// receiverTypeName returns the name of a method receiver type without the
+// pointer and type parameters, e.g. "Box" for *Box[T]
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
 // extractGenDeclInfo extracts information from a general declaration (type, const, var)
-func (a *ProjectAnalyzer) extractGenDeclInfo(genDecl *ast.GenDecl, pkgName string, structure *CodeStructure) {
+func (a *ProjectAnalyzer) extractGenDeclInfo(fset *token.FileSet, genDecl *ast.GenDecl, pkgName string, structure *CodeStructure) {
 	for _, spec := range genDecl.Specs {
 		switch s := spec.(type) {
 		case *ast.TypeSpec:
 			switch t := s.Type.(type) {
 			case *ast.StructType:
 				structInfo := a.extractStructInfo(s, t, genDecl.Doc, pkgName)
+				structInfo.Line = fset.Position(s.Pos()).Line
 				structure.Structs = append(structure.Structs, structInfo)
 
 				// Add to exports if exported
@@ -462,6 +490,7 @@ func (a *ProjectAnalyzer) extractGenDeclInfo(genDecl *ast.GenDecl, pkgName strin
 
 			case *ast.InterfaceType:
 				interfaceInfo := a.extractInterfaceInfo(s, t, genDecl.Doc, pkgName)
+				interfaceInfo.Line = fset.Position(s.Pos()).Line
 				structure.Interfaces = append(structure.Interfaces, interfaceInfo)
 
 				// Add to exports if exported
@@ -1313,6 +1342,7 @@ func (a *ProjectAnalyzer) AnalyzeJavaScriptFile(filePath string) (*CodeStructure
 		if (strings.HasPrefix(line, "const ") || strings.HasPrefix(line, "let ") || strings.HasPrefix(line, "var ")) && strings.Contains(line, "=>") {
 			funcInfo := extractJavaScriptArrowFunction(line, pkgInfo.Name)
 			if funcInfo != nil {
+				funcInfo.Line = i + 1
 				structure.Functions = append(structure.Functions, *funcInfo)
 
 				// Check if exported
@@ -69,6 +69,7 @@ func extractPythonClass(lines []string, startLine int, pkgName string) (*ClassIn
 		Package:    pkgName,
 		Methods:    make([]FunctionInfo, 0),
 		IsExported: !strings.HasPrefix(className, "_"),
+		Line:       startLine + 1,
 	}
 
 	// Extract docstring
@@ -126,6 +127,7 @@ func extractPythonFunction(lines []string, startLine int, pkgName string, minInd
 		Parameters: make([]Parameter, 0),
 		Returns:    make([]ReturnValue, 0),
 		IsExported: !strings.HasPrefix(funcName, "_"),
+		Line:       startLine + 1,
 	}
 
 	// Parse parameters
@@ -371,6 +373,7 @@ func extractJavaScriptClass(lines []string, startLine int, pkgName string) (*Cla
 		Package:    pkgName,
 		Methods:    make([]FunctionInfo, 0),
 		IsExported: strings.Contains(line, "export"),
+		Line:       startLine + 1,
 	}
 
 	// Extract JSDoc comment if present (look backwards)
@@ -418,6 +421,7 @@ func extractJavaScriptClass(lines []string, startLine int, pkgName string) (*Cla
 					Parameters: make([]Parameter, 0),
 					Returns:    make([]ReturnValue, 0),
 					IsExported: classInfo.IsExported,
+					Line:       i + 1,
 				}
 
 				// Extract parameters
@@ -460,6 +464,7 @@ func extractJavaScriptFunction(lines []string, startLine int, pkgName string) (*
 		Parameters: parseJavaScriptParameters(paramsStr),
 		Returns:    make([]ReturnValue, 0),
 		IsExported: strings.Contains(line, "export"),
+		Line:       startLine + 1,
 	}
 
 	// Extract JSDoc comment if present (look backwards)
@@ -292,3 +292,38 @@ func TestProjectAnalyzer_AnalyzeJavaScriptFile_EmptyFile(t *testing.T) {
 		t.Errorf("Expected 0 classes in empty file, got %d", len(structure.Classes))
 	}
 }
+
+func TestProjectAnalyzer_DeclarationLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	pythonCode := "import os\n\nclass Greeter:\n    def greet(self):\n        pass\n\ndef main():\n    pass\n"
+	jsCode := "// util\nexport function add(a, b) {\n  return a + b\n}\n\nconst double = (x) => x * 2\n\nclass Counter {\n  increment() {\n  }\n}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.py"), []byte(pythonCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "util.js"), []byte(jsCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	analyzer := NewProjectAnalyzer(tmpDir, nil)
+
+	py, err := analyzer.AnalyzePythonFile("app.py")
+	if err != nil {
+		t.Fatalf("AnalyzePythonFile failed: %v", err)
+	}
+	if len(py.Classes) != 1 || py.Classes[0].Line != 3 || len(py.Classes[0].Methods) != 1 || py.Classes[0].Methods[0].Line != 4 {
+		t.Errorf("Expected Greeter on line 3 with greet on line 4, got %+v", py.Classes)
+	}
+	if len(py.Functions) != 1 || py.Functions[0].Line != 7 {
+		t.Errorf("Expected main on line 7, got %+v", py.Functions)
+	}
+
+	js, err := analyzer.AnalyzeJavaScriptFile("util.js")
+	if err != nil {
+		t.Fatalf("AnalyzeJavaScriptFile failed: %v", err)
+	}
+	if len(js.Functions) != 2 || js.Functions[0].Line != 2 || js.Functions[1].Line != 6 {
+		t.Errorf("Expected add on line 2 and double on line 6, got %+v", js.Functions)
+	}
+	if len(js.Classes) != 1 || js.Classes[0].Line != 8 || len(js.Classes[0].Methods) != 1 || js.Classes[0].Methods[0].Line != 9 {
+		t.Errorf("Expected Counter on line 8 with increment on line 9, got %+v", js.Classes)
+	}
+}
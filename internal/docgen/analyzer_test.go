@@ -817,6 +817,50 @@ func VariadicFunc(prefix string, values ...int) []string {
 	}
 }
 
+func TestProjectAnalyzer_AnalyzeGoFile_LinesAndReceivers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	goContent := `package shapes
+
+// Shape has an area
+type Shape interface {
+	Area() float64
+}
+
+type Box[T any] struct {
+	items []T
+}
+
+func (b *Box[T]) Len() int { return len(b.items) }
+
+func New() *Box[int] { return &Box[int]{} }
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "shapes.go"), []byte(goContent), 0644); err != nil {
+		t.Fatalf("Failed to create test Go file: %v", err)
+	}
+
+	structure, err := NewProjectAnalyzer(tmpDir, nil).AnalyzeGoFile("shapes.go")
+	if err != nil {
+		t.Fatalf("AnalyzeGoFile() error = %v", err)
+	}
+
+	if len(structure.Interfaces) != 1 || structure.Interfaces[0].Line != 4 {
+		t.Errorf("Expected Shape on line 4, got %+v", structure.Interfaces)
+	}
+	if len(structure.Structs) != 1 || structure.Structs[0].Line != 8 {
+		t.Errorf("Expected Box on line 8, got %+v", structure.Structs)
+	}
+	if len(structure.Functions) != 2 {
+		t.Fatalf("Expected 2 functions, got %d", len(structure.Functions))
+	}
+	if f := structure.Functions[0]; f.Name != "Len" || f.Receiver != "Box" || f.Line != 12 {
+		t.Errorf("Expected method Box.Len on line 12, got %s.%s on line %d", f.Receiver, f.Name, f.Line)
+	}
+	if f := structure.Functions[1]; f.Name != "New" || f.Receiver != "" || f.Line != 14 {
+		t.Errorf("Expected function New on line 14, got %s.%s on line %d", f.Receiver, f.Name, f.Line)
+	}
+}
+
 func TestProjectAnalyzer_AnalyzeGoFile_InvalidFile(t *testing.T) {
 	tmpDir := t.TempDir()
 
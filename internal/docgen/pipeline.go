@@ -1,7 +1,10 @@
 package docgen
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 
 	"github.com/user/terminal-intelligence/internal/ai"
 )
@@ -185,16 +188,46 @@ func (p *Pipeline) ProcessCommandWithOverwrite(input string, overwrite bool) (bo
 	return true, p.generateDocumentationWithOverwrite(parsed, overwrite)
 }
 
+// Generate runs the pipeline for a /doc command without an editor and returns
+// a result for every requested document, including ones skipped because the
+// file already exists or that failed, with the reason in Err. Unlike
+// ProcessCommand it reports a non-documentation command and an unavailable
+// AI service as errors. Cancelling ctx stops the generation.
+func (p *Pipeline) Generate(ctx context.Context, input string, overwrite bool) ([]*WriteResult, error) {
+	parsed, err := p.parser.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.IsDocRequest {
+		return nil, fmt.Errorf("not a documentation request: %s", input)
+	}
+	return p.writeDocumentation(ctx, parsed, overwrite)
+}
+
+// errAIUnavailable is returned by writeDocumentation when the AI service
+// cannot be reached; it has already been reported through the feedback manager
+var errAIUnavailable = errors.New("AI service is unavailable")
+
 // generateDocumentationWithOverwrite executes the pipeline with overwrite option
 func (p *Pipeline) generateDocumentationWithOverwrite(parsed *ParsedCommand, overwrite bool) error {
+	_, err := p.writeDocumentation(context.Background(), parsed, overwrite)
+	if errors.Is(err, errAIUnavailable) {
+		return nil
+	}
+	return err
+}
+
+// writeDocumentation analyzes the project, generates the requested documents
+// and writes them, returning the result of every requested document
+func (p *Pipeline) writeDocumentation(ctx context.Context, parsed *ParsedCommand, overwrite bool) ([]*WriteResult, error) {
 	// Classify the request
 	classification := p.classifier.Classify(parsed.NaturalLanguage)
 
 	// Check AI availability before creating any files
 	available, err := p.aiClient.IsAvailable()
 	if err != nil || !available {
-		p.feedback.NotifyError(fmt.Errorf("AI service is unavailable"))
-		return nil
+		p.feedback.NotifyError(errAIUnavailable)
+		return nil, errAIUnavailable
 	}
 
 	// Notify start
@@ -206,15 +239,30 @@ func (p *Pipeline) generateDocumentationWithOverwrite(parsed *ParsedCommand, ove
 	analysisResult, err := p.analyzer.Analyze()
 	if err != nil {
 		p.feedback.NotifyError(fmt.Errorf("analysis failed: %w", err))
-		return err
+		return nil, err
 	}
 
-	// Generate documentation
+	// Generate documentation; documents that fail are reported with the
+	// reason and the others are still written
 	p.feedback.NotifyProgress("Generating documentation", "")
-	docs, err := p.aiGenerator.GenerateMultiple(analysisResult, classification.Types)
-	if err != nil {
-		p.feedback.NotifyError(fmt.Errorf("generation failed: %w", err))
-		return err
+	var attempts []*WriteResult
+	var docs []*GeneratedDoc
+	for _, docType := range classification.Types {
+		doc, err := p.aiGenerator.GenerateContext(ctx, analysisResult, docType)
+		if ai.IsCancelled(err) {
+			return nil, err
+		}
+		if err != nil {
+			filename := docType.Filename()
+			attempts = append(attempts, &WriteResult{
+				Filename: filename,
+				Path:     filepath.Join(p.workspaceRoot, filename),
+				Existed:  p.writer.CheckExists(filename),
+				Err:      fmt.Errorf("generation failed: %w", err),
+			})
+			continue
+		}
+		docs = append(docs, doc)
 	}
 
 	// Write files
@@ -223,6 +271,10 @@ func (p *Pipeline) generateDocumentationWithOverwrite(parsed *ParsedCommand, ove
 
 	for _, doc := range docs {
 		result, err := p.writer.Write(doc, overwrite)
+		if result != nil {
+			result.Err = err
+			attempts = append(attempts, result)
+		}
 		if err != nil {
 			p.feedback.NotifyProgress("Warning", fmt.Sprintf("Failed to write %s: %v", doc.Filename, err))
 			continue
@@ -235,5 +287,5 @@ func (p *Pipeline) generateDocumentationWithOverwrite(parsed *ParsedCommand, ove
 		p.feedback.NotifyComplete(results, parsed.ScopeFilters)
 	}
 
-	return nil
+	return attempts, nil
 }
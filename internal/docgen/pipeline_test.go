@@ -1,11 +1,14 @@
 package docgen
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/types"
 	"pgregory.net/rapid"
 )
@@ -449,6 +452,64 @@ func TestPipeline_AIUnavailable_NoFilesWritten(t *testing.T) {
 	}
 }
 
+// TestPipeline_Generate_ReturnsWriteResults verifies that Generate writes the
+// requested documents and reports every write, including skipped conflicts.
+func TestPipeline_Generate_ReturnsWriteResults(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestProject(t, tmpDir)
+	if err := os.WriteFile(filepath.Join(tmpDir, "USER_MANUAL.md"), []byte("Existing content"), 0644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+
+	pipeline := NewPipeline(tmpDir, &MockAIClient{}, "test-model", &MockChatPane{})
+	results, err := pipeline.Generate(context.Background(), "/doc create user manual and API reference", false)
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 write results, got %d", len(results))
+	}
+
+	written := map[string]bool{}
+	for _, r := range results {
+		written[r.Filename] = r.Written
+		if r.Filename == "USER_MANUAL.md" && !errors.Is(r.Err, ErrFileExists) {
+			t.Errorf("Expected USER_MANUAL.md skipped with ErrFileExists, got %v", r.Err)
+		}
+	}
+	if written["USER_MANUAL.md"] {
+		t.Error("Existing USER_MANUAL.md should not be overwritten")
+	}
+	if !written["API_REFERENCE.md"] {
+		t.Errorf("Expected API_REFERENCE.md to be written, got %+v", written)
+	}
+}
+
+// TestPipeline_Generate_Errors verifies that Generate reports an unavailable AI
+// service and non-documentation input as errors.
+func TestPipeline_Generate_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pipeline := NewPipeline(tmpDir, &MockUnavailableAIClient{}, "test-model", &MockChatPane{})
+	if _, err := pipeline.Generate(context.Background(), "/doc api", false); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("Expected unavailable error, got %v", err)
+	}
+
+	pipeline = NewPipeline(tmpDir, &MockAIClient{}, "test-model", &MockChatPane{})
+	if _, err := pipeline.Generate(context.Background(), "write some docs", false); err == nil {
+		t.Error("Expected error for input without /doc")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pipeline.Generate(ctx, "/doc api", false); !ai.IsCancelled(err) {
+		t.Errorf("Expected a cancelled error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "API_REFERENCE.md")); !os.IsNotExist(err) {
+		t.Error("Expected nothing written after cancelling")
+	}
+}
+
 // Helper function to create a simple test project
 func createTestProject(t *testing.T, dir string) {
 	// Create a simple Go file
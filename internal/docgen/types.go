@@ -89,11 +89,13 @@ type PackageInfo struct {
 type FunctionInfo struct {
 	Name       string
 	Package    string
+	Receiver   string // Receiver type of a Go method, without the pointer
 	Signature  string
 	Parameters []Parameter
 	Returns    []ReturnValue
 	Comment    string
 	IsExported bool
+	Line       int // 1-based line of the declaration
 }
 
 // ClassInfo represents information about a class/struct
@@ -103,6 +105,7 @@ type ClassInfo struct {
 	Methods    []FunctionInfo
 	Comment    string
 	IsExported bool
+	Line       int // 1-based line of the declaration
 }
 
 // StructInfo represents information about a Go struct
@@ -113,6 +116,7 @@ type StructInfo struct {
 	Methods    []FunctionInfo
 	Comment    string
 	IsExported bool
+	Line       int // 1-based line of the declaration
 }
 
 // FieldInfo represents a struct field
@@ -130,6 +134,7 @@ type InterfaceInfo struct {
 	Methods    []MethodSignature
 	Comment    string
 	IsExported bool
+	Line       int // 1-based line of the declaration
 }
 
 // MethodSignature represents an interface method signature
@@ -217,4 +222,5 @@ type WriteResult struct {
 	Path     string // Full path to file
 	Existed  bool   // Whether file existed before
 	Written  bool   // Whether write succeeded
+	Err      error  // Why the document was not written (nil when Written)
 }
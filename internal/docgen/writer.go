@@ -1,6 +1,7 @@
 package docgen
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,6 +19,10 @@ func NewFileWriter(workspaceRoot string) *FileWriter {
 	}
 }
 
+// ErrFileExists is returned by Write for a file that exists when overwrite is
+// disabled
+var ErrFileExists = errors.New("already exists and overwrite is disabled")
+
 // Write saves a generated document to a file
 // If overwrite is false and the file exists, returns an error without writing
 // If overwrite is true, writes the file regardless of existence
@@ -39,7 +44,7 @@ func (w *FileWriter) Write(doc *GeneratedDoc, overwrite bool) (*WriteResult, err
 			Path:     fullPath,
 			Existed:  true,
 			Written:  false,
-		}, fmt.Errorf("file %s already exists and overwrite is disabled", doc.Filename)
+		}, fmt.Errorf("file %s %w", doc.Filename, ErrFileExists)
 	}
 
 	// Write the file
@@ -412,6 +412,66 @@ func (fm *FileManager) CreateDirectory(dirPath string) error {
 	return nil
 }
 
+// RenamePath renames or moves a file or directory, creating the parent
+// directories of the destination. It refuses to overwrite an existing path.
+func (fm *FileManager) RenamePath(oldPath, newPath string) error {
+	fullOld := fm.resolvePath(oldPath)
+	fullNew := fm.resolvePath(newPath)
+
+	if _, err := os.Stat(fullOld); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file not found: %s", fullOld)
+		}
+		return fmt.Errorf("failed to rename %s: %w", fullOld, err)
+	}
+	if _, err := os.Stat(fullNew); err == nil {
+		return fmt.Errorf("already exists: %s", fullNew)
+	}
+	if fullNew == fullOld || strings.HasPrefix(fullNew, fullOld+string(filepath.Separator)) {
+		return fmt.Errorf("cannot move %s into itself", fullOld)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullNew), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(fullNew), err)
+	}
+	if err := os.Rename(fullOld, fullNew); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied: %s", fullOld)
+		}
+		return fmt.Errorf("failed to rename %s: %w", fullOld, err)
+	}
+
+	return nil
+}
+
+// DeleteDirectory deletes a directory and everything in it
+func (fm *FileManager) DeleteDirectory(dirPath string) error {
+	fullPath := fm.resolvePath(dirPath)
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("directory not found: %s", fullPath)
+		}
+		return fmt.Errorf("failed to delete directory %s: %w", fullPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", fullPath)
+	}
+	if fullPath == filepath.Clean(fm.workspaceDir) {
+		return fmt.Errorf("refusing to delete the workspace directory")
+	}
+
+	if err := os.RemoveAll(fullPath); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied: %s", fullPath)
+		}
+		return fmt.Errorf("failed to delete directory %s: %w", fullPath, err)
+	}
+
+	return nil
+}
+
 // ListEntries returns directories and files in the given directory (non-recursive)
 func (fm *FileManager) ListEntries(dirPath string) ([]string, []string, error) {
 	entries, err := os.ReadDir(dirPath)
@@ -453,6 +513,12 @@ func (fm *FileManager) ListEntries(dirPath string) ([]string, []string, error) {
 	return dirs, files, nil
 }
 
+// ResolvePath returns the path a file operation on path would use: absolute
+// paths are kept and relative ones are joined with the workspace directory
+func (fm *FileManager) ResolvePath(path string) string {
+	return fm.resolvePath(path)
+}
+
 // resolvePath resolves a relative path to an absolute path within the workspace
 func (fm *FileManager) resolvePath(path string) string {
 	// Clean the path to prevent directory traversal
@@ -3,12 +3,14 @@ package gemini
 import (
 	"bufio"
 	"bytes"
+	stdcontext "context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/types"
 )
 
@@ -47,6 +49,7 @@ type geminiRequest struct {
 }
 
 type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"; omitted for single-turn requests
 	Parts []geminiPart `json:"parts"`
 }
 
@@ -92,6 +95,12 @@ func (gc *GeminiClient) IsAvailable() (bool, error) {
 // This uses streamGenerateContent to receive chunks as they are generated,
 // providing real-time streaming and reliable token usage reporting.
 func (gc *GeminiClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	return gc.GenerateContext(stdcontext.Background(), prompt, model, onTokenUsage)
+}
+
+// GenerateContext generates AI response from Gemini until ctx is done.
+// Cancelling ctx aborts the HTTP request and closes the returned channel.
+func (gc *GeminiClient) GenerateContext(ctx stdcontext.Context, prompt string, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
 	if model == "" {
 		model = "gemini-2.0-flash-exp"
 	}
@@ -106,13 +115,44 @@ func (gc *GeminiClient) Generate(prompt string, model string, context []int, onT
 		},
 	}
 
+	return gc.streamContent(ctx, reqBody, model, onTokenUsage)
+}
+
+// Chat generates AI response from Gemini for a multi-turn conversation until
+// ctx is done. Assistant turns are sent with the "model" role expected by the
+// Gemini API.
+func (gc *GeminiClient) Chat(ctx stdcontext.Context, messages []ai.Message, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	if model == "" {
+		model = "gemini-2.0-flash-exp"
+	}
+
+	reqBody := geminiRequest{
+		Contents: make([]geminiContent, len(messages)),
+	}
+	for i, msg := range messages {
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "model"
+		}
+		reqBody.Contents[i] = geminiContent{
+			Role:  role,
+			Parts: []geminiPart{{Text: msg.Content}},
+		}
+	}
+
+	return gc.streamContent(ctx, reqBody, model, onTokenUsage)
+}
+
+// streamContent sends reqBody to streamGenerateContent and streams the
+// response text through the returned channel
+func (gc *GeminiClient) streamContent(ctx stdcontext.Context, reqBody geminiRequest, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse&key=%s", gc.baseURL, model, gc.apiKey)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -159,7 +199,10 @@ func (gc *GeminiClient) Generate(prompt string, model string, context []int, onT
 
 			// Check for API error in stream
 			if chunk.Error != nil {
-				responseChan <- fmt.Sprintf("Error: %s", chunk.Error.Message)
+				select {
+				case responseChan <- fmt.Sprintf("Error: %s", chunk.Error.Message):
+				case <-ctx.Done():
+				}
 				return
 			}
 
@@ -169,7 +212,11 @@ func (gc *GeminiClient) Generate(prompt string, model string, context []int, onT
 				if len(candidate.Content.Parts) > 0 {
 					text := candidate.Content.Parts[0].Text
 					if text != "" {
-						responseChan <- text
+						select {
+						case responseChan <- text:
+						case <-ctx.Done():
+							return
+						}
 					}
 				}
 			}
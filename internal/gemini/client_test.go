@@ -1,12 +1,14 @@
 package gemini
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/types"
 )
 
@@ -334,3 +336,106 @@ func TestGenerate_StreamingUsesCorrectEndpoint(t *testing.T) {
 		t.Errorf("expected path %q, got %q", expected, capturedPath)
 	}
 }
+
+func TestChat_SendsRolesInContents(t *testing.T) {
+	var captured geminiRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeSSEChunk(w, map[string]interface{}{
+			"candidates": []map[string]interface{}{
+				{
+					"content": map[string]interface{}{
+						"parts": []map[string]interface{}{
+							{"text": "Your name is Ada."},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer mockServer.Close()
+
+	client := NewGeminiClientWithURL("test-key", mockServer.URL)
+
+	responseChan, err := client.Chat(context.Background(), []ai.Message{
+		{Role: "user", Content: "My name is Ada."},
+		{Role: "assistant", Content: "Nice to meet you, Ada."},
+		{Role: "user", Content: "What is my name?"},
+	}, "gemini-2.0-flash", nil)
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+
+	var fullResponse string
+	for chunk := range responseChan {
+		fullResponse += chunk
+	}
+
+	if fullResponse != "Your name is Ada." {
+		t.Errorf("expected response 'Your name is Ada.', got %q", fullResponse)
+	}
+
+	wantRoles := []string{"user", "model", "user"}
+	if len(captured.Contents) != len(wantRoles) {
+		t.Fatalf("expected %d contents, got %d", len(wantRoles), len(captured.Contents))
+	}
+	for i, role := range wantRoles {
+		if captured.Contents[i].Role != role {
+			t.Errorf("contents[%d]: expected role %q, got %q", i, role, captured.Contents[i].Role)
+		}
+	}
+	if captured.Contents[2].Parts[0].Text != "What is my name?" {
+		t.Errorf("expected last turn text to be preserved, got %q", captured.Contents[2].Parts[0].Text)
+	}
+}
+
+func TestChatTools_SendsFunctionDeclarationsAndParsesCalls(t *testing.T) {
+	var captured toolRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:generateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"candidates": [{"content": {"role": "model", "parts": [{"text": "Reading it."}, {"functionCall": {"name": "read_file", "args": {"path": "a.go"}}}]}}], "usageMetadata": {"promptTokenCount": 7, "candidatesTokenCount": 2, "totalTokenCount": 9}}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewGeminiClientWithURL("key", mockServer.URL)
+	reply, err := client.ChatTools(context.Background(), []ai.ToolMessage{
+		{Role: "user", Content: "fix a.go"},
+		{Role: "assistant", ToolCalls: []ai.ToolCall{{ID: "call_1", Name: "run_tests", Arguments: json.RawMessage(`{}`)}}},
+		{Role: "tool", Results: []ai.ToolResult{{CallID: "call_1", Name: "run_tests", Content: "FAIL", IsError: true}}},
+	}, []ai.ToolSpec{{Name: "read_file", Parameters: map[string]interface{}{"type": "object"}}}, "gemini-test")
+	if err != nil {
+		t.Fatalf("ChatTools returned error: %v", err)
+	}
+
+	if len(captured.Tools) != 1 || len(captured.Tools[0].FunctionDeclarations) != 1 {
+		t.Fatalf("unexpected tools in request: %+v", captured.Tools)
+	}
+	if len(captured.Contents) != 3 {
+		t.Fatalf("expected 3 contents, got %d", len(captured.Contents))
+	}
+	if captured.Contents[1].Role != "model" || captured.Contents[1].Parts[0].FunctionCall == nil {
+		t.Errorf("expected model turn with functionCall, got %+v", captured.Contents[1])
+	}
+	result := captured.Contents[2].Parts[0].FunctionResponse
+	if captured.Contents[2].Role != "user" || result == nil || result.Response["error"] != "FAIL" {
+		t.Errorf("expected user turn with error functionResponse, got %+v", captured.Contents[2])
+	}
+
+	if reply.Content != "Reading it." {
+		t.Errorf("unexpected content: %q", reply.Content)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].Name != "read_file" || string(reply.ToolCalls[0].Arguments) != `{"path": "a.go"}` {
+		t.Fatalf("unexpected tool calls: %+v", reply.ToolCalls)
+	}
+	if reply.Usage.TotalTokens != 9 {
+		t.Errorf("unexpected token usage: %+v", reply.Usage)
+	}
+}
@@ -0,0 +1,20 @@
+package gemini
+
+import "github.com/user/terminal-intelligence/internal/ai"
+
+func init() {
+	ai.RegisterProvider(ai.ProviderSpec{
+		Name:         "gemini",
+		Description:  "Google Gemini API",
+		ModelKey:     "gmodel",
+		DefaultModel: "gemini-2.0-flash-exp",
+		Fields: []ai.ConfigField{
+			{Key: "gmodel", Description: "Gemini model name"},
+			{Key: "gemini_api", Description: "Gemini API key", Secret: true, Required: true},
+		},
+		Capabilities: ai.Capabilities{Chat: true, Cancel: true, Tools: true},
+		New: func(settings ai.Settings) (ai.AIClient, error) {
+			return NewGeminiClient(settings.Get("gemini_api")), nil
+		},
+	})
+}
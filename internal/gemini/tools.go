@@ -0,0 +1,160 @@
+package gemini
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// functionDeclaration describes a tool in the Gemini "tools" list
+type functionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+}
+
+// functionCall is a tool call requested by the model
+type functionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// functionResponse returns a tool result to the model
+type functionResponse struct {
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+// toolPart is a content part that may hold text, a function call or a
+// function response
+type toolPart struct {
+	Text             string            `json:"text,omitempty"`
+	FunctionCall     *functionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *functionResponse `json:"functionResponse,omitempty"`
+}
+
+type toolContent struct {
+	Role  string     `json:"role"`
+	Parts []toolPart `json:"parts"`
+}
+
+type toolRequest struct {
+	Contents []toolContent `json:"contents"`
+	Tools    []geminiTool  `json:"tools"`
+}
+
+type toolResponse struct {
+	Candidates []struct {
+		Content toolContent `json:"content"`
+	} `json:"candidates"`
+	Error         *geminiError         `json:"error,omitempty"`
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+// ChatTools sends a tool-using conversation to generateContent with native
+// function declarations and returns the model's reply. Tool results are sent
+// as functionResponse parts in a "user" turn.
+func (gc *GeminiClient) ChatTools(ctx stdcontext.Context, messages []ai.ToolMessage, tools []ai.ToolSpec, model string) (*ai.ToolReply, error) {
+	if model == "" {
+		model = "gemini-2.0-flash-exp"
+	}
+
+	decls := make([]functionDeclaration, len(tools))
+	for i, t := range tools {
+		decls[i] = functionDeclaration{Name: t.Name, Description: t.Description, Parameters: t.Parameters}
+	}
+	reqBody := toolRequest{Tools: []geminiTool{{FunctionDeclarations: decls}}}
+
+	for _, msg := range messages {
+		content := toolContent{Role: "user"}
+		if msg.Role == "assistant" {
+			content.Role = "model"
+		}
+		if msg.Content != "" {
+			content.Parts = append(content.Parts, toolPart{Text: msg.Content})
+		}
+		for _, call := range msg.ToolCalls {
+			content.Parts = append(content.Parts, toolPart{FunctionCall: &functionCall{Name: call.Name, Args: call.Arguments}})
+		}
+		for _, result := range msg.Results {
+			response := map[string]interface{}{"content": result.Content}
+			if result.IsError {
+				response = map[string]interface{}{"error": result.Content}
+			}
+			content.Parts = append(content.Parts, toolPart{FunctionResponse: &functionResponse{Name: result.Name, Response: response}})
+		}
+		if len(content.Parts) > 0 {
+			reqBody.Contents = append(reqBody.Contents, content)
+		}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", gc.baseURL, model, gc.apiKey)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Gemini API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var geminiResp toolResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if geminiResp.Error != nil {
+		return nil, fmt.Errorf("Gemini API error: %s", geminiResp.Error.Message)
+	}
+
+	reply := &ai.ToolReply{}
+	if geminiResp.UsageMetadata != nil {
+		reply.Usage = types.TokenUsage{
+			InputTokens:  geminiResp.UsageMetadata.PromptTokenCount,
+			OutputTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:  geminiResp.UsageMetadata.TotalTokenCount,
+		}
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return reply, nil
+	}
+
+	for _, part := range geminiResp.Candidates[0].Content.Parts {
+		reply.Content += part.Text
+		if part.FunctionCall != nil {
+			args := part.FunctionCall.Args
+			if len(args) == 0 {
+				args = json.RawMessage("{}")
+			}
+			reply.ToolCalls = append(reply.ToolCalls, ai.ToolCall{
+				ID:        fmt.Sprintf("call_%d", len(reply.ToolCalls)+1),
+				Name:      part.FunctionCall.Name,
+				Arguments: args,
+			})
+		}
+	}
+
+	return reply, nil
+}
@@ -0,0 +1,217 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshKeyFiles are the private keys tried, in order, when no ssh-agent is
+// running. They are looked up in ~/.ssh like the ssh command does.
+var sshKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// helperTimeout bounds a call to the git credential helper, which may be a
+// slow keychain or a network service.
+const helperTimeout = 10 * time.Second
+
+// remoteAuth is how an operation authenticates against a remote, and what
+// to remember once it has succeeded.
+type remoteAuth struct {
+	method transport.AuthMethod
+	creds  *Credentials // Credentials to remember on success, nil for none
+	helper bool         // creds came from the git credential helper
+}
+
+// urlProtocol returns the transport of url: "ssh" for ssh://... and the
+// scp-like user@host:path form, otherwise "https", "http", "file" and so on.
+func urlProtocol(url string) string {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return ""
+	}
+	return endpoint.Protocol
+}
+
+// originURL returns the first URL of the origin remote, or "" if there is
+// none.
+func originURL(repo *git.Repository) string {
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// authFor chooses how to authenticate against url. SSH remotes use the
+// ssh-agent or a key from ~/.ssh, with password as the key's passphrase.
+// HTTP(S) remotes use the given username and password or, when both are
+// empty, whatever the user's configured git credential helper provides.
+func (c *Client) authFor(url, username, password string) (*remoteAuth, error) {
+	var creds *Credentials
+	if password != "" {
+		creds = &Credentials{URL: url, Username: username, Password: password}
+	}
+
+	protocol := urlProtocol(url)
+	if protocol == "ssh" {
+		method, err := sshAuth(url, password)
+		if err != nil {
+			return nil, err
+		}
+		return &remoteAuth{method: method, creds: creds}, nil
+	}
+
+	if username == "" && password == "" && (protocol == "https" || protocol == "http") {
+		if filled, err := credentialHelper(c.workDir, "fill", &Credentials{URL: url}); err == nil && filled.Password != "" {
+			return &remoteAuth{
+				method: createAuth(filled.Username, filled.Password),
+				creds:  filled,
+				helper: true,
+			}, nil
+		}
+		// Without a helper the remote may still allow anonymous access
+		return &remoteAuth{}, nil
+	}
+
+	return &remoteAuth{method: createAuth(username, password), creds: creds}, nil
+}
+
+// remember records the outcome of an authenticated operation in dir.
+// Credentials typed into the panel are kept in the encrypted store; ones
+// from the credential helper are approved, or rejected after an
+// authentication failure so the helper stops offering them.
+func (c *Client) remember(dir string, auth *remoteAuth, opErr error) {
+	if auth == nil || auth.creds == nil {
+		return
+	}
+	if opErr != nil {
+		var gitErr *GitError
+		if auth.helper && errors.As(categorizeError(opErr), &gitErr) && gitErr.Category == "Authentication" {
+			credentialHelper(dir, "reject", auth.creds)
+		}
+		return
+	}
+	if auth.helper {
+		credentialHelper(dir, "approve", auth.creds)
+		return
+	}
+	// Credential save errors are non-critical
+	NewStore(dir).Save(auth.creds)
+}
+
+// sshAuth authenticates as the user named in url, or "git", through the
+// ssh-agent when SSH_AUTH_SOCK is set, falling back to the keys in ~/.ssh.
+// Every key that can be read is offered, in order, so the server can accept
+// any of them; keys that cannot be read are skipped. Host keys are checked
+// against ~/.ssh/known_hosts.
+func sshAuth(url, passphrase string) (transport.AuthMethod, error) {
+	user := gitssh.DefaultUsername
+	if endpoint, err := transport.NewEndpoint(url); err == nil && endpoint.User != "" {
+		user = endpoint.User
+	}
+
+	if os.Getenv("SSH_AUTH_SOCK") != "" {
+		if agent, err := gitssh.NewSSHAgentAuth(user); err == nil {
+			return agent, nil
+		}
+	}
+
+	var signers []ssh.Signer
+	var keyErr error
+	home, err := os.UserHomeDir()
+	if err == nil {
+		for _, name := range sshKeyFiles {
+			path := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			keys, err := gitssh.NewPublicKeysFromFile(user, path, passphrase)
+			if err != nil {
+				if keyErr == nil {
+					keyErr = &GitError{
+						Category: "Authentication",
+						Message:  fmt.Sprintf("Authentication failed: cannot read SSH key %s: %v", path, err),
+						Hint:     "Enter the key's passphrase in the password field, or load the key into ssh-agent",
+						Original: err,
+					}
+				}
+				continue
+			}
+			signers = append(signers, keys.Signer)
+		}
+	}
+	if len(signers) > 0 {
+		return &gitssh.PublicKeysCallback{
+			User:     user,
+			Callback: func() ([]ssh.Signer, error) { return signers, nil },
+		}, nil
+	}
+	if keyErr != nil {
+		return nil, keyErr
+	}
+
+	return nil, &GitError{
+		Category: "Authentication",
+		Message:  "Authentication failed: no SSH key found",
+		Hint:     "Start ssh-agent with your key loaded, or create a key in ~/.ssh (id_ed25519, id_ecdsa or id_rsa)",
+	}
+}
+
+// credentialHelper runs `git credential <action>` in dir, which hands the
+// request to whatever credential helper the user has configured. For
+// "fill" it returns the credentials the helper provided; "approve" and
+// "reject" tell the helper whether they worked. Terminal prompts are
+// disabled so a missing helper fails instead of taking over the screen.
+func credentialHelper(dir, action string, creds *Credentials) (*Credentials, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git credential helper unavailable: %w", err)
+	}
+
+	var input strings.Builder
+	input.WriteString("url=" + creds.URL + "\n")
+	if creds.Username != "" {
+		input.WriteString("username=" + creds.Username + "\n")
+	}
+	if creds.Password != "" {
+		input.WriteString("password=" + creds.Password + "\n")
+	}
+	input.WriteString("\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "credential", action)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	cmd.Stdin = strings.NewReader(input.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git credential %s failed: %w", action, err)
+	}
+
+	filled := &Credentials{URL: creds.URL}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "username":
+			filled.Username = value
+		case "password":
+			filled.Password = value
+		}
+	}
+	return filled, nil
+}
@@ -0,0 +1,181 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+func TestURLProtocol(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:user/repo.git":       "ssh",
+		"ssh://git@github.com/user/repo.git": "ssh",
+		"https://github.com/user/repo.git":   "https",
+		"http://example.com/repo.git":        "http",
+		"file:///tmp/repo":                   "file",
+	}
+	for url, want := range tests {
+		if got := urlProtocol(url); got != want {
+			t.Errorf("urlProtocol(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// isolateSSH points HOME at an empty directory and hides any ssh-agent.
+func isolateSSH(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	return home
+}
+
+func TestAuthFor_SSHWithoutKey(t *testing.T) {
+	isolateSSH(t)
+	client := NewClient(t.TempDir())
+
+	_, err := client.authFor("git@github.com:user/repo.git", "", "")
+	gitErr, ok := err.(*GitError)
+	if !ok || gitErr.Category != "Authentication" {
+		t.Fatalf("Expected an authentication error, got %v", err)
+	}
+}
+
+func TestAuthFor_SSHKeyFile(t *testing.T) {
+	home := isolateSSH(t)
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	keyPath := filepath.Join(home, ".ssh", "id_ed25519")
+	os.MkdirAll(filepath.Dir(keyPath), 0700)
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "secret", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, out)
+	}
+	client := NewClient(t.TempDir())
+
+	auth, err := client.authFor("ssh://deploy@example.com/repo.git", "", "secret")
+	if err != nil {
+		t.Fatalf("authFor failed: %v", err)
+	}
+	keys, ok := auth.method.(*gitssh.PublicKeysCallback)
+	if !ok {
+		t.Fatalf("Expected public key auth, got %T", auth.method)
+	}
+	if keys.User != "deploy" {
+		t.Errorf("Expected user from the URL, got %q", keys.User)
+	}
+
+	if _, err := client.authFor("git@example.com:repo.git", "", "wrong"); err == nil {
+		t.Error("Expected a wrong passphrase to fail")
+	}
+}
+
+func TestAuthFor_SSHTriesEveryKey(t *testing.T) {
+	home := isolateSSH(t)
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	os.MkdirAll(filepath.Join(home, ".ssh"), 0700)
+	keygen := func(name, kind, passphrase string) {
+		path := filepath.Join(home, ".ssh", name)
+		if out, err := exec.Command("ssh-keygen", "-q", "-t", kind, "-N", passphrase, "-f", path).CombinedOutput(); err != nil {
+			t.Fatalf("ssh-keygen failed: %v\n%s", err, out)
+		}
+	}
+	// The first key has another passphrase, the other two can be read
+	keygen("id_ed25519", "ed25519", "other")
+	keygen("id_ecdsa", "ecdsa", "secret")
+	keygen("id_rsa", "rsa", "secret")
+	client := NewClient(t.TempDir())
+
+	auth, err := client.authFor("git@example.com:repo.git", "", "secret")
+	if err != nil {
+		t.Fatalf("authFor failed: %v", err)
+	}
+	keys, ok := auth.method.(*gitssh.PublicKeysCallback)
+	if !ok {
+		t.Fatalf("Expected public key auth, got %T", auth.method)
+	}
+	signers, err := keys.Callback()
+	if err != nil || len(signers) != 2 {
+		t.Fatalf("Expected the two readable keys to be offered, got %d (%v)", len(signers), err)
+	}
+	if signers[0].PublicKey().Type() != "ecdsa-sha2-nistp256" || signers[1].PublicKey().Type() != "ssh-rsa" {
+		t.Errorf("Expected the keys in lookup order, got %s and %s", signers[0].PublicKey().Type(), signers[1].PublicKey().Type())
+	}
+}
+
+// useCredentialHelper configures a git credential helper that answers
+// with fixed credentials, in an isolated global config.
+func useCredentialHelper(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	home := isolateSSH(t)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	config := "[credential]\n\thelper = \"!f() { test \\\"$1\\\" = get && echo username=helper-user && echo password=helper-pass; }; f\"\n"
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write git config: %v", err)
+	}
+}
+
+func TestAuthFor_CredentialHelper(t *testing.T) {
+	useCredentialHelper(t)
+	client := NewClient(t.TempDir())
+
+	auth, err := client.authFor("https://example.com/repo.git", "", "")
+	if err != nil {
+		t.Fatalf("authFor failed: %v", err)
+	}
+	if !auth.helper {
+		t.Fatal("Expected credentials from the helper")
+	}
+	basic, ok := auth.method.(*http.BasicAuth)
+	if !ok || basic.Username != "helper-user" || basic.Password != "helper-pass" {
+		t.Errorf("Expected the helper's credentials, got %#v", auth.method)
+	}
+
+	// Typed credentials take precedence over the helper
+	auth, err = client.authFor("https://example.com/repo.git", "me", "typed")
+	if err != nil {
+		t.Fatalf("authFor failed: %v", err)
+	}
+	if auth.helper || auth.method.(*http.BasicAuth).Password != "typed" {
+		t.Errorf("Expected the typed credentials, got %#v", auth.method)
+	}
+}
+
+func TestAuthFor_NoCredentialHelper(t *testing.T) {
+	isolateSSH(t)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	client := NewClient(t.TempDir())
+
+	auth, err := client.authFor("https://example.com/repo.git", "", "")
+	if err != nil {
+		t.Fatalf("authFor failed: %v", err)
+	}
+	if auth.method != nil || auth.creds != nil {
+		t.Errorf("Expected anonymous access, got %#v", auth)
+	}
+}
+
+func TestRemember_HelperCredentialsNotStored(t *testing.T) {
+	useCredentialHelper(t)
+	dir := newCredentialRepo(t, "")
+	client := NewClient(dir)
+
+	client.remember(dir, &remoteAuth{creds: &Credentials{URL: "https://example.com/repo.git", Username: "u", Password: "p"}, helper: true}, nil)
+	if _, err := os.Stat(filepath.Join(dir, ".git", storeFile)); !os.IsNotExist(err) {
+		t.Errorf("Helper credentials should be left to the helper, got %v", err)
+	}
+
+	client.remember(dir, &remoteAuth{creds: &Credentials{URL: "https://example.com/repo.git", Username: "u", Password: "p"}}, nil)
+	if creds, err := NewStore(dir).Load(); err != nil || creds.Password != "p" {
+		t.Errorf("Typed credentials should be stored, got %+v (%v)", creds, err)
+	}
+}
@@ -0,0 +1,516 @@
+package git
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// BranchInfo describes a local branch and how it relates to its upstream.
+type BranchInfo struct {
+	Name     string // Short branch name, e.g. "main"
+	Hash     string // Abbreviated hash of the branch tip
+	Current  bool   // Whether HEAD points at this branch
+	Upstream string // Remote-tracking branch, e.g. "origin/main"; empty if none is set
+	Ahead    int    // Commits on the branch that are not on the upstream
+	Behind   int    // Commits on the upstream that are not on the branch
+	Gone     bool   // Whether the upstream is configured but the remote-tracking ref is missing
+}
+
+// failedResult builds the failure return of an operation from err.
+func failedResult(err error) (*OperationResult, error) {
+	err = categorizeError(err)
+	return &OperationResult{Success: false, Message: "", Error: err}, err
+}
+
+// openRepo opens the repository in the working directory.
+func (c *Client) openRepo() (*git.Repository, error) {
+	return git.PlainOpen(c.workDir)
+}
+
+// validateBranchName checks that name can be used as a local branch name.
+func validateBranchName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+	if err := plumbing.NewBranchReferenceName(name).Validate(); err != nil {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// Branches lists the local branches sorted by name, with the upstream of
+// each branch and how far ahead of and behind it the branch is.
+//
+// Returns:
+//   - []BranchInfo: One entry per local branch
+//   - error: Any error that occurred while reading the repository
+func (c *Client) Branches() ([]BranchInfo, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	currentBranch := ""
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		currentBranch = head.Name().Short()
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	var branches []BranchInfo
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		info := BranchInfo{
+			Name:    ref.Name().Short(),
+			Hash:    ref.Hash().String()[:7],
+			Current: ref.Name().Short() == currentBranch,
+		}
+
+		if bc, ok := cfg.Branches[info.Name]; ok && bc.Remote != "" && bc.Merge.IsBranch() {
+			info.Upstream = bc.Remote + "/" + bc.Merge.Short()
+			upstream, err := repo.Reference(plumbing.NewRemoteReferenceName(bc.Remote, bc.Merge.Short()), true)
+			if err != nil {
+				info.Gone = true
+			} else {
+				info.Ahead, info.Behind, err = aheadBehind(repo, ref.Hash(), upstream.Hash())
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		branches = append(branches, info)
+		return nil
+	})
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// aheadBehind counts the commits reachable from local but not from upstream
+// (ahead) and from upstream but not from local (behind). Both histories are
+// walked together, newest commit first, and the walk stops once every pending
+// commit is reachable from both sides, so only the commits since the merge
+// base are read.
+func aheadBehind(repo *git.Repository, local, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	const fromLocal, fromUpstream = 1, 2
+	flags := make(map[plumbing.Hash]int)
+	queue := &commitQueue{}
+	mark := func(hash plumbing.Hash, side int) error {
+		if flags[hash]&side == side {
+			return nil
+		}
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		flags[hash] |= side
+		heap.Push(queue, commit)
+		return nil
+	}
+	if err := mark(local, fromLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := mark(upstream, fromUpstream); err != nil {
+		return 0, 0, err
+	}
+
+	// pending reports whether a queued commit is not yet known to both sides.
+	pending := func() bool {
+		for _, commit := range *queue {
+			if flags[commit.Hash] != fromLocal|fromUpstream {
+				return true
+			}
+		}
+		return false
+	}
+	for queue.Len() > 0 && pending() {
+		commit := heap.Pop(queue).(*object.Commit)
+		side := flags[commit.Hash]
+		for _, parent := range commit.ParentHashes {
+			if err := mark(parent, side); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	ahead, behind := 0, 0
+	for _, side := range flags {
+		switch side {
+		case fromLocal:
+			ahead++
+		case fromUpstream:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitQueue is a heap of commits ordered newest first.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// reachableCommits returns the set of commits reachable from hash.
+func reachableCommits(repo *git.Repository, hash plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	iter, err := repo.Log(&git.LogOptions{From: hash})
+	if err != nil {
+		return nil, err
+	}
+	commits := make(map[plumbing.Hash]bool)
+	err = iter.ForEach(func(commit *object.Commit) error {
+		commits[commit.Hash] = true
+		return nil
+	})
+	return commits, err
+}
+
+// CreateBranch creates a local branch at the current HEAD commit and, if
+// checkout is true, switches to it.
+//
+// Parameters:
+//   - name: The name of the new branch
+//   - checkout: Whether to switch to the new branch
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) CreateBranch(name string, checkout bool) (*OperationResult, error) {
+	if err := validateBranchName(name); err != nil {
+		return failedResult(err)
+	}
+
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	refName := plumbing.NewBranchReferenceName(name)
+	if _, err := repo.Reference(refName, false); err == nil {
+		return failedResult(fmt.Errorf("branch %q already exists", name))
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(fmt.Errorf("cannot create a branch before the first commit: %w", err))
+	}
+
+	if checkout {
+		if err := ensureClean(repo); err != nil {
+			return failedResult(err)
+		}
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, head.Hash())); err != nil {
+		return failedResult(err)
+	}
+
+	if !checkout {
+		return &OperationResult{
+			Success: true,
+			Message: fmt.Sprintf("Created branch %s at %s", name, head.Hash().String()[:7]),
+			Error:   nil,
+		}, nil
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: refName}); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Created and switched to branch %s", name),
+		Error:   nil,
+	}, nil
+}
+
+// SwitchBranch checks out a local branch. If there is no local branch of
+// that name but origin has one, a local branch tracking it is created first.
+// Switching is refused while the worktree has uncommitted changes, so they
+// are never lost.
+//
+// Parameters:
+//   - name: The branch to switch to
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) SwitchBranch(name string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	if head, err := repo.Head(); err == nil && head.Name() == plumbing.NewBranchReferenceName(name) {
+		return &OperationResult{Success: true, Message: "Already on branch " + name, Error: nil}, nil
+	}
+
+	refName := plumbing.NewBranchReferenceName(name)
+	_, localErr := repo.Reference(refName, false)
+	var remoteRef *plumbing.Reference
+	if localErr != nil {
+		remoteRef, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", name), true)
+		if err != nil {
+			return failedResult(fmt.Errorf("branch %q does not exist", name))
+		}
+	}
+
+	if err := ensureClean(repo); err != nil {
+		return failedResult(err)
+	}
+
+	message := "Switched to branch " + name
+	if remoteRef != nil {
+		// Create a local branch that tracks origin/<name>
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, remoteRef.Hash())); err != nil {
+			return failedResult(err)
+		}
+		if err := setUpstream(repo, name, "origin", name); err != nil {
+			return failedResult(err)
+		}
+		message = fmt.Sprintf("Switched to new branch %s tracking origin/%s", name, name)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: refName}); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{Success: true, Message: message, Error: nil}, nil
+}
+
+// ensureClean returns an error if the worktree has staged or unstaged
+// changes to tracked files. Untracked files are carried across a switch.
+func ensureClean(repo *git.Repository) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	for _, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked {
+			continue
+		}
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			return fmt.Errorf("you have uncommitted changes; commit or restore them first")
+		}
+	}
+	return nil
+}
+
+// DeleteBranch deletes a local branch and its upstream configuration. The
+// current branch can never be deleted; unless force is true, a branch whose
+// commits are not all on the current branch is kept.
+//
+// Parameters:
+//   - name: The branch to delete
+//   - force: Delete the branch even if it is not merged into HEAD
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) DeleteBranch(name string, force bool) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	refName := plumbing.NewBranchReferenceName(name)
+	ref, err := repo.Reference(refName, false)
+	if err != nil {
+		return failedResult(fmt.Errorf("branch %q does not exist", name))
+	}
+
+	head, err := repo.Head()
+	if err == nil && head.Name() == refName {
+		return failedResult(fmt.Errorf("cannot delete the current branch %q", name))
+	}
+
+	if !force && err == nil {
+		merged, err := isMerged(repo, ref.Hash(), head.Hash())
+		if err != nil {
+			return failedResult(err)
+		}
+		if !merged {
+			return failedResult(fmt.Errorf("branch %q is not fully merged; force delete to discard its commits", name))
+		}
+	}
+
+	if err := repo.Storer.RemoveReference(refName); err != nil {
+		return failedResult(err)
+	}
+	if err := repo.DeleteBranch(name); err != nil && err != git.ErrBranchNotFound {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Deleted branch %s (was %s)", name, ref.Hash().String()[:7]),
+		Error:   nil,
+	}, nil
+}
+
+// isMerged reports whether the commit branch is reachable from head.
+func isMerged(repo *git.Repository, branch, head plumbing.Hash) (bool, error) {
+	if branch == head {
+		return true, nil
+	}
+	branchCommit, err := repo.CommitObject(branch)
+	if err != nil {
+		return false, err
+	}
+	headCommit, err := repo.CommitObject(head)
+	if err != nil {
+		return false, err
+	}
+	return branchCommit.IsAncestor(headCommit)
+}
+
+// RenameBranch renames a local branch, keeping its upstream configuration.
+// Renaming the current branch keeps it checked out.
+//
+// Parameters:
+//   - oldName: The branch to rename
+//   - newName: The new name of the branch
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) RenameBranch(oldName, newName string) (*OperationResult, error) {
+	if err := validateBranchName(newName); err != nil {
+		return failedResult(err)
+	}
+
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	oldRef, err := repo.Reference(plumbing.NewBranchReferenceName(oldName), false)
+	if err != nil {
+		return failedResult(fmt.Errorf("branch %q does not exist", oldName))
+	}
+	newRefName := plumbing.NewBranchReferenceName(newName)
+	if _, err := repo.Reference(newRefName, false); err == nil {
+		return failedResult(fmt.Errorf("branch %q already exists", newName))
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(newRefName, oldRef.Hash())); err != nil {
+		return failedResult(err)
+	}
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == oldRef.Name() {
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, newRefName)); err != nil {
+			return failedResult(err)
+		}
+	}
+	if err := repo.Storer.RemoveReference(oldRef.Name()); err != nil {
+		return failedResult(err)
+	}
+
+	// Move the upstream configuration to the new name
+	cfg, err := repo.Config()
+	if err != nil {
+		return failedResult(err)
+	}
+	if bc, ok := cfg.Branches[oldName]; ok {
+		delete(cfg.Branches, oldName)
+		bc.Name = newName
+		cfg.Branches[newName] = bc
+		if err := repo.SetConfig(cfg); err != nil {
+			return failedResult(err)
+		}
+	}
+
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Renamed branch %s to %s", oldName, newName),
+		Error:   nil,
+	}, nil
+}
+
+// SetUpstream makes a local branch track a branch of a remote, so that
+// pull, push and the ahead/behind counts use it.
+//
+// Parameters:
+//   - name: The local branch
+//   - remote: The remote name, e.g. "origin"
+//   - remoteBranch: The branch on the remote
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) SetUpstream(name, remote, remoteBranch string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(name), false); err != nil {
+		return failedResult(fmt.Errorf("branch %q does not exist", name))
+	}
+	if _, err := repo.Remote(remote); err != nil {
+		return failedResult(fmt.Errorf("remote %q does not exist", remote))
+	}
+	if err := validateBranchName(remoteBranch); err != nil {
+		return failedResult(err)
+	}
+
+	if err := setUpstream(repo, name, remote, remoteBranch); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Branch %s now tracks %s/%s", name, remote, remoteBranch),
+		Error:   nil,
+	}, nil
+}
+
+// setUpstream writes the branch.<name> configuration.
+func setUpstream(repo *git.Repository, name, remote, remoteBranch string) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Branches[name] = &config.Branch{
+		Name:   name,
+		Remote: remote,
+		Merge:  plumbing.NewBranchReferenceName(remoteBranch),
+	}
+	return repo.SetConfig(cfg)
+}
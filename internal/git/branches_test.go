@@ -0,0 +1,441 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFile writes name in the worktree of repo and commits it
+func commitFile(t *testing.T, repo *git.Repository, name, content string) plumbing.Hash {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Filesystem.Root(), name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("Failed to stage %s: %v", name, err)
+	}
+	hash, err := worktree.Commit("Update "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash
+}
+
+// createRepoWithOrigin creates a repository with one commit on master whose
+// origin is a local bare repository holding the same commit
+func createRepoWithOrigin(t *testing.T) (*Client, *git.Repository, string) {
+	t.Helper()
+	bareDir := filepath.Join(t.TempDir(), "origin.git")
+	if _, err := git.PlainInit(bareDir, true); err != nil {
+		t.Fatalf("Failed to init bare repository: %v", err)
+	}
+
+	workDir := t.TempDir()
+	repo := createTestRepoWithCommit(t, workDir)
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{bareDir}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	pushAndFetch(t, repo, "refs/heads/master:refs/heads/master")
+	return NewClient(workDir), repo, bareDir
+}
+
+// pushAndFetch pushes refspec to origin and fetches origin back
+func pushAndFetch(t *testing.T, repo *git.Repository, refspec string) {
+	t.Helper()
+	err := repo.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{config.RefSpec(refspec)}})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	err = repo.Fetch(&git.FetchOptions{RemoteName: "origin"})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+}
+
+// headBranch returns the short name of the branch HEAD points at
+func headBranch(t *testing.T, repo *git.Repository) string {
+	t.Helper()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to read HEAD: %v", err)
+	}
+	return head.Name().Short()
+}
+
+// findBranch returns the named branch from a listing
+func findBranch(t *testing.T, branches []BranchInfo, name string) BranchInfo {
+	t.Helper()
+	for _, b := range branches {
+		if b.Name == name {
+			return b
+		}
+	}
+	t.Fatalf("branch %q not listed in %+v", name, branches)
+	return BranchInfo{}
+}
+
+func TestBranches_ListsLocalBranches(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepoWithCommit(t, dir)
+	client := NewClient(dir)
+
+	result, err := client.CreateBranch("feature", false)
+	if err != nil || !result.Success {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+
+	branches, err := client.Branches()
+	if err != nil {
+		t.Fatalf("Branches failed: %v", err)
+	}
+	if len(branches) != 2 || branches[0].Name != "feature" || branches[1].Name != "master" {
+		t.Fatalf("unexpected branches: %+v", branches)
+	}
+	if branches[0].Current || !branches[1].Current {
+		t.Errorf("expected master to be current: %+v", branches)
+	}
+	if branches[0].Hash != branches[1].Hash || len(branches[0].Hash) != 7 {
+		t.Errorf("expected both branches at the same short hash: %+v", branches)
+	}
+	if headBranch(t, repo) != "master" {
+		t.Error("CreateBranch without checkout must not switch")
+	}
+}
+
+func TestCreateBranch_Checkout(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepoWithCommit(t, dir)
+	client := NewClient(dir)
+
+	if _, err := client.CreateBranch("feature/login", true); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if got := headBranch(t, repo); got != "feature/login" {
+		t.Errorf("HEAD = %s, want feature/login", got)
+	}
+
+	if _, err := client.CreateBranch("feature/login", false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected duplicate branch error, got %v", err)
+	}
+	for _, name := range []string{"", "bad..name", "trailing/", "has space"} {
+		if result, err := client.CreateBranch(name, false); err == nil || result.Success {
+			t.Errorf("CreateBranch(%q) should fail", name)
+		}
+	}
+}
+
+func TestCreateBranch_EmptyRepository(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(dir).CreateBranch("feature", false); err == nil {
+		t.Error("expected an error before the first commit")
+	}
+}
+
+func TestSwitchBranch(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepoWithCommit(t, dir)
+	client := NewClient(dir)
+
+	if _, err := client.CreateBranch("feature", true); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "feature.txt", "feature work")
+
+	result, err := client.SwitchBranch("master")
+	if err != nil || !result.Success {
+		t.Fatalf("SwitchBranch failed: %v", err)
+	}
+	if headBranch(t, repo) != "master" {
+		t.Error("expected HEAD on master")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "feature.txt")); !os.IsNotExist(err) {
+		t.Error("expected the worktree to match master")
+	}
+
+	if result, _ := client.SwitchBranch("master"); !result.Success || !strings.Contains(result.Message, "Already on") {
+		t.Errorf("switching to the current branch: %+v", result)
+	}
+	if _, err := client.SwitchBranch("missing"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing branch error, got %v", err)
+	}
+}
+
+func TestSwitchBranch_RefusesUncommittedChanges(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepoWithCommit(t, dir)
+	client := NewClient(dir)
+	if _, err := client.CreateBranch("feature", false); err != nil {
+		t.Fatal(err)
+	}
+
+	testFile := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("unsaved work"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SwitchBranch("feature"); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Fatalf("expected uncommitted changes error, got %v", err)
+	}
+	if content, _ := os.ReadFile(testFile); string(content) != "unsaved work" {
+		t.Errorf("worktree changes were lost: %q", content)
+	}
+	if headBranch(t, repo) != "master" {
+		t.Error("HEAD moved despite the refusal")
+	}
+
+	// Untracked files do not block a switch
+	if err := os.WriteFile(testFile, []byte("test content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("scratch"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SwitchBranch("feature"); err != nil {
+		t.Errorf("untracked file blocked the switch: %v", err)
+	}
+}
+
+func TestSwitchBranch_TracksRemoteBranch(t *testing.T) {
+	client, repo, _ := createRepoWithOrigin(t)
+
+	// Publish a dev branch to origin without keeping it locally
+	head, _ := repo.Head()
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/dev", head.Hash())); err != nil {
+		t.Fatal(err)
+	}
+	pushAndFetch(t, repo, "refs/heads/dev:refs/heads/dev")
+	if err := repo.Storer.RemoveReference("refs/heads/dev"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.SwitchBranch("dev")
+	if err != nil {
+		t.Fatalf("SwitchBranch failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "tracking origin/dev") {
+		t.Errorf("unexpected message: %q", result.Message)
+	}
+	branches, err := client.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev := findBranch(t, branches, "dev"); !dev.Current || dev.Upstream != "origin/dev" {
+		t.Errorf("unexpected dev branch: %+v", dev)
+	}
+}
+
+func TestBranches_AheadBehind(t *testing.T) {
+	client, repo, bareDir := createRepoWithOrigin(t)
+	if _, err := client.SetUpstream("master", "origin", "master"); err != nil {
+		t.Fatalf("SetUpstream failed: %v", err)
+	}
+
+	branches, _ := client.Branches()
+	if master := findBranch(t, branches, "master"); master.Upstream != "origin/master" || master.Ahead != 0 || master.Behind != 0 {
+		t.Errorf("expected master in sync with origin: %+v", master)
+	}
+
+	// Two local commits and one commit pushed to origin by someone else
+	commitFile(t, repo, "a.txt", "a")
+	commitFile(t, repo, "b.txt", "b")
+
+	other, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{URL: bareDir})
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	commitFile(t, other, "c.txt", "c")
+	if err := other.Push(&git.PushOptions{}); err != nil {
+		t.Fatalf("Failed to push from clone: %v", err)
+	}
+	if err := repo.Fetch(&git.FetchOptions{RemoteName: "origin"}); err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+
+	branches, err = client.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master := findBranch(t, branches, "master"); master.Ahead != 2 || master.Behind != 1 {
+		t.Errorf("expected 2 ahead and 1 behind, got %+v", master)
+	}
+}
+
+func TestBranches_UpstreamGone(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepoWithCommit(t, dir)
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"/nonexistent"}}); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(dir)
+	if _, err := client.SetUpstream("master", "origin", "master"); err != nil {
+		t.Fatal(err)
+	}
+
+	branches, err := client.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master := findBranch(t, branches, "master"); !master.Gone || master.Upstream != "origin/master" {
+		t.Errorf("expected upstream to be reported gone: %+v", master)
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepoWithCommit(t, dir)
+	client := NewClient(dir)
+
+	if _, err := client.DeleteBranch("master", false); err == nil || !strings.Contains(err.Error(), "current branch") {
+		t.Errorf("expected current branch error, got %v", err)
+	}
+
+	// A merged branch is deleted
+	if _, err := client.CreateBranch("merged", false); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := client.DeleteBranch("merged", false); err != nil || !result.Success {
+		t.Errorf("DeleteBranch(merged) failed: %v", err)
+	}
+
+	// An unmerged branch needs force
+	if _, err := client.CreateBranch("wip", true); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "wip.txt", "wip")
+	if _, err := client.SwitchBranch("master"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DeleteBranch("wip", false); err == nil || !strings.Contains(err.Error(), "not fully merged") {
+		t.Errorf("expected not merged error, got %v", err)
+	}
+	if _, err := client.DeleteBranch("wip", true); err != nil {
+		t.Errorf("force delete failed: %v", err)
+	}
+
+	branches, _ := client.Branches()
+	if len(branches) != 1 || branches[0].Name != "master" {
+		t.Errorf("unexpected branches after delete: %+v", branches)
+	}
+	if _, err := client.DeleteBranch("missing", true); err == nil {
+		t.Error("expected error deleting a missing branch")
+	}
+}
+
+func TestRenameBranch(t *testing.T) {
+	client, repo, _ := createRepoWithOrigin(t)
+	if _, err := client.SetUpstream("master", "origin", "master"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.RenameBranch("master", "main")
+	if err != nil || !result.Success {
+		t.Fatalf("RenameBranch failed: %v", err)
+	}
+	if got := headBranch(t, repo); got != "main" {
+		t.Errorf("HEAD = %s, want main", got)
+	}
+
+	branches, err := client.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 1 {
+		t.Fatalf("unexpected branches: %+v", branches)
+	}
+	if main := branches[0]; main.Name != "main" || !main.Current || main.Upstream != "origin/master" {
+		t.Errorf("rename lost state: %+v", main)
+	}
+
+	if _, err := client.CreateBranch("other", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RenameBranch("other", "main"); err == nil {
+		t.Error("expected error renaming onto an existing branch")
+	}
+	if _, err := client.RenameBranch("missing", "x"); err == nil {
+		t.Error("expected error renaming a missing branch")
+	}
+}
+
+func TestSetUpstream_Errors(t *testing.T) {
+	client, _, _ := createRepoWithOrigin(t)
+	if _, err := client.SetUpstream("master", "upstream", "master"); err == nil || !strings.Contains(err.Error(), "remote") {
+		t.Errorf("expected unknown remote error, got %v", err)
+	}
+	if _, err := client.SetUpstream("missing", "origin", "master"); err == nil {
+		t.Error("expected unknown branch error")
+	}
+}
+
+func TestBranchOperations_NotARepository(t *testing.T) {
+	client := NewClient(t.TempDir())
+	if _, err := client.Branches(); err == nil {
+		t.Error("Branches: expected error outside a repository")
+	}
+	if result, err := client.SwitchBranch("master"); err == nil || result.Success {
+		t.Error("SwitchBranch: expected error outside a repository")
+	}
+}
+
+func TestAheadBehind_CountsSinceMergeBase(t *testing.T) {
+	repo := createTestRepoWithCommit(t, t.TempDir())
+	commitFile(t, repo, "a.txt", "a")
+	base := commitFile(t, repo, "b.txt", "b")
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feature := plumbing.NewBranchReferenceName("feature")
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: feature, Hash: base, Create: true}); err != nil {
+		t.Fatalf("Failed to create feature: %v", err)
+	}
+	commitFile(t, repo, "f1.txt", "f1")
+	featureTip := commitFile(t, repo, "f2.txt", "f2")
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatalf("Failed to check out master: %v", err)
+	}
+	masterTip := commitFile(t, repo, "m.txt", "m")
+
+	ahead, behind, err := aheadBehind(repo, featureTip, masterTip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Errorf("expected 2 ahead and 1 behind, got %d and %d", ahead, behind)
+	}
+
+	// Merging the feature into master leaves it only behind
+	merge, err := worktree.Commit("Merge feature", &git.CommitOptions{
+		Author:            &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		Parents:           []plumbing.Hash{masterTip, featureTip},
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	ahead, behind, err = aheadBehind(repo, featureTip, merge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ahead != 0 || behind != 2 {
+		t.Errorf("expected 0 ahead and 2 behind after the merge, got %d and %d", ahead, behind)
+	}
+}
@@ -0,0 +1,477 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/terminal-intelligence/internal/diff"
+)
+
+// diffContext is the number of unchanged lines kept around each change in
+// the hunks returned by Diff and used for hunk staging.
+const diffContext = 3
+
+// FileStatus is the state of one changed file, like a line of
+// "git status --short". Index compares the index with HEAD and Worktree
+// compares the working tree with the index.
+type FileStatus struct {
+	Path     string         // Slash-separated path relative to the repository root
+	Index    git.StatusCode // e.g. git.Modified if the file has staged changes
+	Worktree git.StatusCode // e.g. git.Modified if the file has unstaged changes
+}
+
+// Staged reports whether the file has changes in the index.
+func (f FileStatus) Staged() bool {
+	return f.Index != git.Unmodified && f.Index != git.Untracked
+}
+
+// Unstaged reports whether the file has changes in the working tree that are
+// not staged, including being untracked.
+func (f FileStatus) Unstaged() bool {
+	return f.Worktree != git.Unmodified
+}
+
+// Code returns the two-letter status code, e.g. "M " or "??".
+func (f FileStatus) Code() string {
+	return string([]byte{byte(f.Index), byte(f.Worktree)})
+}
+
+// FileStatuses returns the changed files of the repository sorted by path.
+//
+// Returns:
+//   - []FileStatus: One entry per file that differs between HEAD, the index and the working tree
+//   - error: Any error that occurred while reading the repository
+func (c *Client) FileStatuses() ([]FileStatus, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	files := make([]FileStatus, 0, len(status))
+	for path, s := range status {
+		if s.Staging == git.Unmodified && s.Worktree == git.Unmodified {
+			continue
+		}
+		files = append(files, FileStatus{Path: path, Index: s.Staging, Worktree: s.Worktree})
+	}
+
+	// Files with merge conflicts are shown as "UU" whatever their content
+	conflicts, err := conflictedPaths(repo)
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	for _, path := range conflicts {
+		i := slices.IndexFunc(files, func(f FileStatus) bool { return f.Path == path })
+		if i < 0 {
+			files = append(files, FileStatus{Path: path})
+			i = len(files) - 1
+		}
+		files[i].Index = git.UpdatedButUnmerged
+		files[i].Worktree = git.UpdatedButUnmerged
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Diff returns the hunks of one file. With staged set it compares HEAD with
+// the index (what the next commit will change), otherwise the index with the
+// working tree (what is not staged yet). Binary files are reported as an
+// error because they cannot be shown or staged by hunk.
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//   - staged: Whether to diff the staged changes instead of the unstaged ones
+func (c *Client) Diff(path string, staged bool) ([]diff.Hunk, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	oldText, newText, err := c.diffSides(repo, path, staged)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compute(oldText, newText, diffContext), nil
+}
+
+// diffSides returns the old and new text of a file for Diff.
+func (c *Client) diffSides(repo *git.Repository, path string, staged bool) (string, string, error) {
+	indexText, _, err := indexContent(repo, path)
+	if err != nil {
+		return "", "", err
+	}
+	var oldText, newText string
+	if staged {
+		oldText, _, err = headContent(repo, path)
+		newText = indexText
+	} else {
+		oldText = indexText
+		newText, _, err = c.worktreeContent(path)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if strings.ContainsRune(oldText, 0) || strings.ContainsRune(newText, 0) {
+		return "", "", fmt.Errorf("%s is a binary file; stage or unstage it as a whole", path)
+	}
+	return oldText, newText, nil
+}
+
+// StagedDiff returns the changes that the next commit will make: one entry
+// per staged file, comparing HEAD with the index, sorted by path.
+//
+// Returns:
+//   - []FileDiff: The staged changes; empty if nothing is staged
+//   - error: Any error that occurred while reading the repository
+func (c *Client) StagedDiff() ([]FileDiff, error) {
+	files, err := c.FileStatuses()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	var diffs []FileDiff
+	for _, f := range files {
+		if !f.Staged() {
+			continue
+		}
+		fd := FileDiff{Path: f.Path, Action: "modified"}
+		switch f.Index {
+		case git.Added:
+			fd.Action = "added"
+		case git.Deleted:
+			fd.Action = "deleted"
+		}
+
+		headText, _, err := headContent(repo, f.Path)
+		if err != nil {
+			return nil, categorizeError(err)
+		}
+		indexText, _, err := indexContent(repo, f.Path)
+		if err != nil {
+			return nil, categorizeError(err)
+		}
+		if strings.ContainsRune(headText, 0) || strings.ContainsRune(indexText, 0) {
+			fd.Binary = true
+		} else {
+			fd.Hunks = diff.Compute(headText, indexText, diffContext)
+		}
+		diffs = append(diffs, fd)
+	}
+	return diffs, nil
+}
+
+// StageFile stages all changes of one file, including its deletion. Staging
+// a file with merge conflicts marks it resolved.
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) StageFile(path string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	// Staging a conflicted file marks it resolved, like git add
+	if conflicts, err := conflictedPaths(repo); err != nil {
+		return failedResult(err)
+	} else if slices.Contains(conflicts, path) {
+		return c.ResolveFile(path)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	if _, exists, err := c.worktreeContent(path); err != nil {
+		return failedResult(err)
+	} else if exists {
+		_, err = worktree.Add(path)
+	} else {
+		_, err = worktree.Remove(path)
+	}
+	if err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{Success: true, Message: fmt.Sprintf("Staged %s", path)}, nil
+}
+
+// UnstageFile resets the index entry of one file to HEAD, keeping the
+// working tree as it is. A file that is not in HEAD is removed from the index.
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) UnstageFile(path string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	file, err := headFile(repo, path)
+	if err != nil {
+		return failedResult(err)
+	}
+	if file == nil {
+		if _, err := idx.Remove(path); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+			return failedResult(err)
+		}
+	} else {
+		entry, err := idx.Entry(path)
+		if errors.Is(err, index.ErrEntryNotFound) {
+			entry = idx.Add(path)
+		} else if err != nil {
+			return failedResult(err)
+		}
+		entry.Hash = file.Hash
+		entry.Mode = file.Mode
+		entry.Size = uint32(file.Size)
+	}
+
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{Success: true, Message: fmt.Sprintf("Unstaged %s", path)}, nil
+}
+
+// StageHunks stages some of the unstaged hunks of a file, as returned by
+// Diff(path, false).
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//   - hunks: Indexes of the hunks to stage
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) StageHunks(path string, hunks []int) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	if _, exists, err := c.worktreeContent(path); err != nil {
+		return failedResult(err)
+	} else if !exists {
+		return failedResult(fmt.Errorf("%s is deleted; stage the whole file", path))
+	}
+	indexText, worktreeText, err := c.diffSides(repo, path, false)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	selected, _, err := splitHunks(diff.Compute(indexText, worktreeText, diffContext), hunks)
+	if err != nil {
+		return failedResult(err)
+	}
+	staged, err := diff.Apply(indexText, selected)
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := c.setIndexContent(repo, path, staged); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{Success: true, Message: fmt.Sprintf("Staged %s of %s", countHunks(len(selected)), path)}, nil
+}
+
+// UnstageHunks removes some of the staged hunks of a file, as returned by
+// Diff(path, true), from the index. The working tree is not changed.
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//   - hunks: Indexes of the hunks to unstage
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) UnstageHunks(path string, hunks []int) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	headText, indexText, err := c.diffSides(repo, path, true)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	selected, kept, err := splitHunks(diff.Compute(headText, indexText, diffContext), hunks)
+	if err != nil {
+		return failedResult(err)
+	}
+	if len(kept) == 0 {
+		// Nothing stays staged, which also covers files that are not in HEAD
+		return c.UnstageFile(path)
+	}
+	unstaged, err := diff.Apply(headText, kept)
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := c.setIndexContent(repo, path, unstaged); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{Success: true, Message: fmt.Sprintf("Unstaged %s of %s", countHunks(len(selected)), path)}, nil
+}
+
+// splitHunks separates the hunks at the given indexes from the others,
+// keeping their order.
+func splitHunks(all []diff.Hunk, indexes []int) (selected, rest []diff.Hunk, err error) {
+	want := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		if i < 0 || i >= len(all) {
+			return nil, nil, fmt.Errorf("hunk %d does not exist; the file has %s", i+1, countHunks(len(all)))
+		}
+		want[i] = true
+	}
+	for i, h := range all {
+		if want[i] {
+			selected = append(selected, h)
+		} else {
+			rest = append(rest, h)
+		}
+	}
+	return selected, rest, nil
+}
+
+// countHunks formats a number of hunks, e.g. "1 hunk" or "2 hunks".
+func countHunks(n int) string {
+	if n == 1 {
+		return "1 hunk"
+	}
+	return fmt.Sprintf("%d hunks", n)
+}
+
+// headFile returns the file at path in the HEAD commit, or nil if the file
+// or HEAD does not exist.
+func headFile(repo *git.Repository, path string) (*object.File, error) {
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	return file, err
+}
+
+// headContent returns the content of path in HEAD and whether it exists there.
+func headContent(repo *git.Repository, path string) (string, bool, error) {
+	file, err := headFile(repo, path)
+	if err != nil || file == nil {
+		return "", false, err
+	}
+	content, err := file.Contents()
+	return content, err == nil, err
+}
+
+// indexContent returns the content of path in the index and whether it is
+// in the index.
+func indexContent(repo *git.Repository, path string) (string, bool, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", false, err
+	}
+	entry, err := idx.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil {
+		return "", false, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", false, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	return string(content), err == nil, err
+}
+
+// worktreeContent returns the content of path in the working tree and
+// whether the file exists.
+func (c *Client) worktreeContent(path string) (string, bool, error) {
+	content, err := os.ReadFile(filepath.Join(c.workDir, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return string(content), true, nil
+}
+
+// setIndexContent stores content as a blob and points the index entry of
+// path at it, adding the entry if the file is not in the index yet.
+func (c *Client) setIndexContent(repo *git.Repository, path, content string) error {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	writer, err := obj.Writer()
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, content); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	entry, err := idx.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		entry = idx.Add(path)
+		entry.Mode = filemode.Regular
+	} else if err != nil {
+		return err
+	}
+	entry.Hash = hash
+	entry.Size = uint32(len(content))
+	return repo.Storer.SetIndex(idx)
+}
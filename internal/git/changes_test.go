@@ -0,0 +1,305 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// twoHunkText has changes far enough apart to form two hunks
+const twoHunkText = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+
+// createChangesRepo returns a client on a repository where code.txt holds
+// twoHunkText in HEAD and has its first and last lines changed in the worktree
+func createChangesRepo(t *testing.T) (*Client, *git.Repository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, "code.txt", twoHunkText)
+	changed := strings.Replace(strings.Replace(twoHunkText, "1\n", "one\n", 1), "12\n", "twelve\n", 1)
+	writeFile(t, dir, "code.txt", changed)
+	return NewClient(dir), repo, changed
+}
+
+// writeFile writes content to name in dir
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+// statusOf returns the status of path, failing if it is not listed
+func statusOf(t *testing.T, client *Client, path string) FileStatus {
+	t.Helper()
+	files, err := client.FileStatuses()
+	if err != nil {
+		t.Fatalf("FileStatuses failed: %v", err)
+	}
+	for _, f := range files {
+		if f.Path == path {
+			return f
+		}
+	}
+	t.Fatalf("%s not in status %+v", path, files)
+	return FileStatus{}
+}
+
+// indexText returns the staged content of path
+func indexText(t *testing.T, repo *git.Repository, path string) string {
+	t.Helper()
+	content, _, err := indexContent(repo, path)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	return content
+}
+
+func TestFileStatuses(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+
+	commitFile(t, repo, "gone.txt", "bye\n")
+	writeFile(t, root, "new.txt", "hello\n")
+	writeFile(t, root, "added.txt", "added\n")
+	if _, err := worktree.Add("added.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := client.FileStatuses()
+	if err != nil {
+		t.Fatalf("FileStatuses failed: %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Code()+" "+f.Path)
+	}
+	want := []string{"A  added.txt", " M code.txt", " D gone.txt", "?? new.txt"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if added := statusOf(t, client, "added.txt"); !added.Staged() || added.Unstaged() {
+		t.Errorf("added.txt should only be staged: %+v", added)
+	}
+	if untracked := statusOf(t, client, "new.txt"); untracked.Staged() || !untracked.Unstaged() {
+		t.Errorf("new.txt should only be unstaged: %+v", untracked)
+	}
+}
+
+func TestDiff_UnstagedAndStaged(t *testing.T) {
+	client, _, _ := createChangesRepo(t)
+
+	hunks, err := client.Diff("code.txt", false)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 unstaged hunks, got %d", len(hunks))
+	}
+	if staged, _ := client.Diff("code.txt", true); len(staged) != 0 {
+		t.Errorf("expected no staged hunks, got %d", len(staged))
+	}
+
+	if _, err := client.StageFile("code.txt"); err != nil {
+		t.Fatalf("StageFile failed: %v", err)
+	}
+	if unstaged, _ := client.Diff("code.txt", false); len(unstaged) != 0 {
+		t.Errorf("expected no unstaged hunks after staging, got %d", len(unstaged))
+	}
+	if staged, _ := client.Diff("code.txt", true); len(staged) != 2 {
+		t.Errorf("expected 2 staged hunks, got %d", len(staged))
+	}
+}
+
+func TestDiff_BinaryFile(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	worktree, _ := repo.Worktree()
+	writeFile(t, worktree.Filesystem.Root(), "image.bin", "\x00\x01\x02")
+
+	if _, err := client.Diff("image.bin", false); err == nil || !strings.Contains(err.Error(), "binary") {
+		t.Errorf("expected binary file error, got %v", err)
+	}
+	if _, err := client.StageFile("image.bin"); err != nil {
+		t.Errorf("binary files should still be staged whole: %v", err)
+	}
+}
+
+func TestStageAndUnstageFile(t *testing.T) {
+	client, repo, changed := createChangesRepo(t)
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+	writeFile(t, root, "other.txt", "untouched\n")
+
+	result, err := client.StageFile("code.txt")
+	if err != nil || !result.Success {
+		t.Fatalf("StageFile failed: %v", err)
+	}
+	if got := statusOf(t, client, "code.txt").Code(); got != "M " {
+		t.Errorf("expected code.txt staged, got %q", got)
+	}
+	if got := statusOf(t, client, "other.txt").Code(); got != "??" {
+		t.Errorf("other.txt should stay untracked, got %q", got)
+	}
+	if indexText(t, repo, "code.txt") != changed {
+		t.Error("index should hold the worktree content")
+	}
+
+	if _, err := client.UnstageFile("code.txt"); err != nil {
+		t.Fatalf("UnstageFile failed: %v", err)
+	}
+	if got := statusOf(t, client, "code.txt").Code(); got != " M" {
+		t.Errorf("expected code.txt unstaged, got %q", got)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "code.txt")); string(content) != changed {
+		t.Error("unstaging must not touch the worktree")
+	}
+}
+
+func TestStageFile_NewAndDeletedFiles(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+	commitFile(t, repo, "gone.txt", "bye\n")
+	writeFile(t, root, "new.txt", "hello\n")
+	os.Remove(filepath.Join(root, "gone.txt"))
+
+	client.StageFile("new.txt")
+	client.StageFile("gone.txt")
+	if got := statusOf(t, client, "new.txt").Code(); got != "A " {
+		t.Errorf("expected new.txt added, got %q", got)
+	}
+	if got := statusOf(t, client, "gone.txt").Code(); got != "D " {
+		t.Errorf("expected gone.txt deleted in the index, got %q", got)
+	}
+
+	// Unstaging a file that is not in HEAD makes it untracked again
+	client.UnstageFile("new.txt")
+	client.UnstageFile("gone.txt")
+	if got := statusOf(t, client, "new.txt").Code(); got != "??" {
+		t.Errorf("expected new.txt untracked, got %q", got)
+	}
+	if got := statusOf(t, client, "gone.txt").Code(); got != " D" {
+		t.Errorf("expected gone.txt deleted in the worktree only, got %q", got)
+	}
+}
+
+func TestStageHunks(t *testing.T) {
+	client, repo, changed := createChangesRepo(t)
+
+	result, err := client.StageHunks("code.txt", []int{1})
+	if err != nil || !result.Success {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "1 hunk") {
+		t.Errorf("unexpected message: %q", result.Message)
+	}
+	want := strings.Replace(twoHunkText, "12\n", "twelve\n", 1)
+	if got := indexText(t, repo, "code.txt"); got != want {
+		t.Errorf("index = %q, want %q", got, want)
+	}
+	if got := statusOf(t, client, "code.txt").Code(); got != "MM" {
+		t.Errorf("expected staged and unstaged changes, got %q", got)
+	}
+
+	// The remaining unstaged hunk is now the only one
+	if _, err := client.StageHunks("code.txt", []int{0}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	if indexText(t, repo, "code.txt") != changed {
+		t.Error("expected every change to be staged")
+	}
+
+	if _, err := client.StageHunks("code.txt", []int{3}); err == nil {
+		t.Error("expected an error for a hunk that does not exist")
+	}
+}
+
+func TestStageHunks_UntrackedFile(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	worktree, _ := repo.Worktree()
+	writeFile(t, worktree.Filesystem.Root(), "new.txt", "hello\n")
+
+	if _, err := client.StageHunks("new.txt", []int{0}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	if got := statusOf(t, client, "new.txt").Code(); got != "A " {
+		t.Errorf("expected new.txt added, got %q", got)
+	}
+}
+
+func TestUnstageHunks(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	client.StageFile("code.txt")
+
+	if _, err := client.UnstageHunks("code.txt", []int{0}); err != nil {
+		t.Fatalf("UnstageHunks failed: %v", err)
+	}
+	want := strings.Replace(twoHunkText, "12\n", "twelve\n", 1)
+	if got := indexText(t, repo, "code.txt"); got != want {
+		t.Errorf("index = %q, want %q", got, want)
+	}
+
+	// Unstaging the last hunk leaves nothing staged
+	if _, err := client.UnstageHunks("code.txt", []int{0}); err != nil {
+		t.Fatalf("UnstageHunks failed: %v", err)
+	}
+	if got := statusOf(t, client, "code.txt").Code(); got != " M" {
+		t.Errorf("expected only unstaged changes, got %q", got)
+	}
+}
+
+func TestChanges_NotARepository(t *testing.T) {
+	client := NewClient(t.TempDir())
+	if _, err := client.FileStatuses(); err == nil {
+		t.Error("expected FileStatuses to fail outside a repository")
+	}
+	if _, err := client.Diff("x", false); err == nil {
+		t.Error("expected Diff to fail outside a repository")
+	}
+	if result, err := client.StageFile("x"); err == nil || result.Success {
+		t.Error("expected StageFile to fail outside a repository")
+	}
+}
+
+func TestStagedDiff(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+	writeFile(t, root, "new.txt", "hello\n")
+	writeFile(t, root, "image.bin", "\x00\x01")
+
+	if files, err := client.StagedDiff(); err != nil || len(files) != 0 {
+		t.Fatalf("expected nothing staged, got %+v (err=%v)", files, err)
+	}
+
+	client.StageHunks("code.txt", []int{0})
+	client.StageFile("new.txt")
+	client.StageFile("image.bin")
+	files, err := client.StagedDiff()
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 staged files, got %+v", files)
+	}
+	if files[0].Path != "code.txt" || files[0].Action != "modified" || len(files[0].Hunks) != 1 {
+		t.Errorf("expected only the staged hunk of code.txt, got %+v", files[0])
+	}
+	if files[1].Path != "image.bin" || !files[1].Binary {
+		t.Errorf("expected image.bin to be binary, got %+v", files[1])
+	}
+	if files[2].Path != "new.txt" || files[2].Action != "added" {
+		t.Errorf("expected new.txt to be added, got %+v", files[2])
+	}
+}
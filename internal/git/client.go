@@ -32,7 +32,7 @@ type OperationResult struct {
 type RepositoryInfo struct {
 	IsRepo      bool         // Whether the directory is a Git repository (contains .git/)
 	RemoteURL   string       // URL of the remote repository, empty if not configured
-	Credentials *Credentials // Stored credentials from .git/config, nil if not found
+	Credentials *Credentials // Stored credentials, nil if not found
 }
 
 // NewClient creates a new Git client for the specified working directory.
@@ -107,19 +107,6 @@ func (c *Client) DetectRepository() (*RepositoryInfo, error) {
 	return info, nil
 }
 
-// createAuth creates an HTTP basic authentication object for go-git operations.
-// It detects GitHub Personal Access Tokens (PAT) by checking for the "ghp_" prefix.
-// For GitHub PATs, the username can be any non-empty string (GitHub ignores it when using PATs).
-// For regular username/password authentication, both values are used as provided.
-//
-// Parameters:
-//   - username: The username for authentication (or any non-empty string for GitHub PATs)
-//   - password: The password or GitHub Personal Access Token (tokens start with "ghp_")
-//
-// Returns:
-//   - *http.BasicAuth: Authentication object for use with go-git operations
-//
-// Requirements: 5.1, 5.2, 5.3
 // createAuth creates an HTTP basic authentication object for go-git operations.
 // It detects GitHub Personal Access Tokens (PAT) by checking for the "ghp_" prefix.
 // For GitHub PATs, the username can be any non-empty string (GitHub ignores it when using PATs).
@@ -172,16 +159,24 @@ func (c *Client) Clone(url, username, password, targetDir string) (*OperationRes
 	cloneDir := c.determineCloneDir(url, targetDir)
 
 	// Create authentication
-	auth := createAuth(username, password)
+	auth, err := c.authFor(url, username, password)
+	if err != nil {
+		return &OperationResult{
+			Success: false,
+			Message: "",
+			Error:   err,
+		}, err
+	}
 
 	// Perform the clone operation
-	_, err := git.PlainClone(cloneDir, false, &git.CloneOptions{
+	_, err = git.PlainClone(cloneDir, false, &git.CloneOptions{
 		URL:      url,
-		Auth:     auth,
+		Auth:     auth.method,
 		Progress: os.Stdout, // Show progress to stdout
 	})
 
 	if err != nil {
+		c.remember(c.workDir, auth, err)
 		// Categorize and return the error
 		return &OperationResult{
 			Success: false,
@@ -190,16 +185,8 @@ func (c *Client) Clone(url, username, password, targetDir string) (*OperationRes
 		}, categorizeError(err)
 	}
 
-	// Success - save credentials for future use
-	store := NewStore(cloneDir)
-	if err := store.Save(&Credentials{
-		URL:      url,
-		Username: username,
-		Password: password,
-	}); err != nil {
-		// Log error but don't fail the operation
-		// Credential save errors are non-critical
-	}
+	// Success - remember credentials for future use
+	c.remember(cloneDir, auth, nil)
 
 	// Return the cloned directory path
 	return &OperationResult{
@@ -246,11 +233,18 @@ func (c *Client) Pull(username, password string) (*OperationResult, error) {
 	}
 
 	// Create authentication
-	auth := createAuth(username, password)
+	auth, err := c.authFor(originURL(repo), username, password)
+	if err != nil {
+		return &OperationResult{
+			Success: false,
+			Message: "",
+			Error:   err,
+		}, err
+	}
 
 	// Perform the pull operation
 	err = worktree.Pull(&git.PullOptions{
-		Auth:     auth,
+		Auth:     auth.method,
 		Progress: os.Stdout,
 	})
 
@@ -265,6 +259,12 @@ func (c *Client) Pull(username, password string) (*OperationResult, error) {
 			}, nil
 		}
 
+		// Diverged histories are merged, stopping for any conflicts
+		if err == git.ErrNonFastForwardUpdate {
+			return c.mergeUpstream(repo)
+		}
+
+		c.remember(c.workDir, auth, err)
 		// Categorize and return the error
 		return &OperationResult{
 			Success: false,
@@ -273,29 +273,8 @@ func (c *Client) Pull(username, password string) (*OperationResult, error) {
 		}, categorizeError(err)
 	}
 
-	// Success - save credentials for future use
-	store := NewStore(c.workDir)
-	if err := store.Save(&Credentials{
-		URL:      "", // Will be populated from remote URL
-		Username: username,
-		Password: password,
-	}); err != nil {
-		// Log error but don't fail the operation
-		// Credential save errors are non-critical
-	}
-
-	// Get remote URL for credential storage
-	remote, err := repo.Remote("origin")
-	if err == nil && remote != nil {
-		config := remote.Config()
-		if len(config.URLs) > 0 {
-			store.Save(&Credentials{
-				URL:      config.URLs[0],
-				Username: username,
-				Password: password,
-			})
-		}
-	}
+	// Success - remember credentials for future use
+	c.remember(c.workDir, auth, nil)
 
 	// Return success message
 	return &OperationResult{
@@ -332,11 +311,18 @@ func (c *Client) Push(username, password string) (*OperationResult, error) {
 	}
 
 	// Create authentication
-	auth := createAuth(username, password)
+	auth, err := c.authFor(originURL(repo), username, password)
+	if err != nil {
+		return &OperationResult{
+			Success: false,
+			Message: "",
+			Error:   err,
+		}, err
+	}
 
 	// Perform the push operation
 	err = repo.Push(&git.PushOptions{
-		Auth:     auth,
+		Auth:     auth.method,
 		Progress: os.Stdout,
 	})
 
@@ -386,6 +372,7 @@ func (c *Client) Push(username, password string) (*OperationResult, error) {
 			}, fmt.Errorf("nothing to push (already up-to-date)")
 		}
 
+		c.remember(c.workDir, auth, err)
 		// Categorize and return the error
 		return &OperationResult{
 			Success: false,
@@ -394,19 +381,8 @@ func (c *Client) Push(username, password string) (*OperationResult, error) {
 		}, categorizeError(err)
 	}
 
-	// Success - save credentials for future use
-	store := NewStore(c.workDir)
-	remote, err := repo.Remote("origin")
-	if err == nil && remote != nil {
-		config := remote.Config()
-		if len(config.URLs) > 0 {
-			store.Save(&Credentials{
-				URL:      config.URLs[0],
-				Username: username,
-				Password: password,
-			})
-		}
-	}
+	// Success - remember credentials for future use
+	c.remember(c.workDir, auth, nil)
 
 	// Return success message
 	return &OperationResult{
@@ -443,11 +419,18 @@ func (c *Client) Fetch(username, password string) (*OperationResult, error) {
 	}
 
 	// Create authentication
-	auth := createAuth(username, password)
+	auth, err := c.authFor(originURL(repo), username, password)
+	if err != nil {
+		return &OperationResult{
+			Success: false,
+			Message: "",
+			Error:   err,
+		}, err
+	}
 
 	// Perform the fetch operation
 	err = repo.Fetch(&git.FetchOptions{
-		Auth:     auth,
+		Auth:     auth.method,
 		Progress: os.Stdout,
 	})
 
@@ -462,6 +445,7 @@ func (c *Client) Fetch(username, password string) (*OperationResult, error) {
 			}, nil
 		}
 
+		c.remember(c.workDir, auth, err)
 		// Categorize and return the error
 		return &OperationResult{
 			Success: false,
@@ -470,19 +454,8 @@ func (c *Client) Fetch(username, password string) (*OperationResult, error) {
 		}, categorizeError(err)
 	}
 
-	// Success - save credentials for future use
-	store := NewStore(c.workDir)
-	remote, err := repo.Remote("origin")
-	if err == nil && remote != nil {
-		config := remote.Config()
-		if len(config.URLs) > 0 {
-			store.Save(&Credentials{
-				URL:      config.URLs[0],
-				Username: username,
-				Password: password,
-			})
-		}
-	}
+	// Success - remember credentials for future use
+	c.remember(c.workDir, auth, nil)
 
 	// Return success message
 	return &OperationResult{
@@ -602,16 +575,31 @@ func categorizeError(err error) error {
 
 	errMsg := err.Error()
 
+	// Check for SSH host key errors, which would otherwise look like network errors
+	if strings.Contains(errMsg, "knownhosts") {
+		return &GitError{
+			Category: "Authentication",
+			Message:  "Authentication failed: " + errMsg,
+			Hint:     "Connect once with ssh to add the host to ~/.ssh/known_hosts",
+			Original: err,
+		}
+	}
+
 	// Check for authentication errors
 	if strings.Contains(errMsg, "authentication") ||
+		strings.Contains(errMsg, "unable to authenticate") ||
 		strings.Contains(errMsg, "401") ||
 		strings.Contains(errMsg, "403") ||
 		strings.Contains(errMsg, "unauthorized") ||
 		strings.Contains(errMsg, "forbidden") {
+		hint := "For private repositories, use a GitHub Personal Access Token (ghp_...)"
+		if strings.Contains(errMsg, "ssh:") {
+			hint = "Load your key into ssh-agent, or enter its passphrase in the password field"
+		}
 		return &GitError{
 			Category: "Authentication",
 			Message:  "Authentication failed: " + errMsg,
-			Hint:     "For private repositories, use a GitHub Personal Access Token (ghp_...)",
+			Hint:     hint,
 			Original: err,
 		}
 	}
@@ -787,6 +775,17 @@ func (c *Client) Commit(message string) (*OperationResult, error) {
 		}, categorizeError(err)
 	}
 
+	// During a merge the commit records both parents, once nothing conflicts
+	if state, err := c.MergeState(); err == nil && state != nil {
+		if len(state.Conflicts) > 0 {
+			err := fmt.Errorf("resolve the merge conflicts before committing")
+			return &OperationResult{Success: false, Message: "", Error: err}, err
+		}
+		if state.Operation == "merge" {
+			return c.continueMerge(message)
+		}
+	}
+
 	// Check if there are staged changes
 	status, err := worktree.Status()
 	if err != nil {
@@ -952,76 +951,3 @@ func (c *Client) Status() (*OperationResult, error) {
 		Error:   nil,
 	}, nil
 }
-
-// Restore restores all modified files to their last committed state.
-// It opens an existing repository in the working directory, gets the worktree,
-// and discards all changes to tracked files, reverting them to HEAD.
-//
-// Returns:
-//   - *OperationResult: Contains success status, message with file count, and any error
-//   - error: Any error that occurred during the operation
-//
-// The OperationResult.Message field contains the number of files restored on success.
-//
-// Requirements: 13.1
-func (c *Client) Restore() (*OperationResult, error) {
-	// Open the existing repository
-	repo, err := git.PlainOpen(c.workDir)
-	if err != nil {
-		return &OperationResult{
-			Success: false,
-			Message: "",
-			Error:   categorizeError(err),
-		}, categorizeError(err)
-	}
-
-	// Get the worktree
-	worktree, err := repo.Worktree()
-	if err != nil {
-		return &OperationResult{
-			Success: false,
-			Message: "",
-			Error:   categorizeError(err),
-		}, categorizeError(err)
-	}
-
-	// Get the current status to count modified files
-	status, err := worktree.Status()
-	if err != nil {
-		return &OperationResult{
-			Success: false,
-			Message: "",
-			Error:   categorizeError(err),
-		}, categorizeError(err)
-	}
-
-	// Count modified files (files that will be restored)
-	fileCount := 0
-	for _, fileStatus := range status {
-		// Count files that are modified in worktree (not untracked)
-		if fileStatus.Worktree != git.Unmodified && fileStatus.Worktree != git.Untracked {
-			fileCount++
-		}
-	}
-
-	// Restore all modified files by checking out HEAD
-	// This reverts all changes in the worktree
-	err = worktree.Reset(&git.ResetOptions{
-		Mode: git.HardReset,
-	})
-	if err != nil {
-		return &OperationResult{
-			Success: false,
-			Message: "",
-			Error:   categorizeError(err),
-		}, categorizeError(err)
-	}
-
-	// Success - return success message with file count
-	message := fmt.Sprintf("Restored %d file(s)", fileCount)
-	return &OperationResult{
-		Success: true,
-		Message: message,
-		Error:   nil,
-	}, nil
-}
@@ -0,0 +1,682 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/terminal-intelligence/internal/diff"
+)
+
+// ErrMergeConflict is wrapped by the error returned when a merge or pull
+// stops with conflicts that have to be resolved.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeState describes a merge or rebase that is in progress.
+type MergeState struct {
+	Operation string   // "merge" or "rebase"
+	Theirs    string   // Abbreviated hash of the commit being merged; empty for rebases
+	Conflicts []string // Paths that still have unresolved conflicts, sorted
+}
+
+// gitFile is the path of a file in the .git directory, such as MERGE_HEAD.
+func (c *Client) gitFile(name string) string {
+	return filepath.Join(c.workDir, ".git", name)
+}
+
+// MergeState returns the merge or rebase in progress, or nil if there is
+// none. Rebases started outside the app are detected so their conflicts can
+// be resolved here too.
+//
+// Returns:
+//   - *MergeState: The operation in progress and its unresolved conflicts
+//   - error: Any error that occurred while reading the repository
+func (c *Client) MergeState() (*MergeState, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	state := &MergeState{}
+	if content, err := os.ReadFile(c.gitFile("MERGE_HEAD")); err == nil {
+		state.Operation = "merge"
+		if hash := strings.TrimSpace(string(content)); len(hash) >= 7 {
+			state.Theirs = hash[:7]
+		}
+	} else if dirExists(c.gitFile("rebase-merge")) || dirExists(c.gitFile("rebase-apply")) {
+		state.Operation = "rebase"
+	}
+
+	state.Conflicts, err = conflictedPaths(repo)
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	if state.Operation == "" {
+		if len(state.Conflicts) == 0 {
+			return nil, nil
+		}
+		state.Operation = "merge"
+	}
+	return state, nil
+}
+
+// conflictedPaths returns the sorted paths that have unmerged index entries.
+func conflictedPaths(repo *git.Repository) ([]string, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var paths []string
+	for _, entry := range idx.Entries {
+		if entry.Stage != 0 && !seen[entry.Name] {
+			seen[entry.Name] = true
+			paths = append(paths, entry.Name)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Merge merges a branch or revision into the current branch. It
+// fast-forwards when possible; otherwise the files are merged line by line
+// and a merge commit is created. When both sides changed the same lines, the
+// conflicting files are written with conflict markers, recorded as unmerged
+// in the index, and an error wrapping ErrMergeConflict is returned; resolve
+// them with ResolveFile and finish with ContinueMerge, or undo the merge
+// with AbortMerge.
+//
+// Parameters:
+//   - rev: Local branch, remote-tracking branch (e.g. "origin/main") or revision to merge
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) Merge(rev string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	hash, name, err := resolveMergeRevision(repo, rev)
+	if err != nil {
+		return failedResult(err)
+	}
+	return c.merge(repo, hash, name)
+}
+
+// resolveMergeRevision resolves rev and describes it for the merge message.
+func resolveMergeRevision(repo *git.Repository, rev string) (plumbing.Hash, string, error) {
+	if ref, err := repo.Reference(plumbing.NewBranchReferenceName(rev), true); err == nil {
+		return ref.Hash(), fmt.Sprintf("branch '%s'", rev), nil
+	}
+	if remote, branch, ok := strings.Cut(rev, "/"); ok {
+		if ref, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, branch), true); err == nil {
+			return ref.Hash(), fmt.Sprintf("remote-tracking branch '%s'", rev), nil
+		}
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return *hash, fmt.Sprintf("commit '%s'", hash.String()[:7]), nil
+}
+
+// merge merges the commit theirs, described by name, into HEAD.
+func (c *Client) merge(repo *git.Repository, theirs plumbing.Hash, name string) (*OperationResult, error) {
+	if state, err := c.MergeState(); err != nil {
+		return failedResult(err)
+	} else if state != nil {
+		return failedResult(fmt.Errorf("a %s is already in progress; resolve or abort it first", state.Operation))
+	}
+	if err := ensureClean(repo); err != nil {
+		return failedResult(err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(fmt.Errorf("cannot merge before the first commit: %w", err))
+	}
+	ours, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return failedResult(err)
+	}
+	theirsCommit, err := repo.CommitObject(theirs)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	bases, err := ours.MergeBase(theirsCommit)
+	if err != nil {
+		return failedResult(err)
+	}
+	var base *object.Commit
+	if len(bases) > 0 {
+		base = bases[0]
+	}
+	if base != nil && base.Hash == theirs {
+		return &OperationResult{Success: true, Message: "Already up-to-date", Error: nil}, nil
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return failedResult(err)
+	}
+	if base != nil && base.Hash == ours.Hash {
+		if err := worktree.Reset(&git.ResetOptions{Commit: theirs, Mode: git.HardReset}); err != nil {
+			return failedResult(err)
+		}
+		return &OperationResult{
+			Success: true,
+			Message: fmt.Sprintf("Fast-forwarded to %s", theirs.String()[:7]),
+			Error:   nil,
+		}, nil
+	}
+
+	conflicts, err := c.mergeTrees(repo, base, ours, theirsCommit, name)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	message := "Merge " + name
+	if head.Name().IsBranch() {
+		message += " into " + head.Name().Short()
+	}
+	if len(conflicts) > 0 {
+		var sb strings.Builder
+		sb.WriteString(message + "\n\n# Conflicts:\n")
+		for _, path := range conflicts {
+			sb.WriteString("#\t" + path + "\n")
+		}
+		if err := os.WriteFile(c.gitFile("MERGE_HEAD"), []byte(theirs.String()+"\n"), 0644); err != nil {
+			return failedResult(err)
+		}
+		if err := os.WriteFile(c.gitFile("MERGE_MSG"), []byte(sb.String()), 0644); err != nil {
+			return failedResult(err)
+		}
+		err := &GitError{
+			Category: "Conflict",
+			Message:  fmt.Sprintf("Merge conflict in %s", strings.Join(conflicts, ", ")),
+			Hint:     "Resolve the conflicts, mark the files resolved and continue the merge",
+			Original: ErrMergeConflict,
+		}
+		return &OperationResult{Success: false, Message: "", Error: err}, err
+	}
+
+	hash, err := commitMerge(worktree, message, ours.Hash, theirs)
+	if err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Merged %s (%s)", name, hash.String()[:7]),
+		Error:   nil,
+	}, nil
+}
+
+// treeEntry is a file of a tree in a merge; a zero hash means the file does
+// not exist on that side.
+type treeEntry struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// treeFiles lists the files of the tree of commit, or none for nil.
+func treeFiles(commit *object.Commit) (map[string]treeEntry, error) {
+	files := map[string]treeEntry{}
+	if commit == nil {
+		return files, nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = treeEntry{hash: f.Hash, mode: f.Mode}
+		return nil
+	})
+	return files, err
+}
+
+// mergeTrees merges the files of theirs into the worktree and index, which
+// hold ours, and returns the paths that have conflicts.
+func (c *Client) mergeTrees(repo *git.Repository, base, ours, theirs *object.Commit, name string) ([]string, error) {
+	baseFiles, err := treeFiles(base)
+	if err != nil {
+		return nil, err
+	}
+	oursFiles, err := treeFiles(ours)
+	if err != nil {
+		return nil, err
+	}
+	theirsFiles, err := treeFiles(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]bool{}
+	for _, files := range []map[string]treeEntry{baseFiles, oursFiles, theirsFiles} {
+		for path := range files {
+			paths[path] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []string
+	for _, path := range sorted {
+		b, o, t := baseFiles[path], oursFiles[path], theirsFiles[path]
+		switch {
+		case o.hash == t.hash, t.hash == b.hash:
+			// Unchanged on their side; ours is already checked out
+			continue
+		case o.hash == b.hash:
+			// Only changed on their side
+			if err := c.takeFile(repo, idx, path, t); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Changed on both sides: merge the lines of text files
+		var merged string
+		count := 1
+		texts, binary, err := blobTexts(repo, b.hash, o.hash, t.hash)
+		if err != nil {
+			return nil, err
+		}
+		if !binary && !o.hash.IsZero() && !t.hash.IsZero() {
+			merged, count = diff.Merge(texts[0], texts[1], texts[2], "HEAD", name)
+		}
+		if count == 0 {
+			hash, err := writeBlob(repo, merged)
+			if err != nil {
+				return nil, err
+			}
+			entry := treeEntry{hash: hash, mode: o.mode}
+			if err := c.writeWorktreeFile(path, merged, entry.mode); err != nil {
+				return nil, err
+			}
+			setStages(idx, path, map[index.Stage]treeEntry{0: entry})
+			continue
+		}
+
+		// Keep the markers in the worktree, or the file of the side that
+		// still has it when the conflict is between a change and a deletion
+		switch {
+		case merged != "":
+			if err := c.writeWorktreeFile(path, merged, o.mode); err != nil {
+				return nil, err
+			}
+		case o.hash.IsZero():
+			if err := c.writeWorktreeFile(path, texts[2], t.mode); err != nil {
+				return nil, err
+			}
+		}
+		setStages(idx, path, map[index.Stage]treeEntry{
+			index.AncestorMode: b,
+			index.OurMode:      o,
+			index.TheirMode:    t,
+		})
+		conflicts = append(conflicts, path)
+	}
+	return conflicts, repo.Storer.SetIndex(idx)
+}
+
+// takeFile replaces path in the worktree and index with entry, or removes
+// it if entry is empty.
+func (c *Client) takeFile(repo *git.Repository, idx *index.Index, path string, entry treeEntry) error {
+	if entry.hash.IsZero() {
+		setStages(idx, path, nil)
+		err := os.Remove(filepath.Join(c.workDir, filepath.FromSlash(path)))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	texts, _, err := blobTexts(repo, entry.hash)
+	if err != nil {
+		return err
+	}
+	if err := c.writeWorktreeFile(path, texts[0], entry.mode); err != nil {
+		return err
+	}
+	setStages(idx, path, map[index.Stage]treeEntry{0: entry})
+	return nil
+}
+
+// setStages replaces the index entries of path with one entry per stage.
+// Stages whose entry is empty are left out.
+func setStages(idx *index.Index, path string, stages map[index.Stage]treeEntry) {
+	kept := idx.Entries[:0]
+	for _, e := range idx.Entries {
+		if e.Name != path {
+			kept = append(kept, e)
+		}
+	}
+	idx.Entries = kept
+
+	for _, stage := range []index.Stage{0, index.AncestorMode, index.OurMode, index.TheirMode} {
+		entry, ok := stages[stage]
+		if !ok || entry.hash.IsZero() {
+			continue
+		}
+		mode := entry.mode
+		if mode == filemode.Empty {
+			mode = filemode.Regular
+		}
+		idx.Entries = append(idx.Entries, &index.Entry{
+			Name:       path,
+			Hash:       entry.hash,
+			Mode:       mode,
+			Stage:      stage,
+			ModifiedAt: time.Now(),
+		})
+	}
+	sort.SliceStable(idx.Entries, func(i, j int) bool {
+		return idx.Entries[i].Name < idx.Entries[j].Name
+	})
+}
+
+// blobTexts reads the blobs with the given hashes; a zero hash reads as
+// empty. It also reports whether any of them is binary.
+func blobTexts(repo *git.Repository, hashes ...plumbing.Hash) ([]string, bool, error) {
+	texts := make([]string, len(hashes))
+	binary := false
+	for i, hash := range hashes {
+		if hash.IsZero() {
+			continue
+		}
+		blob, err := repo.BlobObject(hash)
+		if err != nil {
+			return nil, false, err
+		}
+		file := object.NewFile("", filemode.Regular, blob)
+		if isBinary, err := file.IsBinary(); err != nil {
+			return nil, false, err
+		} else if isBinary {
+			binary = true
+		}
+		if texts[i], err = file.Contents(); err != nil {
+			return nil, false, err
+		}
+	}
+	return texts, binary, nil
+}
+
+// writeBlob stores content as a blob and returns its hash.
+func writeBlob(repo *git.Repository, content string) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	writer, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := writer.Write([]byte(content)); err != nil {
+		writer.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// writeWorktreeFile writes content to path in the worktree.
+func (c *Client) writeWorktreeFile(path, content string, mode filemode.FileMode) error {
+	full := filepath.Join(c.workDir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if mode == filemode.Executable {
+		perm = 0755
+	}
+	return os.WriteFile(full, []byte(content), perm)
+}
+
+// commitMerge commits the index as a merge of ours and theirs.
+func commitMerge(worktree *git.Worktree, message string, ours, theirs plumbing.Hash) (plumbing.Hash, error) {
+	return worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Terminal Intelligence User",
+			Email: "user@terminal-intelligence.local",
+			When:  time.Now(),
+		},
+		Parents:           []plumbing.Hash{ours, theirs},
+		AllowEmptyCommits: true,
+	})
+}
+
+// ResolveFile marks a conflicted file as resolved by staging its worktree
+// content. Files that still contain conflict markers are refused; a file
+// deleted from the worktree is resolved as deleted.
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) ResolveFile(path string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	conflicts, err := conflictedPaths(repo)
+	if err != nil {
+		return failedResult(err)
+	}
+	if i := sort.SearchStrings(conflicts, path); i == len(conflicts) || conflicts[i] != path {
+		return failedResult(fmt.Errorf("%s has no conflicts", path))
+	}
+
+	content, exists, err := c.worktreeContent(path)
+	if err != nil {
+		return failedResult(err)
+	}
+	if exists && diff.HasConflicts(content) {
+		return failedResult(fmt.Errorf("%s still has conflict markers", path))
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return failedResult(err)
+	}
+	mode := filemode.Regular
+	for _, e := range idx.Entries {
+		if e.Name == path && e.Stage == index.OurMode {
+			mode = e.Mode
+		}
+	}
+	if !exists {
+		setStages(idx, path, nil)
+	} else {
+		hash, err := writeBlob(repo, content)
+		if err != nil {
+			return failedResult(err)
+		}
+		setStages(idx, path, map[index.Stage]treeEntry{0: {hash: hash, mode: mode}})
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{Success: true, Message: "Marked " + path + " as resolved", Error: nil}, nil
+}
+
+// ContinueMerge creates the merge commit once every conflict is resolved.
+// Rebases have to be continued with the git command line.
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) ContinueMerge() (*OperationResult, error) {
+	return c.continueMerge("")
+}
+
+// continueMerge creates the merge commit with message, or with the prepared
+// merge message if it is empty.
+func (c *Client) continueMerge(message string) (*OperationResult, error) {
+	state, err := c.MergeState()
+	if err != nil {
+		return failedResult(err)
+	}
+	if state == nil {
+		return failedResult(fmt.Errorf("no merge is in progress"))
+	}
+	if len(state.Conflicts) > 0 {
+		return failedResult(fmt.Errorf("%d files still have conflicts", len(state.Conflicts)))
+	}
+	if state.Operation == "rebase" {
+		return failedResult(fmt.Errorf("conflicts resolved; run git rebase --continue to finish the rebase"))
+	}
+
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(err)
+	}
+	content, err := os.ReadFile(c.gitFile("MERGE_HEAD"))
+	if err != nil {
+		return failedResult(err)
+	}
+	theirs := plumbing.NewHash(strings.TrimSpace(string(content)))
+
+	if message == "" {
+		message = c.mergeMessage(theirs)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return failedResult(err)
+	}
+	hash, err := commitMerge(worktree, message, head.Hash(), theirs)
+	if err != nil {
+		return failedResult(err)
+	}
+	c.clearMergeState()
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Merge completed (%s)", hash.String()[:7]),
+		Error:   nil,
+	}, nil
+}
+
+// mergeMessage returns the prepared message of the merge of theirs without
+// its comment lines.
+func (c *Client) mergeMessage(theirs plumbing.Hash) string {
+	if raw, err := os.ReadFile(c.gitFile("MERGE_MSG")); err == nil {
+		var lines []string
+		for _, line := range strings.Split(string(raw), "\n") {
+			if !strings.HasPrefix(line, "#") {
+				lines = append(lines, line)
+			}
+		}
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			return text
+		}
+	}
+	return "Merge commit " + theirs.String()[:7]
+}
+
+// AbortMerge abandons a merge in progress and restores the worktree and
+// index to HEAD.
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) AbortMerge() (*OperationResult, error) {
+	state, err := c.MergeState()
+	if err != nil {
+		return failedResult(err)
+	}
+	if state == nil {
+		return failedResult(fmt.Errorf("no merge is in progress"))
+	}
+	if state.Operation == "rebase" {
+		return failedResult(fmt.Errorf("run git rebase --abort to abort the rebase"))
+	}
+
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	// Drop the unmerged entries so the reset sees one entry per path
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return failedResult(err)
+	}
+	kept := idx.Entries[:0]
+	for _, e := range idx.Entries {
+		if e.Stage == 0 {
+			kept = append(kept, e)
+		}
+	}
+	idx.Entries = kept
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return failedResult(err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); err != nil {
+		return failedResult(err)
+	}
+	c.clearMergeState()
+	return &OperationResult{Success: true, Message: "Merge aborted", Error: nil}, nil
+}
+
+// clearMergeState removes the files recording a merge in progress.
+func (c *Client) clearMergeState() {
+	os.Remove(c.gitFile("MERGE_HEAD"))
+	os.Remove(c.gitFile("MERGE_MSG"))
+}
+
+// mergeUpstream merges the remote-tracking branch of the current branch,
+// after a pull found that the histories have diverged.
+func (c *Client) mergeUpstream(repo *git.Repository) (*OperationResult, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(err)
+	}
+	if !head.Name().IsBranch() {
+		return failedResult(fmt.Errorf("cannot merge the upstream of a detached HEAD"))
+	}
+	branch := head.Name().Short()
+	remote, remoteBranch := "origin", branch
+	if cfg, err := repo.Config(); err == nil {
+		if b, ok := cfg.Branches[branch]; ok && b.Remote != "" && b.Merge != "" {
+			remote, remoteBranch = b.Remote, b.Merge.Short()
+		}
+	}
+	ref, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, remoteBranch), true)
+	if err != nil {
+		return failedResult(fmt.Errorf("no remote-tracking branch %s/%s to merge", remote, remoteBranch))
+	}
+	return c.merge(repo, ref.Hash(), fmt.Sprintf("remote-tracking branch '%s/%s'", remote, remoteBranch))
+}
@@ -0,0 +1,313 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// createDivergedRepo returns a client on a repository where code.txt holds
+// twoHunkText at the merge base, master changed it to ours and the branch
+// feature changed it to theirs. master is checked out.
+func createDivergedRepo(t *testing.T, ours, theirs string) (*Client, *git.Repository) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	base := commitFile(t, repo, "code.txt", twoHunkText)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), base)); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, _ := repo.Worktree()
+	checkoutBranch(t, worktree, "feature")
+	commitFile(t, repo, "code.txt", theirs)
+	checkoutBranch(t, worktree, "master")
+	commitFile(t, repo, "code.txt", ours)
+	return NewClient(dir), repo
+}
+
+// checkoutBranch switches the worktree to a local branch
+func checkoutBranch(t *testing.T, worktree *git.Worktree, name string) {
+	t.Helper()
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(name)}); err != nil {
+		t.Fatalf("Failed to check out %s: %v", name, err)
+	}
+}
+
+// readWorktree returns the content of name in the worktree of client
+func readWorktree(t *testing.T, client *Client, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(client.workDir, name))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+// headParents returns the number of parents of the HEAD commit
+func headParents(t *testing.T, repo *git.Repository) int {
+	t.Helper()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit.NumParents()
+}
+
+func TestMerge_CleanMerge(t *testing.T) {
+	ours := strings.Replace(twoHunkText, "1\n", "one\n", 1)
+	theirs := strings.Replace(twoHunkText, "12\n", "twelve\n", 1)
+	client, repo := createDivergedRepo(t, ours, theirs)
+
+	result, err := client.Merge("feature")
+	if err != nil || !result.Success {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "branch 'feature'") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	want := strings.Replace(ours, "12\n", "twelve\n", 1)
+	if got := readWorktree(t, client, "code.txt"); got != want {
+		t.Errorf("code.txt = %q, want %q", got, want)
+	}
+	if headParents(t, repo) != 2 {
+		t.Error("expected a merge commit")
+	}
+	if files, _ := client.FileStatuses(); len(files) != 0 {
+		t.Errorf("expected a clean worktree, got %+v", files)
+	}
+}
+
+func TestMerge_FastForwardAndUpToDate(t *testing.T) {
+	client, repo := createDivergedRepo(t, twoHunkText+"13\n", "0\n"+twoHunkText)
+
+	// A branch at an ancestor of master has nothing to merge
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("old"), commit.ParentHashes[0]))
+	result, err := client.Merge("old")
+	if err != nil || result.Message != "Already up-to-date" {
+		t.Fatalf("expected up-to-date, got %+v (err=%v)", result, err)
+	}
+
+	// master can be fast-forwarded to a branch that is ahead of it
+	repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("ahead"), head.Hash()))
+	worktree, _ := repo.Worktree()
+	checkoutBranch(t, worktree, "ahead")
+	next := commitFile(t, repo, "code.txt", twoHunkText+"13\n14\n")
+	checkoutBranch(t, worktree, "master")
+
+	result, err = client.Merge("ahead")
+	if err != nil || !strings.Contains(result.Message, "Fast-forwarded") {
+		t.Fatalf("expected a fast-forward, got %+v (err=%v)", result, err)
+	}
+	if head, _ := repo.Head(); head.Hash() != next || head.Name().Short() != "master" {
+		t.Errorf("expected master at %s, got %s", next, head.Hash())
+	}
+	if got := readWorktree(t, client, "code.txt"); got != twoHunkText+"13\n14\n" {
+		t.Errorf("worktree not updated: %q", got)
+	}
+}
+
+func TestMerge_ConflictResolveAndContinue(t *testing.T) {
+	ours := strings.Replace(twoHunkText, "1\n", "ours\n", 1)
+	theirs := strings.Replace(twoHunkText, "1\n", "theirs\n", 1)
+	client, repo := createDivergedRepo(t, ours, theirs)
+
+	result, err := client.Merge("feature")
+	if !errors.Is(err, ErrMergeConflict) || result.Success {
+		t.Fatalf("expected a merge conflict, got %v", err)
+	}
+	content := readWorktree(t, client, "code.txt")
+	if !strings.Contains(content, "<<<<<<< HEAD\nours\n||||||| base\n1\n=======\ntheirs\n>>>>>>> branch 'feature'\n") {
+		t.Errorf("expected conflict markers, got:\n%s", content)
+	}
+
+	state, err := client.MergeState()
+	if err != nil || state == nil || state.Operation != "merge" || len(state.Conflicts) != 1 || state.Conflicts[0] != "code.txt" {
+		t.Fatalf("unexpected merge state %+v (err=%v)", state, err)
+	}
+	if got := statusOf(t, client, "code.txt").Code(); got != "UU" {
+		t.Errorf("expected code.txt unmerged, got %q", got)
+	}
+
+	// Nothing can be committed or resolved while markers remain
+	if _, err := client.ResolveFile("code.txt"); err == nil || !strings.Contains(err.Error(), "conflict markers") {
+		t.Errorf("expected markers to block resolving, got %v", err)
+	}
+	if _, err := client.ContinueMerge(); err == nil {
+		t.Error("expected continuing to fail with conflicts left")
+	}
+	if _, err := client.Commit("early"); err == nil {
+		t.Error("expected committing to fail with conflicts left")
+	}
+
+	resolved := strings.Replace(twoHunkText, "1\n", "both\n", 1)
+	writeFile(t, client.workDir, "code.txt", resolved)
+	if _, err := client.ResolveFile("code.txt"); err != nil {
+		t.Fatalf("ResolveFile failed: %v", err)
+	}
+	if state, _ := client.MergeState(); state == nil || len(state.Conflicts) != 0 {
+		t.Errorf("expected the merge to stay in progress without conflicts, got %+v", state)
+	}
+
+	result, err = client.ContinueMerge()
+	if err != nil || !result.Success {
+		t.Fatalf("ContinueMerge failed: %v", err)
+	}
+	if state, _ := client.MergeState(); state != nil {
+		t.Errorf("expected no merge in progress, got %+v", state)
+	}
+	if headParents(t, repo) != 2 {
+		t.Error("expected a merge commit")
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.Message != "Merge branch 'feature' into master" {
+		t.Errorf("unexpected merge message %q", commit.Message)
+	}
+	if file, _ := commit.File("code.txt"); file == nil {
+		t.Error("code.txt missing from the merge commit")
+	} else if got, _ := file.Contents(); got != resolved {
+		t.Errorf("merge commit has %q, want %q", got, resolved)
+	}
+}
+
+func TestMerge_CommitFinishesMerge(t *testing.T) {
+	client, repo := createDivergedRepo(t, "ours\n", "theirs\n")
+	client.Merge("feature")
+	writeFile(t, client.workDir, "code.txt", "resolved\n")
+	if _, err := client.StageFile("code.txt"); err != nil {
+		t.Fatalf("staging should resolve the file: %v", err)
+	}
+
+	if _, err := client.Commit("Merge feature by hand"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if headParents(t, repo) != 2 {
+		t.Error("expected the commit to record both parents")
+	}
+	if state, _ := client.MergeState(); state != nil {
+		t.Errorf("expected the merge to be finished, got %+v", state)
+	}
+}
+
+func TestMerge_ModifyDeleteConflict(t *testing.T) {
+	client, repo := createDivergedRepo(t, "changed\n", twoHunkText+"13\n")
+	worktree, _ := repo.Worktree()
+	checkoutBranch(t, worktree, "feature")
+	if _, err := worktree.Remove("code.txt"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "other.txt", "x\n")
+	checkoutBranch(t, worktree, "master")
+
+	if _, err := client.Merge("feature"); !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if got := readWorktree(t, client, "code.txt"); got != "changed\n" {
+		t.Errorf("expected our version to be kept, got %q", got)
+	}
+	if got := readWorktree(t, client, "other.txt"); got != "x\n" {
+		t.Errorf("expected the clean change to be merged, got %q", got)
+	}
+
+	// Deleting the file resolves the conflict as a deletion
+	os.Remove(filepath.Join(client.workDir, "code.txt"))
+	if _, err := client.ResolveFile("code.txt"); err != nil {
+		t.Fatalf("ResolveFile failed: %v", err)
+	}
+	if _, err := client.ContinueMerge(); err != nil {
+		t.Fatalf("ContinueMerge failed: %v", err)
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if _, err := commit.File("code.txt"); err == nil {
+		t.Error("expected code.txt to be deleted by the merge")
+	}
+}
+
+func TestAbortMerge(t *testing.T) {
+	ours := strings.Replace(twoHunkText, "1\n", "ours\n", 1)
+	client, repo := createDivergedRepo(t, ours, "theirs\n")
+	before, _ := repo.Head()
+	client.Merge("feature")
+
+	if _, err := client.Merge("feature"); err == nil || !strings.Contains(err.Error(), "already in progress") {
+		t.Errorf("expected a second merge to be refused, got %v", err)
+	}
+
+	result, err := client.AbortMerge()
+	if err != nil || !result.Success {
+		t.Fatalf("AbortMerge failed: %v", err)
+	}
+	if state, _ := client.MergeState(); state != nil {
+		t.Errorf("expected no merge in progress, got %+v", state)
+	}
+	if got := readWorktree(t, client, "code.txt"); got != ours {
+		t.Errorf("expected our version back, got %q", got)
+	}
+	if after, _ := repo.Head(); after.Hash() != before.Hash() {
+		t.Error("aborting must not move HEAD")
+	}
+	if files, _ := client.FileStatuses(); len(files) != 0 {
+		t.Errorf("expected a clean worktree, got %+v", files)
+	}
+	if _, err := client.AbortMerge(); err == nil {
+		t.Error("expected an error without a merge in progress")
+	}
+}
+
+func TestMergeState_ExternalRebase(t *testing.T) {
+	client, _, _ := createChangesRepo(t)
+	if err := os.MkdirAll(client.gitFile("rebase-merge"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	state, err := client.MergeState()
+	if err != nil || state == nil || state.Operation != "rebase" {
+		t.Fatalf("expected a rebase in progress, got %+v (err=%v)", state, err)
+	}
+	if _, err := client.ContinueMerge(); err == nil || !strings.Contains(err.Error(), "git rebase --continue") {
+		t.Errorf("expected rebases to be continued with git, got %v", err)
+	}
+}
+
+func TestPull_DivergedHistoriesAreMerged(t *testing.T) {
+	client, repo, _ := createRepoWithOrigin(t)
+	base, _ := repo.Head()
+
+	// origin gets one commit and master another one on top of the same base
+	commitFile(t, repo, "remote.txt", "from origin\n")
+	pushAndFetch(t, repo, "refs/heads/master:refs/heads/master")
+	worktree, _ := repo.Worktree()
+	if err := worktree.Reset(&git.ResetOptions{Commit: base.Hash(), Mode: git.HardReset}); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "local.txt", "local\n")
+
+	result, err := client.Pull("", "")
+	if err != nil || !result.Success {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "origin/master") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	if got := readWorktree(t, client, "remote.txt"); got != "from origin\n" {
+		t.Errorf("expected the remote change, got %q", got)
+	}
+	if headParents(t, repo) != 2 {
+		t.Error("expected a merge commit")
+	}
+}
@@ -1,22 +1,53 @@
 package git
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // Credentials represents Git authentication credentials including URL, username, and password/token.
 // The Password field can contain either a traditional password or a GitHub Personal Access Token (PAT).
+// For SSH remotes it holds the passphrase of the SSH key, if any.
 type Credentials struct {
 	URL      string // Repository URL (e.g., https://github.com/user/repo)
 	Username string // Git username
 	Password string // Password or GitHub PAT (tokens begin with ghp_)
 }
 
+// storeFile is the encrypted credential store, inside the .git directory.
+const storeFile = "ti-credentials"
+
+// PassphraseEnv names the environment variable that, when set, derives the
+// store key from a passphrase instead of the key file.
+const PassphraseEnv = "TI_CREDENTIAL_PASSPHRASE"
+
+// pbkdf2Iterations is the work factor for passphrase-derived keys.
+const pbkdf2Iterations = 600000
+
+// sealedStore is the on-disk form of the credential store: the JSON list
+// of credentials encrypted with AES-256-GCM.
+type sealedStore struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`            // "keyfile" or "pbkdf2"
+	Salt    []byte `json:"salt,omitempty"` // For "pbkdf2"
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
 // Store manages persistent storage and retrieval of Git credentials.
-// Credentials are stored in the repository's .git/config file using standard Git configuration format.
+// Credentials are encrypted into .git/ti-credentials with a key kept outside
+// the repository, so a copied or shared .git directory does not leak them.
+// Plaintext credentials that older versions wrote to .git/config are moved
+// into the encrypted store the first time they are loaded.
 type Store struct {
 	repoPath string // Path to the Git repository root directory
 }
@@ -29,10 +60,10 @@ func NewStore(repoPath string) *Store {
 	}
 }
 
-// Save writes credentials to the repository's .git/config file using standard Git configuration format.
-// The credentials are stored in a [credential "URL"] section with username and password fields.
-// For security, the .git/config file permissions are set to 0600 (owner read/write only).
-// GitHub Personal Access Tokens (beginning with ghp_) are supported and stored as-is.
+// Save encrypts credentials into the repository's credential store,
+// replacing any saved for the same URL, and removes a plaintext copy for
+// that URL from .git/config. The store file is only readable by its owner
+// (0600).
 //
 // Requirements: 14.1, 14.4, 5.2
 func (s *Store) Save(creds *Credentials) error {
@@ -40,192 +71,362 @@ func (s *Store) Save(creds *Credentials) error {
 		return fmt.Errorf("credentials cannot be nil")
 	}
 
-	configPath := filepath.Join(s.repoPath, ".git", "config")
-	
 	// Check if .git directory exists
 	gitDir := filepath.Join(s.repoPath, ".git")
 	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
 		return fmt.Errorf(".git directory not found at %s", gitDir)
 	}
 
-	// Read existing config file
+	entries, err := s.readEntries()
+	if err != nil {
+		return err
+	}
+	entries = slices.DeleteFunc(entries, func(e Credentials) bool { return e.URL == creds.URL })
+	entries = append(entries, *creds)
+	if err := s.writeEntries(entries); err != nil {
+		return err
+	}
+
+	return s.removePlaintext(func(url string) bool { return url == creds.URL })
+}
+
+// Load retrieves the most recently saved credentials. Credentials still in
+// plaintext in .git/config are migrated into the encrypted store first.
+// Returns an error if there is neither a store nor a .git/config file, or
+// if no credentials are found.
+//
+// Requirements: 14.2
+func (s *Store) Load() (*Credentials, error) {
+	entries, err := s.readEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(s.repoPath, ".git", "config")
 	content, err := os.ReadFile(configPath)
 	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if os.IsNotExist(err) && len(entries) == 0 {
+		return nil, fmt.Errorf("config file not found at %s", configPath)
 	}
 
-	// Parse existing config and remove old credential section for this URL
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	inCredentialSection := false
-	credentialSectionHeader := fmt.Sprintf("[credential \"%s\"]", creds.URL)
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		
-		// Check if we're entering a credential section for our URL
-		if trimmed == credentialSectionHeader {
-			inCredentialSection = true
-			continue // Skip this line, we'll add new section later
+	if plaintext := parsePlaintext(string(content)); len(plaintext) > 0 {
+		for _, creds := range plaintext {
+			entries = slices.DeleteFunc(entries, func(e Credentials) bool { return e.URL == creds.URL })
+			entries = append(entries, creds)
 		}
-		
-		// Check if we're entering a different section
-		if strings.HasPrefix(trimmed, "[") && trimmed != credentialSectionHeader {
-			inCredentialSection = false
+		if err := s.writeEntries(entries); err != nil {
+			return nil, fmt.Errorf("failed to migrate credentials: %w", err)
 		}
-		
-		// Skip lines within the credential section we're replacing
-		if inCredentialSection {
-			continue
+		migrated := func(url string) bool {
+			return slices.ContainsFunc(plaintext, func(c Credentials) bool { return c.URL == url })
+		}
+		if err := s.removePlaintext(migrated); err != nil {
+			return nil, fmt.Errorf("failed to migrate credentials: %w", err)
 		}
-		
-		newLines = append(newLines, line)
 	}
 
-	// Add new credential section
-	newLines = append(newLines, credentialSectionHeader)
-	newLines = append(newLines, fmt.Sprintf("\tusername = %s", creds.Username))
-	newLines = append(newLines, fmt.Sprintf("\tpassword = %s", creds.Password))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if creds := entries[i]; creds.URL != "" && creds.Username != "" && creds.Password != "" {
+			return &creds, nil
+		}
+	}
+	return nil, fmt.Errorf("no credentials found in config file")
+}
 
-	// Write updated config
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(configPath, []byte(newContent), 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+// Clear removes the encrypted credential store and the [credential "URL"]
+// sections this store used to write to the repository's .git/config file.
+// This method gracefully handles cases where neither file exists. Other
+// configuration, including a plain [credential] section naming the user's
+// credential helper, is preserved.
+//
+// Requirements: 14.3
+func (s *Store) Clear() error {
+	if err := os.Remove(s.storePath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove credential store: %w", err)
 	}
+	return s.removePlaintext(func(string) bool { return true })
+}
+
+// storePath returns the path of the encrypted store file.
+func (s *Store) storePath() string {
+	return filepath.Join(s.repoPath, ".git", storeFile)
+}
 
-	// Explicitly set file permissions to 0600 for security (owner read/write only)
-	if err := os.Chmod(configPath, 0600); err != nil {
-		return fmt.Errorf("failed to set config file permissions: %w", err)
+// readEntries decrypts the saved credentials, oldest first. A missing store
+// has no entries.
+func (s *Store) readEntries() ([]Credentials, error) {
+	data, err := os.ReadFile(s.storePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential store: %w", err)
 	}
 
-	return nil
+	var sealed sealedStore
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("credential store is corrupted: %w", err)
+	}
+	plain, err := openStore(&sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Credentials
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, fmt.Errorf("credential store is corrupted: %w", err)
+	}
+	return entries, nil
 }
 
-// Load retrieves credentials from the repository's .git/config file.
-// It parses the Git configuration format and returns the credentials for the repository's remote URL.
-// Returns an error if the .git/config file doesn't exist or if no credentials are found.
-//
-// Requirements: 14.2
-func (s *Store) Load() (*Credentials, error) {
-	configPath := filepath.Join(s.repoPath, ".git", "config")
-	
-	// Check if config file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("config file not found at %s", configPath)
+// writeEntries encrypts entries into the store file, owner-only.
+func (s *Store) writeEntries(entries []Credentials) error {
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	sealed, err := sealStore(plain)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(sealed)
+	if err != nil {
+		return fmt.Errorf("failed to encode credential store: %w", err)
 	}
 
-	// Read config file
+	path := s.storePath()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credential store: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to set credential store permissions: %w", err)
+	}
+	return nil
+}
+
+// removePlaintext removes the [credential "URL"] sections of .git/config
+// whose URL matches.
+func (s *Store) removePlaintext(match func(url string) bool) error {
+	return s.removeSections(func(header string) bool {
+		url, ok := credentialURL(header)
+		return ok && match(url)
+	})
+}
+
+// removeSections rewrites .git/config without the sections whose header
+// matches, leaving the file untouched when there are none.
+func (s *Store) removeSections(match func(header string) bool) error {
+	configPath := filepath.Join(s.repoPath, ".git", "config")
 	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil // Gracefully handle missing file
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse config to find credential sections
-	lines := strings.Split(string(content), "\n")
-	var currentURL string
-	var username, password string
-	inCredentialSection := false
-
+	text, trailingNewline := strings.CutSuffix(string(content), "\n")
+	lines := strings.Split(text, "\n")
+	var newLines []string
+	removing, removed := false, false
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
-		
-		// Check for credential section header: [credential "URL"]
-		if strings.HasPrefix(trimmed, "[credential \"") && strings.HasSuffix(trimmed, "\"]") {
-			inCredentialSection = true
-			// Extract URL from [credential "URL"]
-			start := strings.Index(trimmed, "\"") + 1
-			end := strings.LastIndex(trimmed, "\"")
-			if start > 0 && end > start {
-				currentURL = trimmed[start:end]
+		if strings.HasPrefix(trimmed, "[") {
+			removing = match(trimmed)
+			removed = removed || removing
+		}
+		if !removing {
+			newLines = append(newLines, line)
+		}
+	}
+	if !removed {
+		return nil
+	}
+
+	text = strings.Join(newLines, "\n")
+	if trailingNewline && text != "" {
+		text += "\n"
+	}
+	if err := os.WriteFile(configPath, []byte(text), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// credentialURL extracts URL from a [credential "URL"] section header.
+func credentialURL(header string) (string, bool) {
+	if !strings.HasPrefix(header, "[credential \"") || !strings.HasSuffix(header, "\"]") {
+		return "", false
+	}
+	return header[len("[credential \"") : len(header)-len("\"]")], true
+}
+
+// parsePlaintext returns the complete credential sections of a .git/config
+// file, in file order.
+func parsePlaintext(content string) []Credentials {
+	var found []Credentials
+	var current *Credentials
+	flush := func() {
+		if current != nil && current.URL != "" && current.Username != "" && current.Password != "" {
+			found = append(found, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			flush()
+			if url, ok := credentialURL(trimmed); ok {
+				current = &Credentials{URL: url}
 			}
 			continue
 		}
-		
-		// Check if we're entering a different section
-		if strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "[credential") {
-			inCredentialSection = false
+		if current == nil {
 			continue
 		}
-		
-		// Parse username and password within credential section
-		if inCredentialSection {
-			if strings.Contains(trimmed, "username = ") {
-				parts := strings.SplitN(trimmed, "username = ", 2)
-				if len(parts) == 2 {
-					username = strings.TrimSpace(parts[1])
-				}
-			} else if strings.Contains(trimmed, "password = ") {
-				parts := strings.SplitN(trimmed, "password = ", 2)
-				if len(parts) == 2 {
-					password = strings.TrimSpace(parts[1])
-				}
-			}
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "username":
+			current.Username = strings.TrimSpace(value)
+		case "password":
+			current.Password = strings.TrimSpace(value)
 		}
 	}
+	flush()
+	return found
+}
 
-	// Check if we found credentials
-	if currentURL == "" || username == "" || password == "" {
-		return nil, fmt.Errorf("no credentials found in config file")
+// sealStore encrypts plain with a fresh nonce, under the passphrase key if
+// PassphraseEnv is set and the key file otherwise.
+func sealStore(plain []byte) (*sealedStore, error) {
+	sealed := &sealedStore{Version: 1, KDF: "keyfile"}
+	if os.Getenv(PassphraseEnv) != "" {
+		sealed.KDF = "pbkdf2"
+		sealed.Salt = make([]byte, 16)
+		if _, err := rand.Read(sealed.Salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
 	}
 
-	return &Credentials{
-		URL:      currentURL,
-		Username: username,
-		Password: password,
-	}, nil
+	gcm, err := storeCipher(sealed)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed.Data = gcm.Seal(nil, sealed.Nonce, plain, nil)
+	return sealed, nil
 }
 
-// Clear removes all credential sections from the repository's .git/config file.
-// This method gracefully handles cases where the .git/config file doesn't exist.
-// It preserves all other configuration sections while removing only credential sections.
-//
-// Requirements: 14.3
-func (s *Store) Clear() error {
-	configPath := filepath.Join(s.repoPath, ".git", "config")
-	
-	// Check if config file exists - if not, nothing to clear
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil // Gracefully handle missing file
+// openStore decrypts a sealed store.
+func openStore(sealed *sealedStore) ([]byte, error) {
+	gcm, err := storeCipher(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("credential store is corrupted: bad nonce")
 	}
+	plain, err := gcm.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt credential store: wrong key or passphrase")
+	}
+	return plain, nil
+}
 
-	// Read existing config file
-	content, err := os.ReadFile(configPath)
+// storeCipher returns the AES-GCM cipher for a sealed store's key.
+func storeCipher(sealed *sealedStore) (cipher.AEAD, error) {
+	var key []byte
+	var err error
+	switch sealed.KDF {
+	case "keyfile":
+		key, err = loadKeyFile()
+	case "pbkdf2":
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("credential store is locked: set %s to its passphrase", PassphraseEnv)
+		}
+		key, err = pbkdf2.Key(sha256.New, passphrase, sealed.Salt, pbkdf2Iterations, 32)
+	default:
+		return nil, fmt.Errorf("credential store is corrupted: unknown key type %q", sealed.KDF)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
-	// Parse config and remove all credential sections
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	inCredentialSection := false
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		
-		// Check if we're entering a credential section
-		if strings.HasPrefix(trimmed, "[credential") {
-			inCredentialSection = true
-			continue // Skip credential section header
-		}
-		
-		// Check if we're entering a different section
-		if strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "[credential") {
-			inCredentialSection = false
-		}
-		
-		// Skip lines within credential sections
-		if inCredentialSection {
-			continue
+// keyFilePath returns where the store key is kept: credentials.key in the
+// terminal-intelligence directory of the user's config directory.
+func keyFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the credential key: %w", err)
+	}
+	return filepath.Join(dir, "terminal-intelligence", "credentials.key"), nil
+}
+
+// loadKeyFile reads the 32-byte store key, creating it owner-only on first
+// use. A new key is written to a temporary file and then linked into place,
+// so the key file is never seen half-written.
+func loadKeyFile() ([]byte, error) {
+	path, err := keyFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("credential key %s is corrupted", path)
 		}
-		
-		newLines = append(newLines, line)
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read credential key: %w", err)
 	}
 
-	// Write updated config
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(configPath, []byte(newContent), 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate credential key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create credential key directory: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".credentials.key-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write credential key: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write credential key: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write credential key: %w", err)
 	}
 
-	return nil
+	// Link rather than rename: like O_EXCL it fails when another process
+	// created the key first, so neither overwrites the other's key
+	if err := os.Link(tmp, path); os.IsExist(err) {
+		return loadKeyFile()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to write credential key: %w", err)
+	}
+	return key, nil
 }
@@ -51,37 +51,42 @@ func TestSave(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	// Verify file permissions are 0600
-	info, err := os.Stat(configPath)
+	// Verify the store file permissions are 0600
+	storePath := filepath.Join(gitDir, storeFile)
+	info, err := os.Stat(storePath)
 	if err != nil {
-		t.Fatalf("Failed to stat config file: %v", err)
+		t.Fatalf("Failed to stat credential store: %v", err)
 	}
 	if info.Mode().Perm() != 0600 {
 		t.Errorf("Expected file permissions 0600, got %o", info.Mode().Perm())
 	}
 
-	// Read and verify config content
+	// Verify the token is not stored in the clear anywhere
+	stored, err := os.ReadFile(storePath)
+	if err != nil {
+		t.Fatalf("Failed to read credential store: %v", err)
+	}
+	if strings.Contains(string(stored), "ghp_testtoken123") || strings.Contains(string(stored), "testuser") {
+		t.Errorf("Credential store is not encrypted. Got:\n%s", stored)
+	}
+
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		t.Fatalf("Failed to read config file: %v", err)
 	}
 
 	configStr := string(content)
-
-	// Verify credential section exists
-	expectedSection := `[credential "https://github.com/test/repo.git"]`
-	if !strings.Contains(configStr, expectedSection) {
-		t.Errorf("Config missing credential section. Got:\n%s", configStr)
+	if strings.Contains(configStr, "[credential") || strings.Contains(configStr, "ghp_testtoken123") {
+		t.Errorf("Config should not contain credentials. Got:\n%s", configStr)
 	}
 
-	// Verify username
-	if !strings.Contains(configStr, "username = testuser") {
-		t.Errorf("Config missing username. Got:\n%s", configStr)
+	// Verify the credentials round-trip
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
 	}
-
-	// Verify password (GitHub PAT)
-	if !strings.Contains(configStr, "password = ghp_testtoken123") {
-		t.Errorf("Config missing password. Got:\n%s", configStr)
+	if *loaded != *creds {
+		t.Errorf("Expected %+v, got %+v", *creds, *loaded)
 	}
 
 	// Verify original config sections are preserved
@@ -139,21 +144,25 @@ func TestSave_UpdateExistingCredentials(t *testing.T) {
 
 	configStr := string(content)
 
-	// Verify new credentials
-	if !strings.Contains(configStr, "username = newuser") {
-		t.Errorf("Config should have new username. Got:\n%s", configStr)
-	}
-	if !strings.Contains(configStr, "password = ghp_newtoken456") {
-		t.Errorf("Config should have new password. Got:\n%s", configStr)
+	// Verify the plaintext credentials are gone
+	if strings.Contains(configStr, "[credential") {
+		t.Errorf("Config should not contain a credential section. Got:\n%s", configStr)
 	}
-
-	// Verify old credentials are gone
 	if strings.Contains(configStr, "olduser") {
 		t.Errorf("Config should not contain old username. Got:\n%s", configStr)
 	}
 	if strings.Contains(configStr, "oldpass") {
 		t.Errorf("Config should not contain old password. Got:\n%s", configStr)
 	}
+
+	// Verify new credentials
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Username != "newuser" || loaded.Password != "ghp_newtoken456" {
+		t.Errorf("Expected new credentials, got %+v", *loaded)
+	}
 }
 
 func TestSave_NoGitDirectory(t *testing.T) {
@@ -449,6 +458,35 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestClear_KeepsCredentialHelper(t *testing.T) {
+	tempDir := t.TempDir()
+	gitDir := filepath.Join(tempDir, ".git")
+	if err := os.Mkdir(gitDir, 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	configPath := filepath.Join(gitDir, "config")
+	configContent := `[credential]
+	helper = osxkeychain
+[credential "https://github.com/test/repo.git"]
+	username = testuser
+	password = ghp_testtoken123
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	if err := NewStore(tempDir).Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if got := string(content); got != "[credential]\n\thelper = osxkeychain\n" {
+		t.Errorf("Expected only the helper section to remain, got %q", got)
+	}
+}
+
 func TestClear_MissingConfigFile(t *testing.T) {
 	// Create a temporary directory without .git/config
 	tempDir, err := os.MkdirTemp("", "git-creds-test-*")
@@ -637,7 +675,7 @@ func TestProperty4_CredentialRoundTripPersistence(t *testing.T) {
 // TestProperty18_CredentialStorageSecurity tests Property 18: Credential Storage Security
 // **Validates: Requirements 14.3**
 //
-// For any credentials saved to the credential store, the storage file (.git/config)
+// For any credentials saved to the credential store, the storage file (.git/ti-credentials)
 // should have file permissions set to 0600 (owner read/write only).
 func TestProperty18_CredentialStorageSecurity(t *testing.T) {
 	properties := gopter.NewProperties(nil)
@@ -695,7 +733,7 @@ func TestProperty18_CredentialStorageSecurity(t *testing.T) {
 			}
 
 			// Verify file permissions are 0600 after save
-			info, err := os.Stat(configPath)
+			info, err := os.Stat(filepath.Join(gitDir, storeFile))
 			if err != nil {
 				t.Logf("Failed to stat config file after save: %v", err)
 				return false
@@ -1061,17 +1099,12 @@ func TestSave_EmptyCredentialFields(t *testing.T) {
 				t.Fatalf("Save failed: %v", err)
 			}
 
-			// Verify the config file was written
-			content, err := os.ReadFile(configPath)
-			if err != nil {
-				t.Fatalf("Failed to read config file: %v", err)
+			// Verify the credential store was written
+			if _, err := os.Stat(filepath.Join(gitDir, storeFile)); err != nil {
+				t.Errorf("Credential store should exist: %v", err)
 			}
-
-			configStr := string(content)
-			
-			// Verify credential section exists (even if URL is empty)
-			if !strings.Contains(configStr, "[credential") {
-				t.Errorf("Config should contain credential section. Got:\n%s", configStr)
+			if _, err := os.Stat(configPath); err != nil {
+				t.Errorf("Config file should be kept: %v", err)
 			}
 			
 			// Note: Load will fail for empty fields, which is expected behavior
@@ -1254,3 +1287,126 @@ func TestSave_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected last saved password 'pass4', got %q", loadedCreds.Password)
 	}
 }
+
+// newCredentialRepo creates a directory with a .git directory and the given
+// .git/config content.
+func newCredentialRepo(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	return dir
+}
+
+func TestLoad_MigratesPlaintextCredentials(t *testing.T) {
+	dir := newCredentialRepo(t, `[core]
+	repositoryformatversion = 0
+[credential "https://github.com/other/repo.git"]
+	username = otheruser
+	password = otherpass
+[credential]
+	helper = cache
+[credential "https://github.com/test/repo.git"]
+	username = testuser
+	password = ghp_testtoken123
+`)
+	store := NewStore(dir)
+
+	creds, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if creds.URL != "https://github.com/test/repo.git" || creds.Password != "ghp_testtoken123" {
+		t.Errorf("Expected the last credential section, got %+v", *creds)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".git", "config"))
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	configStr := string(content)
+	if strings.Contains(configStr, "password") || strings.Contains(configStr, "otheruser") {
+		t.Errorf("Plaintext credentials should be removed. Got:\n%s", configStr)
+	}
+	if !strings.Contains(configStr, "helper = cache") || !strings.Contains(configStr, "[core]") {
+		t.Errorf("Other sections should be kept. Got:\n%s", configStr)
+	}
+
+	// Both sections were migrated, and loading again reads the store
+	entries, err := store.readEntries()
+	if err != nil {
+		t.Fatalf("readEntries failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Username != "otheruser" {
+		t.Errorf("Expected both credentials in the store, got %+v", entries)
+	}
+	again, err := store.Load()
+	if err != nil || *again != *creds {
+		t.Errorf("Expected %+v after migration, got %+v (%v)", *creds, again, err)
+	}
+}
+
+func TestLoad_StoreWithoutConfigFile(t *testing.T) {
+	dir := newCredentialRepo(t, "")
+	store := NewStore(dir)
+	creds := &Credentials{URL: "https://example.com/repo.git", Username: "user", Password: "pass"}
+	if err := store.Save(creds); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	os.Remove(filepath.Join(dir, ".git", "config"))
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if *loaded != *creds {
+		t.Errorf("Expected %+v, got %+v", *creds, *loaded)
+	}
+}
+
+func TestLoad_Passphrase(t *testing.T) {
+	t.Setenv(PassphraseEnv, "correct horse")
+	dir := newCredentialRepo(t, "")
+	store := NewStore(dir)
+	creds := &Credentials{URL: "https://example.com/repo.git", Username: "user", Password: "pass"}
+	if err := store.Save(creds); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil || *loaded != *creds {
+		t.Fatalf("Expected %+v, got %+v (%v)", *creds, loaded, err)
+	}
+
+	t.Setenv(PassphraseEnv, "wrong")
+	if _, err := store.Load(); err == nil || !strings.Contains(err.Error(), "wrong key or passphrase") {
+		t.Errorf("Expected a decryption error, got %v", err)
+	}
+
+	t.Setenv(PassphraseEnv, "")
+	if _, err := store.Load(); err == nil || !strings.Contains(err.Error(), PassphraseEnv) {
+		t.Errorf("Expected the store to be locked, got %v", err)
+	}
+}
+
+func TestClear_RemovesStore(t *testing.T) {
+	dir := newCredentialRepo(t, "[core]\n\tbare = false\n")
+	store := NewStore(dir)
+	if err := store.Save(&Credentials{URL: "https://example.com/repo.git", Username: "user", Password: "pass"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", storeFile)); !os.IsNotExist(err) {
+		t.Errorf("Credential store should be removed, got %v", err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Expected no credentials after Clear")
+	}
+}
@@ -0,0 +1,297 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/user/terminal-intelligence/internal/diff"
+)
+
+// CommitInfo describes one commit of the history.
+type CommitInfo struct {
+	Hash    string    // Full commit hash
+	Author  string    // Author name
+	Email   string    // Author email address
+	Date    time.Time // Author date
+	Message string    // Full commit message
+	Files   []string  // Paths changed relative to the first parent, sorted
+}
+
+// ShortHash returns the abbreviated commit hash.
+func (ci CommitInfo) ShortHash() string {
+	if len(ci.Hash) < 7 {
+		return ci.Hash
+	}
+	return ci.Hash[:7]
+}
+
+// Subject returns the first line of the commit message.
+func (ci CommitInfo) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(ci.Message), "\n")
+	return subject
+}
+
+// FileDiff is the change a commit made to one file.
+type FileDiff struct {
+	Path   string      // Path of the file after the commit, or before it if deleted
+	Action string      // "added", "deleted" or "modified"
+	Binary bool        // Whether the file is binary, in which case Hunks is empty
+	Hunks  []diff.Hunk // Line changes of the file
+}
+
+// BlameLine is the commit that last changed one line of a file.
+type BlameLine struct {
+	Hash   string    // Abbreviated hash of the commit
+	Author string    // Author name
+	Date   time.Time // Author date of the commit
+	Text   string    // Line content, without the line terminator
+}
+
+// Log returns up to limit commits reachable from HEAD, newest first, after
+// skipping the first skip of them.
+//
+// Parameters:
+//   - skip: Number of commits to skip, for pagination
+//   - limit: Maximum number of commits to return
+//
+// Returns:
+//   - []CommitInfo: The commits of the page
+//   - bool: Whether there are more commits after the page
+//   - error: Any error that occurred while reading the history
+func (c *Client) Log(skip, limit int) ([]CommitInfo, bool, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, false, categorizeError(err)
+	}
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, false, nil // no commits yet
+	} else if err != nil {
+		return nil, false, categorizeError(err)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, false, categorizeError(err)
+	}
+
+	var commits []CommitInfo
+	more := false
+	index := 0
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if index < skip {
+			index++
+			return nil
+		}
+		if len(commits) == limit {
+			more = true
+			return storer.ErrStop
+		}
+		info, err := commitInfo(commit)
+		if err != nil {
+			return err
+		}
+		commits = append(commits, info)
+		return nil
+	})
+	if err != nil {
+		return nil, false, categorizeError(err)
+	}
+	return commits, more, nil
+}
+
+// CommitDiff returns a commit and the changes it made to each file,
+// compared with its first parent.
+//
+// Parameters:
+//   - rev: Commit hash, abbreviated hash or other revision such as "HEAD~2"
+//
+// Returns:
+//   - *CommitInfo: The commit
+//   - []FileDiff: One entry per changed file, sorted by path
+//   - error: Any error that occurred while reading the commit
+func (c *Client) CommitDiff(rev string) (*CommitInfo, []FileDiff, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, nil, categorizeError(err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown commit %q", rev)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, categorizeError(err)
+	}
+	info, err := commitInfo(commit)
+	if err != nil {
+		return nil, nil, categorizeError(err)
+	}
+
+	changes, err := commitChanges(commit)
+	if err != nil {
+		return nil, nil, categorizeError(err)
+	}
+	var files []FileDiff
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, nil, categorizeError(err)
+		}
+		fd, err := fileDiff(from, to)
+		if err != nil {
+			return nil, nil, categorizeError(err)
+		}
+		files = append(files, fd)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return &info, files, nil
+}
+
+// Blame returns the commit that last changed each line of a file, as of
+// HEAD.
+//
+// Parameters:
+//   - path: Path of the file, absolute or relative to the working directory
+//
+// Returns:
+//   - []BlameLine: One entry per line of the committed file
+//   - error: Any error that occurred, e.g. when the file is not committed
+func (c *Client) Blame(path string) ([]BlameLine, error) {
+	repo, err := git.PlainOpenWithOptions(c.workDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.workDir, path)
+	}
+	rel, err := filepath.Rel(worktree.Filesystem.Root(), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is not inside the repository", path)
+	}
+	rel = filepath.ToSlash(rel)
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not committed yet", rel)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	if _, err := commit.File(rel); err != nil {
+		return nil, fmt.Errorf("%s is not committed yet", rel)
+	}
+
+	result, err := git.Blame(commit, rel)
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	lines := make([]BlameLine, len(result.Lines))
+	for i, l := range result.Lines {
+		lines[i] = BlameLine{
+			Hash:   l.Hash.String()[:7],
+			Author: l.AuthorName,
+			Date:   l.Date,
+			Text:   l.Text,
+		}
+	}
+	return lines, nil
+}
+
+// commitInfo describes commit, including the files it changed.
+func commitInfo(commit *object.Commit) (CommitInfo, error) {
+	changes, err := commitChanges(commit)
+	if err != nil {
+		return CommitInfo{}, err
+	}
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		files = append(files, changePath(change))
+	}
+	sort.Strings(files)
+
+	return CommitInfo{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		Date:    commit.Author.When,
+		Message: commit.Message,
+		Files:   files,
+	}, nil
+}
+
+// commitChanges returns the tree changes between the first parent of commit
+// and commit. A root commit is compared with an empty tree.
+func commitChanges(commit *object.Commit) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	return object.DiffTree(parentTree, tree)
+}
+
+// changePath returns the path of the file a change is about.
+func changePath(change *object.Change) string {
+	if change.To.Name != "" {
+		return change.To.Name
+	}
+	return change.From.Name
+}
+
+// fileDiff builds the FileDiff between two versions of a file, either of
+// which is nil when the file was added or deleted.
+func fileDiff(from, to *object.File) (FileDiff, error) {
+	var fd FileDiff
+	switch {
+	case from == nil:
+		fd.Path, fd.Action = to.Name, "added"
+	case to == nil:
+		fd.Path, fd.Action = from.Name, "deleted"
+	default:
+		fd.Path, fd.Action = to.Name, "modified"
+	}
+
+	var texts [2]string
+	for i, file := range []*object.File{from, to} {
+		if file == nil {
+			continue
+		}
+		binary, err := file.IsBinary()
+		if err != nil {
+			return fd, err
+		}
+		if binary {
+			fd.Binary = true
+			return fd, nil
+		}
+		if texts[i], err = file.Contents(); err != nil {
+			return fd, err
+		}
+	}
+	fd.Hunks = diff.Compute(texts[0], texts[1], diffContext)
+	return fd, nil
+}
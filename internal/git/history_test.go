@@ -0,0 +1,177 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitAs writes name and commits it with the given author and message
+func commitAs(t *testing.T, repo *git.Repository, name, content, author, message string) {
+	t.Helper()
+	worktree, _ := repo.Worktree()
+	writeFile(t, worktree.Filesystem.Root(), name, content)
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("Failed to stage %s: %v", name, err)
+	}
+	_, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: author, Email: strings.ToLower(author) + "@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+// createHistoryRepo returns a client on a repository with three commits:
+// alice adds a.txt, bob adds b.txt and alice changes the last line of a.txt
+func createHistoryRepo(t *testing.T) (*Client, *git.Repository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitAs(t, repo, "a.txt", "one\ntwo\nthree\n", "Alice", "Add a.txt\n\nWith three lines.")
+	commitAs(t, repo, "b.txt", "bee\n", "Bob", "Add b.txt")
+	commitAs(t, repo, "a.txt", "one\ntwo\nTHREE\n", "Alice", "Shout in a.txt")
+	return NewClient(dir), repo, dir
+}
+
+func TestLog(t *testing.T) {
+	client, _, _ := createHistoryRepo(t)
+
+	commits, more, err := client.Log(0, 10)
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 3 || more {
+		t.Fatalf("expected 3 commits and no more, got %d (more=%v)", len(commits), more)
+	}
+	newest := commits[0]
+	if newest.Subject() != "Shout in a.txt" || newest.Author != "Alice" || newest.Email != "alice@example.com" {
+		t.Errorf("unexpected newest commit: %+v", newest)
+	}
+	if len(newest.Files) != 1 || newest.Files[0] != "a.txt" {
+		t.Errorf("expected a.txt to be changed, got %v", newest.Files)
+	}
+	if len(newest.ShortHash()) != 7 || !strings.HasPrefix(newest.Hash, newest.ShortHash()) {
+		t.Errorf("unexpected short hash %q of %q", newest.ShortHash(), newest.Hash)
+	}
+	if root := commits[2]; root.Subject() != "Add a.txt" || !strings.Contains(root.Message, "three lines") {
+		t.Errorf("unexpected root commit: %+v", root)
+	}
+}
+
+func TestLog_Pagination(t *testing.T) {
+	client, _, _ := createHistoryRepo(t)
+
+	page, more, err := client.Log(0, 2)
+	if err != nil || len(page) != 2 || !more {
+		t.Fatalf("expected a first page of 2 with more, got %d (more=%v, err=%v)", len(page), more, err)
+	}
+	rest, more, err := client.Log(2, 2)
+	if err != nil || len(rest) != 1 || more {
+		t.Fatalf("expected a last page of 1, got %d (more=%v, err=%v)", len(rest), more, err)
+	}
+	if rest[0].Subject() != "Add a.txt" {
+		t.Errorf("expected the root commit last, got %q", rest[0].Subject())
+	}
+}
+
+func TestLog_EmptyRepository(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	commits, more, err := NewClient(dir).Log(0, 10)
+	if err != nil || len(commits) != 0 || more {
+		t.Errorf("expected no commits, got %d (more=%v, err=%v)", len(commits), more, err)
+	}
+}
+
+func TestCommitDiff(t *testing.T) {
+	client, _, _ := createHistoryRepo(t)
+
+	info, files, err := client.CommitDiff("HEAD")
+	if err != nil {
+		t.Fatalf("CommitDiff failed: %v", err)
+	}
+	if info.Subject() != "Shout in a.txt" {
+		t.Errorf("unexpected commit: %+v", info)
+	}
+	if len(files) != 1 || files[0].Path != "a.txt" || files[0].Action != "modified" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	if len(files[0].Hunks) != 1 || strings.Join(files[0].Hunks[0].NewLines(), "") != "one\ntwo\nTHREE\n" {
+		t.Errorf("unexpected hunks: %+v", files[0].Hunks)
+	}
+
+	// The root commit is compared with an empty tree, by abbreviated hash
+	commits, _, _ := client.Log(2, 1)
+	_, files, err = client.CommitDiff(commits[0].ShortHash())
+	if err != nil {
+		t.Fatalf("CommitDiff of the root commit failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Action != "added" {
+		t.Errorf("expected a.txt to be added, got %+v", files)
+	}
+
+	if _, _, err := client.CommitDiff("does-not-exist"); err == nil {
+		t.Error("expected an error for an unknown commit")
+	}
+}
+
+func TestCommitDiff_BinaryFile(t *testing.T) {
+	client, repo, _ := createHistoryRepo(t)
+	commitAs(t, repo, "logo.png", "\x89PNG\x00\x01", "Bob", "Add logo")
+
+	_, files, err := client.CommitDiff("HEAD")
+	if err != nil {
+		t.Fatalf("CommitDiff failed: %v", err)
+	}
+	if len(files) != 1 || !files[0].Binary || len(files[0].Hunks) != 0 {
+		t.Errorf("expected a binary file without hunks, got %+v", files)
+	}
+}
+
+func TestBlame(t *testing.T) {
+	client, _, dir := createHistoryRepo(t)
+
+	lines, err := client.Blame(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatalf("Blame failed: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %+v", lines)
+	}
+	if lines[0].Text != "one" || lines[2].Text != "THREE" {
+		t.Errorf("unexpected line texts: %+v", lines)
+	}
+	if lines[0].Hash == lines[2].Hash || lines[0].Hash != lines[1].Hash {
+		t.Errorf("expected the last line to come from a later commit: %+v", lines)
+	}
+
+	// Relative paths are relative to the working directory
+	if rel, err := client.Blame("a.txt"); err != nil || len(rel) != 3 {
+		t.Errorf("expected blame of a relative path, got %d lines (err=%v)", len(rel), err)
+	}
+}
+
+func TestBlame_Errors(t *testing.T) {
+	client, _, dir := createHistoryRepo(t)
+	writeFile(t, dir, "new.txt", "not committed\n")
+
+	if _, err := client.Blame(filepath.Join(dir, "new.txt")); err == nil || !strings.Contains(err.Error(), "not committed") {
+		t.Errorf("expected not committed error, got %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "x.txt")
+	os.WriteFile(outside, []byte("x\n"), 0644)
+	if _, err := client.Blame(outside); err == nil || !strings.Contains(err.Error(), "not inside") {
+		t.Errorf("expected outside repository error, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreRules are the ignore patterns of a working tree: .git/info/exclude
+// and the .gitignore files of its directories.
+type IgnoreRules struct {
+	matcher gitignore.Matcher
+}
+
+// IgnoreRules reads the ignore patterns of the working directory. It works
+// whether or not the directory is a repository; directories that are
+// ignored themselves, or that cannot be read, are not searched for
+// .gitignore files.
+//
+// Returns:
+//   - *IgnoreRules: The patterns found, possibly none
+//   - error: Any error that occurred while reading the directories
+func (c *Client) IgnoreRules() (*IgnoreRules, error) {
+	patterns, err := readIgnoreFile(filepath.Join(c.workDir, ".git", "info", "exclude"), nil)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err = readIgnorePatterns(c.workDir, nil, patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &IgnoreRules{matcher: gitignore.NewMatcher(patterns)}, nil
+}
+
+// readIgnorePatterns adds the patterns of the .gitignore file in the
+// directory at the slash-separated path below root, and of its
+// subdirectories, to patterns. Directories without read permission are
+// skipped.
+func readIgnorePatterns(root string, path []string, patterns []gitignore.Pattern) ([]gitignore.Pattern, error) {
+	dir := filepath.Join(append([]string{root}, path...)...)
+	own, err := readIgnoreFile(filepath.Join(dir, ".gitignore"), path)
+	if os.IsPermission(err) {
+		return patterns, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsPermission(err) {
+		return append(patterns, own...), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	patterns = append(patterns, own...)
+	matcher := gitignore.NewMatcher(patterns)
+	for _, entry := range entries {
+		sub := append(slices.Clone(path), entry.Name())
+		if !entry.IsDir() || entry.Name() == ".git" || matcher.Match(sub, true) {
+			continue
+		}
+		if patterns, err = readIgnorePatterns(root, sub, patterns); err != nil {
+			return nil, err
+		}
+	}
+	return patterns, nil
+}
+
+// readIgnoreFile parses the ignore file at name, whose patterns apply below
+// the slash-separated directory domain. A missing file has no patterns.
+func readIgnoreFile(name string, domain []string) ([]gitignore.Pattern, error) {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []gitignore.Pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+	return patterns, scanner.Err()
+}
+
+// Ignored reports whether the file or directory at path, slash-separated
+// and relative to the working directory, is ignored. A nil IgnoreRules
+// ignores nothing.
+func (r *IgnoreRules) Ignored(path string, isDir bool) bool {
+	if r == nil || path == "" {
+		return false
+	}
+	return r.matcher.Match(strings.Split(path, "/"), isDir)
+}
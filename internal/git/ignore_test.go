@@ -0,0 +1,76 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"web/dist", ".git/info"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, dir, ".git/info/exclude", "secret.txt\n")
+	writeFile(t, dir, ".gitignore", "*.log\nbuild/\n")
+	writeFile(t, dir, "web/.gitignore", "dist\n!keep.log\n")
+
+	rules, err := NewClient(dir).IgnoreRules()
+	if err != nil {
+		t.Fatalf("IgnoreRules failed: %v", err)
+	}
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"app.go", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"web/dist", true, true},
+		{"dist", true, false},
+		{"web/keep.log", false, false},
+		{"web/other.log", false, true},
+		{"secret.txt", false, true},
+		{"", true, false},
+	}
+	for _, tt := range tests {
+		if got := rules.Ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreRules_SkipsUnreadableDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "private"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	if err := os.Chmod(filepath.Join(dir, "private"), 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(dir, "private"), 0755) })
+
+	rules, err := NewClient(dir).IgnoreRules()
+	if err != nil {
+		t.Fatalf("IgnoreRules failed: %v", err)
+	}
+	if !rules.Ignored("app.log", false) {
+		t.Error("expected the readable patterns to apply")
+	}
+}
+
+func TestIgnoreRules_Nil(t *testing.T) {
+	var rules *IgnoreRules
+	if rules.Ignored("a.log", false) {
+		t.Error("nil rules should ignore nothing")
+	}
+}
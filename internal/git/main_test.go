@@ -0,0 +1,20 @@
+package git
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain points the user config directory at a temporary one, so the
+// credential store key created by tests never lands in the real one.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "git-config-home-*")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Unsetenv(PassphraseEnv)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,784 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// stashRef is the reference whose reflog holds the stash, as with git.
+const stashRef = plumbing.ReferenceName("refs/stash")
+
+// restoreRecord is the file in the .git directory that remembers the stash
+// entry and paths of the last restore, so it can be undone.
+const restoreRecord = "TI_LAST_RESTORE"
+
+// errNoLocalChanges is returned when there is nothing to stash.
+var errNoLocalChanges = errors.New("no local changes to save")
+
+// StashEntry is one entry of the stash. The stash is compatible with git's:
+// entries saved here show up in git stash list and the other way around.
+type StashEntry struct {
+	Index   int       // Position in the stash; 0 is the newest, as in stash@{0}
+	Hash    string    // Abbreviated hash of the stash commit
+	Message string    // e.g. "WIP on main: 1a2b3c4 Add parser"
+	When    time.Time // When the entry was saved
+}
+
+// Name returns the git name of the entry, e.g. "stash@{0}".
+func (s StashEntry) Name() string {
+	return fmt.Sprintf("stash@{%d}", s.Index)
+}
+
+// stashLogLine is one line of the reflog of refs/stash.
+type stashLogLine struct {
+	old, new plumbing.Hash
+	who      string // "Name <email> unix-time zone"
+	message  string
+}
+
+// stashLogPath is the reflog of the stash.
+func (c *Client) stashLogPath() string {
+	return c.gitFile(filepath.Join("logs", "refs", "stash"))
+}
+
+// readStashLog returns the reflog of the stash, oldest first. A stash ref
+// without a reflog reads as a single entry.
+func (c *Client) readStashLog(repo *git.Repository) ([]stashLogLine, error) {
+	content, err := os.ReadFile(c.stashLogPath())
+	if os.IsNotExist(err) {
+		ref, err := repo.Reference(stashRef, false)
+		if err != nil {
+			return nil, nil
+		}
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil, err
+		}
+		return []stashLogLine{{
+			new:     ref.Hash(),
+			who:     signatureLine(commit.Committer),
+			message: strings.TrimSpace(commit.Message),
+		}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []stashLogLine
+	for _, raw := range strings.Split(string(content), "\n") {
+		head, message, _ := strings.Cut(raw, "\t")
+		fields := strings.SplitN(head, " ", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		lines = append(lines, stashLogLine{
+			old:     plumbing.NewHash(fields[0]),
+			new:     plumbing.NewHash(fields[1]),
+			who:     fields[2],
+			message: message,
+		})
+	}
+	return lines, nil
+}
+
+// writeStashLog replaces the reflog of the stash and points the stash ref
+// at its newest entry. An empty log removes the stash.
+func (c *Client) writeStashLog(repo *git.Repository, lines []stashLogLine) error {
+	if len(lines) == 0 {
+		if err := os.Remove(c.stashLogPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return repo.Storer.RemoveReference(stashRef)
+	}
+
+	var sb strings.Builder
+	previous := plumbing.ZeroHash
+	for _, line := range lines {
+		fmt.Fprintf(&sb, "%s %s %s\t%s\n", previous, line.new, line.who, line.message)
+		previous = line.new
+	}
+	if err := os.MkdirAll(filepath.Dir(c.stashLogPath()), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.stashLogPath(), []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(stashRef, previous))
+}
+
+// signatureLine formats a signature as in a reflog line.
+func signatureLine(sig object.Signature) string {
+	return fmt.Sprintf("%s <%s> %d %s", sig.Name, sig.Email, sig.When.Unix(), sig.When.Format("-0700"))
+}
+
+// signatureTime parses the time of a reflog signature line.
+func signatureTime(who string) time.Time {
+	fields := strings.Fields(who)
+	if len(fields) < 2 {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// StashList returns the entries of the stash, newest first.
+//
+// Returns:
+//   - []StashEntry: The stash entries, stash@{0} first
+//   - error: Any error that occurred while reading the stash
+func (c *Client) StashList() ([]StashEntry, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	lines, err := c.readStashLog(repo)
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	entries := make([]StashEntry, 0, len(lines))
+	for i := len(lines) - 1; i >= 0; i-- {
+		entries = append(entries, StashEntry{
+			Index:   len(entries),
+			Hash:    lines[i].new.String()[:7],
+			Message: lines[i].message,
+			When:    signatureTime(lines[i].who),
+		})
+	}
+	return entries, nil
+}
+
+// stashCommit returns the commit of entry index of the stash.
+func (c *Client) stashCommit(repo *git.Repository, index int) (*object.Commit, error) {
+	lines, err := c.readStashLog(repo)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(lines) {
+		return nil, fmt.Errorf("stash@{%d} does not exist", index)
+	}
+	return repo.CommitObject(lines[len(lines)-1-index].new)
+}
+
+// StashSave saves the staged and unstaged changes of tracked files as a new
+// stash entry and restores the worktree and index to HEAD. Untracked files
+// are left alone.
+//
+// Parameters:
+//   - message: Description of the entry; empty for git's "WIP on <branch>" default
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) StashSave(message string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	hash, description, paths, err := c.createStash(repo, message, "")
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := c.pushStash(repo, hash, description); err != nil {
+		return failedResult(err)
+	}
+	if err := c.resetPaths(repo, paths); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: "Saved working directory and index state " + description,
+		Error:   nil,
+	}, nil
+}
+
+// changedPaths returns the sorted tracked paths with staged or unstaged
+// changes, limited to only when it is not empty.
+func changedPaths(repo *git.Repository, only string) ([]string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for path, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked || (only != "" && path != only) {
+			continue
+		}
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// createStash stores the changes of tracked files, or only of the path only
+// when it is not empty, as a stash commit without changing the worktree or
+// the stash. Like git, the stash commit has the worktree as its tree and
+// HEAD and a commit of the index as parents. It returns the commit, its
+// description and the paths it saved.
+func (c *Client) createStash(repo *git.Repository, message, only string) (plumbing.Hash, string, []string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, fmt.Errorf("cannot stash before the first commit")
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+	if conflicts, err := conflictedPaths(repo); err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	} else if len(conflicts) > 0 {
+		return plumbing.ZeroHash, "", nil, fmt.Errorf("resolve the merge conflicts first")
+	}
+	paths, err := changedPaths(repo, only)
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+	if len(paths) == 0 {
+		return plumbing.ZeroHash, "", nil, errNoLocalChanges
+	}
+
+	// The index tree is the whole index, or HEAD with only the one path
+	// taken from the index
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+	indexFiles := map[string]treeEntry{}
+	if only == "" {
+		for _, e := range idx.Entries {
+			indexFiles[e.Name] = treeEntry{hash: e.Hash, mode: e.Mode}
+		}
+	} else {
+		if indexFiles, err = treeFiles(headCommit); err != nil {
+			return plumbing.ZeroHash, "", nil, err
+		}
+		delete(indexFiles, only)
+		if e, err := idx.Entry(only); err == nil {
+			indexFiles[only] = treeEntry{hash: e.Hash, mode: e.Mode}
+		}
+	}
+
+	worktreeFiles := make(map[string]treeEntry, len(indexFiles))
+	for path, entry := range indexFiles {
+		worktreeFiles[path] = entry
+	}
+	for _, path := range paths {
+		info, err := os.Stat(filepath.Join(c.workDir, filepath.FromSlash(path)))
+		if os.IsNotExist(err) {
+			delete(worktreeFiles, path)
+			continue
+		} else if err != nil {
+			return plumbing.ZeroHash, "", nil, err
+		}
+		content, err := os.ReadFile(filepath.Join(c.workDir, filepath.FromSlash(path)))
+		if err != nil {
+			return plumbing.ZeroHash, "", nil, err
+		}
+		hash, err := writeBlob(repo, string(content))
+		if err != nil {
+			return plumbing.ZeroHash, "", nil, err
+		}
+		mode := filemode.Regular
+		if info.Mode()&0111 != 0 {
+			mode = filemode.Executable
+		}
+		worktreeFiles[path] = treeEntry{hash: hash, mode: mode}
+	}
+
+	indexTree, err := writeTree(repo, indexFiles)
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+	worktreeTree, err := writeTree(repo, worktreeFiles)
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+
+	branch := "(no branch)"
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	subject, _, _ := strings.Cut(strings.TrimSpace(headCommit.Message), "\n")
+	description := fmt.Sprintf("WIP on %s: %s %s", branch, head.Hash().String()[:7], subject)
+	if message != "" {
+		description = fmt.Sprintf("On %s: %s", branch, message)
+	}
+
+	indexCommit, err := writeCommit(repo, indexTree, fmt.Sprintf("index on %s: %s %s\n", branch, head.Hash().String()[:7], subject), head.Hash())
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+	stash, err := writeCommit(repo, worktreeTree, description+"\n", head.Hash(), indexCommit)
+	if err != nil {
+		return plumbing.ZeroHash, "", nil, err
+	}
+	return stash, description, paths, nil
+}
+
+// pushStash makes the stash commit hash the newest stash entry.
+func (c *Client) pushStash(repo *git.Repository, hash plumbing.Hash, description string) error {
+	lines, err := c.readStashLog(repo)
+	if err != nil {
+		return err
+	}
+	lines = append(lines, stashLogLine{
+		new:     hash,
+		who:     signatureLine(object.Signature{Name: "Terminal Intelligence User", Email: "user@terminal-intelligence.local", When: time.Now()}),
+		message: description,
+	})
+	return c.writeStashLog(repo, lines)
+}
+
+// writeTree stores files, keyed by slash-separated path, as a tree and its
+// subtrees and returns the hash of the root tree.
+func writeTree(repo *git.Repository, files map[string]treeEntry) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	dirs := map[string]map[string]treeEntry{}
+	for path, file := range files {
+		if dir, rest, ok := strings.Cut(path, "/"); ok {
+			if dirs[dir] == nil {
+				dirs[dir] = map[string]treeEntry{}
+			}
+			dirs[dir][rest] = file
+			continue
+		}
+		mode := file.mode
+		if mode == filemode.Empty {
+			mode = filemode.Regular
+		}
+		entries = append(entries, object.TreeEntry{Name: path, Mode: mode, Hash: file.hash})
+	}
+	for dir, sub := range dirs {
+		hash, err := writeTree(repo, sub)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+
+	// Git orders tree entries as if directory names ended with a slash
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortKey(entries[i]) < sortKey(entries[j])
+	})
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// writeCommit stores a commit of tree with the given parents.
+func writeCommit(repo *git.Repository, tree plumbing.Hash, message string, parents ...plumbing.Hash) (plumbing.Hash, error) {
+	signature := object.Signature{
+		Name:  "Terminal Intelligence User",
+		Email: "user@terminal-intelligence.local",
+		When:  time.Now(),
+	}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     tree,
+		ParentHashes: parents,
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// StashApply applies a stash entry to the worktree and keeps it. The
+// worktree must have no changes to tracked files. The stashed changes are
+// merged with HEAD line by line, so they can be applied after new commits;
+// overlapping changes are left as conflicts and an error wrapping
+// ErrMergeConflict is returned.
+//
+// Parameters:
+//   - index: The entry to apply; 0 is the newest
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) StashApply(index int) (*OperationResult, error) {
+	return c.applyStash(index, false)
+}
+
+// StashPop applies a stash entry like StashApply and drops it if it applied
+// without conflicts.
+//
+// Parameters:
+//   - index: The entry to apply; 0 is the newest
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) StashPop(index int) (*OperationResult, error) {
+	return c.applyStash(index, true)
+}
+
+func (c *Client) applyStash(index int, drop bool) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	stash, err := c.stashCommit(repo, index)
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := ensureClean(repo); err != nil {
+		return failedResult(err)
+	}
+	if stash.NumParents() == 0 {
+		return failedResult(fmt.Errorf("stash@{%d} is not a stash commit", index))
+	}
+	base, err := stash.Parent(0)
+	if err != nil {
+		return failedResult(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(err)
+	}
+	ours, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return failedResult(err)
+	}
+
+	name := fmt.Sprintf("stash@{%d}", index)
+	conflicts, err := c.mergeTrees(repo, base, ours, stash, name)
+	if err != nil {
+		return failedResult(err)
+	}
+	if len(conflicts) > 0 {
+		err := &GitError{
+			Category: "Conflict",
+			Message:  fmt.Sprintf("Applying %s left conflicts in %s; the stash entry is kept", name, strings.Join(conflicts, ", ")),
+			Hint:     "Resolve the conflicts and mark the files resolved",
+			Original: ErrMergeConflict,
+		}
+		return &OperationResult{Success: false, Message: "", Error: err}, err
+	}
+
+	// Like git stash apply, leave the changes unstaged apart from new files
+	if err := c.unstageApplied(repo, base, ours, stash); err != nil {
+		return failedResult(err)
+	}
+
+	message := "Applied " + name
+	if drop {
+		if err := c.dropStash(repo, index); err != nil {
+			return failedResult(err)
+		}
+		message = fmt.Sprintf("Applied and dropped %s", name)
+	}
+	return &OperationResult{Success: true, Message: message, Error: nil}, nil
+}
+
+// unstageApplied resets the index entries that applying stash changed back
+// to HEAD, keeping files that HEAD does not have staged.
+func (c *Client) unstageApplied(repo *git.Repository, base, head, stash *object.Commit) error {
+	baseFiles, err := treeFiles(base)
+	if err != nil {
+		return err
+	}
+	headFiles, err := treeFiles(head)
+	if err != nil {
+		return err
+	}
+	stashFiles, err := treeFiles(stash)
+	if err != nil {
+		return err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for path, entry := range stashFiles {
+		if entry != baseFiles[path] {
+			if headEntry, ok := headFiles[path]; ok {
+				setStages(idx, path, map[index.Stage]treeEntry{0: headEntry})
+			}
+		}
+	}
+	for path, headEntry := range headFiles {
+		if _, kept := stashFiles[path]; !kept {
+			if _, inBase := baseFiles[path]; inBase {
+				setStages(idx, path, map[index.Stage]treeEntry{0: headEntry})
+			}
+		}
+	}
+	return repo.Storer.SetIndex(idx)
+}
+
+// StashDrop removes a stash entry.
+//
+// Parameters:
+//   - index: The entry to drop; 0 is the newest
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) StashDrop(index int) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	stash, err := c.stashCommit(repo, index)
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := c.dropStash(repo, index); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Dropped stash@{%d} (%s)", index, stash.Hash.String()[:7]),
+		Error:   nil,
+	}, nil
+}
+
+func (c *Client) dropStash(repo *git.Repository, index int) error {
+	lines, err := c.readStashLog(repo)
+	if err != nil {
+		return err
+	}
+	i := len(lines) - 1 - index
+	if index < 0 || i < 0 {
+		return fmt.Errorf("stash@{%d} does not exist", index)
+	}
+	return c.writeStashLog(repo, append(lines[:i], lines[i+1:]...))
+}
+
+// resetPaths restores paths in the worktree and index to HEAD, removing
+// those that HEAD does not have. Other files, untracked ones included, are
+// not touched.
+func (c *Client) resetPaths(repo *git.Repository, paths []string) error {
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	headFiles, err := treeFiles(headCommit)
+	if err != nil {
+		return err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := c.takeFile(repo, idx, path, headFiles[path]); err != nil {
+			return err
+		}
+	}
+	return repo.Storer.SetIndex(idx)
+}
+
+// recordRestore remembers the stash entry and paths of a restore.
+func (c *Client) recordRestore(hash plumbing.Hash, paths []string) error {
+	content := hash.String() + "\n" + strings.Join(paths, "\n") + "\n"
+	return os.WriteFile(c.gitFile(restoreRecord), []byte(content), 0644)
+}
+
+// Restore discards all changes to tracked files, restoring them in the
+// worktree and index to HEAD; untracked files are kept. The changes are
+// first saved as a stash entry, so they can be brought back with
+// UndoRestore or from the stash.
+//
+// Returns:
+//   - *OperationResult: Contains success status, message with file count, and any error
+//   - error: Any error that occurred during the operation
+//
+// The OperationResult.Message field contains the number of files restored on success.
+//
+// Requirements: 13.1
+func (c *Client) Restore() (*OperationResult, error) {
+	// Open the existing repository
+	repo, err := git.PlainOpen(c.workDir)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	hash, description, paths, err := c.createStash(repo, "restore all files", "")
+	if err == errNoLocalChanges {
+		return &OperationResult{Success: true, Message: "Nothing to restore", Error: nil}, nil
+	} else if err != nil {
+		return failedResult(err)
+	}
+	if err := c.pushStash(repo, hash, description); err != nil {
+		return failedResult(err)
+	}
+	if err := c.recordRestore(hash, paths); err != nil {
+		return failedResult(err)
+	}
+	if err := c.resetPaths(repo, paths); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Restored %d file(s); the changes were saved as stash@{0}", len(paths)),
+		Error:   nil,
+	}, nil
+}
+
+// RestoreFile discards the staged and unstaged changes of one tracked file,
+// restoring it to HEAD; a file that is new in the index is removed. The
+// changes are first saved as a stash entry, like Restore.
+//
+// Parameters:
+//   - path: Slash-separated path relative to the repository root
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) RestoreFile(path string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	hash, description, paths, err := c.createStash(repo, "restore "+path, path)
+	if err == errNoLocalChanges {
+		return failedResult(fmt.Errorf("%s has no changes to restore", path))
+	} else if err != nil {
+		return failedResult(err)
+	}
+	if err := c.pushStash(repo, hash, description); err != nil {
+		return failedResult(err)
+	}
+	if err := c.recordRestore(hash, paths); err != nil {
+		return failedResult(err)
+	}
+	if err := c.resetPaths(repo, paths); err != nil {
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Restored %s; the changes were saved as stash@{0}", path),
+		Error:   nil,
+	}, nil
+}
+
+// UndoRestore brings back the changes discarded by the last Restore or
+// RestoreFile, staged and unstaged as they were, and drops the stash entry
+// that held them. It is refused if the restored files have changed since.
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) UndoRestore() (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	content, err := os.ReadFile(c.gitFile(restoreRecord))
+	if os.IsNotExist(err) {
+		return failedResult(fmt.Errorf("there is no restore to undo"))
+	} else if err != nil {
+		return failedResult(err)
+	}
+	record := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(record) < 2 {
+		return failedResult(fmt.Errorf("there is no restore to undo"))
+	}
+	hash, paths := plumbing.NewHash(record[0]), record[1:]
+
+	// The changes are only kept while their stash entry is
+	lines, err := c.readStashLog(repo)
+	if err != nil {
+		return failedResult(err)
+	}
+	entry := -1
+	for i, line := range lines {
+		if line.new == hash {
+			entry = len(lines) - 1 - i
+		}
+	}
+	if entry < 0 {
+		os.Remove(c.gitFile(restoreRecord))
+		return failedResult(fmt.Errorf("the stash entry of the last restore was dropped"))
+	}
+
+	changed, err := changedPaths(repo, "")
+	if err != nil {
+		return failedResult(err)
+	}
+	for _, path := range paths {
+		if slices.Contains(changed, path) {
+			return failedResult(fmt.Errorf("%s has changed since the restore; commit or restore it first", path))
+		}
+	}
+
+	stash, err := repo.CommitObject(hash)
+	if err != nil {
+		return failedResult(err)
+	}
+	worktreeFiles, err := treeFiles(stash)
+	if err != nil {
+		return failedResult(err)
+	}
+	indexFiles := worktreeFiles
+	if stash.NumParents() > 1 {
+		indexCommit, err := stash.Parent(1)
+		if err != nil {
+			return failedResult(err)
+		}
+		if indexFiles, err = treeFiles(indexCommit); err != nil {
+			return failedResult(err)
+		}
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return failedResult(err)
+	}
+	for _, path := range paths {
+		if err := c.takeFile(repo, idx, path, worktreeFiles[path]); err != nil {
+			return failedResult(err)
+		}
+		setStages(idx, path, map[index.Stage]treeEntry{0: indexFiles[path]})
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return failedResult(err)
+	}
+	if err := c.dropStash(repo, entry); err != nil {
+		return failedResult(err)
+	}
+	os.Remove(c.gitFile(restoreRecord))
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Brought back the changes to %d file(s)", len(paths)),
+		Error:   nil,
+	}, nil
+}
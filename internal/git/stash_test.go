@@ -0,0 +1,260 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stashCount returns the number of stash entries of client
+func stashCount(t *testing.T, client *Client) int {
+	t.Helper()
+	entries, err := client.StashList()
+	if err != nil {
+		t.Fatalf("StashList failed: %v", err)
+	}
+	return len(entries)
+}
+
+func TestStashSaveAndPop(t *testing.T) {
+	client, repo, changed := createChangesRepo(t)
+	root := client.workDir
+	commitFile(t, repo, "staged.txt", "old\n")
+	writeFile(t, root, "staged.txt", "staged\n")
+	worktree, _ := repo.Worktree()
+	worktree.Add("staged.txt")
+	writeFile(t, root, "untracked.txt", "keep me\n")
+
+	result, err := client.StashSave("")
+	if err != nil || !result.Success {
+		t.Fatalf("StashSave failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "WIP on master: ") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	if readWorktree(t, client, "code.txt") != twoHunkText || readWorktree(t, client, "staged.txt") != "old\n" {
+		t.Error("expected the worktree to be restored to HEAD")
+	}
+	if readWorktree(t, client, "untracked.txt") != "keep me\n" {
+		t.Error("untracked files should be left alone")
+	}
+
+	entries, _ := client.StashList()
+	if len(entries) != 1 || entries[0].Name() != "stash@{0}" || !strings.HasPrefix(entries[0].Message, "WIP on master") {
+		t.Fatalf("unexpected stash %+v", entries)
+	}
+	if entries[0].When.IsZero() {
+		t.Error("expected the entry to have a time")
+	}
+
+	result, err = client.StashPop(0)
+	if err != nil || !result.Success {
+		t.Fatalf("StashPop failed: %v", err)
+	}
+	if readWorktree(t, client, "code.txt") != changed || readWorktree(t, client, "staged.txt") != "staged\n" {
+		t.Error("expected the changes to be back")
+	}
+	if status := statusOf(t, client, "code.txt"); status.Code() != " M" {
+		t.Errorf("expected the change to be unstaged, got %q", status.Code())
+	}
+	if stashCount(t, client) != 0 {
+		t.Error("expected pop to drop the entry")
+	}
+}
+
+func TestStashApplyAfterNewCommit(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	root := client.workDir
+	writeFile(t, root, "code.txt", strings.Replace(twoHunkText, "12\n", "twelve\n", 1))
+	if _, err := client.StashSave("late change"); err != nil {
+		t.Fatalf("StashSave failed: %v", err)
+	}
+	entries, _ := client.StashList()
+	if entries[0].Message != "On master: late change" {
+		t.Errorf("unexpected message %q", entries[0].Message)
+	}
+
+	// The stash merges with a commit that changed other lines
+	commitFile(t, repo, "code.txt", strings.Replace(twoHunkText, "1\n", "one\n", 1))
+	if result, err := client.StashApply(0); err != nil || !result.Success {
+		t.Fatalf("StashApply failed: %v", err)
+	}
+	want := strings.Replace(strings.Replace(twoHunkText, "1\n", "one\n", 1), "12\n", "twelve\n", 1)
+	if got := readWorktree(t, client, "code.txt"); got != want {
+		t.Errorf("unexpected merge %q", got)
+	}
+	if stashCount(t, client) != 1 {
+		t.Error("apply should keep the entry")
+	}
+
+	// Applying again needs a clean worktree
+	if _, err := client.StashApply(0); err == nil {
+		t.Error("expected apply over uncommitted changes to be refused")
+	}
+}
+
+func TestStashApplyConflict(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	if _, err := client.StashSave(""); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "code.txt", strings.Replace(twoHunkText, "1\n", "uno\n", 1))
+
+	if _, err := client.StashPop(0); !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if stashCount(t, client) != 1 {
+		t.Error("a conflicted pop should keep the entry")
+	}
+	state, _ := client.MergeState()
+	if state == nil || len(state.Conflicts) != 1 {
+		t.Errorf("expected code.txt to be conflicted, got %+v", state)
+	}
+}
+
+func TestStashDrop(t *testing.T) {
+	client, _, _ := createChangesRepo(t)
+	root := client.workDir
+	client.StashSave("first")
+	writeFile(t, root, "code.txt", "second\n")
+	client.StashSave("second")
+	writeFile(t, root, "code.txt", "third\n")
+	client.StashSave("third")
+
+	if result, err := client.StashDrop(1); err != nil || !strings.HasPrefix(result.Message, "Dropped stash@{1}") {
+		t.Fatalf("StashDrop failed: %v", err)
+	}
+	entries, _ := client.StashList()
+	if len(entries) != 2 || entries[0].Message != "On master: third" || entries[1].Message != "On master: first" {
+		t.Fatalf("unexpected stash %+v", entries)
+	}
+	if _, err := client.StashDrop(5); err == nil {
+		t.Error("expected an error for a missing entry")
+	}
+
+	client.StashDrop(0)
+	client.StashDrop(0)
+	if stashCount(t, client) != 0 {
+		t.Error("expected the stash to be empty")
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git", "refs", "stash")); !os.IsNotExist(err) {
+		t.Error("expected refs/stash to be removed")
+	}
+}
+
+func TestStashSave_NothingToSave(t *testing.T) {
+	client, repo, changed := createChangesRepo(t)
+	commitFile(t, repo, "code.txt", changed)
+	if _, err := client.StashSave(""); err == nil || !strings.Contains(err.Error(), "no local changes") {
+		t.Errorf("expected nothing to save, got %v", err)
+	}
+}
+
+func TestRestore_SavesStashAndUndo(t *testing.T) {
+	client, repo, changed := createChangesRepo(t)
+	root := client.workDir
+	commitFile(t, repo, "other.txt", "a\n")
+	writeFile(t, root, "other.txt", "b\n")
+	worktree, _ := repo.Worktree()
+	worktree.Add("other.txt")
+
+	result, err := client.Restore()
+	if err != nil || !result.Success {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Message != "Restored 2 file(s); the changes were saved as stash@{0}" {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	if readWorktree(t, client, "code.txt") != twoHunkText || readWorktree(t, client, "other.txt") != "a\n" {
+		t.Error("expected the files to be restored")
+	}
+	entries, _ := client.StashList()
+	if len(entries) != 1 || entries[0].Message != "On master: restore all files" {
+		t.Fatalf("unexpected stash %+v", entries)
+	}
+
+	if result, err := client.UndoRestore(); err != nil || !result.Success {
+		t.Fatalf("UndoRestore failed: %v", err)
+	}
+	if readWorktree(t, client, "code.txt") != changed || readWorktree(t, client, "other.txt") != "b\n" {
+		t.Error("expected the changes to be back")
+	}
+	if status := statusOf(t, client, "other.txt"); status.Code() != "M " {
+		t.Errorf("expected other.txt to be staged again, got %q", status.Code())
+	}
+	if stashCount(t, client) != 0 {
+		t.Error("expected the restore entry to be dropped")
+	}
+	if _, err := client.UndoRestore(); err == nil {
+		t.Error("expected nothing left to undo")
+	}
+
+	if result, _ := client.Restore(); result.Message != "Restored 2 file(s); the changes were saved as stash@{0}" {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	if result, _ := client.Restore(); result.Message != "Nothing to restore" {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+}
+
+func TestRestoreFile(t *testing.T) {
+	client, repo, changed := createChangesRepo(t)
+	root := client.workDir
+	writeFile(t, root, "new.txt", "new\n")
+	worktree, _ := repo.Worktree()
+	worktree.Add("new.txt")
+
+	result, err := client.RestoreFile("code.txt")
+	if err != nil || result.Message != "Restored code.txt; the changes were saved as stash@{0}" {
+		t.Fatalf("RestoreFile failed: %v %+v", err, result)
+	}
+	if readWorktree(t, client, "code.txt") != twoHunkText {
+		t.Error("expected code.txt to be restored")
+	}
+	if statusOf(t, client, "new.txt").Code() != "A " {
+		t.Error("other files should keep their changes")
+	}
+
+	// A new file is removed
+	if _, err := client.RestoreFile("new.txt"); err != nil {
+		t.Fatalf("RestoreFile failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "new.txt")); !os.IsNotExist(err) {
+		t.Error("expected the new file to be removed")
+	}
+
+	// Only the last restore is undone; the earlier one stays in the stash
+	if _, err := client.UndoRestore(); err != nil {
+		t.Fatalf("UndoRestore failed: %v", err)
+	}
+	if statusOf(t, client, "new.txt").Code() != "A " {
+		t.Error("expected new.txt to be back in the index")
+	}
+	entries, _ := client.StashList()
+	if len(entries) != 1 || entries[0].Message != "On master: restore code.txt" {
+		t.Fatalf("unexpected stash %+v", entries)
+	}
+
+	if _, err := client.RestoreFile("code.txt"); err == nil {
+		t.Error("expected an error for a file without changes")
+	}
+	commitFile(t, repo, "new.txt", "new\n")
+	if _, err := client.StashPop(0); err != nil {
+		t.Fatalf("StashPop failed: %v", err)
+	}
+	if readWorktree(t, client, "code.txt") != changed {
+		t.Error("expected the stashed change to come back")
+	}
+}
+
+func TestUndoRestore_RefusedAfterEdits(t *testing.T) {
+	client, _, _ := createChangesRepo(t)
+	client.Restore()
+	writeFile(t, client.workDir, "code.txt", "edited again\n")
+
+	if _, err := client.UndoRestore(); err == nil || !strings.Contains(err.Error(), "changed since the restore") {
+		t.Errorf("expected the undo to be refused, got %v", err)
+	}
+}
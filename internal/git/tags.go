@@ -0,0 +1,333 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// TagInfo describes a tag and the commit it points at.
+type TagInfo struct {
+	Name      string    // Tag name, e.g. "v1.2.0"
+	Hash      string    // Full hash of the tagged commit
+	Annotated bool      // Whether this is an annotated tag object rather than a lightweight tag
+	Message   string    // Message of an annotated tag, empty for lightweight tags
+	Date      time.Time // Tagging date of an annotated tag, or the commit date of a lightweight one
+}
+
+// ShortHash returns the abbreviated hash of the tagged commit.
+func (t TagInfo) ShortHash() string {
+	if len(t.Hash) > 7 {
+		return t.Hash[:7]
+	}
+	return t.Hash
+}
+
+// validateTagName checks that name can be used as a tag name.
+func validateTagName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	if err := plumbing.NewTagReferenceName(name).Validate(); err != nil {
+		return fmt.Errorf("invalid tag name %q", name)
+	}
+	return nil
+}
+
+// Tags lists the tags of the repository, newest first, with ties broken by
+// name, highest first. Tags that do not point at a commit are skipped.
+//
+// Returns:
+//   - []TagInfo: One entry per tag
+//   - error: Any error that occurred while reading the repository
+func (c *Client) Tags() ([]TagInfo, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	tags, err := readTags(repo)
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+	return tags, nil
+}
+
+// readTags lists the tags of repo that point at commits, newest first.
+func readTags(repo *git.Repository) ([]TagInfo, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []TagInfo
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		info := TagInfo{Name: ref.Name().Short()}
+		if tag, err := repo.TagObject(ref.Hash()); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return nil // tags of trees or blobs have no place in releases
+			}
+			info.Hash = commit.Hash.String()
+			info.Annotated = true
+			info.Message = strings.TrimSpace(tag.Message)
+			info.Date = tag.Tagger.When
+		} else {
+			commit, err := repo.CommitObject(ref.Hash())
+			if err != nil {
+				return nil
+			}
+			info.Hash = commit.Hash.String()
+			info.Date = commit.Committer.When
+		}
+		tags = append(tags, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if !tags[i].Date.Equal(tags[j].Date) {
+			return tags[i].Date.After(tags[j].Date)
+		}
+		return tags[i].Name > tags[j].Name
+	})
+	return tags, nil
+}
+
+// CreateTag tags the HEAD commit. With an empty message the tag is
+// lightweight; otherwise an annotated tag object carrying the message is
+// created.
+//
+// Parameters:
+//   - name: The name of the new tag, e.g. "v1.2.0"
+//   - message: The annotation, or "" for a lightweight tag
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) CreateTag(name, message string) (*OperationResult, error) {
+	if err := validateTagName(name); err != nil {
+		return failedResult(err)
+	}
+
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	if _, err := repo.Tag(name); err == nil {
+		return failedResult(fmt.Errorf("tag %q already exists", name))
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return failedResult(fmt.Errorf("cannot create a tag before the first commit: %w", err))
+	}
+
+	var opts *git.CreateTagOptions
+	kind := "lightweight tag"
+	if message = strings.TrimSpace(message); message != "" {
+		kind = "annotated tag"
+		opts = &git.CreateTagOptions{
+			Tagger: &object.Signature{
+				Name:  "Terminal Intelligence User",
+				Email: "user@terminal-intelligence.local",
+				When:  time.Now(),
+			},
+			Message: message + "\n",
+		}
+	}
+	if _, err := repo.CreateTag(name, head.Hash(), opts); err != nil {
+		return failedResult(err)
+	}
+
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Created %s %s at %s", kind, name, head.Hash().String()[:7]),
+		Error:   nil,
+	}, nil
+}
+
+// DeleteTag deletes a local tag. A tag already pushed stays on the remote.
+//
+// Parameters:
+//   - name: The tag to delete
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) DeleteTag(name string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+	if err := repo.DeleteTag(name); err != nil {
+		if errors.Is(err, git.ErrTagNotFound) {
+			return failedResult(fmt.Errorf("tag %q does not exist", name))
+		}
+		return failedResult(err)
+	}
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Deleted tag %s", name),
+		Error:   nil,
+	}, nil
+}
+
+// PushTags pushes one tag, or all tags when name is empty, to origin.
+// Authentication works as for Push.
+//
+// Parameters:
+//   - name: The tag to push, or "" for all tags
+//   - username: The username for authentication (or any non-empty string for GitHub PATs)
+//   - password: The password, GitHub Personal Access Token or SSH key passphrase
+//
+// Returns:
+//   - *OperationResult: Contains success status, message, and any error
+//   - error: Any error that occurred during the operation
+func (c *Client) PushTags(name, username, password string) (*OperationResult, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return failedResult(err)
+	}
+
+	spec := config.RefSpec("refs/tags/*:refs/tags/*")
+	what := "all tags"
+	if name != "" {
+		if _, err := repo.Tag(name); err != nil {
+			return failedResult(fmt.Errorf("tag %q does not exist", name))
+		}
+		spec = config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", name, name))
+		what = "tag " + name
+	}
+
+	auth, err := c.authFor(originURL(repo), username, password)
+	if err != nil {
+		return failedResult(err)
+	}
+	err = repo.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{spec},
+		Auth:     auth.method,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return &OperationResult{
+			Success: true,
+			Message: fmt.Sprintf("The remote already has %s", what),
+			Error:   nil,
+		}, nil
+	}
+	c.remember(c.workDir, auth, err)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	return &OperationResult{
+		Success: true,
+		Message: fmt.Sprintf("Pushed %s to origin", what),
+		Error:   nil,
+	}, nil
+}
+
+// ReleaseCommits returns the commits of a release: those reachable from tag,
+// or from HEAD when tag is empty, but not from the nearest older tag. The
+// commits are newest first. Their Files are left empty: a first release
+// spans the whole history, and diffing every commit's tree would be slow.
+//
+// Parameters:
+//   - tag: The tag of the release, or "" for the unreleased commits on HEAD
+//
+// Returns:
+//   - string: The previous tag, or "" if the release is the first
+//   - []CommitInfo: The commits since the previous tag
+//   - error: Any error that occurred while reading the history
+func (c *Client) ReleaseCommits(tag string) (string, []CommitInfo, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", nil, categorizeError(err)
+	}
+
+	var target plumbing.Hash
+	if tag == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return "", nil, categorizeError(fmt.Errorf("there are no commits yet: %w", err))
+		}
+		target = head.Hash()
+	} else {
+		hash, err := repo.ResolveRevision(plumbing.Revision("refs/tags/" + tag + "^{commit}"))
+		if err != nil {
+			return "", nil, categorizeError(fmt.Errorf("tag %q does not exist", tag))
+		}
+		target = *hash
+	}
+
+	// The newest tag of each commit, other than the release tag itself
+	tags, err := readTags(repo)
+	if err != nil {
+		return "", nil, categorizeError(err)
+	}
+	tagged := make(map[plumbing.Hash]string)
+	for _, t := range tags {
+		hash := plumbing.NewHash(t.Hash)
+		if _, ok := tagged[hash]; !ok && t.Name != tag {
+			tagged[hash] = t.Name
+		}
+	}
+
+	// The first tagged ancestor in history order is the previous release.
+	// Other tags on the tagged commit are aliases of the release, but a tag
+	// on HEAD means nothing is unreleased.
+	previous := ""
+	var previousHash plumbing.Hash
+	iter, err := repo.Log(&git.LogOptions{From: target, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return "", nil, categorizeError(err)
+	}
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if name, ok := tagged[commit.Hash]; ok && (tag == "" || commit.Hash != target) {
+			previous, previousHash = name, commit.Hash
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, categorizeError(err)
+	}
+
+	released := map[plumbing.Hash]bool{}
+	if previous != "" {
+		if released, err = reachableCommits(repo, previousHash); err != nil {
+			return "", nil, categorizeError(err)
+		}
+	}
+
+	iter, err = repo.Log(&git.LogOptions{From: target, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return "", nil, categorizeError(err)
+	}
+	var commits []CommitInfo
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if released[commit.Hash] {
+			return nil
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author.Name,
+			Email:   commit.Author.Email,
+			Date:    commit.Author.When,
+			Message: commit.Message,
+		})
+		return nil
+	})
+	if err != nil {
+		return "", nil, categorizeError(err)
+	}
+	return previous, commits, nil
+}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// tagNames returns the names of the tags of client, newest first
+func tagNames(t *testing.T, client *Client) []string {
+	t.Helper()
+	tags, err := client.Tags()
+	if err != nil {
+		t.Fatalf("Tags failed: %v", err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestCreateTag_LightweightAndAnnotated(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	head, _ := repo.Head()
+
+	result, err := client.CreateTag("v0.1.0", "")
+	if err != nil || !result.Success {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "lightweight tag v0.1.0") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	ref, err := repo.Tag("v0.1.0")
+	if err != nil || ref.Hash() != head.Hash() {
+		t.Fatalf("expected a lightweight tag at HEAD, got %v (%v)", ref, err)
+	}
+
+	result, err = client.CreateTag("v0.2.0", "  First beta  ")
+	if err != nil || !result.Success {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	ref, _ = repo.Tag("v0.2.0")
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("expected an annotated tag: %v", err)
+	}
+	if tag.Message != "First beta\n" || tag.Target != head.Hash() {
+		t.Errorf("unexpected tag %q -> %s", tag.Message, tag.Target)
+	}
+
+	tags, _ := client.Tags()
+	if len(tags) != 2 {
+		t.Fatalf("expected two tags, got %+v", tags)
+	}
+	for _, info := range tags {
+		if info.Hash != head.Hash().String() {
+			t.Errorf("%s should point at HEAD, got %s", info.Name, info.Hash)
+		}
+		if info.Annotated != (info.Name == "v0.2.0") {
+			t.Errorf("%s: unexpected Annotated %v", info.Name, info.Annotated)
+		}
+	}
+}
+
+func TestCreateTag_Rejected(t *testing.T) {
+	client, _, _ := createChangesRepo(t)
+	client.CreateTag("v1", "")
+
+	for _, name := range []string{"", "  ", "bad..name", "v1"} {
+		if result, err := client.CreateTag(name, ""); err == nil || result.Success {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+
+	empty := NewClient(t.TempDir())
+	git.PlainInit(empty.workDir, false)
+	if _, err := empty.CreateTag("v1", ""); err == nil {
+		t.Error("expected tagging an empty repository to fail")
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	client, _, _ := createChangesRepo(t)
+	client.CreateTag("v1", "release")
+
+	if result, err := client.DeleteTag("v1"); err != nil || !result.Success {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+	if names := tagNames(t, client); len(names) != 0 {
+		t.Errorf("expected no tags, got %v", names)
+	}
+	if _, err := client.DeleteTag("v1"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a missing tag error, got %v", err)
+	}
+}
+
+func TestTags_NewestFirst(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	first, _ := repo.Head()
+	repo.CreateTag("v1", first.Hash(), nil)
+	commitFile(t, repo, "b.txt", "b\n")
+	client.CreateTag("v2", "second")
+
+	names := tagNames(t, client)
+	if strings.Join(names, ",") != "v2,v1" {
+		t.Errorf("expected v2,v1, got %v", names)
+	}
+}
+
+func TestPushTags(t *testing.T) {
+	client, _, bareDir := createRepoWithOrigin(t)
+	client.CreateTag("v1", "")
+	client.CreateTag("v2", "annotated")
+
+	result, err := client.PushTags("v1", "", "")
+	if err != nil || !result.Success {
+		t.Fatalf("PushTags failed: %v", err)
+	}
+	origin, err := git.PlainOpen(bareDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := origin.Tag("v1"); err != nil {
+		t.Errorf("expected v1 on origin: %v", err)
+	}
+	if _, err := origin.Tag("v2"); err == nil {
+		t.Error("only v1 should have been pushed")
+	}
+
+	if result, err := client.PushTags("", "", ""); err != nil || !result.Success {
+		t.Fatalf("PushTags of all tags failed: %v", err)
+	}
+	if _, err := origin.Tag("v2"); err != nil {
+		t.Errorf("expected v2 on origin: %v", err)
+	}
+
+	result, err = client.PushTags("", "", "")
+	if err != nil || !strings.Contains(result.Message, "already has") {
+		t.Errorf("expected an up-to-date push, got %+v (%v)", result, err)
+	}
+	if _, err := client.PushTags("missing", "", ""); err == nil {
+		t.Error("expected pushing a missing tag to fail")
+	}
+}
+
+// commitSubjects returns the subjects of commits
+func commitSubjects(commits []CommitInfo) string {
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, c.Subject())
+	}
+	return strings.Join(subjects, ",")
+}
+
+func TestReleaseCommits(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+
+	// Without tags every commit is part of the first release
+	previous, commits, err := client.ReleaseCommits("")
+	if err != nil || previous != "" || commitSubjects(commits) != "Update code.txt" {
+		t.Fatalf("unexpected first release %q %q (%v)", previous, commitSubjects(commits), err)
+	}
+
+	client.CreateTag("v1", "first")
+	previous, commits, err = client.ReleaseCommits("")
+	if err != nil || previous != "v1" || len(commits) != 0 {
+		t.Errorf("expected nothing unreleased on a tagged HEAD, got %q %q (%v)", previous, commitSubjects(commits), err)
+	}
+	commitFile(t, repo, "a.txt", "a\n")
+	commitFile(t, repo, "b.txt", "b\n")
+
+	previous, commits, err = client.ReleaseCommits("")
+	if err != nil {
+		t.Fatalf("ReleaseCommits failed: %v", err)
+	}
+	if previous != "v1" || commitSubjects(commits) != "Update b.txt,Update a.txt" {
+		t.Errorf("unexpected unreleased commits since %q: %q", previous, commitSubjects(commits))
+	}
+
+	// Once tagged, the release still reaches back to v1 and not to itself
+	client.CreateTag("v2", "")
+	commitFile(t, repo, "c.txt", "c\n")
+	previous, commits, err = client.ReleaseCommits("v2")
+	if err != nil {
+		t.Fatalf("ReleaseCommits failed: %v", err)
+	}
+	if previous != "v1" || commitSubjects(commits) != "Update b.txt,Update a.txt" {
+		t.Errorf("unexpected v2 commits since %q: %q", previous, commitSubjects(commits))
+	}
+
+	previous, commits, _ = client.ReleaseCommits("")
+	if previous != "v2" || commitSubjects(commits) != "Update c.txt" {
+		t.Errorf("unexpected unreleased commits since %q: %q", previous, commitSubjects(commits))
+	}
+
+	if _, _, err := client.ReleaseCommits("v9"); err == nil {
+		t.Error("expected a missing tag to fail")
+	}
+}
+
+func TestReleaseCommits_MergedBranch(t *testing.T) {
+	client, repo, _ := createChangesRepo(t)
+	writeFile(t, client.workDir, "code.txt", twoHunkText)
+	client.CreateTag("v1", "")
+	base, _ := repo.Head()
+
+	// A feature branch from v1 merged back after another commit on master
+	worktree, _ := repo.Worktree()
+	worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true, Hash: base.Hash()})
+	commitFile(t, repo, "feature.txt", "f\n")
+	worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")})
+	commitFile(t, repo, "main.txt", "m\n")
+	if _, err := client.Merge("feature"); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	previous, commits, err := client.ReleaseCommits("")
+	if err != nil || previous != "v1" {
+		t.Fatalf("unexpected previous tag %q (%v)", previous, err)
+	}
+	subjects := commitSubjects(commits)
+	for _, want := range []string{"Update feature.txt", "Update main.txt", "Merge"} {
+		if !strings.Contains(subjects, want) {
+			t.Errorf("expected %q among %q", want, subjects)
+		}
+	}
+	if strings.Contains(subjects, "code.txt") {
+		t.Errorf("v1's commit should be excluded: %q", subjects)
+	}
+}
@@ -0,0 +1,191 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Client talks to one running language server.
+type Client struct {
+	conn *conn
+}
+
+// NewClient connects to a language server over rw. notify receives the
+// server's notifications on the connection's read goroutine, so it must
+// not call back into the client. Requests from the server are answered so
+// that servers which ask for configuration or progress tokens keep
+// working.
+func NewClient(rw io.ReadWriteCloser, notify func(method string, params json.RawMessage)) *Client {
+	return &Client{conn: newConn(rw, handlers{request: serverRequest, notification: notify})}
+}
+
+// serverRequest answers the requests servers send to the client.
+func serverRequest(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "workspace/configuration":
+		// No settings: one null per requested item
+		var p struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(params, &p)
+		return make([]any, len(p.Items)), nil
+	case "workspace/applyEdit":
+		// Edits are only applied when the user asks for them
+		return map[string]bool{"applied": false}, nil
+	case "workspace/workspaceFolders", "client/registerCapability", "client/unregisterCapability",
+		"window/workDoneProgress/create", "window/showMessageRequest":
+		return nil, nil
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: "method not found: " + method}
+}
+
+// textDocument identifies a document in requests.
+type textDocument struct {
+	URI string `json:"uri"`
+}
+
+// positionParams are the parameters of requests about a position.
+type positionParams struct {
+	TextDocument textDocument `json:"textDocument"`
+	Position     Position     `json:"position"`
+}
+
+// Initialize performs the initialize handshake for the workspace at root.
+func (c *Client) Initialize(ctx context.Context, root string) error {
+	rootURI := PathToURI(root)
+	params := map[string]any{
+		"processId": os.Getpid(),
+		"rootUri":   rootURI,
+		"clientInfo": map[string]string{
+			"name": "terminal-intelligence",
+		},
+		"workspaceFolders": []map[string]string{
+			{"uri": rootURI, "name": filepath.Base(root)},
+		},
+		"capabilities": map[string]any{
+			"workspace": map[string]any{
+				"configuration":    true,
+				"workspaceFolders": true,
+				"workspaceEdit":    map[string]bool{"documentChanges": true},
+			},
+			"textDocument": map[string]any{
+				"synchronization":    map[string]bool{"dynamicRegistration": false},
+				"publishDiagnostics": map[string]bool{"relatedInformation": false},
+				"hover": map[string]any{
+					"contentFormat": []string{"plaintext", "markdown"},
+				},
+				"definition": map[string]bool{"linkSupport": true},
+				"completion": map[string]any{
+					"completionItem": map[string]bool{"snippetSupport": false},
+				},
+				"rename": map[string]bool{"prepareSupport": false},
+			},
+		},
+	}
+	if err := c.conn.Call(ctx, "initialize", params, nil); err != nil {
+		return err
+	}
+	return c.conn.Notify("initialized", struct{}{})
+}
+
+// DidOpen tells the server that the document at path is open with text.
+func (c *Client) DidOpen(path, languageID string, version int, text string) error {
+	return c.conn.Notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{
+			"uri":        PathToURI(path),
+			"languageId": languageID,
+			"version":    version,
+			"text":       text,
+		},
+	})
+}
+
+// DidChange sends the full new text of the document at path.
+func (c *Client) DidChange(path string, version int, text string) error {
+	return c.conn.Notify("textDocument/didChange", map[string]any{
+		"textDocument": map[string]any{
+			"uri":     PathToURI(path),
+			"version": version,
+		},
+		"contentChanges": []map[string]string{{"text": text}},
+	})
+}
+
+// DidClose tells the server that the document at path was closed.
+func (c *Client) DidClose(path string) error {
+	return c.conn.Notify("textDocument/didClose", map[string]any{
+		"textDocument": textDocument{URI: PathToURI(path)},
+	})
+}
+
+// Hover returns the hover text for pos in the document at path, or "" if
+// there is none.
+func (c *Client) Hover(ctx context.Context, path string, pos Position) (string, error) {
+	var result *hoverResult
+	if err := c.conn.Call(ctx, "textDocument/hover", positionParams{textDocument{PathToURI(path)}, pos}, &result); err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	return result.text(), nil
+}
+
+// Definition returns where the symbol at pos in the document at path is
+// defined.
+func (c *Client) Definition(ctx context.Context, path string, pos Position) ([]Location, error) {
+	var result locations
+	if err := c.conn.Call(ctx, "textDocument/definition", positionParams{textDocument{PathToURI(path)}, pos}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Completion returns the completions for pos in the document at path.
+func (c *Client) Completion(ctx context.Context, path string, pos Position) ([]CompletionItem, error) {
+	var result *completionList
+	if err := c.conn.Call(ctx, "textDocument/completion", positionParams{textDocument{PathToURI(path)}, pos}, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return result.Items, nil
+}
+
+// Rename returns the edits that rename the symbol at pos in the document at
+// path to newName.
+func (c *Client) Rename(ctx context.Context, path string, pos Position, newName string) (*WorkspaceEdit, error) {
+	params := map[string]any{
+		"textDocument": textDocument{URI: PathToURI(path)},
+		"position":     pos,
+		"newName":      newName,
+	}
+	var result *WorkspaceEdit
+	if err := c.conn.Call(ctx, "textDocument/rename", params, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &WorkspaceEdit{}
+	}
+	return result, nil
+}
+
+// Shutdown asks the server to shut down and exit, then closes the
+// connection.
+func (c *Client) Shutdown(ctx context.Context) error {
+	err := c.conn.Call(ctx, "shutdown", nil, nil)
+	if err == nil {
+		err = c.conn.Notify("exit", nil)
+	}
+	c.conn.Close()
+	return err
+}
+
+// Done is closed when the connection to the server has closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.conn.Done()
+}
@@ -0,0 +1,269 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// errClosed is returned for calls on a closed connection.
+var errClosed = errors.New("language server connection closed")
+
+// ResponseError is an error a server returned for a request.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// codeMethodNotFound is the JSON-RPC error code for unknown methods.
+const codeMethodNotFound = -32601
+
+// message is any JSON-RPC message: a request has a method and an ID, a
+// notification only a method and a response only an ID.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// handlers receive the requests and notifications of the other side of a
+// conn. A nil handler ignores notifications and answers requests with a
+// "method not found" error.
+type handlers struct {
+	request      func(method string, params json.RawMessage) (any, error)
+	notification func(method string, params json.RawMessage)
+}
+
+// conn is a JSON-RPC 2.0 connection with the Content-Length framing of the
+// Language Server Protocol. Messages are read on a goroutine started by
+// newConn; handlers run on it, in the order the messages arrive.
+type conn struct {
+	rw       io.ReadWriteCloser
+	handlers handlers
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan *message
+	err     error // Why the connection closed, once it has
+
+	done chan struct{}
+}
+
+// newConn starts reading messages from rw.
+func newConn(rw io.ReadWriteCloser, h handlers) *conn {
+	c := &conn{
+		rw:       rw,
+		handlers: h,
+		pending:  make(map[int]chan *message),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// Call sends a request and decodes its result into result, which may be
+// nil. It returns when the response arrives, ctx is done or the
+// connection closes; a cancelled request is also cancelled on the server.
+func (c *conn) Call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	rawID := json.RawMessage(strconv.Itoa(id))
+	if err := c.send(message{ID: &rawID, Method: method}, params); err != nil {
+		c.forget(id)
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("invalid %s response: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		c.forget(id)
+		_ = c.Notify("$/cancelRequest", map[string]int{"id": id})
+		return ctx.Err()
+	case <-c.done:
+		return c.closeErr()
+	}
+}
+
+// Notify sends a notification.
+func (c *conn) Notify(method string, params any) error {
+	return c.send(message{Method: method}, params)
+}
+
+// reply answers the request with the given ID.
+func (c *conn) reply(id *json.RawMessage, result any, err error) error {
+	msg := message{ID: id}
+	if err != nil {
+		var respErr *ResponseError
+		if !errors.As(err, &respErr) {
+			respErr = &ResponseError{Code: -32603, Message: err.Error()}
+		}
+		msg.Error = respErr
+		return c.send(msg, nil)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	// A successful response must have a result, even a null one
+	msg.Result = data
+	return c.send(msg, nil)
+}
+
+// send writes msg with params as its parameters.
+func (c *conn) send(msg message, params any) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = data
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.rw, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.rw.Write(body)
+	return err
+}
+
+// forget drops the pending request with the given ID.
+func (c *conn) forget(id int) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// closeErr returns why the connection closed.
+func (c *conn) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection. Pending and later calls fail.
+func (c *conn) Close() error {
+	err := c.rw.Close()
+	<-c.done
+	return err
+}
+
+// Done is closed when the connection has closed.
+func (c *conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// readLoop reads and dispatches messages until the connection fails.
+func (c *conn) readLoop() {
+	r := bufio.NewReader(c.rw)
+	var err error
+	for {
+		var msg *message
+		if msg, err = readMessage(r); err != nil {
+			break
+		}
+		c.dispatch(msg)
+	}
+	c.mu.Lock()
+	if err == io.EOF || errors.Is(err, io.ErrClosedPipe) {
+		c.err = errClosed
+	} else {
+		c.err = fmt.Errorf("%w: %v", errClosed, err)
+	}
+	c.pending = make(map[int]chan *message)
+	c.mu.Unlock()
+	c.rw.Close()
+	close(c.done)
+}
+
+// dispatch hands msg to the handlers or to the call waiting for it.
+func (c *conn) dispatch(msg *message) {
+	switch {
+	case msg.Method != "" && msg.ID != nil:
+		var result any
+		err := error(&ResponseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method})
+		if c.handlers.request != nil {
+			result, err = c.handlers.request(msg.Method, msg.Params)
+		}
+		// Reply from another goroutine: a server that writes before it
+		// reads would wait for this loop while it waits for the server
+		go func() { _ = c.reply(msg.ID, result, err) }()
+	case msg.Method != "":
+		if c.handlers.notification != nil {
+			c.handlers.notification(msg.Method, msg.Params)
+		}
+	case msg.ID != nil:
+		id, err := strconv.Atoi(string(*msg.ID))
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+}
+
+// readMessage reads one framed message.
+func readMessage(r *bufio.Reader) (*message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return &msg, nil
+}
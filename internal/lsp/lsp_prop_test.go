@@ -0,0 +1,48 @@
+package lsp
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"pgregory.net/rapid"
+)
+
+// Property: converting a byte column at a rune boundary to UTF-16 and back
+// gives the same column.
+func TestProperty_ColumnsRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		line := rapid.StringN(0, 20, -1).Draw(t, "line")
+		if !utf8.ValidString(line) {
+			t.Skip("invalid UTF-8")
+		}
+		var boundaries []int
+		for i := range line {
+			boundaries = append(boundaries, i)
+		}
+		boundaries = append(boundaries, len(line))
+		col := rapid.SampledFrom(boundaries).Draw(t, "col")
+		if got := ByteColumn(line, UTF16Column(line, col)); got != col {
+			t.Fatalf("round trip of %d in %q gave %d", col, line, got)
+		}
+	})
+}
+
+// Property: applying one edit to single-line text replaces exactly its
+// range.
+func TestProperty_ApplyEditSplices(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		text := rapid.StringMatching(`[a-z ]{0,30}`).Draw(t, "text")
+		start := rapid.IntRange(0, len(text)).Draw(t, "start")
+		end := rapid.IntRange(start, len(text)).Draw(t, "end")
+		newText := rapid.StringMatching(`[A-Z\n]{0,5}`).Draw(t, "new")
+
+		edit := TextEdit{Range: Range{Start: Position{0, start}, End: Position{0, end}}, NewText: newText}
+		got, err := ApplyEdits(text, []TextEdit{edit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := text[:start] + newText + text[end:]; got != want {
+			t.Fatalf("ApplyEdits() = %q, want %q", got, want)
+		}
+	})
+}
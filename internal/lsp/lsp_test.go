@@ -0,0 +1,514 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a language server on the other end of a pipe. It records
+// the notifications it receives and answers requests with canned results.
+type fakeServer struct {
+	conn *conn
+
+	mu            sync.Mutex
+	notifications []string          // Methods, in arrival order
+	texts         map[string]string // Document text by URI
+	versions      map[string]int
+	positions     []Position // Positions of the requests, in order
+	results       map[string]any
+}
+
+// newFakeServer starts a fake server and returns the client end of its
+// pipe.
+func newFakeServer(t *testing.T, results map[string]any) (*fakeServer, io.ReadWriteCloser) {
+	t.Helper()
+	clientEnd, serverEnd := net.Pipe()
+	s := &fakeServer{texts: make(map[string]string), versions: make(map[string]int), results: results}
+	s.conn = newConn(serverEnd, handlers{request: s.request, notification: s.notification})
+	t.Cleanup(func() { s.conn.Close() })
+	return s, clientEnd
+}
+
+func (s *fakeServer) request(method string, params json.RawMessage) (any, error) {
+	var p positionParams
+	_ = json.Unmarshal(params, &p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, method)
+	if strings.HasPrefix(method, "textDocument/") {
+		s.positions = append(s.positions, p.Position)
+	}
+	if result, ok := s.results[method]; ok {
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeServer) notification(method string, params json.RawMessage) {
+	var p struct {
+		TextDocument struct {
+			URI     string `json:"uri"`
+			Text    string `json:"text"`
+			Version int    `json:"version"`
+		} `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	_ = json.Unmarshal(params, &p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, method)
+	uri := p.TextDocument.URI
+	switch method {
+	case "textDocument/didOpen":
+		s.texts[uri] = p.TextDocument.Text
+		s.versions[uri] = p.TextDocument.Version
+	case "textDocument/didChange":
+		s.texts[uri] = p.ContentChanges[0].Text
+		s.versions[uri] = p.TextDocument.Version
+	case "textDocument/didClose":
+		delete(s.texts, uri)
+	}
+}
+
+// seen returns the methods the server received.
+func (s *fakeServer) seen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.notifications...)
+}
+
+// document returns the text and version of the document at path.
+func (s *fakeServer) document(path string) (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.texts[PathToURI(path)], s.versions[PathToURI(path)]
+}
+
+// eventually fails the test unless cond becomes true within a second.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+var testServers = []Server{{Name: "fake", Command: "fake-ls", Languages: map[string]string{".go": "go"}}}
+
+// newTestManager returns a manager whose only server is fake, and a
+// channel of its events.
+func newTestManager(t *testing.T, fake io.ReadWriteCloser) (*Manager, chan Event) {
+	t.Helper()
+	events := make(chan Event, 16)
+	root := t.TempDir()
+	lookPath := func(file string) (string, error) {
+		if file == "fake-ls" {
+			return "/usr/bin/fake-ls", nil
+		}
+		return "", errors.New("not found")
+	}
+	start := func(Server, string, string) (io.ReadWriteCloser, error) { return fake, nil }
+	m := newManager(root, testServers, lookPath, start, func(e Event) { events <- e })
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		m.Shutdown(ctx)
+	})
+	return m, events
+}
+
+func TestManager_SyncOpensAndChangesDocuments(t *testing.T) {
+	server, pipe := newFakeServer(t, nil)
+	m, events := newTestManager(t, pipe)
+	path := filepath.Join(m.Root(), "main.go")
+
+	m.Sync(path, "package main\n")
+	eventually(t, "didOpen", func() bool { text, _ := server.document(path); return text == "package main\n" })
+	if e := <-events; e.Server != "fake" || e.Err != nil {
+		t.Errorf("expected a started event, got %+v", e)
+	}
+
+	m.Sync(path, "package main\n\nfunc main() {}\n")
+	m.Sync(path, "package main\n\nfunc main() {}\n")
+	eventually(t, "didChange", func() bool { _, v := server.document(path); return v == 2 })
+	if text, _ := server.document(path); text != "package main\n\nfunc main() {}\n" {
+		t.Errorf("server has %q", text)
+	}
+
+	m.Sync(filepath.Join(m.Root(), "notes.txt"), "not handled")
+	m.Retain(nil)
+	eventually(t, "didClose", func() bool { text, _ := server.document(path); return text == "" })
+
+	want := []string{"initialize", "initialized", "textDocument/didOpen", "textDocument/didChange", "textDocument/didClose"}
+	if got := server.seen(); !reflect.DeepEqual(got, want) {
+		t.Errorf("server received %v, want %v", got, want)
+	}
+}
+
+func TestManager_RetainDoesNotWaitForStartingServer(t *testing.T) {
+	server, pipe := newFakeServer(t, nil)
+	starting, release := make(chan struct{}), make(chan struct{})
+	m := newManager(t.TempDir(), testServers,
+		func(string) (string, error) { return "/usr/bin/fake-ls", nil },
+		func(Server, string, string) (io.ReadWriteCloser, error) {
+			close(starting)
+			<-release
+			return pipe, nil
+		},
+		nil)
+	defer m.Shutdown(context.Background())
+	a, b := filepath.Join(m.Root(), "a.go"), filepath.Join(m.Root(), "b.go")
+
+	m.Sync(a, "package a\n")
+	<-starting
+	retained := make(chan struct{})
+	go func() {
+		m.Retain([]string{b})
+		close(retained)
+	}()
+	select {
+	case <-retained:
+	case <-time.After(time.Second):
+		t.Fatal("Retain waited for the server to start")
+	}
+
+	close(release)
+	m.Sync(b, "package b\n")
+	eventually(t, "b.go opened", func() bool { text, _ := server.document(b); return text == "package b\n" })
+	eventually(t, "a.go closed", func() bool {
+		text, _ := server.document(a)
+		return text == ""
+	})
+	// b.go may be opened before or after a.go is closed
+	got := server.seen()
+	sort.Strings(got[2:])
+	want := []string{"initialize", "initialized", "textDocument/didClose", "textDocument/didOpen", "textDocument/didOpen"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("server received %v, want %v", got, want)
+	}
+}
+
+func TestManager_Diagnostics(t *testing.T) {
+	server, pipe := newFakeServer(t, nil)
+	m, events := newTestManager(t, pipe)
+	path := filepath.Join(m.Root(), "pkg", "a.go")
+	m.Sync(path, "package pkg\n")
+	<-events // started
+
+	diags := []Diagnostic{
+		{Range: Range{Start: Position{Line: 4, Character: 1}}, Severity: SeverityWarning, Message: "unused\nvariable", Source: "vet"},
+		{Range: Range{Start: Position{Line: 2}}, Message: "undefined: x"},
+		{Range: Range{Start: Position{Line: 0}}, Severity: SeverityHint, Message: "could be simpler"},
+	}
+	params := map[string]any{"uri": PathToURI(path), "diagnostics": diags}
+	if err := server.conn.Notify("textDocument/publishDiagnostics", params); err != nil {
+		t.Fatal(err)
+	}
+	e := <-events
+	if e.Path != path || len(e.Diagnostics) != 3 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if got := m.Diagnostics(path); len(got) != 3 {
+		t.Errorf("Diagnostics() = %v", got)
+	}
+
+	want := "pkg/a.go:3:1: error: undefined: x\npkg/a.go:5:2: warning: unused variable (vet)\n"
+	if got := m.DiagnosticsReport(10); got != want {
+		t.Errorf("DiagnosticsReport() = %q, want %q", got, want)
+	}
+	if got := m.DiagnosticsReport(1); !strings.HasSuffix(got, "... and 1 more\n") {
+		t.Errorf("expected the report to be cut, got %q", got)
+	}
+
+	params["diagnostics"] = []Diagnostic{}
+	_ = server.conn.Notify("textDocument/publishDiagnostics", params)
+	if e := <-events; e.Path != path || len(e.Diagnostics) != 0 {
+		t.Errorf("expected cleared diagnostics, got %+v", e)
+	}
+	if got := m.DiagnosticsReport(10); got != "" {
+		t.Errorf("expected an empty report, got %q", got)
+	}
+}
+
+func TestManager_Requests(t *testing.T) {
+	target := PathToURI("/src/lib.go")
+	server, pipe := newFakeServer(t, map[string]any{
+		"textDocument/hover": map[string]any{"contents": map[string]string{"kind": "markdown", "value": "func Println(a ...any)"}},
+		"textDocument/definition": []map[string]any{{
+			"targetUri":            target,
+			"targetRange":          Range{End: Position{Line: 9}},
+			"targetSelectionRange": Range{Start: Position{Line: 3, Character: 5}},
+		}},
+		"textDocument/completion": map[string]any{"isIncomplete": false, "items": []CompletionItem{{Label: "Println"}, {Label: "Printf", InsertText: "Printf"}}},
+		"textDocument/rename": WorkspaceEdit{Changes: map[string][]TextEdit{
+			target: {{Range: Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 0, Character: 1}}, NewText: "y"}},
+		}},
+	})
+	m, _ := newTestManager(t, pipe)
+	path := filepath.Join(m.Root(), "main.go")
+	m.Sync(path, "package main\n\n// é\nfmt.Pr\n")
+	ctx := context.Background()
+
+	hover, err := m.Hover(ctx, path, 2, 5)
+	if err != nil || hover != "func Println(a ...any)" {
+		t.Errorf("Hover() = %q, %v", hover, err)
+	}
+	locs, err := m.Definition(ctx, path, 3, 6)
+	if err != nil || len(locs) != 1 || locs[0].URI != target || locs[0].Range.Start != (Position{Line: 3, Character: 5}) {
+		t.Errorf("Definition() = %+v, %v", locs, err)
+	}
+	items, err := m.Completion(ctx, path, 3, 6)
+	if err != nil || len(items) != 2 || items[1].Text() != "Printf" {
+		t.Errorf("Completion() = %+v, %v", items, err)
+	}
+	edit, err := m.Rename(ctx, path, 3, 0, "y")
+	if files := edit.Files(); err != nil || len(files) != 1 || files[0].Path != URIToPath(target) {
+		t.Errorf("Rename() = %+v, %v", edit, err)
+	}
+
+	// The hover was sent before the text, whose "é" is two bytes but one
+	// UTF-16 unit
+	server.mu.Lock()
+	positions := server.positions
+	server.mu.Unlock()
+	if positions[0] != (Position{Line: 2, Character: 4}) {
+		t.Errorf("hover position = %+v", positions[0])
+	}
+	if seen := server.seen(); seen[2] != "textDocument/didOpen" {
+		t.Errorf("expected the document to be opened before the requests, got %v", seen)
+	}
+}
+
+func TestManager_RequestErrors(t *testing.T) {
+	_, pipe := newFakeServer(t, map[string]any{
+		"textDocument/rename": &ResponseError{Code: -32803, Message: "cannot rename a builtin"},
+	})
+	m, _ := newTestManager(t, pipe)
+	ctx := context.Background()
+
+	if _, err := m.Hover(ctx, filepath.Join(m.Root(), "README.md"), 0, 0); !errors.Is(err, ErrNoServer) {
+		t.Errorf("expected ErrNoServer for an unhandled file, got %v", err)
+	}
+	path := filepath.Join(m.Root(), "main.go")
+	m.Sync(path, "package main\n")
+	if _, err := m.Rename(ctx, path, 0, 0, "x"); err == nil || !strings.Contains(err.Error(), "cannot rename a builtin") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+}
+
+func TestManager_ServerNotOnPath(t *testing.T) {
+	started := false
+	m := newManager(t.TempDir(), testServers,
+		func(string) (string, error) { return "", errors.New("not found") },
+		func(Server, string, string) (io.ReadWriteCloser, error) {
+			started = true
+			return nil, errors.New("unreachable")
+		},
+		nil)
+	defer m.Shutdown(context.Background())
+	if m.Handles("main.go") {
+		t.Error("expected no server for main.go")
+	}
+	m.Sync("main.go", "package main\n")
+	if _, err := m.Hover(context.Background(), "main.go", 0, 0); !errors.Is(err, ErrNoServer) {
+		t.Errorf("expected ErrNoServer, got %v", err)
+	}
+	if started {
+		t.Error("no server should have been started")
+	}
+}
+
+func TestManager_ServerThatFailsIsNotRestarted(t *testing.T) {
+	starts := 0
+	events := make(chan Event, 4)
+	m := newManager(t.TempDir(), testServers,
+		func(string) (string, error) { return "/usr/bin/fake-ls", nil },
+		func(Server, string, string) (io.ReadWriteCloser, error) {
+			starts++
+			return nil, errors.New("permission denied")
+		},
+		func(e Event) { events <- e })
+	defer m.Shutdown(context.Background())
+
+	m.Sync("a.go", "package a\n")
+	if e := <-events; e.Server != "fake" || e.Err == nil {
+		t.Errorf("expected a failure event, got %+v", e)
+	}
+	m.Sync("b.go", "package b\n")
+	if _, err := m.Hover(context.Background(), "b.go", 0, 0); !errors.Is(err, ErrNoServer) {
+		t.Errorf("expected ErrNoServer, got %v", err)
+	}
+	if starts != 1 {
+		t.Errorf("server started %d times, want 1", starts)
+	}
+}
+
+func TestManager_ServerThatExitsIsRestarted(t *testing.T) {
+	var servers []*fakeServer
+	var mu sync.Mutex
+	events := make(chan Event, 16)
+	m := newManager(t.TempDir(), testServers,
+		func(string) (string, error) { return "/usr/bin/fake-ls", nil },
+		func(Server, string, string) (io.ReadWriteCloser, error) {
+			server, pipe := newFakeServer(t, nil)
+			mu.Lock()
+			servers = append(servers, server)
+			mu.Unlock()
+			return pipe, nil
+		},
+		func(e Event) { events <- e })
+	defer m.Shutdown(context.Background())
+	server := func(i int) *fakeServer {
+		mu.Lock()
+		defer mu.Unlock()
+		if i < len(servers) {
+			return servers[i]
+		}
+		return nil
+	}
+	path := filepath.Join(m.Root(), "main.go")
+
+	m.Sync(path, "package main\n")
+	<-events // started
+	for i := 0; i < maxRestarts; i++ {
+		server(i).conn.Close()
+		if e := <-events; e.Server != "fake" || e.Err == nil || !strings.Contains(e.Err.Error(), "restarting") {
+			t.Fatalf("expected an exit event, got %+v", e)
+		}
+		if e := <-events; e.Server != "fake" || e.Err != nil {
+			t.Fatalf("expected a restarted event, got %+v", e)
+		}
+		eventually(t, "the document reopened", func() bool {
+			s := server(i + 1)
+			if s == nil {
+				return false
+			}
+			text, _ := s.document(path)
+			return text == "package main\n"
+		})
+	}
+
+	// A server that keeps exiting is given up on
+	server(maxRestarts).conn.Close()
+	if e := <-events; e.Err == nil || !strings.Contains(e.Err.Error(), "not started again") {
+		t.Fatalf("expected the server to be given up on, got %+v", e)
+	}
+	m.Sync(path, "package other\n")
+	if _, err := m.Hover(context.Background(), path, 0, 0); err == nil {
+		t.Error("expected no server after the last exit")
+	}
+	if server(maxRestarts+1) != nil {
+		t.Error("expected no further restart")
+	}
+}
+
+func TestManager_ShutdownStopsServers(t *testing.T) {
+	server, pipe := newFakeServer(t, nil)
+	m, events := newTestManager(t, pipe)
+	m.Sync(filepath.Join(m.Root(), "main.go"), "package main\n")
+	<-events
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m.Shutdown(ctx)
+	seen := server.seen()
+	if got := seen[len(seen)-2:]; !reflect.DeepEqual(got, []string{"shutdown", "exit"}) {
+		t.Errorf("expected shutdown and exit last, got %v", seen)
+	}
+	select {
+	case <-server.conn.Done():
+	case <-time.After(time.Second):
+		t.Error("expected the connection to close")
+	}
+	m.Sync(filepath.Join(m.Root(), "main.go"), "package other\n")
+}
+
+func TestServerRequests(t *testing.T) {
+	result, err := serverRequest("workspace/configuration", json.RawMessage(`{"items":[{"section":"gopls"},{}]}`))
+	if err != nil || len(result.([]any)) != 2 {
+		t.Errorf("configuration = %v, %v", result, err)
+	}
+	if _, err := serverRequest("window/workDoneProgress/create", nil); err != nil {
+		t.Errorf("progress tokens should be accepted, got %v", err)
+	}
+	var respErr *ResponseError
+	if _, err := serverRequest("custom/thing", nil); !errors.As(err, &respErr) || respErr.Code != codeMethodNotFound {
+		t.Errorf("expected method not found, got %v", err)
+	}
+}
+
+func TestCall_ClosedConnection(t *testing.T) {
+	clientEnd, serverEnd := net.Pipe()
+	c := newConn(clientEnd, handlers{})
+	serverEnd.Close()
+	<-c.Done()
+	if err := c.Call(context.Background(), "initialize", nil, nil); !errors.Is(err, errClosed) {
+		t.Errorf("expected errClosed, got %v", err)
+	}
+}
+
+func TestConn_ReplyDoesNotBlockReads(t *testing.T) {
+	clientEnd, serverEnd := net.Pipe()
+	defer serverEnd.Close()
+	notified := make(chan string, 1)
+	c := newConn(clientEnd, handlers{
+		request:      func(string, json.RawMessage) (any, error) { return nil, nil },
+		notification: func(method string, _ json.RawMessage) { notified <- method },
+	})
+	defer c.Close()
+
+	// A server that sends a request and a notification before it reads
+	go func() {
+		for _, body := range []string{
+			`{"jsonrpc":"2.0","id":1,"method":"workspace/configuration"}`,
+			`{"jsonrpc":"2.0","method":"window/logMessage"}`,
+		} {
+			fmt.Fprintf(serverEnd, "Content-Length: %d\r\n\r\n%s", len(body), body)
+		}
+	}()
+	select {
+	case method := <-notified:
+		if method != "window/logMessage" {
+			t.Errorf("unexpected notification %s", method)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the reply blocked reading the notification")
+	}
+	reply, err := readMessage(bufio.NewReader(serverEnd))
+	if err != nil || reply.ID == nil || string(*reply.ID) != "1" {
+		t.Errorf("expected the reply to request 1, got %+v, %v", reply, err)
+	}
+}
+
+func TestCall_Cancelled(t *testing.T) {
+	clientEnd, serverEnd := net.Pipe()
+	// A server that reads requests but never answers them
+	go io.Copy(io.Discard, serverEnd)
+	c := newConn(clientEnd, handlers{})
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Call(ctx, "textDocument/hover", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,570 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoServer is returned for requests about files no language server on
+// PATH handles.
+var ErrNoServer = errors.New("no language server for this file")
+
+// initializeTimeout is how long a server may take to start.
+const initializeTimeout = 30 * time.Second
+
+// maxRestarts is how many times a server that exits is started again in a
+// session.
+const maxRestarts = 3
+
+// Event reports diagnostics, or a server that started or failed.
+type Event struct {
+	Path        string       // File the diagnostics are for
+	Diagnostics []Diagnostic // Current diagnostics of Path; empty when they were cleared
+	Server      string       // Server that started or failed, for status events
+	Err         error        // Why Server failed
+}
+
+// Manager runs the language servers of a workspace. Servers are started
+// the first time a file they handle is synced and run until Shutdown; one
+// that exits is restarted. All methods are safe for concurrent use.
+type Manager struct {
+	root     string
+	servers  []Server
+	lookPath func(file string) (string, error)
+	start    func(s Server, path, root string) (io.ReadWriteCloser, error)
+	events   func(Event)
+
+	mu          sync.Mutex
+	found       map[string]string // Executable paths by command; "" when not on PATH
+	clients     map[string]*serverState
+	docs        map[string]*document
+	pending     map[string]string // Text not yet sent, by path
+	retained    map[string]bool   // Files whose buffers are open; nil until the first Retain
+	diagnostics map[string][]Diagnostic
+	restarts    map[string]int // Times each server was restarted after exiting
+	closed      bool
+
+	syncMu sync.Mutex // Held while sending, so versions arrive in order
+	wake   chan struct{}
+	stop   chan struct{}
+}
+
+// serverState is a server that was started, or failed to.
+type serverState struct {
+	ready  chan struct{} // Closed when the server is initialized or failed
+	client *Client
+	err    error
+}
+
+// document is a file open on a server.
+type document struct {
+	server  string
+	version int
+	text    string
+}
+
+// NewManager returns a manager for the workspace at root. events receives
+// diagnostics and server status changes on the manager's goroutines; it
+// must not block.
+func NewManager(root string, events func(Event)) *Manager {
+	return newManager(root, DefaultServers, exec.LookPath, startProcess, events)
+}
+
+func newManager(root string, servers []Server, lookPath func(string) (string, error),
+	start func(Server, string, string) (io.ReadWriteCloser, error), events func(Event)) *Manager {
+	if events == nil {
+		events = func(Event) {}
+	}
+	m := &Manager{
+		root:        root,
+		servers:     servers,
+		lookPath:    lookPath,
+		start:       start,
+		events:      events,
+		found:       make(map[string]string),
+		clients:     make(map[string]*serverState),
+		docs:        make(map[string]*document),
+		pending:     make(map[string]string),
+		diagnostics: make(map[string][]Diagnostic),
+		restarts:    make(map[string]int),
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// Root returns the workspace root.
+func (m *Manager) Root() string {
+	return m.root
+}
+
+// run sends synced text in the background until Shutdown.
+func (m *Manager) run() {
+	for {
+		select {
+		case <-m.wake:
+			m.flush("")
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// serverFor returns the first server on PATH that handles the file at
+// path, and its executable. Lookups are cached. m.mu must be held.
+func (m *Manager) serverFor(path string) (Server, string, bool) {
+	for _, s := range m.servers {
+		if s.languageID(path) == "" {
+			continue
+		}
+		exe, ok := m.found[s.Command]
+		if !ok {
+			exe, _ = m.lookPath(s.Command)
+			m.found[s.Command] = exe
+		}
+		if exe != "" {
+			return s, exe, true
+		}
+	}
+	return Server{}, "", false
+}
+
+// Handles reports whether a server on PATH handles the file at path.
+func (m *Manager) Handles(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, _, ok := m.serverFor(path)
+	return ok
+}
+
+// Sync sends text as the content of the file at path to its server, which
+// is started if needed. It does not wait: the text is sent in the
+// background, and unchanged text is not sent again. Files no server
+// handles are ignored.
+func (m *Manager) Sync(path, text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	if _, _, ok := m.serverFor(path); !ok {
+		return
+	}
+	if pending, ok := m.pending[path]; ok && pending == text {
+		return
+	}
+	if doc := m.docs[path]; doc != nil && doc.text == text {
+		delete(m.pending, path)
+		return
+	}
+	m.pending[path] = text
+	if m.retained != nil {
+		m.retained[path] = true
+	}
+	m.wakeUp()
+}
+
+// wakeUp has run flush the pending changes.
+func (m *Manager) wakeUp() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Retain closes the documents of files other than paths on their servers,
+// after their buffers were closed, and drops their diagnostics. Like Sync,
+// it does not wait: the documents are closed in the background.
+func (m *Manager) Retain(paths []string) {
+	keep := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		keep[p] = true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	m.retained = keep
+	for path := range m.pending {
+		if !keep[path] {
+			delete(m.pending, path)
+		}
+	}
+	m.wakeUp()
+}
+
+// closeDocument closes the document at path. m.syncMu must be held.
+func (m *Manager) closeDocument(path string) {
+	m.mu.Lock()
+	doc := m.docs[path]
+	if doc == nil {
+		// Forgotten when its server exited
+		m.mu.Unlock()
+		return
+	}
+	delete(m.docs, path)
+	_, hadDiagnostics := m.diagnostics[path]
+	delete(m.diagnostics, path)
+	var client *Client
+	if st := m.clients[doc.server]; st != nil {
+		client = st.client
+	}
+	m.mu.Unlock()
+	if client != nil {
+		_ = client.DidClose(path)
+	}
+	if hadDiagnostics {
+		m.events(Event{Path: path})
+	}
+}
+
+// flush closes the documents Retain dropped and sends the pending text of
+// the file at path, or of every file when path is "".
+func (m *Manager) flush(path string) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	m.mu.Lock()
+	var closing []string
+	if m.retained != nil {
+		for p := range m.docs {
+			if !m.retained[p] {
+				closing = append(closing, p)
+			}
+		}
+	}
+	texts := make(map[string]string)
+	for p, text := range m.pending {
+		if path == "" || p == path {
+			texts[p] = text
+			delete(m.pending, p)
+		}
+	}
+	m.mu.Unlock()
+	for _, p := range closing {
+		m.closeDocument(p)
+	}
+	for p, text := range texts {
+		m.send(p, text)
+	}
+}
+
+// send opens or updates the document at path. m.syncMu must be held.
+func (m *Manager) send(path, text string) {
+	m.mu.Lock()
+	s, exe, ok := m.serverFor(path)
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	client, err := m.client(s, exe)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	doc := m.docs[path]
+	if doc == nil {
+		doc = &document{server: s.Name, version: 1, text: text}
+		m.docs[path] = doc
+		m.mu.Unlock()
+		_ = client.DidOpen(path, s.languageID(path), doc.version, text)
+		return
+	}
+	if doc.text == text {
+		m.mu.Unlock()
+		return
+	}
+	doc.version++
+	doc.text = text
+	version := doc.version
+	m.mu.Unlock()
+	_ = client.DidChange(path, version, text)
+}
+
+// client returns the running client of s, starting it the first time. A
+// server that failed to start is not started again; watch restarts one
+// that exits.
+func (m *Manager) client(s Server, exe string) (*Client, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, errClosed
+	}
+	st, ok := m.clients[s.Name]
+	if ok {
+		m.mu.Unlock()
+		<-st.ready
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return st.client, st.err
+	}
+	st = &serverState{ready: make(chan struct{})}
+	m.clients[s.Name] = st
+	m.mu.Unlock()
+
+	client, err := m.startClient(s, exe)
+	m.mu.Lock()
+	st.client, st.err = client, err
+	closed := m.closed
+	m.mu.Unlock()
+	close(st.ready)
+	if closed && client != nil {
+		// Shutdown came while the server was starting
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		_ = client.Shutdown(ctx)
+		return nil, errClosed
+	}
+	m.events(Event{Server: s.Name, Err: err})
+	if err == nil {
+		go m.watch(s.Name, st)
+	}
+	return client, err
+}
+
+// startClient starts and initializes s.
+func (m *Manager) startClient(s Server, exe string) (*Client, error) {
+	rw, err := m.start(s, exe, m.root)
+	if err != nil {
+		return nil, err
+	}
+	client := NewClient(rw, func(method string, params json.RawMessage) {
+		m.notification(method, params)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), initializeTimeout)
+	defer cancel()
+	if err := client.Initialize(ctx, m.root); err != nil {
+		client.conn.Close()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	return client, nil
+}
+
+// watch forgets the documents of a server that exits before Shutdown. The
+// server is started again for its open documents, up to maxRestarts times.
+func (m *Manager) watch(name string, st *serverState) {
+	<-st.client.Done()
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.restarts[name]++
+	restart := m.restarts[name] <= maxRestarts
+	if restart {
+		delete(m.clients, name)
+		st.err = fmt.Errorf("%s exited; restarting it", name)
+	} else {
+		st.err = fmt.Errorf("%s exited %d times; it is not started again", name, m.restarts[name])
+	}
+	st.client = nil
+	var cleared []string
+	for path, doc := range m.docs {
+		if doc.server != name {
+			continue
+		}
+		delete(m.docs, path)
+		if _, ok := m.pending[path]; restart && !ok && (m.retained == nil || m.retained[path]) {
+			m.pending[path] = doc.text
+		}
+		if _, ok := m.diagnostics[path]; ok {
+			delete(m.diagnostics, path)
+			cleared = append(cleared, path)
+		}
+	}
+	err := st.err
+	m.mu.Unlock()
+	m.events(Event{Server: name, Err: err})
+	for _, path := range cleared {
+		m.events(Event{Path: path})
+	}
+	if restart {
+		m.wakeUp()
+	}
+}
+
+// notification handles a notification from a server.
+func (m *Manager) notification(method string, params json.RawMessage) {
+	if method != "textDocument/publishDiagnostics" {
+		return
+	}
+	var p struct {
+		URI         string       `json:"uri"`
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+	path := URIToPath(p.URI)
+	m.mu.Lock()
+	if len(p.Diagnostics) == 0 {
+		delete(m.diagnostics, path)
+	} else {
+		m.diagnostics[path] = p.Diagnostics
+	}
+	m.mu.Unlock()
+	m.events(Event{Path: path, Diagnostics: p.Diagnostics})
+}
+
+// Diagnostics returns the current diagnostics of the file at path.
+func (m *Manager) Diagnostics(path string) []Diagnostic {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.diagnostics[path]
+}
+
+// DiagnosticsReport lists the errors and warnings of every file, one per
+// line as "path:line:col: severity: message", with paths relative to the
+// workspace root. At most limit lines are listed, errors first.
+func (m *Manager) DiagnosticsReport(limit int) string {
+	type entry struct {
+		path string
+		d    Diagnostic
+	}
+	m.mu.Lock()
+	var entries []entry
+	for path, diags := range m.diagnostics {
+		for _, d := range diags {
+			if d.Severity <= SeverityWarning {
+				entries = append(entries, entry{path, d})
+			}
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.d.Severity == SeverityWarning) != (b.d.Severity == SeverityWarning) {
+			return b.d.Severity == SeverityWarning
+		}
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		return a.d.Range.Start.Line < b.d.Range.Start.Line
+	})
+	var sb strings.Builder
+	for i, e := range entries {
+		if i == limit {
+			fmt.Fprintf(&sb, "... and %d more\n", len(entries)-limit)
+			break
+		}
+		path := e.path
+		if rel, err := filepath.Rel(m.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = filepath.ToSlash(rel)
+		}
+		message := strings.ReplaceAll(strings.TrimSpace(e.d.Message), "\n", " ")
+		if e.d.Source != "" {
+			message += " (" + e.d.Source + ")"
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d: %s: %s\n", path, e.d.Range.Start.Line+1, e.d.Range.Start.Character+1, e.d.Severity, message)
+	}
+	return sb.String()
+}
+
+// target returns the client and document of the file at path after
+// sending its pending text, and the protocol position of the byte column
+// col on line.
+func (m *Manager) target(path string, line, col int) (*Client, Position, error) {
+	m.flush(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	doc := m.docs[path]
+	if doc == nil {
+		return nil, Position{}, ErrNoServer
+	}
+	st := m.clients[doc.server]
+	if st == nil || st.client == nil {
+		return nil, Position{}, ErrNoServer
+	}
+	lines := strings.Split(doc.text, "\n")
+	pos := Position{Line: line}
+	if line >= 0 && line < len(lines) {
+		pos.Character = UTF16Column(lines[line], col)
+	}
+	return st.client, pos, nil
+}
+
+// Hover returns the hover text for the byte column col on line of the
+// synced file at path.
+func (m *Manager) Hover(ctx context.Context, path string, line, col int) (string, error) {
+	client, pos, err := m.target(path, line, col)
+	if err != nil {
+		return "", err
+	}
+	return client.Hover(ctx, path, pos)
+}
+
+// Definition returns where the symbol at the byte column col on line of
+// the synced file at path is defined.
+func (m *Manager) Definition(ctx context.Context, path string, line, col int) ([]Location, error) {
+	client, pos, err := m.target(path, line, col)
+	if err != nil {
+		return nil, err
+	}
+	return client.Definition(ctx, path, pos)
+}
+
+// Completion returns the completions for the byte column col on line of
+// the synced file at path.
+func (m *Manager) Completion(ctx context.Context, path string, line, col int) ([]CompletionItem, error) {
+	client, pos, err := m.target(path, line, col)
+	if err != nil {
+		return nil, err
+	}
+	return client.Completion(ctx, path, pos)
+}
+
+// Rename returns the edits that rename the symbol at the byte column col on
+// line of the synced file at path to newName.
+func (m *Manager) Rename(ctx context.Context, path string, line, col int, newName string) (*WorkspaceEdit, error) {
+	client, pos, err := m.target(path, line, col)
+	if err != nil {
+		return nil, err
+	}
+	return client.Rename(ctx, path, pos, newName)
+}
+
+// Shutdown stops every server. The manager does nothing afterwards.
+func (m *Manager) Shutdown(ctx context.Context) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	states := make([]*serverState, 0, len(m.clients))
+	for _, st := range m.clients {
+		states = append(states, st)
+	}
+	m.mu.Unlock()
+	close(m.stop)
+
+	var wg sync.WaitGroup
+	for _, st := range states {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-st.ready:
+			case <-ctx.Done():
+				return
+			}
+			m.mu.Lock()
+			client := st.client
+			m.mu.Unlock()
+			if client != nil {
+				_ = client.Shutdown(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,340 @@
+// Package lsp is a client for the Language Server Protocol. It starts the
+// language servers found on PATH for the files being edited, keeps them in
+// sync with the editor's buffers and asks them for diagnostics, hovers,
+// definitions, completions and renames.
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// Position is a 0-based line and a column in UTF-16 code units, as the
+// protocol counts them.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of text from Start up to, but not including, End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Severity is how serious a diagnostic is.
+type Severity int
+
+// Diagnostic severities
+const (
+	SeverityError       Severity = 1
+	SeverityWarning     Severity = 2
+	SeverityInformation Severity = 3
+	SeverityHint        Severity = 4
+)
+
+// String returns the lower-case name of s. Servers may leave the severity
+// out, which counts as an error.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic is a problem a server reports in a document.
+type Diagnostic struct {
+	Range    Range    `json:"range"`
+	Severity Severity `json:"severity,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// TextEdit replaces the text in Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit is a set of edits to several documents, as returned for a
+// rename. Servers use either Changes or DocumentChanges; document changes
+// that create, rename or delete files have no text document and are
+// ignored.
+type WorkspaceEdit struct {
+	Changes         map[string][]TextEdit `json:"changes,omitempty"`
+	DocumentChanges []TextDocumentEdit    `json:"documentChanges,omitempty"`
+}
+
+// TextDocumentEdit are the edits to one document of a WorkspaceEdit.
+type TextDocumentEdit struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Edits []TextEdit `json:"edits"`
+}
+
+// Files returns the edits of w by absolute file path, in path order.
+func (w WorkspaceEdit) Files() []FileEdit {
+	byPath := make(map[string][]TextEdit)
+	for uri, edits := range w.Changes {
+		byPath[URIToPath(uri)] = append(byPath[URIToPath(uri)], edits...)
+	}
+	for _, change := range w.DocumentChanges {
+		if change.TextDocument.URI == "" {
+			continue
+		}
+		path := URIToPath(change.TextDocument.URI)
+		byPath[path] = append(byPath[path], change.Edits...)
+	}
+	files := make([]FileEdit, 0, len(byPath))
+	for path, edits := range byPath {
+		files = append(files, FileEdit{Path: path, Edits: edits})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// FileEdit are the edits to one file.
+type FileEdit struct {
+	Path  string // Absolute path of the file
+	Edits []TextEdit
+}
+
+// CompletionItem is a suggestion for the text at the cursor.
+type CompletionItem struct {
+	Label      string    `json:"label"`
+	Kind       int       `json:"kind,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	InsertText string    `json:"insertText,omitempty"`
+	FilterText string    `json:"filterText,omitempty"`
+	SortText   string    `json:"sortText,omitempty"`
+	TextEdit   *TextEdit `json:"textEdit,omitempty"`
+}
+
+// Text returns what accepting the item inserts.
+func (c CompletionItem) Text() string {
+	switch {
+	case c.TextEdit != nil:
+		return c.TextEdit.NewText
+	case c.InsertText != "":
+		return c.InsertText
+	default:
+		return c.Label
+	}
+}
+
+// Filter returns the text typed input is matched against.
+func (c CompletionItem) Filter() string {
+	if c.FilterText != "" {
+		return c.FilterText
+	}
+	return c.Label
+}
+
+// completionList is the result of a completion request, which servers send
+// either as a list or as a bare array of items.
+type completionList struct {
+	Items []CompletionItem
+}
+
+func (l *completionList) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, &l.Items)
+	}
+	var list struct {
+		Items []CompletionItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	l.Items = list.Items
+	return nil
+}
+
+// hoverResult is the result of a hover request. Its contents are a string,
+// a marked string ({language, value}), markup content ({kind, value}) or an
+// array of strings and marked strings.
+type hoverResult struct {
+	Contents json.RawMessage `json:"contents"`
+}
+
+// text returns the contents of h as plain text.
+func (h hoverResult) text() string {
+	var parts []json.RawMessage
+	if len(h.Contents) > 0 && h.Contents[0] == '[' {
+		if err := json.Unmarshal(h.Contents, &parts); err != nil {
+			return ""
+		}
+	} else {
+		parts = []json.RawMessage{h.Contents}
+	}
+	var texts []string
+	for _, part := range parts {
+		var s string
+		if err := json.Unmarshal(part, &s); err == nil {
+			texts = append(texts, s)
+			continue
+		}
+		var marked struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(part, &marked); err == nil && marked.Value != "" {
+			texts = append(texts, marked.Value)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n\n"))
+}
+
+// locations is the result of a definition request: a location, an array
+// of locations or an array of location links.
+type locations []Location
+
+func (l *locations) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	if len(data) > 0 && data[0] != '[' {
+		var loc Location
+		if err := json.Unmarshal(data, &loc); err != nil {
+			return err
+		}
+		*l = locations{loc}
+		return nil
+	}
+	var items []struct {
+		Location
+		TargetURI            string `json:"targetUri"`
+		TargetSelectionRange *Range `json:"targetSelectionRange"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*l = nil
+	for _, item := range items {
+		if item.TargetURI != "" && item.TargetSelectionRange != nil {
+			*l = append(*l, Location{URI: item.TargetURI, Range: *item.TargetSelectionRange})
+			continue
+		}
+		*l = append(*l, item.Location)
+	}
+	return nil
+}
+
+// PathToURI returns the file URI of the absolute path.
+func PathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if runtime.GOOS == "windows" {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// URIToPath returns the file path of a file URI. Other URIs are returned as
+// they are.
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// UTF16Column returns the UTF-16 column of the byte column col in line.
+// Columns past the end of the line count to its end.
+func UTF16Column(line string, col int) int {
+	n := 0
+	for i, r := range line {
+		if i >= col {
+			break
+		}
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// ByteColumn returns the byte column of the UTF-16 column char in line.
+// Columns past the end of the line, or inside a surrogate pair, count to
+// the end of the line or the start of the rune.
+func ByteColumn(line string, char int) int {
+	n := 0
+	for i, r := range line {
+		if n >= char {
+			return i
+		}
+		n += utf16.RuneLen(r)
+		if n > char {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// offset returns the byte offset of pos in text. Positions past the end of
+// a line or of the text count to its end.
+func offset(text string, pos Position) int {
+	start := 0
+	for line := 0; line < pos.Line; line++ {
+		i := strings.IndexByte(text[start:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		start += i + 1
+	}
+	end := strings.IndexByte(text[start:], '\n')
+	if end < 0 {
+		end = len(text) - start
+	}
+	return start + ByteColumn(text[start:start+end], pos.Character)
+}
+
+// ApplyEdits returns text with edits applied. The edits may come in any
+// order but must not overlap, as the protocol requires.
+func ApplyEdits(text string, edits []TextEdit) (string, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, len(edits))
+	for i, edit := range edits {
+		spans[i] = span{offset(text, edit.Range.Start), offset(text, edit.Range.End), edit.NewText}
+		if spans[i].end < spans[i].start {
+			return "", fmt.Errorf("edit %d ends before it starts", i)
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			return "", fmt.Errorf("overlapping edits")
+		}
+		b.WriteString(text[last:s.start])
+		b.WriteString(s.text)
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String(), nil
+}
@@ -0,0 +1,122 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	line := "a€😀b" // 1, 3 and 4 bytes; 1, 1 and 2 UTF-16 units
+	tests := []struct{ byteCol, utf16Col int }{{0, 0}, {1, 1}, {4, 2}, {8, 4}, {9, 5}}
+	for _, tt := range tests {
+		if got := UTF16Column(line, tt.byteCol); got != tt.utf16Col {
+			t.Errorf("UTF16Column(%d) = %d, want %d", tt.byteCol, got, tt.utf16Col)
+		}
+		if got := ByteColumn(line, tt.utf16Col); got != tt.byteCol {
+			t.Errorf("ByteColumn(%d) = %d, want %d", tt.utf16Col, got, tt.byteCol)
+		}
+	}
+	if got := ByteColumn(line, 3); got != 4 {
+		t.Errorf("a column inside a surrogate pair should count to the rune start, got %d", got)
+	}
+	if got := ByteColumn(line, 99); got != len(line) {
+		t.Errorf("a column past the end should count to the end, got %d", got)
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	text := "func old() {}\n\nold()\n"
+	edits := []TextEdit{
+		{Range: Range{Start: Position{2, 0}, End: Position{2, 3}}, NewText: "renamed"},
+		{Range: Range{Start: Position{0, 5}, End: Position{0, 8}}, NewText: "renamed"},
+		{Range: Range{Start: Position{3, 0}, End: Position{3, 0}}, NewText: "// end\n"},
+	}
+	got, err := ApplyEdits(text, edits)
+	if want := "func renamed() {}\n\nrenamed()\n// end\n"; err != nil || got != want {
+		t.Errorf("ApplyEdits() = %q, %v, want %q", got, err, want)
+	}
+
+	overlapping := []TextEdit{
+		{Range: Range{Start: Position{0, 0}, End: Position{0, 6}}, NewText: "a"},
+		{Range: Range{Start: Position{0, 5}, End: Position{0, 8}}, NewText: "b"},
+	}
+	if _, err := ApplyEdits(text, overlapping); err == nil {
+		t.Error("expected an error for overlapping edits")
+	}
+}
+
+func TestURIs(t *testing.T) {
+	path := filepath.Join(string(filepath.Separator), "src", "my project", "main.go")
+	uri := PathToURI(path)
+	if uri != "file:///src/my%20project/main.go" {
+		t.Errorf("PathToURI() = %q", uri)
+	}
+	if got := URIToPath(uri); got != path {
+		t.Errorf("URIToPath() = %q, want %q", got, path)
+	}
+	if got := URIToPath("untitled:1"); got != "untitled:1" {
+		t.Errorf("other URIs should be kept, got %q", got)
+	}
+}
+
+func TestHoverText(t *testing.T) {
+	tests := map[string]string{
+		`"plain"`:                                "plain",
+		`{"kind":"markdown","value":"**bold**"}`: "**bold**",
+		`{"language":"go","value":"func f()"}`:   "func f()",
+		`["doc", {"language":"go","value":"x"}]`: "doc\n\nx",
+		`[]`:                                     "",
+	}
+	for contents, want := range tests {
+		if got := (hoverResult{Contents: json.RawMessage(contents)}).text(); got != want {
+			t.Errorf("text(%s) = %q, want %q", contents, got, want)
+		}
+	}
+}
+
+func TestLocationsUnmarshal(t *testing.T) {
+	one := `{"uri":"file:///a.go","range":{"start":{"line":1,"character":2},"end":{"line":1,"character":3}}}`
+	tests := map[string]int{one: 1, "[" + one + "," + one + "]": 2, "null": 0, "[]": 0}
+	for data, want := range tests {
+		var l locations
+		if err := json.Unmarshal([]byte(data), &l); err != nil || len(l) != want {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %d locations", data, l, err, want)
+		}
+	}
+}
+
+func TestCompletionListUnmarshal(t *testing.T) {
+	for _, data := range []string{`[{"label":"a"}]`, `{"isIncomplete":true,"items":[{"label":"a"}]}`} {
+		var l completionList
+		if err := json.Unmarshal([]byte(data), &l); err != nil || !reflect.DeepEqual(l.Items, []CompletionItem{{Label: "a"}}) {
+			t.Errorf("Unmarshal(%s) = %+v, %v", data, l, err)
+		}
+	}
+}
+
+func TestWorkspaceEditFiles(t *testing.T) {
+	var edit WorkspaceEdit
+	data := `{"documentChanges":[
+		{"textDocument":{"uri":"file:///b.go","version":3},"edits":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}},"newText":"x"}]},
+		{"kind":"create","uri":"file:///c.go"},
+		{"textDocument":{"uri":"file:///a.go","version":1},"edits":[]}
+	]}`
+	if err := json.Unmarshal([]byte(data), &edit); err != nil {
+		t.Fatal(err)
+	}
+	files := edit.Files()
+	if len(files) != 2 || files[0].Path != URIToPath("file:///a.go") || files[1].Path != URIToPath("file:///b.go") || len(files[1].Edits) != 1 {
+		t.Errorf("Files() = %+v", files)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	if got := Commands("app/main.PY"); !reflect.DeepEqual(got, []string{"pyright-langserver", "pylsp"}) {
+		t.Errorf("Commands() = %v", got)
+	}
+	if got := Commands("README"); got != nil {
+		t.Errorf("expected no servers for README, got %v", got)
+	}
+}
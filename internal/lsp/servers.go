@@ -0,0 +1,105 @@
+package lsp
+
+import (
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Server describes a language server and the files it handles.
+type Server struct {
+	Name      string            // Name shown in status messages
+	Command   string            // Executable looked up on PATH
+	Args      []string          // Arguments that make it talk over stdio
+	Languages map[string]string // Language IDs by file extension, with the leading dot
+}
+
+// DefaultServers are the servers tried for a file, in order: the first one
+// found on PATH for its extension is used.
+var DefaultServers = []Server{
+	{Name: "gopls", Command: "gopls", Languages: map[string]string{".go": "go"}},
+	{Name: "pyright", Command: "pyright-langserver", Args: []string{"--stdio"}, Languages: map[string]string{".py": "python"}},
+	{Name: "pylsp", Command: "pylsp", Languages: map[string]string{".py": "python"}},
+	{Name: "typescript-language-server", Command: "typescript-language-server", Args: []string{"--stdio"}, Languages: map[string]string{
+		".ts": "typescript", ".tsx": "typescriptreact", ".js": "javascript", ".jsx": "javascriptreact", ".mjs": "javascript", ".cjs": "javascript",
+	}},
+	{Name: "rust-analyzer", Command: "rust-analyzer", Languages: map[string]string{".rs": "rust"}},
+	{Name: "clangd", Command: "clangd", Languages: map[string]string{
+		".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp", ".hpp": "cpp",
+	}},
+	{Name: "bash-language-server", Command: "bash-language-server", Args: []string{"start"}, Languages: map[string]string{
+		".sh": "shellscript", ".bash": "shellscript",
+	}},
+}
+
+// Commands returns the commands of the default servers for the file at
+// path, in the order they are tried.
+func Commands(path string) []string {
+	var commands []string
+	for _, s := range DefaultServers {
+		if s.languageID(path) != "" {
+			commands = append(commands, s.Command)
+		}
+	}
+	return commands
+}
+
+// languageID returns the language ID s uses for the file at path, or ""
+// if s does not handle it.
+func (s Server) languageID(path string) string {
+	return s.Languages[strings.ToLower(filepath.Ext(path))]
+}
+
+// stopTimeout is how long a server may take to exit after its input is
+// closed before it is killed.
+const stopTimeout = 2 * time.Second
+
+// process is a running server; reading and writing talk to its stdio.
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *io.PipeReader
+	exited chan struct{}
+}
+
+// startProcess runs s with the workspace root as its working directory.
+func startProcess(s Server, path, root string) (io.ReadWriteCloser, error) {
+	cmd := exec.Command(path, s.Args...)
+	cmd.Dir = root
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	// Reading through an io.Pipe lets Wait finish copying the output
+	// before the reader sees the end of it
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{cmd: cmd, stdin: stdin, stdout: pr, exited: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		pw.Close()
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+func (p *process) Read(b []byte) (int, error)  { return p.stdout.Read(b) }
+func (p *process) Write(b []byte) (int, error) { return p.stdin.Write(b) }
+
+// Close closes the server's input and waits for it to exit, killing it if
+// it takes too long.
+func (p *process) Close() error {
+	p.stdin.Close()
+	select {
+	case <-p.exited:
+	case <-time.After(stopTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.exited
+	}
+	return nil
+}
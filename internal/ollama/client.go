@@ -3,12 +3,14 @@ package ollama
 import (
 	"bufio"
 	"bytes"
+	stdcontext "context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/types"
 )
 
@@ -77,6 +79,23 @@ type generateResponse struct {
 //   onTokenUsage: callback invoked with actual token usage when stream completes
 // Returns: channel for streaming response chunks, error if request fails
 func (oc *OllamaClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	return oc.generate(stdcontext.Background(), prompt, model, context, onTokenUsage)
+}
+
+// GenerateContext generates AI response with streaming until ctx is done.
+// Cancelling ctx aborts the HTTP request and closes the returned channel.
+// Args:
+//   ctx: context.Context - cancellation and deadline for the request
+//   prompt: string - user's prompt to the AI
+//   model: string - model name to use (default: "llama2")
+//   onTokenUsage: callback invoked with actual token usage when stream completes
+// Returns: channel for streaming response chunks, error if request fails
+func (oc *OllamaClient) GenerateContext(ctx stdcontext.Context, prompt string, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	return oc.generate(ctx, prompt, model, nil, onTokenUsage)
+}
+
+// generate sends a request to the /api/generate endpoint and streams the response
+func (oc *OllamaClient) generate(ctx stdcontext.Context, prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
 	if model == "" {
 		model = "llama2"
 	}
@@ -93,7 +112,7 @@ func (oc *OllamaClient) Generate(prompt string, model string, context []int, onT
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", oc.baseURL+"/api/generate", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.baseURL+"/api/generate", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -129,19 +148,21 @@ func (oc *OllamaClient) Generate(prompt string, model string, context []int, onT
 			var genResp generateResponse
 			if err := json.Unmarshal(line, &genResp); err != nil {
 				// Send error message to channel
-				responseChan <- fmt.Sprintf("Error parsing response: %v", err)
+				send(ctx, responseChan, fmt.Sprintf("Error parsing response: %v", err))
 				return
 			}
 
 			// Check for API error
 			if genResp.Error != "" {
-				responseChan <- fmt.Sprintf("API Error: %s", genResp.Error)
+				send(ctx, responseChan, fmt.Sprintf("API Error: %s", genResp.Error))
 				return
 			}
 
 			// Send response chunk to channel
 			if genResp.Response != "" {
-				responseChan <- genResp.Response
+				if !send(ctx, responseChan, genResp.Response) {
+					return
+				}
 			}
 
 			// Stop if done
@@ -157,7 +178,136 @@ func (oc *OllamaClient) Generate(prompt string, model string, context []int, onT
 			}
 		}
 
-		if err := scanner.Err(); err != nil {
+		// A read error after cancellation is expected and not reported
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			responseChan <- fmt.Sprintf("Error reading response: %v", err)
+		}
+	}()
+
+	return responseChan, nil
+}
+
+// send delivers a chunk unless ctx is done first; it reports whether the
+// chunk was delivered
+func send(ctx stdcontext.Context, ch chan<- string, chunk string) bool {
+	select {
+	case ch <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// chatMessage represents a single message for the chat API
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest represents the request body for the chat API
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+// chatResponse represents a streaming response chunk from the chat API
+type chatResponse struct {
+	Model           string      `json:"model"`
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	Error           string      `json:"error,omitempty"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+// Chat generates AI response with streaming for a multi-turn conversation
+// using the /api/chat endpoint
+// Args:
+//   ctx: context.Context - cancellation and deadline for the request
+//   messages: []ai.Message - conversation history, oldest first
+//   model: string - model name to use (default: "llama2")
+//   onTokenUsage: callback invoked with actual token usage when stream completes
+// Returns: channel for streaming response chunks, error if request fails
+func (oc *OllamaClient) Chat(ctx stdcontext.Context, messages []ai.Message, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	if model == "" {
+		model = "llama2"
+	}
+
+	reqBody := chatRequest{
+		Model:    model,
+		Messages: make([]chatMessage, len(messages)),
+		Stream:   true,
+	}
+	for i, msg := range messages {
+		reqBody.Messages[i] = chatMessage{Role: msg.Role, Content: msg.Content}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Use a client without timeout for streaming
+	streamClient := &http.Client{
+		Timeout: 0, // No timeout for streaming
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Ollama API returned status %d", resp.StatusCode)
+	}
+
+	responseChan := make(chan string, 10)
+
+	go func() {
+		defer close(responseChan)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var chatResp chatResponse
+			if err := json.Unmarshal(scanner.Bytes(), &chatResp); err != nil {
+				send(ctx, responseChan, fmt.Sprintf("Error parsing response: %v", err))
+				return
+			}
+
+			if chatResp.Error != "" {
+				send(ctx, responseChan, fmt.Sprintf("API Error: %s", chatResp.Error))
+				return
+			}
+
+			if chatResp.Message.Content != "" {
+				if !send(ctx, responseChan, chatResp.Message.Content) {
+					return
+				}
+			}
+
+			if chatResp.Done {
+				if onTokenUsage != nil {
+					onTokenUsage(types.TokenUsage{
+						InputTokens:  chatResp.PromptEvalCount,
+						OutputTokens: chatResp.EvalCount,
+						TotalTokens:  chatResp.PromptEvalCount + chatResp.EvalCount,
+					})
+				}
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
 			responseChan <- fmt.Sprintf("Error reading response: %v", err)
 		}
 	}()
@@ -0,0 +1,199 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+func TestChat_UsesChatEndpointWithHistory(t *testing.T) {
+	var captured chatRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected request to /api/chat, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		for _, chunk := range []string{"Your name ", "is Ada."} {
+			data, _ := json.Marshal(map[string]interface{}{
+				"model":   "testmodel",
+				"message": map[string]string{"role": "assistant", "content": chunk},
+				"done":    false,
+			})
+			fmt.Fprintf(w, "%s\n", data)
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"model":             "testmodel",
+			"message":           map[string]string{"role": "assistant", "content": ""},
+			"done":              true,
+			"prompt_eval_count": 30,
+			"eval_count":        5,
+		})
+		fmt.Fprintf(w, "%s\n", data)
+	}))
+	defer mockServer.Close()
+
+	client := NewOllamaClient(mockServer.URL)
+
+	var usage types.TokenUsage
+	responseChan, err := client.Chat(context.Background(), []ai.Message{
+		{Role: "user", Content: "My name is Ada."},
+		{Role: "assistant", Content: "Nice to meet you, Ada."},
+		{Role: "user", Content: "What is my name?"},
+	}, "testmodel", func(u types.TokenUsage) { usage = u })
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+
+	var fullResponse string
+	for chunk := range responseChan {
+		fullResponse += chunk
+	}
+
+	if fullResponse != "Your name is Ada." {
+		t.Errorf("expected 'Your name is Ada.', got %q", fullResponse)
+	}
+	if len(captured.Messages) != 3 {
+		t.Fatalf("expected 3 messages in request, got %d", len(captured.Messages))
+	}
+	if captured.Messages[1].Role != "assistant" || captured.Messages[1].Content != "Nice to meet you, Ada." {
+		t.Errorf("unexpected assistant turn: %+v", captured.Messages[1])
+	}
+	if !captured.Stream {
+		t.Error("expected stream to be enabled")
+	}
+	if usage.InputTokens != 30 || usage.OutputTokens != 5 || usage.TotalTokens != 35 {
+		t.Errorf("unexpected token usage: %+v", usage)
+	}
+}
+
+func TestChat_APIErrorDeliveredThroughChannel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"error":"model not found"}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOllamaClient(mockServer.URL)
+	responseChan, err := client.Chat(context.Background(), []ai.Message{{Role: "user", Content: "hi"}}, "missing", nil)
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+
+	var received []string
+	for chunk := range responseChan {
+		received = append(received, chunk)
+	}
+	if len(received) != 1 || received[0] != "API Error: model not found" {
+		t.Errorf("expected API error chunk, got %v", received)
+	}
+}
+
+func TestGenerateContext_CancelStopsStream(t *testing.T) {
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		data, _ := json.Marshal(map[string]interface{}{"response": "partial", "done": false})
+		fmt.Fprintf(w, "%s\n", data)
+		flusher.Flush()
+		// Hold the stream open until the client goes away
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer mockServer.Close()
+	defer close(release)
+
+	client := NewOllamaClient(mockServer.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	responseChan, err := client.GenerateContext(ctx, "prompt", "testmodel", nil)
+	if err != nil {
+		t.Fatalf("GenerateContext returned error: %v", err)
+	}
+
+	if chunk := <-responseChan; chunk != "partial" {
+		t.Fatalf("expected first chunk 'partial', got %q", chunk)
+	}
+	cancel()
+
+	select {
+	case chunk, ok := <-responseChan:
+		if ok {
+			t.Errorf("expected channel to close after cancel, got chunk %q", chunk)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+}
+
+func TestChatTools_SendsToolsAndParsesCalls(t *testing.T) {
+	var captured toolChatRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected request to /api/chat, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"message": {"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "read_file", "arguments": {"path": "main.go"}}}]}, "prompt_eval_count": 12, "eval_count": 4}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOllamaClient(mockServer.URL)
+	reply, err := client.ChatTools(context.Background(), []ai.ToolMessage{
+		{Role: "user", Content: "fix main.go"},
+		{Role: "assistant", ToolCalls: []ai.ToolCall{{ID: "call_1", Name: "list_dir", Arguments: json.RawMessage(`{}`)}}},
+		{Role: "tool", Results: []ai.ToolResult{{CallID: "call_1", Name: "list_dir", Content: "main.go"}}},
+	}, []ai.ToolSpec{{Name: "read_file", Description: "Read a file", Parameters: map[string]interface{}{"type": "object"}}}, "testmodel")
+	if err != nil {
+		t.Fatalf("ChatTools returned error: %v", err)
+	}
+
+	if captured.Stream {
+		t.Error("expected a non-streaming request")
+	}
+	if len(captured.Tools) != 1 || captured.Tools[0].Type != "function" || captured.Tools[0].Function.Name != "read_file" {
+		t.Errorf("unexpected tools in request: %+v", captured.Tools)
+	}
+	if len(captured.Messages) != 3 {
+		t.Fatalf("expected 3 messages in request, got %d", len(captured.Messages))
+	}
+	if tool := captured.Messages[2]; tool.Role != "tool" || tool.ToolName != "list_dir" || tool.Content != "main.go" {
+		t.Errorf("unexpected tool result message: %+v", tool)
+	}
+
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].Name != "read_file" || reply.ToolCalls[0].ID != "call_1" {
+		t.Fatalf("unexpected tool calls: %+v", reply.ToolCalls)
+	}
+	if string(reply.ToolCalls[0].Arguments) != `{"path": "main.go"}` {
+		t.Errorf("unexpected arguments: %s", reply.ToolCalls[0].Arguments)
+	}
+	if reply.Usage.TotalTokens != 16 {
+		t.Errorf("unexpected token usage: %+v", reply.Usage)
+	}
+}
+
+func TestChatTools_UnsupportedModel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": "registry.ollama.ai/library/llama2:latest does not support tools"}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOllamaClient(mockServer.URL)
+	_, err := client.ChatTools(context.Background(), []ai.ToolMessage{{Role: "user", Content: "hi"}}, nil, "llama2")
+	if !errors.Is(err, ai.ErrToolsUnsupported) {
+		t.Fatalf("expected ErrToolsUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package ollama
+
+import "github.com/user/terminal-intelligence/internal/ai"
+
+func init() {
+	ai.RegisterProvider(ai.ProviderSpec{
+		Name:         "ollama",
+		Description:  "Local models served by Ollama",
+		ModelKey:     "model",
+		DefaultModel: "llama2",
+		Fields: []ai.ConfigField{
+			{Key: "model", Description: "Ollama model name"},
+			{Key: "ollama_url", Description: "Ollama server URL", Default: "http://localhost:11434"},
+		},
+		Capabilities: ai.Capabilities{Chat: true, Cancel: true, ListModels: true, VerifyModel: true, Tools: true},
+		New: func(settings ai.Settings) (ai.AIClient, error) {
+			return NewOllamaClient(settings.Get("ollama_url")), nil
+		},
+	})
+}
@@ -0,0 +1,159 @@
+package ollama
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// toolFunction describes a function in the chat API "tools" list
+type toolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// tool is an entry of the chat API "tools" list
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+// toolCall is a function call requested by the model
+type toolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// toolChatMessage is a chat message that may carry tool calls or a tool result
+type toolChatMessage struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+// toolChatRequest is a non-streaming chat request with tools
+type toolChatRequest struct {
+	Model    string            `json:"model"`
+	Messages []toolChatMessage `json:"messages"`
+	Tools    []tool            `json:"tools"`
+	Stream   bool              `json:"stream"`
+}
+
+// toolChatResponse is the response to a toolChatRequest
+type toolChatResponse struct {
+	Message         toolChatMessage `json:"message"`
+	Error           string          `json:"error,omitempty"`
+	PromptEvalCount int             `json:"prompt_eval_count"`
+	EvalCount       int             `json:"eval_count"`
+}
+
+// ChatTools sends a tool-using conversation to /api/chat with native tool
+// definitions and returns the model's reply
+// Args:
+//   ctx: context.Context - cancellation and deadline for the request
+//   messages: []ai.ToolMessage - conversation, including earlier tool calls and results
+//   tools: []ai.ToolSpec - tools the model may call
+//   model: string - model name to use (default: "llama2"); must support tools
+// Returns: the model's reply, error if request fails
+func (oc *OllamaClient) ChatTools(ctx stdcontext.Context, messages []ai.ToolMessage, tools []ai.ToolSpec, model string) (*ai.ToolReply, error) {
+	if model == "" {
+		model = "llama2"
+	}
+
+	reqBody := toolChatRequest{Model: model, Stream: false}
+	for _, t := range tools {
+		reqBody.Tools = append(reqBody.Tools, tool{
+			Type:     "function",
+			Function: toolFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters},
+		})
+	}
+	for _, msg := range messages {
+		switch msg.Role {
+		case "tool":
+			// One message per result; Ollama matches results to calls by order
+			for _, result := range msg.Results {
+				reqBody.Messages = append(reqBody.Messages, toolChatMessage{
+					Role:     "tool",
+					Content:  result.Content,
+					ToolName: result.Name,
+				})
+			}
+		default:
+			m := toolChatMessage{Role: msg.Role, Content: msg.Content}
+			for _, call := range msg.ToolCalls {
+				var tc toolCall
+				tc.Function.Name = call.Name
+				tc.Function.Arguments = call.Arguments
+				m.ToolCalls = append(m.ToolCalls, tc)
+			}
+			reqBody.Messages = append(reqBody.Messages, m)
+		}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Tool turns are not streamed but can take as long as a streamed answer
+	resp, err := (&http.Client{Timeout: 0}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(body), "does not support tools") {
+			return nil, fmt.Errorf("%w: %s", ai.ErrToolsUnsupported, string(body))
+		}
+		return nil, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp toolChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if chatResp.Error != "" {
+		return nil, fmt.Errorf("API Error: %s", chatResp.Error)
+	}
+
+	reply := &ai.ToolReply{
+		Content: chatResp.Message.Content,
+		Usage: types.TokenUsage{
+			InputTokens:  chatResp.PromptEvalCount,
+			OutputTokens: chatResp.EvalCount,
+			TotalTokens:  chatResp.PromptEvalCount + chatResp.EvalCount,
+		},
+	}
+	for i, tc := range chatResp.Message.ToolCalls {
+		args := tc.Function.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		reply.ToolCalls = append(reply.ToolCalls, ai.ToolCall{
+			ID:        fmt.Sprintf("call_%d", i+1),
+			Name:      tc.Function.Name,
+			Arguments: args,
+		})
+	}
+
+	return reply, nil
+}
@@ -0,0 +1,320 @@
+// Package openai implements ai.AIClient for servers that speak the OpenAI
+// chat completions protocol, such as llama.cpp server, vLLM, LM Studio and the
+// OpenAI API itself.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// DefaultBaseURL is used when no base URL is configured
+const DefaultBaseURL = "http://localhost:8080/v1"
+
+// OpenAIClient handles communication with an OpenAI-compatible server
+type OpenAIClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewOpenAIClient creates a new client for an OpenAI-compatible server.
+// Args:
+//
+//	baseURL: string - API base URL; "/v1" is added when it has no path
+//	  (default: "http://localhost:8080/v1")
+//	apiKey: string - bearer token; may be empty for local servers
+//
+// Returns: initialized OpenAIClient
+func NewOpenAIClient(baseURL string, apiKey string) *OpenAIClient {
+	return &OpenAIClient{
+		baseURL: normalizeBaseURL(baseURL),
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// normalizeBaseURL trims trailing slashes and adds /v1 to a URL without a
+// path, so both "http://host:8080" and "http://host:8080/v1/" work. A URL
+// with a path, e.g. ".../v1beta/openai", is used as given.
+func normalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return DefaultBaseURL
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Path == "" {
+		baseURL += "/v1"
+	}
+	return baseURL
+}
+
+// chatMessage represents a single message in a chat completions request
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// streamOptions asks the server to report usage in the final chunk
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatRequest represents the request body for /v1/chat/completions
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// chatChunk represents a single SSE chunk from /v1/chat/completions
+type chatChunk struct {
+	Choices []chunkChoice `json:"choices"`
+	Usage   *usage        `json:"usage,omitempty"`
+	Error   *apiError     `json:"error,omitempty"`
+}
+
+type chunkChoice struct {
+	Delta        chatMessage `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
+
+// usage represents token accounting reported by the server
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// modelsResponse represents the response from /v1/models
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// IsAvailable checks if the server is reachable by listing its models
+// Returns: true if the server answered /v1/models, error if not
+func (oc *OpenAIClient) IsAvailable() (bool, error) {
+	resp, err := oc.get("/models")
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to OpenAI-compatible server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("OpenAI-compatible server returned status %d", resp.StatusCode)
+	}
+
+	return true, nil
+}
+
+// Generate generates AI response with streaming. The context token slice is
+// not used by this protocol and is ignored.
+// Args:
+//
+//	prompt: string - user's prompt to the AI
+//	model: string - model name to use; servers hosting a single model accept any name
+//	context: []int - ignored
+//	onTokenUsage: callback invoked with actual token usage when stream completes
+//
+// Returns: channel for streaming response chunks, error if request fails
+func (oc *OpenAIClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	return oc.GenerateContext(stdcontext.Background(), prompt, model, onTokenUsage)
+}
+
+// GenerateContext generates AI response with streaming until ctx is done.
+// Cancelling ctx aborts the HTTP request and closes the returned channel.
+func (oc *OpenAIClient) GenerateContext(ctx stdcontext.Context, prompt string, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	return oc.Chat(ctx, []ai.Message{{Role: "user", Content: prompt}}, model, onTokenUsage)
+}
+
+// Chat generates AI response with streaming for a multi-turn conversation
+// Args:
+//
+//	ctx: context.Context - cancellation and deadline for the request
+//	messages: []ai.Message - conversation history, oldest first
+//	model: string - model name to use
+//	onTokenUsage: callback invoked with actual token usage when stream completes
+//
+// Returns: channel for streaming response chunks, error if request fails
+func (oc *OpenAIClient) Chat(ctx stdcontext.Context, messages []ai.Message, model string, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	reqBody := chatRequest{
+		Model:         model,
+		Messages:      make([]chatMessage, len(messages)),
+		Stream:        true,
+		StreamOptions: &streamOptions{IncludeUsage: true},
+	}
+	for i, msg := range messages {
+		reqBody.Messages[i] = chatMessage{Role: msg.Role, Content: msg.Content}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	oc.authorize(req)
+
+	// Use a client without timeout for streaming
+	streamClient := &http.Client{
+		Timeout: 0, // No timeout for streaming
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenAI-compatible API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	responseChan := make(chan string, 10)
+
+	go func() {
+		defer close(responseChan)
+		defer resp.Body.Close()
+
+		// Servers that do not support stream_options report no usage
+		var tokenUsage *types.TokenUsage
+		defer func() {
+			if onTokenUsage != nil && tokenUsage != nil {
+				onTokenUsage(*tokenUsage)
+			}
+		}()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			// SSE format: only "data:" lines carry payloads
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				return
+			}
+
+			var chunk chatChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				continue
+			}
+
+			if chunk.Error != nil {
+				send(ctx, responseChan, fmt.Sprintf("API Error: %s", chunk.Error.Message))
+				return
+			}
+
+			if chunk.Usage != nil {
+				tokenUsage = &types.TokenUsage{
+					InputTokens:  chunk.Usage.PromptTokens,
+					OutputTokens: chunk.Usage.CompletionTokens,
+					TotalTokens:  chunk.Usage.TotalTokens,
+				}
+				if tokenUsage.TotalTokens == 0 {
+					tokenUsage.TotalTokens = tokenUsage.InputTokens + tokenUsage.OutputTokens
+				}
+			}
+
+			for _, choice := range chunk.Choices {
+				if choice.Delta.Content == "" {
+					continue
+				}
+				if !send(ctx, responseChan, choice.Delta.Content) {
+					return
+				}
+			}
+		}
+
+		// A read error after cancellation is expected and not reported
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			responseChan <- fmt.Sprintf("Error reading response: %v", err)
+		}
+	}()
+
+	return responseChan, nil
+}
+
+// ListModels lists the models served at /v1/models
+// Returns: slice of model IDs, error if request fails
+func (oc *OpenAIClient) ListModels() ([]string, error) {
+	resp, err := oc.get("/models")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to OpenAI-compatible server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenAI-compatible API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var modelsResp modelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	modelNames := make([]string, len(modelsResp.Data))
+	for i, model := range modelsResp.Data {
+		modelNames[i] = model.ID
+	}
+
+	return modelNames, nil
+}
+
+// get issues an authorized GET request relative to the base URL
+func (oc *OpenAIClient) get(path string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", oc.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	oc.authorize(req)
+	return oc.httpClient.Do(req)
+}
+
+// authorize adds the bearer token when an API key is configured
+func (oc *OpenAIClient) authorize(req *http.Request) {
+	if oc.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+oc.apiKey)
+	}
+}
+
+// send delivers a chunk unless ctx is done first; it reports whether the
+// chunk was delivered
+func send(ctx stdcontext.Context, ch chan<- string, chunk string) bool {
+	select {
+	case ch <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,329 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// writeSSE writes each payload as an SSE data line and flushes it
+func writeSSE(w http.ResponseWriter, payloads ...string) {
+	for _, p := range payloads {
+		fmt.Fprintf(w, "data: %s\n\n", p)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+func deltaChunk(content string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{
+			{"delta": map[string]string{"content": content}, "finish_reason": nil},
+		},
+	})
+	return string(data)
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"":                           DefaultBaseURL,
+		"http://localhost:1234":      "http://localhost:1234/v1",
+		"http://localhost:1234/":     "http://localhost:1234/v1",
+		"http://localhost:1234/v1":   "http://localhost:1234/v1",
+		"https://api.openai.com/v1/": "https://api.openai.com/v1",
+		"https://generativelanguage.googleapis.com/v1beta/openai/": "https://generativelanguage.googleapis.com/v1beta/openai",
+		"https://example.openai.azure.com/openai/deployments/gpt":  "https://example.openai.azure.com/openai/deployments/gpt",
+	}
+	for in, want := range tests {
+		if got := normalizeBaseURL(in); got != want {
+			t.Errorf("normalizeBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerate_StreamsChunksAndReportsUsage(t *testing.T) {
+	var captured chatRequest
+	var auth string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("expected request to /v1/chat/completions, got %s", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeSSE(w,
+			deltaChunk("Hello"),
+			deltaChunk(", world"),
+			`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":4,"total_tokens":16}}`,
+			"[DONE]",
+		)
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL, "sk-test")
+
+	var usage types.TokenUsage
+	responseChan, err := client.Generate("Say hello", "local-model", nil, func(u types.TokenUsage) { usage = u })
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+
+	var fullResponse string
+	for chunk := range responseChan {
+		fullResponse += chunk
+	}
+
+	if fullResponse != "Hello, world" {
+		t.Errorf("expected 'Hello, world', got %q", fullResponse)
+	}
+	if auth != "Bearer sk-test" {
+		t.Errorf("expected bearer auth header, got %q", auth)
+	}
+	if captured.Model != "local-model" || !captured.Stream {
+		t.Errorf("unexpected request: %+v", captured)
+	}
+	if len(captured.Messages) != 1 || captured.Messages[0].Role != "user" || captured.Messages[0].Content != "Say hello" {
+		t.Errorf("unexpected messages: %+v", captured.Messages)
+	}
+	if captured.StreamOptions == nil || !captured.StreamOptions.IncludeUsage {
+		t.Error("expected stream_options.include_usage to be requested")
+	}
+	if usage.InputTokens != 12 || usage.OutputTokens != 4 || usage.TotalTokens != 16 {
+		t.Errorf("unexpected token usage: %+v", usage)
+	}
+}
+
+func TestChat_SendsConversationWithoutAPIKey(t *testing.T) {
+	var captured chatRequest
+	var auth string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		writeSSE(w, deltaChunk("Ada."), "[DONE]")
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL+"/v1", "")
+	responseChan, err := client.Chat(context.Background(), []ai.Message{
+		{Role: "user", Content: "My name is Ada."},
+		{Role: "assistant", Content: "Hi Ada."},
+		{Role: "user", Content: "What is my name?"},
+	}, "m", nil)
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+	full, _ := ai.Collect(context.Background(), responseChan)
+
+	if full != "Ada." {
+		t.Errorf("expected 'Ada.', got %q", full)
+	}
+	if auth != "" {
+		t.Errorf("expected no Authorization header without an API key, got %q", auth)
+	}
+	if len(captured.Messages) != 3 || captured.Messages[1].Role != "assistant" {
+		t.Errorf("unexpected messages: %+v", captured.Messages)
+	}
+}
+
+func TestChat_NoUsageReportedSkipsCallback(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w, deltaChunk("Hi"), "[DONE]")
+	}))
+	defer mockServer.Close()
+
+	called := false
+	client := NewOpenAIClient(mockServer.URL+"/v1", "")
+	responseChan, err := client.Chat(context.Background(), []ai.Message{{Role: "user", Content: "Hello"}}, "m",
+		func(types.TokenUsage) { called = true })
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+	ai.Collect(context.Background(), responseChan)
+
+	if called {
+		t.Error("expected no usage callback when the server reports no usage")
+	}
+}
+
+func TestChat_NonOKStatusReturnsError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"invalid api key"}}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL, "bad")
+	_, err := client.Generate("hi", "m", nil, nil)
+	if err == nil {
+		t.Fatal("expected error for 401 response")
+	}
+	if !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid api key") {
+		t.Errorf("error should include status and body, got: %v", err)
+	}
+}
+
+func TestChat_StreamErrorDeliveredThroughChannel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w, `{"error":{"message":"model not loaded","type":"server_error"}}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL, "")
+	responseChan, err := client.Generate("hi", "m", nil, nil)
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+
+	var fullResponse string
+	for chunk := range responseChan {
+		fullResponse += chunk
+	}
+	if fullResponse != "API Error: model not loaded" {
+		t.Errorf("unexpected response: %q", fullResponse)
+	}
+}
+
+func TestGenerateContext_CancelStopsStream(t *testing.T) {
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w, deltaChunk("partial "))
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer mockServer.Close()
+	defer close(release)
+
+	client := NewOpenAIClient(mockServer.URL, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	responseChan, err := client.GenerateContext(ctx, "hi", "m", nil)
+	if err != nil {
+		t.Fatalf("GenerateContext returned error: %v", err)
+	}
+
+	first := <-responseChan
+	if first != "partial " {
+		t.Fatalf("expected first chunk 'partial ', got %q", first)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-responseChan:
+		if ok {
+			// Drain any chunk racing with cancellation
+			for range responseChan {
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel was not closed after cancellation")
+	}
+}
+
+func TestListModels(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("expected request to /v1/models, got %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen2.5-coder"},{"id":"llama-3.1-8b"}]}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL, "")
+	models, err := client.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels returned error: %v", err)
+	}
+	if len(models) != 2 || models[0] != "qwen2.5-coder" || models[1] != "llama-3.1-8b" {
+		t.Errorf("unexpected models: %v", models)
+	}
+
+	available, err := client.IsAvailable()
+	if err != nil || !available {
+		t.Errorf("IsAvailable() = %v, %v; want true, nil", available, err)
+	}
+}
+
+func TestIsAvailable_ServerDown(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := mockServer.URL
+	mockServer.Close()
+
+	client := NewOpenAIClient(url, "")
+	available, err := client.IsAvailable()
+	if available || err == nil {
+		t.Errorf("IsAvailable() = %v, %v; want false and an error", available, err)
+	}
+}
+
+func TestChatTools_SendsToolsAndParsesCalls(t *testing.T) {
+	var captured toolChatRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("expected request to /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "", "tool_calls": [{"id": "abc", "type": "function", "function": {"name": "search", "arguments": "{\"pattern\":\"TODO\"}"}}]}}], "usage": {"prompt_tokens": 9, "completion_tokens": 3, "total_tokens": 12}}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL+"/v1", "")
+	reply, err := client.ChatTools(context.Background(), []ai.ToolMessage{
+		{Role: "user", Content: "find todos"},
+		{Role: "assistant", ToolCalls: []ai.ToolCall{{ID: "prev", Name: "list_dir", Arguments: json.RawMessage(`{}`)}}},
+		{Role: "tool", Results: []ai.ToolResult{{CallID: "prev", Name: "list_dir", Content: "main.go"}}},
+	}, []ai.ToolSpec{{Name: "search", Parameters: map[string]interface{}{"type": "object"}}}, "gpt-test")
+	if err != nil {
+		t.Fatalf("ChatTools returned error: %v", err)
+	}
+
+	if len(captured.Tools) != 1 || captured.Tools[0].Function.Name != "search" {
+		t.Errorf("unexpected tools in request: %+v", captured.Tools)
+	}
+	if len(captured.Messages) != 3 {
+		t.Fatalf("expected 3 messages in request, got %d", len(captured.Messages))
+	}
+	if call := captured.Messages[1].ToolCalls; len(call) != 1 || call[0].Function.Arguments != "{}" {
+		t.Errorf("expected arguments sent as a JSON string, got %+v", call)
+	}
+	if tool := captured.Messages[2]; tool.Role != "tool" || tool.ToolCallID != "prev" {
+		t.Errorf("unexpected tool result message: %+v", tool)
+	}
+
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].ID != "abc" || string(reply.ToolCalls[0].Arguments) != `{"pattern":"TODO"}` {
+		t.Fatalf("unexpected tool calls: %+v", reply.ToolCalls)
+	}
+	if reply.Usage.TotalTokens != 12 {
+		t.Errorf("unexpected token usage: %+v", reply.Usage)
+	}
+}
+
+func TestChatTools_ServerWithoutToolSupport(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error": {"message": "tools param requires --jinja flag"}}`)
+	}))
+	defer mockServer.Close()
+
+	client := NewOpenAIClient(mockServer.URL+"/v1", "")
+	_, err := client.ChatTools(context.Background(), []ai.ToolMessage{{Role: "user", Content: "hi"}}, nil, "local")
+	if !strings.Contains(err.Error(), ai.ErrToolsUnsupported.Error()) {
+		t.Fatalf("expected ErrToolsUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+)
+
+func init() {
+	ai.RegisterProvider(ai.ProviderSpec{
+		Name:        "openai",
+		Description: "OpenAI-compatible server (llama.cpp, vLLM, LM Studio, OpenAI)",
+		ModelKey:    "openai_model",
+		Fields: []ai.ConfigField{
+			{Key: "openai_model", Description: "Model ID as listed by /v1/models"},
+			{Key: "openai_url", Description: "Server base URL", Default: DefaultBaseURL},
+			{Key: "openai_api", Description: "API key (optional for local servers)", Secret: true},
+		},
+		Capabilities: ai.Capabilities{Chat: true, Cancel: true, ListModels: true, Tools: true},
+		Validate: func(settings ai.Settings) error {
+			url := settings.Get("openai_url")
+			if url != "" && !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+				return fmt.Errorf("openai_url must start with http:// or https://")
+			}
+			return nil
+		},
+		New: func(settings ai.Settings) (ai.AIClient, error) {
+			return NewOpenAIClient(settings.Get("openai_url"), settings.Get("openai_api")), nil
+		},
+	})
+}
@@ -0,0 +1,160 @@
+package openai
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// toolFunction describes a function in the "tools" list
+type toolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+// toolCall is a function call requested by the model; arguments are a JSON
+// encoded string in this protocol
+type toolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// toolChatMessage is a chat message that may carry tool calls or a tool result
+type toolChatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type toolChatRequest struct {
+	Model    string            `json:"model"`
+	Messages []toolChatMessage `json:"messages"`
+	Tools    []tool            `json:"tools"`
+}
+
+type toolChatResponse struct {
+	Choices []struct {
+		Message toolChatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *usage    `json:"usage,omitempty"`
+	Error *apiError `json:"error,omitempty"`
+}
+
+// ChatTools sends a tool-using conversation to /v1/chat/completions with
+// native tool definitions and returns the model's reply. Each tool result is
+// sent as a "tool" message referencing its call ID.
+func (oc *OpenAIClient) ChatTools(ctx stdcontext.Context, messages []ai.ToolMessage, tools []ai.ToolSpec, model string) (*ai.ToolReply, error) {
+	reqBody := toolChatRequest{Model: model}
+	for _, t := range tools {
+		reqBody.Tools = append(reqBody.Tools, tool{
+			Type:     "function",
+			Function: toolFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters},
+		})
+	}
+	for _, msg := range messages {
+		if msg.Role == "tool" {
+			for _, result := range msg.Results {
+				reqBody.Messages = append(reqBody.Messages, toolChatMessage{
+					Role:       "tool",
+					Content:    result.Content,
+					ToolCallID: result.CallID,
+				})
+			}
+			continue
+		}
+		m := toolChatMessage{Role: msg.Role, Content: msg.Content}
+		for _, call := range msg.ToolCalls {
+			var tc toolCall
+			tc.ID = call.ID
+			tc.Type = "function"
+			tc.Function.Name = call.Name
+			tc.Function.Arguments = string(call.Arguments)
+			m.ToolCalls = append(m.ToolCalls, tc)
+		}
+		reqBody.Messages = append(reqBody.Messages, m)
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	oc.authorize(req)
+
+	// Tool turns are not streamed but can take as long as a streamed answer
+	resp, err := (&http.Client{Timeout: 0}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("OpenAI-compatible API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		// Local servers reject the tools parameter when the loaded model or
+		// chat template has no tool support (e.g. llama.cpp without --jinja)
+		if resp.StatusCode != http.StatusUnauthorized && strings.Contains(strings.ToLower(string(body)), "tool") {
+			return nil, fmt.Errorf("%w: %v", ai.ErrToolsUnsupported, err)
+		}
+		return nil, err
+	}
+
+	var chatResp toolChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if chatResp.Error != nil {
+		return nil, fmt.Errorf("API Error: %s", chatResp.Error.Message)
+	}
+
+	reply := &ai.ToolReply{}
+	if chatResp.Usage != nil {
+		reply.Usage = types.TokenUsage{
+			InputTokens:  chatResp.Usage.PromptTokens,
+			OutputTokens: chatResp.Usage.CompletionTokens,
+			TotalTokens:  chatResp.Usage.TotalTokens,
+		}
+	}
+	if len(chatResp.Choices) == 0 {
+		return reply, nil
+	}
+
+	message := chatResp.Choices[0].Message
+	reply.Content = message.Content
+	for i, tc := range message.ToolCalls {
+		args := json.RawMessage(tc.Function.Arguments)
+		if !json.Valid(args) {
+			args = json.RawMessage("{}")
+		}
+		id := tc.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i+1)
+		}
+		reply.ToolCalls = append(reply.ToolCalls, ai.ToolCall{ID: id, Name: tc.Function.Name, Arguments: args})
+	}
+
+	return reply, nil
+}
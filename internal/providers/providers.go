@@ -0,0 +1,11 @@
+// Package providers links every built-in AI backend into the binary. Each
+// backend registers itself with the ai provider registry from its init
+// function; import this package for its side effects.
+package providers
+
+import (
+	_ "github.com/user/terminal-intelligence/internal/bedrock"
+	_ "github.com/user/terminal-intelligence/internal/gemini"
+	_ "github.com/user/terminal-intelligence/internal/ollama"
+	_ "github.com/user/terminal-intelligence/internal/openai"
+)
@@ -0,0 +1,118 @@
+// Package releasenotes drafts release notes by sending the commits of a
+// release to the configured AI model and asking for a Markdown summary.
+package releasenotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// Dir is the directory, relative to the repository root, where drafts are
+// written.
+const Dir = "release-notes"
+
+// ErrNoCommits is returned when a release has no commits to describe.
+var ErrNoCommits = errors.New("there are no commits since the previous tag")
+
+// instructions asks for Markdown release notes grouped by change type; the
+// release and its commits follow it in the prompt.
+const instructions = `Write release notes in Markdown for the release described below, based on its commits.
+
+Rules:
+- start with a level-one heading naming the release
+- follow with a one or two sentence summary of the release
+- group the changes under level-two headings such as Features, Fixes, Documentation and Other, leaving out empty groups
+- use the Conventional Commits type of a commit (feat, fix, docs, ...) to pick its group when it has one
+- write one bullet per user-visible change, merging commits that belong together and skipping merge commits and pure housekeeping
+- do not invent changes that are not in the commits
+
+Reply with the Markdown only, without code fences or commentary.
+`
+
+// BuildPrompt returns the prompt asking for release notes of version, the
+// release after previous, made of commits (newest first). The commit
+// section holds at most about budget tokens: commits are included newest
+// first until the budget is used up and the rest are only counted.
+func BuildPrompt(version, previous string, commits []git.CommitInfo, budget int) string {
+	var sb strings.Builder
+	sb.WriteString(instructions)
+
+	sb.WriteString("\nRelease: " + Title(version) + "\n")
+	if previous != "" {
+		sb.WriteString("Previous release: " + previous + "\n")
+	} else {
+		sb.WriteString("Previous release: none, this is the first release\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nCommits (%d, newest first):\n", len(commits)))
+	entries := make([]string, len(commits))
+	for i, c := range commits {
+		entries[i] = formatCommit(c)
+	}
+	n := ai.FitBudget(entries, budget)
+	for _, entry := range entries[:n] {
+		sb.WriteString(entry)
+	}
+	if n < len(entries) {
+		sb.WriteString(fmt.Sprintf("[... %d older commits omitted]\n", len(entries)-n))
+	}
+	return sb.String()
+}
+
+// formatCommit renders a commit for the prompt: its short hash and subject,
+// followed by its body indented.
+func formatCommit(c git.CommitInfo) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s %s\n", c.ShortHash(), c.Subject()))
+	_, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// Title returns the name of a release: its tag, or "Unreleased" for the
+// commits that are not tagged yet.
+func Title(version string) string {
+	if version == "" {
+		return "Unreleased"
+	}
+	return version
+}
+
+// FileName returns where the draft of version is written, relative to the
+// repository root. Slashes in tag names are replaced so every draft is a
+// single file in Dir.
+func FileName(version string) string {
+	name := "unreleased"
+	if version != "" {
+		name = strings.ReplaceAll(version, "/", "-")
+	}
+	return Dir + "/" + name + ".md"
+}
+
+// Generate asks the model for release notes of version and returns them
+// cleaned up. ErrNoCommits is returned when commits is empty.
+func Generate(ctx context.Context, client ai.AIClient, model, version, previous string, commits []git.CommitInfo, budget int) (string, error) {
+	if len(commits) == 0 {
+		return "", ErrNoCommits
+	}
+	return ai.GenerateText(ctx, client, model, BuildPrompt(version, previous, commits, budget), Clean, "release notes")
+}
+
+// Clean extracts the release notes from a model reply with ai.CleanReply
+// and ends them with a single newline.
+func Clean(reply string) string {
+	text := ai.CleanReply(reply)
+	if text == "" {
+		return ""
+	}
+	return text + "\n"
+}
@@ -0,0 +1,155 @@
+package releasenotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// mockClient is an AIClient that records the prompt and replies with a fixed
+// response.
+type mockClient struct {
+	reply  string
+	err    error
+	prompt string
+}
+
+func (m *mockClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	m.prompt = prompt
+	if m.err != nil {
+		return nil, m.err
+	}
+	ch := make(chan string, 1)
+	ch <- m.reply
+	close(ch)
+	return ch, nil
+}
+
+func (m *mockClient) IsAvailable() (bool, error)    { return true, nil }
+func (m *mockClient) ListModels() ([]string, error) { return nil, nil }
+
+// commit returns a CommitInfo with message and a hash derived from n.
+func commit(n int, message string) git.CommitInfo {
+	return git.CommitInfo{Hash: fmt.Sprintf("%040x", n), Message: message}
+}
+
+func TestBuildPrompt_IncludesReleaseAndCommits(t *testing.T) {
+	commits := []git.CommitInfo{
+		commit(2, "fix(parser): handle empty input\n\nEmpty files no longer crash.\n"),
+		commit(1, "feat: add export\n"),
+	}
+	prompt := BuildPrompt("v1.1.0", "v1.0.0", commits, ai.DefaultPromptTokenBudget)
+
+	for _, want := range []string{
+		"Release: v1.1.0",
+		"Previous release: v1.0.0",
+		"Commits (2, newest first)",
+		"- 0000000 fix(parser): handle empty input\n  Empty files no longer crash.\n",
+		"- 0000000 feat: add export\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "omitted") {
+		t.Error("a short history should not be truncated")
+	}
+}
+
+func TestBuildPrompt_FirstAndUnreleased(t *testing.T) {
+	prompt := BuildPrompt("", "", []git.CommitInfo{commit(1, "initial")}, ai.DefaultPromptTokenBudget)
+	if !strings.Contains(prompt, "Release: Unreleased") || !strings.Contains(prompt, "this is the first release") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+}
+
+func TestBuildPrompt_OmitsOldCommits(t *testing.T) {
+	var commits []git.CommitInfo
+	for i := 0; i < 100; i++ {
+		commits = append(commits, commit(i, fmt.Sprintf("feat: change number %d with a longer subject line", i)))
+	}
+	prompt := BuildPrompt("v2", "v1", commits, 100)
+	if !strings.Contains(prompt, "older commits omitted]") {
+		t.Error("expected old commits to be omitted")
+	}
+	if !strings.Contains(prompt, "change number 0 ") {
+		t.Error("the newest commit should be kept")
+	}
+	if strings.Contains(prompt, "change number 99 ") {
+		t.Error("the oldest commit should be omitted")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.0":      "release-notes/v1.2.0.md",
+		"release/2.0": "release-notes/release-2.0.md",
+		"":            "release-notes/unreleased.md",
+	}
+	for version, want := range tests {
+		if got := FileName(version); got != want {
+			t.Errorf("FileName(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	client := &mockClient{reply: "```markdown\n# v1.1.0\n\n## Fixes\n- Handle empty input  \n```"}
+	commits := []git.CommitInfo{commit(1, "fix: handle empty input")}
+
+	notes, err := Generate(context.Background(), client, "model", "v1.1.0", "v1.0.0", commits, ai.DefaultPromptTokenBudget)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if notes != "# v1.1.0\n\n## Fixes\n- Handle empty input\n" {
+		t.Errorf("unexpected notes %q", notes)
+	}
+	if !strings.Contains(client.prompt, "handle empty input") {
+		t.Error("the commits should be sent to the model")
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	commits := []git.CommitInfo{commit(1, "fix: x")}
+	ctx := context.Background()
+
+	if _, err := Generate(ctx, &mockClient{reply: "x"}, "model", "v1", "", nil, ai.DefaultPromptTokenBudget); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("expected ErrNoCommits, got %v", err)
+	}
+	if _, err := Generate(ctx, nil, "model", "v1", "", commits, ai.DefaultPromptTokenBudget); err == nil {
+		t.Error("expected an error without a client")
+	}
+	if _, err := Generate(ctx, &mockClient{err: errors.New("offline")}, "model", "v1", "", commits, ai.DefaultPromptTokenBudget); err == nil {
+		t.Error("expected the client error to be returned")
+	}
+	if _, err := Generate(ctx, &mockClient{reply: " \n```\n```"}, "model", "v1", "", commits, ai.DefaultPromptTokenBudget); err == nil {
+		t.Error("expected an error for an empty reply")
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"plain", "# v1\n\n- a", "# v1\n\n- a\n"},
+		{"fenced", "```md\n# v1\n```", "# v1\n"},
+		{"inner fences kept", "# v1\n\n```go\nx()\n```", "# v1\n\n```go\nx()\n```\n"},
+		{"trailing spaces", "# v1   \n- a\t\n\n", "# v1\n- a\n"},
+		{"empty", "  ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clean(tt.reply); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package search finds files and symbols in a workspace by fuzzy matching.
+// An Index lists the files of a workspace and the functions, types and
+// classes declared in its Go, Python and JavaScript sources; updating it
+// only re-reads the files whose size or modification time changed, so
+// queries stay fast on large repositories.
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scores of a match. Every matched rune earns matchScore; runs of adjacent
+// matches, matches at the start of a word and matches in the last path
+// element earn more, and every skipped rune between the first and the last
+// match costs gapPenalty.
+const (
+	matchScore       = 16
+	consecutiveBonus = 12
+	boundaryBonus    = 10
+	baseNameBonus    = 6
+	prefixBonus      = 20
+	gapPenalty       = 1
+	maxGapPenalty    = 30
+)
+
+// Match reports whether the runes of pattern occur in candidate in order,
+// ignoring case and spaces in the pattern. It returns a score, higher for
+// better matches, and the rune positions in candidate of the matched runes.
+// An empty pattern matches everything with a score of zero.
+func Match(pattern, candidate string) (score int, positions []int, ok bool) {
+	query := foldRunes([]rune(strings.ReplaceAll(pattern, " ", "")))
+	if len(query) == 0 {
+		return 0, nil, true
+	}
+	text := []rune(candidate)
+	lower := foldRunes(text)
+
+	// Find the end of the first match, then walk back from there for the
+	// latest start of a match
+	end := -1
+	for i, q := 0, 0; i < len(lower); i++ {
+		if lower[i] == query[q] {
+			q++
+			if q == len(query) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for i, q := end, len(query)-1; i >= 0; i-- {
+		if lower[i] == query[q] {
+			q--
+			if q < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	// From the start of the window, prefer word starts and runs over the
+	// earliest occurrence of each rune, as long as the rest still fits
+	positions = make([]int, 0, len(query))
+	for i, q := start, 0; q < len(query); q++ {
+		pos := -1
+		for j := i; j < len(lower); j++ {
+			if lower[j] != query[q] || !isSubsequence(query[q+1:], lower[j+1:]) {
+				continue
+			}
+			if pos < 0 {
+				pos = j
+			}
+			if isBoundary(text, j) || (len(positions) > 0 && j == positions[len(positions)-1]+1) {
+				pos = j
+				break
+			}
+		}
+		positions = append(positions, pos)
+		i = pos + 1
+	}
+
+	base := strings.LastIndexByte(candidate, '/')
+	baseStart := 0
+	if base >= 0 {
+		baseStart = utf8.RuneCountInString(candidate[:base+1])
+	}
+	for n, pos := range positions {
+		score += matchScore
+		if n > 0 && pos == positions[n-1]+1 {
+			score += consecutiveBonus
+		}
+		if isBoundary(text, pos) {
+			score += boundaryBonus
+		}
+		if pos >= baseStart {
+			score += baseNameBonus
+		}
+	}
+	if strings.HasPrefix(string(lower[baseStart:]), string(query)) {
+		score += prefixBonus
+	}
+	gaps := positions[len(positions)-1] - positions[0] + 1 - len(positions)
+	score -= min(gaps*gapPenalty, maxGapPenalty)
+	return score, positions, true
+}
+
+// isBoundary reports whether the rune at i starts a word: it is the first
+// rune, follows a separator, or is an upper case letter after a lower case
+// one.
+func isBoundary(text []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := text[i-1], text[i]
+	switch prev {
+	case '/', '\\', '_', '-', '.', ' ':
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// isSubsequence reports whether the runes of query occur in text in order.
+func isSubsequence(query, text []rune) bool {
+	q := 0
+	for i := 0; i < len(text) && q < len(query); i++ {
+		if text[i] == query[q] {
+			q++
+		}
+	}
+	return q == len(query)
+}
+
+// foldRunes lower cases each rune on its own, keeping the length.
+func foldRunes(text []rune) []rune {
+	folded := make([]rune, len(text))
+	for i, r := range text {
+		folded[i] = unicode.ToLower(r)
+	}
+	return folded
+}
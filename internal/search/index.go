@@ -0,0 +1,289 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/docgen"
+)
+
+// Symbol kinds.
+const (
+	KindFunction  = "func"
+	KindMethod    = "method"
+	KindStruct    = "struct"
+	KindInterface = "interface"
+	KindClass     = "class"
+)
+
+// Symbol is a declaration found in a source file.
+type Symbol struct {
+	Name      string // Declared name
+	Container string // Receiver or class of a method, empty otherwise
+	Kind      string // One of the Kind constants
+	Path      string // Slash-separated path relative to the workspace
+	Line      int    // 1-based line of the declaration
+}
+
+// FullName returns the name of the symbol qualified by its container, as
+// in "Client.Push".
+func (s Symbol) FullName() string {
+	if s.Container == "" {
+		return s.Name
+	}
+	return s.Container + "." + s.Name
+}
+
+// UpdateStats describes what an Update did.
+type UpdateStats struct {
+	Files   int // Files in the workspace
+	Parsed  int // Source files read for symbols because they were new or changed
+	Removed int // Source files dropped because they no longer exist
+}
+
+// indexedFile is the cached state of one source file.
+type indexedFile struct {
+	modTime time.Time
+	size    int64
+	symbols []Symbol
+}
+
+// Index lists the files and symbols of a workspace. It is safe for
+// concurrent use; queries see the state of the last completed Update.
+type Index struct {
+	root string
+
+	update sync.Mutex // Serializes updates
+
+	mu      sync.RWMutex
+	files   []string               // All files, sorted
+	sources map[string]indexedFile // Source files by path
+	symbols []Symbol               // Symbols of all source files, by path and line
+}
+
+// NewIndex creates an empty index of the workspace at root. Call Update to
+// fill it.
+func NewIndex(root string) *Index {
+	return &Index{root: root, sources: make(map[string]indexedFile)}
+}
+
+// Root returns the workspace directory of the index.
+func (x *Index) Root() string {
+	return x.root
+}
+
+// Update rescans the workspace. Files are listed with the rules of
+// docgen.ProjectAnalyzer, so ignored and dependency directories are left
+// out; only source files that are new or whose size or modification time
+// changed are parsed again. A file that no longer parses keeps the symbols
+// it had.
+func (x *Index) Update() (UpdateStats, error) {
+	x.update.Lock()
+	defer x.update.Unlock()
+
+	analyzer := docgen.NewProjectAnalyzer(x.root, nil)
+	discovered, err := analyzer.DiscoverFiles()
+	if err != nil {
+		return UpdateStats{}, err
+	}
+
+	x.mu.RLock()
+	previous := x.sources
+	x.mu.RUnlock()
+
+	stats := UpdateStats{Files: len(discovered.AllFiles)}
+	files := make([]string, 0, len(discovered.AllFiles))
+	sources := make(map[string]indexedFile, len(previous))
+	for _, rel := range discovered.AllFiles {
+		path := filepath.ToSlash(rel)
+		files = append(files, path)
+
+		analyze := analyzerFor(analyzer, path)
+		if analyze == nil {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(x.root, rel))
+		if err != nil {
+			continue
+		}
+		old, known := previous[path]
+		if known && old.modTime.Equal(info.ModTime()) && old.size == info.Size() {
+			sources[path] = old
+			continue
+		}
+
+		entry := indexedFile{modTime: info.ModTime(), size: info.Size(), symbols: old.symbols}
+		if structure, err := analyze(rel); err == nil {
+			entry.symbols = symbolsOf(structure, path)
+		}
+		sources[path] = entry
+		stats.Parsed++
+	}
+	for path := range previous {
+		if _, ok := sources[path]; !ok {
+			stats.Removed++
+		}
+	}
+	sort.Strings(files)
+
+	var symbols []Symbol
+	for _, path := range files {
+		symbols = append(symbols, sources[path].symbols...)
+	}
+
+	x.mu.Lock()
+	x.files = files
+	x.sources = sources
+	x.symbols = symbols
+	x.mu.Unlock()
+	return stats, nil
+}
+
+// analyzerFor returns the docgen extractor for the file at path, or nil if
+// its language is not supported.
+func analyzerFor(a *docgen.ProjectAnalyzer, path string) func(string) (*docgen.CodeStructure, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return a.AnalyzeGoFile
+	case ".py":
+		return a.AnalyzePythonFile
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		return a.AnalyzeJavaScriptFile
+	}
+	return nil
+}
+
+// symbolsOf lists the declarations of structure, which was read from path,
+// in line order.
+func symbolsOf(structure *docgen.CodeStructure, path string) []Symbol {
+	var symbols []Symbol
+	for _, f := range structure.Functions {
+		kind := KindFunction
+		if f.Receiver != "" {
+			kind = KindMethod
+		}
+		symbols = append(symbols, Symbol{Name: f.Name, Container: f.Receiver, Kind: kind, Path: path, Line: f.Line})
+	}
+	for _, s := range structure.Structs {
+		symbols = append(symbols, Symbol{Name: s.Name, Kind: KindStruct, Path: path, Line: s.Line})
+	}
+	for _, i := range structure.Interfaces {
+		symbols = append(symbols, Symbol{Name: i.Name, Kind: KindInterface, Path: path, Line: i.Line})
+	}
+	for _, c := range structure.Classes {
+		symbols = append(symbols, Symbol{Name: c.Name, Kind: KindClass, Path: path, Line: c.Line})
+		for _, m := range c.Methods {
+			symbols = append(symbols, Symbol{Name: m.Name, Container: c.Name, Kind: KindMethod, Path: path, Line: m.Line})
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool { return symbols[i].Line < symbols[j].Line })
+	return symbols
+}
+
+// Files returns the paths of the indexed files, slash-separated and
+// relative to the workspace, in sorted order.
+func (x *Index) Files() []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.files
+}
+
+// Symbols returns the indexed symbols ordered by path and line.
+func (x *Index) Symbols() []Symbol {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.symbols
+}
+
+// FileMatch is a file found by MatchFiles.
+type FileMatch struct {
+	Path      string // Slash-separated path relative to the workspace
+	Positions []int  // Rune positions of the matched query runes in Path
+	Score     int
+}
+
+// recencyBonus is the score added to the most recently used file; each
+// older file gets recencyStep less.
+const (
+	recencyBonus = 40
+	recencyStep  = 4
+)
+
+// MatchFiles returns up to limit files whose paths fuzzy-match query, best
+// first. recent lists recently used paths, most recent first; they rank
+// higher, and with an empty query they are listed before the other files.
+func (x *Index) MatchFiles(query string, recent []string, limit int) []FileMatch {
+	rank := make(map[string]int, len(recent))
+	for i, path := range recent {
+		if _, ok := rank[path]; !ok {
+			rank[path] = i
+		}
+	}
+
+	var matches []FileMatch
+	for _, path := range x.Files() {
+		score, positions, ok := Match(query, path)
+		if !ok {
+			continue
+		}
+		if r, ok := rank[path]; ok {
+			score += max(recencyBonus-r*recencyStep, recencyStep)
+		}
+		matches = append(matches, FileMatch{Path: path, Positions: positions, Score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		// Shorter paths are closer matches; without a query keep the
+		// sorted order
+		return query != "" && len(matches[i].Path) < len(matches[j].Path)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// SymbolMatch is a symbol found by MatchSymbols.
+type SymbolMatch struct {
+	Symbol
+	Positions []int // Rune positions of the matched query runes in FullName
+	Score     int
+}
+
+// MatchSymbols returns up to limit symbols whose names fuzzy-match query,
+// best first. The name is tried on its own first, then qualified by its
+// container, so "push" and "clientpush" both find Client.Push.
+func (x *Index) MatchSymbols(query string, limit int) []SymbolMatch {
+	var matches []SymbolMatch
+	for _, sym := range x.Symbols() {
+		score, positions, ok := Match(query, sym.Name)
+		if ok && sym.Container != "" {
+			offset := len([]rune(sym.Container)) + 1
+			for i := range positions {
+				positions[i] += offset
+			}
+		}
+		if !ok {
+			if score, positions, ok = Match(query, sym.FullName()); !ok {
+				continue
+			}
+		}
+		matches = append(matches, SymbolMatch{Symbol: sym, Positions: positions, Score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return query != "" && len(matches[i].Name) < len(matches[j].Name)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
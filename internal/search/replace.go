@@ -0,0 +1,188 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFileSize is the size above which FindInFiles skips a file.
+const maxFileSize = 2 << 20
+
+// Pattern describes what a Finder looks for.
+type Pattern struct {
+	Text          string // Literal text, or a regular expression when Regex is set
+	Regex         bool   // Whether Text is a regular expression; replacements may then use $1 or ${name}
+	CaseSensitive bool   // Whether case must match
+	WholeWord     bool   // Whether matches may not run into a word on either side
+}
+
+// Finder finds the matches of a Pattern in text and computes their
+// replacements.
+type Finder struct {
+	pattern Pattern
+	re      *regexp.Regexp
+}
+
+// NewFinder compiles p. Regular expressions use the RE2 syntax of the
+// regexp package, with ^ and $ matching at line boundaries.
+func NewFinder(p Pattern) (*Finder, error) {
+	if p.Text == "" {
+		return nil, fmt.Errorf("nothing to find")
+	}
+	expr := regexp.QuoteMeta(p.Text)
+	if p.Regex {
+		expr = "(?m)" + p.Text
+	}
+	if !p.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return &Finder{pattern: p, re: re}, nil
+}
+
+// TextMatch is a match in a text.
+type TextMatch struct {
+	Start  int   // Byte offset of the match
+	End    int   // Byte offset just after the match
+	Line   int   // 0-based line of Start
+	Col    int   // Byte column of Start in its line
+	groups []int // Offsets of the submatches, for expanding replacements
+}
+
+// FindAll returns the non-empty matches in text, in order.
+func (f *Finder) FindAll(text string) []TextMatch {
+	var matches []TextMatch
+	line, lineStart := 0, 0
+	for _, loc := range f.re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if start == end || (f.pattern.WholeWord && !wholeWord(text, start, end)) {
+			continue
+		}
+		line += strings.Count(text[lineStart:start], "\n")
+		if i := strings.LastIndexByte(text[:start], '\n'); i >= 0 {
+			lineStart = i + 1
+		}
+		matches = append(matches, TextMatch{Start: start, End: end, Line: line, Col: start - lineStart, groups: loc})
+	}
+	return matches
+}
+
+// wholeWord reports whether text[start:end] does not run into a word: an
+// end of the match that is a word rune must not be next to another one.
+func wholeWord(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:end])
+	if before, size := utf8.DecodeLastRuneInString(text[:start]); size > 0 && isWordRune(first) && isWordRune(before) {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(text[start:end])
+	if after, size := utf8.DecodeRuneInString(text[end:]); size > 0 && isWordRune(last) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+// isWordRune reports whether r is part of a word: a letter, digit or
+// underscore.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Replacement returns what m, a match in text, is replaced with. Regular
+// expression templates expand $1 and ${name} to the submatches; literal
+// templates are used as they are.
+func (f *Finder) Replacement(text string, m TextMatch, template string) string {
+	if !f.pattern.Regex {
+		return template
+	}
+	return string(f.re.ExpandString(nil, template, text, m.groups))
+}
+
+// ReplaceAll replaces every match in text with template and returns the
+// result and the number of replacements.
+func (f *Finder) ReplaceAll(text, template string) (string, int) {
+	matches := f.FindAll(text)
+	replacements := make([]string, len(matches))
+	for i, m := range matches {
+		replacements[i] = f.Replacement(text, m, template)
+	}
+	return Apply(text, matches, replacements), len(matches)
+}
+
+// Apply returns text with each of matches, which must be in order, replaced
+// by the replacement at the same index.
+func Apply(text string, matches []TextMatch, replacements []string) string {
+	var b strings.Builder
+	last := 0
+	for i, m := range matches {
+		b.WriteString(text[last:m.Start])
+		b.WriteString(replacements[i])
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// FileMatches are the matches of a pattern in one file.
+type FileMatches struct {
+	Path    string // Slash-separated path relative to the searched directory
+	Content string // Content the matches were found in
+	Matches []TextMatch
+}
+
+// FindInFiles searches the text files below root, in path order. It skips
+// the .git directory, whatever ignored reports as ignored (it may be nil),
+// binary files and files larger than 2 MiB.
+func FindInFiles(root string, ignored func(path string, isDir bool) bool, f *Finder) ([]FileMatches, error) {
+	var results []FileMatches
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are skipped, as in the other scans
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || (ignored != nil && ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || (ignored != nil && ignored(rel, false)) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+			return nil
+		}
+		content := string(data)
+		if matches := f.FindAll(content); len(matches) > 0 {
+			results = append(results, FileMatches{Path: rel, Content: content, Matches: matches})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, nil
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+// finder compiles p and fails the test on error.
+func finder(t *testing.T, p Pattern) *Finder {
+	t.Helper()
+	f, err := NewFinder(p)
+	if err != nil {
+		t.Fatalf("NewFinder(%+v) error = %v", p, err)
+	}
+	return f
+}
+
+func TestFinder_FindAll(t *testing.T) {
+	text := "Foo foo food\nbar_foo foo.Bar\n"
+	tests := []struct {
+		name    string
+		pattern Pattern
+		want    [][2]int // line and column of each match
+	}{
+		{"literal ignores case", Pattern{Text: "foo"}, [][2]int{{0, 0}, {0, 4}, {0, 8}, {1, 4}, {1, 8}}},
+		{"case sensitive", Pattern{Text: "Foo", CaseSensitive: true}, [][2]int{{0, 0}}},
+		{"whole word", Pattern{Text: "foo", WholeWord: true}, [][2]int{{0, 0}, {0, 4}, {1, 8}}},
+		{"literal dot is not a wildcard", Pattern{Text: "o.b"}, [][2]int{{1, 10}}},
+		{"regex", Pattern{Text: `fo+d?\b`, Regex: true}, [][2]int{{0, 0}, {0, 4}, {0, 8}, {1, 4}, {1, 8}}},
+		{"regex anchors at lines", Pattern{Text: `^\w+`, Regex: true}, [][2]int{{0, 0}, {1, 0}}},
+		{"whole word with punctuation edges", Pattern{Text: ".bar", WholeWord: true}, [][2]int{{1, 11}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][2]int
+			for _, m := range finder(t, tt.pattern).FindAll(text) {
+				got = append(got, [2]int{m.Line, m.Col})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinder_EmptyMatchesSkipped(t *testing.T) {
+	if m := finder(t, Pattern{Text: `x*`, Regex: true}).FindAll("abxxc"); len(m) != 1 || m[0].Start != 2 || m[0].End != 4 {
+		t.Errorf("expected only the non-empty match, got %+v", m)
+	}
+}
+
+func TestNewFinder_Errors(t *testing.T) {
+	if _, err := NewFinder(Pattern{}); err == nil {
+		t.Error("expected an error for an empty pattern")
+	}
+	if _, err := NewFinder(Pattern{Text: "(", Regex: true}); err == nil {
+		t.Error("expected an error for an invalid regular expression")
+	}
+	if _, err := NewFinder(Pattern{Text: "("}); err != nil {
+		t.Errorf("a literal parenthesis should be valid, got %v", err)
+	}
+}
+
+func TestFinder_ReplaceAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  Pattern
+		text     string
+		template string
+		want     string
+		count    int
+	}{
+		{"literal", Pattern{Text: "a.b"}, "a.b axb A.B", "c", "c axb c", 2},
+		{"literal keeps dollars", Pattern{Text: "x"}, "x", "$1", "$1", 1},
+		{"capture groups", Pattern{Text: `(\w+)=(\w+)`, Regex: true}, "a=1, b=2", "$2=$1", "1=a, 2=b", 2},
+		{"named groups", Pattern{Text: `(?P<key>\w+):`, Regex: true}, "name: x", "${key} =", "name = x", 1},
+		{"whole word", Pattern{Text: "id", WholeWord: true}, "id idx uid id", "ID", "ID idx uid ID", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := finder(t, tt.pattern).ReplaceAll(tt.text, tt.template)
+			if got != tt.want || count != tt.count {
+				t.Errorf("ReplaceAll() = %q, %d, want %q, %d", got, count, tt.want, tt.count)
+			}
+		})
+	}
+}
+
+func TestApply_SelectedMatches(t *testing.T) {
+	text := "one two one two one"
+	f := finder(t, Pattern{Text: "one"})
+	matches := f.FindAll(text)
+	got := Apply(text, []TextMatch{matches[0], matches[2]}, []string{"1", "3"})
+	if want := "1 two one two 3"; got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+}
+
+func TestFindInFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.go":            "package a // TODO\n",
+		"b/b.txt":         "nothing\ntodo: later\n",
+		"build/out.txt":   "TODO\n",
+		"data.bin":        "TODO\x00\x01",
+		".git/HEAD":       "TODO\n",
+		"notes/empty.txt": "",
+	})
+	ignored := func(path string, isDir bool) bool { return path == "build" && isDir }
+	results, err := FindInFiles(dir, ignored, finder(t, Pattern{Text: "todo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	if want := []string{"a.go", "b/b.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("FindInFiles() paths = %v, want %v", paths, want)
+	}
+	if m := results[1].Matches; len(m) != 1 || m[0].Line != 1 || m[0].Col != 0 {
+		t.Errorf("unexpected matches in b/b.txt: %+v", m)
+	}
+	if results[0].Content != "package a // TODO\n" {
+		t.Errorf("expected the searched content, got %q", results[0].Content)
+	}
+}
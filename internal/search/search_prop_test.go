@@ -0,0 +1,98 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+
+	"pgregory.net/rapid"
+)
+
+// Property: Match succeeds exactly when the pattern is a case-insensitive
+// subsequence of the candidate, and the positions it returns are increasing
+// and point at the pattern's runes.
+func TestProperty_MatchPositions(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		alphabet := rapid.SampledFrom([]rune("abcABC/._-xyz"))
+		candidate := string(rapid.SliceOfN(alphabet, 0, 30).Draw(t, "candidate"))
+		pattern := string(rapid.SliceOfN(alphabet, 0, 6).Draw(t, "pattern"))
+
+		_, positions, ok := Match(pattern, candidate)
+		query := foldRunes([]rune(pattern))
+		text := []rune(candidate)
+		if want := isSubsequence(query, foldRunes(text)); ok != want {
+			t.Fatalf("Match(%q, %q) ok = %v, want %v", pattern, candidate, ok, want)
+		}
+		if !ok {
+			return
+		}
+		if len(positions) != len(query) {
+			t.Fatalf("got %d positions for %d pattern runes", len(positions), len(query))
+		}
+		for i, pos := range positions {
+			if i > 0 && pos <= positions[i-1] {
+				t.Fatalf("positions %v are not increasing", positions)
+			}
+			if unicode.ToLower(text[pos]) != query[i] {
+				t.Fatalf("position %d of %q is %q, want %q", pos, candidate, text[pos], query[i])
+			}
+		}
+	})
+}
+
+// Property: an exact match of the whole base name never scores below a
+// candidate that merely contains the same runes scattered.
+func TestProperty_ExactBaseNameRanksFirst(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		name := rapid.StringMatching(`[a-z]{2,8}`).Draw(t, "name")
+		dir := rapid.StringMatching(`[a-z]{1,8}`).Draw(t, "dir")
+		scattered := strings.Join(strings.Split(name, ""), "x")
+
+		exact, _, ok1 := Match(name, dir+"/"+name+".go")
+		other, _, ok2 := Match(name, dir+"/"+scattered+".go")
+		if !ok1 || !ok2 {
+			t.Fatalf("%q should match both candidates", name)
+		}
+		if exact < other {
+			t.Fatalf("exact %d scored below scattered %d", exact, other)
+		}
+	})
+}
+
+// Property: replacing every match with the text it matched gives back the
+// original text, and the matches are in order and do not overlap.
+func TestProperty_ReplaceWithMatchIsIdentity(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		alphabet := rapid.SampledFrom([]rune("abAB _.\n"))
+		text := string(rapid.SliceOfN(alphabet, 0, 40).Draw(t, "text"))
+		p := Pattern{
+			Text:          string(rapid.SliceOfN(alphabet, 1, 3).Draw(t, "pattern")),
+			CaseSensitive: rapid.Bool().Draw(t, "case"),
+			WholeWord:     rapid.Bool().Draw(t, "word"),
+		}
+		f, err := NewFinder(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := f.FindAll(text)
+		for i, m := range matches {
+			if m.Start >= m.End || (i > 0 && m.Start < matches[i-1].End) {
+				t.Fatalf("matches %+v overlap or are empty", matches)
+			}
+			if !strings.EqualFold(text[m.Start:m.End], p.Text) {
+				t.Fatalf("match %q does not equal %q", text[m.Start:m.End], p.Text)
+			}
+		}
+
+		p.Regex = true
+		p.Text = regexp.QuoteMeta(p.Text)
+		rf, err := NewFinder(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, n := rf.ReplaceAll(text, "${0}"); got != text || n != len(matches) {
+			t.Fatalf("ReplaceAll with ${0} = %q (%d), want %q (%d)", got, n, text, len(matches))
+		}
+	})
+}
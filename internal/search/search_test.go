@@ -0,0 +1,206 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeFiles creates the files of a workspace in a temporary directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// update updates the index and fails the test on error.
+func update(t *testing.T, x *Index) UpdateStats {
+	t.Helper()
+	stats, err := x.Update()
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	return stats
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, candidate string
+		ok                 bool
+		positions          []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "a/b/c", true, []int{0, 2, 4}},
+		{"ABC", "abc.go", true, []int{0, 1, 2}},
+		{"cba", "abc", false, nil},
+		{"fm go", "internal/filemanager/filemanager.go", true, []int{21, 25, 33, 34}},
+		// Word starts are preferred over the first occurrence
+		{"gp", "internal/ui/gitpane.go", true, []int{12, 15}},
+		{"ct", "CommitTemplate", true, []int{0, 6}},
+	}
+	for _, tt := range tests {
+		_, positions, ok := Match(tt.pattern, tt.candidate)
+		if ok != tt.ok {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tt.pattern, tt.candidate, ok, tt.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("Match(%q, %q) positions = %v, want %v", tt.pattern, tt.candidate, positions, tt.positions)
+		}
+	}
+}
+
+func TestMatch_Ranking(t *testing.T) {
+	better := [][2]string{
+		// query, preferred candidate over the next one
+		{"app", "internal/ui/app.go"},
+		{"app", "internal/ui/aichat_prop.go"},
+		{"conf", "internal/config/config.go"},
+		{"conf", "docs/contributing/faq.md"},
+		{"gp", "internal/git/push.go"},
+		{"gp", "internal/ui/graph.go"},
+	}
+	for i := 0; i < len(better); i += 2 {
+		query := better[i][0]
+		s1, _, ok1 := Match(query, better[i][1])
+		s2, _, ok2 := Match(query, better[i+1][1])
+		if !ok1 || !ok2 {
+			t.Fatalf("%q should match both %q and %q", query, better[i][1], better[i+1][1])
+		}
+		if s1 <= s2 {
+			t.Errorf("%q: %q scored %d, not above %q with %d", query, better[i][1], s1, better[i+1][1], s2)
+		}
+	}
+}
+
+func TestIndex_FilesAndSymbols(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".gitignore":        "build.log\n",
+		"build.log":         "ignored\n",
+		"README.md":         "# demo\n",
+		"cmd/main.go":       "package main\n\nfunc main() {}\n",
+		"server/client.go":  "package server\n\ntype Client struct{}\n\nfunc (c *Client) Push() error { return nil }\n",
+		"scripts/deploy.py": "class Deployer:\n    def push(self):\n        pass\n",
+		"web/app.js":        "export function render() {\n}\n",
+		"vendor/lib/lib.go": "package lib\n\nfunc Vendored() {}\n",
+	})
+	x := NewIndex(dir)
+	stats := update(t, x)
+
+	wantFiles := []string{".gitignore", "README.md", "cmd/main.go", "scripts/deploy.py", "server/client.go", "web/app.js"}
+	if got := x.Files(); !reflect.DeepEqual(got, wantFiles) {
+		t.Errorf("Files() = %v, want %v", got, wantFiles)
+	}
+	if stats.Files != len(wantFiles) || stats.Parsed != 4 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	var names []string
+	for _, s := range x.Symbols() {
+		names = append(names, s.FullName())
+	}
+	wantNames := []string{"main", "Deployer", "Deployer.push", "Client", "Client.Push", "render"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("symbols = %v, want %v", names, wantNames)
+	}
+
+	matches := x.MatchSymbols("push", 0)
+	if len(matches) != 2 || matches[0].Kind != KindMethod || matches[0].Line == 0 {
+		t.Fatalf("unexpected matches for push: %+v", matches)
+	}
+	for _, m := range matches {
+		if full := []rune(m.FullName()); string(full[m.Positions[0]]) != "p" && string(full[m.Positions[0]]) != "P" {
+			t.Errorf("positions %v do not point into %q", m.Positions, m.FullName())
+		}
+	}
+	if m := x.MatchSymbols("clipush", 0); len(m) != 1 || m[0].FullName() != "Client.Push" || m[0].Path != "server/client.go" || m[0].Line != 5 {
+		t.Errorf("expected the qualified name to match Client.Push, got %+v", m)
+	}
+}
+
+func TestIndex_IncrementalUpdate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.go": "package a\n\nfunc One() {}\n",
+		"b.go": "package a\n\nfunc Two() {}\n",
+	})
+	x := NewIndex(dir)
+	if stats := update(t, x); stats.Parsed != 2 {
+		t.Fatalf("expected both files to be parsed, got %+v", stats)
+	}
+	if stats := update(t, x); stats.Parsed != 0 || stats.Removed != 0 {
+		t.Errorf("unchanged files should not be parsed again, got %+v", stats)
+	}
+
+	// Change one file, break another and remove nothing
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc Uno() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.go"), []byte("package a\n\nfunc Two( {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.Chtimes(filepath.Join(dir, name), later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := update(t, x); stats.Parsed != 2 {
+		t.Errorf("expected the changed files to be parsed, got %+v", stats)
+	}
+	var names []string
+	for _, s := range x.Symbols() {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Uno", "Two"}) {
+		t.Errorf("expected the new symbol and the last good one of the broken file, got %v", names)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	if stats := update(t, x); stats.Removed != 1 || len(x.Files()) != 1 {
+		t.Errorf("expected the removed file to be dropped, got %+v and %v", stats, x.Files())
+	}
+}
+
+func TestIndex_MatchFilesRecency(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"alpha.txt":     "",
+		"beta.txt":      "",
+		"docs/beta.txt": "",
+		"gamma.txt":     "",
+	})
+	x := NewIndex(dir)
+	update(t, x)
+
+	all := x.MatchFiles("", []string{"gamma.txt", "docs/beta.txt"}, 0)
+	var paths []string
+	for _, m := range all {
+		paths = append(paths, m.Path)
+	}
+	want := []string{"gamma.txt", "docs/beta.txt", "alpha.txt", "beta.txt"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("empty query = %v, want recent files first then %v", paths, want)
+	}
+
+	if m := x.MatchFiles("beta", nil, 0); len(m) != 2 || m[0].Path != "beta.txt" {
+		t.Errorf("expected the shorter path first, got %+v", m)
+	}
+	if m := x.MatchFiles("beta", []string{"docs/beta.txt"}, 0); len(m) != 2 || m[0].Path != "docs/beta.txt" {
+		t.Errorf("expected the recent file first, got %+v", m)
+	}
+	if m := x.MatchFiles("t", nil, 2); len(m) != 2 {
+		t.Errorf("expected the limit to apply, got %d matches", len(m))
+	}
+}
@@ -0,0 +1,400 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+)
+
+// quote describes a kind of string literal.
+type quote struct {
+	open, close string
+	multiline   bool // Whether the string may span lines
+	escapes     bool // Whether a backslash escapes the next rune
+	interpolate bool // Whether variables are expanded inside, as in "$x"
+}
+
+// codeLexer is a table-driven lexer for C-like and shell-like languages.
+//
+// States: 0 is plain code, 1 is inside a block comment and 2+i is inside
+// the multi-line string opened by quotes[i].
+type codeLexer struct {
+	lineComments   []string
+	commentAtWord  bool     // Line comments only start at the beginning of a word, as in shells
+	blockComment   []string // Open and close markers, empty when there are none
+	quotes         []quote  // Tried in order, so longer openers come first
+	keywords       map[string]bool
+	types          map[string]bool
+	literals       map[string]bool
+	foldCase       bool // Whether words are matched ignoring case
+	variables      bool // Whether $name is a variable
+	dashWords      bool // Whether words may contain dashes, as in Get-ChildItem
+	decorators     bool // Whether @name is a decorator
+	keyStrings     bool // Whether a string followed by ':' is a key, as in JSON
+	commandsAreFns bool // Whether dashed words are commands, highlighted as functions
+}
+
+// words returns a set of the space-separated words.
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
+
+// language returns a Language lexed by l, commented out with its first
+// line comment marker.
+func (l *codeLexer) language(name string) *Language {
+	lang := &Language{Name: name, lex: l.lex}
+	if len(l.lineComments) > 0 {
+		lang.LineComment = l.lineComments[0]
+	}
+	return lang
+}
+
+// hasPrefixAt reports whether line continues with prefix at i.
+func hasPrefixAt(line []rune, i int, prefix string) bool {
+	for _, r := range prefix {
+		if i >= len(line) || line[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// fill sets kinds[from:to] to kind.
+func fill(kinds []Kind, from, to int, kind Kind) {
+	for i := from; i < to && i < len(kinds); i++ {
+		kinds[i] = kind
+	}
+}
+
+// isWordStart reports whether r can start an identifier.
+func isWordStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isWordRune reports whether r can continue an identifier.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (l *codeLexer) lex(line []rune, kinds []Kind, state State) State {
+	i := 0
+	for i < len(line) {
+		switch {
+		case state == 1:
+			end, closed := l.blockCommentEnd(line, i)
+			fill(kinds, i, end, Comment)
+			if closed {
+				state = 0
+			}
+			i = end
+			continue
+		case state >= 2:
+			var closed bool
+			i, closed = l.stringBody(line, kinds, i, l.quotes[state-2])
+			if closed {
+				state = 0
+			}
+			continue
+		}
+
+		r := line[i]
+		if comment := l.lineCommentAt(line, i); comment {
+			fill(kinds, i, len(line), Comment)
+			return state
+		}
+		if len(l.blockComment) == 2 && hasPrefixAt(line, i, l.blockComment[0]) {
+			fill(kinds, i, i+len([]rune(l.blockComment[0])), Comment)
+			i += len([]rune(l.blockComment[0]))
+			state = 1
+			continue
+		}
+		if q, ok := l.quoteAt(line, i); ok {
+			start := i
+			fill(kinds, i, i+len([]rune(q.open)), String)
+			var closed bool
+			i, closed = l.stringBody(line, kinds, i+len([]rune(q.open)), q)
+			if !closed && q.multiline {
+				state = State(2 + l.quoteIndex(q))
+			}
+			if l.keyStrings && closed && nextNonSpace(line, i) == ':' {
+				fill(kinds, start, i, Key)
+			}
+			continue
+		}
+		if l.variables && r == '$' {
+			if end := variableEnd(line, i); end > i+1 {
+				fill(kinds, i, end, Variable)
+				i = end
+				continue
+			}
+		}
+		if l.decorators && r == '@' && i+1 < len(line) && isWordStart(line[i+1]) {
+			end := i + 1
+			for end < len(line) && (isWordRune(line[end]) || line[end] == '.') {
+				end++
+			}
+			fill(kinds, i, end, Function)
+			i = end
+			continue
+		}
+		if unicode.IsDigit(r) && (i == 0 || !isWordRune(line[i-1])) {
+			end := i
+			for end < len(line) && (isWordRune(line[end]) || line[end] == '.') {
+				end++
+			}
+			fill(kinds, i, end, Number)
+			i = end
+			continue
+		}
+		// Dashed words start with a dash in PowerShell operators such as -eq
+		dashed := l.dashWords && r == '-' && i+1 < len(line) && isWordStart(line[i+1]) && (i == 0 || unicode.IsSpace(line[i-1]))
+		if isWordStart(r) || dashed {
+			end := i + 1
+			for end < len(line) && (isWordRune(line[end]) || (l.dashWords && line[end] == '-' && end+1 < len(line) && isWordRune(line[end+1]))) {
+				end++
+			}
+			fill(kinds, i, end, l.wordKind(string(line[i:end]), nextNonSpace(line, end)))
+			i = end
+			continue
+		}
+		i++
+	}
+	return state
+}
+
+// wordKind classifies a word followed by the rune next.
+func (l *codeLexer) wordKind(word string, next rune) Kind {
+	key := word
+	if l.foldCase {
+		key = strings.ToLower(word)
+	}
+	switch {
+	case l.keywords[key]:
+		return Keyword
+	case l.types[key]:
+		return Type
+	case l.literals[key]:
+		return Literal
+	case next == '(':
+		return Function
+	case l.commandsAreFns && strings.Contains(word, "-"):
+		return Function
+	}
+	return Text
+}
+
+// lineCommentAt reports whether a line comment starts at i.
+func (l *codeLexer) lineCommentAt(line []rune, i int) bool {
+	if l.commentAtWord && i > 0 && !unicode.IsSpace(line[i-1]) {
+		return false
+	}
+	for _, prefix := range l.lineComments {
+		if hasPrefixAt(line, i, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockCommentEnd returns the index after the block comment close marker
+// found from i and true, or the line length and false if the comment goes
+// on.
+func (l *codeLexer) blockCommentEnd(line []rune, i int) (int, bool) {
+	closing := l.blockComment[1]
+	for j := i; j < len(line); j++ {
+		if hasPrefixAt(line, j, closing) {
+			return j + len([]rune(closing)), true
+		}
+	}
+	return len(line), false
+}
+
+// quoteAt returns the string literal opened at i, if any.
+func (l *codeLexer) quoteAt(line []rune, i int) (quote, bool) {
+	for _, q := range l.quotes {
+		if hasPrefixAt(line, i, q.open) {
+			return q, true
+		}
+	}
+	return quote{}, false
+}
+
+// quoteIndex returns the index of q in l.quotes.
+func (l *codeLexer) quoteIndex(q quote) int {
+	for i, other := range l.quotes {
+		if other == q {
+			return i
+		}
+	}
+	return 0
+}
+
+// stringBody marks the string content from i up to and including the
+// closing quote and returns the index after it and whether the string was
+// closed on this line.
+func (l *codeLexer) stringBody(line []rune, kinds []Kind, i int, q quote) (int, bool) {
+	for i < len(line) {
+		switch {
+		case q.escapes && line[i] == '\\':
+			fill(kinds, i, i+2, String)
+			i += 2
+		case hasPrefixAt(line, i, q.close):
+			end := i + len([]rune(q.close))
+			fill(kinds, i, end, String)
+			return end, true
+		case q.interpolate && line[i] == '$' && variableEnd(line, i) > i+1:
+			end := variableEnd(line, i)
+			fill(kinds, i, end, Variable)
+			i = end
+		default:
+			kinds[i] = String
+			i++
+		}
+	}
+	return min(i, len(line)), false
+}
+
+// variableEnd returns the index after the shell variable starting with the
+// '$' at i: $name, ${...}, $(...) is not a variable, and the special
+// parameters $1, $?, $@ and the like.
+func variableEnd(line []rune, i int) int {
+	j := i + 1
+	if j >= len(line) {
+		return j
+	}
+	switch r := line[j]; {
+	case r == '{':
+		for j < len(line) && line[j] != '}' {
+			j++
+		}
+		return min(j+1, len(line))
+	case isWordStart(r):
+		for j < len(line) && (isWordRune(line[j]) || (line[j] == ':' && j+1 < len(line) && isWordStart(line[j+1]))) {
+			j++
+		}
+		return j
+	case unicode.IsDigit(r) || strings.ContainsRune("@*#?$!-_", r):
+		return j + 1
+	}
+	return j
+}
+
+// nextNonSpace returns the first non-space rune from i, or 0 if there is
+// none.
+func nextNonSpace(line []rune, i int) rune {
+	for ; i < len(line); i++ {
+		if !unicode.IsSpace(line[i]) {
+			return line[i]
+		}
+	}
+	return 0
+}
+
+var (
+	doubleQuote = quote{open: `"`, close: `"`, escapes: true}
+	singleQuote = quote{open: `'`, close: `'`, escapes: true}
+)
+
+var goLanguage = (&codeLexer{
+	lineComments: []string{"//"},
+	blockComment: []string{"/*", "*/"},
+	quotes:       []quote{doubleQuote, singleQuote, {open: "`", close: "`", multiline: true}},
+	keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+		import interface map package range return select struct switch type var`),
+	types: words(`any bool byte comparable complex64 complex128 error float32 float64 int int8 int16 int32
+		int64 rune string uint uint8 uint16 uint32 uint64 uintptr
+		append cap clear close complex copy delete imag len make max min new panic print println real recover`),
+	literals: words("true false nil iota"),
+}).language("go")
+
+var pythonLanguage = (&codeLexer{
+	lineComments: []string{"#"},
+	quotes: []quote{
+		{open: `"""`, close: `"""`, multiline: true, escapes: true},
+		{open: `'''`, close: `'''`, multiline: true, escapes: true},
+		doubleQuote, singleQuote,
+	},
+	keywords: words(`and as assert async await break class continue def del elif else except finally for
+		from global if import in is lambda match case nonlocal not or pass raise return try while with yield`),
+	types: words(`bool bytes dict float frozenset int list object set str tuple type
+		abs all any enumerate filter isinstance len map max min open print range repr reversed sorted sum super zip
+		self cls`),
+	literals:   words("True False None"),
+	decorators: true,
+}).language("python")
+
+var bashLanguage = (&codeLexer{
+	lineComments:  []string{"#"},
+	commentAtWord: true,
+	quotes: []quote{
+		{open: `"`, close: `"`, multiline: true, escapes: true, interpolate: true},
+		{open: `'`, close: `'`, multiline: true},
+	},
+	keywords: words(`if then else elif fi case esac for while until do done in function select time
+		return exit break continue local export readonly declare typeset unset shift source`),
+	types: words(`echo printf read cd pwd test eval exec trap set alias cat grep sed awk find ls mkdir rm cp mv
+		chmod chown curl wget tar sudo apt-get git`),
+	literals:  words("true false"),
+	variables: true,
+	dashWords: true,
+}).language("bash")
+
+var powershellLanguage = (&codeLexer{
+	lineComments:  []string{"#"},
+	commentAtWord: true,
+	blockComment:  []string{"<#", "#>"},
+	quotes: []quote{
+		{open: `@"`, close: `"@`, multiline: true, interpolate: true},
+		{open: `@'`, close: `'@`, multiline: true},
+		{open: `"`, close: `"`, multiline: true, interpolate: true},
+		{open: `'`, close: `'`, multiline: true},
+	},
+	keywords: words(`begin break catch class continue data do dynamicparam else elseif end enum exit filter
+		finally for foreach from function if in param process return switch throw trap try until using while
+		-and -or -not -eq -ne -gt -ge -lt -le -like -notlike -match -notmatch -contains -in -is`),
+	types:          words("string int long bool double decimal array hashtable object pscustomobject datetime void"),
+	literals:       words("true false null"),
+	foldCase:       true,
+	variables:      true,
+	dashWords:      true,
+	commandsAreFns: true,
+}).language("powershell")
+
+// jsKeywords are the keywords shared by JavaScript and TypeScript.
+const jsKeywords = `async await break case catch class const continue debugger default delete do else export
+	extends finally for from function if import in instanceof let new of return static super switch this
+	throw try typeof var void while with yield get set`
+
+var javascriptLanguage = (&codeLexer{
+	lineComments: []string{"//"},
+	blockComment: []string{"/*", "*/"},
+	quotes:       []quote{doubleQuote, singleQuote, {open: "`", close: "`", multiline: true, escapes: true}},
+	keywords:     words(jsKeywords),
+	types:        words("Array Boolean Date Error JSON Map Math Number Object Promise RegExp Set String Symbol console"),
+	literals:     words("true false null undefined NaN Infinity"),
+	decorators:   true,
+}).language("javascript")
+
+var typescriptLanguage = (&codeLexer{
+	lineComments: []string{"//"},
+	blockComment: []string{"/*", "*/"},
+	quotes:       []quote{doubleQuote, singleQuote, {open: "`", close: "`", multiline: true, escapes: true}},
+	keywords: words(jsKeywords + ` abstract as declare enum implements interface keyof namespace private
+		protected public readonly type satisfies`),
+	types: words(`any bigint boolean never number object string symbol unknown
+		Array Boolean Date Error JSON Map Math Number Object Promise Record Partial RegExp Set String Symbol console`),
+	literals:   words("true false null undefined NaN Infinity"),
+	decorators: true,
+}).language("typescript")
+
+var jsonLanguage = (&codeLexer{
+	lineComments: []string{"//"},
+	blockComment: []string{"/*", "*/"},
+	quotes:       []quote{doubleQuote},
+	literals:     words("true false null"),
+	keyStrings:   true,
+}).language("json")
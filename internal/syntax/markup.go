@@ -0,0 +1,295 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+)
+
+// markdownLanguage lexes Markdown. State 1 is inside a fenced code block.
+var markdownLanguage = &Language{Name: "markdown", lex: lexMarkdown}
+
+func lexMarkdown(line []rune, kinds []Kind, state State) State {
+	text := strings.TrimSpace(string(line))
+	indent := leadingSpace(line)
+
+	if strings.HasPrefix(text, "```") || strings.HasPrefix(text, "~~~") {
+		fill(kinds, 0, len(line), Code)
+		if state == 1 {
+			return 0
+		}
+		return 1
+	}
+	if state == 1 {
+		fill(kinds, 0, len(line), Code)
+		return 1
+	}
+
+	switch {
+	case strings.HasPrefix(text, "#"):
+		level := len(text) - len(strings.TrimLeft(text, "#"))
+		if level <= 6 && (len(text) == level || text[level] == ' ') {
+			fill(kinds, 0, len(line), Heading)
+			return 0
+		}
+	case strings.HasPrefix(text, ">"):
+		fill(kinds, 0, len(line), Comment)
+		return 0
+	case text == "---" || text == "***" || text == "___":
+		fill(kinds, 0, len(line), Keyword)
+		return 0
+	}
+
+	// List bullets and numbers
+	start := indent
+	if marker := listMarker(line[indent:]); marker > 0 {
+		fill(kinds, indent, indent+marker, Keyword)
+		start = indent + marker
+	}
+	lexInline(line, kinds, start)
+	return 0
+}
+
+// leadingSpace returns the number of leading spaces and tabs of line.
+func leadingSpace(line []rune) int {
+	n := 0
+	for n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+		n++
+	}
+	return n
+}
+
+// listMarker returns the length of the list marker that starts text, such
+// as "- " or "12. ", or 0 if there is none.
+func listMarker(text []rune) int {
+	if len(text) >= 2 && strings.ContainsRune("-*+", text[0]) && text[1] == ' ' {
+		return 1
+	}
+	n := 0
+	for n < len(text) && unicode.IsDigit(text[n]) {
+		n++
+	}
+	if n > 0 && n+1 < len(text) && (text[n] == '.' || text[n] == ')') && text[n+1] == ' ' {
+		return n + 1
+	}
+	return 0
+}
+
+// lexInline marks code spans, emphasis and links in line from start.
+func lexInline(line []rune, kinds []Kind, start int) {
+	for i := start; i < len(line); {
+		switch r := line[i]; {
+		case r == '`':
+			if end := closingRun(line, i+1, "`"); end > 0 {
+				fill(kinds, i, end, Code)
+				i = end
+				continue
+			}
+		case r == '*' || r == '_':
+			delim := string(r)
+			if i+1 < len(line) && line[i+1] == r {
+				delim += delim
+			}
+			open := i + len(delim)
+			// Emphasis needs text right after the opener and an intraword
+			// underscore is not emphasis
+			if open < len(line) && !unicode.IsSpace(line[open]) && !(r == '_' && i > 0 && isWordRune(line[i-1])) {
+				if end := closingRun(line, open, delim); end > open+len(delim) {
+					fill(kinds, i, end, Emphasis)
+					i = end
+					continue
+				}
+			}
+		case r == '[':
+			if end := linkEnd(line, i); end > 0 {
+				fill(kinds, i, end, Link)
+				i = end
+				continue
+			}
+		case r == '<':
+			if end := closingRun(line, i+1, ">"); end > 0 && strings.Contains(string(line[i:end]), "://") {
+				fill(kinds, i, end, Link)
+				i = end
+				continue
+			}
+		}
+		i++
+	}
+}
+
+// closingRun returns the index after the first occurrence of delim at or
+// after i, or 0 if there is none.
+func closingRun(line []rune, i int, delim string) int {
+	for j := i; j < len(line); j++ {
+		if hasPrefixAt(line, j, delim) {
+			return j + len([]rune(delim))
+		}
+	}
+	return 0
+}
+
+// linkEnd returns the index after the link [text](target) or [text][ref]
+// starting at i, or 0 if there is none.
+func linkEnd(line []rune, i int) int {
+	close := closingRun(line, i+1, "]")
+	if close == 0 || close >= len(line) {
+		return 0
+	}
+	switch line[close] {
+	case '(':
+		return closingRun(line, close+1, ")")
+	case '[':
+		return closingRun(line, close+1, "]")
+	}
+	return 0
+}
+
+// yamlLanguage lexes YAML. States above 1 are inside a block scalar (| or >)
+// whose key is indented by state-2 columns.
+var yamlLanguage = &Language{Name: "yaml", LineComment: "#", lex: lexYAML}
+
+func lexYAML(line []rune, kinds []Kind, state State) State {
+	indent := leadingSpace(line)
+	if state >= 2 {
+		if indent == len(line) || indent > int(state-2) {
+			fill(kinds, indent, len(line), String)
+			return state
+		}
+		state = 0
+	}
+
+	text := strings.TrimSpace(string(line))
+	if text == "---" || text == "..." {
+		fill(kinds, 0, len(line), Keyword)
+		return 0
+	}
+
+	// Sequence dashes, then an optional key
+	i := indent
+	for i+1 < len(line) && line[i] == '-' && line[i+1] == ' ' {
+		kinds[i] = Keyword
+		i = i + 1 + leadingSpace(line[i+1:])
+	}
+	if i+1 == len(line) && line[i] == '-' {
+		kinds[i] = Keyword
+		return 0
+	}
+	keyIndent := i
+	if end := yamlKeyEnd(line, i); end > 0 {
+		fill(kinds, i, end, Key)
+		i = end + 1
+	}
+
+	// A block scalar indicator ends the value on this line
+	valueEnd := len(line)
+	for j := i; j < len(line); j++ {
+		if line[j] == '#' && (j == 0 || line[j-1] == ' ' || line[j-1] == '\t') {
+			valueEnd = j
+			break
+		}
+	}
+	value := strings.TrimSpace(string(line[i:valueEnd]))
+	if value != "" && (value[0] == '|' || value[0] == '>') && strings.Trim(value, "|>+-0123456789") == "" {
+		fill(kinds, i, valueEnd, Keyword)
+		fill(kinds, valueEnd, len(line), Comment)
+		return State(2 + keyIndent)
+	}
+	lexYAMLValue(line, kinds, i)
+	return 0
+}
+
+// yamlKeyEnd returns the index of the ':' ending the mapping key that
+// starts at i, or 0 if the line has no key there.
+func yamlKeyEnd(line []rune, i int) int {
+	if i >= len(line) || line[i] == '#' {
+		return 0
+	}
+	j := i
+	if line[i] == '"' || line[i] == '\'' {
+		end := closingRun(line, i+1, string(line[i]))
+		if end == 0 {
+			return 0
+		}
+		j = end
+	}
+	for ; j < len(line); j++ {
+		switch {
+		case line[j] == ':' && (j+1 == len(line) || line[j+1] == ' '):
+			if j == i {
+				return 0
+			}
+			return j
+		case line[j] == '#' && j > i && line[j-1] == ' ':
+			return 0
+		case strings.ContainsRune("[]{},", line[j]) && j == i:
+			return 0
+		}
+	}
+	return 0
+}
+
+// yamlLiterals are the plain scalars highlighted as constants.
+var yamlLiterals = words("true false yes no on off null ~ True False Yes No On Off Null TRUE FALSE NULL")
+
+// lexYAMLValue marks comments, quoted strings, anchors, tags and scalars in
+// the value part of a line, starting at i.
+func lexYAMLValue(line []rune, kinds []Kind, i int) {
+	for i < len(line) {
+		r := line[i]
+		switch {
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			fill(kinds, i, len(line), Comment)
+			return
+		case r == '"' || r == '\'':
+			end := closingRun(line, i+1, string(r))
+			if end == 0 {
+				end = len(line)
+			}
+			fill(kinds, i, end, String)
+			i = end
+			continue
+		case r == '&' || r == '*' || r == '!':
+			end := i + 1
+			for end < len(line) && !unicode.IsSpace(line[end]) && !strings.ContainsRune(",[]{}", line[end]) {
+				end++
+			}
+			if end > i+1 {
+				kind := Variable
+				if r == '!' {
+					kind = Type
+				}
+				fill(kinds, i, end, kind)
+				i = end
+				continue
+			}
+		case !unicode.IsSpace(r) && !strings.ContainsRune(",[]{}:", r):
+			end := i
+			for end < len(line) && !strings.ContainsRune(",[]{}", line[end]) && !(line[end] == ' ' && end+1 < len(line) && line[end+1] == '#') {
+				end++
+			}
+			scalar := strings.TrimSpace(string(line[i:end]))
+			switch {
+			case yamlLiterals[scalar]:
+				fill(kinds, i, i+len([]rune(scalar)), Literal)
+			case isNumber(scalar):
+				fill(kinds, i, i+len([]rune(scalar)), Number)
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+}
+
+// isNumber reports whether s is a YAML integer or float.
+func isNumber(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" || !unicode.IsDigit(rune(s[0])) && s[0] != '.' {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) && !strings.ContainsRune("._eExXoOabcdefABCDEF+-", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+// Package syntax tokenizes source code for highlighting. Each supported
+// language is lexed one line at a time; a State carries multi-line
+// constructs such as block comments and raw strings from one line to the
+// next, so a file is highlighted by feeding its lines in order.
+package syntax
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Kind is the highlighting class of a piece of text.
+type Kind uint8
+
+const (
+	Text     Kind = iota // Anything without a class of its own
+	Keyword              // Language keywords and markup markers such as list bullets
+	Type                 // Built-in types and type-like names
+	Function             // Function and command names, decorators
+	String               // String and character literals
+	Number               // Numeric literals
+	Comment              // Comments and Markdown block quotes
+	Literal              // Constants such as true, false and nil
+	Variable             // Shell and PowerShell variables, YAML anchors
+	Key                  // JSON and YAML mapping keys
+	Heading              // Markdown headings
+	Emphasis             // Markdown bold and italic text
+	Link                 // Markdown links
+	Code                 // Markdown code spans and fenced blocks
+
+	numKinds
+)
+
+// State is the lexer state at the end of a line: 0 outside of any multi-line
+// construct, otherwise a language-specific value to pass with the next line.
+type State int
+
+// Language tokenizes the lines of one language.
+type Language struct {
+	Name        string
+	LineComment string // Marker that starts a line comment, empty when there is none
+
+	// lex classifies each rune of line into kinds, which has the same
+	// length, starting in state, and returns the state after the line.
+	lex func(line []rune, kinds []Kind, state State) State
+}
+
+// Line returns the kind of each rune of line, lexed starting in state, and
+// the state to lex the next line with.
+func (l *Language) Line(line string, state State) ([]Kind, State) {
+	runes := []rune(line)
+	kinds := make([]Kind, len(runes))
+	return kinds, l.lex(runes, kinds, state)
+}
+
+// Highlight returns the kind of each rune of each line of text, lexing the
+// lines in order. A nil language leaves every line without kinds.
+func Highlight(lang *Language, text string) [][]Kind {
+	lines := strings.Split(text, "\n")
+	kinds := make([][]Kind, len(lines))
+	if lang == nil {
+		return kinds
+	}
+	var state State
+	for i, line := range lines {
+		kinds[i], state = lang.Line(line, state)
+	}
+	return kinds
+}
+
+// languages maps language names and their common aliases, as used after a
+// Markdown code fence, to languages.
+var languages = map[string]*Language{}
+
+// extensions maps file extensions to language names.
+var extensions = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".pyw":  "python",
+	".sh":   "bash",
+	".bash": "bash",
+	".zsh":  "bash",
+	".ps1":  "powershell",
+	".psm1": "powershell",
+	".psd1": "powershell",
+	".js":   "javascript",
+	".mjs":  "javascript",
+	".cjs":  "javascript",
+	".jsx":  "javascript",
+	".ts":   "typescript",
+	".mts":  "typescript",
+	".cts":  "typescript",
+	".tsx":  "typescript",
+	".md":   "markdown",
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+}
+
+// register adds lang under its name and aliases.
+func register(lang *Language, aliases ...string) {
+	languages[lang.Name] = lang
+	for _, alias := range aliases {
+		languages[alias] = lang
+	}
+}
+
+func init() {
+	register(goLanguage, "golang")
+	register(pythonLanguage, "py", "python3")
+	register(bashLanguage, "sh", "shell", "zsh", "console", "shell-session")
+	register(powershellLanguage, "ps1", "pwsh", "posh")
+	register(javascriptLanguage, "js", "jsx", "node", "mjs")
+	register(typescriptLanguage, "ts", "tsx")
+	register(markdownLanguage, "md")
+	register(jsonLanguage, "jsonc")
+	jsonLanguage.LineComment = "" // Comments are tolerated when lexing but are not JSON
+	register(yamlLanguage, "yml")
+}
+
+// Lookup returns the language with the given name or alias, ignoring case,
+// or nil if it is not supported. The info string of a Markdown code fence
+// may be passed as is: only its first word is used.
+func Lookup(name string) *Language {
+	fields := strings.Fields(strings.ToLower(name))
+	if len(fields) == 0 {
+		return nil
+	}
+	return languages[strings.Trim(fields[0], "{}.")]
+}
+
+// ForFile returns the language of the file at path, chosen by its
+// extension, or nil if it is not supported.
+func ForFile(path string) *Language {
+	name, ok := extensions[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil
+	}
+	return languages[name]
+}
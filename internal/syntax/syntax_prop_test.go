@@ -0,0 +1,55 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// fragments are pieces of source that open and close the constructs the
+// lexers track across lines
+var fragments = []string{
+	"\n", " ", "\t", "x", "42", "$v", "${v}", "@d", "\"", "'", "`", "\\", "#", "//", "/*", "*/",
+	"<#", "#>", "@\"", "\"@", "'''", "\"\"\"", "```", "- ", "key: ", "|", "**", "_", "[a](b)", "é",
+}
+
+// TestProperty_HighlightCoversEveryRune checks that every language returns
+// exactly one kind per rune of every line, whatever the input.
+func TestProperty_HighlightCoversEveryRune(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		parts := rapid.SliceOf(rapid.SampledFrom(fragments)).Draw(t, "parts")
+		text := strings.Join(parts, "")
+		name := rapid.SampledFrom([]string{"go", "python", "bash", "powershell", "javascript", "typescript", "markdown", "json", "yaml"}).Draw(t, "lang")
+
+		lines := strings.Split(text, "\n")
+		kinds := Highlight(Lookup(name), text)
+		if len(kinds) != len(lines) {
+			t.Fatalf("got %d lines of kinds for %d lines", len(kinds), len(lines))
+		}
+		for i, line := range lines {
+			if len(kinds[i]) != len([]rune(line)) {
+				t.Fatalf("line %q: got %d kinds for %d runes", line, len(kinds[i]), len([]rune(line)))
+			}
+			for _, k := range kinds[i] {
+				if k >= numKinds {
+					t.Fatalf("line %q: invalid kind %d", line, k)
+				}
+			}
+		}
+	})
+}
+
+// TestProperty_RenderKeepsText checks that rendering without a theme
+// returns the text unchanged.
+func TestProperty_RenderKeepsText(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		text := strings.Join(rapid.SliceOf(rapid.SampledFrom(fragments)).Draw(t, "parts"), "")
+		for _, line := range strings.Split(text, "\n") {
+			kinds, _ := Lookup("go").Line(line, 0)
+			if got := LoadTheme(NoTheme).Render([]rune(line), kinds); got != line {
+				t.Fatalf("got %q, want %q", got, line)
+			}
+		}
+	})
+}
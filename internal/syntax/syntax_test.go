@@ -0,0 +1,190 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+// kindOf returns the kind of the runes of the first occurrence of word in
+// text, lexed as lang, failing the test when they differ
+func kindOf(t *testing.T, lang *Language, text, word string) Kind {
+	t.Helper()
+	lines := strings.Split(text, "\n")
+	kinds := Highlight(lang, text)
+	for i, line := range lines {
+		idx := strings.Index(line, word)
+		if idx < 0 {
+			continue
+		}
+		start := len([]rune(line[:idx]))
+		kind := kinds[i][start]
+		for j := start; j < start+len([]rune(word)); j++ {
+			if kinds[i][j] != kind {
+				t.Fatalf("%q is split between kinds %d and %d", word, kind, kinds[i][j])
+			}
+		}
+		return kind
+	}
+	t.Fatalf("%q not found in %q", word, text)
+	return Text
+}
+
+func TestHighlight_Languages(t *testing.T) {
+	tests := []struct {
+		lang string
+		text string
+		word string
+		want Kind
+	}{
+		{"go", "func main() {}", "func", Keyword},
+		{"go", "func main() {}", "main", Function},
+		{"go", "var n int = 42", "int", Type},
+		{"go", "var n int = 42", "42", Number},
+		{"go", `s := "a \" b" // note`, `"a \" b"`, String},
+		{"go", `s := "a \" b" // note`, "// note", Comment},
+		{"go", "return nil", "nil", Literal},
+		{"go", "x := `raw\nstill raw` + y", "still raw`", String},
+		{"go", "a /* one\ntwo */ b", "two */", Comment},
+		{"go", "a /* one\ntwo */ b", "b", Text},
+
+		{"python", "def f(self):\n    return None", "def", Keyword},
+		{"python", "def f(self):\n    return None", "None", Literal},
+		{"python", "@app.route\ndef f(): pass", "@app.route", Function},
+		{"python", "s = '''one\ntwo'''\nx = 1", "two'''", String},
+		{"python", "s = '''one\ntwo'''\nx = 1", "x", Text},
+		{"python", "x = 1  # comment", "# comment", Comment},
+
+		{"bash", `echo "hi $USER" # greet`, "echo", Type},
+		{"bash", `echo "hi $USER" # greet`, "$USER", Variable},
+		{"bash", `echo "hi $USER" # greet`, "# greet", Comment},
+		{"bash", `echo "hi $USER" # greet`, `"hi `, String},
+		{"bash", "if [ -f x ]; then exit 1; fi", "then", Keyword},
+		{"bash", "echo ${#list[@]} $1", "$1", Variable},
+		{"bash", "echo a#b", "a#b", Text},
+		{"bash", "echo 'no $expansion'", "$expansion", String},
+
+		{"powershell", "Get-ChildItem -Path $env:TEMP", "Get-ChildItem", Function},
+		{"powershell", "Get-ChildItem -Path $env:TEMP", "$env:TEMP", Variable},
+		{"powershell", "if ($a -EQ 1) { Write-Host 'x' }", "-EQ", Keyword},
+		{"powershell", "IF ($true) {}", "IF", Keyword},
+		{"powershell", "<# help\nmore #>\n$x = 1", "more #>", Comment},
+		{"powershell", "$s = @\"\nline $name\n\"@", "$name", Variable},
+
+		{"javascript", "const f = async () => await g()", "await", Keyword},
+		{"javascript", "const s = `a\n${x}`", "${x}`", String},
+		{"javascript", "if (x === undefined) {}", "undefined", Literal},
+		{"typescript", "interface User { name: string }", "interface", Keyword},
+		{"typescript", "interface User { name: string }", "string", Type},
+		{"typescript", "@Component({})\nclass A {}", "@Component", Function},
+
+		{"json", `{"name": "ti", "ok": true, "n": -1.5}`, `"name"`, Key},
+		{"json", `{"name": "ti", "ok": true, "n": -1.5}`, `"ti"`, String},
+		{"json", `{"name": "ti", "ok": true, "n": -1.5}`, "true", Literal},
+		{"json", `{"name": "ti", "ok": true, "n": -1.5}`, "1.5", Number},
+
+		{"yaml", "name: ti # app", "name", Key},
+		{"yaml", "name: ti # app", "# app", Comment},
+		{"yaml", "- enabled: yes", "-", Keyword},
+		{"yaml", "- enabled: yes", "enabled", Key},
+		{"yaml", "- enabled: yes", "yes", Literal},
+		{"yaml", "port: 8080", "8080", Number},
+		{"yaml", "base: &base\nother: *base", "*base", Variable},
+		{"yaml", "tag: !Ref x", "!Ref", Type},
+		{"yaml", "url: 'http://x'", "'http://x'", String},
+		{"yaml", "script: |\n  echo # not a comment\nnext: 1", "echo # not a comment", String},
+		{"yaml", "script: |\n  echo # not a comment\nnext: 1", "next", Key},
+
+		{"markdown", "## Install", "## Install", Heading},
+		{"markdown", "- run `make`", "-", Keyword},
+		{"markdown", "- run `make`", "`make`", Code},
+		{"markdown", "1. a **bold** move", "1.", Keyword},
+		{"markdown", "1. a **bold** move", "**bold**", Emphasis},
+		{"markdown", "see [docs](https://x.y)", "[docs](https://x.y)", Link},
+		{"markdown", "snake_case_name", "snake_case_name", Text},
+		{"markdown", "> quoted", "> quoted", Comment},
+		{"markdown", "```sh\n# not a heading\n```\n# Heading", "# not a heading", Code},
+		{"markdown", "```sh\n# not a heading\n```\n# Heading", "# Heading", Heading},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang+"/"+tt.word, func(t *testing.T) {
+			lang := Lookup(tt.lang)
+			if lang == nil {
+				t.Fatalf("no language %q", tt.lang)
+			}
+			if got := kindOf(t, lang, tt.text, tt.word); got != tt.want {
+				t.Errorf("%q in %q: got kind %d, want %d", tt.word, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := map[string]string{
+		"go":            "go",
+		"Golang":        "go",
+		"py":            "python",
+		"sh":            "bash",
+		"shell":         "bash",
+		"pwsh":          "powershell",
+		"ts":            "typescript",
+		"jsx":           "javascript",
+		"yml":           "yaml",
+		"json":          "json",
+		"md":            "markdown",
+		"python {.foo}": "python",
+	}
+	for name, want := range tests {
+		if lang := Lookup(name); lang == nil || lang.Name != want {
+			t.Errorf("Lookup(%q) = %v, want %s", name, lang, want)
+		}
+	}
+	for _, name := range []string{"", "  ", "cobol"} {
+		if Lookup(name) != nil {
+			t.Errorf("expected no language for %q", name)
+		}
+	}
+}
+
+func TestForFile(t *testing.T) {
+	tests := map[string]string{
+		"main.go":              "go",
+		"/a/b/script.SH":       "bash",
+		"deploy.ps1":           "powershell",
+		"app.tsx":              "typescript",
+		"index.mjs":            "javascript",
+		"README.md":            "markdown",
+		"package.json":         "json",
+		".github/ci.yml":       "yaml",
+		"tool.py":              "python",
+		"docker-compose.yaml":  "yaml",
+		"notes/todo.bash":      "bash",
+		"C:\\scripts\\run.ps1": "powershell",
+	}
+	for path, want := range tests {
+		if lang := ForFile(path); lang == nil || lang.Name != want {
+			t.Errorf("ForFile(%q) = %v, want %s", path, lang, want)
+		}
+	}
+	if ForFile("Makefile") != nil || ForFile("notes.txt") != nil {
+		t.Error("expected no language for unsupported files")
+	}
+}
+
+func TestHighlight_NilLanguage(t *testing.T) {
+	kinds := Highlight(nil, "a\nb")
+	if len(kinds) != 2 || kinds[0] != nil || kinds[1] != nil {
+		t.Errorf("expected two lines without kinds, got %v", kinds)
+	}
+}
+
+func TestLineComment(t *testing.T) {
+	tests := map[string]string{
+		"go": "//", "python": "#", "bash": "#", "powershell": "#", "javascript": "//",
+		"typescript": "//", "yaml": "#", "json": "", "markdown": "",
+	}
+	for name, want := range tests {
+		if got := Lookup(name).LineComment; got != want {
+			t.Errorf("%s: got line comment %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package syntax
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultTheme is the theme used when none or an unknown one is configured.
+const DefaultTheme = "monokai"
+
+// NoTheme turns highlighting off.
+const NoTheme = "none"
+
+// palette gives the color of each kind, as an ANSI 256 color number, and
+// the kinds drawn in bold or italic.
+type palette struct {
+	colors map[Kind]string
+	bold   []Kind
+	italic []Kind
+}
+
+// palettes holds the built-in themes by name.
+var palettes = map[string]palette{
+	"monokai": {
+		colors: map[Kind]string{
+			Keyword: "197", Type: "81", Function: "148", String: "186", Number: "141",
+			Comment: "242", Literal: "141", Variable: "208", Key: "197",
+			Heading: "197", Emphasis: "208", Link: "81", Code: "186",
+		},
+		bold:   []Kind{Heading},
+		italic: []Kind{Type, Comment, Emphasis},
+	},
+	"dracula": {
+		colors: map[Kind]string{
+			Keyword: "212", Type: "117", Function: "84", String: "228", Number: "141",
+			Comment: "61", Literal: "141", Variable: "215", Key: "117",
+			Heading: "141", Emphasis: "228", Link: "117", Code: "84",
+		},
+		bold:   []Kind{Heading, Keyword},
+		italic: []Kind{Type, Emphasis},
+	},
+	"solarized-dark": {
+		colors: map[Kind]string{
+			Keyword: "64", Type: "136", Function: "33", String: "37", Number: "125",
+			Comment: "240", Literal: "166", Variable: "33", Key: "33",
+			Heading: "166", Emphasis: "61", Link: "33", Code: "37",
+		},
+		bold:   []Kind{Heading},
+		italic: []Kind{Comment, Emphasis},
+	},
+	"github-light": {
+		colors: map[Kind]string{
+			Keyword: "161", Type: "25", Function: "91", String: "24", Number: "25",
+			Comment: "245", Literal: "25", Variable: "130", Key: "25",
+			Heading: "25", Emphasis: "130", Link: "25", Code: "24",
+		},
+		bold:   []Kind{Heading, Keyword},
+		italic: []Kind{Comment, Emphasis},
+	},
+	NoTheme: {},
+}
+
+// Theme renders highlighted text.
+type Theme struct {
+	Name   string
+	styles [numKinds]*lipgloss.Style // nil for kinds drawn as plain text
+}
+
+// ThemeNames returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsTheme reports whether name, ignoring case, is a built-in theme.
+func IsTheme(name string) bool {
+	_, ok := palettes[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// LoadTheme returns the built-in theme called name, ignoring case, or the
+// default theme if there is none by that name.
+func LoadTheme(name string) Theme {
+	name = strings.ToLower(strings.TrimSpace(name))
+	p, ok := palettes[name]
+	if !ok {
+		name, p = DefaultTheme, palettes[DefaultTheme]
+	}
+
+	theme := Theme{Name: name}
+	for kind, color := range p.colors {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		if slices.Contains(p.bold, kind) {
+			style = style.Bold(true)
+		}
+		if slices.Contains(p.italic, kind) {
+			style = style.Italic(true)
+		}
+		theme.styles[kind] = &style
+	}
+	return theme
+}
+
+// Style returns the style of kind, which is unstyled when the theme leaves
+// the kind plain.
+func (t Theme) Style(kind Kind) lipgloss.Style {
+	if int(kind) < len(t.styles) && t.styles[kind] != nil {
+		return *t.styles[kind]
+	}
+	return lipgloss.NewStyle()
+}
+
+// Render returns text with each run of runes of the same kind styled.
+// kinds holds the kind of each rune of text; runes past its end are plain.
+func (t Theme) Render(text []rune, kinds []Kind) string {
+	var sb strings.Builder
+	for start := 0; start < len(text); {
+		kind := kindAt(kinds, start)
+		end := start + 1
+		for end < len(text) && kindAt(kinds, end) == kind {
+			end++
+		}
+		run := string(text[start:end])
+		if style := t.styles[kind]; style != nil && strings.TrimSpace(run) != "" {
+			run = style.Render(run)
+		}
+		sb.WriteString(run)
+		start = end
+	}
+	return sb.String()
+}
+
+// kindAt returns kinds[i], or Text past the end of kinds.
+func kindAt(kinds []Kind, i int) Kind {
+	if i < len(kinds) {
+		return kinds[i]
+	}
+	return Text
+}
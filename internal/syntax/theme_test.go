@@ -0,0 +1,61 @@
+package syntax
+
+import "testing"
+
+func TestLoadTheme(t *testing.T) {
+	if got := LoadTheme("Dracula").Name; got != "dracula" {
+		t.Errorf("expected names to ignore case, got %s", got)
+	}
+	for _, name := range []string{"", "no-such-theme"} {
+		if got := LoadTheme(name).Name; got != DefaultTheme {
+			t.Errorf("LoadTheme(%q) = %s, want the default theme", name, got)
+		}
+	}
+	for _, name := range ThemeNames() {
+		if !IsTheme(name) || LoadTheme(name).Name != name {
+			t.Errorf("theme %s does not load", name)
+		}
+	}
+	if IsTheme("no-such-theme") {
+		t.Error("expected unknown themes to be rejected")
+	}
+}
+
+func TestTheme_Render(t *testing.T) {
+	text := []rune(`x := "hi"`)
+	kinds, _ := Lookup("go").Line(string(text), 0)
+
+	got := LoadTheme("monokai").Render(text, kinds)
+	want := "x := " + LoadTheme("monokai").Style(String).Render(`"hi"`)
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if plain := LoadTheme(NoTheme).Render(text, kinds); plain != string(text) {
+		t.Errorf("expected no styling without a theme, got %q", plain)
+	}
+}
+
+func TestTheme_RenderShortKinds(t *testing.T) {
+	theme := LoadTheme("monokai")
+	text := []rune("return value")
+	kinds := []Kind{Keyword, Keyword, Keyword, Keyword, Keyword, Keyword}
+
+	want := theme.Style(Keyword).Render("return") + " value"
+	if got := theme.Render(text, kinds); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTheme_Styles(t *testing.T) {
+	theme := LoadTheme("monokai")
+	if theme.Style(Keyword).GetForeground() == theme.Style(Text).GetForeground() {
+		t.Error("expected keywords to have a color of their own")
+	}
+	if !theme.Style(Heading).GetBold() {
+		t.Error("expected headings to be bold")
+	}
+	if LoadTheme(NoTheme).Style(Keyword).GetForeground() != theme.Style(Text).GetForeground() {
+		t.Error("expected no colors without a theme")
+	}
+}
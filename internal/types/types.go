@@ -13,11 +13,12 @@ const (
 	EditorPaneType PaneType = iota
 	AIPaneType
 	AIResponsePaneType
+	FileTreePaneType
 )
 
 // AppConfig holds application configuration
 type AppConfig struct {
-	Provider      string `yaml:"provider"` // "ollama" or "gemini"
+	Provider      string `yaml:"provider"` // registered AI provider name, e.g. "ollama"
 	OllamaURL     string `yaml:"ollama_url"`
 	GeminiAPIKey  string `yaml:"gemini_api_key"`
 	BedrockAPIKey string `yaml:"bedrock_api_key"`
@@ -30,7 +31,12 @@ type AppConfig struct {
 	WorkspaceDir  string `yaml:"workspace_dir"`
 	AutoSave      bool   `yaml:"auto_save"`
 	Autonomous    bool   `yaml:"autonomous"`
+	Validation    bool   `yaml:"validation"` // compile-check files after saves and AI edits
 	TabSize       int    `yaml:"tab_size"`
+
+	// ProviderSettings holds settings of providers without a dedicated field
+	// above, keyed by their config.json key (e.g. "openai_url")
+	ProviderSettings map[string]string `yaml:"provider_settings"`
 }
 
 // DefaultConfig returns default application configuration
@@ -47,6 +53,7 @@ func DefaultConfig() *AppConfig {
 		WorkspaceDir: filepath.Join(homeDir, "ti-workspace"),
 		AutoSave:     false,
 		Autonomous:   false,
+		Validation:   true,
 		TabSize:      4,
 	}
 }
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"bufio"
+	stdcontext "context"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/atotto/clipboard"
@@ -19,6 +21,7 @@ import (
 	"github.com/user/terminal-intelligence/internal/ai"
 	"github.com/user/terminal-intelligence/internal/dirtracker"
 	"github.com/user/terminal-intelligence/internal/docgen"
+	"github.com/user/terminal-intelligence/internal/syntax"
 	"github.com/user/terminal-intelligence/internal/types"
 )
 
@@ -52,12 +55,15 @@ type AIChatPane struct {
 	inputBuffer       string                     // Current input being typed
 	aiClient          ai.AIClient                // AI service client
 	model             string                     // AI model to use
-	provider          string                     // "ollama" or "gemini"
+	provider          string                     // registered provider name, e.g. "ollama"
 	scrollOffset      int                        // Vertical scroll offset for responses
 	width             int                        // Pane width
 	height            int                        // Pane height
 	focused           bool                       // Whether this pane is focused
 	streaming         bool                       // Whether AI is currently generating
+	cancelStream      stdcontext.CancelFunc      // Stops the in-flight chat generation (nil when idle)
+	streamContent     string                     // Partial response received so far while streaming
+	streamStarted     time.Time                  // When the streaming response started
 	copyMode          bool                       // Whether in code block selection mode
 	viewMode          bool                       // Whether viewing a code block
 	codeBlocks        []string                   // Extracted code blocks from responses
@@ -91,16 +97,87 @@ type AIChatPane struct {
 	lastKeystrokeTime time.Time                  // Last keypress timestamp to detect rapid/terminal paste
 	sessionFile       string                     // File path for automated chat session saving
 	fileToOpen        string                     // File path to open in editor after doc generation
+	theme             syntax.Theme               // Highlighting theme for fenced code blocks
 }
 
 // AIResponseMsg is sent when AI response chunk is received.
-// Used for streaming AI responses from the AI client.
+// Used for streaming AI responses from the AI client. While Done is false,
+// Content holds the whole response received so far; the final message
+// (Done true) carries the complete response and token usage.
 type AIResponseMsg struct {
 	Content      string // Response content
 	Done         bool   // Whether generation is complete
 	InputTokens  int    // Actual input token count from API
 	OutputTokens int    // Actual output token count from API
 	TotalTokens  int    // Actual total token count from API
+	stream       *chatStream
+}
+
+// streamRenderInterval is the minimum time between re-renders of a streaming
+// response; chunks arriving faster are coalesced into one update.
+const streamRenderInterval = 50 * time.Millisecond
+
+// chatStream carries a streaming response from the goroutine reading the
+// provider's channel to the UI, following the DocPipelineMsg pattern: each
+// AIResponseMsg carries the stream so Update can chain the next read.
+type chatStream struct {
+	mu         sync.Mutex
+	content    strings.Builder
+	final      *AIResponseMsg
+	updated    chan struct{} // signalled (non-blocking) on new content or completion
+	lastRender time.Time     // only touched by the sequential read commands
+}
+
+func newChatStream() *chatStream {
+	return &chatStream{updated: make(chan struct{}, 1)}
+}
+
+// append records a chunk and wakes the pending read
+func (s *chatStream) append(chunk string) {
+	s.mu.Lock()
+	s.content.WriteString(chunk)
+	s.mu.Unlock()
+	s.signal()
+}
+
+// finish records the final message; the next read returns it
+func (s *chatStream) finish(msg AIResponseMsg) {
+	s.mu.Lock()
+	s.final = &msg
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *chatStream) signal() {
+	select {
+	case s.updated <- struct{}{}:
+	default:
+	}
+}
+
+// next returns a command that waits for the stream to change and reports
+// the response so far, at most once per streamRenderInterval.
+func (s *chatStream) next() tea.Cmd {
+	return func() tea.Msg {
+		<-s.updated
+
+		s.mu.Lock()
+		done := s.final != nil
+		s.mu.Unlock()
+		if !done {
+			if wait := streamRenderInterval - time.Since(s.lastRender); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.final != nil {
+			return *s.final
+		}
+		s.lastRender = time.Now()
+		return AIResponseMsg{Content: s.content.String(), stream: s}
+	}
 }
 
 // InsertCodeMsg is sent when user wants to insert code into editor.
@@ -213,6 +290,7 @@ type LanguageInstallResultMsg struct {
 // Used by documentation generation to open generated files.
 type OpenFileInEditorMsg struct {
 	FilePath string // Path to the file to open
+	Line     int    // 1-based line to move the cursor to, 0 for the top
 }
 
 // - *AIChatPane: Initialized AI chat pane
@@ -241,9 +319,17 @@ func NewAIChatPane(client ai.AIClient, model string, provider string, workspaceR
 		workspaceRoot:     workspaceRoot,
 		lastKeystrokeTime: time.Now(),
 		sessionFile:       "",
+		theme:             syntax.LoadTheme(syntax.DefaultTheme),
 	}
 }
 
+// SetTheme sets the theme used to highlight fenced code blocks in
+// responses. Unknown names fall back to the default theme and "none" turns
+// highlighting off.
+func (a *AIChatPane) SetTheme(name string) {
+	a.theme = syntax.LoadTheme(name)
+}
+
 // SetWorkingDir sets the directory from which commands should execute.
 func (a *AIChatPane) SetWorkingDir(dir string) {
 	if dir != "" {
@@ -314,8 +400,9 @@ func (a *AIChatPane) CheckAIAvailability() tea.Cmd {
 			return AIAvailabilityMsg{Available: false}
 		}
 
-		// For Ollama, also verify the specific model exists
-		if provider == "ollama" {
+		// For providers that serve locally pulled models (e.g. Ollama), also
+		// verify the specific model exists
+		if spec, ok := ai.LookupProvider(provider); ok && spec.Capabilities.VerifyModel {
 			models, err := client.ListModels()
 			if err != nil {
 				return AIAvailabilityMsg{Available: false}
@@ -332,13 +419,6 @@ func (a *AIChatPane) CheckAIAvailability() tea.Cmd {
 			}
 		}
 
-		// For Gemini, try listing models to confirm API key + model access
-		if provider == "gemini" {
-			// IsAvailable already checks API key; do a lightweight generate test
-			// by just confirming the client was created with a key.
-			// The real validation happens on first request.
-		}
-
 		return AIAvailabilityMsg{Available: true}
 	}
 }
@@ -359,7 +439,9 @@ func (a *AIChatPane) GetActiveArea() int {
 
 // SendMessage sends a message to the AI with optional code context.
 // Adds the user message to history and initiates streaming AI generation.
-// If context is provided, it's included in the prompt as a code block.
+// The whole conversation (excluding notifications) is sent so the model
+// remembers earlier turns; if context is provided, it's included with the
+// message as a code block.
 //
 // Parameters:
 //   - message: User's message
@@ -405,49 +487,67 @@ func (a *AIChatPane) SendMessage(message string, context string) tea.Cmd {
 
 	// --- Regular AI message path ---
 
-	// Build prompt with context if provided
-	prompt := message
-	if context != "" {
-		prompt = "Here is the current code:\n\n```\n" + context + "\n```\n\n" + message
-	}
+	// Build the conversation from history (including the user message just
+	// appended) so the model sees earlier turns, windowed to a token budget.
+	history := ai.BuildHistory(a.messages, ai.DefaultHistoryTokenBudget)
 
 	a.streaming = true
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	a.cancelStream = cancel
 
-	// Capture pointer to the last message (the user message we just appended)
-	// so the onTokenUsage callback can store token counts on the assistant message later.
-	msgIndex := len(a.messages) // The assistant message will be appended at this index after response
+	a.streamContent = ""
+	a.streamStarted = time.Now()
+	client := a.aiClient
+	model := a.model
 
-	// Return command to start streaming
-	return func() tea.Msg {
+	// Read the response in the background; the pane re-renders as chunks
+	// arrive and the final message carries the full text and token usage.
+	stream := newChatStream()
+	go func() {
 		var tokenUsage types.TokenUsage
 		onTokenUsage := func(usage types.TokenUsage) {
 			tokenUsage = usage
 		}
 
-		responseChan, err := a.aiClient.Generate(prompt, a.model, nil, onTokenUsage)
+		defer cancel()
+
+		responseChan, err := ai.Converse(ctx, client, history, model, onTokenUsage)
 		if err != nil {
-			return AIResponseMsg{
+			stream.finish(AIResponseMsg{
 				Content: "Error: " + err.Error(),
 				Done:    true,
-			}
+			})
+			return
 		}
 
-		// Collect streaming responses
-		var fullResponse strings.Builder
-		for chunk := range responseChan {
-			fullResponse.WriteString(chunk)
+		// Collect streaming responses, keeping whatever arrived before a stop
+		fullResponse, err := ai.CollectFunc(ctx, responseChan, stream.append)
+		if ai.IsCancelled(err) {
+			fullResponse = strings.TrimRight(fullResponse, "\n") + "\n\n[generation stopped by user]"
 		}
 
-		// Return complete response with token usage
-		_ = msgIndex // will be used when storing tokens on the ChatMessage
-		return AIResponseMsg{
-			Content:      fullResponse.String(),
+		stream.finish(AIResponseMsg{
+			Content:      fullResponse,
 			Done:         true,
 			InputTokens:  tokenUsage.InputTokens,
 			OutputTokens: tokenUsage.OutputTokens,
 			TotalTokens:  tokenUsage.TotalTokens,
-		}
+		})
+	}()
+
+	return stream.next()
+}
+
+// StopGeneration cancels the in-flight chat generation, if any. The partial
+// response is still delivered through the pending AIResponseMsg.
+// Returns true if a generation was stopped.
+func (a *AIChatPane) StopGeneration() bool {
+	if a.cancelStream == nil {
+		return false
 	}
+	a.cancelStream()
+	a.cancelStream = nil
+	return true
 }
 
 // DisplayResponse displays AI response in the chat pane.
@@ -474,6 +574,8 @@ func (a *AIChatPane) DisplayResponseWithTokens(response string, inputTokens, out
 	a.messages = append(a.messages, assistantMsg)
 	a.appendMessageToSessionLog(assistantMsg)
 	a.streaming = false
+	a.cancelStream = nil
+	a.streamContent = ""
 
 	// Extract code blocks from response
 	a.extractCodeBlocks()
@@ -826,6 +928,9 @@ func (a *AIChatPane) Update(msg tea.Msg) tea.Cmd {
 	case AIResponseMsg:
 		if msg.Done {
 			a.DisplayResponseWithTokens(msg.Content, msg.InputTokens, msg.OutputTokens, msg.TotalTokens)
+		} else if msg.stream != nil {
+			a.updateStreamContent(msg.Content)
+			return msg.stream.next()
 		}
 	case AINotificationMsg:
 		a.DisplayNotification(msg.Content)
@@ -1527,7 +1632,7 @@ func (a *AIChatPane) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 //   - int: Maximum scroll offset (0 if all content fits in visible area)
 func (a *AIChatPane) getMaxScroll() int {
 	totalLines := 0
-	for _, msg := range a.messages {
+	for _, msg := range a.displayedMessages() {
 		totalLines += a.countMessageLines(msg)
 	}
 
@@ -1553,6 +1658,30 @@ func (a *AIChatPane) getMaxScroll() int {
 	return maxScroll
 }
 
+// displayedMessages returns the conversation history followed by the partial
+// response that is still streaming, if any.
+func (a *AIChatPane) displayedMessages() []types.ChatMessage {
+	if a.streamContent == "" {
+		return a.messages
+	}
+	// Cap the capacity so appending never writes into a.messages' backing array
+	return append(a.messages[:len(a.messages):len(a.messages)], types.ChatMessage{
+		Role:      "assistant",
+		Content:   a.streamContent,
+		Timestamp: a.streamStarted,
+	})
+}
+
+// updateStreamContent shows the response received so far, following it to
+// the bottom unless the user has scrolled up to read earlier messages.
+func (a *AIChatPane) updateStreamContent(content string) {
+	following := a.scrollOffset >= a.getMaxScroll()
+	a.streamContent = content
+	if following {
+		a.scrollToBottom()
+	}
+}
+
 // scrollToBottom scrolls to the bottom of the conversation.
 // Sets scroll offset to maximum value, showing the most recent messages.
 func (a *AIChatPane) scrollToBottom() {
@@ -1666,7 +1795,24 @@ func (a *AIChatPane) renderMessage(msg types.ChatMessage) []string {
 	msgContent := strings.ReplaceAll(msg.Content, "\t", "    ")
 	contentLines := strings.Split(msgContent, "\n")
 
+	// Code in fenced blocks is highlighted by the language named after the
+	// opening fence, carrying the lexer state from line to line
+	inFence := false
+	var fenceLang *syntax.Language
+	var fenceState syntax.State
+
 	for _, line := range contentLines {
+		var kinds []syntax.Kind
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			fenceLang, fenceState = nil, 0
+			if inFence {
+				fenceLang = syntax.Lookup(strings.TrimPrefix(trimmed, "```"))
+			}
+		} else if inFence && fenceLang != nil && !msg.IsNotification {
+			kinds, fenceState = fenceLang.Line(line, fenceState)
+		}
+
 		if len(line) == 0 {
 			lines = append(lines, "")
 			continue
@@ -1674,9 +1820,16 @@ func (a *AIChatPane) renderMessage(msg types.ChatMessage) []string {
 
 		// Wrap long lines to prevent them from hiding or breaking layout
 		wrapped := wrapTextFast(line, contentWidth)
+		offset := 0
 		for _, wline := range wrapped {
 			if msg.IsNotification {
 				lines = append(lines, contentStyle.Render(wline))
+			} else if kinds != nil {
+				// Wrapping keeps every rune, so each piece takes the next kinds
+				runes := []rune(wline)
+				end := min(offset+len(runes), len(kinds))
+				lines = append(lines, a.theme.Render(runes, kinds[min(offset, end):end]))
+				offset += len(runes)
 			} else {
 				lines = append(lines, wline)
 			}
@@ -1784,7 +1937,11 @@ func (a *AIChatPane) View() string {
 		statusColor = "196" // red for unavailable
 	}
 	if a.streaming {
-		statusText = fmt.Sprintf("⚡ %s/%s  ⏳ generating...%s", a.provider, a.model, statsStr)
+		generating := "generating..."
+		if a.streamContent != "" {
+			generating = fmt.Sprintf("generating ~%d tokens", ai.EstimateTokens(a.streamContent))
+		}
+		statusText = fmt.Sprintf("⚡ %s/%s  ⏳ %s%s", a.provider, a.model, generating, statsStr)
 		statusColor = "15"
 	}
 	statusLine := lipgloss.NewStyle().
@@ -1831,12 +1988,13 @@ func (a *AIChatPane) View() string {
 	}
 
 	// Calculate total lines to determine scrollbar
+	displayed := a.displayedMessages()
 	totalLines := 0
-	for _, msg := range a.messages {
+	for _, msg := range displayed {
 		totalLines += a.countMessageLines(msg)
 	}
 	// Add expected lines for "AI is thinking..."
-	if a.streaming {
+	if a.streaming && a.streamContent == "" {
 		totalLines++
 	}
 
@@ -1866,7 +2024,7 @@ func (a *AIChatPane) View() string {
 	var renderedLines []string
 	currentLine := 0
 
-	for _, msg := range a.messages {
+	for _, msg := range displayed {
 		msgLines := a.renderMessage(msg)
 		for _, line := range msgLines {
 			if currentLine >= a.scrollOffset && len(renderedLines) < visibleLines {
@@ -1876,8 +2034,8 @@ func (a *AIChatPane) View() string {
 		}
 	}
 
-	// Add streaming indicator if streaming
-	if a.streaming {
+	// Add streaming indicator until the first chunk arrives
+	if a.streaming && a.streamContent == "" {
 		if len(renderedLines) < visibleLines {
 			renderedLines = append(renderedLines, lipgloss.NewStyle().
 				Foreground(lipgloss.Color("240")).
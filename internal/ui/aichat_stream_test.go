@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// chunkedAIClient streams its chunks one at a time, waiting for a signal
+// before each so tests can observe intermediate renders
+type chunkedAIClient struct {
+	chunks []string
+	step   chan struct{}
+}
+
+func (c *chunkedAIClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *chunkedAIClient) ListModels() ([]string, error) { return []string{"test-model"}, nil }
+
+func (c *chunkedAIClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, chunk := range c.chunks {
+			<-c.step
+			ch <- chunk
+		}
+		<-c.step
+		if onTokenUsage != nil {
+			onTokenUsage(types.TokenUsage{InputTokens: 3, OutputTokens: 5, TotalTokens: 8})
+		}
+	}()
+	return ch, nil
+}
+
+func TestSendMessage_RendersChunksAsTheyArrive(t *testing.T) {
+	client := &chunkedAIClient{
+		chunks: []string{"Here you go:\n", "```go\nfmt.Println(1)\n", "```\n"},
+		step:   make(chan struct{}),
+	}
+	pane := NewAIChatPane(client, "test-model", "ollama", "")
+	pane.SetSize(80, 40)
+
+	cmd := pane.SendMessage("print one", "")
+
+	// First chunk: the partial response is shown before the stream completes
+	client.step <- struct{}{}
+	msg, ok := cmd().(AIResponseMsg)
+	if !ok || msg.Done {
+		t.Fatalf("expected an in-progress AIResponseMsg, got %#v", msg)
+	}
+	cmd = pane.Update(msg)
+	if pane.streamContent != "Here you go:\n" {
+		t.Errorf("streamContent = %q", pane.streamContent)
+	}
+	if !strings.Contains(pane.View(), "Here you go:") {
+		t.Error("partial response is not rendered")
+	}
+	if len(pane.messages) != 1 {
+		t.Errorf("partial response must not be added to history yet, got %d messages", len(pane.messages))
+	}
+
+	// Remaining chunks and completion
+	for range client.chunks[1:] {
+		client.step <- struct{}{}
+	}
+	client.step <- struct{}{}
+	for {
+		msg, ok := cmd().(AIResponseMsg)
+		if !ok {
+			t.Fatalf("unexpected message %#v", msg)
+		}
+		cmd = pane.Update(msg)
+		if msg.Done {
+			break
+		}
+	}
+
+	if pane.streaming || pane.streamContent != "" {
+		t.Error("streaming state should be cleared once the response completes")
+	}
+	if len(pane.messages) != 2 {
+		t.Fatalf("expected user and assistant messages, got %d", len(pane.messages))
+	}
+	last := pane.messages[1]
+	if last.Content != strings.Join(client.chunks, "") {
+		t.Errorf("final content = %q", last.Content)
+	}
+	if last.OutputTokens != 5 || last.TotalTokens != 8 {
+		t.Errorf("token usage not recorded: %+v", last)
+	}
+	if len(pane.codeBlockInfos) != 1 {
+		t.Errorf("expected 1 extracted code block, got %d", len(pane.codeBlockInfos))
+	}
+}
+
+func TestChatStream_CoalescesChunks(t *testing.T) {
+	stream := newChatStream()
+	stream.append("a")
+	stream.append("b")
+	stream.append("c")
+
+	msg := stream.next()().(AIResponseMsg)
+	if msg.Done || msg.Content != "abc" {
+		t.Errorf("expected one update with all chunks, got %+v", msg)
+	}
+
+	start := time.Now()
+	stream.append("d")
+	msg = stream.next()().(AIResponseMsg)
+	if msg.Content != "abcd" {
+		t.Errorf("unexpected content %q", msg.Content)
+	}
+	if elapsed := time.Since(start); elapsed < streamRenderInterval/2 {
+		t.Errorf("updates were not throttled (%v between renders)", elapsed)
+	}
+
+	stream.finish(AIResponseMsg{Content: "abcd", Done: true})
+	if msg := stream.next()().(AIResponseMsg); !msg.Done {
+		t.Errorf("expected final message, got %+v", msg)
+	}
+}
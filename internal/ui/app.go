@@ -14,7 +14,9 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -23,21 +25,20 @@ import (
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/user/terminal-intelligence/internal/agentic"
 	"github.com/user/terminal-intelligence/internal/ai"
-	"github.com/user/terminal-intelligence/internal/bedrock"
 	"github.com/user/terminal-intelligence/internal/config"
 	"github.com/user/terminal-intelligence/internal/filemanager"
-	"github.com/user/terminal-intelligence/internal/gemini"
 	"github.com/user/terminal-intelligence/internal/git"
 	"github.com/user/terminal-intelligence/internal/installer"
-	"github.com/user/terminal-intelligence/internal/ollama"
+	"github.com/user/terminal-intelligence/internal/lsp"
 	"github.com/user/terminal-intelligence/internal/projectctx"
+	"github.com/user/terminal-intelligence/internal/search"
 	"github.com/user/terminal-intelligence/internal/types"
+	"github.com/user/terminal-intelligence/internal/validation"
 )
 
 // App is the main Bubble Tea application that orchestrates all components.
@@ -63,12 +64,20 @@ type App struct {
 	editorPane                *EditorPane                  // Left pane: code editor
 	aiPane                    *AIChatPane                  // Right pane: AI chat
 	gitPane                   *GitPane                     // Git operations popup overlay
+	reviewPane                *ReviewPane                  // Hunk-by-hunk review of previewed changes
+	fileTree                  *FileTree                    // Workspace file tree sidebar (Ctrl+E)
+	palette                   *Palette                     // Go to file / symbol popup (Ctrl+P, Alt+M)
+	replacePane               *ReplacePane                 // Preview of a project-wide replace
 	fileManager               *filemanager.FileManager     // File system operations
 	aiClient                  ai.AIClient                  // AI service client (Ollama or Gemini)
 	agenticFixer              *agentic.AgenticCodeFixer    // Autonomous code fixing orchestrator
 	projectFixer              *agentic.ProjectFixer        // Project-wide agentic fixer
 	agenticProjectFixer       *agentic.AgenticProjectFixer // Project-wide agentic fixer with retry loop
 	autonomousCreator         *agentic.AutonomousCreator   // Autonomous application builder
+	cancelAgent               context.CancelFunc           // Stops the running /fix session or AI conflict resolution (nil when idle)
+	cancelSuggestion          context.CancelFunc           // Stops the running commit message suggestion (nil when idle)
+	autonomousStepRunning     bool                         // Whether an AutonomousCreator step is in flight
+	autonomousState           agentic.CreatorState         // State of autonomousCreator after its last step; the creator is not read while a step runs
 	activePane                types.PaneType               // Currently focused pane
 	width                     int                          // Terminal width
 	height                    int                          // Terminal height
@@ -95,6 +104,11 @@ type App struct {
 	findIndex                 int                          // Current search result index
 	findResults               []struct{ line, col int }    // Line and column positions of search results
 	findReplaceMode           int                          // 0: find input, 1: replace input
+	findRegex                 bool                         // Whether the find text is a regular expression (Alt+R)
+	findCaseSensitive         bool                         // Whether find matches case (Alt+C)
+	findWholeWord             bool                         // Whether find only matches whole words (Alt+W)
+	replaceInProject          bool                         // Whether replace works on every workspace file (Alt+A)
+	findError                 string                       // Why the find text cannot be searched for
 	fileList                  []string                     // List of files for picker
 	folderList                []string                     // List of folders for picker
 	backupList                []string                     // List of backups for picker
@@ -111,8 +125,19 @@ type App struct {
 	searchResultIndex         int                          // Current index in searchResults
 	searchTerms               []string                     // Last search terms used
 	lastPreviewRequest        string                       // Original /project request from the last preview run
-	autonomousFileToOpen      string                       // File path to open after autonomous creation step
+	lastPreviewReport         *agentic.ChangeReport        // Changes of the last preview run, for /review
 	projectCtxCache           *projectctx.ContextCache     // Cache for project context metadata
+	validator                 *validation.Pipeline         // Compile checks after saves and AI edits (nil when disabled)
+	validationCh              chan ValidationMsg           // Validation output for the chat pane and editor gutter
+	selectionContext          string                       // Editor selection sent with the next chat message (Alt+S)
+	lsp                       *lsp.Manager                 // Language servers of the workspace (nil until a file they handle is open)
+	lspEvents                 *lspInbox                    // Diagnostics and server status from the language servers
+	lspOpenFiles              []string                     // Files open in buffers when the servers were last told
+	showRenamePrompt          bool                         // Whether the rename symbol prompt is showing (F2)
+	renameBuffer              string                       // Buffer for the new symbol name
+	output                    io.Writer                    // Program output; OSC 52 clipboard sequences are written to it
+	mouseReleased             bool                         // Whether the mouse is left to the terminal (F9)
+	progressCh                chan AgentProgressMsg        // Progress lines of agents running in commands
 }
 
 // New creates a new application instance with the provided configuration.
@@ -160,18 +185,10 @@ func New(config *types.AppConfig, buildNumber string) *App {
 	fm := filemanager.NewFileManager(config.WorkspaceDir)
 
 	// Create AI client based on provider
-	var aiClient ai.AIClient
-	if config.Provider == "gemini" {
-		aiClient = gemini.NewGeminiClient(config.GeminiAPIKey)
-	} else if config.Provider == "bedrock" {
-		client, err := bedrock.NewBedrockClient(config.BedrockAPIKey, config.BedrockRegion)
-		if err != nil {
-			// Log error but continue with nil client - will be caught by availability check
-			fmt.Fprintf(os.Stderr, "Failed to initialize Bedrock client: %v\n", err)
-		}
-		aiClient = client
-	} else {
-		aiClient = ollama.NewOllamaClient(config.OllamaURL)
+	aiClient, err := newAIClient(config)
+	if err != nil {
+		// Log error but continue with nil client - will be caught by availability check
+		fmt.Fprintf(os.Stderr, "Failed to initialize %s client: %v\n", config.Provider, err)
 	}
 
 	// Initialize AgenticCodeFixer
@@ -180,15 +197,27 @@ func New(config *types.AppConfig, buildNumber string) *App {
 	// Initialize ProjectFixer
 	projectFixer := agentic.NewProjectFixer(aiClient, config.DefaultModel)
 
-	// Initialize AgenticProjectFixer with a placeholder logger.
-	// The real notify function is wired up after the App struct is created (see below).
-	var fixNotify func(string)
-	fixLogger := agentic.NewActionLogger(func(msg string) {
-		if fixNotify != nil {
-			fixNotify(msg)
-		}
-	})
+	// Initialize AgenticProjectFixer with a logger that reports to the chat
+	// pane through Update, as the fixer runs in commands.
+	progressCh := make(chan AgentProgressMsg, agentProgressBuffer)
+	fixLogger := agentic.NewActionLogger(agentProgressNotifier(progressCh))
 	agenticProjectFixer := agentic.NewAgenticProjectFixer(aiClient, config.DefaultModel, fixLogger)
+	projectFixer.SetLogger(fixLogger)
+
+	// Initialize the validation pipeline for saves and AI edits
+	validationCh := make(chan ValidationMsg, validationBuffer)
+	var validator *validation.Pipeline
+	if config.Validation {
+		validator = newValidationPipeline(validationCh)
+	}
+	projectFixer.SetValidator(validator)
+	agenticProjectFixer.SetValidator(validator)
+	editorPane := NewEditorPane(fm)
+	editorPane.SetValidator(validator)
+	editorPane.SetTheme(config.EditorTheme)
+	editorPane.SetTabSize(config.TabSize)
+	aiPane := NewAIChatPane(aiClient, config.DefaultModel, config.Provider, config.WorkspaceDir)
+	aiPane.SetTheme(config.EditorTheme)
 
 	// Initialize GitClient and GitPane
 	gitClient := git.NewClient(config.WorkspaceDir)
@@ -201,9 +230,13 @@ func New(config *types.AppConfig, buildNumber string) *App {
 		agenticFixer:         agenticFixer,
 		projectFixer:         projectFixer,
 		agenticProjectFixer:  agenticProjectFixer,
-		editorPane:           NewEditorPane(fm),
-		aiPane:               NewAIChatPane(aiClient, config.DefaultModel, config.Provider, config.WorkspaceDir),
+		editorPane:           editorPane,
+		aiPane:               aiPane,
 		gitPane:              gitPane,
+		reviewPane:           NewReviewPane(fm),
+		fileTree:             NewFileTree(fm, config.WorkspaceDir),
+		palette:              NewPalette(config.WorkspaceDir),
+		replacePane:          NewReplacePane(fm),
 		autonomousCreator:    nil,
 		activePane:           types.EditorPaneType,
 		ready:                false,
@@ -214,11 +247,11 @@ func New(config *types.AppConfig, buildNumber string) *App {
 		searchResultIndex:    0,
 		searchTerms:          []string{},
 		projectCtxCache:      projectctx.NewContextCache(),
-	}
-
-	// Wire up the fix logger now that the App (and its aiPane) exist.
-	fixNotify = func(msg string) {
-		app.aiPane.DisplayNotification(msg)
+		validator:            validator,
+		validationCh:         validationCh,
+		lspEvents:            newLSPInbox(),
+		progressCh:           progressCh,
+		output:               &terminalOutput{File: os.Stdout},
 	}
 
 	return app
@@ -231,12 +264,41 @@ func (a *App) Init() tea.Cmd {
 	return tea.Batch(
 		a.aiPane.CheckAIAvailability(),
 		tea.EnableBracketedPaste,
+		tea.EnableMouseCellMotion,
 		func() tea.Msg {
 			return OpenWorkspacePickerMsg{}
 		},
+		waitForValidation(a.validationCh),
+		waitForLSP(a.lspEvents),
+		waitForAgentProgress(a.progressCh),
 	)
 }
 
+// agentProgressBuffer is the number of agent progress lines that may be
+// pending before the agent waits for the UI
+const agentProgressBuffer = 100
+
+// agentProgressNotifier returns a logger callback that hands each line to
+// Update through ch, for agents that run in commands.
+func agentProgressNotifier(ch chan AgentProgressMsg) func(string) {
+	return func(msg string) {
+		if ch != nil {
+			ch <- AgentProgressMsg{Content: msg}
+		}
+	}
+}
+
+// waitForAgentProgress returns a command that delivers the next agent
+// progress line. Update re-issues it after handling each line.
+func waitForAgentProgress(ch chan AgentProgressMsg) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
 // Update handles messages and updates application state.
 // This is the main message handler for the Bubble Tea Model interface.
 //
@@ -260,7 +322,7 @@ func (a *App) Init() tea.Cmd {
 //   - Ctrl+H: Toggle help
 //   - Tab: Switch between editor and AI panes
 //   - Ctrl+S: Save file
-//   - Ctrl+X: Close file
+//   - Ctrl+X: Close the file in the active tab
 //   - Ctrl+Enter: Send AI message with editor context
 //
 // Parameters:
@@ -270,6 +332,18 @@ func (a *App) Init() tea.Cmd {
 //   - tea.Model: Updated model (always returns *App)
 //   - tea.Cmd: Command to execute (can be nil or batched commands)
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := a.update(msg)
+	// The completion popup follows the cursor, and the language servers see
+	// every edit, whichever handler made it
+	if a.editorPane != nil {
+		a.editorPane.refreshCompletion()
+		a.syncLSP()
+	}
+	return model, cmd
+}
+
+// update handles msg for Update.
+func (a *App) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -304,28 +378,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Build JSONConfig from fields and values
 		jcfg := &config.JSONConfig{}
 		for i, field := range msg.Fields {
-			switch field {
-			case "agent":
-				jcfg.Agent = msg.Values[i]
-			case "model":
-				jcfg.Model = msg.Values[i]
-			case "gmodel":
-				jcfg.GModel = msg.Values[i]
-			case "bedrock_model":
-				jcfg.BedrockModel = msg.Values[i]
-			case "ollama_url":
-				jcfg.OllamaURL = msg.Values[i]
-			case "gemini_api":
-				jcfg.GeminiAPI = msg.Values[i]
-			case "bedrock_api":
-				jcfg.BedrockAPI = msg.Values[i]
-			case "bedrock_region":
-				jcfg.BedrockRegion = msg.Values[i]
-			case "workspace":
-				jcfg.Workspace = msg.Values[i]
-			case "autonomous":
-				jcfg.Autonomous = msg.Values[i]
-			}
+			jcfg.SetSetting(field, msg.Values[i])
 		}
 
 		// Validate config
@@ -351,18 +404,12 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		config.ApplyToAppConfig(jcfg, a.config)
 
 		// Reinitialize AI client if provider or settings changed
-		if a.config.Provider == "gemini" {
-			a.aiClient = gemini.NewGeminiClient(a.config.GeminiAPIKey)
-		} else if a.config.Provider == "bedrock" {
-			client, err := bedrock.NewBedrockClient(a.config.BedrockAPIKey, a.config.BedrockRegion)
-			if err != nil {
-				a.statusMessage = "Failed to initialize Bedrock client: " + err.Error()
-				return a, nil
-			}
-			a.aiClient = client
-		} else {
-			a.aiClient = ollama.NewOllamaClient(a.config.OllamaURL)
+		client, err := newAIClient(a.config)
+		if err != nil {
+			a.statusMessage = fmt.Sprintf("Failed to initialize %s client: %s", a.config.Provider, err.Error())
+			return a, nil
 		}
+		a.aiClient = client
 
 		// Update AI pane with new client and model
 		a.aiPane.aiClient = a.aiClient
@@ -375,6 +422,14 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update agentic project fixer
 		fixLogger := agentic.NewActionLogger(func(msg string) {})
 		a.agenticProjectFixer = agentic.NewAgenticProjectFixer(a.aiClient, a.config.DefaultModel, fixLogger)
+		a.agenticProjectFixer.SetValidator(a.validator)
+
+		// Start or stop validation when the setting changed
+		a.setValidationEnabled(a.config.Validation)
+
+		// Redraw code in the new highlighting theme
+		a.editorPane.SetTheme(a.config.EditorTheme)
+		a.aiPane.SetTheme(a.config.EditorTheme)
 
 		// Re-check AI availability with the new config
 		a.aiPane.aiChecked = false
@@ -383,6 +438,33 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.statusMessage = "Configuration saved successfully to " + configPath
 		return a, a.aiPane.CheckAIAvailability()
 
+	case GitBlameMsg:
+		if msg.Error != nil {
+			a.statusMessage = "Blame unavailable: " + msg.Error.Error()
+		} else if msg.Path == a.editorPane.currentFilePath() {
+			a.editorPane.SetBlame(msg.Path, msg.Lines)
+			a.statusMessage = "Blame shown (Alt+B to hide)"
+		}
+		return a, nil
+
+	case clipboardErrorMsg:
+		a.statusMessage = "Error copying to clipboard: " + msg.err.Error()
+		return a, nil
+
+	case LSPEventMsg, LSPHoverMsg, LSPDefinitionMsg, LSPCompletionMsg, LSPRenameMsg:
+		return a, a.handleLSPMsg(msg)
+
+	case AgentProgressMsg:
+		a.aiPane.DisplayNotification(msg.Content)
+		return a, waitForAgentProgress(a.progressCh)
+
+	case ValidationMsg:
+		if msg.Notification != "" {
+			a.aiPane.DisplayNotification(msg.Notification)
+		}
+		a.editorPane.ApplyValidationSession(msg.Session)
+		return a, waitForValidation(a.validationCh)
+
 	case LanguageCheckMsg:
 		// Check if the required language runtime is installed
 		langInstaller := installer.NewLanguageInstaller()
@@ -564,12 +646,17 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case OpenFileInEditorMsg:
-		// Handle file opening from documentation generation
+		// Handle file opening from documentation generation, the file tree
+		// and the go to file / symbol palette
 		err := a.editorPane.LoadFile(msg.FilePath)
 		if err != nil {
 			a.statusMessage = "Error opening file: " + err.Error()
 		} else {
 			a.statusMessage = "Opened: " + msg.FilePath
+			if msg.Line > 0 {
+				a.editorPane.SetCursorLine(msg.Line - 1)
+				a.statusMessage = fmt.Sprintf("Opened: %s:%d", msg.FilePath, msg.Line)
+			}
 			// Switch to editor pane to show the file
 			a.activePane = types.EditorPaneType
 			a.editorPane.focused = true
@@ -579,6 +666,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case AgenticFixResultMsg:
 		a.aiPane.streaming = false
+		a.cancelAgent = nil
 		result := msg.Result
 
 		// Record token usage from the agentic fix on the session.
@@ -592,12 +680,16 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, a.aiPane.SendMessage(result.ChangesSummary, "")
 		}
 
+		var cmd tea.Cmd
 		if result.Success {
 			// In preview mode, don't apply the fix to the editor
 			// Just show the changes summary
 			if !result.PreviewMode {
 				// Apply the fix to the editor
 				a.editorPane.SetContent(result.ModifiedContent)
+				if a.editorPane.currentFile != nil {
+					cmd = a.markAgentChanged([]string{a.editorPane.currentFile.Filepath})
+				}
 			}
 
 			// Show notification in chat
@@ -606,7 +698,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Handle fix failure
 			a.aiPane.DisplayNotification(result.ErrorMessage)
 		}
-		return a, nil
+		return a, cmd
 
 	case SendAIMessageMsg:
 		// Handle AI message through the new handleAIMessage method
@@ -630,7 +722,8 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				// Update GitPane working directory
 				cmd := a.gitPane.SetWorkDir(msg.NewDir)
-				cmds = append(cmds, cmd)
+				cmds = append(cmds, cmd, a.fileTree.SetWorkspace(msg.NewDir))
+				a.palette.SetWorkspace(msg.NewDir)
 
 				// Update AIChatPane workspace root
 				a.aiPane.SetWorkspaceRoot(msg.NewDir)
@@ -652,35 +745,86 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		return a, tea.Batch(cmds...)
 
-	case tea.WindowSizeMsg:
-		a.width = msg.Width
-		a.height = msg.Height
-		a.ready = true
+	case GitSuggestCommitMsg:
+		return a, a.suggestCommitMessage(false)
+
+	case GitCommitSuggestionMsg:
+		return a, a.handleCommitSuggestion(msg)
 
-		// Account for header (3 lines), editor title bar (3 lines), status bar (1 line)
-		paneHeight := msg.Height - 7
+	case GitOpenConflictMsg:
+		return a, a.handleOpenConflict(msg)
 
-		// Width budget:
-		// Editor View() uses Border + Width(w-4) → rendered width = w - 4 (content) + 2 (border) = w - 2
-		// AI pane View() wraps everything in a container with Width(w) → rendered width = w
-		// Total must equal msg.Width: (editorW - 2) + aiW = msg.Width
-		// So: editorW + aiW = msg.Width + 2
-		halfWidth := msg.Width / 2
-		editorWidth := halfWidth + 2           // renders as halfWidth wide
-		aiWidth := msg.Width + 2 - editorWidth // renders as msg.Width - halfWidth wide
+	case GitResolveWithAIMsg:
+		return a, a.handleResolveWithAI(msg)
 
-		// Update editor pane size
-		a.editorPane.width = editorWidth
-		a.editorPane.height = paneHeight
+	case GitDraftReleaseNotesMsg:
+		a.gitPane.statusMessage = "Drafting release notes..."
+		return a, a.draftReleaseNotes(msg.Tag)
 
-		// Update AI pane size
-		a.aiPane.width = aiWidth
-		a.aiPane.height = paneHeight
+	case GitReleaseNotesMsg:
+		return a, a.handleReleaseNotes(msg)
 
-		// Update GitPane size for proper centering
-		a.gitPane.width = msg.Width
-		a.gitPane.height = msg.Height
+	case FileTreeStatusMsg:
+		a.fileTree.SetStatus(msg)
+		return a, nil
 
+	case FileTreeRenamedMsg:
+		a.editorPane.RenamePath(msg.From, msg.To)
+		return a, nil
+
+	case PaletteIndexedMsg:
+		a.palette.SetIndexed(msg)
+		return a, nil
+
+	case ReplacePreviewMsg:
+		if msg.Root != a.config.WorkspaceDir {
+			return a, nil
+		}
+		if msg.Err != nil {
+			a.statusMessage = "Project search failed: " + msg.Err.Error()
+			return a, nil
+		}
+		// Files with unsaved edits are left alone; saving the buffer
+		// would overwrite the replacement
+		var results []search.FileMatches
+		unsaved := 0
+		for _, r := range msg.Results {
+			if a.editorPane.IsUnsaved(filepath.Join(msg.Root, filepath.FromSlash(r.Path))) {
+				unsaved++
+				continue
+			}
+			results = append(results, r)
+		}
+		msg.Results = results
+		opened := a.replacePane.Open(msg)
+		switch {
+		case opened && unsaved > 0:
+			a.statusMessage = fmt.Sprintf("%d file(s) with unsaved changes left out of the replace", unsaved)
+		case opened:
+			a.statusMessage = "Review the matches to replace"
+		case unsaved > 0:
+			a.statusMessage = fmt.Sprintf("Matches only in %d file(s) with unsaved changes; save them first", unsaved)
+		default:
+			a.statusMessage = "No matches found for: " + msg.Find
+		}
+		return a, nil
+
+	case ReplaceAppliedMsg:
+		a.statusMessage = msg.Summary
+		if len(msg.Written) == 0 {
+			return a, nil
+		}
+		a.editorPane.ReloadFiles(msg.Written)
+		if a.validator != nil {
+			a.validator.ValidateChanges(msg.Written)
+		}
+		return a, a.fileTree.Refresh()
+
+	case tea.WindowSizeMsg:
+		a.width = msg.Width
+		a.height = msg.Height
+		a.ready = true
+		a.layout()
 		return a, nil
 
 	case AutonomousTickMsg:
@@ -688,7 +832,39 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 
-		status, err := a.autonomousCreator.Step()
+		// Run the step off the UI loop so the stop key stays responsive. The
+		// step reports back only through its message and the progress channel.
+		creator := a.autonomousCreator
+		var fileToOpen string
+		creator.OpenFileCallback = func(filePath string) error {
+			fileToOpen = filePath
+			return nil
+		}
+		a.aiPane.streaming = true
+		a.autonomousStepRunning = true
+		return a, func() tea.Msg {
+			status, err := creator.Step()
+			return AutonomousStepMsg{Status: status, Err: err, State: creator.State, FileToOpen: fileToOpen, creator: creator}
+		}
+
+	case AutonomousStepMsg:
+		a.aiPane.streaming = false
+		a.autonomousStepRunning = false
+		if a.autonomousCreator == nil || msg.creator != a.autonomousCreator {
+			// Aborted with /cancel while the step was running
+			return a, nil
+		}
+		a.autonomousState = msg.State
+
+		status, err := msg.Status, msg.Err
+		if ai.IsCancelled(err) {
+			a.aiPane.DisplayNotification("⏹ Autonomous creation stopped by user.")
+			if a.autonomousCreator.InputTokens > 0 || a.autonomousCreator.OutputTokens > 0 {
+				a.aiPane.RecordAgenticTokens(a.autonomousCreator.InputTokens, a.autonomousCreator.OutputTokens, a.autonomousCreator.TotalTokens)
+			}
+			a.autonomousCreator = nil
+			return a, nil
+		}
 		if err != nil {
 			a.aiPane.DisplayNotification("Autonomous Creation Error: " + err.Error())
 			// Record any tokens accumulated before the error.
@@ -715,9 +891,8 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Check if there's a file to open (e.g., SUMMARY.md) and open it immediately
-		if a.autonomousFileToOpen != "" {
-			filePath := a.autonomousFileToOpen
-			a.autonomousFileToOpen = "" // Clear it after capturing
+		if msg.FileToOpen != "" {
+			filePath := msg.FileToOpen
 
 			// Open the file directly in the editor pane
 			err := a.editorPane.LoadFile(filePath)
@@ -734,7 +909,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// If the state is not waiting for user or done, queue the next tick to keep it going.
 		// For waiting states, we pause the loop until the user proceeds.
-		if a.autonomousCreator.State != agentic.StateWaitingApproval && a.autonomousCreator.State != agentic.StateDone {
+		if a.autonomousState != agentic.StateWaitingApproval && a.autonomousState != agentic.StateDone {
 			tickCmd := func() tea.Msg {
 				// yield to the UI event loop momentarily to redraw
 				return AutonomousTickMsg{}
@@ -742,7 +917,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tickCmd
 		}
 
-		if a.autonomousCreator.State == agentic.StateDone {
+		if a.autonomousState == agentic.StateDone {
 			a.autonomousCreator = nil // Process complete, reset
 		}
 
@@ -762,6 +937,12 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		a.aiPane.DisplayNotification(msg.Formatted)
 
+		// Open the review screen for previewed changes
+		if msg.Report != nil && msg.Report.PreviewMode && len(msg.Report.FilesModified) > 0 {
+			a.lastPreviewReport = msg.Report
+			a.reviewPane.Open(msg.Report)
+		}
+
 		// Open modified files sequentially into the editor panel.
 		// Preview-mode runs don't write files, so skip loading.
 		if msg.Report != nil && !msg.Report.PreviewMode && len(msg.Report.FilesModified) > 0 {
@@ -771,6 +952,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					paths = append(paths, f.Path)
 				}
 			}
+			cmds = append(cmds, a.markAgentChanged(paths))
 			if len(paths) > 0 {
 				// Load the first file immediately.
 				if err := a.editorPane.LoadFile(paths[0]); err == nil {
@@ -790,6 +972,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case FixSessionCompleteMsg:
 		a.aiPane.streaming = false
+		a.cancelAgent = nil
 
 		if msg.Error != nil {
 			a.aiPane.DisplayNotification("Fix session error: " + msg.Error.Error())
@@ -822,6 +1005,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						paths = append(paths, f.Path)
 					}
 				}
+				cmds = append(cmds, a.markAgentChanged(paths))
 				if len(paths) > 0 {
 					if err := a.editorPane.LoadFile(paths[0]); err == nil {
 						a.activePane = types.EditorPaneType
@@ -835,6 +1019,13 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+		} else if result.Cancelled {
+			summary := fmt.Sprintf("⏹ Fix session stopped by user; files restored.\nAttempts: %d, Cycles: %d",
+				result.TotalAttempts, result.TotalCycles)
+			if n := len(result.Attempts); n > 0 && strings.TrimSpace(result.Attempts[n-1].Strategy.AIResponse) != "" {
+				summary += "\n\nPartial AI response:\n" + result.Attempts[n-1].Strategy.AIResponse
+			}
+			a.aiPane.DisplayNotification(summary)
 		} else {
 			errMsg := result.ErrorMessage
 			if errMsg == "" {
@@ -847,6 +1038,21 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return a, tea.Batch(cmds...)
 
+	case ReviewAppliedMsg:
+		a.aiPane.DisplayNotification(msg.Summary)
+		if len(msg.Written) == 0 {
+			return a, nil
+		}
+		// The previewed changes are now on disk; /proceed would redo them
+		a.lastPreviewRequest = ""
+		a.lastPreviewReport = nil
+		if a.validator != nil {
+			a.validator.ValidateChanges(msg.Written)
+		}
+		return a, tea.Batch(a.markAgentChanged(msg.Written), func() tea.Msg {
+			return ProjectFileOpenMsg{Paths: msg.Written}
+		})
+
 	case ProjectFileOpenMsg:
 		if len(msg.Paths) == 0 {
 			return a, nil
@@ -920,7 +1126,35 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		return a, tea.Batch(cmds...)
 
+	case tea.MouseMsg:
+		return a, a.handleMouse(msg)
+
 	case tea.KeyMsg:
+		// The review screen takes all keys while it is open
+		if a.reviewPane.IsVisible() {
+			return a, a.reviewPane.Update(msg)
+		}
+
+		// So does the replace preview
+		if a.replacePane.IsVisible() {
+			return a, a.replacePane.Update(msg)
+		}
+
+		// So does the Git popup, except for its toggle and quit, and for
+		// Ctrl+K while a suggestion is being generated
+		if a.gitPane.IsVisible() && msg.String() != "ctrl+g" && msg.String() != "ctrl+q" {
+			if msg.String() == "ctrl+k" && a.stopGeneration() {
+				return a, nil
+			}
+			_, cmd := a.gitPane.Update(msg)
+			return a, cmd
+		}
+
+		// And the go to file / symbol palette
+		if a.palette.IsVisible() {
+			return a, a.palette.Update(msg)
+		}
+
 		// Handle help dialog
 		if a.showHelp {
 			switch msg.String() {
@@ -932,6 +1166,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Handle find and replace prompt dialog
 		if a.showFindReplacePrompt {
+			if a.toggleFindOption(msg.String()) {
+				return a, nil
+			}
 			switch msg.String() {
 			case "tab":
 				// Switch between find and replace input
@@ -942,25 +1179,41 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					a.statusMessage = "Enter replacement text (Tab to find, Enter to replace all, Esc to cancel)"
 				}
 				return a, nil
+			case "alt+a":
+				// Switch between the open file and the whole project
+				if a.editorPane.currentFile == nil {
+					a.findError = "No file open; replace works on the whole project"
+					return a, nil
+				}
+				a.replaceInProject = !a.replaceInProject
+				a.findError = ""
+				return a, nil
 			case "enter":
 				// Replace all occurrences
-				if a.findBuffer != "" && a.findReplaceMode == 1 {
-					a.replaceAll()
-					a.showFindReplacePrompt = false
-					a.findBuffer = ""
-					a.replaceBuffer = ""
-					a.findReplaceMode = 0
+				if a.findBuffer == "" || a.findReplaceMode != 1 {
+					return a, nil
 				}
-				return a, nil
+				finder, err := search.NewFinder(a.findPattern(a.findBuffer))
+				if err != nil {
+					a.findError = err.Error()
+					return a, nil
+				}
+				var cmd tea.Cmd
+				if a.replaceInProject {
+					a.statusMessage = "Searching the workspace..."
+					cmd = findInProject(a.config.WorkspaceDir, finder, a.findBuffer, a.replaceBuffer)
+				} else {
+					a.replaceAll(finder)
+				}
+				a.closeFindReplacePrompt()
+				return a, cmd
 			case "esc":
 				// Cancel find and replace
-				a.showFindReplacePrompt = false
-				a.findBuffer = ""
-				a.replaceBuffer = ""
-				a.findReplaceMode = 0
+				a.closeFindReplacePrompt()
 				a.statusMessage = "Find and replace cancelled"
 				return a, nil
 			case "backspace":
+				a.findError = ""
 				if a.findReplaceMode == 0 {
 					if len(a.findBuffer) > 0 {
 						a.findBuffer = a.findBuffer[:len(a.findBuffer)-1]
@@ -974,6 +1227,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			default:
 				// Add character to buffer
 				if len(msg.String()) == 1 {
+					a.findError = ""
 					if a.findReplaceMode == 0 {
 						a.findBuffer += msg.String()
 					} else {
@@ -984,26 +1238,42 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Handle rename symbol prompt dialog
+		if a.showRenamePrompt {
+			return a, a.handleRenamePromptKey(msg)
+		}
+
 		// Handle find text prompt dialog
 		if a.showFindPrompt {
+			if a.toggleFindOption(msg.String()) {
+				return a, nil
+			}
 			switch msg.String() {
 			case "enter":
 				// Start search
 				if a.findBuffer != "" {
+					finder, err := search.NewFinder(a.findPattern(a.findBuffer))
+					if err != nil {
+						a.findError = err.Error()
+						return a, nil
+					}
 					a.findTerm = a.findBuffer
 					a.findMode = true
-					a.performSearch()
+					a.performSearch(finder)
 				}
 				a.showFindPrompt = false
 				a.findBuffer = ""
+				a.findError = ""
 				return a, nil
 			case "esc":
 				// Cancel find
 				a.showFindPrompt = false
 				a.findBuffer = ""
+				a.findError = ""
 				a.statusMessage = "Find cancelled"
 				return a, nil
 			case "backspace":
+				a.findError = ""
 				if len(a.findBuffer) > 0 {
 					a.findBuffer = a.findBuffer[:len(a.findBuffer)-1]
 				}
@@ -1011,6 +1281,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			default:
 				// Add character to buffer
 				if len(msg.String()) == 1 {
+					a.findError = ""
 					a.findBuffer += msg.String()
 				}
 				return a, nil
@@ -1100,13 +1371,16 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						a.projectCtxCache.Invalidate(a.config.WorkspaceDir) // Invalidate cache for old workspace (Req 2.3)
 						a.config.WorkspaceDir = newDir
 
+						var treeCmd tea.Cmd
 						if err := os.Chdir(newDir); err != nil {
 							a.statusMessage = "Failed to change directory: " + err.Error()
 						} else {
 							a.fileManager.SetWorkspaceDir(newDir)
 							a.gitPane.SetWorkDir(newDir)
 							a.aiPane.SetWorkspaceRoot(newDir)
-							a.editorPane.CloseFile() // Close current file as it's outside new workspace
+							a.editorPane.CloseAll() // Close open files as they are outside new workspace
+							treeCmd = a.fileTree.SetWorkspace(newDir)
+							a.palette.SetWorkspace(newDir)
 
 							// Save workspace to config file
 							if err := config.UpdateWorkspace(newDir); err != nil {
@@ -1117,7 +1391,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 						a.showFolderPicker = false
 						a.folderList = nil
-						return a, nil
+						return a, treeCmd
 					}
 
 					if selected == "[ Create New Folder ]" {
@@ -1434,10 +1708,16 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 
+		// The completion and hover popups take their keys before the
+		// shortcuts
+		if a.activePane == types.EditorPaneType && a.editorPane.popupKey(msg.String()) {
+			return a, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+q":
 			// Check for unsaved changes
-			if a.editorPane.HasUnsavedChanges() && !a.forceQuit {
+			if len(a.editorPane.UnsavedBuffers()) > 0 && !a.forceQuit {
 				a.showExitConfirmation = true
 				return a, nil
 			}
@@ -1446,25 +1726,33 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tea.Quit
 
 		case "ctrl+c":
+			// Copy the editor selection, or else the cursor line
 			var textToCopy string
 
 			if a.activePane == types.EditorPaneType {
-				textToCopy = a.editorPane.GetCurrentLine()
+				textToCopy = a.editorPane.SelectedText()
+				if textToCopy == "" {
+					textToCopy = a.editorPane.GetCurrentLine()
+				}
 			} else if a.activePane == types.AIPaneType || a.activePane == types.AIResponsePaneType {
 				textToCopy = a.aiPane.GetSelectedCodeBlock()
 			}
 
-			if textToCopy != "" {
-				err := clipboard.WriteAll(textToCopy)
-				if err != nil {
-					a.statusMessage = "Error copying to clipboard: " + err.Error()
-				} else {
-					a.statusMessage = "Copied to clipboard"
-				}
-			} else {
-				a.statusMessage = "Nothing to copy"
+			return a, a.copyToClipboard(textToCopy)
+
+		case "ctrl+v":
+			// The AI input pastes on its own
+			if a.activePane == types.EditorPaneType {
+				a.pasteClipboard()
+				return a, nil
+			}
+
+		case "alt+s":
+			// Send the selection to the AI as the context of the next message
+			if a.activePane == types.EditorPaneType {
+				a.attachSelection()
+				return a, nil
 			}
-			return a, nil
 
 		case "ctrl+b":
 			// Open backup picker
@@ -1529,6 +1817,20 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.statusMessage = "Select a file to open or folder to browse"
 			return a, nil
 
+		case "ctrl+p":
+			// Go to file; the AI pane keeps Ctrl+P for pasting its response
+			if a.activePane != types.AIPaneType && a.activePane != types.AIResponsePaneType {
+				return a, a.openPalette(paletteFiles)
+			}
+
+		case "alt+m":
+			// Go to symbol
+			return a, a.openPalette(paletteSymbols)
+
+		case "f9":
+			// Release the mouse to the terminal, or capture it again
+			return a, a.toggleMouse()
+
 		case "ctrl+w":
 			// Open folder picker via message
 			return a, func() tea.Msg {
@@ -1573,23 +1875,53 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 
 		case "alt+f":
-			// Open find and replace prompt
-			if a.editorPane.currentFile != nil {
-				a.showFindReplacePrompt = true
-				a.findBuffer = ""
-				a.replaceBuffer = ""
-				a.findReplaceMode = 0 // Start with find input
-				a.statusMessage = "Enter text to find (Esc to cancel)"
-			} else {
-				a.statusMessage = "No file open to search"
-			}
+			// Open find and replace prompt, on the whole project when no
+			// file is open
+			a.showFindReplacePrompt = true
+			a.findBuffer = ""
+			a.replaceBuffer = ""
+			a.findReplaceMode = 0 // Start with find input
+			a.findError = ""
+			a.replaceInProject = a.editorPane.currentFile == nil
+			a.statusMessage = "Enter text to find (Esc to cancel)"
 			return a, nil
 
+		case "alt+k", "f12", "f2", "ctrl+@":
+			// Language server requests about the symbol at the cursor
+			if a.activePane == types.EditorPaneType {
+				switch msg.String() {
+				case "alt+k":
+					return a, a.showHover()
+				case "f12":
+					return a, a.goToDefinition()
+				case "f2":
+					a.openRenamePrompt()
+					return a, nil
+				default:
+					return a, a.complete()
+				}
+			}
+
+		case "alt+b":
+			// Toggle the blame gutter of the open file
+			if a.editorPane.currentFile == nil {
+				a.statusMessage = "No file open to blame"
+				return a, nil
+			}
+			if a.editorPane.BlameVisible() {
+				a.editorPane.ClearBlame()
+				a.statusMessage = "Blame hidden"
+				return a, nil
+			}
+			a.statusMessage = "Loading blame..."
+			return a, loadBlame(a.gitPane.gitClient, a.editorPane.currentFilePath())
+
 		case "ctrl+f":
 			// Open find text prompt
 			if a.editorPane.currentFile != nil {
 				a.showFindPrompt = true
 				a.findBuffer = ""
+				a.findError = ""
 				a.statusMessage = "Enter text to find (Esc to cancel)"
 			} else {
 				a.statusMessage = "No file open to search"
@@ -1615,6 +1947,21 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.statusMessage = "Search mode exited"
 				return a, nil
 			}
+			// Otherwise stop a running generation
+			if a.stopGeneration() {
+				return a, nil
+			}
+			// Leave the file tree unless it is asking for input
+			if a.activePane == types.FileTreePaneType && !a.fileTree.Prompting() {
+				a.focusPane(types.EditorPaneType)
+				return a, nil
+			}
+
+		case "ctrl+k":
+			// Stop generation; in terminal mode Ctrl+K kills the process instead
+			if !a.aiPane.terminalMode && a.stopGeneration() {
+				return a, nil
+			}
 
 		case "ctrl+h":
 			// Toggle help menu
@@ -1626,6 +1973,10 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmd := a.gitPane.Toggle()
 			return a, cmd
 
+		case "ctrl+e":
+			// Show, focus or hide the file tree
+			return a, a.toggleFileTree()
+
 		case "ctrl+l":
 			// Open chat loader to reload saved chats
 			tiDir := filepath.Join(a.config.WorkspaceDir, ".ti")
@@ -1667,8 +2018,16 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 
 		case "tab":
-			// Cycle through: Editor → AI Input → AI Response → Editor
-			if a.activePane == types.EditorPaneType {
+			// Indent the selected block in the editor
+			if a.activePane == types.EditorPaneType && a.editorPane.HasSelection() {
+				a.editorPane.IndentSelection()
+				return a, nil
+			}
+
+			// Cycle through: Editor → AI Input → AI Response → File Tree (when shown) → Editor
+			if a.activePane == types.FileTreePaneType {
+				a.focusPane(types.EditorPaneType)
+			} else if a.activePane == types.EditorPaneType {
 				// Switch from Editor to AI Input
 				a.activePane = types.AIPaneType
 				a.editorPane.focused = false
@@ -1679,6 +2038,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if a.aiPane.GetActiveArea() == 0 {
 					// Switch from AI Input to AI Response
 					a.aiPane.SetActiveArea(1) // Set to Response area
+				} else if a.fileTree.IsVisible() {
+					// Switch from AI Response to the file tree
+					a.focusPane(types.FileTreePaneType)
 				} else {
 					// Switch from AI Response back to Editor
 					a.activePane = types.EditorPaneType
@@ -1731,7 +2093,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 									a.ensurePythonVenv(filepath.Dir(a.editorPane.currentFile.Filepath))
 								}
 								if fileType == "go" || fileType == "python" {
-									return a, func() tea.Msg {
+									return a, tea.Batch(a.fileTree.Refresh(), func() tea.Msg {
 										langName := "Go"
 										if fileType == "python" {
 											langName = "Python"
@@ -1740,7 +2102,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 											FileType:     fileType,
 											LanguageName: langName,
 										}
-									}
+									})
 								}
 							}
 						}
@@ -1769,7 +2131,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 								a.ensurePythonVenv(filepath.Dir(a.editorPane.currentFile.Filepath))
 							}
 							if fileType == "go" || fileType == "python" {
-								return a, func() tea.Msg {
+								return a, tea.Batch(a.fileTree.Refresh(), func() tea.Msg {
 									langName := "Go"
 									if fileType == "python" {
 										langName = "Python"
@@ -1778,18 +2140,20 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 										FileType:     fileType,
 										LanguageName: langName,
 									}
-								}
+								})
 							}
 						}
 					}
 				}
 			}
-			return a, nil
+			return a, a.fileTree.Refresh()
 
 		case "ctrl+x":
-			// Close file in editor
+			// Cut the selection, or else close the file in the active editor tab
 			if a.activePane == types.EditorPaneType {
-				if a.editorPane.currentFile != nil {
+				if a.editorPane.HasSelection() {
+					return a, a.cutSelection()
+				} else if a.editorPane.currentFile != nil || a.editorPane.BufferCount() > 1 {
 					a.editorPane.CloseFile()
 					a.statusMessage = "File closed"
 				}
@@ -1886,7 +2250,11 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Route messages to active pane
-	if a.activePane == types.EditorPaneType {
+	if a.activePane == types.FileTreePaneType {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			cmds = append(cmds, a.fileTree.Update(keyMsg))
+		}
+	} else if a.activePane == types.EditorPaneType {
 		cmd := a.editorPane.Update(msg)
 		cmds = append(cmds, cmd)
 	} else if a.activePane == types.AIPaneType || a.activePane == types.AIResponsePaneType {
@@ -1897,6 +2265,45 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, tea.Batch(cmds...)
 }
 
+// layout sizes the panes for the terminal size. The file tree, when shown,
+// takes a column on the left and the editor and AI panes share the rest.
+func (a *App) layout() {
+	// Account for header (3 lines), editor title bar (3 lines), status bar (1 line)
+	paneHeight := a.height - 7
+
+	treeWidth := 0
+	if a.fileTree.IsVisible() {
+		treeWidth = fileTreeWidth(a.width)
+	}
+	a.fileTree.SetSize(treeWidth, paneHeight)
+	a.palette.SetSize(a.width, a.height)
+
+	// Width budget:
+	// Editor View() uses Border + Width(w-4) → rendered width = w - 4 (content) + 2 (border) = w - 2
+	// AI pane View() wraps everything in a container with Width(w) → rendered width = w
+	// Total must equal the width left by the tree: (editorW - 2) + aiW = width
+	// So: editorW + aiW = width + 2
+	width := a.width - treeWidth
+	halfWidth := width / 2
+	editorWidth := halfWidth + 2       // renders as halfWidth wide
+	aiWidth := width + 2 - editorWidth // renders as width - halfWidth wide
+
+	// Update editor pane size
+	a.editorPane.width = editorWidth
+	a.editorPane.height = paneHeight
+
+	// Update AI pane size
+	a.aiPane.width = aiWidth
+	a.aiPane.height = paneHeight
+
+	// Update GitPane size for proper centering
+	a.gitPane.width = a.width
+	a.gitPane.height = a.height
+
+	a.reviewPane.SetSize(a.width, a.height)
+	a.replacePane.SetSize(a.width, a.height)
+}
+
 // renderHeader renders the application header with logo.
 // Displays "MINICLICODER" centered with binary code "01000011 01001100 01001001" (CLI) on the right.
 // The header is wrapped in a blue rounded border.
@@ -1982,6 +2389,7 @@ func (a *App) renderHeader() string {
 // renderEditorTitleBar renders the full-width editor title bar.
 // Displays "Editor: <filepath>" with an asterisk (*) if the file has unsaved changes.
 // Shows "<no file>" if no file is currently open.
+// With several buffers open, the title lists them as tabs instead.
 // The title bar has a blue background when a file is open.
 //
 // Returns:
@@ -1989,7 +2397,9 @@ func (a *App) renderHeader() string {
 func (a *App) renderEditorTitleBar() string {
 	// Build title text
 	title := "Editor: "
-	if a.editorPane.currentFile != nil {
+	if a.editorPane.BufferCount() > 1 {
+		title += a.editorPane.TabBar(a.width - 4 - 2 - len(title))
+	} else if a.editorPane.currentFile != nil {
 		title += a.editorPane.currentFile.Filepath
 		if a.editorPane.HasUnsavedChanges() {
 			title += " *"
@@ -2071,6 +2481,21 @@ func (a *App) View() string {
 		return "Initializing..."
 	}
 
+	// Show the review screen if open
+	if a.reviewPane.IsVisible() {
+		return a.reviewPane.View()
+	}
+
+	// Show the replace preview if open
+	if a.replacePane.IsVisible() {
+		return a.replacePane.View()
+	}
+
+	// Show the go to file / symbol palette if open
+	if a.palette.IsVisible() {
+		return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, a.palette.View())
+	}
+
 	// Show help dialog if needed
 	if a.showHelp {
 		return a.renderHelpDialog()
@@ -2331,16 +2756,18 @@ func (a *App) View() string {
 			Width(70).
 			Align(lipgloss.Center)
 
-		// Get the current file name
-		fileName := "No file"
-		if a.editorPane.currentFile != nil {
-			fileName = filepath.Base(a.editorPane.currentFile.Filepath)
+		// Get the current file name, or the project scope
+		scope := "File: No file"
+		if a.replaceInProject {
+			scope = "Project: all files not ignored by git (Alt+A for this file)"
+		} else if a.editorPane.currentFile != nil {
+			scope = "File: " + filepath.Base(a.editorPane.currentFile.Filepath) + " (Alt+A for the project)"
 		}
 
 		promptText := "Find and Replace:\n"
 		promptText += lipgloss.NewStyle().
 			Foreground(lipgloss.Color("15")).
-			Render("File: "+fileName) + "\n\n"
+			Render(scope) + "\n\n"
 
 		// Find input
 		findLabel := "Find:    "
@@ -2368,10 +2795,19 @@ func (a *App) View() string {
 		if a.findReplaceMode == 1 {
 			promptText += "█"
 		}
-		promptText += "\n\n"
+		promptText += "\n\n" + a.findOptionsLine() + "\n"
+		if a.findRegex {
+			promptText += "Use $1 or ${name} in the replacement for groups\n"
+		}
+		if a.findError != "" {
+			promptText += lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(a.findError) + "\n"
+		}
+		promptText += "\n"
 
 		if a.findReplaceMode == 0 {
 			promptText += "[Tab] Replace | [Esc] Cancel"
+		} else if a.replaceInProject {
+			promptText += "[Tab] Find | [Enter] Preview | [Esc] Cancel"
 		} else {
 			promptText += "[Tab] Find | [Enter] Replace All | [Esc] Cancel"
 		}
@@ -2383,6 +2819,10 @@ func (a *App) View() string {
 	}
 
 	// Show find text prompt dialog if needed
+	if a.showRenamePrompt {
+		return a.renamePromptView()
+	}
+
 	if a.showFindPrompt {
 		promptStyle := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -2401,7 +2841,11 @@ func (a *App) View() string {
 		promptText += lipgloss.NewStyle().
 			Foreground(lipgloss.Color("15")).
 			Render("File: "+fileName) + "\n\n"
-		promptText += a.findBuffer + "█\n\n[Enter] to search, [Esc] to cancel"
+		promptText += a.findBuffer + "█\n\n" + a.findOptionsLine() + "\n"
+		if a.findError != "" {
+			promptText += lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(a.findError) + "\n"
+		}
+		promptText += "\n[Enter] to search, [Esc] to cancel"
 
 		dialog := promptStyle.Render(promptText)
 
@@ -2469,7 +2913,7 @@ func (a *App) View() string {
 			Width(60).
 			Align(lipgloss.Center)
 
-		confirmText := "You have unsaved changes.\nAre you sure you want to quit without saving?\n\n[Y]es / [N]o"
+		confirmText := "You have unsaved changes in " + strings.Join(a.editorPane.UnsavedBuffers(), ", ") + ".\nAre you sure you want to quit without saving?\n\n[Y]es / [N]o"
 
 		dialog := confirmStyle.Render(confirmText)
 
@@ -2589,6 +3033,11 @@ func (a *App) View() string {
 	editorTitleBar := a.renderEditorTitleBar()
 
 	mainView := lipgloss.JoinHorizontal(lipgloss.Top, editorContent, aiContent)
+	if a.fileTree.IsVisible() {
+		a.fileTree.focused = a.activePane == types.FileTreePaneType
+		treeContent := enforceWidth(a.fileTree.View(), a.fileTree.width)
+		mainView = lipgloss.JoinHorizontal(lipgloss.Top, treeContent, editorContent, aiContent)
+	}
 
 	// Combine all sections vertically
 	baseView := lipgloss.JoinVertical(lipgloss.Left, header, editorTitleBar, mainView, statusBar)
@@ -2684,19 +3133,16 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		// Ensure all fields are populated with defaults if missing
 		config.EnsureAllFields(jcfg)
 
-		// Prepare config fields and values
-		fields := []string{"agent", "model", "gmodel", "bedrock_model", "ollama_url", "gemini_api", "bedrock_api", "bedrock_region", "workspace", "autonomous"}
-		values := []string{
-			jcfg.Agent,
-			jcfg.Model,
-			jcfg.GModel,
-			jcfg.BedrockModel,
-			jcfg.OllamaURL,
-			jcfg.GeminiAPI,
-			jcfg.BedrockAPI,
-			jcfg.BedrockRegion,
-			jcfg.Workspace,
-			jcfg.Autonomous,
+		// Prepare config fields and values: the agent, every registered
+		// provider's settings, then the general settings
+		fields := []string{"agent"}
+		for _, field := range ai.ProviderFields() {
+			fields = append(fields, field.Key)
+		}
+		fields = append(fields, "workspace", "autonomous", "validation", "editor_theme")
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = jcfg.Setting(field)
 		}
 
 		// Enter config mode
@@ -2710,10 +3156,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		// Return current agent and model information
 		modelInfo := fmt.Sprintf("Agent: %s\nModel: %s", a.config.Provider, a.config.DefaultModel)
 
-		// If agent is Gemini, also show the API key
-		if a.config.Provider == "gemini" && a.config.GeminiAPIKey != "" {
-			modelInfo += fmt.Sprintf("\nAPI Key: %s", a.config.GeminiAPIKey)
-		}
+		modelInfo += formatProviderInfo(a.config)
 
 		return func() tea.Msg {
 			return AIResponseMsg{
@@ -2726,7 +3169,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 	// Handle /quit command
 	if trimmedMsg == "/quit" {
 		// Check for unsaved changes
-		if a.editorPane.HasUnsavedChanges() && !a.forceQuit {
+		if len(a.editorPane.UnsavedBuffers()) > 0 && !a.forceQuit {
 			a.showExitConfirmation = true
 			return nil
 		}
@@ -2745,7 +3188,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		helpText += "  Ctrl+O    Open file\n"
 		helpText += "  Ctrl+N    New file\n"
 		helpText += "  Ctrl+S    Save file\n"
-		helpText += "  Ctrl+X    Close file\n"
+		helpText += "  Ctrl+X    Close file (tab)\n"
 		helpText += "  Ctrl+R    Run current script\n"
 		helpText += "  Ctrl+K    Kill running process (in terminal mode)\n"
 		helpText += "  Ctrl+B    Backup Picker (Restore previous versions)\n"
@@ -2755,7 +3198,8 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		helpText += "  Ctrl+Y    List code blocks (Execute/Insert/Return)\n"
 		helpText += "  Ctrl+P    Paste response / Insert code into editor\n"
 		helpText += "  Ctrl+L    Load saved chat from .ti/ folder\n"
-		helpText += "  Ctrl+T    Clear chat / New chat\n\n"
+		helpText += "  Ctrl+T    Clear chat / New chat\n"
+		helpText += "  Esc       Stop generating (also Ctrl+K; partial output is kept)\n\n"
 		helpText += "Navigation\n"
 		helpText += "----------\n"
 		helpText += "  Tab       Switch between Editor, AI Input, and AI Response\n"
@@ -2771,6 +3215,8 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		helpText += "  /preview  Preview changes without applying\n"
 		helpText += "  /project  Run a project-wide change across all files\n"
 		helpText += "  /proceed  Apply the last previewed change\n"
+		helpText += "  /review   Review the last preview hunk by hunk\n"
+		helpText += "  /commit   Suggest a commit message for the staged changes\n"
 		helpText += "  /create   Autonomously build an app from scratch\n"
 		helpText += "  /rescan   Rescan project files for fresh context\n"
 		helpText += "  /model    Show current agent and model info\n"
@@ -2810,24 +3256,43 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		strings.HasPrefix(trimmedForProject, "/preview/project"))
 
 	// Handle /cancel for AutonomousCreator
-	if a.autonomousCreator != nil && a.autonomousCreator.State != agentic.StateDone && strings.TrimSpace(strings.ToLower(message)) == "/cancel" {
+	if a.autonomousCreator != nil && a.autonomousState != agentic.StateDone && strings.TrimSpace(strings.ToLower(message)) == "/cancel" {
+		a.autonomousCreator.Cancel()
 		a.autonomousCreator = nil
 		return func() tea.Msg {
 			return AINotificationMsg{Content: "Autonomous creation task aborted."}
 		}
 	}
 
+	// Handle /review — reopen the review screen for the last preview.
+	if trimmedForProject == "/review" {
+		if a.lastPreviewReport == nil || !a.reviewPane.Open(a.lastPreviewReport) {
+			return func() tea.Msg {
+				return AINotificationMsg{Content: "Nothing to review. Run /preview /project <request> first."}
+			}
+		}
+		return nil
+	}
+
+	// Handle /commit — suggest a commit message for the staged changes.
+	if trimmedForProject == "/commit" {
+		a.statusMessage = "Generating commit message from the staged diff..."
+		return a.suggestCommitMessage(true)
+	}
+
 	// Handle /proceed — re-run the last preview request without preview mode.
 	if trimmedForProject == "/proceed" {
-		if a.lastPreviewRequest == "" && (a.autonomousCreator == nil || a.autonomousCreator.State != agentic.StateWaitingApproval) {
+		if a.lastPreviewRequest == "" && (a.autonomousCreator == nil || a.autonomousState != agentic.StateWaitingApproval) {
 			return func() tea.Msg {
 				return AINotificationMsg{Content: "Nothing to proceed with."}
 			}
 		}
 
 		// Check if we are proceeding with an AutonomousCreator plan
-		if a.autonomousCreator != nil && a.autonomousCreator.State == agentic.StateWaitingApproval {
+		if a.autonomousCreator != nil && a.autonomousState == agentic.StateWaitingApproval {
+			// No step runs while the plan waits for approval
 			a.autonomousCreator.State = agentic.StateSetup
+			a.autonomousState = agentic.StateSetup
 			return func() tea.Msg {
 				return AutonomousTickMsg{}
 			}
@@ -2850,7 +3315,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		}
 
 		// Proceed with /create logic if not already running
-		if a.autonomousCreator != nil && a.autonomousCreator.State != agentic.StateDone {
+		if a.autonomousCreator != nil && a.autonomousState != agentic.StateDone {
 			return func() tea.Msg {
 				return AINotificationMsg{Content: "An autonomous creation task is already in progress. Type /cancel to abort it first."}
 			}
@@ -2869,20 +3334,13 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		// Show immediate feedback that AI is working
 		a.aiPane.DisplayNotification("🤖 AI is thinking and generating implementation plan...")
 
-		createLogger := agentic.NewActionLogger(func(msg string) {
-			a.aiPane.DisplayNotification(msg)
-		})
+		createLogger := agentic.NewActionLogger(agentProgressNotifier(a.progressCh))
 		a.autonomousCreator = agentic.NewAutonomousCreator(
 			a.aiClient, a.config.DefaultModel, a.config.WorkspaceDir, description,
 			a.agenticProjectFixer, createLogger,
 		)
-
-		// Set callback to open SUMMARY.md in editor when it's created
-		a.autonomousCreator.OpenFileCallback = func(filePath string) error {
-			// Store the file path to be opened in the next tick
-			a.autonomousFileToOpen = filePath
-			return nil
-		}
+		a.autonomousCreator.Validator = a.validator
+		a.autonomousState = a.autonomousCreator.State
 
 		// Return a command to tick the autonomous creator immediately to start planning
 		return func() tea.Msg {
@@ -2928,7 +3386,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 				}
 				// Store the bare request without any command prefixes
 				bareRequest = bare
-				formatted += "\nType /proceed to apply these changes."
+				formatted += "\nReview the changes hunk by hunk in the review screen (reopen it with /review), or type /proceed to apply them all."
 			}
 
 			// Return ProjectCompleteMsg so the Update handler can open modified files.
@@ -2947,6 +3405,9 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 		fileType = fileContext.FileType
 	}
 
+	// Chat replies get the selection sent with Alt+S instead of the file
+	chatContext := a.takeChatContext(fileContent)
+
 	// Handle /fix command — route to project-wide agentic fixer (Req 1.2, 1.3, 6.7)
 	if strings.HasPrefix(trimmedMsg, "/fix") {
 		fixMessage := strings.TrimSpace(message[len("/fix"):])
@@ -2966,18 +3427,22 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 			Message:      fixMessage,
 			ProjectRoot:  a.aiPane.workspaceRoot,
 			OpenFilePath: openFilePath,
+			Diagnostics:  a.lspDiagnosticsReport(),
 			MaxAttempts:  9,
 			MaxCycles:    3,
 		}
 
 		a.aiPane.AddFixRequest(message, openFilePath, "")
 		a.aiPane.streaming = true
+		ctx, cancel := context.WithCancel(context.Background())
+		a.cancelAgent = cancel
 
 		return func() tea.Msg {
+			defer cancel()
 			statusCallback := func(phase string) {
 				a.aiPane.DisplayNotification(fmt.Sprintf("🔧 Fix phase: %s", phase))
 			}
-			result, err := a.agenticProjectFixer.ProcessFixCommand(request, statusCallback)
+			result, err := a.agenticProjectFixer.ProcessFixCommandContext(ctx, request, statusCallback)
 			return FixSessionCompleteMsg{Result: result, Error: err}
 		}
 	}
@@ -3067,7 +3532,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 			if buildErr != nil {
 				// Fall through to existing conversational path on error
 				a.aiPane.DisplayNotification("⚠️ Project context build failed: " + buildErr.Error())
-				return a.aiPane.SendMessage(message, fileContent)
+				return a.aiPane.SendMessage(message, chatContext)
 			}
 			a.projectCtxCache.Put(a.config.WorkspaceDir, meta)
 		}
@@ -3081,7 +3546,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 
 		// Build augmented prompt with project context
 		promptBuilder := projectctx.NewPromptBuilder()
-		augmentedPrompt := promptBuilder.Build(meta, message, searchResults, fileContent)
+		augmentedPrompt := promptBuilder.Build(meta, message, searchResults, chatContext)
 
 		// Send through existing streaming path — display user's original message,
 		// but send the augmented prompt to the AI (Req 4.5: don't expose injected context)
@@ -3092,7 +3557,7 @@ func (a *App) handleAIMessage(message string) tea.Cmd {
 
 	// Step 3: Handle conversational mode immediately
 	if !isFixDetection.IsFixRequest {
-		return a.aiPane.SendMessage(message, fileContent)
+		return a.aiPane.SendMessage(message, chatContext)
 	}
 
 	// Step 4: Handle fix request
@@ -3521,18 +3986,115 @@ func (a *App) restoreAutonomousState() {
 	}
 
 	// Reconstruct the AutonomousCreator in StateWaitingApproval
-	a.autonomousCreator = &agentic.AutonomousCreator{
-		AIClient:    a.aiClient,
-		Model:       a.config.DefaultModel,
-		Workspace:   a.config.WorkspaceDir,
-		Description: description,
-		Plan:        plan,
-		ProjectName: projectName,
-		ProjectDir:  filepath.Join(a.config.WorkspaceDir, projectName),
-		State:       agentic.StateWaitingApproval,
+	a.autonomousCreator = agentic.NewAutonomousCreator(
+		a.aiClient, a.config.DefaultModel, a.config.WorkspaceDir, description, nil, nil,
+	)
+	a.autonomousCreator.Validator = a.validator
+	a.autonomousCreator.Plan = plan
+	a.autonomousCreator.ProjectName = projectName
+	a.autonomousCreator.ProjectDir = filepath.Join(a.config.WorkspaceDir, projectName)
+	a.autonomousCreator.State = agentic.StateWaitingApproval
+	a.autonomousState = agentic.StateWaitingApproval
+}
+
+// setValidationEnabled starts or shuts down the validation pipeline and
+// hands it to the editor and the agents.
+func (a *App) setValidationEnabled(enabled bool) {
+	if enabled == (a.validator != nil) {
+		return
+	}
+	if enabled {
+		a.validator = newValidationPipeline(a.validationCh)
+	} else {
+		a.validator.Shutdown()
+		a.validator = nil
+	}
+	a.editorPane.SetValidator(a.validator)
+	a.projectFixer.SetValidator(a.validator)
+	a.agenticProjectFixer.SetValidator(a.validator)
+	if a.autonomousCreator != nil {
+		a.autonomousCreator.Validator = a.validator
 	}
 }
 
+// newAIClient builds the client for the configured provider through the
+// provider registry, falling back to the default provider for unknown names.
+func newAIClient(cfg *types.AppConfig) (ai.AIClient, error) {
+	provider := cfg.Provider
+	if _, ok := ai.LookupProvider(provider); !ok {
+		provider = config.DefaultProvider
+	}
+	return ai.NewClient(provider, config.ProviderSettings(cfg))
+}
+
+// formatProviderInfo describes the active provider for /model: its settings
+// (secrets masked), its capabilities and the other registered providers.
+func formatProviderInfo(cfg *types.AppConfig) string {
+	var sb strings.Builder
+	if spec, ok := ai.LookupProvider(cfg.Provider); ok {
+		if spec.Description != "" {
+			sb.WriteString("\nProvider: " + spec.Description)
+		}
+
+		settings := spec.WithDefaults(config.ProviderSettings(cfg))
+		for _, field := range spec.Fields {
+			value := settings.Get(field.Key)
+			if field.Key == spec.ModelKey || value == "" {
+				continue
+			}
+			if field.Secret {
+				value = ai.MaskSecret(value)
+			}
+			sb.WriteString(fmt.Sprintf("\n%s: %s", field.Key, value))
+		}
+
+		var caps []string
+		if spec.Capabilities.Chat {
+			caps = append(caps, "multi-turn chat")
+		}
+		if spec.Capabilities.Cancel {
+			caps = append(caps, "stop generation")
+		}
+		if spec.Capabilities.ListModels {
+			caps = append(caps, "model listing")
+		}
+		if spec.Capabilities.Tools {
+			caps = append(caps, "tool calling")
+		}
+		if len(caps) > 0 {
+			sb.WriteString("\nCapabilities: " + strings.Join(caps, ", "))
+		}
+	}
+
+	sb.WriteString("\nAvailable agents: " + strings.Join(ai.ProviderNames(), ", "))
+	return sb.String()
+}
+
+// stopGeneration cancels whatever AI work is in flight: a chat response, a
+// /fix session, a commit message suggestion or an autonomous /create step.
+// Partial output is kept. Returns true if something was stopped.
+func (a *App) stopGeneration() bool {
+	stopped := a.aiPane.StopGeneration()
+	if a.cancelAgent != nil {
+		a.cancelAgent()
+		a.cancelAgent = nil
+		stopped = true
+	}
+	if a.cancelSuggestion != nil {
+		a.cancelSuggestion()
+		a.cancelSuggestion = nil
+		stopped = true
+	}
+	if a.autonomousCreator != nil && a.autonomousStepRunning {
+		a.autonomousCreator.Cancel()
+		stopped = true
+	}
+	if stopped {
+		a.statusMessage = "Stopping generation..."
+	}
+	return stopped
+}
+
 // extractProjectNameFromPlan attempts to extract the project name from the plan text.
 // Looks for patterns like "project name: xyz" or "Project Name: xyz"
 func extractProjectNameFromPlan(plan string) string {
@@ -3557,32 +4119,66 @@ func extractProjectNameFromPlan(plan string) string {
 	return ""
 }
 
-// performSearch searches for the current find term in the active file
-func (a *App) performSearch() {
+// findPattern returns the pattern of text with the current find options
+func (a *App) findPattern(text string) search.Pattern {
+	return search.Pattern{
+		Text:          text,
+		Regex:         a.findRegex,
+		CaseSensitive: a.findCaseSensitive,
+		WholeWord:     a.findWholeWord,
+	}
+}
+
+// toggleFindOption flips the find option bound to key in the find dialogs,
+// reporting whether key was one
+func (a *App) toggleFindOption(key string) bool {
+	switch key {
+	case "alt+r":
+		a.findRegex = !a.findRegex
+	case "alt+c":
+		a.findCaseSensitive = !a.findCaseSensitive
+	case "alt+w":
+		a.findWholeWord = !a.findWholeWord
+	default:
+		return false
+	}
+	a.findError = ""
+	return true
+}
+
+// findOptionsLine renders the find options and their toggle keys
+func (a *App) findOptionsLine() string {
+	option := func(on bool, label string) string {
+		if on {
+			return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("42")).Render("[x] " + label)
+		}
+		return "[ ] " + label
+	}
+	return option(a.findRegex, "Regex (Alt+R)") + "  " +
+		option(a.findCaseSensitive, "Case (Alt+C)") + "  " +
+		option(a.findWholeWord, "Word (Alt+W)")
+}
+
+// closeFindReplacePrompt hides the find and replace prompt and clears its
+// input
+func (a *App) closeFindReplacePrompt() {
+	a.showFindReplacePrompt = false
+	a.findBuffer = ""
+	a.replaceBuffer = ""
+	a.findReplaceMode = 0
+	a.findError = ""
+}
+
+// performSearch searches the active file with finder
+func (a *App) performSearch(finder *search.Finder) {
 	if a.editorPane.currentFile == nil || a.findTerm == "" {
 		return
 	}
 
-	content := a.editorPane.GetContent()
-	lines := strings.Split(content, "\n")
 	a.findResults = nil
 	a.findIndex = 0
-
-	// Search for the term in each line (case-insensitive)
-	searchTerm := strings.ToLower(a.findTerm)
-	for lineNum, line := range lines {
-		lowerLine := strings.ToLower(line)
-		col := 0
-		for {
-			// Find next occurrence in this line
-			pos := strings.Index(lowerLine[col:], searchTerm)
-			if pos == -1 {
-				break
-			}
-			// Store the actual position (col + pos)
-			a.findResults = append(a.findResults, struct{ line, col int }{lineNum, col + pos})
-			col += pos + 1 // Move past this match to find next one
-		}
+	for _, m := range finder.FindAll(a.editorPane.GetContent()) {
+		a.findResults = append(a.findResults, struct{ line, col int }{m.Line, m.Col})
 	}
 
 	if len(a.findResults) > 0 {
@@ -3613,48 +4209,21 @@ func (a *App) findNext() {
 	a.statusMessage = fmt.Sprintf("Match %d of %d. Press F3 for next, Esc to exit search", a.findIndex+1, len(a.findResults))
 }
 
-// replaceAll replaces all occurrences of the find term with the replace term
-func (a *App) replaceAll() {
-	if a.editorPane.currentFile == nil || a.findBuffer == "" {
+// replaceAll replaces every match of finder in the active file with the
+// replace text and saves the file
+func (a *App) replaceAll(finder *search.Finder) {
+	if a.editorPane.currentFile == nil {
 		return
 	}
 
-	content := a.editorPane.GetContent()
-
-	// Replace all occurrences (case-insensitive search, but preserve case in replacement)
-	searchTerm := a.findBuffer
-	replaceTerm := a.replaceBuffer
-
-	// Simple case-insensitive replace
-	lowerContent := strings.ToLower(content)
-	lowerSearch := strings.ToLower(searchTerm)
-
-	var result strings.Builder
-	lastIndex := 0
-	count := 0
-
-	for {
-		index := strings.Index(lowerContent[lastIndex:], lowerSearch)
-		if index == -1 {
-			// No more matches, append the rest
-			result.WriteString(content[lastIndex:])
-			break
-		}
-
-		// Append content before the match
-		actualIndex := lastIndex + index
-		result.WriteString(content[lastIndex:actualIndex])
-
-		// Append replacement
-		result.WriteString(replaceTerm)
-
-		// Move past the matched text
-		lastIndex = actualIndex + len(searchTerm)
-		count++
+	content, count := finder.ReplaceAll(a.editorPane.GetContent(), a.replaceBuffer)
+	if count == 0 {
+		a.statusMessage = "No matches found for: " + a.findBuffer
+		return
 	}
 
 	// Update the editor content
-	a.editorPane.SetContent(result.String())
+	a.editorPane.SetContent(content)
 
 	// Save the file
 	if err := a.editorPane.SaveFile(); err != nil {
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"io"
+	"testing"
+
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// newTestApp returns an App on dir with every pane in place and the editor
+// focused. Tests replace the panes they need to control, so a new pane only
+// has to be added here.
+func newTestApp(t *testing.T, dir string) *App {
+	t.Helper()
+	fm := filemanager.NewFileManager(dir)
+	editor := NewEditorPane(fm)
+	editor.SetSize(80, 20)
+	return &App{
+		editorPane:  editor,
+		aiPane:      NewAIChatPane(nil, "test-model", "ollama", dir),
+		gitPane:     NewGitPane(git.NewClient(dir), dir),
+		reviewPane:  NewReviewPane(fm),
+		replacePane: NewReplacePane(fm),
+		fileTree:    NewFileTree(fm, dir),
+		palette:     NewPalette(dir),
+		config:      &types.AppConfig{WorkspaceDir: dir},
+		activePane:  types.EditorPaneType,
+		width:       120,
+		height:      40,
+		ready:       true,
+		output:      io.Discard,
+	}
+}
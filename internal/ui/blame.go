@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/diff"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// GitBlameMsg carries the blame of a file for the editor gutter.
+type GitBlameMsg struct {
+	Path  string
+	Lines []git.BlameLine
+	Error error
+}
+
+// blameWidth is the width of the blame gutter: a short hash, the author
+// and a separating space.
+const blameWidth = 19
+
+// loadBlame returns a command that blames path as of HEAD.
+func loadBlame(client *git.Client, path string) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return GitBlameMsg{Path: path, Error: fmt.Errorf("no repository")}
+		}
+		lines, err := client.Blame(path)
+		return GitBlameMsg{Path: path, Lines: lines, Error: err}
+	}
+}
+
+// SetBlame shows the blame gutter for the file at path. It is only drawn
+// while that file is open.
+func (e *EditorPane) SetBlame(path string, lines []git.BlameLine) {
+	e.blame = lines
+	e.blamePath = path
+	e.blameGutters = nil
+}
+
+// ClearBlame hides the blame gutter.
+func (e *EditorPane) ClearBlame() {
+	e.blame = nil
+	e.blamePath = ""
+	e.blameGutters = nil
+}
+
+// BlameVisible reports whether the blame gutter is shown for the open file.
+func (e *EditorPane) BlameVisible() bool {
+	return e.blamePath != "" && e.blamePath == e.currentFilePath()
+}
+
+// blameGutter returns the rendered blame annotation of each editor line.
+// Lines are matched to the blamed HEAD version by diffing it against the
+// editor content, so edits made since the last commit do not shift the
+// annotations; new lines are marked as uncommitted. The result is cached
+// until the content changes.
+func (e *EditorPane) blameGutter() []string {
+	if e.blameGutters != nil && e.blameContent == e.content {
+		return e.blameGutters
+	}
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	uncommitted := style.Render(fmt.Sprintf("%-*s", blameWidth, "(uncommitted)"))
+	aligned := alignBlame(e.blame, e.content)
+	gutters := make([]string, len(aligned))
+	for i, line := range aligned {
+		if line == nil {
+			gutters[i] = uncommitted
+			continue
+		}
+		author := []rune(line.Author)
+		if len(author) > 10 {
+			author = author[:10]
+		}
+		gutters[i] = style.Render(fmt.Sprintf("%-7s %-10s ", line.Hash, string(author)))
+	}
+
+	e.blameGutters = gutters
+	e.blameContent = e.content
+	return gutters
+}
+
+// alignBlame maps each line of content to the blamed line it is unchanged
+// from, or nil if the line was added or changed since it was blamed.
+func alignBlame(blame []git.BlameLine, content string) []*git.BlameLine {
+	var blamed strings.Builder
+	for _, l := range blame {
+		blamed.WriteString(l.Text)
+		blamed.WriteString("\n")
+	}
+	// One entry per editor row, including the empty row after a final newline
+	aligned := make([]*git.BlameLine, strings.Count(content, "\n")+1)
+
+	oldIdx, newIdx := 0, 0
+	advance := func() {
+		if oldIdx < len(blame) && newIdx < len(aligned) {
+			aligned[newIdx] = &blame[oldIdx]
+		}
+		oldIdx++
+		newIdx++
+	}
+	for _, h := range diff.Compute(blamed.String(), content+"\n", 0) {
+		for newIdx < h.NewStart {
+			advance()
+		}
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case diff.Equal:
+				advance()
+			case diff.Delete:
+				oldIdx++
+			case diff.Insert:
+				newIdx++
+			}
+		}
+	}
+	for newIdx < len(aligned) && oldIdx < len(blame) {
+		advance()
+	}
+	return aligned
+}
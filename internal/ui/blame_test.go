@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// blameOf returns blame lines for texts, one commit per text prefix letter
+func blameOf(texts ...string) []git.BlameLine {
+	var lines []git.BlameLine
+	for _, text := range texts {
+		lines = append(lines, git.BlameLine{Hash: "abc" + text[:1] + "123", Author: "Alice", Text: text})
+	}
+	return lines
+}
+
+func TestAlignBlame(t *testing.T) {
+	blame := blameOf("one", "two", "three")
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string // blamed text per row, "" for none
+	}{
+		{"unchanged", "one\ntwo\nthree\n", []string{"one", "two", "three", ""}},
+		{"no final newline", "one\ntwo\nthree", []string{"one", "two", "three"}},
+		{"line inserted", "one\nnew\ntwo\nthree\n", []string{"one", "", "two", "three", ""}},
+		{"line changed", "one\nTWO\nthree\n", []string{"one", "", "three", ""}},
+		{"line deleted", "one\nthree\n", []string{"one", "three", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aligned := alignBlame(blame, tt.content)
+			var got []string
+			for _, line := range aligned {
+				if line == nil {
+					got = append(got, "")
+				} else {
+					got = append(got, line.Text)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorPane_BlameGutter(t *testing.T) {
+	editor, path := newValidatedEditor(t, "package main\n\nfunc main() {}\n")
+	editor.SetSize(100, 20)
+
+	editor.SetBlame(path, []git.BlameLine{
+		{Hash: "1234567", Author: "Alice Longname", Text: "package main"},
+		{Hash: "1234567", Author: "Alice Longname", Text: ""},
+		{Hash: "89abcde", Author: "Bob", Text: "func main() {}"},
+	})
+	if !editor.BlameVisible() {
+		t.Fatal("expected the blame to be visible for the open file")
+	}
+	view := editor.View()
+	for _, want := range []string{"1234567 Alice Long", "89abcde Bob"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// Typing a new line marks it as uncommitted
+	editor.SetCursorPosition(2, 0)
+	editor.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(editor.View(), "(uncommitted)") {
+		t.Error("expected the inserted line to be marked uncommitted")
+	}
+
+	editor.ClearBlame()
+	if editor.BlameVisible() || strings.Contains(editor.View(), "89abcde") {
+		t.Error("expected the blame gutter to be hidden")
+	}
+}
+
+func TestEditorPane_BlameOnlyForBlamedFile(t *testing.T) {
+	editor, _ := newValidatedEditor(t, "package main\n")
+	editor.SetBlame("/elsewhere/other.go", blameOf("package main"))
+	if editor.BlameVisible() {
+		t.Error("blame of another file should not be shown")
+	}
+}
+
+func TestApp_AltBTogglesBlame(t *testing.T) {
+	pane, repo := newBranchTestPane(t)
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+	editor := NewEditorPane(filemanager.NewFileManager(root))
+	editor.SetSize(100, 20)
+	if err := editor.LoadFile("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	pane.visible = false
+	app := newTestApp(t, root)
+	app.editorPane = editor
+	app.gitPane = pane
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true})
+	if cmd == nil {
+		t.Fatal("expected Alt+B to load the blame")
+	}
+	app.Update(cmd())
+	if !editor.BlameVisible() {
+		t.Fatalf("expected the blame to be shown, status: %s", app.statusMessage)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true})
+	if editor.BlameVisible() {
+		t.Error("expected a second Alt+B to hide the blame")
+	}
+
+	app.Update(GitBlameMsg{Path: editor.currentFilePath(), Error: errors.New("boom")})
+	if !strings.Contains(app.statusMessage, "Blame unavailable: boom") {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+}
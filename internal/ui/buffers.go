@@ -0,0 +1,365 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// editorBuffer holds the state of one open buffer. The active buffer is
+// edited in place in the EditorPane fields and only copied into its slot
+// when another buffer is shown, so the editing code works on one buffer at
+// a time.
+type editorBuffer struct {
+	content         string
+	originalContent string
+	cursorLine      int
+	cursorCol       int
+	scrollOffset    int
+	currentFile     *types.FileMetadata
+	diffMarkers     map[int]string
+	undoStack       []editorSnapshot
+	redoStack       []editorSnapshot
+	suggestedName   string
+}
+
+// modified reports whether b has unsaved changes.
+func (b editorBuffer) modified() bool {
+	return b.content != b.originalContent || len(b.diffMarkers) > 0
+}
+
+// bufferTab describes a buffer for the tab bar.
+type bufferTab struct {
+	Name     string // File name, or the suggested name of an unsaved buffer
+	Modified bool   // Whether the buffer has unsaved changes
+	Active   bool   // Whether the buffer is shown
+}
+
+// ensureBuffers makes sure the active buffer has a slot.
+func (e *EditorPane) ensureBuffers() {
+	if len(e.buffers) == 0 {
+		e.buffers = make([]editorBuffer, 1)
+		e.activeBuffer = 0
+	}
+}
+
+// storeBuffer copies the active buffer's state into its slot.
+func (e *EditorPane) storeBuffer() {
+	e.ensureBuffers()
+	e.buffers[e.activeBuffer] = editorBuffer{
+		content:         e.content,
+		originalContent: e.originalContent,
+		cursorLine:      e.cursorLine,
+		cursorCol:       e.cursorCol,
+		scrollOffset:    e.scrollOffset,
+		currentFile:     e.currentFile,
+		diffMarkers:     e.diffMarkers,
+		undoStack:       e.undoStack,
+		redoStack:       e.redoStack,
+		suggestedName:   e.suggestedName,
+	}
+}
+
+// showBuffer makes buffer i active. The state of the previously active
+// buffer must already be stored.
+func (e *EditorPane) showBuffer(i int) {
+	b := e.buffers[i]
+	e.activeBuffer = i
+	e.content = b.content
+	e.originalContent = b.originalContent
+	e.cursorLine = b.cursorLine
+	e.cursorCol = b.cursorCol
+	e.scrollOffset = b.scrollOffset
+	e.currentFile = b.currentFile
+	e.diffMarkers = b.diffMarkers
+	if e.diffMarkers == nil {
+		e.diffMarkers = make(map[int]string)
+	}
+	e.undoStack = b.undoStack
+	e.redoStack = b.redoStack
+	e.suggestedName = b.suggestedName
+	e.pendingAltD = false
+	e.selecting = false
+	e.hover = nil
+	e.completion = nil
+}
+
+// isScratch reports whether the active buffer is an untouched empty buffer,
+// which is reused instead of opening a new tab next to it.
+func (e *EditorPane) isScratch() bool {
+	return e.currentFile == nil && e.content == "" && e.originalContent == "" && len(e.undoStack) == 0
+}
+
+// openBuffer makes room for a new buffer: it adds an empty buffer after the
+// existing ones and shows it, unless the active buffer is an untouched
+// scratch buffer, which is reused.
+func (e *EditorPane) openBuffer() {
+	e.ensureBuffers()
+	if e.isScratch() {
+		return
+	}
+	e.storeBuffer()
+	e.buffers = append(e.buffers, editorBuffer{})
+	e.showBuffer(len(e.buffers) - 1)
+}
+
+// findBuffer returns the index of the buffer holding the file at path, or
+// -1 if the file is not open.
+func (e *EditorPane) findBuffer(path string) int {
+	e.ensureBuffers()
+	want := e.resolvePath(path)
+	for i := range e.buffers {
+		file := e.buffers[i].currentFile
+		if i == e.activeBuffer {
+			file = e.currentFile
+		}
+		if file != nil && e.resolvePath(file.Filepath) == want {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolvePath returns the absolute, cleaned form of path for comparisons.
+func (e *EditorPane) resolvePath(path string) string {
+	if e.fileManager != nil {
+		return e.fileManager.ResolvePath(path)
+	}
+	return filepath.Clean(path)
+}
+
+// BufferCount returns the number of open buffers.
+func (e *EditorPane) BufferCount() int {
+	return max(len(e.buffers), 1)
+}
+
+// ActiveBuffer returns the index of the shown buffer.
+func (e *EditorPane) ActiveBuffer() int {
+	return e.activeBuffer
+}
+
+// SwitchBuffer shows buffer i, keeping the cursor, scroll position and undo
+// history of the buffer it leaves. Out of range indexes are ignored.
+func (e *EditorPane) SwitchBuffer(i int) {
+	e.ensureBuffers()
+	if i < 0 || i >= len(e.buffers) || i == e.activeBuffer {
+		return
+	}
+	e.storeBuffer()
+	e.showBuffer(i)
+	e.touchRecent()
+}
+
+// NextBuffer shows the buffer after the active one, wrapping around.
+func (e *EditorPane) NextBuffer() {
+	e.SwitchBuffer((e.activeBuffer + 1) % e.BufferCount())
+}
+
+// PrevBuffer shows the buffer before the active one, wrapping around.
+func (e *EditorPane) PrevBuffer() {
+	n := e.BufferCount()
+	e.SwitchBuffer((e.activeBuffer + n - 1) % n)
+}
+
+// CloseAll closes every buffer, leaving one empty buffer.
+func (e *EditorPane) CloseAll() {
+	e.buffers = nil
+	e.activeBuffer = 0
+	e.CloseFile()
+}
+
+// RenamePath points the buffers of files at oldPath, or below it when it
+// is a directory, to where they were moved to newPath.
+func (e *EditorPane) RenamePath(oldPath, newPath string) {
+	e.storeBuffer()
+	oldPath = e.resolvePath(oldPath)
+	newPath = e.resolvePath(newPath)
+	for i := range e.buffers {
+		file := e.buffers[i].currentFile
+		if file == nil {
+			continue
+		}
+		path := e.resolvePath(file.Filepath)
+		rel, err := filepath.Rel(oldPath, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		moved := *file
+		moved.Filepath = filepath.Join(newPath, rel)
+		moved.FileType = determineFileType(moved.Filepath)
+		e.buffers[i].currentFile = &moved
+	}
+	e.currentFile = e.buffers[e.activeBuffer].currentFile
+}
+
+// IsUnsaved reports whether the file at path is open in a buffer with
+// unsaved changes.
+func (e *EditorPane) IsUnsaved(path string) bool {
+	e.storeBuffer()
+	i := e.findBuffer(path)
+	if i < 0 {
+		return false
+	}
+	return e.buffers[i].modified()
+}
+
+// ReloadFiles re-reads the buffers of the files at paths from disk after
+// they were written elsewhere. Buffers with unsaved changes are kept as
+// they are; the cursor of a reloaded buffer stays where it was, as far as
+// the new content allows.
+func (e *EditorPane) ReloadFiles(paths []string) {
+	e.storeBuffer()
+	for _, path := range paths {
+		i := e.findBuffer(path)
+		if i < 0 || e.buffers[i].modified() {
+			continue
+		}
+		b := &e.buffers[i]
+		content, err := e.fileManager.ReadFile(b.currentFile.Filepath)
+		if err != nil {
+			continue
+		}
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		content = strings.ReplaceAll(content, "\r", "\n")
+		lines := strings.Split(content, "\n")
+		b.content = content
+		b.originalContent = content
+		b.cursorLine = min(b.cursorLine, len(lines)-1)
+		b.cursorCol = min(b.cursorCol, len(lines[b.cursorLine]))
+		b.scrollOffset = min(b.scrollOffset, b.cursorLine)
+		b.undoStack = nil
+		b.redoStack = nil
+	}
+	e.showBuffer(e.activeBuffer)
+}
+
+// OpenFiles returns the absolute paths of the files open in buffers, in tab
+// order.
+func (e *EditorPane) OpenFiles() []string {
+	e.storeBuffer()
+	var paths []string
+	for _, b := range e.buffers {
+		if b.currentFile != nil {
+			paths = append(paths, e.resolvePath(b.currentFile.Filepath))
+		}
+	}
+	return paths
+}
+
+// maxRecentFiles is the number of recently shown files remembered for the
+// go-to-file palette.
+const maxRecentFiles = 50
+
+// touchRecent moves the file of the active buffer to the front of the
+// recently shown files.
+func (e *EditorPane) touchRecent() {
+	if e.currentFile == nil {
+		return
+	}
+	path := e.resolvePath(e.currentFile.Filepath)
+	recent := []string{path}
+	for _, p := range e.recentFiles {
+		if p != path && len(recent) < maxRecentFiles {
+			recent = append(recent, p)
+		}
+	}
+	e.recentFiles = recent
+}
+
+// RecentFiles returns the absolute paths of recently shown files, most
+// recent first.
+func (e *EditorPane) RecentFiles() []string {
+	return e.recentFiles
+}
+
+// UnsavedBuffers returns the names of the buffers with unsaved changes, in
+// tab order.
+func (e *EditorPane) UnsavedBuffers() []string {
+	var names []string
+	for _, tab := range e.tabs() {
+		if tab.Modified {
+			names = append(names, tab.Name)
+		}
+	}
+	return names
+}
+
+// tabs describes the open buffers in tab order.
+func (e *EditorPane) tabs() []bufferTab {
+	e.storeBuffer()
+	tabs := make([]bufferTab, len(e.buffers))
+	for i, b := range e.buffers {
+		tabs[i] = bufferTab{
+			Name:     bufferName(b),
+			Modified: b.modified(),
+			Active:   i == e.activeBuffer,
+		}
+	}
+	return tabs
+}
+
+// bufferName returns the tab label of b.
+func bufferName(b editorBuffer) string {
+	switch {
+	case b.currentFile != nil:
+		return filepath.Base(b.currentFile.Filepath)
+	case b.suggestedName != "":
+		return b.suggestedName
+	default:
+		return "untitled"
+	}
+}
+
+// TabBar renders the open buffers on one line of at most width columns:
+// each tab is numbered for Alt+1..9, unsaved buffers are marked with "*"
+// and the active tab is bracketed. Tabs far from the active one are
+// replaced by "…" when they do not fit.
+func (e *EditorPane) TabBar(width int) string {
+	tabs := e.tabs()
+	labels := make([]string, len(tabs))
+	active := 0
+	for i, tab := range tabs {
+		label := fmt.Sprintf("%d %s", i+1, tab.Name)
+		if tab.Modified {
+			label += " *"
+		}
+		if tab.Active {
+			label = "[" + label + "]"
+			active = i
+		} else {
+			label = " " + label + " "
+		}
+		labels[i] = label
+	}
+
+	// Grow a window around the active tab while it fits
+	first, last := active, active
+	used := len([]rune(labels[active]))
+	for {
+		grew := false
+		if last+1 < len(labels) && used+len([]rune(labels[last+1]))+3 <= width {
+			last++
+			used += len([]rune(labels[last])) + 1
+			grew = true
+		}
+		if first > 0 && used+len([]rune(labels[first-1]))+3 <= width {
+			first--
+			used += len([]rune(labels[first])) + 1
+			grew = true
+		}
+		if !grew {
+			break
+		}
+	}
+
+	bar := strings.Join(labels[first:last+1], " ")
+	if first > 0 {
+		bar = "…" + bar
+	}
+	if last < len(labels)-1 {
+		bar += "…"
+	}
+	return bar
+}
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// newBufferEditor returns a focused editor over a workspace holding files,
+// given as name and content pairs
+func newBufferEditor(t *testing.T, files ...string) *EditorPane {
+	t.Helper()
+	fm := filemanager.NewFileManager(t.TempDir())
+	for i := 0; i < len(files); i += 2 {
+		if err := fm.CreateFile(files[i], files[i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	editor := NewEditorPane(fm)
+	editor.SetSize(80, 20)
+	editor.SetFocused(true)
+	return editor
+}
+
+// typeInto sends text to the editor one key at a time
+func typeInto(editor *EditorPane, text string) {
+	for _, r := range text {
+		editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// openName returns the name of the file shown in editor
+func openName(editor *EditorPane) string {
+	if editor.currentFile == nil {
+		return ""
+	}
+	return editor.currentFile.Filepath
+}
+
+func TestEditorPane_LoadFileOpensTabs(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "echo a\n", "b.sh", "echo b\n")
+
+	editor.LoadFile("a.sh")
+	if editor.BufferCount() != 1 {
+		t.Fatalf("expected the empty buffer to be reused, got %d buffers", editor.BufferCount())
+	}
+	editor.LoadFile("b.sh")
+	if editor.BufferCount() != 2 || editor.ActiveBuffer() != 1 || openName(editor) != "b.sh" {
+		t.Fatalf("expected b.sh in a second tab, got %d buffers showing %q", editor.BufferCount(), openName(editor))
+	}
+
+	// Opening an open file switches to its tab
+	editor.LoadFile("a.sh")
+	if editor.BufferCount() != 2 || editor.ActiveBuffer() != 0 || editor.GetContent() != "echo a\n" {
+		t.Errorf("expected to switch back to a.sh, got %d buffers showing %q", editor.BufferCount(), openName(editor))
+	}
+}
+
+func TestEditorPane_BuffersKeepTheirState(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "echo a\n", "b.sh", "echo b\n")
+	editor.LoadFile("a.sh")
+	typeInto(editor, "X")
+	editor.LoadFile("b.sh")
+	editor.SetCursorPosition(0, 4)
+	typeInto(editor, "Y")
+
+	editor.SwitchBuffer(0)
+	if editor.GetContent() != "Xecho a\n" || !editor.HasUnsavedChanges() || editor.cursorCol != 1 {
+		t.Errorf("expected a.sh with its edit and cursor, got %q at %d", editor.GetContent(), editor.cursorCol)
+	}
+
+	// Undo only affects the shown buffer
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u"), Alt: true})
+	if editor.GetContent() != "echo a\n" || editor.HasUnsavedChanges() {
+		t.Errorf("expected the edit of a.sh to be undone, got %q", editor.GetContent())
+	}
+	editor.SwitchBuffer(1)
+	if editor.GetContent() != "echoY b\n" {
+		t.Errorf("expected b.sh to keep its edit, got %q", editor.GetContent())
+	}
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u"), Alt: true})
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u"), Alt: true})
+	if editor.GetContent() != "echo b\n" {
+		t.Errorf("undo should stop at the start of b.sh's history, got %q", editor.GetContent())
+	}
+}
+
+func TestEditorPane_LoadFileKeepsUnsavedChanges(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "echo a\n", "b.sh", "echo b\n")
+	editor.LoadFile("a.sh")
+	typeInto(editor, "X")
+	editor.LoadFile("b.sh")
+
+	if err := editor.LoadFile("a.sh"); err != nil {
+		t.Fatal(err)
+	}
+	if editor.GetContent() != "Xecho a\n" {
+		t.Errorf("expected the unsaved edit to be kept, got %q", editor.GetContent())
+	}
+
+	// Without unsaved changes the file is reloaded from disk
+	editor.SwitchBuffer(1)
+	editor.GetFileManager().WriteFile("b.sh", "echo changed\n")
+	editor.LoadFile("b.sh")
+	if editor.GetContent() != "echo changed\n" {
+		t.Errorf("expected b.sh to be reloaded, got %q", editor.GetContent())
+	}
+}
+
+func TestEditorPane_SwitchKeys(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "a\n", "b.sh", "b\n", "c.sh", "c\n")
+	for _, name := range []string{"a.sh", "b.sh", "c.sh"} {
+		editor.LoadFile(name)
+	}
+
+	tests := []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("."), Alt: true}, "a.sh"}, // wraps around
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("."), Alt: true}, "b.sh"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(","), Alt: true}, "a.sh"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(","), Alt: true}, "c.sh"}, // wraps around
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: true}, "b.sh"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9"), Alt: true}, "b.sh"}, // no such tab
+		{tea.KeyMsg{Type: tea.KeyCtrlPgDown}, "c.sh"},
+		{tea.KeyMsg{Type: tea.KeyCtrlPgUp}, "b.sh"},
+	}
+	for _, tt := range tests {
+		editor.Update(tt.key)
+		if got := openName(editor); got != tt.want {
+			t.Errorf("after %s: expected %s, got %s", tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestEditorPane_AltDDigitStillDeletesLines(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "1\n2\n3\n", "b.sh", "b\n")
+	editor.LoadFile("b.sh")
+	editor.LoadFile("a.sh")
+
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d"), Alt: true})
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: true})
+	if openName(editor) != "a.sh" || editor.GetContent() != "3\n" {
+		t.Errorf("expected Alt+D, Alt+2 to delete two lines, got %q in %s", editor.GetContent(), openName(editor))
+	}
+}
+
+func TestEditorPane_CloseFileShowsNeighbour(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "a\n", "b.sh", "b\n", "c.sh", "c\n")
+	for _, name := range []string{"a.sh", "b.sh", "c.sh"} {
+		editor.LoadFile(name)
+	}
+	editor.SwitchBuffer(1)
+
+	editor.CloseFile()
+	if editor.BufferCount() != 2 || openName(editor) != "c.sh" {
+		t.Fatalf("expected c.sh after closing b.sh, got %s", openName(editor))
+	}
+	editor.CloseFile()
+	if openName(editor) != "a.sh" {
+		t.Fatalf("expected a.sh after closing the last tab, got %s", openName(editor))
+	}
+	editor.CloseFile()
+	if editor.BufferCount() != 1 || editor.currentFile != nil || editor.GetContent() != "" {
+		t.Error("expected an empty editor after closing the only tab")
+	}
+}
+
+func TestEditorPane_UnsavedBuffersAndCloseAll(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "a\n", "b.sh", "b\n")
+	editor.LoadFile("a.sh")
+	typeInto(editor, "X")
+	editor.LoadFile("b.sh")
+	editor.SetContentUnsaved("generated\n", "gen.sh")
+
+	if got := strings.Join(editor.UnsavedBuffers(), ","); got != "a.sh,gen.sh" {
+		t.Errorf("expected a.sh and gen.sh to be unsaved, got %s", got)
+	}
+	if editor.HasUnsavedChanges() != true || editor.BufferCount() != 3 {
+		t.Errorf("expected the generated content in a third tab")
+	}
+
+	editor.CloseAll()
+	if editor.BufferCount() != 1 || len(editor.UnsavedBuffers()) != 0 || editor.currentFile != nil {
+		t.Error("expected CloseAll to leave one empty buffer")
+	}
+}
+
+func TestEditorPane_TabBar(t *testing.T) {
+	editor := newBufferEditor(t, "alpha.sh", "a\n", "beta.sh", "b\n", "gamma.sh", "c\n", "delta.sh", "d\n")
+	for _, name := range []string{"alpha.sh", "beta.sh", "gamma.sh", "delta.sh"} {
+		editor.LoadFile(name)
+	}
+	editor.SwitchBuffer(1)
+	typeInto(editor, "X")
+
+	bar := editor.TabBar(200)
+	for _, want := range []string{" 1 alpha.sh ", "[2 beta.sh *]", " 3 gamma.sh ", " 4 delta.sh "} {
+		if !strings.Contains(bar, want) {
+			t.Errorf("expected %q in %q", want, bar)
+		}
+	}
+
+	bar = editor.TabBar(30)
+	if !strings.Contains(bar, "[2 beta.sh *]") || !strings.Contains(bar, "…") || len([]rune(bar)) > 30 {
+		t.Errorf("expected a shortened bar around the active tab, got %q", bar)
+	}
+}
+
+func TestApp_EditorTitleBarShowsTabs(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "a\n", "b.sh", "b\n")
+	app := &App{editorPane: editor, width: 100, config: &types.AppConfig{}}
+
+	editor.LoadFile("a.sh")
+	if title := app.renderEditorTitleBar(); !strings.Contains(title, "Editor: a.sh") {
+		t.Errorf("expected a single file title, got %q", title)
+	}
+	editor.LoadFile("b.sh")
+	if title := app.renderEditorTitleBar(); !strings.Contains(title, "1 a.sh") || !strings.Contains(title, "[2 b.sh]") {
+		t.Errorf("expected a tab bar, got %q", title)
+	}
+}
+
+func TestApp_ProjectFilesOpenInTabs(t *testing.T) {
+	editor := newBufferEditor(t, "a.sh", "a\n", "b.sh", "b\n", "c.sh", "c\n")
+	app := &App{
+		editorPane: editor,
+		aiPane:     NewAIChatPane(nil, "test-model", "ollama", t.TempDir()),
+		config:     &types.AppConfig{},
+	}
+
+	msg := tea.Msg(ProjectFileOpenMsg{Paths: []string{"a.sh", "b.sh", "c.sh"}})
+	for msg != nil {
+		_, cmd := app.Update(msg)
+		msg = nil
+		if cmd != nil {
+			if next, ok := cmd().(ProjectFileOpenMsg); ok {
+				msg = next
+			}
+		}
+	}
+	if editor.BufferCount() != 3 || openName(editor) != "c.sh" {
+		t.Errorf("expected three tabs showing c.sh, got %d showing %s", editor.BufferCount(), openName(editor))
+	}
+}
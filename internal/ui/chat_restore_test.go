@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/user/terminal-intelligence/internal/agentic"
 	"github.com/user/terminal-intelligence/internal/types"
 )
@@ -56,6 +58,9 @@ Do you want to proceed? Type /proceed to continue or /cancel to abort.`,
 	if app.autonomousCreator.State != agentic.StateWaitingApproval {
 		t.Errorf("Expected state StateWaitingApproval, got %v", app.autonomousCreator.State)
 	}
+	if app.autonomousState != agentic.StateWaitingApproval {
+		t.Errorf("Expected the App to track StateWaitingApproval, got %v", app.autonomousState)
+	}
 
 	// Verify description was extracted
 	expectedDesc := "A simple web server"
@@ -147,3 +152,40 @@ More details`,
 		})
 	}
 }
+
+// TestAutonomousStep_ReportsThroughMessages verifies that a step running in
+// its command hands the file to open and the progress lines to Update.
+func TestAutonomousStep_ReportsThroughMessages(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp(t, dir)
+	app.progressCh = make(chan AgentProgressMsg, agentProgressBuffer)
+	logger := agentic.NewActionLogger(agentProgressNotifier(app.progressCh))
+	app.autonomousCreator = agentic.NewAutonomousCreator(&replyAIClient{reply: "# Demo"}, "test-model", dir, "demo", nil, logger)
+	app.autonomousCreator.ProjectDir = dir
+	app.autonomousCreator.State = agentic.StateDocumentation
+
+	_, cmd := app.Update(AutonomousTickMsg{})
+	done := make(chan tea.Msg)
+	go func() {
+		logger.Log("Writing the summary")
+		done <- cmd()
+	}()
+	app.View() // Rendering while the step runs must not race with it
+
+	progress := waitForAgentProgress(app.progressCh)()
+	app.Update(progress)
+	last := app.aiPane.messages[len(app.aiPane.messages)-1]
+	if !last.IsNotification || !strings.HasSuffix(last.Content, "Writing the summary") {
+		t.Errorf("expected the progress line in the chat, got %+v", last)
+	}
+
+	step := (<-done).(AutonomousStepMsg)
+	summary := filepath.Join(dir, "SUMMARY.md")
+	if step.FileToOpen != summary {
+		t.Fatalf("expected the step to ask for SUMMARY.md, got %q", step.FileToOpen)
+	}
+	app.Update(step)
+	if openName(app.editorPane) != summary || app.activePane != types.EditorPaneType {
+		t.Errorf("expected SUMMARY.md open in the editor, got %q", openName(app.editorPane))
+	}
+}
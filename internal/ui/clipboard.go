@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// terminalOutput is the program's output. Bubble Tea renders to it while
+// clipboard commands write OSC 52 sequences to it, so writes are serialized
+// to keep a sequence from landing inside a frame. It stays an *os.File so
+// Bubble Tea still sees the terminal.
+type terminalOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (o *terminalOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
+func (o *terminalOutput) WriteString(s string) (int, error) {
+	return o.Write([]byte(s))
+}
+
+// Output returns the writer the program must render to; see terminalOutput.
+func (a *App) Output() io.Writer {
+	return a.output
+}
+
+// clipboardErrorMsg reports that the OSC 52 sequence could not be written.
+type clipboardErrorMsg struct {
+	err error
+}
+
+// writeClipboard copies text to the system clipboard. Over SSH, or when no
+// clipboard tool is installed, it returns a command that asks the terminal
+// to set its clipboard with an OSC 52 escape sequence on the program's
+// output instead.
+func (a *App) writeClipboard(text string) tea.Cmd {
+	if !remoteSession() && !clipboard.Unsupported {
+		if err := clipboard.WriteAll(text); err == nil {
+			return nil
+		}
+	}
+	out := a.output
+	return func() tea.Msg {
+		if err := writeOSC52(out, text); err != nil {
+			return clipboardErrorMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// remoteSession reports whether TI runs in an SSH session, where the
+// system clipboard belongs to another machine.
+func remoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// writeOSC52 writes the escape sequence that sets the terminal's clipboard
+// to text, wrapped for tmux when running inside it.
+func writeOSC52(w io.Writer, text string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	_, err := io.WriteString(w, seq)
+	return err
+}
+
+// copyToClipboard copies text and reports it in the status bar. A failed
+// OSC 52 write replaces the status through clipboardErrorMsg.
+func (a *App) copyToClipboard(text string) tea.Cmd {
+	if text == "" {
+		a.statusMessage = "Nothing to copy"
+		return nil
+	}
+	a.statusMessage = "Copied to clipboard"
+	return a.writeClipboard(text)
+}
+
+// cutSelection copies the editor selection to the clipboard and removes it.
+func (a *App) cutSelection() tea.Cmd {
+	cmd := a.copyToClipboard(a.editorPane.SelectedText())
+	a.editorPane.deleteSelection()
+	a.statusMessage = "Cut to clipboard"
+	return cmd
+}
+
+// pasteClipboard inserts the clipboard contents at the editor cursor,
+// replacing the selection.
+func (a *App) pasteClipboard() {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		a.statusMessage = "Error reading clipboard: " + err.Error()
+		if remoteSession() {
+			a.statusMessage += " (use your terminal's paste over SSH)"
+		}
+		return
+	}
+	a.editorPane.InsertText(text)
+}
+
+// attachSelection sends the editor selection with the next AI message, in
+// place of the whole file, and moves the focus to the AI input.
+func (a *App) attachSelection() {
+	text := a.editorPane.SelectedText()
+	if text == "" {
+		a.statusMessage = "Select text to send to the AI first (Shift+arrows or drag)"
+		return
+	}
+	a.selectionContext = text
+	a.activePane = types.AIPaneType
+	a.editorPane.focused = false
+	a.aiPane.focused = true
+	a.aiPane.SetActiveArea(0)
+	a.statusMessage = fmt.Sprintf("Selection attached (%d lines) — it is sent with your next message", strings.Count(text, "\n")+1)
+}
+
+// takeChatContext returns the code context to send with a chat message: the
+// attached selection, used once, or else fileContent.
+func (a *App) takeChatContext(fileContent string) string {
+	if a.selectionContext == "" {
+		return fileContent
+	}
+	selection := a.selectionContext
+	a.selectionContext = ""
+	return selection
+}
+
+// editorTop returns the screen row of the editor pane's top border, below
+// the header and the editor title bar.
+func (a *App) editorTop() int {
+	return lipgloss.Height(a.renderHeader()) + lipgloss.Height(a.renderEditorTitleBar())
+}
+
+// toggleMouse releases the mouse to the terminal, so it can select and copy
+// text itself, or captures it again for TI.
+func (a *App) toggleMouse() tea.Cmd {
+	a.mouseReleased = !a.mouseReleased
+	if a.mouseReleased {
+		a.statusMessage = "Mouse released to the terminal (F9 to capture it again)"
+		return tea.DisableMouse
+	}
+	a.statusMessage = "Mouse captured"
+	return tea.EnableMouseCellMotion
+}
+
+// dialogOpen reports whether a popup or dialog covers the panes.
+func (a *App) dialogOpen() bool {
+	return a.showExitConfirmation || a.showFilePrompt || a.showFilePicker || a.showFolderPicker ||
+		a.showFolderCreatePrompt || a.showFindPrompt || a.showFindReplacePrompt || a.showBackupPicker ||
+		a.showChatLoader || a.showHelp || a.showLanguageInstallPrompt ||
+		a.gitPane.IsVisible() || a.reviewPane.IsVisible() || a.palette.IsVisible() ||
+		a.replacePane.IsVisible() || a.showRenamePrompt
+}
+
+// handleMouse handles mouse input. The wheel scrolls as the arrow keys do,
+// as the terminal did before TI captured the mouse; a click in the editor
+// focuses it and moves the cursor, and dragging selects text. A click in
+// the file tree selects a row, and a second click opens it.
+func (a *App) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		if msg.Action != tea.MouseActionPress {
+			return nil
+		}
+		key := tea.KeyMsg{Type: tea.KeyUp}
+		if msg.Button == tea.MouseButtonWheelDown {
+			key.Type = tea.KeyDown
+		}
+		_, cmd := a.Update(key)
+		return cmd
+	}
+
+	if a.dialogOpen() {
+		return nil
+	}
+	y := msg.Y - a.editorTop()
+	if y < 0 || y >= a.editorPane.height {
+		return nil
+	}
+
+	// A click in the file tree focuses it and selects the row
+	x := msg.X
+	if a.fileTree.IsVisible() {
+		if x < a.fileTree.width {
+			if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+				return nil
+			}
+			a.focusPane(types.FileTreePaneType)
+			return a.fileTree.HandleClick(y)
+		}
+		x -= a.fileTree.width
+	}
+
+	if x >= a.editorPane.width-2 {
+		return nil
+	}
+	if msg.Action == tea.MouseActionPress && a.activePane != types.EditorPaneType {
+		a.focusPane(types.EditorPaneType)
+	}
+	if a.activePane == types.EditorPaneType {
+		a.editorPane.HandleMouse(msg, x, y)
+	}
+	return nil
+}
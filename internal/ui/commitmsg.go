@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/commitmsg"
+)
+
+// GitSuggestCommitMsg asks the App to suggest a commit message for the
+// staged changes. The Git panel sends it on Alt+S because only the App has
+// the AI client.
+type GitSuggestCommitMsg struct{}
+
+// GitCommitSuggestionMsg carries a suggested commit message.
+type GitCommitSuggestionMsg struct {
+	Message  string
+	FromChat bool // Whether it was requested with /commit rather than from the Git panel
+	Error    error
+}
+
+// suggestCommitMessage returns a command that sends the staged diff to the
+// configured model and returns its commit message suggestion. The request
+// can be stopped like any other generation.
+func (a *App) suggestCommitMessage(fromChat bool) tea.Cmd {
+	client := a.aiPane.aiClient
+	model := a.aiPane.model
+	gitClient := a.gitPane.gitClient
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancelSuggestion = cancel
+	return func() tea.Msg {
+		defer cancel()
+		if gitClient == nil {
+			return GitCommitSuggestionMsg{FromChat: fromChat, Error: fmt.Errorf("no repository")}
+		}
+		files, err := gitClient.StagedDiff()
+		if err != nil {
+			return GitCommitSuggestionMsg{FromChat: fromChat, Error: err}
+		}
+		message, err := commitmsg.Generate(ctx, client, model, files, ai.DefaultPromptTokenBudget)
+		return GitCommitSuggestionMsg{Message: message, FromChat: fromChat, Error: err}
+	}
+}
+
+// handleCommitSuggestion shows a suggested commit message. A suggestion
+// requested with /commit is also shown in the chat, and the Git panel is
+// opened so it can be edited and committed.
+func (a *App) handleCommitSuggestion(msg GitCommitSuggestionMsg) tea.Cmd {
+	a.cancelSuggestion = nil
+	var cmds []tea.Cmd
+	if msg.FromChat {
+		a.statusMessage = ""
+		if ai.IsCancelled(msg.Error) {
+			a.aiPane.DisplayNotification("Commit message suggestion stopped.")
+			return nil
+		}
+		if msg.Error != nil {
+			a.aiPane.DisplayNotification("Could not suggest a commit message: " + msg.Error.Error())
+			return nil
+		}
+		a.aiPane.DisplayNotification("Suggested commit message:\n\n```\n" + msg.Message + "\n```\n\nIt is filled in on the Git panel's Commit button; edit it there and press Enter to commit.")
+		if !a.gitPane.IsVisible() {
+			cmds = append(cmds, a.gitPane.Toggle())
+		}
+	}
+	_, cmd := a.gitPane.Update(msg)
+	cmds = append(cmds, cmd)
+	return tea.Batch(cmds...)
+}
+
+// requestSuggestion asks the App for a commit message suggestion.
+func (g *GitPane) requestSuggestion() tea.Cmd {
+	g.isProcessing = true
+	g.statusMessage = "Generating commit message from the staged diff..."
+	g.errorMessage = ""
+	return func() tea.Msg {
+		return GitSuggestCommitMsg{}
+	}
+}
+
+// setSuggestion fills the commit message input with a suggestion. The input
+// holds a single line, so the body is kept aside, shown below it and added
+// back when committing. Editing the subject or leaving the input drops the
+// body, since it no longer describes what is committed.
+func (g *GitPane) setSuggestion(msg GitCommitSuggestionMsg) {
+	g.isProcessing = false
+	if ai.IsCancelled(msg.Error) {
+		g.statusMessage = "Suggestion stopped"
+		g.errorMessage = ""
+		return
+	}
+	if msg.Error != nil {
+		g.errorMessage = msg.Error.Error()
+		g.statusMessage = ""
+		return
+	}
+	subject, body := commitmsg.Split(msg.Message)
+	g.selectedButton = 4
+	g.focusedInput = 4
+	g.updateFocus()
+	g.commitMsgInput.SetValue(subject)
+	g.commitMsgInput.CursorEnd()
+	g.commitBody = body
+	g.statusMessage = "Suggested message; press Enter to commit it, or edit the subject to write your own"
+	g.errorMessage = ""
+}
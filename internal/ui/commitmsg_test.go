@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// replyAIClient answers every prompt with a fixed reply and keeps the last
+// prompt
+type replyAIClient struct {
+	reply  string
+	prompt string
+}
+
+func (c *replyAIClient) IsAvailable() (bool, error) { return true, nil }
+
+func (c *replyAIClient) ListModels() ([]string, error) { return []string{"test-model"}, nil }
+
+func (c *replyAIClient) Generate(prompt string, model string, context []int, onTokenUsage func(types.TokenUsage)) (<-chan string, error) {
+	c.prompt = prompt
+	ch := make(chan string, 1)
+	ch <- c.reply
+	close(ch)
+	return ch, nil
+}
+
+// newCommitTestApp returns an App whose repository has a staged change to
+// README.md and whose model replies with reply
+func newCommitTestApp(t *testing.T, reply string) (*App, *replyAIClient) {
+	t.Helper()
+	pane, repo := newBranchTestPane(t)
+	pane.visible = false
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\n\nUsage notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree.Add("README.md")
+
+	client := &replyAIClient{reply: reply}
+	app := newTestApp(t, root)
+	app.aiPane = NewAIChatPane(client, "test-model", "ollama", root)
+	app.gitPane = pane
+	return app, client
+}
+
+func TestGitPane_AltSRequestsSuggestion(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.focusedInput = 3
+	pane.selectedButton = 4
+
+	_, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s"), Alt: true})
+	if cmd == nil {
+		t.Fatal("expected Alt+S to request a suggestion")
+	}
+	if _, ok := cmd().(GitSuggestCommitMsg); !ok {
+		t.Fatal("expected a GitSuggestCommitMsg")
+	}
+	if !pane.isProcessing || !strings.Contains(pane.statusMessage, "Generating") {
+		t.Errorf("expected a progress status, got %q", pane.statusMessage)
+	}
+
+	// Only the Commit button suggests messages
+	pane.isProcessing = false
+	pane.selectedButton = 1
+	if _, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s"), Alt: true}); cmd != nil {
+		t.Error("expected Alt+S to do nothing on other buttons")
+	}
+}
+
+func TestGitPane_SuggestionPrefillsAndCommits(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.isProcessing = true
+
+	pane.Update(GitCommitSuggestionMsg{Message: "docs: add usage notes\n\nExplain how to run the demo."})
+	if pane.isProcessing {
+		t.Error("expected processing to end")
+	}
+	if pane.selectedButton != 4 || pane.focusedInput != 4 {
+		t.Errorf("expected the commit input to be focused, got button %d input %d", pane.selectedButton, pane.focusedInput)
+	}
+	if got := pane.commitMsgInput.Value(); got != "docs: add usage notes" {
+		t.Errorf("expected the subject in the input, got %q", got)
+	}
+	if view := pane.View(); !strings.Contains(view, "Explain how to run the demo.") {
+		t.Error("expected the body to be shown below the input")
+	}
+
+	// The unchanged subject is committed together with the body
+	_, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	commit, ok := cmd().(GitCommitMsg)
+	if !ok {
+		t.Fatal("expected Enter to commit")
+	}
+	if commit.Message != "docs: add usage notes\n\nExplain how to run the demo." {
+		t.Errorf("unexpected commit message %q", commit.Message)
+	}
+	if pane.commitBody != "" {
+		t.Error("expected the body to be cleared after committing")
+	}
+}
+
+func TestGitPane_EditedSuggestionDropsBody(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	suggestion := GitCommitSuggestionMsg{Message: "docs: add usage notes\n\nExplain how to run the demo."}
+
+	// Rewriting the subject commits only what was typed
+	pane.Update(suggestion)
+	pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	if view := pane.View(); strings.Contains(view, "Explain how to run the demo.") {
+		t.Error("expected the body to be dropped once the subject is edited")
+	}
+	_, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if commit := cmd().(GitCommitMsg); commit.Message != "docs: add usage notes!" {
+		t.Errorf("unexpected commit message %q", commit.Message)
+	}
+
+	// So does leaving the input
+	pane.Update(suggestion)
+	pane.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if pane.commitBody != "" {
+		t.Error("expected the body to be dropped when the input loses focus")
+	}
+}
+
+func TestGitPane_SuggestionError(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.isProcessing = true
+
+	pane.Update(GitCommitSuggestionMsg{Error: os.ErrNotExist})
+	if pane.isProcessing || pane.errorMessage == "" {
+		t.Errorf("expected the error to be shown, got %q", pane.errorMessage)
+	}
+	if pane.commitMsgInput.Value() != "" {
+		t.Error("the input should stay empty on error")
+	}
+}
+
+func TestApp_SuggestCommitMessageFromPanel(t *testing.T) {
+	app, client := newCommitTestApp(t, "docs: add usage notes")
+	app.gitPane.visible = true
+
+	_, cmd := app.Update(GitSuggestCommitMsg{})
+	if cmd == nil {
+		t.Fatal("expected a suggestion command")
+	}
+	app.Update(cmd())
+	if !strings.Contains(client.prompt, "README.md") || !strings.Contains(client.prompt, "+Usage notes") {
+		t.Errorf("expected the staged diff in the prompt:\n%s", client.prompt)
+	}
+	if got := app.gitPane.commitMsgInput.Value(); got != "docs: add usage notes" {
+		t.Errorf("expected the suggestion in the commit input, got %q", got)
+	}
+}
+
+func TestApp_StopCancelsSuggestion(t *testing.T) {
+	app, _ := newCommitTestApp(t, "")
+	app.aiPane.aiClient = &chunkedAIClient{chunks: []string{"docs: never sent"}, step: make(chan struct{})}
+	app.gitPane.visible = true
+
+	_, cmd := app.Update(GitSuggestCommitMsg{})
+	if cmd == nil {
+		t.Fatal("expected a suggestion command")
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if !app.gitPane.IsVisible() {
+		t.Error("Ctrl+K should not close the Git panel")
+	}
+
+	msg, ok := cmd().(GitCommitSuggestionMsg)
+	if !ok || msg.Error == nil {
+		t.Fatalf("expected the suggestion to be stopped, got %#v", msg)
+	}
+	app.Update(msg)
+	if app.gitPane.errorMessage != "" || app.gitPane.statusMessage != "Suggestion stopped" {
+		t.Errorf("unexpected status %q / error %q", app.gitPane.statusMessage, app.gitPane.errorMessage)
+	}
+	if app.cancelSuggestion != nil {
+		t.Error("expected the cancel function to be released")
+	}
+}
+
+func TestApp_CommitCommandOpensGitPanel(t *testing.T) {
+	app, _ := newCommitTestApp(t, "```\ndocs: add usage notes\n```")
+
+	cmd := app.handleAIMessage("/commit")
+	if cmd == nil {
+		t.Fatal("expected /commit to generate a message")
+	}
+	msg, ok := cmd().(GitCommitSuggestionMsg)
+	if !ok || !msg.FromChat {
+		t.Fatalf("expected a suggestion from the chat, got %#v", msg)
+	}
+	app.Update(msg)
+
+	if !app.gitPane.IsVisible() {
+		t.Error("expected /commit to open the Git panel")
+	}
+	if got := app.gitPane.commitMsgInput.Value(); got != "docs: add usage notes" {
+		t.Errorf("expected the suggestion in the commit input, got %q", got)
+	}
+	if n := len(app.aiPane.messages); n == 0 || !strings.Contains(app.aiPane.messages[n-1].Content, "docs: add usage notes") {
+		t.Error("expected the suggestion to be shown in the chat")
+	}
+}
+
+func TestApp_CommitCommandNothingStaged(t *testing.T) {
+	app, client := newCommitTestApp(t, "docs: unused")
+	app.gitPane.gitClient.UnstageFile("README.md")
+
+	msg := app.handleAIMessage("/commit")()
+	app.Update(msg)
+	if app.gitPane.IsVisible() {
+		t.Error("the Git panel should stay closed when nothing is staged")
+	}
+	if client.prompt != "" {
+		t.Error("the model should not be asked when nothing is staged")
+	}
+	if n := len(app.aiPane.messages); n == 0 || !strings.Contains(app.aiPane.messages[n-1].Content, "nothing is staged") {
+		t.Error("expected the error to be shown in the chat")
+	}
+}
@@ -0,0 +1,403 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/user/terminal-intelligence/internal/lsp"
+)
+
+// maxCompletionRows is the number of completions the popup shows at once.
+const maxCompletionRows = 8
+
+// maxPopupWidth is the widest the hover and completion popups get.
+const maxPopupWidth = 60
+
+// maxHoverLines is the number of hover lines the popup shows.
+const maxHoverLines = 12
+
+// completionPopup is the list of completions shown below the cursor. It
+// follows the word being typed: the items are filtered by the text between
+// start and the cursor, and the popup closes when the cursor leaves it.
+type completionPopup struct {
+	line     int                  // Line being completed
+	start    int                  // Byte column where the completed word starts
+	items    []lsp.CompletionItem // Completions from the server
+	shown    []int                // Indexes of the items matching the typed text
+	selected int                  // Index into shown
+}
+
+// severityColors are the gutter and inline colors of the diagnostic
+// severities.
+var severityColors = map[lsp.Severity]lipgloss.Color{
+	lsp.SeverityError:       "9",
+	lsp.SeverityWarning:     "11",
+	lsp.SeverityInformation: "12",
+	lsp.SeverityHint:        "8",
+}
+
+// severityColor returns the color of s; servers may leave it out, which
+// counts as an error.
+func severityColor(s lsp.Severity) lipgloss.Color {
+	if c, ok := severityColors[s]; ok {
+		return c
+	}
+	return severityColors[lsp.SeverityError]
+}
+
+// severityRank orders severities from the most serious, with a missing
+// severity counted as an error.
+func severityRank(s lsp.Severity) int {
+	if s == 0 {
+		return int(lsp.SeverityError)
+	}
+	return int(s)
+}
+
+// SetLSPDiagnostics replaces the language server diagnostics of the file at
+// path, an absolute path.
+func (e *EditorPane) SetLSPDiagnostics(path string, diagnostics []lsp.Diagnostic) {
+	if len(diagnostics) == 0 {
+		delete(e.lspDiagnostics, path)
+		return
+	}
+	if e.lspDiagnostics == nil {
+		e.lspDiagnostics = make(map[string][]lsp.Diagnostic)
+	}
+	e.lspDiagnostics[path] = diagnostics
+}
+
+// lineDiagnostics returns the most serious language server diagnostic of
+// each line of the open file.
+func (e *EditorPane) lineDiagnostics() map[int]lsp.Diagnostic {
+	if e.currentFile == nil || len(e.lspDiagnostics) == 0 {
+		return nil
+	}
+	lines := make(map[int]lsp.Diagnostic)
+	for _, d := range e.lspDiagnostics[e.currentFilePath()] {
+		line := d.Range.Start.Line
+		if prev, ok := lines[line]; !ok || severityRank(d.Severity) < severityRank(prev.Severity) {
+			lines[line] = d
+		}
+	}
+	return lines
+}
+
+// diagnosticsOnLine returns the language server diagnostics of a line of
+// the open file, most serious first.
+func (e *EditorPane) diagnosticsOnLine(line int) []lsp.Diagnostic {
+	if e.currentFile == nil {
+		return nil
+	}
+	var found []lsp.Diagnostic
+	for _, d := range e.lspDiagnostics[e.currentFilePath()] {
+		if d.Range.Start.Line <= line && line <= max(d.Range.End.Line, d.Range.Start.Line) {
+			found = append(found, d)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return severityRank(found[i].Severity) < severityRank(found[j].Severity)
+	})
+	return found
+}
+
+// diagnosticText fits the message of d into the padding after a line. It
+// returns the styled message and what is left of pad, which keeps two
+// spaces before the message so the cursor can sit at the end of the line.
+// Without room for a few characters the message is left out.
+func diagnosticText(d lsp.Diagnostic, pad string) (string, string) {
+	room := len(pad) - 2
+	if room < 4 {
+		return "", pad
+	}
+	message, _, _ := strings.Cut(strings.TrimSpace(d.Message), "\n")
+	message = ansi.Truncate(message, room, "…")
+	width := ansi.StringWidth(message)
+	style := lipgloss.NewStyle().Foreground(severityColor(d.Severity)).Faint(true)
+	return style.Render(message), pad[:len(pad)-width]
+}
+
+// ShowHover shows text in a popup below the cursor until the next key.
+func (e *EditorPane) ShowHover(text string) {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		// Code fences of markdown hovers are noise in a terminal
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	e.hover = lines
+	e.completion = nil
+}
+
+// OpenCompletion shows items for the cursor position line and col, where
+// they were asked for. It reports whether any item matches what has been
+// typed since.
+func (e *EditorPane) OpenCompletion(line, col int, items []lsp.CompletionItem) bool {
+	lines := strings.Split(e.content, "\n")
+	if e.cursorLine != line || line >= len(lines) || len(items) == 0 {
+		return false
+	}
+	sorted := append([]lsp.CompletionItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sortKey(sorted[i]) < sortKey(sorted[j])
+	})
+	e.hover = nil
+	e.completion = &completionPopup{line: line, start: wordStart(lines[line], min(col, len(lines[line]))), items: sorted}
+	e.refreshCompletion()
+	return e.completion != nil
+}
+
+// sortKey returns the text completions are ordered by.
+func sortKey(item lsp.CompletionItem) string {
+	if item.SortText != "" {
+		return item.SortText
+	}
+	return item.Label
+}
+
+// CompletionVisible reports whether the completion popup is shown.
+func (e *EditorPane) CompletionVisible() bool {
+	return e.completion != nil
+}
+
+// refreshCompletion filters the completions by the word typed so far, and
+// closes the popup when the cursor left the word or nothing matches.
+func (e *EditorPane) refreshCompletion() {
+	c := e.completion
+	if c == nil {
+		return
+	}
+	lines := strings.Split(e.content, "\n")
+	if e.cursorLine != c.line || c.line >= len(lines) || e.cursorCol < c.start || e.cursorCol > len(lines[c.line]) {
+		e.completion = nil
+		return
+	}
+	typed := lines[c.line][c.start:e.cursorCol]
+	if wordStart(typed, len(typed)) != 0 {
+		e.completion = nil
+		return
+	}
+	var selected string
+	if c.selected < len(c.shown) {
+		selected = c.items[c.shown[c.selected]].Label
+	}
+	c.shown = c.shown[:0]
+	c.selected = 0
+	lower := strings.ToLower(typed)
+	for i, item := range c.items {
+		if strings.HasPrefix(strings.ToLower(item.Filter()), lower) {
+			if item.Label == selected {
+				c.selected = len(c.shown)
+			}
+			c.shown = append(c.shown, i)
+		}
+	}
+	if len(c.shown) == 0 {
+		e.completion = nil
+	}
+}
+
+// acceptCompletion replaces the typed word with the selected completion,
+// as one undo step.
+func (e *EditorPane) acceptCompletion() {
+	e.refreshCompletion()
+	c := e.completion
+	e.completion = nil
+	if c == nil {
+		return
+	}
+	lines := strings.Split(e.content, "\n")
+	line := lines[c.line]
+	before, after := line[:c.start], line[e.cursorCol:]
+
+	e.saveSnapshot()
+	inserted := strings.Split(c.items[c.shown[c.selected]].Text(), "\n")
+	last := len(inserted) - 1
+	inserted[0] = before + inserted[0]
+	e.cursorCol = len(inserted[last])
+	inserted[last] += after
+
+	lines = append(lines[:c.line], append(inserted, lines[c.line+1:]...)...)
+	e.shiftMarkers(c.line+1, last)
+	e.cursorLine = c.line + last
+	e.content = strings.Join(lines, "\n")
+	e.updateModified()
+	e.adjustScroll()
+}
+
+// popupKey handles a key while a popup is shown and reports whether it was
+// used. The completion popup takes the keys that move through it, accept
+// and dismiss it; typing goes on in the editor. The hover popup closes on
+// any key and only takes Esc.
+func (e *EditorPane) popupKey(key string) bool {
+	if c := e.completion; c != nil {
+		switch key {
+		case "up", "ctrl+p":
+			c.selected = (c.selected + len(c.shown) - 1) % len(c.shown)
+			return true
+		case "down", "ctrl+n":
+			c.selected = (c.selected + 1) % len(c.shown)
+			return true
+		case "enter", "tab":
+			e.acceptCompletion()
+			return true
+		case "esc":
+			e.completion = nil
+			return true
+		}
+		return false
+	}
+	if e.hover != nil {
+		e.hover = nil
+		return key == "esc"
+	}
+	return false
+}
+
+// popupBox renders the shown popup as lines at most width cells wide, and
+// returns how many cells left of the cursor it starts: completions line up
+// with the word they complete.
+func (e *EditorPane) popupBox(width int) ([]string, int) {
+	width = min(width, maxPopupWidth)
+	if width < 10 {
+		return nil, 0
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62"))
+	inner := width - 2
+
+	if c := e.completion; c != nil {
+		first := max(0, min(c.selected-maxCompletionRows/2, len(c.shown)-maxCompletionRows))
+		last := min(len(c.shown), first+maxCompletionRows)
+		labelWidth := 0
+		for _, i := range c.shown[first:last] {
+			labelWidth = max(labelWidth, ansi.StringWidth(c.items[i].Label))
+		}
+		labelWidth = min(labelWidth, inner)
+		var rows []string
+		for n, i := range c.shown[first:last] {
+			item := c.items[i]
+			label := ansi.Truncate(item.Label, labelWidth, "…")
+			row := label + strings.Repeat(" ", labelWidth-ansi.StringWidth(label))
+			if room := inner - labelWidth - 2; item.Detail != "" && room > 3 {
+				row += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render(ansi.Truncate(oneLine(item.Detail), room, "…"))
+			}
+			row += strings.Repeat(" ", max(0, inner-ansi.StringWidth(row)))
+			if first+n == c.selected {
+				row = lipgloss.NewStyle().Reverse(true).Render(ansi.Strip(row))
+			}
+			rows = append(rows, row)
+		}
+		if len(c.shown) > maxCompletionRows {
+			rows = append(rows, lipgloss.NewStyle().Foreground(lipgloss.Color("240")).
+				Render(fmt.Sprintf("%d/%d", c.selected+1, len(c.shown))))
+		}
+		lines := strings.Split(e.content, "\n")
+		typed := len([]rune(strings.ReplaceAll(lines[c.line][c.start:e.cursorCol], "\t", "    ")))
+		return strings.Split(box.Render(strings.Join(rows, "\n")), "\n"), typed + 1
+	}
+
+	if len(e.hover) > 0 {
+		var rows []string
+		for _, line := range e.hover {
+			line = strings.ReplaceAll(line, "\t", "    ")
+			for _, wrapped := range strings.Split(ansi.Wrap(line, inner, ""), "\n") {
+				rows = append(rows, wrapped)
+			}
+		}
+		if len(rows) > maxHoverLines {
+			rows = append(rows[:maxHoverLines-1], "…")
+		}
+		contentWidth := 0
+		for _, row := range rows {
+			contentWidth = max(contentWidth, ansi.StringWidth(row))
+		}
+		return strings.Split(box.Width(contentWidth).Render(strings.Join(rows, "\n")), "\n"), 0
+	}
+	return nil, 0
+}
+
+// overlay draws box over lines with its top left corner at row and col,
+// keeping the text left and right of it.
+func overlay(lines []string, box []string, row, col int) {
+	for i, boxLine := range box {
+		r := row + i
+		if r < 0 || r >= len(lines) {
+			continue
+		}
+		left := ansi.Truncate(lines[r], col, "")
+		if w := ansi.StringWidth(left); w < col {
+			left += strings.Repeat(" ", col-w)
+		}
+		right := ansi.TruncateLeft(lines[r], col+ansi.StringWidth(boxLine), "")
+		lines[r] = left + ansi.ResetStyle + boxLine + ansi.ResetStyle + right
+	}
+}
+
+// wordStart returns the byte column where the identifier ending at col in
+// line starts; col itself when there is none.
+func wordStart(line string, col int) int {
+	start := col
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if !isIdentRune(r) {
+			break
+		}
+		start -= size
+	}
+	return start
+}
+
+// wordEnd returns the byte column where the identifier starting at or
+// running through col in line ends.
+func wordEnd(line string, col int) int {
+	end := col
+	for end < len(line) {
+		r, size := utf8.DecodeRuneInString(line[end:])
+		if !isIdentRune(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+// isIdentRune reports whether r may be part of an identifier.
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// EditBuffer applies edit to the content of the buffer holding the file at
+// path as one undo step, leaving the change unsaved. It reports whether
+// the file is open.
+func (e *EditorPane) EditBuffer(path string, edit func(string) (string, error)) (bool, error) {
+	e.storeBuffer()
+	i := e.findBuffer(path)
+	if i < 0 {
+		return false, nil
+	}
+	b := &e.buffers[i]
+	content, err := edit(b.content)
+	if err != nil || content == b.content {
+		return true, err
+	}
+	b.undoStack = append(b.undoStack, editorSnapshot{content: b.content, cursorLine: b.cursorLine, cursorCol: b.cursorCol})
+	b.redoStack = nil
+	b.content = content
+	lines := strings.Split(content, "\n")
+	b.cursorLine = min(b.cursorLine, len(lines)-1)
+	b.cursorCol = min(b.cursorCol, len(lines[b.cursorLine]))
+	if b.currentFile != nil {
+		b.currentFile.IsModified = b.modified()
+	}
+	e.showBuffer(e.activeBuffer)
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/diff"
+)
+
+// conflictLine classifies the lines of a conflict block for display.
+type conflictLine int
+
+const (
+	conflictMarker conflictLine = iota + 1 // <<<<<<<, |||||||, ======= and >>>>>>> lines
+	conflictOurs                           // lines of our side
+	conflictBase                           // lines of the common ancestor
+	conflictTheirs                         // lines of their side
+)
+
+// conflictStyles colors each kind of conflict line in the editor.
+var conflictStyles = map[conflictLine]lipgloss.Style{
+	conflictMarker: lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true),
+	conflictOurs:   lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+	conflictBase:   lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+	conflictTheirs: lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+}
+
+// conflicts returns the conflict blocks of the editor content.
+func (e *EditorPane) conflicts() []diff.Conflict {
+	if !strings.Contains(e.content, diff.MarkerOurs) {
+		return nil
+	}
+	return diff.ParseConflicts(e.content)
+}
+
+// conflictLines classifies every line that belongs to a conflict block, by
+// line index.
+func (e *EditorPane) conflictLines() map[int]conflictLine {
+	blocks := e.conflicts()
+	if len(blocks) == 0 {
+		return nil
+	}
+	kinds := make(map[int]conflictLine)
+	for _, c := range blocks {
+		line := c.Start
+		kinds[line] = conflictMarker
+		for range c.Ours {
+			line++
+			kinds[line] = conflictOurs
+		}
+		if c.Base != nil {
+			line++
+			kinds[line] = conflictMarker
+			for range c.Base {
+				line++
+				kinds[line] = conflictBase
+			}
+		}
+		line++
+		kinds[line] = conflictMarker
+		for range c.Theirs {
+			line++
+			kinds[line] = conflictTheirs
+		}
+		kinds[c.End-1] = conflictMarker
+	}
+	return kinds
+}
+
+// HasConflicts reports whether the editor content still has conflict markers.
+func (e *EditorPane) HasConflicts() bool {
+	return len(e.conflicts()) > 0
+}
+
+// ResolveConflict resolves the conflict under the cursor, or the next one
+// after it, and moves the cursor to where it was. It reports whether there
+// was a conflict to resolve.
+func (e *EditorPane) ResolveConflict(resolution diff.Resolution) bool {
+	var target *diff.Conflict
+	for _, c := range e.conflicts() {
+		if c.End > e.cursorLine {
+			target = &c
+			break
+		}
+	}
+	if target == nil {
+		return false
+	}
+
+	e.saveSnapshot()
+	e.content = diff.Resolve(e.content, *target, resolution)
+	e.cursorLine = min(target.Start, strings.Count(e.content, "\n"))
+	e.cursorCol = 0
+	if e.currentFile != nil {
+		e.currentFile.IsModified = (e.content != e.originalContent) || len(e.diffMarkers) > 0
+	}
+	e.adjustScroll()
+	return true
+}
+
+// NextConflict moves the cursor to the next conflict after it, wrapping
+// around to the first. It reports whether there was a conflict.
+func (e *EditorPane) NextConflict() bool {
+	blocks := e.conflicts()
+	if len(blocks) == 0 {
+		return false
+	}
+	next := blocks[0]
+	for _, c := range blocks {
+		if c.Start > e.cursorLine {
+			next = c
+			break
+		}
+	}
+	e.cursorLine = next.Start
+	e.cursorCol = 0
+	e.adjustScroll()
+	return true
+}
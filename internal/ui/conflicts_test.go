@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/diff"
+)
+
+const twoConflicts = "start\n" +
+	"<<<<<<< HEAD\nours 1\n||||||| base\nbase 1\n=======\ntheirs 1\n>>>>>>> feature\n" +
+	"middle\n" +
+	"<<<<<<< HEAD\nours 2\n=======\ntheirs 2\n>>>>>>> feature\n" +
+	"end\n"
+
+// newConflictEditor returns an editor showing twoConflicts
+func newConflictEditor() *EditorPane {
+	editor := NewEditorPane(nil)
+	editor.SetSize(100, 30)
+	editor.focused = true
+	editor.content = twoConflicts
+	return editor
+}
+
+func altKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true}
+}
+
+func TestEditorPane_ResolveConflictKeys(t *testing.T) {
+	tests := []struct {
+		key  rune
+		want string
+	}{
+		{'o', "start\nours 1\nmiddle\n"},
+		{'t', "start\ntheirs 1\nmiddle\n"},
+		{'a', "start\nours 1\ntheirs 1\nmiddle\n"},
+	}
+	for _, tt := range tests {
+		editor := newConflictEditor()
+		editor.Update(altKey(tt.key))
+		if !strings.HasPrefix(editor.GetContent(), tt.want) {
+			t.Errorf("Alt+%c: got %q", tt.key, editor.GetContent())
+		}
+		if len(editor.conflicts()) != 1 {
+			t.Errorf("Alt+%c: expected only the first conflict to be resolved", tt.key)
+		}
+		if editor.cursorLine != 1 {
+			t.Errorf("Alt+%c: expected the cursor on the resolution, got line %d", tt.key, editor.cursorLine)
+		}
+	}
+}
+
+func TestEditorPane_ResolveConflictAtCursor(t *testing.T) {
+	editor := newConflictEditor()
+	editor.cursorLine = 8 // "middle", after the first conflict
+
+	editor.Update(altKey('t'))
+	want := strings.Replace(twoConflicts, "<<<<<<< HEAD\nours 2\n=======\ntheirs 2\n>>>>>>> feature\n", "theirs 2\n", 1)
+	if got := editor.GetContent(); got != want {
+		t.Errorf("expected the second conflict to be resolved, got %q", got)
+	}
+
+	editor.Update(altKey('u'))
+	if editor.GetContent() != twoConflicts {
+		t.Error("expected undo to restore the conflict")
+	}
+
+	editor.cursorLine = 15 // after the last conflict
+	if editor.ResolveConflict(diff.TakeOurs) {
+		t.Error("expected nothing to resolve after the last conflict")
+	}
+}
+
+func TestEditorPane_NextConflict(t *testing.T) {
+	editor := newConflictEditor()
+
+	editor.Update(altKey('c'))
+	if editor.cursorLine != 1 {
+		t.Errorf("expected the first conflict, got line %d", editor.cursorLine)
+	}
+	editor.Update(altKey('c'))
+	if editor.cursorLine != 9 {
+		t.Errorf("expected the second conflict, got line %d", editor.cursorLine)
+	}
+	editor.Update(altKey('c'))
+	if editor.cursorLine != 1 {
+		t.Errorf("expected to wrap to the first conflict, got line %d", editor.cursorLine)
+	}
+
+	editor.content = "no conflicts\n"
+	if editor.NextConflict() || editor.HasConflicts() {
+		t.Error("expected no conflicts")
+	}
+}
+
+func TestEditorPane_ConflictLines(t *testing.T) {
+	editor := newConflictEditor()
+	kinds := editor.conflictLines()
+
+	want := map[int]conflictLine{
+		1: conflictMarker, 2: conflictOurs, 3: conflictMarker, 4: conflictBase,
+		5: conflictMarker, 6: conflictTheirs, 7: conflictMarker,
+		9: conflictMarker, 10: conflictOurs, 11: conflictMarker, 12: conflictTheirs, 13: conflictMarker,
+	}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected %d conflict lines, got %v", len(want), kinds)
+	}
+	for line, kind := range want {
+		if kinds[line] != kind {
+			t.Errorf("line %d: got %d, want %d", line, kinds[line], kind)
+		}
+	}
+	if view := editor.View(); !strings.Contains(view, "theirs 2") {
+		t.Error("expected the conflict to be rendered")
+	}
+}
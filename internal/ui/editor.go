@@ -6,8 +6,14 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/user/terminal-intelligence/internal/diff"
 	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/lsp"
+	"github.com/user/terminal-intelligence/internal/syntax"
 	"github.com/user/terminal-intelligence/internal/types"
+	"github.com/user/terminal-intelligence/internal/validation"
 )
 
 // EditorPane manages the code editor pane with syntax highlighting and file editing.
@@ -15,6 +21,7 @@ import (
 //   - Cursor navigation (arrow keys, home, end)
 //   - Text editing (insert, delete, backspace, newline)
 //   - File operations (load, save, close)
+//   - Multiple buffers shown as tabs, each with its own cursor and undo history
 //   - Unsaved changes tracking
 //   - Line numbering
 //   - Scrolling for large files
@@ -22,6 +29,8 @@ import (
 //
 // The editor supports multiple file types (bash, shell, powershell, markdown) and
 // integrates with the AgenticCodeFixer for autonomous code modifications.
+// Go, Python, Bash, PowerShell, JavaScript, TypeScript, Markdown, JSON and
+// YAML files are highlighted in the configured theme (see SetTheme).
 //
 // File Type Detection:
 // File types are determined by extension:
@@ -34,21 +43,38 @@ import (
 // The editor tracks modifications by comparing current content with originalContent.
 // This enables the exit confirmation dialog and modified indicator (*) in the title bar.
 type EditorPane struct {
-	content         string                   // Current editor content
-	originalContent string                   // Original content for unsaved changes detection
-	cursorLine      int                      // Current cursor line (0-indexed)
-	cursorCol       int                      // Current cursor column (0-indexed)
-	scrollOffset    int                      // Vertical scroll offset
-	currentFile     *types.FileMetadata      // Current file metadata (nil if no file open)
-	fileManager     *filemanager.FileManager // File system operations
-	width           int                      // Pane width
-	height          int                      // Pane height
-	focused         bool                     // Whether this pane is focused
-	diffMarkers     map[int]string           // Tracks red/green line styling for diffs
-	undoStack       []editorSnapshot         // Undo history
-	redoStack       []editorSnapshot         // Redo history
-	pendingAltD     bool                     // Waiting for second key after Alt+D
-	suggestedName   string                   // AI-suggested filename for unsaved buffer
+	content         string                      // Current editor content
+	originalContent string                      // Original content for unsaved changes detection
+	cursorLine      int                         // Current cursor line (0-indexed)
+	cursorCol       int                         // Current cursor column (0-indexed)
+	scrollOffset    int                         // Vertical scroll offset
+	currentFile     *types.FileMetadata         // Current file metadata (nil if no file open)
+	fileManager     *filemanager.FileManager    // File system operations
+	width           int                         // Pane width
+	height          int                         // Pane height
+	focused         bool                        // Whether this pane is focused
+	diffMarkers     map[int]string              // Tracks red/green line styling for diffs
+	undoStack       []editorSnapshot            // Undo history
+	redoStack       []editorSnapshot            // Redo history
+	pendingAltD     bool                        // Waiting for second key after Alt+D
+	suggestedName   string                      // AI-suggested filename for unsaved buffer
+	validator       *validation.Pipeline        // Validates files on save (nil when disabled)
+	diagnostics     map[string]lineMarks        // Validation errors per absolute path
+	lspDiagnostics  map[string][]lsp.Diagnostic // Language server diagnostics per absolute path
+	hover           []string                    // Lines of the hover popup (nil when hidden)
+	completion      *completionPopup            // Completion popup (nil when hidden)
+	blame           []git.BlameLine             // Blame of blamePath as of HEAD (nil when hidden)
+	blamePath       string                      // Absolute path of the blamed file
+	blameGutters    []string                    // Rendered blame gutter per line, for blameContent
+	blameContent    string                      // Content blameGutters was computed for
+	buffers         []editorBuffer              // Open buffers in tab order; the active one is stored lazily
+	activeBuffer    int                         // Index of the buffer shown in the fields above
+	theme           syntax.Theme                // Syntax highlighting theme
+	tabSize         int                         // Spaces per indentation level for block indent
+	selecting       bool                        // Whether text is selected from selAnchor to the cursor
+	selAnchor       textPos                     // Fixed end of the selection
+	highlight       editorHighlight             // Cached highlighting of content
+	recentFiles     []string                    // Absolute paths of shown files, most recent first
 }
 
 // editorSnapshot stores editor state for undo/redo
@@ -79,6 +105,16 @@ func NewEditorPane(fm *filemanager.FileManager) *EditorPane {
 		height:          0,
 		focused:         false,
 		diffMarkers:     make(map[int]string),
+		theme:           syntax.LoadTheme(syntax.DefaultTheme),
+		tabSize:         4,
+	}
+}
+
+// SetTabSize sets the number of spaces a block is indented by. Sizes
+// below one are ignored.
+func (e *EditorPane) SetTabSize(size int) {
+	if size > 0 {
+		e.tabSize = size
 	}
 }
 
@@ -86,6 +122,10 @@ func NewEditorPane(fm *filemanager.FileManager) *EditorPane {
 // Reads the file content, normalizes line endings (CRLF -> LF), and resets cursor position.
 // Determines file type from extension and creates FileMetadata.
 //
+// The file opens in a new tab, or reuses the active tab if it is an empty
+// scratch buffer. A file that is already open is switched to and reloaded
+// from disk, unless its buffer has unsaved changes, which are kept.
+//
 // Line ending normalization:
 //   - \r\n (Windows) -> \n
 //   - \r (old Mac) -> \n
@@ -106,12 +146,24 @@ func (e *EditorPane) LoadFile(filepath string) error {
 	content = strings.ReplaceAll(content, "\r\n", "\n")
 	content = strings.ReplaceAll(content, "\r", "\n")
 
+	if i := e.findBuffer(filepath); i >= 0 {
+		e.SwitchBuffer(i)
+		if e.HasUnsavedChanges() {
+			return nil
+		}
+	} else {
+		e.openBuffer()
+	}
+
 	e.content = content
 	e.originalContent = content
 	e.cursorLine = 0
 	e.cursorCol = 0
 	e.scrollOffset = 0
 	e.diffMarkers = make(map[int]string)
+	e.suggestedName = ""
+	e.undoStack = nil
+	e.redoStack = nil
 
 	// Determine file type from extension
 	fileType := determineFileType(filepath)
@@ -121,6 +173,7 @@ func (e *EditorPane) LoadFile(filepath string) error {
 		FileType:   fileType,
 		IsModified: false,
 	}
+	e.touchRecent()
 
 	return nil
 }
@@ -165,11 +218,24 @@ func (e *EditorPane) SaveFile() error {
 	e.originalContent = e.content
 	e.currentFile.IsModified = false
 
+	if e.validator != nil {
+		e.validator.ValidateChanges([]string{e.currentFilePath()})
+	}
+
 	return nil
 }
 
-// CloseFile closes the current file and clears the editor
+// CloseFile closes the active buffer and shows the next one, or the
+// previous one if it was the last tab. Closing the only buffer clears the
+// editor.
 func (e *EditorPane) CloseFile() {
+	e.ensureBuffers()
+	if len(e.buffers) > 1 {
+		e.buffers = append(e.buffers[:e.activeBuffer], e.buffers[e.activeBuffer+1:]...)
+		e.showBuffer(min(e.activeBuffer, len(e.buffers)-1))
+		return
+	}
+
 	e.content = ""
 	e.originalContent = ""
 	e.cursorLine = 0
@@ -177,6 +243,9 @@ func (e *EditorPane) CloseFile() {
 	e.scrollOffset = 0
 	e.currentFile = nil
 	e.diffMarkers = nil
+	e.suggestedName = ""
+	e.undoStack = nil
+	e.redoStack = nil
 }
 
 // GetContent returns current editor content.
@@ -219,7 +288,9 @@ func (e *EditorPane) SetContent(content string) {
 
 // SetContentUnsaved loads content into the editor without an associated file.
 // If suggestedName is provided, it will be used as the default filename on save.
+// The content opens in a new tab unless the active tab is an empty scratch buffer.
 func (e *EditorPane) SetContentUnsaved(content string, suggestedName string) {
+	e.openBuffer()
 	e.content = content
 	e.originalContent = ""
 	e.cursorLine = 0
@@ -398,6 +469,25 @@ func (e *EditorPane) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 		}
 	}
 
+	// Selection keys, then typing replaces the selection, deleting removes
+	// it and any other key drops it
+	if e.handleSelectionKey(keyStr) {
+		return nil
+	}
+	if e.selecting {
+		switch {
+		case keyStr == "backspace" || keyStr == "delete":
+			if e.deleteSelection() {
+				return nil
+			}
+		case keyStr == "enter" || msg.Type == tea.KeyRunes:
+			e.deleteSelection()
+			lines = strings.Split(e.content, "\n")
+		default:
+			e.selecting = false
+		}
+	}
+
 	switch keyStr {
 	case "alt+d":
 		// Start Alt+D sequence, wait for next key
@@ -433,6 +523,29 @@ func (e *EditorPane) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 		e.cursorCol = 0
 		e.adjustScroll()
 		return nil
+	// Merge conflicts: resolve the block at or after the cursor, or jump to the next one
+	case "alt+o":
+		e.ResolveConflict(diff.TakeOurs)
+		return nil
+	case "alt+t":
+		e.ResolveConflict(diff.TakeTheirs)
+		return nil
+	case "alt+a":
+		e.ResolveConflict(diff.TakeBoth)
+		return nil
+	case "alt+c":
+		e.NextConflict()
+		return nil
+	// Buffers: next and previous tab, or Alt+1..9 for a tab by number
+	case "alt+.", "ctrl+pgdown":
+		e.NextBuffer()
+		return nil
+	case "alt+,", "ctrl+pgup":
+		e.PrevBuffer()
+		return nil
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		e.SwitchBuffer(int(keyStr[4] - '1'))
+		return nil
 	case "up":
 		if e.cursorLine > 0 {
 			e.cursorLine--
@@ -480,8 +593,10 @@ func (e *EditorPane) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 	case "delete":
 		e.deleteNextChar()
 	default:
-		// Insert regular characters
-		if msg.Type == tea.KeyRunes {
+		// Insert regular characters; pasted text goes in as one edit
+		if msg.Type == tea.KeyRunes && msg.Paste {
+			e.InsertText(string(msg.Runes))
+		} else if msg.Type == tea.KeyRunes {
 			for _, r := range msg.Runes {
 				if r >= 32 || r == '\t' {
 					e.insertChar(string(r))
@@ -495,6 +610,7 @@ func (e *EditorPane) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 
 // shiftMarkers shifts diff markers when rows are inserted or deleted
 func (e *EditorPane) shiftMarkers(fromLine int, amount int) {
+	e.shiftDiagnostics(fromLine, amount)
 	if len(e.diffMarkers) == 0 {
 		return
 	}
@@ -823,6 +939,9 @@ func (e *EditorPane) adjustScroll() {
 	}
 
 	maxLineWidth := e.width - 12
+	if e.BlameVisible() {
+		maxLineWidth -= blameWidth
+	}
 	if maxLineWidth < 10 {
 		maxLineWidth = 10
 	}
@@ -916,6 +1035,15 @@ func (e *EditorPane) View() string {
 	// Constraint: X + 4 <= e.width - 6  =>  X <= e.width - 10
 	// Using -12 to be safe and prevent any wrapping
 	maxLineWidth := e.width - 12
+
+	// The blame gutter takes its width from the text
+	var blameGutters []string
+	blankBlame := ""
+	if e.BlameVisible() {
+		blameGutters = e.blameGutter()
+		blankBlame = strings.Repeat(" ", blameWidth)
+		maxLineWidth -= blameWidth
+	}
 	if maxLineWidth < 10 {
 		maxLineWidth = 10
 	}
@@ -927,13 +1055,21 @@ func (e *EditorPane) View() string {
 		isContinuation bool
 		isCursorChunk  bool
 		cursorRelCol   int
+		kinds          []syntax.Kind // Highlighting of text, nil when plain
+		start          int           // Column of the line where text starts
+		isLast         bool          // Whether text ends the line
 	}
 	var vLines []visualLine
 
+	lineKinds := e.lineKinds()
 	for fileLineIdx, rawLine := range lines {
 		expanded := strings.ReplaceAll(rawLine, "\t", "    ")
 		runes := []rune(expanded)
 		lineLen := len(runes)
+		var kinds []syntax.Kind
+		if fileLineIdx < len(lineKinds) {
+			kinds = expandKinds(rawLine, lineKinds[fileLineIdx])
+		}
 
 		if lineLen == 0 {
 			vLines = append(vLines, visualLine{
@@ -942,6 +1078,7 @@ func (e *EditorPane) View() string {
 				isContinuation: false,
 				isCursorChunk:  fileLineIdx == e.cursorLine,
 				cursorRelCol:   0,
+				isLast:         true,
 			})
 			continue
 		}
@@ -975,12 +1112,24 @@ func (e *EditorPane) View() string {
 				isContinuation: start > 0,
 				isCursorChunk:  isCursorChunk,
 				cursorRelCol:   relCol,
+				kinds:          kinds[min(start, len(kinds)):min(end, len(kinds))],
+				start:          start,
+				isLast:         end == lineLen,
 			})
 		}
 	}
 
+	diagnostics := e.currentDiagnostics()
+	lspMarks := e.lineDiagnostics()
+	var conflictKinds map[int]conflictLine
+	if len(e.diffMarkers) == 0 {
+		conflictKinds = e.conflictLines()
+	}
+	errorMark := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("●")
+
 	// Render exactly visibleLines lines
 	var renderedLines []string
+	cursorRow, cursorX := -1, 0
 	for i := 0; i < visibleLines; i++ {
 		vIdx := e.scrollOffset + i
 
@@ -997,14 +1146,33 @@ func (e *EditorPane) View() string {
 
 			line := vl.text
 
+			// Diff and conflict lines keep their own colors
+			_, marked := e.diffMarkers[vl.fileLineIdx]
+			_, conflicted := conflictKinds[vl.fileLineIdx]
+
 			// Fill the chunk to maxLineWidth so that it doesn't shorten the container border
 			runeLine := []rune(line)
+			pad := ""
 			if len(runeLine) < maxLineWidth {
-				line += strings.Repeat(" ", maxLineWidth-len(runeLine))
+				pad = strings.Repeat(" ", maxLineWidth-len(runeLine))
 			}
 
-			// Highlight cursor chunk
-			if vl.isCursorChunk && e.focused {
+			// Language server diagnostics are shown after the end of their line
+			virtual := ""
+			if d, ok := lspMarks[vl.fileLineIdx]; ok && vl.isLast && !marked && !conflicted {
+				virtual, pad = diagnosticText(d, pad)
+			}
+			line += pad
+			selFrom, selTo := e.selectedColumns(vl.fileLineIdx, lines[vl.fileLineIdx])
+			selFrom, selTo = selFrom-vl.start, selTo-vl.start
+			if (vl.kinds != nil || selTo > 0 && selFrom < len(runeLine)) && !marked && !conflicted {
+				cursor := -1
+				if vl.isCursorChunk && e.focused {
+					cursor = vl.cursorRelCol
+				}
+				line = e.renderLine(runeLine, vl.kinds, pad, cursor, selFrom, selTo)
+			} else if vl.isCursorChunk && e.focused {
+				// Highlight cursor chunk
 				cursorStyle := lipgloss.NewStyle().Reverse(true)
 				runeLine = []rune(line) // re-evaluate after padding
 				if vl.cursorRelCol < len(runeLine) {
@@ -1020,16 +1188,49 @@ func (e *EditorPane) View() string {
 				} else if color == "green" {
 					line = lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render(line)
 				}
+			} else if kind, ok := conflictKinds[vl.fileLineIdx]; ok {
+				line = conflictStyles[kind].Render(line)
 			}
 
-			renderedLines = append(renderedLines, lineNumStyled+" │ "+line)
+			line += virtual
+
+			gutter := " │ "
+			if _, hasError := diagnostics[vl.fileLineIdx]; hasError && !vl.isContinuation {
+				gutter = errorMark + "│ "
+			} else if d, ok := lspMarks[vl.fileLineIdx]; ok && !vl.isContinuation {
+				gutter = lipgloss.NewStyle().Foreground(severityColor(d.Severity)).Render("●") + "│ "
+			}
+
+			blameGutter := blankBlame
+			if !vl.isContinuation && vl.fileLineIdx < len(blameGutters) {
+				blameGutter = blameGutters[vl.fileLineIdx]
+			}
+
+			if vl.isCursorChunk {
+				cursorRow, cursorX = i, len(blankBlame)+6+vl.cursorRelCol
+			}
+			renderedLines = append(renderedLines, blameGutter+lineNumStyled+gutter+line)
 		} else {
 			// Ensure empty lines have the appropriate width padding to match content lines
-			emptyLine := "  ~" + strings.Repeat(" ", maxLineWidth+2) // 2 for the space+pipe padding
+			emptyLine := blankBlame + "  ~" + strings.Repeat(" ", maxLineWidth+2) // 2 for the space+pipe padding
 			renderedLines = append(renderedLines, emptyLine)
 		}
 	}
 
+	// The hover and completion popups open below the cursor, or above it
+	// when there is no room
+	if cursorRow >= 0 && e.focused {
+		lineWidth := len(blankBlame) + 6 + maxLineWidth
+		if box, back := e.popupBox(lineWidth); len(box) > 0 {
+			row := cursorRow + 1
+			if row+len(box) > visibleLines && cursorRow >= len(box) {
+				row = cursorRow - len(box)
+			}
+			col := max(0, min(cursorX-back, lineWidth-ansi.StringWidth(box[0])))
+			overlay(renderedLines, box, row, col)
+		}
+	}
+
 	content := strings.Join(renderedLines, "\n")
 
 	// Use strict Height and MaxWidth to enforce size
@@ -0,0 +1,683 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// fileTreePrompt identifies what the file tree input is asking for
+type fileTreePrompt int
+
+const (
+	treePromptNone      fileTreePrompt = iota
+	treePromptNewFile                  // name of a new file in the target folder
+	treePromptNewFolder                // name of a new folder in the target folder
+	treePromptRename                   // new name of the selected entry
+	treePromptMove                     // new workspace-relative path of the selected entry
+	treePromptDelete                   // confirmation of deleting the selected entry
+)
+
+// treeNode is one row of the file tree.
+type treeNode struct {
+	path  string // Slash-separated path relative to the workspace
+	name  string // Base name
+	dir   bool   // Whether the entry is a folder
+	depth int    // Nesting level, 0 for the entries of the workspace
+}
+
+// FileTreeStatusMsg carries the ignore rules and git status loaded for the
+// file tree.
+type FileTreeStatusMsg struct {
+	Root     string           // Workspace the state was loaded for
+	Ignore   *git.IgnoreRules // Patterns of the .gitignore files
+	Statuses []git.FileStatus // Changed files, nil outside a repository
+	Err      error            // Why the ignore rules could not be read
+}
+
+// FileTreeRenamedMsg is sent when the file tree renamed or moved a file or
+// folder, so the editor buffers of the files in it follow.
+type FileTreeRenamedMsg struct {
+	From string // Absolute path before the move
+	To   string // Absolute path after the move
+}
+
+// FileTree is a sidebar showing the workspace as a collapsible tree. It
+// hides what .gitignore ignores, marks files with their git status and
+// highlights the files changed by the last /fix or /project session.
+// Files and folders are created, renamed, moved and deleted through the
+// FileManager.
+type FileTree struct {
+	visible bool
+	focused bool
+	width   int // Rendered width, including the border
+	height  int // Rendered height, including the border
+
+	root     string          // Workspace directory shown
+	expanded map[string]bool // Paths of the open folders
+	nodes    []treeNode      // Visible rows, in display order
+	selected int             // Index of the selected row
+	offset   int             // First row on screen
+
+	ignore       *git.IgnoreRules // Hidden entries
+	status       map[string]byte  // Status letter of each changed file, '*' for folders containing one
+	agentChanged map[string]bool  // Files changed by the last agent session, and their folders
+	gitClient    *git.Client      // Loads the ignore rules and status
+	fileManager  *filemanager.FileManager
+
+	prompt  fileTreePrompt
+	input   textinput.Model
+	message string // Outcome of the last operation
+	isError bool   // Whether message is an error
+}
+
+// NewFileTree creates a hidden file tree of the workspace at root.
+func NewFileTree(fileManager *filemanager.FileManager, root string) *FileTree {
+	input := textinput.New()
+	input.CharLimit = 255
+	return &FileTree{
+		root:        root,
+		expanded:    make(map[string]bool),
+		gitClient:   git.NewClient(root),
+		fileManager: fileManager,
+		input:       input,
+	}
+}
+
+// fileTreeWidth returns the width of the file tree for a terminal width.
+func fileTreeWidth(termWidth int) int {
+	return min(max(termWidth/5, 24), 40)
+}
+
+// Toggle shows or hides the tree. Showing it reloads the entries and
+// returns a command that loads the ignore rules and git status.
+func (t *FileTree) Toggle() tea.Cmd {
+	t.visible = !t.visible
+	if !t.visible {
+		t.cancelPrompt()
+		return nil
+	}
+	return t.Refresh()
+}
+
+// IsVisible returns whether the tree is shown.
+func (t *FileTree) IsVisible() bool {
+	return t.visible
+}
+
+// Prompting reports whether the tree is asking for a name or confirmation.
+func (t *FileTree) Prompting() bool {
+	return t.prompt != treePromptNone
+}
+
+// SetSize sets the rendered size of the tree.
+func (t *FileTree) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+	t.input.Width = max(width-6, 1)
+}
+
+// SetWorkspace shows the workspace at root, collapsed.
+func (t *FileTree) SetWorkspace(root string) tea.Cmd {
+	t.root = root
+	t.gitClient = git.NewClient(root)
+	t.expanded = make(map[string]bool)
+	t.status = nil
+	t.ignore = nil
+	t.agentChanged = nil
+	t.selected = 0
+	t.offset = 0
+	t.cancelPrompt()
+	return t.Refresh()
+}
+
+// Refresh reloads the entries and returns a command that loads the ignore
+// rules and git status. It does nothing while the tree is hidden.
+func (t *FileTree) Refresh() tea.Cmd {
+	if !t.visible {
+		return nil
+	}
+	t.rebuild()
+	client, root := t.gitClient, t.root
+	return func() tea.Msg {
+		msg := FileTreeStatusMsg{Root: root}
+		msg.Ignore, msg.Err = client.IgnoreRules()
+		msg.Statuses, _ = client.FileStatuses()
+		return msg
+	}
+}
+
+// SetStatus applies loaded ignore rules and git status. Ignore rules that
+// could not be read are reported in the footer, and nothing is hidden.
+func (t *FileTree) SetStatus(msg FileTreeStatusMsg) {
+	if msg.Root != t.root {
+		return
+	}
+	t.ignore = msg.Ignore
+	if msg.Err != nil {
+		t.message = "Cannot read ignore rules: " + msg.Err.Error()
+		t.isError = true
+	}
+	t.status = make(map[string]byte)
+	for _, f := range msg.Statuses {
+		code := f.Code()
+		letter := code[1]
+		if letter == ' ' {
+			letter = code[0]
+		}
+		t.status[f.Path] = letter
+		for dir := path.Dir(f.Path); dir != "."; dir = path.Dir(dir) {
+			t.status[dir] = '*'
+		}
+	}
+	t.rebuild()
+}
+
+// SetAgentChanged highlights the files at paths, absolute or relative to
+// the workspace, as changed by the last agent session, replacing the files
+// of the session before.
+func (t *FileTree) SetAgentChanged(paths []string) {
+	t.agentChanged = make(map[string]bool)
+	for _, p := range paths {
+		if filepath.IsAbs(p) {
+			rel, err := filepath.Rel(t.root, p)
+			if err != nil || !filepath.IsLocal(rel) {
+				continue
+			}
+			p = rel
+		}
+		for p = filepath.ToSlash(p); p != "."; p = path.Dir(p) {
+			t.agentChanged[p] = true
+		}
+	}
+}
+
+// rebuild lists the entries of the workspace and its open folders, keeping
+// the selection on the same path where possible.
+func (t *FileTree) rebuild() {
+	var selectedPath string
+	if node := t.selectedNode(); node != nil {
+		selectedPath = node.path
+	}
+	t.nodes = t.nodes[:0]
+	t.appendEntries("", 0)
+	t.selectPath(selectedPath)
+}
+
+// appendEntries adds the rows of the folder at dir, and of its open
+// subfolders, to the tree.
+func (t *FileTree) appendEntries(dir string, depth int) {
+	dirs, files, err := t.fileManager.ListEntries(filepath.Join(t.root, filepath.FromSlash(dir)))
+	if err != nil {
+		return
+	}
+	for _, name := range dirs {
+		p := path.Join(dir, name)
+		if t.ignore.Ignored(p, true) {
+			continue
+		}
+		t.nodes = append(t.nodes, treeNode{path: p, name: name, dir: true, depth: depth})
+		if t.expanded[p] {
+			t.appendEntries(p, depth+1)
+		}
+	}
+	for _, name := range files {
+		p := path.Join(dir, name)
+		if t.ignore.Ignored(p, false) {
+			continue
+		}
+		t.nodes = append(t.nodes, treeNode{path: p, name: name, depth: depth})
+	}
+}
+
+// selectedNode returns the selected row, or nil when the tree is empty.
+func (t *FileTree) selectedNode() *treeNode {
+	if t.selected < 0 || t.selected >= len(t.nodes) {
+		return nil
+	}
+	return &t.nodes[t.selected]
+}
+
+// selectPath selects the row of p, or keeps the selection in range when p
+// is not shown.
+func (t *FileTree) selectPath(p string) {
+	for i, node := range t.nodes {
+		if node.path == p {
+			t.selected = i
+			return
+		}
+	}
+	t.selected = min(t.selected, len(t.nodes)-1)
+	t.selected = max(t.selected, 0)
+}
+
+// absPath returns the absolute path of the workspace-relative p.
+func (t *FileTree) absPath(p string) string {
+	return filepath.Join(t.root, filepath.FromSlash(p))
+}
+
+// targetDir returns the folder new entries are created in: the selected
+// folder, or the folder of the selected file.
+func (t *FileTree) targetDir() string {
+	node := t.selectedNode()
+	switch {
+	case node == nil:
+		return ""
+	case node.dir:
+		return node.path
+	default:
+		return parentDir(node.path)
+	}
+}
+
+// parentDir returns the folder of the workspace-relative p, "" for the
+// workspace itself.
+func parentDir(p string) string {
+	if dir := path.Dir(p); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// Update handles a key press while the tree is focused. Opening a file
+// returns a command that sends an OpenFileInEditorMsg.
+func (t *FileTree) Update(msg tea.KeyMsg) tea.Cmd {
+	if t.prompt != treePromptNone {
+		return t.updatePrompt(msg)
+	}
+
+	node := t.selectedNode()
+	switch msg.String() {
+	case "up", "k":
+		t.move(-1)
+	case "down", "j":
+		t.move(1)
+	case "pgup":
+		t.move(-t.listHeight())
+	case "pgdown":
+		t.move(t.listHeight())
+	case "home", "g":
+		t.move(-len(t.nodes))
+	case "end", "G":
+		t.move(len(t.nodes))
+	case "enter", "right", "l":
+		if node == nil {
+			return nil
+		}
+		if !node.dir {
+			return t.open(node.path)
+		}
+		if msg.String() == "enter" || !t.expanded[node.path] {
+			t.expanded[node.path] = !t.expanded[node.path]
+			t.rebuild()
+		}
+	case "left", "h":
+		if node == nil {
+			return nil
+		}
+		if node.dir && t.expanded[node.path] {
+			t.expanded[node.path] = false
+			t.rebuild()
+		} else if dir := parentDir(node.path); dir != "" {
+			t.selectPath(dir)
+		}
+	case "n":
+		t.startPrompt(treePromptNewFile, "New file: ", "")
+	case "N":
+		t.startPrompt(treePromptNewFolder, "New folder: ", "")
+	case "r":
+		if node != nil {
+			t.startPrompt(treePromptRename, "Rename to: ", node.name)
+		}
+	case "m":
+		if node != nil {
+			t.startPrompt(treePromptMove, "Move to: ", node.path)
+		}
+	case "d":
+		if node != nil {
+			t.startPrompt(treePromptDelete, "", "")
+		}
+	case "R":
+		t.message = ""
+		return t.Refresh()
+	}
+	return nil
+}
+
+// move moves the selection by delta rows.
+func (t *FileTree) move(delta int) {
+	t.selected = max(min(t.selected+delta, len(t.nodes)-1), 0)
+}
+
+// open returns a command that opens the file at the workspace-relative p
+// in the editor.
+func (t *FileTree) open(p string) tea.Cmd {
+	abs := t.absPath(p)
+	return func() tea.Msg {
+		return OpenFileInEditorMsg{FilePath: abs}
+	}
+}
+
+// startPrompt opens the input for prompt, prefilled with value.
+func (t *FileTree) startPrompt(prompt fileTreePrompt, label, value string) {
+	t.prompt = prompt
+	t.message = ""
+	t.input.Prompt = label
+	t.input.SetValue(value)
+	t.input.CursorEnd()
+	if prompt != treePromptDelete {
+		t.input.Focus()
+	}
+}
+
+// cancelPrompt closes the input.
+func (t *FileTree) cancelPrompt() {
+	t.prompt = treePromptNone
+	t.input.Blur()
+}
+
+// updatePrompt handles keys while the input or the delete confirmation is
+// open: Enter (or y) runs the operation, Esc cancels it.
+func (t *FileTree) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	if t.prompt == treePromptDelete {
+		switch msg.String() {
+		case "y", "Y", "enter":
+			t.cancelPrompt()
+			return t.deleteSelected()
+		case "n", "N", "esc":
+			t.cancelPrompt()
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		t.cancelPrompt()
+		return nil
+	case "enter":
+		prompt := t.prompt
+		value := strings.TrimSpace(t.input.Value())
+		t.cancelPrompt()
+		if value == "" {
+			return nil
+		}
+		return t.run(prompt, value)
+	}
+
+	var cmd tea.Cmd
+	t.input, cmd = t.input.Update(msg)
+	return cmd
+}
+
+// run carries out the operation of a prompt answered with value.
+func (t *FileTree) run(prompt fileTreePrompt, value string) tea.Cmd {
+	value = filepath.ToSlash(value)
+	node := t.selectedNode()
+	var target string
+	switch prompt {
+	case treePromptNewFile, treePromptNewFolder:
+		target = path.Join(t.targetDir(), value)
+	case treePromptRename:
+		if strings.Contains(value, "/") {
+			return t.fail(fmt.Errorf("a name cannot contain /; press m to move"))
+		}
+		target = path.Join(parentDir(node.path), value)
+	case treePromptMove:
+		target = path.Clean(value)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(target)) {
+		return t.fail(fmt.Errorf("%s is outside the workspace", value))
+	}
+
+	var cmd tea.Cmd
+	switch prompt {
+	case treePromptNewFile:
+		if t.fileManager.FileExists(t.absPath(target)) {
+			return t.fail(fmt.Errorf("%s already exists", target))
+		}
+		if err := t.fileManager.CreateFile(t.absPath(target), ""); err != nil {
+			return t.fail(err)
+		}
+		t.message = "Created " + target
+		cmd = t.open(target)
+	case treePromptNewFolder:
+		if t.fileManager.FileExists(t.absPath(target)) {
+			return t.fail(fmt.Errorf("%s already exists", target))
+		}
+		if err := t.fileManager.CreateDirectory(t.absPath(target)); err != nil {
+			return t.fail(err)
+		}
+		t.message = "Created " + target + "/"
+	case treePromptRename, treePromptMove:
+		from, to := t.absPath(node.path), t.absPath(target)
+		if err := t.fileManager.RenamePath(from, to); err != nil {
+			return t.fail(err)
+		}
+		if t.expanded[node.path] {
+			delete(t.expanded, node.path)
+			t.expanded[target] = true
+		}
+		t.message = "Moved to " + target
+		if prompt == treePromptRename {
+			t.message = "Renamed to " + value
+		}
+		cmd = func() tea.Msg { return FileTreeRenamedMsg{From: from, To: to} }
+	}
+	t.isError = false
+
+	// Show the new path
+	for dir := parentDir(target); dir != ""; dir = parentDir(dir) {
+		t.expanded[dir] = true
+	}
+	t.rebuild()
+	t.selectPath(target)
+	return tea.Batch(cmd, t.Refresh())
+}
+
+// deleteSelected deletes the selected file or folder.
+func (t *FileTree) deleteSelected() tea.Cmd {
+	node := t.selectedNode()
+	if node == nil {
+		return nil
+	}
+	var err error
+	if node.dir {
+		err = t.fileManager.DeleteDirectory(t.absPath(node.path))
+	} else {
+		err = t.fileManager.DeleteFile(t.absPath(node.path))
+	}
+	if err != nil {
+		return t.fail(err)
+	}
+	delete(t.expanded, node.path)
+	t.message = "Deleted " + node.path
+	t.isError = false
+	return t.Refresh()
+}
+
+// fail shows err as the outcome of the last operation.
+func (t *FileTree) fail(err error) tea.Cmd {
+	t.message = err.Error()
+	t.isError = true
+	return nil
+}
+
+// HandleClick selects the row at y, relative to the top border; clicking
+// the selected row opens it, as Enter does.
+func (t *FileTree) HandleClick(y int) tea.Cmd {
+	row := t.offset + y - 2 // border and title
+	if y < 2 || row >= len(t.nodes) || t.prompt != treePromptNone {
+		return nil
+	}
+	if row != t.selected {
+		t.selected = row
+		return nil
+	}
+	return t.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+// listHeight returns the number of rows shown: the height less the
+// border, the title and the footer line.
+func (t *FileTree) listHeight() int {
+	return max(t.height-4, 1)
+}
+
+// View renders the tree in a bordered column.
+func (t *FileTree) View() string {
+	contentWidth := max(t.width-2, 1)
+	listHeight := t.listHeight()
+
+	if t.selected < t.offset {
+		t.offset = t.selected
+	}
+	if t.selected >= t.offset+listHeight {
+		t.offset = t.selected - listHeight + 1
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15"))
+	lines := []string{titleStyle.Render(truncateToWidth(filepath.Base(t.root)+"/", contentWidth))}
+
+	for i := t.offset; i < len(t.nodes) && i < t.offset+listHeight; i++ {
+		lines = append(lines, t.renderNode(t.nodes[i], i == t.selected, contentWidth))
+	}
+	if len(t.nodes) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("(empty)"))
+	}
+	for len(lines) < listHeight+1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, t.footer(contentWidth))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Width(contentWidth).
+		MaxWidth(t.width).
+		Height(t.height - 2)
+	if t.focused {
+		borderStyle = borderStyle.BorderForeground(lipgloss.Color("62"))
+	} else {
+		borderStyle = borderStyle.BorderForeground(lipgloss.Color("240"))
+	}
+	return borderStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderNode renders one row: the name indented by depth, with the git
+// status letter at the right edge.
+func (t *FileTree) renderNode(node treeNode, selected bool, width int) string {
+	icon := "  "
+	if node.dir {
+		icon = "▸ "
+		if t.expanded[node.path] {
+			icon = "▾ "
+		}
+	}
+	label := strings.Repeat("  ", node.depth) + icon + node.name
+	if node.dir {
+		label += "/"
+	}
+	label = truncateToWidth(label, width-2)
+	label += strings.Repeat(" ", max(width-2-lipgloss.Width(label), 0))
+
+	marker := " "
+	markerStyle := lipgloss.NewStyle()
+	if letter, ok := t.status[node.path]; ok {
+		marker = string(letter)
+		markerStyle = markerStyle.Foreground(lipgloss.Color(statusColor(letter)))
+		if letter == '*' {
+			marker = "●"
+		}
+	}
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	if t.agentChanged[node.path] {
+		style = style.Foreground(lipgloss.Color("213")).Bold(true)
+	}
+	if selected {
+		bg := lipgloss.Color("237")
+		if t.focused {
+			bg = lipgloss.Color("62")
+		}
+		style = style.Background(bg)
+		markerStyle = markerStyle.Background(bg)
+	}
+	return style.Render(label+" ") + markerStyle.Render(marker)
+}
+
+// statusColor returns the color of a git status letter in the tree.
+func statusColor(letter byte) string {
+	switch letter {
+	case 'A', '?':
+		return "42"
+	case 'D', 'U':
+		return "196"
+	case 'R', 'C':
+		return "39"
+	default:
+		return "214"
+	}
+}
+
+// footer renders the bottom line: the open prompt, the outcome of the last
+// operation, or the keys when focused.
+func (t *FileTree) footer(width int) string {
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	switch {
+	case t.prompt == treePromptDelete:
+		node := t.selectedNode()
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).
+			Render(truncateToWidth("Delete "+node.name+"? [y/n]", width))
+	case t.prompt != treePromptNone:
+		return truncateToWidth(t.input.View(), width)
+	case t.message != "":
+		color := "42"
+		if t.isError {
+			color = "196"
+		}
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(truncateToWidth(t.message, width))
+	case t.focused:
+		return hintStyle.Render(truncateToWidth("n/N new  r/m move  d del", width))
+	}
+	return ""
+}
+
+// toggleFileTree shows the file tree and focuses it. When the tree is shown
+// but another pane has the focus it is focused instead, and when it has the
+// focus it is hidden.
+func (a *App) toggleFileTree() tea.Cmd {
+	var cmd tea.Cmd
+	switch {
+	case !a.fileTree.IsVisible():
+		cmd = a.fileTree.Toggle()
+		a.focusPane(types.FileTreePaneType)
+	case a.activePane != types.FileTreePaneType:
+		a.focusPane(types.FileTreePaneType)
+	default:
+		a.fileTree.Toggle()
+		a.focusPane(types.EditorPaneType)
+	}
+	a.layout()
+	return cmd
+}
+
+// focusPane moves the focus to the editor, the AI input or the file tree.
+func (a *App) focusPane(pane types.PaneType) {
+	a.activePane = pane
+	a.editorPane.focused = pane == types.EditorPaneType
+	a.aiPane.focused = pane == types.AIPaneType
+	a.aiPane.SetActiveArea(0)
+}
+
+// markAgentChanged highlights the files changed by an agent session in the
+// file tree and returns a command that refreshes the tree.
+func (a *App) markAgentChanged(paths []string) tea.Cmd {
+	a.fileTree.SetAgentChanged(paths)
+	return a.fileTree.Refresh()
+}
@@ -0,0 +1,443 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// writeTreeFiles creates files under dir, given as path, content pairs
+func writeTreeFiles(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for i := 0; i < len(files); i += 2 {
+		path := filepath.Join(dir, filepath.FromSlash(files[i]))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(files[i+1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestTree returns a shown, focused file tree of dir with its status
+// loaded
+func newTestTree(t *testing.T, dir string) *FileTree {
+	t.Helper()
+	tree := NewFileTree(filemanager.NewFileManager(dir), dir)
+	tree.SetSize(30, 20)
+	tree.focused = true
+	loadTree(t, tree, tree.Toggle())
+	return tree
+}
+
+// loadTree applies the status loaded by cmd, which must come from Refresh
+func loadTree(t *testing.T, tree *FileTree, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command loading the status")
+	}
+	for _, msg := range runCmds(cmd) {
+		if status, ok := msg.(FileTreeStatusMsg); ok {
+			tree.SetStatus(status)
+			return
+		}
+	}
+	t.Fatal("expected a FileTreeStatusMsg")
+}
+
+// runCmds runs cmd and the commands of the batches it returns
+func runCmds(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, runCmds(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+// treePaths returns the paths of the rows of tree
+func treePaths(tree *FileTree) []string {
+	var paths []string
+	for _, node := range tree.nodes {
+		paths = append(paths, node.path)
+	}
+	return paths
+}
+
+// treeKey sends a key, given by name, to the tree
+func treeKey(tree *FileTree, key string) tea.Cmd {
+	switch key {
+	case "enter":
+		return tree.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	case "esc":
+		return tree.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	case "left":
+		return tree.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	case "down":
+		return tree.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	return tree.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+}
+
+// answer types text into the open prompt of tree and presses Enter
+func answer(tree *FileTree, text string) tea.Cmd {
+	tree.input.SetValue(text)
+	return treeKey(tree, "enter")
+}
+
+func TestFileTree_ListsAndHonoursGitignore(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir,
+		".gitignore", "*.log\nbuild/\n",
+		"main.go", "package main\n",
+		"debug.log", "x",
+		"build/out.bin", "x",
+		"src/util.go", "package src\n",
+		"src/trace.log", "x",
+	)
+	tree := newTestTree(t, dir)
+
+	if got := strings.Join(treePaths(tree), " "); got != "src main.go" {
+		t.Fatalf("expected folders first and ignored entries hidden, got %q", got)
+	}
+
+	treeKey(tree, "enter")
+	if got := strings.Join(treePaths(tree), " "); got != "src src/util.go main.go" {
+		t.Fatalf("expected Enter to expand src, got %q", got)
+	}
+	if !strings.Contains(tree.View(), "▾ src/") || !strings.Contains(tree.View(), "    util.go") {
+		t.Errorf("expected the open folder and its indented file in the view:\n%s", tree.View())
+	}
+
+	treeKey(tree, "down")
+	treeKey(tree, "left")
+	if node := tree.selectedNode(); node == nil || node.path != "src" {
+		t.Fatalf("expected Left on a file to select its folder, got %+v", node)
+	}
+	treeKey(tree, "left")
+	if len(tree.nodes) != 2 {
+		t.Errorf("expected Left on an open folder to close it, got %v", treePaths(tree))
+	}
+}
+
+func TestFileTree_ReportsIgnoreRulesError(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, "main.go", "package main\n")
+	// A .gitignore that is a folder cannot be read
+	if err := os.Mkdir(filepath.Join(dir, ".gitignore"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tree := newTestTree(t, dir)
+
+	if !tree.isError || !strings.Contains(tree.message, "Cannot read ignore rules") {
+		t.Errorf("expected the error in the footer, got %q", tree.message)
+	}
+	if !strings.Contains(strings.Join(treePaths(tree), " "), "main.go") {
+		t.Errorf("expected the files to be listed anyway, got %v", treePaths(tree))
+	}
+}
+
+func TestFileTree_OpensFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, "a.txt", "a")
+	tree := newTestTree(t, dir)
+
+	msgs := runCmds(treeKey(tree, "enter"))
+	if len(msgs) != 1 {
+		t.Fatalf("expected one message, got %v", msgs)
+	}
+	if open, ok := msgs[0].(OpenFileInEditorMsg); !ok || open.FilePath != filepath.Join(dir, "a.txt") {
+		t.Errorf("expected the file to be opened, got %#v", msgs[0])
+	}
+}
+
+func TestFileTree_GitStatusMarkers(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTreeFiles(t, dir, "kept.txt", "same\n", "pkg/edited.go", "one\n")
+	worktree, _ := repo.Worktree()
+	if _, err := worktree.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Commit("Initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	writeTreeFiles(t, dir, "pkg/edited.go", "two\n", "new.txt", "new\n")
+
+	tree := newTestTree(t, dir)
+	tree.expanded["pkg"] = true
+	tree.rebuild()
+
+	want := map[string]byte{"pkg": '*', "pkg/edited.go": 'M', "new.txt": '?'}
+	for path, letter := range want {
+		if tree.status[path] != letter {
+			t.Errorf("status of %s = %q, want %q", path, tree.status[path], letter)
+		}
+	}
+	if _, ok := tree.status["kept.txt"]; ok {
+		t.Error("expected no marker for an unchanged file")
+	}
+
+	lines := strings.Split(tree.View(), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "edited.go") && !strings.HasSuffix(strings.TrimSuffix(line, "│"), "M") {
+			t.Errorf("expected the M marker at the right edge: %q", line)
+		}
+		if strings.Contains(line, "pkg/") && !strings.Contains(line, "●") {
+			t.Errorf("expected a dot on the folder with changes: %q", line)
+		}
+	}
+}
+
+func TestFileTree_CreateRenameMoveDelete(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, "docs/readme.md", "# hi\n")
+	tree := newTestTree(t, dir)
+
+	// New file inside the selected folder, opened in the editor
+	treeKey(tree, "n")
+	if !tree.Prompting() {
+		t.Fatal("expected n to ask for a name")
+	}
+	msgs := runCmds(answer(tree, "guide.md"))
+	if _, err := os.Stat(filepath.Join(dir, "docs", "guide.md")); err != nil {
+		t.Fatalf("expected docs/guide.md to be created: %v", err)
+	}
+	if node := tree.selectedNode(); node == nil || node.path != "docs/guide.md" {
+		t.Errorf("expected the new file to be selected, got %+v", node)
+	}
+	opened := false
+	for _, msg := range msgs {
+		if open, ok := msg.(OpenFileInEditorMsg); ok && open.FilePath == filepath.Join(dir, "docs", "guide.md") {
+			opened = true
+		}
+	}
+	if !opened {
+		t.Errorf("expected the new file to be opened, got %v", msgs)
+	}
+
+	// Creating it again is refused
+	treeKey(tree, "n")
+	answer(tree, "guide.md")
+	if !tree.isError || !strings.Contains(tree.message, "already exists") {
+		t.Errorf("expected an error for an existing file, got %q", tree.message)
+	}
+
+	// New folder next to the selected file
+	treeKey(tree, "N")
+	answer(tree, "img")
+	if info, err := os.Stat(filepath.Join(dir, "docs", "img")); err != nil || !info.IsDir() {
+		t.Fatalf("expected docs/img to be created: %v", err)
+	}
+
+	// Rename keeps the folder
+	tree.selectPath("docs/guide.md")
+	treeKey(tree, "r")
+	if tree.input.Value() != "guide.md" {
+		t.Errorf("expected the rename prompt to start with the name, got %q", tree.input.Value())
+	}
+	msgs = runCmds(answer(tree, "manual.md"))
+	if len(msgs) == 0 {
+		t.Fatal("expected a rename message")
+	}
+	renamed, ok := msgs[0].(FileTreeRenamedMsg)
+	if !ok || renamed.From != filepath.Join(dir, "docs", "guide.md") || renamed.To != filepath.Join(dir, "docs", "manual.md") {
+		t.Errorf("unexpected rename message %#v", msgs[0])
+	}
+	treeKey(tree, "r")
+	answer(tree, "../x.md")
+	if !tree.isError {
+		t.Error("expected a rename with a slash to be refused")
+	}
+
+	// Move anywhere in the workspace, but not outside it
+	tree.selectPath("docs/manual.md")
+	treeKey(tree, "m")
+	answer(tree, "guides/manual.md")
+	if _, err := os.Stat(filepath.Join(dir, "guides", "manual.md")); err != nil {
+		t.Fatalf("expected the file to be moved: %v", err)
+	}
+	if node := tree.selectedNode(); node == nil || node.path != "guides/manual.md" {
+		t.Errorf("expected the moved file to be selected, got %+v", node)
+	}
+	treeKey(tree, "m")
+	answer(tree, "../outside.md")
+	if !tree.isError || !strings.Contains(tree.message, "outside the workspace") {
+		t.Errorf("expected a move out of the workspace to be refused, got %q", tree.message)
+	}
+
+	// Delete asks first
+	tree.selectPath("docs")
+	treeKey(tree, "d")
+	if !strings.Contains(tree.View(), "Delete docs? [y/n]") {
+		t.Errorf("expected a confirmation:\n%s", tree.View())
+	}
+	treeKey(tree, "n")
+	if _, err := os.Stat(filepath.Join(dir, "docs")); err != nil {
+		t.Fatal("expected n to keep the folder")
+	}
+	treeKey(tree, "d")
+	treeKey(tree, "y")
+	if _, err := os.Stat(filepath.Join(dir, "docs")); !os.IsNotExist(err) {
+		t.Errorf("expected the folder to be deleted, got %v", err)
+	}
+	if got := strings.Join(treePaths(tree), " "); got != "guides guides/manual.md" {
+		t.Errorf("unexpected rows after deleting: %q", got)
+	}
+}
+
+func TestFileTree_EscCancelsPrompt(t *testing.T) {
+	dir := t.TempDir()
+	tree := newTestTree(t, dir)
+	treeKey(tree, "n")
+	treeKey(tree, "esc")
+	if tree.Prompting() {
+		t.Error("expected Esc to close the prompt")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected nothing created, got %v", entries)
+	}
+}
+
+func TestFileTree_AgentChangedHighlight(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, "cmd/app/main.go", "package main\n", "other.go", "package x\n")
+	tree := newTestTree(t, dir)
+
+	tree.SetAgentChanged([]string{filepath.Join(dir, "cmd", "app", "main.go"), "/elsewhere/file.go"})
+	for _, path := range []string{"cmd", "cmd/app", "cmd/app/main.go"} {
+		if !tree.agentChanged[path] {
+			t.Errorf("expected %s to be highlighted", path)
+		}
+	}
+	if tree.agentChanged["other.go"] || len(tree.agentChanged) != 3 {
+		t.Errorf("unexpected highlights %v", tree.agentChanged)
+	}
+
+	tree.SetAgentChanged([]string{"other.go"})
+	if tree.agentChanged["cmd"] || !tree.agentChanged["other.go"] {
+		t.Errorf("expected a new session to replace the highlights, got %v", tree.agentChanged)
+	}
+}
+
+func TestEditorPane_RenamePath(t *testing.T) {
+	editor := newBufferEditor(t, "pkg/a.go", "package pkg\n", "b.txt", "b")
+	if err := editor.LoadFile("pkg/a.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := editor.LoadFile("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	editor.RenamePath("pkg", "lib")
+	if i := editor.findBuffer("lib/a.go"); i != 0 {
+		t.Errorf("expected the buffer of pkg/a.go to follow its folder, got %d", i)
+	}
+	editor.RenamePath("b.txt", "c.md")
+	if editor.currentFile == nil || filepath.Base(editor.currentFile.Filepath) != "c.md" || editor.currentFile.FileType != "markdown" {
+		t.Errorf("expected the active buffer to be renamed, got %+v", editor.currentFile)
+	}
+}
+
+func TestApp_FileTreeToggleAndLayout(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "hello")
+	app.width, app.height = 120, 40
+	app.layout()
+	fullEditor := app.editorPane.width
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if !app.fileTree.IsVisible() || app.activePane != types.FileTreePaneType {
+		t.Fatal("expected Ctrl+E to show and focus the tree")
+	}
+	if app.fileTree.width != fileTreeWidth(120) || app.editorPane.width >= fullEditor {
+		t.Errorf("expected the tree to take a column, tree %d editor %d", app.fileTree.width, app.editorPane.width)
+	}
+	lines := strings.Split(app.View(), "\n")
+	for _, line := range lines {
+		if w := len([]rune(line)); w > 120 {
+			t.Fatalf("line wider than the terminal (%d): %q", w, line)
+		}
+	}
+
+	// Tab leaves the tree for the editor, Esc comes back to it
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if app.activePane != types.EditorPaneType {
+		t.Errorf("expected Tab to move to the editor, got %v", app.activePane)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if app.activePane != types.FileTreePaneType || !app.fileTree.IsVisible() {
+		t.Error("expected Ctrl+E to focus the shown tree")
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.activePane != types.EditorPaneType {
+		t.Errorf("expected Esc to leave the tree, got %v", app.activePane)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if app.fileTree.IsVisible() || app.activePane != types.EditorPaneType || app.editorPane.width != fullEditor {
+		t.Error("expected Ctrl+E on the focused tree to hide it and give the space back")
+	}
+}
+
+func TestApp_FileTreeRenameFollowsBuffers(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "hello")
+	from := app.editorPane.resolvePath("a.txt")
+	to := filepath.Join(filepath.Dir(from), "b.txt")
+	app.Update(FileTreeRenamedMsg{From: from, To: to})
+	if app.editorPane.currentFile.Filepath != to {
+		t.Errorf("expected the open buffer to be renamed, got %s", app.editorPane.currentFile.Filepath)
+	}
+}
+
+func TestApp_MouseClickInFileTree(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "hello")
+	writeTreeFiles(t, app.config.WorkspaceDir, "one.txt", "1", "two.txt", "2")
+	app.width, app.height = 120, 40
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	// Rows start below the top border and the title
+	click := tea.MouseMsg{X: 3, Y: app.editorTop() + 3, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	app.Update(click)
+	if app.activePane != types.FileTreePaneType {
+		t.Fatal("expected a click to focus the tree")
+	}
+	if node := app.fileTree.selectedNode(); node == nil || node.path != "two.txt" {
+		t.Fatalf("expected the clicked row to be selected, got %+v", node)
+	}
+	_, cmd := app.Update(click)
+	msgs := runCmds(cmd)
+	if len(msgs) != 1 {
+		t.Fatalf("expected a second click to open the file, got %v", msgs)
+	}
+	if open, ok := msgs[0].(OpenFileInEditorMsg); !ok || filepath.Base(open.FilePath) != "two.txt" {
+		t.Errorf("unexpected message %#v", msgs[0])
+	}
+
+	// Clicks right of the tree reach the editor
+	app.Update(tea.MouseMsg{X: app.fileTree.width + editorTextLeft + 2, Y: app.editorTop() + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if app.activePane != types.EditorPaneType || app.editorPane.cursorCol != 2 {
+		t.Errorf("expected the click to focus the editor at column 2, got pane %v col %d", app.activePane, app.editorPane.cursorCol)
+	}
+}
@@ -1,11 +1,13 @@
 package ui
 
 import (
+	"errors"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/diff"
 	"github.com/user/terminal-intelligence/internal/git"
 )
 
@@ -33,11 +35,56 @@ type GitPane struct {
 	userInput      textinput.Model // Input field for Git username
 	passInput      textinput.Model // Input field for Git password or GitHub PAT (ghp_*)
 	commitMsgInput textinput.Model // Input field for commit message (only shown when Commit is selected)
+	commitBody     string          // Body of a suggested commit message, added below the input's subject
 
 	// Button state
-	// selectedButton: 0=Clone, 1=Pull, 2=Fetch, 3=Stage, 4=Commit, 5=Push, 6=Status, 7=Restore
+	// selectedButton: 0=Clone, 1=Pull, 2=Fetch, 3=Stage, 4=Commit, 5=Push, 6=Status, 7=Restore, 8=Branches, 9=Changes, 10=Log, 11=Conflicts, 12=Stash, 13=Tags
 	selectedButton int
 
+	// Branch view state (shown instead of the inputs and buttons)
+	branchMode   bool             // Whether the branch list is shown
+	branches     []git.BranchInfo // Local branches of the repository
+	branchIdx    int              // Index of the selected branch
+	branchPrompt branchPrompt     // Which prompt the branch input is showing
+	branchInput  textinput.Model  // Input for new branch names, renames and upstreams
+
+	// Changes view state (shown instead of the inputs and buttons)
+	changesMode bool             // Whether the changed file list is shown
+	changes     []git.FileStatus // Changed files of the repository
+	changeIdx   int              // Index of the selected file
+	diffMode    bool             // Whether the diff of diffPath is shown
+	diffPath    string           // File whose diff is shown
+	diffStaged  bool             // Whether the staged rather than the unstaged diff is shown
+	diffHunks   []diff.Hunk      // Hunks of the shown diff
+	hunkIdx     int              // Index of the selected hunk
+
+	// History view state (shown instead of the inputs and buttons)
+	logMode      bool             // Whether the commit history is shown
+	commits      []git.CommitInfo // Commits loaded so far, newest first
+	logIdx       int              // Index of the selected commit
+	logMore      bool             // Whether older commits can be loaded
+	commitShown  *git.CommitInfo  // Commit whose diff is shown, nil for the list
+	commitFiles  []git.FileDiff   // Changes of the shown commit
+	commitScroll int              // First line of the commit view on screen
+
+	// Conflicts view state (shown instead of the inputs and buttons)
+	conflictsMode bool            // Whether the merge in progress is shown
+	mergeState    *git.MergeState // Merge or rebase in progress, nil if none
+	conflictIdx   int             // Index of the selected conflicted file
+
+	// Stash view state (shown instead of the inputs and buttons)
+	stashMode   bool             // Whether the stash list is shown
+	stashes     []git.StashEntry // Stash entries, newest first
+	stashIdx    int              // Index of the selected entry
+	stashPrompt bool             // Whether the stash message is being asked for
+
+	// Tags view state (shown instead of the inputs and buttons)
+	tagsMode  bool          // Whether the tag list is shown
+	tags      []git.TagInfo // Tags, newest first
+	tagIdx    int           // Index of the selected tag
+	tagPrompt tagPrompt     // What the tag input is asking for
+	tagName   string        // Name of the tag being created, once entered
+
 	// Status display
 	statusMessage string // Success message displayed after successful operations
 	errorMessage  string // Error message displayed after failed operations
@@ -104,6 +151,8 @@ func (g *GitPane) Init() tea.Cmd {
 	g.commitMsgInput.CharLimit = 200
 	g.commitMsgInput.Width = 60
 
+	g.branchInput = newBranchInput()
+
 	// Set initial focus to URL input
 	g.urlInput.Focus()
 	g.focusedInput = 0
@@ -117,7 +166,7 @@ func (g *GitPane) Init() tea.Cmd {
 // and any stored credentials that were found.
 type GitRepositoryDetectedMsg struct {
 	IsRepo      bool             // Whether the directory is a Git repository
-	Credentials *git.Credentials // Stored credentials from .git/config, nil if not found
+	Credentials *git.Credentials // Stored credentials, nil if not found
 	RemoteURL   string           // URL of the remote repository, empty if not configured
 }
 
@@ -166,7 +215,7 @@ type GitRestoreMsg struct{}
 // It contains the operation type, success status, result message, any error, and for clone operations,
 // the path to the newly cloned directory.
 type GitOperationCompleteMsg struct {
-	Operation string // The operation type: "clone", "pull", "push", "fetch", "stage", "status", "restore"
+	Operation string // The operation type: "clone", "pull", "push", "fetch", "stage", "status", "restore", "branch"
 	Success   bool   // Whether the operation completed successfully
 	Message   string // Human-readable message describing the result
 	Error     error  // Error details if the operation failed, nil on success
@@ -190,6 +239,12 @@ func (g *GitPane) Toggle() tea.Cmd {
 	// Closing the pane - clear status messages
 	g.statusMessage = ""
 	g.errorMessage = ""
+	g.closeBranches()
+	g.closeChanges()
+	g.closeLog()
+	g.closeConflicts()
+	g.closeStash()
+	g.closeTags()
 	return nil
 }
 
@@ -427,6 +482,57 @@ func (g *GitPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			g.statusMessage = ""
 		}
 
+		// A merge or pull that stopped with conflicts shows them
+		if errors.Is(msg.Error, git.ErrMergeConflict) {
+			cmd := g.openConflicts()
+			g.errorMessage = msg.Error.Error()
+			return g, cmd
+		}
+
+		// Branch and staging operations change the open list, so reload it
+		if msg.Operation == "branch" && g.branchMode {
+			return g, g.loadBranches()
+		}
+		if msg.Operation == "changes" && g.changesMode {
+			return g, g.loadChanges()
+		}
+		if msg.Operation == "conflicts" && g.conflictsMode {
+			return g, g.loadConflicts()
+		}
+		if msg.Operation == "stash" && g.stashMode {
+			return g, g.loadStash()
+		}
+		if msg.Operation == "tag" && g.tagsMode {
+			return g, g.loadTags()
+		}
+
+	case GitBranchesMsg:
+		g.setBranches(msg)
+
+	case GitMergeStateMsg:
+		g.setConflicts(msg)
+
+	case GitStashListMsg:
+		g.setStash(msg)
+
+	case GitTagsMsg:
+		g.setTags(msg)
+
+	case GitChangesMsg:
+		return g, g.setChanges(msg)
+
+	case GitDiffMsg:
+		g.setDiff(msg)
+
+	case GitLogMsg:
+		g.setLog(msg)
+
+	case GitCommitDiffMsg:
+		g.setCommitDiff(msg)
+
+	case GitCommitSuggestionMsg:
+		g.setSuggestion(msg)
+
 	case GitCloneMsg:
 		// Handle clone operation - execute async via GitClient
 		return g, func() tea.Msg {
@@ -525,11 +631,32 @@ func (g *GitPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		// The branch view has its own keys
+		if g.branchMode {
+			return g.updateBranches(msg)
+		}
+		if g.changesMode {
+			return g.updateChanges(msg)
+		}
+		if g.logMode {
+			return g.updateLog(msg)
+		}
+		if g.conflictsMode {
+			return g.updateConflicts(msg)
+		}
+		if g.stashMode {
+			return g.updateStash(msg)
+		}
+		if g.tagsMode {
+			return g.updateTags(msg)
+		}
+
 		// Handle keyboard input for navigation and interaction
 		switch msg.String() {
 		case "esc":
 			// Esc closes the popup
 			g.visible = false
+			g.commitBody = ""
 			g.statusMessage = ""
 			g.errorMessage = ""
 			return g, nil
@@ -598,6 +725,18 @@ func (g *GitPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return g, g.executeStatus()
 				case 7: // Restore
 					return g, g.executeRestore()
+				case branchesButton:
+					return g, g.openBranches()
+				case changesButton:
+					return g, g.openChanges()
+				case logButton:
+					return g, g.openLog()
+				case conflictsButton:
+					return g, g.openConflicts()
+				case stashButton:
+					return g, g.openStash()
+				case tagsButton:
+					return g, g.openTags()
 				}
 				return g, nil
 			} else if g.focusedInput == 4 {
@@ -607,25 +746,36 @@ func (g *GitPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					g.errorMessage = "Commit message cannot be empty"
 					return g, nil
 				}
+				if g.commitBody != "" {
+					commitMsg += "\n\n" + g.commitBody
+				}
 				// Clear the commit message input and go back to buttons
 				g.commitMsgInput.SetValue("")
+				g.commitBody = ""
 				g.focusedInput = 3
 				g.updateFocus()
 				return g, g.executeCommit(commitMsg)
 			}
 			// If focused on input field, Enter does nothing (use Tab/Down to move)
 
+		case "alt+s":
+			// Alt+S on the Commit button suggests a message from the staged diff
+			if g.selectedButton == 4 && g.focusedInput >= 3 && !g.isProcessing {
+				return g, g.requestSuggestion()
+			}
+			return g, nil
+
 		case "left", "right":
 			// Arrow keys navigate between buttons when focused on buttons
 			if g.focusedInput == 3 {
 				if msg.String() == "left" {
 					g.selectedButton--
 					if g.selectedButton < 0 {
-						g.selectedButton = 7 // Wrap to last button (Restore)
+						g.selectedButton = tagsButton // Wrap to last button (Tags)
 					}
 				} else { // "right"
 					g.selectedButton++
-					if g.selectedButton > 7 {
+					if g.selectedButton > tagsButton {
 						g.selectedButton = 0 // Wrap to first button (Clone)
 					}
 				}
@@ -649,7 +799,11 @@ func (g *GitPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case 2:
 			g.passInput, cmd = g.passInput.Update(msg)
 		case 4:
+			subject := g.commitMsgInput.Value()
 			g.commitMsgInput, cmd = g.commitMsgInput.Update(msg)
+			if g.commitMsgInput.Value() != subject {
+				g.commitBody = ""
+			}
 		}
 	}
 
@@ -657,8 +811,13 @@ func (g *GitPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 // updateFocus updates the focus state of input fields based on focusedInput.
-// It ensures only the currently focused input field is active.
+// It ensures only the currently focused input field is active, and drops a
+// suggested commit body once the commit message input loses focus.
 func (g *GitPane) updateFocus() {
+	if g.focusedInput != 4 {
+		g.commitBody = ""
+	}
+
 	// Blur all input fields first
 	g.urlInput.Blur()
 	g.userInput.Blur()
@@ -695,7 +854,7 @@ func (g *GitPane) View() string {
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("62")).
 		Padding(1, 2).
-		Width(84)
+		Width(100)
 
 	buttonStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("230")).
@@ -721,6 +880,53 @@ func (g *GitPane) View() string {
 	// Build the content
 	var content strings.Builder
 
+	if g.branchMode {
+		content.WriteString(g.viewBranches())
+	} else if g.changesMode {
+		content.WriteString(g.viewChanges())
+	} else if g.logMode {
+		content.WriteString(g.viewLog())
+	} else if g.conflictsMode {
+		content.WriteString(g.viewConflicts())
+	} else if g.stashMode {
+		content.WriteString(g.viewStash())
+	} else if g.tagsMode {
+		content.WriteString(g.viewTags())
+	} else {
+		g.viewOperations(&content, buttonStyle, buttonSelectedStyle)
+	}
+
+	// Status/Error messages
+	if g.isProcessing {
+		content.WriteString(processingStyle.Render("Processing..."))
+		content.WriteString("\n")
+	} else if g.errorMessage != "" {
+		content.WriteString(errorStyle.Render("Error: " + g.errorMessage))
+		content.WriteString("\n")
+	} else if g.statusMessage != "" {
+		content.WriteString(successStyle.Render(g.statusMessage))
+		content.WriteString("\n")
+	}
+
+	// Render the popup with the content
+	popup := popupStyle.Render(content.String())
+
+	// Center the popup on screen
+	if g.width > 0 && g.height > 0 {
+		return lipgloss.Place(
+			g.width,
+			g.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			popup,
+		)
+	}
+
+	return popup
+}
+
+// viewOperations renders the input fields and operation buttons.
+func (g *GitPane) viewOperations(content *strings.Builder, buttonStyle, buttonSelectedStyle lipgloss.Style) {
 	// Title
 	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Operations"))
 	content.WriteString("\n\n")
@@ -734,7 +940,8 @@ func (g *GitPane) View() string {
 	content.WriteString("\n\n")
 
 	// Buttons - reordered and grouped: Clone Pull Fetch | Stage Commit Push | Status Restore
-	buttonNames := []string{"Clone", "Pull", "Fetch", "Stage", "Commit", "Push", "Status", "Restore"}
+	// and, on a second row, Branches Changes Log Conflicts Stash Tags
+	buttonNames := []string{"Clone", "Pull", "Fetch", "Stage", "Commit", "Push", "Status", "Restore", "Branches", "Changes", "Log", "Conflicts", "Stash", "Tags"}
 	var buttons []string
 	for i, name := range buttonNames {
 		if g.focusedInput == 3 && g.selectedButton == i {
@@ -755,41 +962,22 @@ func (g *GitPane) View() string {
 	buttonRow += "  |  "
 	// Group 3: Status Restore (info and undo)
 	buttonRow += buttons[6] + "  " + buttons[7]
+	// Group 4: Branches Changes Log Conflicts Stash Tags (branches, selective staging, history, merges, stash and releases)
+	buttonRow += "\n\n" + buttons[8] + "  " + buttons[9] + "  " + buttons[10] + "  " + buttons[11] + "  " + buttons[12] + "  " + buttons[13]
 	
 	content.WriteString(buttonRow)
 	content.WriteString("\n\n")
 
 	// Show commit message input only when Commit button is selected
 	if g.selectedButton == 4 {
+		helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 		content.WriteString(g.commitMsgInput.View())
-		content.WriteString("\n\n")
-	}
-
-	// Status/Error messages
-	if g.isProcessing {
-		content.WriteString(processingStyle.Render("Processing..."))
 		content.WriteString("\n")
-	} else if g.errorMessage != "" {
-		content.WriteString(errorStyle.Render("Error: " + g.errorMessage))
-		content.WriteString("\n")
-	} else if g.statusMessage != "" {
-		content.WriteString(successStyle.Render(g.statusMessage))
-		content.WriteString("\n")
-	}
-
-	// Render the popup with the content
-	popup := popupStyle.Render(content.String())
-
-	// Center the popup on screen
-	if g.width > 0 && g.height > 0 {
-		return lipgloss.Place(
-			g.width,
-			g.height,
-			lipgloss.Center,
-			lipgloss.Center,
-			popup,
-		)
+		if g.commitBody != "" {
+			content.WriteString(helpStyle.Render("    " + strings.ReplaceAll(g.commitBody, "\n", "\n    ")))
+			content.WriteString("\n")
+		}
+		content.WriteString(helpStyle.Render("Alt+S suggest a message from the staged diff"))
+		content.WriteString("\n\n")
 	}
-
-	return popup
 }
@@ -0,0 +1,281 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// branchesButton is the index of the Branches button, which opens the
+// branch list in place of the input fields and buttons.
+const branchesButton = 8
+
+// branchPrompt identifies what the branch name input is asking for
+type branchPrompt int
+
+const (
+	branchPromptNone     branchPrompt = iota
+	branchPromptNew                   // name of a new branch
+	branchPromptRename                // new name of the selected branch
+	branchPromptUpstream              // remote/branch for the selected branch to track
+)
+
+// GitBranchesMsg carries the branch list loaded for the branch view.
+type GitBranchesMsg struct {
+	Branches []git.BranchInfo
+	Error    error
+}
+
+// newBranchInput creates the input used by the branch prompts.
+func newBranchInput() textinput.Model {
+	input := textinput.New()
+	input.CharLimit = 200
+	input.Width = 60
+	return input
+}
+
+// openBranches shows the branch view and loads the branch list.
+func (g *GitPane) openBranches() tea.Cmd {
+	g.branchMode = true
+	g.branchPrompt = branchPromptNone
+	g.branchIdx = -1 // select the current branch once loaded
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return g.loadBranches()
+}
+
+// closeBranches returns from the branch view to the buttons.
+func (g *GitPane) closeBranches() {
+	g.branchMode = false
+	g.branchPrompt = branchPromptNone
+	g.branchInput.Blur()
+}
+
+// loadBranches returns a command that lists the branches of the repository.
+func (g *GitPane) loadBranches() tea.Cmd {
+	client := g.gitClient
+	return func() tea.Msg {
+		if client == nil {
+			return GitBranchesMsg{Error: fmt.Errorf("no repository")}
+		}
+		branches, err := client.Branches()
+		return GitBranchesMsg{Branches: branches, Error: err}
+	}
+}
+
+// selectedBranch returns the branch under the cursor, or nil if there is none.
+func (g *GitPane) selectedBranch() *git.BranchInfo {
+	if g.branchIdx < 0 || g.branchIdx >= len(g.branches) {
+		return nil
+	}
+	return &g.branches[g.branchIdx]
+}
+
+// runClientOperation returns a command that runs op and reports it as a
+// GitOperationCompleteMsg for operation, after which the open view reloads.
+func (g *GitPane) runClientOperation(operation string, op func(*git.Client) (*git.OperationResult, error)) tea.Cmd {
+	client := g.gitClient
+	if client == nil {
+		return nil
+	}
+	g.isProcessing = true
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return func() tea.Msg {
+		result, _ := op(client)
+		return GitOperationCompleteMsg{
+			Operation: operation,
+			Success:   result.Success,
+			Message:   result.Message,
+			Error:     result.Error,
+		}
+	}
+}
+
+// startBranchPrompt focuses the branch input with an initial value.
+func (g *GitPane) startBranchPrompt(prompt branchPrompt, label, value string) tea.Cmd {
+	g.branchPrompt = prompt
+	g.branchInput.Prompt = label
+	g.branchInput.SetValue(value)
+	g.branchInput.CursorEnd()
+	return g.branchInput.Focus()
+}
+
+// updateBranches handles keys in the branch view.
+func (g *GitPane) updateBranches(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if g.branchPrompt != branchPromptNone {
+		return g.updateBranchPrompt(msg)
+	}
+
+	branch := g.selectedBranch()
+	switch msg.String() {
+	case "esc":
+		g.closeBranches()
+	case "up", "k":
+		if g.branchIdx > 0 {
+			g.branchIdx--
+		}
+	case "down", "j":
+		if g.branchIdx < len(g.branches)-1 {
+			g.branchIdx++
+		}
+	case "enter":
+		if branch != nil {
+			name := branch.Name
+			return g, g.runClientOperation("branch", func(c *git.Client) (*git.OperationResult, error) {
+				return c.SwitchBranch(name)
+			})
+		}
+	case "m":
+		if branch != nil && !branch.Current {
+			name := branch.Name
+			return g, g.runClientOperation("branch", func(c *git.Client) (*git.OperationResult, error) {
+				return c.Merge(name)
+			})
+		}
+	case "n":
+		return g, g.startBranchPrompt(branchPromptNew, "New branch: ", "")
+	case "r":
+		if branch != nil {
+			return g, g.startBranchPrompt(branchPromptRename, "Rename to: ", branch.Name)
+		}
+	case "u":
+		if branch != nil {
+			upstream := branch.Upstream
+			if upstream == "" {
+				upstream = "origin/" + branch.Name
+			}
+			return g, g.startBranchPrompt(branchPromptUpstream, "Track (remote/branch): ", upstream)
+		}
+	case "d", "D":
+		if branch != nil {
+			name, force := branch.Name, msg.String() == "D"
+			return g, g.runClientOperation("branch", func(c *git.Client) (*git.OperationResult, error) {
+				return c.DeleteBranch(name, force)
+			})
+		}
+	}
+	return g, nil
+}
+
+// updateBranchPrompt handles keys while a branch prompt is open: Enter runs
+// the operation, Esc cancels it.
+func (g *GitPane) updateBranchPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		g.branchPrompt = branchPromptNone
+		g.branchInput.Blur()
+		return g, nil
+
+	case "enter":
+		value := strings.TrimSpace(g.branchInput.Value())
+		prompt := g.branchPrompt
+		g.branchPrompt = branchPromptNone
+		g.branchInput.Blur()
+
+		var selected string
+		if branch := g.selectedBranch(); branch != nil {
+			selected = branch.Name
+		}
+		switch prompt {
+		case branchPromptNew:
+			return g, g.runClientOperation("branch", func(c *git.Client) (*git.OperationResult, error) {
+				return c.CreateBranch(value, true)
+			})
+		case branchPromptRename:
+			return g, g.runClientOperation("branch", func(c *git.Client) (*git.OperationResult, error) {
+				return c.RenameBranch(selected, value)
+			})
+		case branchPromptUpstream:
+			remote, remoteBranch, ok := strings.Cut(value, "/")
+			if !ok || remote == "" || remoteBranch == "" {
+				g.errorMessage = "Upstream must be given as remote/branch, e.g. origin/main"
+				return g, nil
+			}
+			return g, g.runClientOperation("branch", func(c *git.Client) (*git.OperationResult, error) {
+				return c.SetUpstream(selected, remote, remoteBranch)
+			})
+		}
+		return g, nil
+	}
+
+	var cmd tea.Cmd
+	g.branchInput, cmd = g.branchInput.Update(msg)
+	return g, cmd
+}
+
+// setBranches replaces the branch list. The cursor starts on the current
+// branch and otherwise stays where it was.
+func (g *GitPane) setBranches(msg GitBranchesMsg) {
+	if msg.Error != nil {
+		g.branches = nil
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	g.branches = msg.Branches
+	if g.branchIdx < 0 {
+		g.branchIdx = 0
+		for i, b := range g.branches {
+			if b.Current {
+				g.branchIdx = i
+			}
+		}
+	}
+	g.branchIdx = min(g.branchIdx, max(len(g.branches)-1, 0))
+}
+
+// formatBranch renders one row of the branch list.
+func formatBranch(b git.BranchInfo) string {
+	marker := "  "
+	if b.Current {
+		marker = "* "
+	}
+	line := fmt.Sprintf("%s%-24s %s", marker, b.Name, b.Hash)
+	switch {
+	case b.Upstream == "":
+	case b.Gone:
+		line += fmt.Sprintf("  [%s: gone]", b.Upstream)
+	case b.Ahead == 0 && b.Behind == 0:
+		line += fmt.Sprintf("  [%s]", b.Upstream)
+	default:
+		line += fmt.Sprintf("  [%s ↑%d ↓%d]", b.Upstream, b.Ahead, b.Behind)
+	}
+	return line
+}
+
+// viewBranches renders the content of the branch view.
+func (g *GitPane) viewBranches() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Branches"))
+	content.WriteString("\n\n")
+
+	if len(g.branches) == 0 && g.errorMessage == "" {
+		content.WriteString("(no branches yet - make a first commit)\n")
+	}
+	for i, b := range g.branches {
+		if i == g.branchIdx {
+			content.WriteString(selectedStyle.Render("▶ " + formatBranch(b)))
+		} else {
+			content.WriteString("  " + formatBranch(b))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	if g.branchPrompt != branchPromptNone {
+		content.WriteString(g.branchInput.View())
+		content.WriteString("\n")
+		content.WriteString(helpStyle.Render("Enter confirm  Esc cancel"))
+	} else {
+		content.WriteString(helpStyle.Render("Enter switch  m merge into current  n new  r rename  d delete (D force)  u track upstream  Esc back"))
+	}
+	content.WriteString("\n\n")
+	return content.String()
+}
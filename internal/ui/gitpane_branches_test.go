@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// newBranchTestPane returns a visible GitPane on a repository with one commit
+func newBranchTestPane(t *testing.T) (*GitPane, *gogit.Repository) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := repo.Worktree()
+	if _, err := worktree.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Commit("Initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pane := NewGitPane(git.NewClient(dir), dir)
+	pane.visible = true
+	return pane, repo
+}
+
+// feed sends msg to the pane and keeps delivering the messages produced by
+// the git results of the returned commands until none are left. Commands
+// that do not finish promptly (cursor blinks) are abandoned.
+func feed(pane *GitPane, msg tea.Msg) {
+	for msg != nil {
+		_, cmd := pane.Update(msg)
+		if cmd == nil {
+			return
+		}
+		result := make(chan tea.Msg, 1)
+		go func() { result <- cmd() }()
+		select {
+		case next := <-result:
+			switch next.(type) {
+			case GitBranchesMsg, GitChangesMsg, GitDiffMsg, GitLogMsg, GitCommitDiffMsg, GitMergeStateMsg, GitStashListMsg, GitTagsMsg, GitOperationCompleteMsg:
+				msg = next
+			default:
+				return
+			}
+		case <-time.After(2 * time.Second):
+			return
+		}
+	}
+}
+
+func TestGitPane_BranchesButtonOpensList(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.focusedInput = 3
+	pane.selectedButton = branchesButton
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.branchMode {
+		t.Fatal("expected the branch view to open")
+	}
+	if len(pane.branches) != 1 || !pane.branches[0].Current {
+		t.Fatalf("unexpected branches: %+v", pane.branches)
+	}
+	view := pane.View()
+	if !strings.Contains(view, "Git Branches") || !strings.Contains(view, "* master") {
+		t.Errorf("branch list not rendered:\n%s", view)
+	}
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.branchMode || !pane.visible {
+		t.Error("esc should return from the branch view to the buttons")
+	}
+}
+
+func TestGitPane_CreateRenameAndDeleteBranch(t *testing.T) {
+	pane, repo := newBranchTestPane(t)
+	feed(pane, pane.openBranches()())
+
+	// n opens the prompt; Enter creates and switches
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if pane.branchPrompt != branchPromptNew {
+		t.Fatal("expected the new branch prompt")
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("feature")})
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if head, _ := repo.Head(); head.Name().Short() != "feature" {
+		t.Fatalf("expected HEAD on feature, got %s (error: %s)", head.Name().Short(), pane.errorMessage)
+	}
+	if len(pane.branches) != 2 {
+		t.Fatalf("expected the list to be reloaded, got %+v", pane.branches)
+	}
+
+	// Rename the selected branch
+	pane.branchIdx = 0 // feature
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	pane.branchInput.SetValue("feature-2")
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if head, _ := repo.Head(); head.Name().Short() != "feature-2" {
+		t.Errorf("expected the current branch to be renamed, got %s (error: %s)", head.Name().Short(), pane.errorMessage)
+	}
+
+	// Deleting the current branch is refused
+	pane.branchIdx = 0
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if !strings.Contains(pane.errorMessage, "current branch") {
+		t.Errorf("expected current branch error, got %q", pane.errorMessage)
+	}
+
+	// Switch to master and delete the merged branch
+	pane.branchIdx = 1
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	pane.branchIdx = 0
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if len(pane.branches) != 1 || pane.branches[0].Name != "master" {
+		t.Errorf("expected only master to remain, got %+v (error: %s)", pane.branches, pane.errorMessage)
+	}
+}
+
+func TestGitPane_UpstreamPromptValidates(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	feed(pane, pane.openBranches()())
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if got := pane.branchInput.Value(); got != "origin/master" {
+		t.Errorf("expected origin/master to be suggested, got %q", got)
+	}
+	pane.branchInput.SetValue("nonsense")
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(pane.errorMessage, "remote/branch") {
+		t.Errorf("expected format error, got %q", pane.errorMessage)
+	}
+
+	// A repository without the remote reports it
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(pane.errorMessage, `remote "origin" does not exist`) {
+		t.Errorf("expected missing remote error, got %q", pane.errorMessage)
+	}
+}
+
+func TestFormatBranch(t *testing.T) {
+	tests := []struct {
+		branch git.BranchInfo
+		want   string
+	}{
+		{git.BranchInfo{Name: "main", Hash: "abc1234", Current: true}, "* main"},
+		{git.BranchInfo{Name: "dev", Hash: "abc1234", Upstream: "origin/dev"}, "[origin/dev]"},
+		{git.BranchInfo{Name: "dev", Hash: "abc1234", Upstream: "origin/dev", Ahead: 2, Behind: 1}, "[origin/dev ↑2 ↓1]"},
+		{git.BranchInfo{Name: "old", Hash: "abc1234", Upstream: "origin/old", Gone: true}, "[origin/old: gone]"},
+	}
+	for _, tt := range tests {
+		if got := formatBranch(tt.branch); !strings.Contains(got, tt.want) {
+			t.Errorf("formatBranch(%+v) = %q, want it to contain %q", tt.branch, got, tt.want)
+		}
+	}
+}
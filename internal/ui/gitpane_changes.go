@@ -0,0 +1,341 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/diff"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// changesButton is the index of the Changes button, which opens the list of
+// changed files with per-file diffs and staging.
+const changesButton = 9
+
+// GitChangesMsg carries the changed files loaded for the changes view.
+type GitChangesMsg struct {
+	Files []git.FileStatus
+	Error error
+}
+
+// GitDiffMsg carries the diff of one file for the changes view.
+type GitDiffMsg struct {
+	Path   string
+	Staged bool // Whether the diff is HEAD against the index rather than the index against the worktree
+	Hunks  []diff.Hunk
+	Error  error
+}
+
+// openChanges shows the changes view and loads the changed files.
+func (g *GitPane) openChanges() tea.Cmd {
+	g.changesMode = true
+	g.diffMode = false
+	g.changeIdx = 0
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return g.loadChanges()
+}
+
+// closeChanges returns from the changes view to the buttons.
+func (g *GitPane) closeChanges() {
+	g.changesMode = false
+	g.diffMode = false
+	g.diffHunks = nil
+}
+
+// loadChanges returns a command that lists the changed files.
+func (g *GitPane) loadChanges() tea.Cmd {
+	client := g.gitClient
+	return func() tea.Msg {
+		if client == nil {
+			return GitChangesMsg{Error: fmt.Errorf("no repository")}
+		}
+		files, err := client.FileStatuses()
+		return GitChangesMsg{Files: files, Error: err}
+	}
+}
+
+// loadDiff returns a command that diffs path, staged or unstaged.
+func (g *GitPane) loadDiff(path string, staged bool) tea.Cmd {
+	client := g.gitClient
+	return func() tea.Msg {
+		if client == nil {
+			return GitDiffMsg{Path: path, Staged: staged, Error: fmt.Errorf("no repository")}
+		}
+		hunks, err := client.Diff(path, staged)
+		return GitDiffMsg{Path: path, Staged: staged, Hunks: hunks, Error: err}
+	}
+}
+
+// selectedChange returns the file under the cursor, or nil if there is none.
+func (g *GitPane) selectedChange() *git.FileStatus {
+	if g.changeIdx < 0 || g.changeIdx >= len(g.changes) {
+		return nil
+	}
+	return &g.changes[g.changeIdx]
+}
+
+// setChanges replaces the changed file list, keeping the cursor in range.
+// While a diff is open it is reloaded, since the operation that changed the
+// list changed the diff too.
+func (g *GitPane) setChanges(msg GitChangesMsg) tea.Cmd {
+	if msg.Error != nil {
+		g.changes = nil
+		g.errorMessage = msg.Error.Error()
+		return nil
+	}
+	g.changes = msg.Files
+	g.changeIdx = min(g.changeIdx, max(len(g.changes)-1, 0))
+	if g.diffMode {
+		return g.loadDiff(g.diffPath, g.diffStaged)
+	}
+	return nil
+}
+
+// setDiff shows a loaded diff. Results for a file or side that is no longer
+// shown are ignored.
+func (g *GitPane) setDiff(msg GitDiffMsg) {
+	if !g.diffMode || msg.Path != g.diffPath || msg.Staged != g.diffStaged {
+		return
+	}
+	if msg.Error != nil {
+		g.diffHunks = nil
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	g.diffHunks = msg.Hunks
+	g.hunkIdx = min(g.hunkIdx, max(len(g.diffHunks)-1, 0))
+}
+
+// openDiff shows the diff of a file. Unstaged changes are shown first; a
+// file with only staged changes opens on its staged diff.
+func (g *GitPane) openDiff(file git.FileStatus) tea.Cmd {
+	g.diffMode = true
+	g.diffPath = file.Path
+	g.diffStaged = !file.Unstaged()
+	g.diffHunks = nil
+	g.hunkIdx = 0
+	return g.loadDiff(g.diffPath, g.diffStaged)
+}
+
+// updateChanges handles keys in the changes view.
+func (g *GitPane) updateChanges(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if g.diffMode {
+		return g.updateDiff(msg)
+	}
+
+	file := g.selectedChange()
+	switch msg.String() {
+	case "esc":
+		g.closeChanges()
+	case "up", "k":
+		if g.changeIdx > 0 {
+			g.changeIdx--
+		}
+	case "down", "j":
+		if g.changeIdx < len(g.changes)-1 {
+			g.changeIdx++
+		}
+	case "enter", "d":
+		if file != nil {
+			return g, g.openDiff(*file)
+		}
+	case "s", " ":
+		if file != nil {
+			path := file.Path
+			return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+				return c.StageFile(path)
+			})
+		}
+	case "u":
+		if file != nil {
+			path := file.Path
+			return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+				return c.UnstageFile(path)
+			})
+		}
+	case "x":
+		if file != nil {
+			path := file.Path
+			return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+				return c.RestoreFile(path)
+			})
+		}
+	}
+	return g, nil
+}
+
+// updateDiff handles keys while the diff of a file is shown.
+func (g *GitPane) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	path, hunk := g.diffPath, g.hunkIdx
+	switch msg.String() {
+	case "esc":
+		g.diffMode = false
+		g.diffHunks = nil
+	case "up", "k":
+		if g.hunkIdx > 0 {
+			g.hunkIdx--
+		}
+	case "down", "j":
+		if g.hunkIdx < len(g.diffHunks)-1 {
+			g.hunkIdx++
+		}
+	case "t":
+		g.diffStaged = !g.diffStaged
+		g.diffHunks = nil
+		g.hunkIdx = 0
+		return g, g.loadDiff(g.diffPath, g.diffStaged)
+	case "s":
+		if g.diffStaged || len(g.diffHunks) == 0 {
+			return g, nil
+		}
+		return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+			return c.StageHunks(path, []int{hunk})
+		})
+	case "u":
+		if !g.diffStaged || len(g.diffHunks) == 0 {
+			return g, nil
+		}
+		return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+			return c.UnstageHunks(path, []int{hunk})
+		})
+	case "S":
+		return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+			return c.StageFile(path)
+		})
+	case "U":
+		return g, g.runClientOperation("changes", func(c *git.Client) (*git.OperationResult, error) {
+			return c.UnstageFile(path)
+		})
+	}
+	return g, nil
+}
+
+// describeStatus explains the status of a file, e.g. "staged added, modified".
+func describeStatus(f git.FileStatus) string {
+	names := map[byte]string{
+		'M': "modified", 'A': "added", 'D': "deleted", 'R': "renamed",
+		'C': "copied", 'U': "conflict", '?': "untracked",
+	}
+	var parts []string
+	if f.Staged() {
+		parts = append(parts, "staged "+names[byte(f.Index)])
+	}
+	if f.Unstaged() {
+		parts = append(parts, names[byte(f.Worktree)])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// viewChanges renders the content of the changes view.
+func (g *GitPane) viewChanges() string {
+	if g.diffMode {
+		return g.viewDiff()
+	}
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Changes"))
+	content.WriteString("\n\n")
+
+	if len(g.changes) == 0 && g.errorMessage == "" {
+		content.WriteString("(working tree clean)\n")
+	}
+	for i, f := range g.changes {
+		line := fmt.Sprintf("%s  %-50s %s", f.Code(), f.Path, describeStatus(f))
+		if i == g.changeIdx {
+			content.WriteString(selectedStyle.Render("▶ " + line))
+		} else {
+			content.WriteString("  " + line)
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(helpStyle.Render("Enter diff  s stage  u unstage  x restore  Esc back"))
+	content.WriteString("\n\n")
+	return content.String()
+}
+
+// viewDiff renders the diff of the selected file, scrolled to the selected
+// hunk.
+func (g *GitPane) viewDiff() string {
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	side := "unstaged (index → worktree)"
+	if g.diffStaged {
+		side = "staged (HEAD → index)"
+	}
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(g.diffPath + " — " + side))
+	content.WriteString("\n\n")
+
+	if len(g.diffHunks) == 0 && g.errorMessage == "" {
+		if g.diffStaged {
+			content.WriteString("(no staged changes)\n")
+		} else {
+			content.WriteString("(no unstaged changes)\n")
+		}
+	}
+
+	// Lines from the selected hunk on, as many as fit the screen
+	var lines []string
+	for i, h := range g.diffHunks {
+		if i < g.hunkIdx {
+			continue
+		}
+		header := fmt.Sprintf("%s  (hunk %d/%d)", h.Header(), i+1, len(g.diffHunks))
+		if i == g.hunkIdx {
+			lines = append(lines, selectedStyle.Render("▶ "+header))
+		} else {
+			lines = append(lines, headerStyle.Render("  "+header))
+		}
+		for _, l := range h.Lines {
+			lines = append(lines, "  "+renderDiffLine(l))
+		}
+	}
+	limit := g.listHeight()
+	if len(lines) > limit {
+		lines = append(lines[:limit], helpStyle.Render("  …"))
+	}
+	for _, line := range lines {
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	if g.diffStaged {
+		content.WriteString(helpStyle.Render("j/k hunk  u unstage hunk  U unstage file  t show unstaged  Esc back"))
+	} else {
+		content.WriteString(helpStyle.Render("j/k hunk  s stage hunk  S stage file  t show staged  Esc back"))
+	}
+	content.WriteString("\n\n")
+	return content.String()
+}
+
+// renderDiffLine renders one line of a hunk with its +/- marker, colored by
+// kind.
+func renderDiffLine(l diff.Line) string {
+	text := strings.TrimRight(l.Text, "\r\n")
+	switch l.Kind {
+	case diff.Insert:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("+" + text)
+	case diff.Delete:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("-" + text)
+	}
+	return " " + text
+}
+
+// listHeight returns how many rows of a list or diff fit in the popup.
+func (g *GitPane) listHeight() int {
+	if g.height <= 0 {
+		return 20
+	}
+	return max(g.height-16, 5)
+}
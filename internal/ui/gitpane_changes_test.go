@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// newChangesTestPane returns a branch test pane whose README.md has changes
+// at its start and end, far enough apart to form two hunks
+func newChangesTestPane(t *testing.T) *GitPane {
+	t.Helper()
+	pane, repo := newBranchTestPane(t)
+	worktree, _ := repo.Worktree()
+	root := worktree.Filesystem.Root()
+
+	lines := "# demo\n1\n2\n3\n4\n5\n6\n7\n8\n9\nend\n"
+	commitREADME(t, repo, lines)
+	changed := strings.Replace(strings.Replace(lines, "# demo", "# Demo", 1), "end", "END", 1)
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("todo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	feed(pane, pane.openChanges()())
+	return pane
+}
+
+// commitREADME commits README.md with content
+func commitREADME(t *testing.T, repo *gogit.Repository, content string) {
+	t.Helper()
+	worktree, _ := repo.Worktree()
+	if err := os.WriteFile(filepath.Join(worktree.Filesystem.Root(), "README.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree.Add("README.md")
+	if _, err := worktree.Commit("Update README", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// statusCodes lists the changes of the pane as "XY path"
+func statusCodes(pane *GitPane) string {
+	var codes []string
+	for _, f := range pane.changes {
+		codes = append(codes, f.Code()+" "+f.Path)
+	}
+	return strings.Join(codes, "|")
+}
+
+func TestGitPane_ChangesButtonOpensList(t *testing.T) {
+	pane := newChangesTestPane(t)
+	pane.closeChanges()
+	pane.focusedInput = 3
+	pane.selectedButton = changesButton
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.changesMode {
+		t.Fatal("expected the changes view to open")
+	}
+	if got := statusCodes(pane); got != " M README.md|?? notes.txt" {
+		t.Errorf("unexpected changes: %q", got)
+	}
+	view := pane.View()
+	if !strings.Contains(view, "Git Changes") || !strings.Contains(view, "untracked") {
+		t.Errorf("changes not rendered:\n%s", view)
+	}
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.changesMode || !pane.visible {
+		t.Error("esc should return from the changes view to the buttons")
+	}
+}
+
+func TestGitPane_StageAndUnstageFile(t *testing.T) {
+	pane := newChangesTestPane(t)
+
+	pane.changeIdx = 1 // notes.txt
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if got := statusCodes(pane); got != " M README.md|A  notes.txt" {
+		t.Errorf("expected notes.txt staged, got %q (error: %s)", got, pane.errorMessage)
+	}
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if got := statusCodes(pane); got != " M README.md|?? notes.txt" {
+		t.Errorf("expected notes.txt unstaged, got %q (error: %s)", got, pane.errorMessage)
+	}
+}
+
+func TestGitPane_StageHunkFromDiff(t *testing.T) {
+	pane := newChangesTestPane(t)
+
+	pane.changeIdx = 0 // README.md
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.diffMode || pane.diffStaged || len(pane.diffHunks) != 2 {
+		t.Fatalf("expected the unstaged diff with 2 hunks, got staged=%v hunks=%d", pane.diffStaged, len(pane.diffHunks))
+	}
+	if view := pane.View(); !strings.Contains(view, "+# Demo") || !strings.Contains(view, "hunk 1/2") {
+		t.Errorf("diff not rendered:\n%s", view)
+	}
+
+	// Stage the second hunk only
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if got := statusCodes(pane); !strings.HasPrefix(got, "MM README.md") {
+		t.Errorf("expected README.md partly staged, got %q (error: %s)", got, pane.errorMessage)
+	}
+	if len(pane.diffHunks) != 1 {
+		t.Errorf("expected one unstaged hunk left, got %d", len(pane.diffHunks))
+	}
+
+	// The staged side shows the staged hunk, which can be unstaged again
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if !pane.diffStaged || len(pane.diffHunks) != 1 || !strings.Contains(pane.View(), "+END") {
+		t.Fatalf("expected the staged hunk, got %+v", pane.diffHunks)
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if got := statusCodes(pane); !strings.HasPrefix(got, " M README.md") {
+		t.Errorf("expected nothing staged, got %q (error: %s)", got, pane.errorMessage)
+	}
+
+	client := git.NewClient(pane.workDir)
+	if hunks, _ := client.Diff("README.md", true); len(hunks) != 0 {
+		t.Errorf("expected no staged hunks, got %d", len(hunks))
+	}
+}
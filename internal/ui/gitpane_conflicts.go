@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/agentic"
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// conflictsButton is the index of the Conflicts button, which opens the
+// merge in progress and its conflicted files.
+const conflictsButton = 11
+
+// GitMergeStateMsg carries the merge or rebase in progress for the
+// conflicts view. State is nil when there is none.
+type GitMergeStateMsg struct {
+	State *git.MergeState
+	Error error
+}
+
+// GitOpenConflictMsg asks the App to open a conflicted file in the editor.
+type GitOpenConflictMsg struct {
+	Path string // Relative to the repository root
+}
+
+// GitResolveWithAIMsg asks the App to open a conflicted file and have the
+// agentic fixer propose a resolution.
+type GitResolveWithAIMsg struct {
+	Path string // Relative to the repository root
+}
+
+// openConflicts shows the conflicts view and loads the merge state.
+func (g *GitPane) openConflicts() tea.Cmd {
+	g.closeBranches()
+	g.closeChanges()
+	g.closeLog()
+	g.closeStash()
+	g.closeTags()
+	g.conflictsMode = true
+	g.conflictIdx = 0
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return g.loadConflicts()
+}
+
+// closeConflicts returns from the conflicts view to the buttons.
+func (g *GitPane) closeConflicts() {
+	g.conflictsMode = false
+	g.mergeState = nil
+}
+
+// loadConflicts returns a command that reads the merge in progress.
+func (g *GitPane) loadConflicts() tea.Cmd {
+	client := g.gitClient
+	return func() tea.Msg {
+		if client == nil {
+			return GitMergeStateMsg{Error: fmt.Errorf("no repository")}
+		}
+		state, err := client.MergeState()
+		return GitMergeStateMsg{State: state, Error: err}
+	}
+}
+
+// setConflicts replaces the merge state, keeping the cursor in range.
+func (g *GitPane) setConflicts(msg GitMergeStateMsg) {
+	if msg.Error != nil {
+		g.mergeState = nil
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	g.mergeState = msg.State
+	count := 0
+	if msg.State != nil {
+		count = len(msg.State.Conflicts)
+	}
+	g.conflictIdx = min(g.conflictIdx, max(count-1, 0))
+}
+
+// selectedConflict returns the conflicted path under the cursor, or "" if
+// there is none.
+func (g *GitPane) selectedConflict() string {
+	if g.mergeState == nil || g.conflictIdx >= len(g.mergeState.Conflicts) {
+		return ""
+	}
+	return g.mergeState.Conflicts[g.conflictIdx]
+}
+
+// updateConflicts handles keys in the conflicts view.
+func (g *GitPane) updateConflicts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	path := g.selectedConflict()
+	switch msg.String() {
+	case "esc":
+		g.closeConflicts()
+	case "up", "k":
+		if g.conflictIdx > 0 {
+			g.conflictIdx--
+		}
+	case "down", "j":
+		if g.mergeState != nil && g.conflictIdx < len(g.mergeState.Conflicts)-1 {
+			g.conflictIdx++
+		}
+	case "enter", "e":
+		if path != "" {
+			return g, func() tea.Msg { return GitOpenConflictMsg{Path: path} }
+		}
+	case "a":
+		if path != "" {
+			return g, func() tea.Msg { return GitResolveWithAIMsg{Path: path} }
+		}
+	case "r":
+		if path != "" {
+			return g, g.runClientOperation("conflicts", func(c *git.Client) (*git.OperationResult, error) {
+				return c.ResolveFile(path)
+			})
+		}
+	case "c":
+		if g.mergeState != nil {
+			return g, g.runClientOperation("conflicts", func(c *git.Client) (*git.OperationResult, error) {
+				return c.ContinueMerge()
+			})
+		}
+	case "X":
+		if g.mergeState != nil {
+			return g, g.runClientOperation("conflicts", func(c *git.Client) (*git.OperationResult, error) {
+				return c.AbortMerge()
+			})
+		}
+	}
+	return g, nil
+}
+
+// viewConflicts renders the content of the conflicts view.
+func (g *GitPane) viewConflicts() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Merge Conflicts"))
+	content.WriteString("\n\n")
+
+	state := g.mergeState
+	switch {
+	case state == nil:
+		if g.errorMessage == "" {
+			content.WriteString("(no merge in progress)\n\n")
+			content.WriteString(helpStyle.Render("Esc back"))
+			content.WriteString("\n\n")
+		}
+		return content.String()
+	case state.Operation == "rebase":
+		content.WriteString("Rebase in progress\n\n")
+	default:
+		content.WriteString(fmt.Sprintf("Merging %s\n\n", state.Theirs))
+	}
+
+	if len(state.Conflicts) == 0 {
+		content.WriteString("All conflicts resolved\n")
+	}
+	for i, path := range state.Conflicts {
+		if i == g.conflictIdx {
+			content.WriteString(selectedStyle.Render("▶ UU " + path))
+		} else {
+			content.WriteString("  UU " + path)
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	if len(state.Conflicts) == 0 {
+		content.WriteString(helpStyle.Render("c commit the merge  X abort  Esc back"))
+	} else {
+		content.WriteString(helpStyle.Render("Enter edit  a resolve with AI  r mark resolved  X abort  Esc back"))
+	}
+	content.WriteString("\n\n")
+	return content.String()
+}
+
+// openConflictFile loads a conflicted file into the editor and focuses it,
+// closing the Git panel so the conflict keys reach the editor.
+func (a *App) openConflictFile(path string) error {
+	fullPath := filepath.Join(a.gitPane.workDir, path)
+	if err := a.editorPane.LoadFile(fullPath); err != nil {
+		return err
+	}
+	if a.gitPane.IsVisible() {
+		a.gitPane.Toggle()
+	}
+	a.activePane = types.EditorPaneType
+	a.editorPane.focused = true
+	a.aiPane.focused = false
+	return nil
+}
+
+// handleOpenConflict opens a conflicted file for manual resolution.
+func (a *App) handleOpenConflict(msg GitOpenConflictMsg) tea.Cmd {
+	if err := a.openConflictFile(msg.Path); err != nil {
+		a.statusMessage = "Error opening file: " + err.Error()
+		return nil
+	}
+	a.statusMessage = "Alt+O ours  Alt+T theirs  Alt+A both  Alt+C next conflict — save, then mark it resolved in the Git panel"
+	return nil
+}
+
+// handleResolveWithAI opens a conflicted file and asks the agentic fixer to
+// resolve its conflict markers. The proposal replaces the editor content
+// like any other fix, so it can be reviewed and undone before saving. The
+// request can be stopped like a /fix session.
+func (a *App) handleResolveWithAI(msg GitResolveWithAIMsg) tea.Cmd {
+	if err := a.openConflictFile(msg.Path); err != nil {
+		a.statusMessage = "Error opening file: " + err.Error()
+		return nil
+	}
+	if a.agenticFixer == nil {
+		a.statusMessage = "AI resolution is not available"
+		return nil
+	}
+
+	file := a.editorPane.currentFile
+	content := a.editorPane.GetContent()
+	message := "fix the merge conflicts in this file: resolve every block between <<<<<<< and >>>>>>> markers by combining the intent of both sides, and remove all conflict markers"
+
+	a.aiPane.AddFixRequest(message, file.Filepath, content)
+	a.aiPane.streaming = true
+	a.statusMessage = "Asking the AI to resolve " + msg.Path + "..."
+
+	fixer := a.agenticFixer
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancelAgent = cancel
+	return func() tea.Msg {
+		defer cancel()
+		result, err := fixer.ProcessMessageContext(ctx, message, content, file.Filepath, file.FileType)
+		if ai.IsCancelled(err) {
+			return AgenticFixResultMsg{
+				Result: &agentic.FixResult{
+					Success:      false,
+					ErrorMessage: "Conflict resolution stopped; " + msg.Path + " is unchanged",
+				},
+			}
+		}
+		if err != nil {
+			return AgenticFixResultMsg{
+				Result: &agentic.FixResult{
+					Success:      false,
+					ErrorMessage: "Error processing message: " + err.Error(),
+				},
+			}
+		}
+		return AgenticFixResultMsg{Result: result}
+	}
+}
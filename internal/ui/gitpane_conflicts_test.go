@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/user/terminal-intelligence/internal/agentic"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// newConflictTestPane returns a GitPane on master whose README.md conflicts
+// with the one on the feature branch
+func newConflictTestPane(t *testing.T) (*GitPane, *gogit.Repository) {
+	t.Helper()
+	pane, repo := newBranchTestPane(t)
+	head, _ := repo.Head()
+	feature := plumbing.NewBranchReferenceName("feature")
+	repo.Storer.SetReference(plumbing.NewHashReference(feature, head.Hash()))
+
+	commitREADME(t, repo, "# demo\nours\n")
+	worktree, _ := repo.Worktree()
+	if err := worktree.Checkout(&gogit.CheckoutOptions{Branch: feature}); err != nil {
+		t.Fatal(err)
+	}
+	commitREADME(t, repo, "# demo\ntheirs\n")
+	if err := worktree.Checkout(&gogit.CheckoutOptions{Branch: plumbing.Master}); err != nil {
+		t.Fatal(err)
+	}
+	return pane, repo
+}
+
+// mergeFeature merges the feature branch from the branch view
+func mergeFeature(t *testing.T, pane *GitPane) {
+	t.Helper()
+	feed(pane, pane.openBranches()())
+	for i, b := range pane.branches {
+		if b.Name == "feature" {
+			pane.branchIdx = i
+		}
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+}
+
+func TestGitPane_MergeShowsConflicts(t *testing.T) {
+	pane, _ := newConflictTestPane(t)
+	mergeFeature(t, pane)
+
+	if pane.branchMode || !pane.conflictsMode {
+		t.Fatal("expected a conflicted merge to open the conflicts view")
+	}
+	if pane.mergeState == nil || strings.Join(pane.mergeState.Conflicts, ",") != "README.md" {
+		t.Fatalf("unexpected merge state: %+v", pane.mergeState)
+	}
+	view := pane.View()
+	for _, want := range []string{"Merge Conflicts", "Merging ", "UU README.md", "Merge conflict in README.md"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestGitPane_ResolveAndCommitMerge(t *testing.T) {
+	pane, repo := newConflictTestPane(t)
+	mergeFeature(t, pane)
+	root := pane.workDir
+
+	// Marking a file with markers left as resolved is refused
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if pane.errorMessage == "" || len(pane.mergeState.Conflicts) != 1 {
+		t.Fatal("expected a file with conflict markers to stay unresolved")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\nours and theirs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if len(pane.mergeState.Conflicts) != 0 {
+		t.Fatalf("expected the file to be resolved, got %+v (%s)", pane.mergeState, pane.errorMessage)
+	}
+	if !strings.Contains(pane.View(), "All conflicts resolved") {
+		t.Error("expected the view to offer committing the merge")
+	}
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if pane.mergeState != nil {
+		t.Fatalf("expected the merge to be finished, got %+v (%s)", pane.mergeState, pane.errorMessage)
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.NumParents() != 2 {
+		t.Errorf("expected a merge commit, got %d parents", commit.NumParents())
+	}
+}
+
+func TestGitPane_AbortMerge(t *testing.T) {
+	pane, _ := newConflictTestPane(t)
+	mergeFeature(t, pane)
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X")})
+	if pane.mergeState != nil {
+		t.Fatalf("expected the merge to be aborted, got %+v", pane.mergeState)
+	}
+	content, _ := os.ReadFile(filepath.Join(pane.workDir, "README.md"))
+	if string(content) != "# demo\nours\n" {
+		t.Errorf("expected our version to be restored, got %q", content)
+	}
+}
+
+func TestGitPane_ConflictKeysOpenFiles(t *testing.T) {
+	pane, _ := newConflictTestPane(t)
+	mergeFeature(t, pane)
+
+	_, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(GitOpenConflictMsg); !ok || msg.Path != "README.md" {
+		t.Errorf("expected Enter to open the file, got %#v", msg)
+	}
+	_, cmd = pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if msg, ok := cmd().(GitResolveWithAIMsg); !ok || msg.Path != "README.md" {
+		t.Errorf("expected a to ask the AI, got %#v", msg)
+	}
+}
+
+func TestGitPane_ConflictsWithoutMerge(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.focusedInput = 3
+	pane.selectedButton = conflictsButton
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.conflictsMode || pane.mergeState != nil {
+		t.Fatal("expected an empty conflicts view")
+	}
+	if !strings.Contains(pane.View(), "no merge in progress") {
+		t.Error("expected the view to say there is no merge")
+	}
+	pane.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.conflictsMode {
+		t.Error("expected Esc to close the view")
+	}
+}
+
+func TestApp_OpenConflictFocusesEditor(t *testing.T) {
+	pane, _ := newConflictTestPane(t)
+	mergeFeature(t, pane)
+	root := pane.workDir
+	editor := NewEditorPane(filemanager.NewFileManager(root))
+	editor.SetSize(100, 20)
+	app := newTestApp(t, root)
+	app.editorPane = editor
+	app.gitPane = pane
+
+	app.Update(GitOpenConflictMsg{Path: "README.md"})
+	if pane.IsVisible() {
+		t.Error("expected the Git panel to close")
+	}
+	if app.activePane != types.EditorPaneType || !editor.HasConflicts() {
+		t.Fatal("expected the conflicted file in the focused editor")
+	}
+	if !strings.Contains(app.statusMessage, "Alt+O") {
+		t.Errorf("expected the conflict keys in the status, got %q", app.statusMessage)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t"), Alt: true})
+	if got := editor.GetContent(); got != "# demo\ntheirs\n" {
+		t.Errorf("expected Alt+T to take theirs, got %q", got)
+	}
+}
+
+func TestApp_StopCancelsAIConflictResolution(t *testing.T) {
+	pane, _ := newConflictTestPane(t)
+	mergeFeature(t, pane)
+	app := newTestApp(t, pane.workDir)
+	app.gitPane = pane
+	client := &chunkedAIClient{chunks: []string{"never sent"}, step: make(chan struct{})}
+	app.agenticFixer = agentic.NewAgenticCodeFixer(client, "test-model")
+
+	_, cmd := app.Update(GitResolveWithAIMsg{Path: "README.md"})
+	if cmd == nil || app.cancelAgent == nil {
+		t.Fatal("expected a cancellable resolution request")
+	}
+	if !app.stopGeneration() {
+		t.Fatal("expected the resolution to be stopped")
+	}
+
+	msg, ok := cmd().(AgenticFixResultMsg)
+	if !ok || msg.Result.Success || !strings.Contains(msg.Result.ErrorMessage, "stopped") {
+		t.Fatalf("expected a stopped result, got %+v", msg.Result)
+	}
+	app.Update(msg)
+	if !app.editorPane.HasConflicts() {
+		t.Error("expected the conflicted file to be left unchanged")
+	}
+}
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// logButton is the index of the Log button, which opens the commit history.
+const logButton = 10
+
+// logPageSize is the number of commits loaded at a time.
+const logPageSize = 50
+
+// GitLogMsg carries one page of the commit history.
+type GitLogMsg struct {
+	Commits []git.CommitInfo
+	Skip    int  // Number of commits before this page
+	More    bool // Whether there are commits after this page
+	Error   error
+}
+
+// GitCommitDiffMsg carries a commit and its changes for the commit view.
+type GitCommitDiffMsg struct {
+	Commit *git.CommitInfo
+	Files  []git.FileDiff
+	Error  error
+}
+
+// openLog shows the history view and loads the first page of commits.
+func (g *GitPane) openLog() tea.Cmd {
+	g.logMode = true
+	g.commits = nil
+	g.logIdx = 0
+	g.logMore = false
+	g.commitShown = nil
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return g.loadLog(0)
+}
+
+// closeLog returns from the history view to the buttons.
+func (g *GitPane) closeLog() {
+	g.logMode = false
+	g.commitShown = nil
+	g.commitFiles = nil
+}
+
+// loadLog returns a command that loads the page of commits after skip.
+func (g *GitPane) loadLog(skip int) tea.Cmd {
+	client := g.gitClient
+	g.isProcessing = true
+	return func() tea.Msg {
+		if client == nil {
+			return GitLogMsg{Skip: skip, Error: fmt.Errorf("no repository")}
+		}
+		commits, more, err := client.Log(skip, logPageSize)
+		return GitLogMsg{Commits: commits, Skip: skip, More: more, Error: err}
+	}
+}
+
+// loadCommitDiff returns a command that loads a commit and its changes.
+func (g *GitPane) loadCommitDiff(hash string) tea.Cmd {
+	client := g.gitClient
+	g.isProcessing = true
+	return func() tea.Msg {
+		if client == nil {
+			return GitCommitDiffMsg{Error: fmt.Errorf("no repository")}
+		}
+		commit, files, err := client.CommitDiff(hash)
+		return GitCommitDiffMsg{Commit: commit, Files: files, Error: err}
+	}
+}
+
+// setLog appends a loaded page to the history. Pages that do not continue
+// the list, e.g. from before the view was reopened, are ignored.
+func (g *GitPane) setLog(msg GitLogMsg) {
+	g.isProcessing = false
+	if msg.Error != nil {
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	if !g.logMode || msg.Skip != len(g.commits) {
+		return
+	}
+	g.commits = append(g.commits, msg.Commits...)
+	g.logMore = msg.More
+}
+
+// setCommitDiff shows a loaded commit.
+func (g *GitPane) setCommitDiff(msg GitCommitDiffMsg) {
+	g.isProcessing = false
+	if msg.Error != nil {
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	if !g.logMode {
+		return
+	}
+	g.commitShown = msg.Commit
+	g.commitFiles = msg.Files
+	g.commitScroll = 0
+}
+
+// updateLog handles keys in the history view.
+func (g *GitPane) updateLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if g.commitShown != nil {
+		return g.updateCommitView(msg)
+	}
+
+	switch msg.String() {
+	case "esc":
+		g.closeLog()
+	case "up", "k":
+		if g.logIdx > 0 {
+			g.logIdx--
+		}
+	case "down", "j":
+		if g.logIdx < len(g.commits)-1 {
+			g.logIdx++
+		}
+		// Load the next page when the cursor reaches the end
+		if g.logIdx == len(g.commits)-1 && g.logMore && !g.isProcessing {
+			return g, g.loadLog(len(g.commits))
+		}
+	case "enter":
+		if g.logIdx < len(g.commits) {
+			return g, g.loadCommitDiff(g.commits[g.logIdx].Hash)
+		}
+	}
+	return g, nil
+}
+
+// updateCommitView handles keys while a commit is shown.
+func (g *GitPane) updateCommitView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := g.listHeight()
+	last := max(len(g.commitLines())-page, 0)
+	switch msg.String() {
+	case "esc":
+		g.commitShown = nil
+		g.commitFiles = nil
+	case "up", "k":
+		g.commitScroll = max(g.commitScroll-1, 0)
+	case "down", "j":
+		g.commitScroll = min(g.commitScroll+1, last)
+	case "pgup", "b":
+		g.commitScroll = max(g.commitScroll-page, 0)
+	case "pgdown", " ":
+		g.commitScroll = min(g.commitScroll+page, last)
+	}
+	return g, nil
+}
+
+// formatCommit renders one row of the history list.
+func formatCommit(c git.CommitInfo) string {
+	author := c.Author
+	if len([]rune(author)) > 16 {
+		author = string([]rune(author)[:15]) + "…"
+	}
+	return fmt.Sprintf("%s  %s  %-16s  %s", c.ShortHash(), c.Date.Format("2006-01-02"), author, c.Subject())
+}
+
+// viewLog renders the content of the history view.
+func (g *GitPane) viewLog() string {
+	if g.commitShown != nil {
+		return g.viewCommit()
+	}
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Log"))
+	content.WriteString("\n\n")
+
+	if len(g.commits) == 0 && !g.isProcessing && g.errorMessage == "" {
+		content.WriteString("(no commits yet)\n")
+	}
+
+	// Keep the selected commit in the visible window
+	rows := g.listHeight()
+	start := max(g.logIdx-rows+1, 0)
+	for i := start; i < len(g.commits) && i < start+rows; i++ {
+		if i == g.logIdx {
+			content.WriteString(selectedStyle.Render("▶ " + formatCommit(g.commits[i])))
+		} else {
+			content.WriteString("  " + formatCommit(g.commits[i]))
+		}
+		content.WriteString("\n")
+	}
+	if g.logMore {
+		content.WriteString(helpStyle.Render("  … more below"))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(helpStyle.Render("j/k move  Enter show commit  Esc back"))
+	content.WriteString("\n\n")
+	return content.String()
+}
+
+// commitLines returns the lines of the commit view: the header, the
+// message, the changed files and their diffs.
+func (g *GitPane) commitLines() []string {
+	c := g.commitShown
+	if c == nil {
+		return nil
+	}
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	fileStyle := lipgloss.NewStyle().Bold(true)
+
+	lines := []string{
+		headerStyle.Render("commit " + c.Hash),
+		fmt.Sprintf("Author: %s <%s>", c.Author, c.Email),
+		"Date:   " + c.Date.Format("Mon Jan 2 15:04:05 2006 -0700"),
+		"",
+	}
+	for _, l := range strings.Split(strings.TrimRight(c.Message, "\n"), "\n") {
+		lines = append(lines, "    "+l)
+	}
+	lines = append(lines, "")
+
+	for _, f := range g.commitFiles {
+		lines = append(lines, fmt.Sprintf("  %-8s %s", f.Action, f.Path))
+	}
+	for _, f := range g.commitFiles {
+		lines = append(lines, "", fileStyle.Render("─── "+f.Path))
+		if f.Binary {
+			lines = append(lines, "  (binary file)")
+			continue
+		}
+		for _, h := range f.Hunks {
+			lines = append(lines, headerStyle.Render(h.Header()))
+			for _, l := range h.Lines {
+				lines = append(lines, renderDiffLine(l))
+			}
+		}
+	}
+	return lines
+}
+
+// viewCommit renders the shown commit, scrolled to commitScroll.
+func (g *GitPane) viewCommit() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Log — " + g.commitShown.ShortHash()))
+	content.WriteString("\n\n")
+
+	lines := g.commitLines()
+	end := min(g.commitScroll+g.listHeight(), len(lines))
+	for _, line := range lines[min(g.commitScroll, end):end] {
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(helpStyle.Render(fmt.Sprintf("j/k scroll  Space/b page  Esc back to log  (%d/%d)", end, len(lines))))
+	content.WriteString("\n\n")
+	return content.String()
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+func TestGitPane_LogButtonShowsHistory(t *testing.T) {
+	pane, repo := newBranchTestPane(t)
+	commitREADME(t, repo, "# demo\n\nMore docs.\n")
+	pane.focusedInput = 3
+	pane.selectedButton = logButton
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.logMode || len(pane.commits) != 2 || pane.logMore {
+		t.Fatalf("expected 2 commits, got %d (more=%v, error=%s)", len(pane.commits), pane.logMore, pane.errorMessage)
+	}
+	view := pane.View()
+	if !strings.Contains(view, "Git Log") || !strings.Contains(view, "Update README") || !strings.Contains(view, "Initial commit") {
+		t.Errorf("history not rendered:\n%s", view)
+	}
+
+	// Enter shows the selected commit with its diff
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if pane.commitShown == nil || pane.commitShown.Subject() != "Update README" {
+		t.Fatalf("expected the newest commit to be shown, got %+v", pane.commitShown)
+	}
+	view = pane.View()
+	for _, want := range []string{"Author: Test <test@example.com>", "modified README.md", "+More docs."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("commit view missing %q:\n%s", want, view)
+		}
+	}
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.commitShown != nil || !pane.logMode {
+		t.Error("esc should return from the commit to the log")
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.logMode {
+		t.Error("esc should return from the log to the buttons")
+	}
+}
+
+func TestGitPane_LogLoadsNextPage(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.logMode = true
+	page := make([]git.CommitInfo, logPageSize)
+	for i := range page {
+		page[i] = git.CommitInfo{Hash: strings.Repeat("a", 40), Message: "commit"}
+	}
+	pane.Update(GitLogMsg{Commits: page[:2], More: true})
+	pane.Update(GitLogMsg{Commits: page[:1], Skip: 5}) // stale page
+	if len(pane.commits) != 2 {
+		t.Fatalf("expected only the first page, got %d commits", len(pane.commits))
+	}
+
+	// Moving onto the last loaded commit requests the page after it
+	_, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if cmd == nil {
+		t.Fatal("expected the next page to be requested")
+	}
+	msg, ok := cmd().(GitLogMsg)
+	if !ok || msg.Skip != 2 {
+		t.Errorf("expected a page after 2 commits, got %+v", msg)
+	}
+}
+
+func TestFormatCommit(t *testing.T) {
+	c := git.CommitInfo{Hash: "0123456789abcdef", Author: "A Very Long Author Name", Message: "Subject\n\nBody"}
+	got := formatCommit(c)
+	if !strings.HasPrefix(got, "0123456") || !strings.Contains(got, "A Very Long Aut…") || !strings.HasSuffix(got, "Subject") {
+		t.Errorf("unexpected row %q", got)
+	}
+}
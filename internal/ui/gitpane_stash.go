@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/git"
+)
+
+// stashButton is the index of the Stash button, which opens the stash list.
+const stashButton = 12
+
+// GitStashListMsg carries the stash entries loaded for the stash view.
+type GitStashListMsg struct {
+	Entries []git.StashEntry
+	Error   error
+}
+
+// openStash shows the stash view and loads the stash.
+func (g *GitPane) openStash() tea.Cmd {
+	g.stashMode = true
+	g.stashPrompt = false
+	g.stashIdx = 0
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return g.loadStash()
+}
+
+// closeStash returns from the stash view to the buttons.
+func (g *GitPane) closeStash() {
+	g.stashMode = false
+	g.stashPrompt = false
+	g.branchInput.Blur()
+}
+
+// loadStash returns a command that lists the stash entries.
+func (g *GitPane) loadStash() tea.Cmd {
+	client := g.gitClient
+	return func() tea.Msg {
+		if client == nil {
+			return GitStashListMsg{Error: fmt.Errorf("no repository")}
+		}
+		entries, err := client.StashList()
+		return GitStashListMsg{Entries: entries, Error: err}
+	}
+}
+
+// setStash replaces the stash list, keeping the cursor in range.
+func (g *GitPane) setStash(msg GitStashListMsg) {
+	if msg.Error != nil {
+		g.stashes = nil
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	g.stashes = msg.Entries
+	g.stashIdx = min(g.stashIdx, max(len(g.stashes)-1, 0))
+}
+
+// updateStash handles keys in the stash view.
+func (g *GitPane) updateStash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if g.stashPrompt {
+		return g.updateStashPrompt(msg)
+	}
+
+	selected := g.stashIdx < len(g.stashes)
+	index := g.stashIdx
+	switch msg.String() {
+	case "esc":
+		g.closeStash()
+	case "up", "k":
+		if g.stashIdx > 0 {
+			g.stashIdx--
+		}
+	case "down", "j":
+		if g.stashIdx < len(g.stashes)-1 {
+			g.stashIdx++
+		}
+	case "s":
+		// The branch input doubles as the stash message input
+		g.stashPrompt = true
+		g.branchInput.Prompt = "Stash message (optional): "
+		g.branchInput.SetValue("")
+		return g, g.branchInput.Focus()
+	case "enter", "a":
+		if selected {
+			return g, g.runClientOperation("stash", func(c *git.Client) (*git.OperationResult, error) {
+				return c.StashApply(index)
+			})
+		}
+	case "p":
+		if selected {
+			return g, g.runClientOperation("stash", func(c *git.Client) (*git.OperationResult, error) {
+				return c.StashPop(index)
+			})
+		}
+	case "d":
+		if selected {
+			return g, g.runClientOperation("stash", func(c *git.Client) (*git.OperationResult, error) {
+				return c.StashDrop(index)
+			})
+		}
+	case "u":
+		return g, g.runClientOperation("stash", func(c *git.Client) (*git.OperationResult, error) {
+			return c.UndoRestore()
+		})
+	}
+	return g, nil
+}
+
+// updateStashPrompt handles keys while the stash message is asked for.
+func (g *GitPane) updateStashPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		g.stashPrompt = false
+		g.branchInput.Blur()
+		return g, nil
+
+	case "enter":
+		message := strings.TrimSpace(g.branchInput.Value())
+		g.stashPrompt = false
+		g.branchInput.Blur()
+		g.stashIdx = 0
+		return g, g.runClientOperation("stash", func(c *git.Client) (*git.OperationResult, error) {
+			return c.StashSave(message)
+		})
+	}
+
+	var cmd tea.Cmd
+	g.branchInput, cmd = g.branchInput.Update(msg)
+	return g, cmd
+}
+
+// formatStash renders one row of the stash list.
+func formatStash(e git.StashEntry) string {
+	return fmt.Sprintf("%-10s %s  %s", e.Name(), e.When.Format("2006-01-02 15:04"), e.Message)
+}
+
+// viewStash renders the content of the stash view.
+func (g *GitPane) viewStash() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Stash"))
+	content.WriteString("\n\n")
+
+	if len(g.stashes) == 0 && g.errorMessage == "" {
+		content.WriteString("(no stash entries)\n")
+	}
+	for i, entry := range g.stashes {
+		if i == g.stashIdx {
+			content.WriteString(selectedStyle.Render("▶ " + formatStash(entry)))
+		} else {
+			content.WriteString("  " + formatStash(entry))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	if g.stashPrompt {
+		content.WriteString(g.branchInput.View())
+		content.WriteString("\n")
+		content.WriteString(helpStyle.Render("Enter stash  Esc cancel"))
+	} else {
+		content.WriteString(helpStyle.Render("s stash changes  Enter apply  p pop  d drop  u undo last restore  Esc back"))
+	}
+	content.WriteString("\n\n")
+	return content.String()
+}
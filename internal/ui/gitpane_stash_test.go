@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// readme returns the content of README.md in the worktree of pane
+func readme(t *testing.T, pane *GitPane) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(pane.workDir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestGitPane_StashSaveAndPop(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	if err := os.WriteFile(filepath.Join(pane.workDir, "README.md"), []byte("# demo\nwork in progress\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pane.focusedInput = 3
+	pane.selectedButton = stashButton
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.stashMode || !strings.Contains(pane.View(), "(no stash entries)") {
+		t.Fatal("expected an empty stash view")
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if !pane.stashPrompt {
+		t.Fatal("expected s to ask for a message")
+	}
+	for _, r := range "parser work" {
+		pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(pane.stashes) != 1 || pane.stashes[0].Message != "On master: parser work" {
+		t.Fatalf("unexpected stash %+v (%s)", pane.stashes, pane.errorMessage)
+	}
+	if readme(t, pane) != "# demo\n" {
+		t.Error("expected the change to be stashed away")
+	}
+	if view := pane.View(); !strings.Contains(view, "stash@{0}") || !strings.Contains(view, "parser work") {
+		t.Errorf("expected the entry in the view:\n%s", view)
+	}
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if len(pane.stashes) != 0 || readme(t, pane) != "# demo\nwork in progress\n" {
+		t.Errorf("expected the entry to be popped, got %+v (%s)", pane.stashes, pane.errorMessage)
+	}
+}
+
+func TestGitPane_RestoreFileAndUndo(t *testing.T) {
+	pane := newChangesTestPane(t)
+	feed(pane, pane.openChanges()())
+	before := statusCodes(pane)
+	for i, f := range pane.changes {
+		if f.Path == "README.md" {
+			pane.changeIdx = i
+		}
+	}
+	edited := readme(t, pane)
+
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if strings.Contains(statusCodes(pane), "README.md") {
+		t.Fatalf("expected README.md to be restored, got %s (%s)", statusCodes(pane), pane.errorMessage)
+	}
+	if !strings.Contains(pane.statusMessage, "stash@{0}") {
+		t.Errorf("expected the stash to be named, got %q", pane.statusMessage)
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	feed(pane, pane.openStash()())
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if readme(t, pane) != edited || len(pane.stashes) != 0 {
+		t.Errorf("expected the restore to be undone (%s)", pane.errorMessage)
+	}
+	feed(pane, pane.openChanges()())
+	if statusCodes(pane) != before {
+		t.Errorf("expected %s after the undo, got %s", before, statusCodes(pane))
+	}
+}
@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/ai"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/releasenotes"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// tagsButton is the index of the Tags button, which opens the tag list.
+const tagsButton = 13
+
+// tagPrompt identifies what the tag input is asking for
+type tagPrompt int
+
+const (
+	tagPromptNone    tagPrompt = iota
+	tagPromptName              // name of a new tag
+	tagPromptMessage           // annotation of the new tag, empty for a lightweight tag
+)
+
+// GitTagsMsg carries the tags loaded for the tags view.
+type GitTagsMsg struct {
+	Tags  []git.TagInfo
+	Error error
+}
+
+// GitDraftReleaseNotesMsg asks the App to draft release notes for a tag, or
+// for the untagged commits when Tag is empty. The Git panel sends it
+// because only the App has the AI client.
+type GitDraftReleaseNotesMsg struct {
+	Tag string
+}
+
+// GitReleaseNotesMsg reports the drafted release notes file.
+type GitReleaseNotesMsg struct {
+	Path  string // Relative to the repository root
+	Error error
+}
+
+// openTags shows the tags view and loads the tags.
+func (g *GitPane) openTags() tea.Cmd {
+	g.tagsMode = true
+	g.tagPrompt = tagPromptNone
+	g.tagIdx = 0
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return g.loadTags()
+}
+
+// closeTags returns from the tags view to the buttons.
+func (g *GitPane) closeTags() {
+	g.tagsMode = false
+	g.tagPrompt = tagPromptNone
+	g.branchInput.Blur()
+}
+
+// loadTags returns a command that lists the tags of the repository.
+func (g *GitPane) loadTags() tea.Cmd {
+	client := g.gitClient
+	return func() tea.Msg {
+		if client == nil {
+			return GitTagsMsg{Error: fmt.Errorf("no repository")}
+		}
+		tags, err := client.Tags()
+		return GitTagsMsg{Tags: tags, Error: err}
+	}
+}
+
+// setTags replaces the tag list, keeping the cursor in range.
+func (g *GitPane) setTags(msg GitTagsMsg) {
+	if msg.Error != nil {
+		g.tags = nil
+		g.errorMessage = msg.Error.Error()
+		return
+	}
+	g.tags = msg.Tags
+	g.tagIdx = min(g.tagIdx, max(len(g.tags)-1, 0))
+}
+
+// selectedTag returns the name of the tag under the cursor, or "" if there
+// is none.
+func (g *GitPane) selectedTag() string {
+	if g.tagIdx >= len(g.tags) {
+		return ""
+	}
+	return g.tags[g.tagIdx].Name
+}
+
+// requestReleaseNotes asks the App to draft release notes for tag.
+func (g *GitPane) requestReleaseNotes(tag string) tea.Cmd {
+	g.isProcessing = true
+	g.statusMessage = ""
+	g.errorMessage = ""
+	return func() tea.Msg {
+		return GitDraftReleaseNotesMsg{Tag: tag}
+	}
+}
+
+// updateTags handles keys in the tags view.
+func (g *GitPane) updateTags(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if g.tagPrompt != tagPromptNone {
+		return g.updateTagPrompt(msg)
+	}
+
+	name := g.selectedTag()
+	username, password := g.userInput.Value(), g.passInput.Value()
+	switch msg.String() {
+	case "esc":
+		g.closeTags()
+	case "up", "k":
+		if g.tagIdx > 0 {
+			g.tagIdx--
+		}
+	case "down", "j":
+		if g.tagIdx < len(g.tags)-1 {
+			g.tagIdx++
+		}
+	case "n":
+		// The branch input doubles as the tag name and message input
+		g.tagPrompt = tagPromptName
+		g.branchInput.Prompt = "New tag at HEAD: "
+		g.branchInput.SetValue("")
+		return g, g.branchInput.Focus()
+	case "d":
+		if name != "" {
+			return g, g.runClientOperation("tag", func(c *git.Client) (*git.OperationResult, error) {
+				return c.DeleteTag(name)
+			})
+		}
+	case "p":
+		if name != "" {
+			return g, g.runClientOperation("tag", func(c *git.Client) (*git.OperationResult, error) {
+				return c.PushTags(name, username, password)
+			})
+		}
+	case "P":
+		return g, g.runClientOperation("tag", func(c *git.Client) (*git.OperationResult, error) {
+			return c.PushTags("", username, password)
+		})
+	case "r":
+		if name != "" && !g.isProcessing {
+			return g, g.requestReleaseNotes(name)
+		}
+	case "R":
+		if !g.isProcessing {
+			return g, g.requestReleaseNotes("")
+		}
+	}
+	return g, nil
+}
+
+// updateTagPrompt handles keys while a tag name or message is asked for.
+func (g *GitPane) updateTagPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		g.tagPrompt = tagPromptNone
+		g.branchInput.Blur()
+		return g, nil
+
+	case "enter":
+		value := strings.TrimSpace(g.branchInput.Value())
+		if g.tagPrompt == tagPromptName {
+			if value == "" {
+				return g, nil
+			}
+			g.tagName = value
+			g.tagPrompt = tagPromptMessage
+			g.branchInput.Prompt = "Message (empty for a lightweight tag): "
+			g.branchInput.SetValue("")
+			return g, nil
+		}
+
+		name := g.tagName
+		g.tagPrompt = tagPromptNone
+		g.branchInput.Blur()
+		g.tagIdx = 0
+		return g, g.runClientOperation("tag", func(c *git.Client) (*git.OperationResult, error) {
+			return c.CreateTag(name, value)
+		})
+	}
+
+	var cmd tea.Cmd
+	g.branchInput, cmd = g.branchInput.Update(msg)
+	return g, cmd
+}
+
+// formatTag renders one row of the tag list.
+func formatTag(t git.TagInfo) string {
+	row := fmt.Sprintf("%-16s %s  %s", t.Name, t.ShortHash(), t.Date.Format("2006-01-02 15:04"))
+	if t.Annotated {
+		subject, _, _ := strings.Cut(t.Message, "\n")
+		row += "  " + subject
+	}
+	return row
+}
+
+// viewTags renders the content of the tags view.
+func (g *GitPane) viewTags() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Git Tags"))
+	content.WriteString("\n\n")
+
+	if len(g.tags) == 0 && g.errorMessage == "" {
+		content.WriteString("(no tags)\n")
+	}
+	for i, tag := range g.tags {
+		if i == g.tagIdx {
+			content.WriteString(selectedStyle.Render("▶ " + formatTag(tag)))
+		} else {
+			content.WriteString("  " + formatTag(tag))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	switch g.tagPrompt {
+	case tagPromptName:
+		content.WriteString(g.branchInput.View())
+		content.WriteString("\n")
+		content.WriteString(helpStyle.Render("Enter next  Esc cancel"))
+	case tagPromptMessage:
+		content.WriteString(g.branchInput.View())
+		content.WriteString("\n")
+		content.WriteString(helpStyle.Render("Enter create " + g.tagName + "  Esc cancel"))
+	default:
+		content.WriteString(helpStyle.Render("n new tag  d delete  p push  P push all  r release notes  R notes for untagged commits  Esc back"))
+	}
+	content.WriteString("\n\n")
+	return content.String()
+}
+
+// draftReleaseNotes returns a command that collects the commits of a
+// release, has the configured model summarise them and writes the result
+// to release-notes/<tag>.md through the FileManager.
+func (a *App) draftReleaseNotes(tag string) tea.Cmd {
+	client := a.aiPane.aiClient
+	model := a.aiPane.model
+	gitClient := a.gitPane.gitClient
+	workDir := a.gitPane.workDir
+	fm := a.fileManager
+	return func() tea.Msg {
+		if gitClient == nil || fm == nil {
+			return GitReleaseNotesMsg{Error: fmt.Errorf("no repository")}
+		}
+		previous, commits, err := gitClient.ReleaseCommits(tag)
+		if err != nil {
+			return GitReleaseNotesMsg{Error: err}
+		}
+		notes, err := releasenotes.Generate(context.Background(), client, model, tag, previous, commits, ai.DefaultPromptTokenBudget)
+		if err != nil {
+			return GitReleaseNotesMsg{Error: err}
+		}
+		path := releasenotes.FileName(tag)
+		if err := fm.WriteFile(filepath.Join(workDir, path), notes); err != nil {
+			return GitReleaseNotesMsg{Error: err}
+		}
+		return GitReleaseNotesMsg{Path: path}
+	}
+}
+
+// handleReleaseNotes opens drafted release notes in the editor for review,
+// or shows why they could not be drafted in the Git panel.
+func (a *App) handleReleaseNotes(msg GitReleaseNotesMsg) tea.Cmd {
+	a.gitPane.isProcessing = false
+	if msg.Error != nil {
+		a.gitPane.errorMessage = "Could not draft release notes: " + msg.Error.Error()
+		return nil
+	}
+
+	if err := a.editorPane.LoadFile(filepath.Join(a.gitPane.workDir, msg.Path)); err != nil {
+		a.gitPane.errorMessage = "Error opening file: " + err.Error()
+		return nil
+	}
+	if a.gitPane.IsVisible() {
+		a.gitPane.Toggle()
+	}
+	a.activePane = types.EditorPaneType
+	a.editorPane.focused = true
+	a.aiPane.focused = false
+	a.statusMessage = "Drafted " + msg.Path + "; review and edit it before publishing"
+	return nil
+}
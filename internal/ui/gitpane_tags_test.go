@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/releasenotes"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// typeText sends text to the pane one key at a time
+func typeText(pane *GitPane, text string) {
+	for _, r := range text {
+		pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestGitPane_CreateAndDeleteTags(t *testing.T) {
+	pane, repo := newBranchTestPane(t)
+	pane.focusedInput = 3
+	pane.selectedButton = tagsButton
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.tagsMode || !strings.Contains(pane.View(), "(no tags)") {
+		t.Fatal("expected an empty tags view")
+	}
+
+	// An empty message makes a lightweight tag
+	pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	typeText(pane, "v0.1.0")
+	pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pane.tagPrompt != tagPromptMessage {
+		t.Fatal("expected the message to be asked for")
+	}
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(pane.tags) != 1 || pane.tags[0].Annotated {
+		t.Fatalf("expected a lightweight tag, got %+v (%s)", pane.tags, pane.errorMessage)
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	typeText(pane, "v0.2.0")
+	pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typeText(pane, "First beta")
+	feed(pane, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(pane.tags) != 2 {
+		t.Fatalf("expected two tags, got %+v (%s)", pane.tags, pane.errorMessage)
+	}
+	if view := pane.View(); !strings.Contains(view, "v0.2.0") || !strings.Contains(view, "First beta") {
+		t.Errorf("expected the annotated tag in the view:\n%s", view)
+	}
+
+	pane.tagIdx = 0
+	name := pane.selectedTag()
+	feed(pane, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if len(pane.tags) != 1 || pane.tags[0].Name == name {
+		t.Errorf("expected %s to be deleted, got %+v", name, pane.tags)
+	}
+	if _, err := repo.Tag(name); err == nil {
+		t.Errorf("expected %s to be gone from the repository", name)
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.tagsMode {
+		t.Error("expected Esc to close the view")
+	}
+}
+
+func TestGitPane_TagPromptCancel(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	feed(pane, pane.openTags()())
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pane.tagPrompt != tagPromptName {
+		t.Error("expected an empty name to be ignored")
+	}
+	pane.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if pane.tagPrompt != tagPromptNone || !pane.tagsMode {
+		t.Error("expected Esc to cancel the prompt but keep the view")
+	}
+}
+
+func TestGitPane_ReleaseNotesKeys(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.gitClient.CreateTag("v1", "")
+	feed(pane, pane.openTags()())
+
+	_, cmd := pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatal("expected r to request release notes")
+	}
+	if msg, ok := cmd().(GitDraftReleaseNotesMsg); !ok || msg.Tag != "v1" {
+		t.Errorf("expected a request for v1, got %#v", msg)
+	}
+	if !pane.isProcessing {
+		t.Error("expected the pane to be busy while drafting")
+	}
+
+	pane.isProcessing = false
+	_, cmd = pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if msg, ok := cmd().(GitDraftReleaseNotesMsg); !ok || msg.Tag != "" {
+		t.Errorf("expected a request for the untagged commits, got %#v", msg)
+	}
+}
+
+// newReleaseTestApp returns an App with an editor over the repository of
+// pane and a model that replies with reply
+func newReleaseTestApp(t *testing.T, pane *GitPane, reply string) (*App, *replyAIClient) {
+	t.Helper()
+	root := pane.workDir
+	fm := filemanager.NewFileManager(root)
+	editor := NewEditorPane(fm)
+	editor.SetSize(100, 20)
+	client := &replyAIClient{reply: reply}
+	app := &App{
+		editorPane:  editor,
+		aiPane:      NewAIChatPane(client, "test-model", "ollama", root),
+		gitPane:     pane,
+		fileManager: fm,
+		config:      &types.AppConfig{WorkspaceDir: root},
+		ready:       true,
+	}
+	return app, client
+}
+
+// draft runs the release notes request for tag through app
+func draft(app *App, tag string) {
+	_, cmd := app.Update(GitDraftReleaseNotesMsg{Tag: tag})
+	app.Update(cmd())
+}
+
+func TestApp_DraftReleaseNotes(t *testing.T) {
+	pane, repo := newBranchTestPane(t)
+	pane.gitClient.CreateTag("v1", "")
+	worktree, _ := repo.Worktree()
+	if err := os.WriteFile(filepath.Join(pane.workDir, "README.md"), []byte("# demo\n\nUsage\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree.Add("README.md")
+	pane.gitClient.Commit("docs: add usage section")
+	pane.gitClient.CreateTag("v2", "")
+
+	app, client := newReleaseTestApp(t, pane, "```markdown\n# v2\n\n## Documentation\n- Usage section\n```")
+	draft(app, "v2")
+
+	if pane.errorMessage != "" {
+		t.Fatalf("unexpected error %q", pane.errorMessage)
+	}
+	if !strings.Contains(client.prompt, "docs: add usage section") || !strings.Contains(client.prompt, "Previous release: v1") {
+		t.Errorf("expected the v2 commits in the prompt:\n%s", client.prompt)
+	}
+	if strings.Contains(client.prompt, "Initial commit") {
+		t.Error("commits of v1 should not be in the prompt")
+	}
+
+	path := filepath.Join(pane.workDir, "release-notes", "v2.md")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected the notes to be written: %v", err)
+	}
+	want := "# v2\n\n## Documentation\n- Usage section\n"
+	if string(content) != want {
+		t.Errorf("expected %q, got %q", want, content)
+	}
+	if app.activePane != types.EditorPaneType || app.editorPane.GetContent() != want {
+		t.Error("expected the notes to be opened in the focused editor")
+	}
+	if pane.isProcessing {
+		t.Error("expected the pane to be idle again")
+	}
+}
+
+func TestApp_DraftReleaseNotesWithoutCommits(t *testing.T) {
+	pane, _ := newBranchTestPane(t)
+	pane.gitClient.CreateTag("v1", "")
+	app, client := newReleaseTestApp(t, pane, "# Unreleased\n")
+
+	draft(app, "")
+	if !strings.Contains(pane.errorMessage, releasenotes.ErrNoCommits.Error()) {
+		t.Errorf("expected a no commits error, got %q", pane.errorMessage)
+	}
+	if client.prompt != "" {
+		t.Error("the model should not be asked without commits")
+	}
+	if _, err := os.Stat(filepath.Join(pane.workDir, releasenotes.FileName(""))); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no file to be written, got %v", err)
+	}
+}
@@ -265,6 +265,8 @@ func TestUpdateHandlesRepositoryDetectedMsg(t *testing.T) {
 // For any directory, when the Git UI opens, the system should check for a .git subdirectory
 // and populate credentials if found, or leave fields empty if not found.
 func TestProperty3_RepositoryDetectionOnOpen(t *testing.T) {
+	// Keep the credential store key out of the real config directory
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("opening UI in non-repo directory leaves fields empty", prop.ForAll(
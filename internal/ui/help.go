@@ -33,9 +33,12 @@ func (a *App) renderHelpDialog() string {
 	leftColumn += sectionStyle.Render("── File ──────────────────────────────────────") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+W") + descStyle.Render("    Change Workspace / Open Folder") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+O") + descStyle.Render("    Open file") + "\n"
+	leftColumn += keyStyle.Render("  Ctrl+P") + descStyle.Render("    Go to file (fuzzy, outside the AI pane)") + "\n"
+	leftColumn += keyStyle.Render("  Alt+M") + descStyle.Render("     Go to symbol (Tab switches)") + "\n"
+	leftColumn += keyStyle.Render("  Ctrl+E") + descStyle.Render("    File tree (show / focus / hide)") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+N") + descStyle.Render("    New file") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+S") + descStyle.Render("    Save file") + "\n"
-	leftColumn += keyStyle.Render("  Ctrl+X") + descStyle.Render("    Close file") + "\n"
+	leftColumn += keyStyle.Render("  Ctrl+X") + descStyle.Render("    Cut selection / close file (tab)") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+R") + descStyle.Render("    Run current script") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+K") + descStyle.Render("    Kill running process (in terminal mode)") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+B") + descStyle.Render("    Backup Picker (Restore previous versions)") + "\n"
@@ -45,19 +48,21 @@ func (a *App) renderHelpDialog() string {
 	// AI section
 	leftColumn += sectionStyle.Render("── AI ────────────────────────────────────────") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+Y") + descStyle.Render("    List code blocks (Execute/Insert/Return)") + "\n"
-	leftColumn += keyStyle.Render("  Ctrl+P") + descStyle.Render("    Paste response / Insert code into editor") + "\n"
+	leftColumn += keyStyle.Render("  Ctrl+P") + descStyle.Render("    Paste response / Insert code into editor (AI pane)") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+L") + descStyle.Render("    Load saved chat from .ti/ folder") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+T") + descStyle.Render("    Clear chat / New chat") + "\n"
+	leftColumn += keyStyle.Render("  Esc") + descStyle.Render("       Stop generating (also Ctrl+K)") + "\n"
 	leftColumn += "\n"
 
 	// Navigation section
 	leftColumn += sectionStyle.Render("── Navigation ────────────────────────────────") + "\n"
-	leftColumn += keyStyle.Render("  Tab") + descStyle.Render("       Cycle: Editor → AI Input → AI Response") + "\n"
+	leftColumn += keyStyle.Render("  Tab") + descStyle.Render("       Cycle: Editor → AI Input → AI Response → Tree") + "\n"
 	leftColumn += keyStyle.Render("  ↑↓") + descStyle.Render("        Scroll line by line") + "\n"
 	leftColumn += keyStyle.Render("  PgUp/PgDn") + descStyle.Render(" Scroll page") + "\n"
 	leftColumn += keyStyle.Render("  Home/End") + descStyle.Render("  Jump to top/bottom") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+F") + descStyle.Render("    Find text in current file") + "\n"
-	leftColumn += keyStyle.Render("  Alt+F") + descStyle.Render("     Find and Replace") + "\n"
+	leftColumn += keyStyle.Render("  Alt+F") + descStyle.Render("     Find and Replace (file or project)") + "\n"
+	leftColumn += keyStyle.Render("  Alt+R/C/W") + descStyle.Render(" Regex / case / whole word (find)") + "\n"
 	leftColumn += keyStyle.Render("  F3") + descStyle.Render("        Find next occurrence") + "\n"
 	leftColumn += keyStyle.Render("  Ctrl+H") + descStyle.Render("    Help") + "\n"
 	leftColumn += keyStyle.Render("  Esc") + descStyle.Render("       Back / Exit search mode") + "\n"
@@ -71,6 +76,8 @@ func (a *App) renderHelpDialog() string {
 	leftColumn += keyStyle.Render("  /preview") + descStyle.Render("           Preview changes without applying") + "\n"
 	leftColumn += keyStyle.Render("  /project <request>") + descStyle.Render(" Project-wide change across all files") + "\n"
 	leftColumn += keyStyle.Render("  /proceed") + descStyle.Render("           Apply changes from last preview") + "\n"
+	leftColumn += keyStyle.Render("  /review") + descStyle.Render("            Review last preview hunk by hunk") + "\n"
+	leftColumn += keyStyle.Render("  /commit") + descStyle.Render("            Suggest a commit message") + "\n"
 	leftColumn += "\n"
 	leftColumn += sectionStyle.Render("── Other Commands ────────────────────────────") + "\n"
 	leftColumn += keyStyle.Render("  /rescan") + descStyle.Render("            Rescan project files for fresh context") + "\n"
@@ -98,6 +105,24 @@ func (a *App) renderHelpDialog() string {
 	rightColumn += keyStyle.Render("  Alt+R") + descStyle.Render("         Redo last undone change") + "\n"
 	rightColumn += "\n"
 
+	// Buffers
+	rightColumn += sectionStyle.Render("── Tabs ──────────────────────────────────────") + "\n"
+	rightColumn += keyStyle.Render("  Alt+. / Alt+,") + descStyle.Render(" Next / previous tab") + "\n"
+	rightColumn += keyStyle.Render("  Alt+1-9") + descStyle.Render("       Go to tab N") + "\n"
+	rightColumn += "\n"
+
+	// Selection
+	rightColumn += sectionStyle.Render("── Selection ─────────────────────────────────") + "\n"
+	rightColumn += keyStyle.Render("  Shift+Arrows") + descStyle.Render("  Select text (or drag the mouse)") + "\n"
+	rightColumn += keyStyle.Render("  Ctrl+A") + descStyle.Render("        Select all") + "\n"
+	rightColumn += keyStyle.Render("  Ctrl+C") + descStyle.Render("        Copy selection (or line)") + "\n"
+	rightColumn += keyStyle.Render("  Ctrl+V") + descStyle.Render("        Paste") + "\n"
+	rightColumn += keyStyle.Render("  Tab / S+Tab") + descStyle.Render("   Indent / outdent selection") + "\n"
+	rightColumn += keyStyle.Render("  Alt+/") + descStyle.Render("         Toggle line comment") + "\n"
+	rightColumn += keyStyle.Render("  Alt+S") + descStyle.Render("         Send selection to AI") + "\n"
+	rightColumn += keyStyle.Render("  F9") + descStyle.Render("            Release / capture the mouse") + "\n"
+	rightColumn += "\n"
+
 	// Navigation
 	rightColumn += sectionStyle.Render("── Navigation ────────────────────────────────") + "\n"
 	rightColumn += keyStyle.Render("  Alt+G") + descStyle.Render("         Go to end of file") + "\n"
@@ -113,12 +138,39 @@ func (a *App) renderHelpDialog() string {
 	rightColumn += keyStyle.Render("  Delete") + descStyle.Render("        Delete char at cursor") + "\n"
 	rightColumn += "\n"
 
+	// Language server
+	rightColumn += sectionStyle.Render("── Language Server ───────────────────────────") + "\n"
+	rightColumn += keyStyle.Render("  Ctrl+Space") + descStyle.Render("    Complete (↑↓ pick, Enter/Tab accept)") + "\n"
+	rightColumn += keyStyle.Render("  Alt+K") + descStyle.Render("         Hover info and problems on the line") + "\n"
+	rightColumn += keyStyle.Render("  F12") + descStyle.Render("           Go to definition") + "\n"
+	rightColumn += keyStyle.Render("  F2") + descStyle.Render("            Rename symbol") + "\n"
+	rightColumn += "\n"
+
 	// Git Operations
 	rightColumn += sectionStyle.Render("── Git Operations ────────────────────────────") + "\n"
 	rightColumn += keyStyle.Render("  Ctrl+G") + descStyle.Render("        Open Git Panel") + "\n"
 	rightColumn += keyStyle.Render("  ↑↓") + descStyle.Render("            Navigate options") + "\n"
 	rightColumn += keyStyle.Render("  Enter") + descStyle.Render("         Select operation") + "\n"
 	rightColumn += keyStyle.Render("  Esc") + descStyle.Render("           Close Git Panel") + "\n"
+	rightColumn += keyStyle.Render("  Alt+B") + descStyle.Render("         Toggle blame in the editor") + "\n"
+	rightColumn += keyStyle.Render("  Alt+S") + descStyle.Render("         Suggest a commit message") + "\n"
+	rightColumn += "\n"
+
+	// File tree
+	rightColumn += sectionStyle.Render("── File Tree ─────────────────────────────────") + "\n"
+	rightColumn += keyStyle.Render("  Enter / ←→") + descStyle.Render("    Open file, expand / collapse folder") + "\n"
+	rightColumn += keyStyle.Render("  n / N") + descStyle.Render("         New file / new folder") + "\n"
+	rightColumn += keyStyle.Render("  r / m") + descStyle.Render("         Rename / move") + "\n"
+	rightColumn += keyStyle.Render("  d") + descStyle.Render("             Delete (asks first)") + "\n"
+	rightColumn += keyStyle.Render("  R") + descStyle.Render("             Refresh") + "\n"
+	rightColumn += "\n"
+
+	// Merge conflicts
+	rightColumn += sectionStyle.Render("── Merge Conflicts ───────────────────────────") + "\n"
+	rightColumn += keyStyle.Render("  Alt+O") + descStyle.Render("         Take our side of the conflict") + "\n"
+	rightColumn += keyStyle.Render("  Alt+T") + descStyle.Render("         Take their side of the conflict") + "\n"
+	rightColumn += keyStyle.Render("  Alt+A") + descStyle.Render("         Take both sides") + "\n"
+	rightColumn += keyStyle.Render("  Alt+C") + descStyle.Render("         Jump to the next conflict") + "\n"
 	rightColumn += "\n"
 
 	//Documentation section
@@ -201,6 +253,24 @@ func (a *App) renderEditorHelpDialog() string {
 	helpText += keyStyle.Render("  Alt+R") + descStyle.Render("         Redo last undone change") + "\n"
 	helpText += "\n"
 
+	// Buffers
+	helpText += sectionStyle.Render("── Tabs ──────────────────────────────────────") + "\n"
+	helpText += keyStyle.Render("  Alt+. / Alt+,") + descStyle.Render(" Next / previous tab") + "\n"
+	helpText += keyStyle.Render("  Alt+1-9") + descStyle.Render("       Go to tab N") + "\n"
+	helpText += "\n"
+
+	// Selection
+	helpText += sectionStyle.Render("── Selection ─────────────────────────────────") + "\n"
+	helpText += keyStyle.Render("  Shift+Arrows") + descStyle.Render("  Select text (or drag the mouse)") + "\n"
+	helpText += keyStyle.Render("  Ctrl+A") + descStyle.Render("        Select all") + "\n"
+	helpText += keyStyle.Render("  Ctrl+C") + descStyle.Render("        Copy selection (or line)") + "\n"
+	helpText += keyStyle.Render("  Ctrl+V") + descStyle.Render("        Paste") + "\n"
+	helpText += keyStyle.Render("  Tab / S+Tab") + descStyle.Render("   Indent / outdent selection") + "\n"
+	helpText += keyStyle.Render("  Alt+/") + descStyle.Render("         Toggle line comment") + "\n"
+	helpText += keyStyle.Render("  Alt+S") + descStyle.Render("         Send selection to AI") + "\n"
+	helpText += keyStyle.Render("  F9") + descStyle.Render("            Release / capture the mouse") + "\n"
+	helpText += "\n"
+
 	// Navigation
 	helpText += sectionStyle.Render("── Navigation ────────────────────────────────") + "\n"
 	helpText += keyStyle.Render("  Alt+G") + descStyle.Render("         Go to end of file") + "\n"
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/syntax"
+)
+
+// maxHighlightSize is the largest content, in bytes, that is highlighted;
+// bigger files are shown as plain text to keep rendering fast.
+const maxHighlightSize = 1 << 20
+
+// editorHighlight caches the highlighting of the editor content, which only
+// changes when the content or its language does.
+type editorHighlight struct {
+	content string
+	lang    *syntax.Language
+	kinds   [][]syntax.Kind
+}
+
+// SetTheme sets the syntax highlighting theme by name. Unknown names fall
+// back to the default theme and "none" turns highlighting off.
+func (e *EditorPane) SetTheme(name string) {
+	e.theme = syntax.LoadTheme(name)
+}
+
+// language returns the language of the active buffer, from its file name or
+// the name suggested for an unsaved buffer, or nil if it has none.
+func (e *EditorPane) language() *syntax.Language {
+	if e.currentFile != nil {
+		return syntax.ForFile(e.currentFile.Filepath)
+	}
+	if e.suggestedName != "" {
+		return syntax.ForFile(e.suggestedName)
+	}
+	return nil
+}
+
+// lineKinds returns the kind of each rune of each content line, or nil when
+// the content is not highlighted.
+func (e *EditorPane) lineKinds() [][]syntax.Kind {
+	lang := e.language()
+	if lang == nil || e.theme.Name == syntax.NoTheme || len(e.content) > maxHighlightSize {
+		return nil
+	}
+	if e.highlight.lang != lang || e.highlight.content != e.content {
+		e.highlight = editorHighlight{
+			content: e.content,
+			lang:    lang,
+			kinds:   syntax.Highlight(lang, e.content),
+		}
+	}
+	return e.highlight.kinds
+}
+
+// expandKinds returns kinds for line with its tabs expanded to four spaces,
+// as the editor draws them.
+func expandKinds(line string, kinds []syntax.Kind) []syntax.Kind {
+	if !strings.ContainsRune(line, '\t') {
+		return kinds
+	}
+	expanded := make([]syntax.Kind, 0, len(kinds)+8)
+	for i, r := range []rune(line) {
+		kind := syntax.Text
+		if i < len(kinds) {
+			kind = kinds[i]
+		}
+		n := 1
+		if r == '\t' {
+			n = 4
+		}
+		for ; n > 0; n-- {
+			expanded = append(expanded, kind)
+		}
+	}
+	return expanded
+}
+
+// renderLine renders a chunk of a line in the theme, padded with pad. The
+// cursor is drawn at cursor unless it is negative; a cursor past the chunk
+// is drawn on the first padding space, or after the chunk. The runes from
+// selFrom up to selTo are drawn selected.
+func (e *EditorPane) renderLine(chunk []rune, kinds []syntax.Kind, pad string, cursor, selFrom, selTo int) string {
+	selected := func(i int) bool { return i >= selFrom && i < selTo }
+	var sb strings.Builder
+	for start := 0; start < len(chunk); {
+		kind := kindAt(kinds, start)
+		end := start + 1
+		for end < len(chunk) && end != cursor && start != cursor &&
+			kindAt(kinds, end) == kind && selected(end) == selected(start) {
+			end++
+		}
+		switch {
+		case start == cursor:
+			sb.WriteString(e.theme.Style(kind).Reverse(true).Render(string(chunk[start:end])))
+		case selected(start):
+			sb.WriteString(e.theme.Style(kind).Background(lipgloss.Color(selectionColor)).Render(string(chunk[start:end])))
+		default:
+			sb.WriteString(e.theme.Render(chunk[start:end], kinds[min(start, len(kinds)):min(end, len(kinds))]))
+		}
+		start = end
+	}
+	if cursor >= len(chunk) {
+		if pad != "" {
+			pad = pad[1:]
+		}
+		sb.WriteString(lipgloss.NewStyle().Reverse(true).Render(" "))
+	}
+	return sb.String() + pad
+}
+
+// kindAt returns kinds[i], or syntax.Text past the end of kinds.
+func kindAt(kinds []syntax.Kind, i int) syntax.Kind {
+	if i < len(kinds) {
+		return kinds[i]
+	}
+	return syntax.Text
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/syntax"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+func TestEditorPane_LineKindsFollowLanguage(t *testing.T) {
+	editor := newBufferEditor(t, "main.go", "package main\n", "notes.txt", "package main\n")
+
+	editor.LoadFile("main.go")
+	kinds := editor.lineKinds()
+	if len(kinds) != 2 || kinds[0][0] != syntax.Keyword {
+		t.Fatalf("expected Go highlighting, got %v", kinds)
+	}
+
+	editor.LoadFile("notes.txt")
+	if editor.lineKinds() != nil {
+		t.Error("expected no highlighting for a file without a language")
+	}
+
+	editor.SetContentUnsaved("x = 1", "script.py")
+	if editor.language() == nil || editor.language().Name != "python" {
+		t.Errorf("expected the suggested name to pick the language, got %v", editor.language())
+	}
+}
+
+func TestEditorPane_LineKindsCachedUntilEdit(t *testing.T) {
+	editor := newBufferEditor(t, "run.sh", "echo hi\n")
+	editor.LoadFile("run.sh")
+
+	first := editor.lineKinds()
+	if &editor.lineKinds()[0] != &first[0] {
+		t.Error("expected unchanged content to reuse the cached kinds")
+	}
+
+	typeInto(editor, "# ")
+	kinds := editor.lineKinds()
+	if kinds[0][0] != syntax.Comment {
+		t.Errorf("expected the edited line to be re-lexed as a comment, got %v", kinds[0])
+	}
+}
+
+func TestEditorPane_NoHighlighting(t *testing.T) {
+	editor := newBufferEditor(t, "main.go", "package main\n", "big.go", "// "+strings.Repeat("x", maxHighlightSize)+"\n")
+
+	editor.LoadFile("main.go")
+	editor.SetTheme(syntax.NoTheme)
+	if editor.lineKinds() != nil {
+		t.Error("expected the none theme to turn highlighting off")
+	}
+
+	editor.SetTheme("dracula")
+	editor.LoadFile("big.go")
+	if editor.lineKinds() != nil {
+		t.Error("expected files over the size limit to be shown plain")
+	}
+}
+
+func TestExpandKinds(t *testing.T) {
+	kinds := []syntax.Kind{syntax.Text, syntax.Keyword, syntax.String}
+	got := expandKinds("\ti\"", kinds)
+	want := []syntax.Kind{syntax.Text, syntax.Text, syntax.Text, syntax.Text, syntax.Keyword, syntax.String}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	if expandKinds("no tabs", kinds) == nil {
+		t.Error("expected lines without tabs to keep their kinds")
+	}
+}
+
+func TestEditorPane_RenderHighlightedCursor(t *testing.T) {
+	editor := newBufferEditor(t)
+	chunk := []rune("if x")
+	kinds := []syntax.Kind{syntax.Keyword, syntax.Keyword, syntax.Text, syntax.Text}
+	keyword := editor.theme.Style(syntax.Keyword)
+	cursorStyle := keyword.Reverse(true)
+
+	tests := []struct {
+		name   string
+		pad    string
+		cursor int
+		want   string
+	}{
+		{"no cursor", "  ", -1, keyword.Render("if") + " x  "},
+		{"in a keyword", "", 1, keyword.Render("i") + cursorStyle.Render("f") + " x"},
+		{"on the padding", "  ", 4, keyword.Render("if") + " x" + lipgloss.NewStyle().Reverse(true).Render(" ") + " "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editor.renderLine(chunk, kinds, tt.pad, tt.cursor, 0, 0); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorPane_HighlightedViewKeepsText(t *testing.T) {
+	content := "package main\n\nfunc main() {\n\tprintln(\"hi\") // greet\n}\n"
+	editor := newBufferEditor(t, "main.go", content, "main.txt", content)
+
+	editor.LoadFile("main.go")
+	highlighted := editor.View()
+	editor.LoadFile("main.txt")
+	plain := editor.View()
+
+	// Without a color terminal the styles add nothing, so the views match
+	if highlighted != plain {
+		t.Errorf("highlighting changed the text:\n%s\nvs\n%s", highlighted, plain)
+	}
+}
+
+func TestAIChatPane_HighlightsFencedCode(t *testing.T) {
+	pane := NewAIChatPane(nil, "", "", t.TempDir())
+	pane.SetSize(60, 30)
+	msg := types.ChatMessage{
+		Role:      "assistant",
+		Content:   "Try this:\n```go\nx := `raw\n" + strings.Repeat("a", 80) + "`\n```\n`x` is fine",
+		Timestamp: time.Now(),
+	}
+
+	highlighted := pane.renderMessage(msg)
+	pane.SetTheme(syntax.NoTheme)
+	plain := pane.renderMessage(msg)
+
+	if len(highlighted) != len(plain) {
+		t.Fatalf("expected the same lines, got %d and %d", len(highlighted), len(plain))
+	}
+	for i := range plain {
+		if highlighted[i] != plain[i] && !strings.Contains(highlighted[i], plain[i]) {
+			t.Errorf("line %d: %q does not show %q", i, highlighted[i], plain[i])
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/lsp"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// lspRequestTimeout is how long a hover, definition, completion or rename
+// request may take
+const lspRequestTimeout = 10 * time.Second
+
+// maxFixDiagnostics is the number of diagnostics passed to /fix
+const maxFixDiagnostics = 50
+
+// LSPEventMsg carries diagnostics, or a language server that started or
+// failed, from the language server manager.
+type LSPEventMsg struct {
+	Event lsp.Event
+}
+
+// LSPHoverMsg carries the hover text for a position in a file.
+type LSPHoverMsg struct {
+	Path string
+	Line int
+	Text string
+	Err  error
+}
+
+// LSPDefinitionMsg carries where the symbol at the cursor is defined.
+type LSPDefinitionMsg struct {
+	Locations []lsp.Location
+	Err       error
+}
+
+// LSPCompletionMsg carries the completions for a position in a file.
+type LSPCompletionMsg struct {
+	Path  string
+	Line  int
+	Col   int
+	Items []lsp.CompletionItem
+	Err   error
+}
+
+// LSPRenameMsg carries the edits that rename the symbol at the cursor.
+type LSPRenameMsg struct {
+	NewName string
+	Edit    *lsp.WorkspaceEdit
+	Err     error
+}
+
+// lspInbox holds the language server events the UI has not handled yet.
+// Servers never wait for a busy UI: newer diagnostics of a file replace the
+// pending ones in place, so only the latest diagnostics of each file are
+// delivered.
+type lspInbox struct {
+	mu     sync.Mutex
+	events []lsp.Event
+	wake   chan struct{}
+}
+
+func newLSPInbox() *lspInbox {
+	return &lspInbox{wake: make(chan struct{}, 1)}
+}
+
+// push adds e without blocking.
+func (b *lspInbox) push(e lsp.Event) {
+	b.mu.Lock()
+	merged := false
+	if e.Server == "" {
+		for i, pending := range b.events {
+			if pending.Server == "" && pending.Path == e.Path {
+				b.events[i] = e
+				merged = true
+				break
+			}
+		}
+	}
+	if !merged {
+		b.events = append(b.events, e)
+	}
+	b.mu.Unlock()
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for the oldest pending event and removes it.
+func (b *lspInbox) next() lsp.Event {
+	for {
+		b.mu.Lock()
+		if len(b.events) > 0 {
+			e := b.events[0]
+			b.events = b.events[1:]
+			b.mu.Unlock()
+			return e
+		}
+		b.mu.Unlock()
+		<-b.wake
+	}
+}
+
+// waitForLSP returns a command that delivers the next language server
+// event. Update re-issues it after handling each event.
+func waitForLSP(inbox *lspInbox) tea.Cmd {
+	if inbox == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return LSPEventMsg{Event: inbox.next()}
+	}
+}
+
+// syncLSP sends the open file to its language server, starting the
+// manager for the workspace the first time and restarting it when the
+// workspace changes. Documents of closed buffers are closed on their
+// servers.
+func (a *App) syncLSP() {
+	if a.config == nil || a.lspEvents == nil {
+		return
+	}
+	root, err := filepath.Abs(a.config.WorkspaceDir)
+	if err != nil {
+		return
+	}
+	if a.lsp != nil && a.lsp.Root() != root {
+		a.stopLSP()
+	}
+
+	path := a.editorPane.currentFilePath()
+	if a.lsp == nil {
+		if path == "" || len(lsp.Commands(path)) == 0 {
+			return
+		}
+		a.lsp = lsp.NewManager(root, a.lspEvents.push)
+	}
+	if path != "" {
+		a.lsp.Sync(path, a.editorPane.GetContent())
+	}
+
+	// Buffers are only closed or opened in between syncs
+	if open := a.editorPane.OpenFiles(); strings.Join(open, "\n") != strings.Join(a.lspOpenFiles, "\n") {
+		a.lspOpenFiles = open
+		a.lsp.Retain(open)
+	}
+}
+
+// stopLSP shuts down the language servers in the background.
+func (a *App) stopLSP() {
+	if a.lsp == nil {
+		return
+	}
+	m := a.lsp
+	a.lsp = nil
+	a.lspOpenFiles = nil
+	a.editorPane.lspDiagnostics = nil
+	go shutdownLSP(m)
+}
+
+// shutdownLSP stops the servers of m, giving them a moment to exit.
+func shutdownLSP(m *lsp.Manager) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	m.Shutdown(ctx)
+}
+
+// Close stops the language servers started for the workspace. It is
+// called when the program exits.
+func (a *App) Close() {
+	if a.lsp != nil {
+		shutdownLSP(a.lsp)
+		a.lsp = nil
+	}
+}
+
+// lspDiagnosticsReport returns the errors and warnings of the language
+// servers for /fix, or "" when there are none.
+func (a *App) lspDiagnosticsReport() string {
+	if a.lsp == nil {
+		return ""
+	}
+	return a.lsp.DiagnosticsReport(maxFixDiagnostics)
+}
+
+// lspTarget returns the manager and path for a request about the open
+// file, or sets the status bar to why there is none.
+func (a *App) lspTarget() (*lsp.Manager, string, bool) {
+	path := a.editorPane.currentFilePath()
+	if path == "" {
+		a.statusMessage = "No file open"
+		return nil, "", false
+	}
+	if a.lsp == nil || !a.lsp.Handles(path) {
+		a.statusMessage = noServerMessage(path)
+		return nil, "", false
+	}
+	return a.lsp, path, true
+}
+
+// noServerMessage explains that no language server handles the file at
+// path.
+func noServerMessage(path string) string {
+	name := filepath.Base(path)
+	if commands := lsp.Commands(path); len(commands) > 0 {
+		return fmt.Sprintf("No language server for %s: install %s", name, strings.Join(commands, " or "))
+	}
+	return "No language server for " + name
+}
+
+// lspRequestError returns the status bar message for a failed request.
+func lspRequestError(what string, err error) string {
+	if errors.Is(err, lsp.ErrNoServer) {
+		return "The language server is not running"
+	}
+	return fmt.Sprintf("%s failed: %v", what, err)
+}
+
+// showHover asks the language server about the symbol at the cursor
+// (Alt+K).
+func (a *App) showHover() tea.Cmd {
+	m, path, ok := a.lspTarget()
+	if !ok {
+		return nil
+	}
+	line, col := a.editorPane.cursorLine, a.editorPane.cursorCol
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), lspRequestTimeout)
+		defer cancel()
+		text, err := m.Hover(ctx, path, line, col)
+		return LSPHoverMsg{Path: path, Line: line, Text: text, Err: err}
+	}
+}
+
+// goToDefinition asks the language server where the symbol at the cursor
+// is defined (F12).
+func (a *App) goToDefinition() tea.Cmd {
+	m, path, ok := a.lspTarget()
+	if !ok {
+		return nil
+	}
+	line, col := a.editorPane.cursorLine, a.editorPane.cursorCol
+	a.statusMessage = "Looking up the definition..."
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), lspRequestTimeout)
+		defer cancel()
+		locations, err := m.Definition(ctx, path, line, col)
+		return LSPDefinitionMsg{Locations: locations, Err: err}
+	}
+}
+
+// complete asks the language server for completions at the cursor
+// (Ctrl+Space).
+func (a *App) complete() tea.Cmd {
+	m, path, ok := a.lspTarget()
+	if !ok {
+		return nil
+	}
+	line, col := a.editorPane.cursorLine, a.editorPane.cursorCol
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), lspRequestTimeout)
+		defer cancel()
+		items, err := m.Completion(ctx, path, line, col)
+		return LSPCompletionMsg{Path: path, Line: line, Col: col, Items: items, Err: err}
+	}
+}
+
+// openRenamePrompt asks for the new name of the symbol at the cursor (F2).
+func (a *App) openRenamePrompt() {
+	if _, _, ok := a.lspTarget(); !ok {
+		return
+	}
+	lines := strings.Split(a.editorPane.GetContent(), "\n")
+	line := lines[min(a.editorPane.cursorLine, len(lines)-1)]
+	col := min(a.editorPane.cursorCol, len(line))
+	a.renameBuffer = line[wordStart(line, col):wordEnd(line, col)]
+	a.showRenamePrompt = true
+	a.statusMessage = "Enter the new name (Esc to cancel)"
+}
+
+// handleRenamePromptKey edits the new name and starts the rename on Enter.
+func (a *App) handleRenamePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		a.showRenamePrompt = false
+		newName := strings.TrimSpace(a.renameBuffer)
+		a.renameBuffer = ""
+		if newName == "" {
+			a.statusMessage = "Rename cancelled"
+			return nil
+		}
+		m, path, ok := a.lspTarget()
+		if !ok {
+			return nil
+		}
+		line, col := a.editorPane.cursorLine, a.editorPane.cursorCol
+		a.statusMessage = "Renaming to " + newName + "..."
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), lspRequestTimeout)
+			defer cancel()
+			edit, err := m.Rename(ctx, path, line, col, newName)
+			return LSPRenameMsg{NewName: newName, Edit: edit, Err: err}
+		}
+	case "esc":
+		a.showRenamePrompt = false
+		a.renameBuffer = ""
+		a.statusMessage = "Rename cancelled"
+	case "backspace":
+		if len(a.renameBuffer) > 0 {
+			_, size := utf8.DecodeLastRuneInString(a.renameBuffer)
+			a.renameBuffer = a.renameBuffer[:len(a.renameBuffer)-size]
+		}
+	default:
+		if msg.Type == tea.KeyRunes {
+			a.renameBuffer += string(msg.Runes)
+		}
+	}
+	return nil
+}
+
+// renamePromptView renders the rename prompt dialog.
+func (a *App) renamePromptView() string {
+	promptStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(60).
+		Align(lipgloss.Center)
+
+	promptText := "Rename symbol to:\n\n"
+	promptText += a.renameBuffer + "█\n\n[Enter] to rename, [Esc] to cancel"
+
+	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, promptStyle.Render(promptText))
+}
+
+// handleLSPMsg handles the language server events and request results.
+func (a *App) handleLSPMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case LSPEventMsg:
+		e := msg.Event
+		switch {
+		case e.Server != "" && e.Err != nil:
+			a.statusMessage = fmt.Sprintf("Language server %s failed: %v", e.Server, e.Err)
+		case e.Server != "":
+			a.statusMessage = "Language server " + e.Server + " started"
+		default:
+			a.editorPane.SetLSPDiagnostics(e.Path, e.Diagnostics)
+		}
+		return waitForLSP(a.lspEvents)
+
+	case LSPHoverMsg:
+		if msg.Err != nil {
+			a.statusMessage = lspRequestError("Hover", msg.Err)
+			return nil
+		}
+		if msg.Path != a.editorPane.currentFilePath() || msg.Line != a.editorPane.cursorLine {
+			return nil
+		}
+		// Problems on the line come first, in full
+		var parts []string
+		for _, d := range a.editorPane.diagnosticsOnLine(msg.Line) {
+			parts = append(parts, fmt.Sprintf("%s: %s", d.Severity, strings.TrimSpace(d.Message)))
+		}
+		if msg.Text != "" {
+			parts = append(parts, msg.Text)
+		}
+		if len(parts) == 0 {
+			a.statusMessage = "Nothing known about the text at the cursor"
+			return nil
+		}
+		a.editorPane.ShowHover(strings.Join(parts, "\n\n"))
+		return nil
+
+	case LSPDefinitionMsg:
+		if msg.Err != nil {
+			a.statusMessage = lspRequestError("Go to definition", msg.Err)
+			return nil
+		}
+		if len(msg.Locations) == 0 {
+			a.statusMessage = "No definition found"
+			return nil
+		}
+		loc := msg.Locations[0]
+		path := lsp.URIToPath(loc.URI)
+		if err := a.editorPane.LoadFile(path); err != nil {
+			a.statusMessage = "Error opening file: " + err.Error()
+			return nil
+		}
+		lines := strings.Split(a.editorPane.GetContent(), "\n")
+		line := min(loc.Range.Start.Line, len(lines)-1)
+		a.editorPane.SetCursorPosition(line, lsp.ByteColumn(lines[line], loc.Range.Start.Character))
+		a.activePane = types.EditorPaneType
+		a.editorPane.focused = true
+		a.aiPane.focused = false
+		a.statusMessage = fmt.Sprintf("Definition: %s:%d", a.relativePath(path), line+1)
+		if len(msg.Locations) > 1 {
+			a.statusMessage += fmt.Sprintf(" (1 of %d)", len(msg.Locations))
+		}
+		return nil
+
+	case LSPCompletionMsg:
+		if msg.Err != nil {
+			a.statusMessage = lspRequestError("Completion", msg.Err)
+			return nil
+		}
+		if msg.Path != a.editorPane.currentFilePath() {
+			return nil
+		}
+		if !a.editorPane.OpenCompletion(msg.Line, msg.Col, msg.Items) {
+			a.statusMessage = "No completions"
+		}
+		return nil
+
+	case LSPRenameMsg:
+		if msg.Err != nil {
+			a.statusMessage = lspRequestError("Rename", msg.Err)
+			return nil
+		}
+		return a.applyWorkspaceEdit(msg.NewName, msg.Edit)
+	}
+	return nil
+}
+
+// applyWorkspaceEdit applies the edits of a rename. Files open in a buffer
+// are edited there, as the server computed the edits from the buffer, and
+// left unsaved; other files are written, with a backup.
+func (a *App) applyWorkspaceEdit(newName string, edit *lsp.WorkspaceEdit) tea.Cmd {
+	files := edit.Files()
+	if len(files) == 0 {
+		a.statusMessage = "Nothing to rename at the cursor"
+		return nil
+	}
+	var written, failed []string
+	buffers := 0
+	for _, f := range files {
+		apply := func(text string) (string, error) { return lsp.ApplyEdits(text, f.Edits) }
+		open, err := a.editorPane.EditBuffer(f.Path, apply)
+		if !open {
+			var content string
+			if content, err = a.fileManager.ReadFile(f.Path); err == nil {
+				if content, err = apply(content); err == nil {
+					err = a.fileManager.WriteFile(f.Path, content)
+				}
+			}
+		}
+		switch {
+		case err != nil:
+			failed = append(failed, fmt.Sprintf("%s: %v", a.relativePath(f.Path), err))
+		case open:
+			buffers++
+		default:
+			written = append(written, f.Path)
+		}
+	}
+
+	a.statusMessage = fmt.Sprintf("Renamed to %s in %d file(s)", newName, buffers+len(written))
+	if buffers > 0 {
+		a.statusMessage += fmt.Sprintf("; %d open buffer(s) changed, save to keep", buffers)
+	}
+	if len(failed) > 0 {
+		a.statusMessage += "; not changed: " + strings.Join(failed, ", ")
+	}
+	if len(written) == 0 {
+		return nil
+	}
+	if a.validator != nil {
+		a.validator.ValidateChanges(written)
+	}
+	return a.fileTree.Refresh()
+}
+
+// relativePath returns path relative to the workspace when it is inside it.
+func (a *App) relativePath(path string) string {
+	if a.config != nil {
+		if root, err := filepath.Abs(a.config.WorkspaceDir); err == nil {
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return path
+}
@@ -0,0 +1,325 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/lsp"
+)
+
+// newLSPApp returns newFindApp's App with a file manager for writing the
+// files a rename touches, and the editor focused.
+func newLSPApp(t *testing.T) (*App, string) {
+	t.Helper()
+	app, dir := newFindApp(t)
+	app.fileManager = filemanager.NewFileManager(dir)
+	app.editorPane.focused = true
+	return app, dir
+}
+
+// diagnostic returns a diagnostic at the start of line.
+func diagnostic(line int, severity lsp.Severity, message string) lsp.Diagnostic {
+	return lsp.Diagnostic{
+		Range:    lsp.Range{Start: lsp.Position{Line: line}, End: lsp.Position{Line: line, Character: 3}},
+		Severity: severity,
+		Message:  message,
+	}
+}
+
+func TestEditor_LSPDiagnosticsShownInline(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	editor := NewEditorPane(filemanager.NewFileManager(dir))
+	editor.SetSize(80, 10)
+	path := filepath.Join(dir, "main.go")
+	if err := editor.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	editor.SetLSPDiagnostics(path, []lsp.Diagnostic{
+		diagnostic(2, lsp.SeverityWarning, "main is empty"),
+		diagnostic(2, lsp.SeverityError, "undefined: x\nmore detail"),
+	})
+	view := ansi.Strip(editor.View())
+	if !strings.Contains(view, "undefined: x") || strings.Contains(view, "more detail") {
+		t.Errorf("expected the first line of the error inline:\n%s", view)
+	}
+	if strings.Contains(view, "main is empty") {
+		t.Errorf("expected only the most serious diagnostic of the line:\n%s", view)
+	}
+	if !strings.Contains(view, "●") {
+		t.Errorf("expected a gutter mark:\n%s", view)
+	}
+	if got := editor.diagnosticsOnLine(2); len(got) != 2 || got[0].Severity != lsp.SeverityError {
+		t.Errorf("expected both diagnostics of the line, error first, got %+v", got)
+	}
+
+	// Diagnostics of another file are not shown
+	editor.SetLSPDiagnostics(path, nil)
+	editor.SetLSPDiagnostics(filepath.Join(dir, "other.go"), []lsp.Diagnostic{diagnostic(0, lsp.SeverityError, "elsewhere")})
+	if view := ansi.Strip(editor.View()); strings.Contains(view, "elsewhere") || strings.Contains(view, "undefined") {
+		t.Errorf("expected no diagnostics for main.go:\n%s", view)
+	}
+}
+
+func TestEditor_Completion(t *testing.T) {
+	app, _ := newLSPApp(t)
+	editor := app.editorPane
+	editor.SetCursorPosition(0, len("foo := foo"))
+
+	items := []lsp.CompletionItem{
+		{Label: "food", Detail: "func()"},
+		{Label: "foo", Detail: "int", SortText: "0"},
+		{Label: "fmt"},
+	}
+	app.Update(LSPCompletionMsg{Path: editor.currentFilePath(), Line: 0, Col: editor.cursorCol, Items: items})
+	if !editor.CompletionVisible() {
+		t.Fatal("expected the completion popup")
+	}
+	if view := ansi.Strip(editor.View()); !strings.Contains(view, "foo") || !strings.Contains(view, "food") || strings.Contains(view, "fmt") {
+		t.Errorf("expected the completions starting with foo:\n%s", view)
+	}
+
+	// Typing narrows the list
+	typeApp(app, "d")
+	if c := editor.completion; c == nil || len(c.shown) != 1 || c.items[c.shown[0]].Label != "food" {
+		t.Fatalf("expected only food after typing d, got %+v", c)
+	}
+	// The selected completion stays selected as the list widens
+	app.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if c := editor.completion; c == nil || len(c.shown) != 2 || c.items[c.shown[c.selected]].Label != "food" {
+		t.Fatalf("expected food still selected, got %+v", c)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if c := editor.completion; c.items[c.shown[c.selected]].Label != "foo" {
+		t.Fatalf("expected the selection to wrap to foo, got %+v", c)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyUp})
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if editor.CompletionVisible() || editor.GetContent() != "foo := food(1)\n" {
+		t.Errorf("expected food inserted, got %q", editor.GetContent())
+	}
+	if editor.cursorCol != len("foo := food") {
+		t.Errorf("expected the cursor after the completion, got column %d", editor.cursorCol)
+	}
+	app.Update(altKey('u'))
+	if editor.GetContent() != "foo := foo(1)\n" {
+		t.Errorf("expected one undo to remove the completion, got %q", editor.GetContent())
+	}
+
+	// Esc and leaving the word close the popup
+	app.Update(LSPCompletionMsg{Path: editor.currentFilePath(), Line: 0, Col: editor.cursorCol, Items: items})
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if editor.CompletionVisible() {
+		t.Error("expected Esc to close the popup")
+	}
+	app.Update(LSPCompletionMsg{Path: editor.currentFilePath(), Line: 0, Col: editor.cursorCol, Items: items})
+	typeApp(app, " ")
+	if editor.CompletionVisible() {
+		t.Error("expected the popup to close after the word")
+	}
+
+	// Nothing matching the word
+	app.Update(LSPCompletionMsg{Path: editor.currentFilePath(), Line: 0, Col: editor.cursorCol, Items: []lsp.CompletionItem{}})
+	if editor.CompletionVisible() || app.statusMessage != "No completions" {
+		t.Errorf("expected no popup, got status %q", app.statusMessage)
+	}
+}
+
+func TestApp_LSPDefinitionOpensFile(t *testing.T) {
+	app, dir := newLSPApp(t)
+	writeTreeFiles(t, dir, "c.go", "package c\n\n// ü\nvar ünïcode, target = 1, 2\n")
+	target := filepath.Join(dir, "c.go")
+
+	app.Update(LSPDefinitionMsg{Locations: []lsp.Location{
+		{URI: lsp.PathToURI(target), Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 13}}},
+		{URI: lsp.PathToURI(filepath.Join(dir, "a.go"))},
+	}})
+	if app.editorPane.currentFilePath() != target {
+		t.Fatalf("expected c.go open, got %s", app.editorPane.currentFilePath())
+	}
+	// The UTF-16 column is converted to the byte column of target
+	if app.editorPane.cursorLine != 3 || app.editorPane.cursorCol != len("var ünïcode, ") {
+		t.Errorf("expected the cursor on target, got %d:%d", app.editorPane.cursorLine, app.editorPane.cursorCol)
+	}
+	if app.statusMessage != "Definition: c.go:4 (1 of 2)" {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+
+	app.Update(LSPDefinitionMsg{})
+	if app.statusMessage != "No definition found" {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+	app.Update(LSPDefinitionMsg{Err: lsp.ErrNoServer})
+	if app.statusMessage != "The language server is not running" {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+}
+
+func TestApp_LSPRename(t *testing.T) {
+	app, dir := newLSPApp(t)
+	open := filepath.Join(dir, "a.go")
+	closed := filepath.Join(dir, "b", "b.txt")
+	edit := func(line, start, end int) lsp.TextEdit {
+		return lsp.TextEdit{
+			Range:   lsp.Range{Start: lsp.Position{Line: line, Character: start}, End: lsp.Position{Line: line, Character: end}},
+			NewText: "bar",
+		}
+	}
+
+	app.Update(LSPRenameMsg{NewName: "bar", Edit: &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{
+		lsp.PathToURI(open):   {edit(0, 0, 3), edit(0, 7, 10)},
+		lsp.PathToURI(closed): {edit(2, 0, 3)},
+	}}})
+
+	if got := app.editorPane.GetContent(); got != "bar := bar(1)\n" {
+		t.Errorf("expected the open buffer renamed, got %q", got)
+	}
+	if !app.editorPane.currentFile.IsModified {
+		t.Error("expected the open buffer left unsaved")
+	}
+	if got := readFile(t, dir, "a.go"); got != "foo := foo(1)\n" {
+		t.Errorf("expected a.go unchanged on disk, got %q", got)
+	}
+	if got := readFile(t, dir, "b/b.txt"); got != "Foo\nfood\nbar\n" {
+		t.Errorf("expected b/b.txt written, got %q", got)
+	}
+	if want := "Renamed to bar in 2 file(s); 1 open buffer(s) changed, save to keep"; app.statusMessage != want {
+		t.Errorf("expected %q, got %q", want, app.statusMessage)
+	}
+	app.Update(altKey('u'))
+	if got := app.editorPane.GetContent(); got != "foo := foo(1)\n" {
+		t.Errorf("expected one undo to revert the rename, got %q", got)
+	}
+
+	// Overlapping edits leave the file alone
+	app.Update(LSPRenameMsg{NewName: "baz", Edit: &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{
+		lsp.PathToURI(closed): {edit(0, 0, 3), edit(0, 1, 2)},
+	}}})
+	if !strings.Contains(app.statusMessage, "not changed: b/b.txt") {
+		t.Errorf("expected the failure in the status, got %q", app.statusMessage)
+	}
+	if got := readFile(t, dir, "b/b.txt"); got != "Foo\nfood\nbar\n" {
+		t.Errorf("expected b/b.txt unchanged, got %q", got)
+	}
+}
+
+func TestApp_LSPHoverAndEvents(t *testing.T) {
+	app, _ := newLSPApp(t)
+	path := app.editorPane.currentFilePath()
+
+	app.Update(LSPEventMsg{Event: lsp.Event{Path: path, Diagnostics: []lsp.Diagnostic{
+		diagnostic(0, lsp.SeverityError, "foo redeclared"),
+	}}})
+	if len(app.editorPane.diagnosticsOnLine(0)) != 1 {
+		t.Fatal("expected the event to set the diagnostics")
+	}
+
+	app.Update(LSPHoverMsg{Path: path, Line: 0, Text: "```go\nvar foo int\n```"})
+	view := ansi.Strip(app.editorPane.View())
+	if !strings.Contains(view, "error: foo redeclared") || !strings.Contains(view, "var foo int") || strings.Contains(view, "```") {
+		t.Errorf("expected the diagnostic and the hover text in the popup:\n%s", view)
+	}
+	// The next key closes it, and Esc goes no further
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.editorPane.hover != nil {
+		t.Error("expected Esc to close the hover")
+	}
+
+	// A hover for a line the cursor left is dropped
+	app.Update(LSPHoverMsg{Path: path, Line: 1, Text: "stale"})
+	if app.editorPane.hover != nil {
+		t.Error("expected the stale hover to be dropped")
+	}
+
+	app.Update(LSPEventMsg{Event: lsp.Event{Server: "gopls"}})
+	if app.statusMessage != "Language server gopls started" {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+	app.Update(LSPEventMsg{Event: lsp.Event{Path: path}})
+	if len(app.editorPane.diagnosticsOnLine(0)) != 0 {
+		t.Error("expected an empty event to clear the diagnostics")
+	}
+}
+
+func TestLSPInbox_KeepsLatestDiagnosticsPerFile(t *testing.T) {
+	inbox := newLSPInbox()
+	for i := 0; i < 200; i++ {
+		inbox.push(lsp.Event{Path: "a.go", Diagnostics: []lsp.Diagnostic{diagnostic(i, lsp.SeverityError, "old")}})
+	}
+	inbox.push(lsp.Event{Server: "gopls"})
+	inbox.push(lsp.Event{Path: "b.go", Diagnostics: []lsp.Diagnostic{diagnostic(0, lsp.SeverityWarning, "b")}})
+	inbox.push(lsp.Event{Path: "a.go"})
+
+	msg := waitForLSP(inbox)().(LSPEventMsg)
+	if msg.Event.Path != "a.go" || len(msg.Event.Diagnostics) != 0 {
+		t.Errorf("expected the latest, cleared diagnostics of a.go first, got %+v", msg.Event)
+	}
+	if e := inbox.next(); e.Server != "gopls" {
+		t.Errorf("expected the server event next, got %+v", e)
+	}
+	if e := inbox.next(); e.Path != "b.go" || len(e.Diagnostics) != 1 {
+		t.Errorf("expected the diagnostics of b.go last, got %+v", e)
+	}
+
+	// next waits for a later event
+	got := make(chan lsp.Event)
+	go func() { got <- inbox.next() }()
+	inbox.push(lsp.Event{Path: "c.go"})
+	select {
+	case e := <-got:
+		if e.Path != "c.go" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected next to return the pushed event")
+	}
+}
+
+func TestApp_LSPKeysWithoutServer(t *testing.T) {
+	app, _ := newLSPApp(t)
+
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyF2}, {Type: tea.KeyF12}, {Type: tea.KeyCtrlAt}, altKey('k')} {
+		app.statusMessage = ""
+		app.Update(key)
+		if app.statusMessage != "No language server for a.go: install gopls" {
+			t.Errorf("%s: unexpected status %q", key, app.statusMessage)
+		}
+		if app.showRenamePrompt {
+			t.Errorf("%s: expected no rename prompt", key)
+		}
+	}
+}
+
+func TestApp_RenamePrompt(t *testing.T) {
+	app, _ := newLSPApp(t)
+	app.showRenamePrompt = true
+	app.renameBuffer = "foo"
+
+	app.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	typeApp(app, "x")
+	if !strings.Contains(app.View(), "fox█") {
+		t.Errorf("expected the edited name in the prompt:\n%s", app.View())
+	}
+	if app.editorPane.GetContent() != "foo := foo(1)\n" {
+		t.Errorf("expected the prompt to take the keys, got %q", app.editorPane.GetContent())
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.showRenamePrompt || app.statusMessage != "Rename cancelled" {
+		t.Errorf("expected the prompt closed, got status %q", app.statusMessage)
+	}
+
+	// Without a server Enter closes the prompt and says why
+	app.showRenamePrompt = true
+	app.renameBuffer = "bar"
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.showRenamePrompt || app.statusMessage != "No language server for a.go: install gopls" {
+		t.Errorf("expected the prompt closed, got status %q", app.statusMessage)
+	}
+}
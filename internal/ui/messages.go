@@ -40,6 +40,23 @@ type OpenWorkspacePickerMsg struct{}
 // AutonomousTickMsg signals the App to invoke the Step() method on the active AutonomousCreator.
 type AutonomousTickMsg struct{}
 
+// AutonomousStepMsg carries the outcome of one AutonomousCreator.Step() run in
+// the background.
+type AutonomousStepMsg struct {
+	Status     string
+	Err        error
+	State      agentic.CreatorState // the creator's state after the step
+	FileToOpen string               // file the step asked to open in the editor, if any
+
+	creator *agentic.AutonomousCreator // the creator that ran the step
+}
+
+// AgentProgressMsg carries a progress line an agent logged while running in
+// a command. Agents never touch the chat pane from their goroutine.
+type AgentProgressMsg struct {
+	Content string
+}
+
 // FixSessionCompleteMsg is sent when the AgenticProjectFixer finishes a /fix session.
 type FixSessionCompleteMsg struct {
 	Result *agentic.FixSessionResult
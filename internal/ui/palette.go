@@ -0,0 +1,356 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/terminal-intelligence/internal/search"
+)
+
+// paletteMode identifies what the palette searches
+type paletteMode int
+
+const (
+	paletteFiles   paletteMode = iota // go to file
+	paletteSymbols                    // go to symbol
+)
+
+// paletteLimit is the number of matches kept for display.
+const paletteLimit = 200
+
+// PaletteIndexedMsg is sent when an update of the palette's workspace index
+// finished.
+type PaletteIndexedMsg struct {
+	Index *search.Index      // Index that was updated
+	Stats search.UpdateStats // What the update did
+	Err   error              // Why the workspace could not be scanned
+}
+
+// Palette is a popup that fuzzy-finds a file or a symbol of the workspace
+// and opens it. Files are ranked by how well their path matches and how
+// recently they were shown; symbols come from the Go, Python and
+// JavaScript declarations of the workspace. The index behind it is
+// updated in the background each time the palette opens, re-reading only
+// the files that changed, and the results refresh when the update ends.
+type Palette struct {
+	visible bool
+	mode    paletteMode
+	input   textinput.Model
+	width   int // Terminal width
+	height  int // Terminal height
+
+	index    *search.Index
+	indexing bool     // Whether an index update is running
+	indexed  bool     // Whether the index was filled at least once
+	message  string   // Error of the last index update
+	recent   []string // Recently shown files, relative to the workspace
+
+	files    []search.FileMatch
+	symbols  []search.SymbolMatch
+	selected int // Index of the selected match
+	offset   int // First match on screen
+}
+
+// NewPalette creates a hidden palette for the workspace at root.
+func NewPalette(root string) *Palette {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 200
+	return &Palette{index: search.NewIndex(root), input: input}
+}
+
+// SetWorkspace switches the palette to the workspace at root. The new
+// workspace is indexed when the palette next opens.
+func (p *Palette) SetWorkspace(root string) {
+	p.index = search.NewIndex(root)
+	p.indexing = false
+	p.indexed = false
+	p.Close()
+}
+
+// SetSize sets the terminal size the palette is centered in.
+func (p *Palette) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.input.Width = max(p.boxWidth()-8, 1)
+}
+
+// IsVisible returns whether the palette is shown.
+func (p *Palette) IsVisible() bool {
+	return p.visible
+}
+
+// Open shows the palette in the given mode with an empty query. recent
+// lists the absolute paths of recently shown files, most recent first. It
+// returns a command that updates the index.
+func (p *Palette) Open(mode paletteMode, recent []string) tea.Cmd {
+	root := p.index.Root()
+	p.recent = p.recent[:0]
+	for _, path := range recent {
+		if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+			p.recent = append(p.recent, filepath.ToSlash(rel))
+		}
+	}
+	p.visible = true
+	p.mode = mode
+	p.message = ""
+	p.input.SetValue("")
+	p.input.Focus()
+	p.filter()
+	return p.refresh()
+}
+
+// Close hides the palette.
+func (p *Palette) Close() {
+	p.visible = false
+	p.input.Blur()
+}
+
+// refresh returns a command that updates the index in the background, or
+// nil while an update is already running.
+func (p *Palette) refresh() tea.Cmd {
+	if p.indexing {
+		return nil
+	}
+	p.indexing = true
+	index := p.index
+	return func() tea.Msg {
+		stats, err := index.Update()
+		return PaletteIndexedMsg{Index: index, Stats: stats, Err: err}
+	}
+}
+
+// SetIndexed records a finished index update and refreshes the matches.
+// Updates of a previous workspace are ignored.
+func (p *Palette) SetIndexed(msg PaletteIndexedMsg) {
+	if msg.Index != p.index {
+		return
+	}
+	p.indexing = false
+	p.indexed = true
+	p.message = ""
+	if msg.Err != nil {
+		p.message = "Indexing failed: " + msg.Err.Error()
+	}
+	if p.visible {
+		p.filter()
+	}
+}
+
+// filter recomputes the matches of the query and selects the best one.
+func (p *Palette) filter() {
+	query := p.input.Value()
+	if p.mode == paletteFiles {
+		p.files = p.index.MatchFiles(query, p.recent, paletteLimit)
+	} else {
+		p.symbols = p.index.MatchSymbols(query, paletteLimit)
+	}
+	p.selected = 0
+	p.offset = 0
+}
+
+// count returns the number of matches of the current mode.
+func (p *Palette) count() int {
+	if p.mode == paletteFiles {
+		return len(p.files)
+	}
+	return len(p.symbols)
+}
+
+// Update handles a key while the palette is open. Enter returns a command
+// that opens the selected match in the editor.
+func (p *Palette) Update(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		p.Close()
+		return nil
+	case "enter":
+		return p.choose()
+	case "tab":
+		if p.mode == paletteFiles {
+			p.mode = paletteSymbols
+		} else {
+			p.mode = paletteFiles
+		}
+		p.filter()
+		return nil
+	case "up":
+		p.move(-1)
+		return nil
+	case "down":
+		p.move(1)
+		return nil
+	case "pgup":
+		p.move(-p.listHeight())
+		return nil
+	case "pgdown":
+		p.move(p.listHeight())
+		return nil
+	}
+
+	before := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.filter()
+	}
+	return cmd
+}
+
+// move moves the selection by delta matches, staying in range.
+func (p *Palette) move(delta int) {
+	p.selected = min(max(p.selected+delta, 0), max(p.count()-1, 0))
+}
+
+// choose closes the palette and returns a command that opens the selected
+// match, or nil if there is none.
+func (p *Palette) choose() tea.Cmd {
+	if p.selected >= p.count() {
+		return nil
+	}
+	p.Close()
+	root := p.index.Root()
+	if p.mode == paletteFiles {
+		path := filepath.Join(root, filepath.FromSlash(p.files[p.selected].Path))
+		return func() tea.Msg { return OpenFileInEditorMsg{FilePath: path} }
+	}
+	sym := p.symbols[p.selected]
+	path := filepath.Join(root, filepath.FromSlash(sym.Path))
+	return func() tea.Msg { return OpenFileInEditorMsg{FilePath: path, Line: sym.Line} }
+}
+
+// boxWidth returns the width of the palette box, including the border.
+func (p *Palette) boxWidth() int {
+	return min(max(p.width-10, 40), 100)
+}
+
+// listHeight returns the number of matches shown at once.
+func (p *Palette) listHeight() int {
+	return min(max(p.height-12, 3), 15)
+}
+
+// View renders the palette box. The caller centers it on the screen.
+func (p *Palette) View() string {
+	contentWidth := p.boxWidth() - 6
+	listHeight := p.listHeight()
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+listHeight {
+		p.offset = p.selected - listHeight + 1
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	title := "Go to file"
+	if p.mode == paletteSymbols {
+		title = "Go to symbol"
+	}
+	status := fmt.Sprintf("%d matches", p.count())
+	if p.count() == 1 {
+		status = "1 match"
+	}
+	if p.indexing {
+		status += " · indexing…"
+	}
+	lines := []string{
+		titleStyle.Render(title) + "  " + dimStyle.Render(status),
+		p.input.View(),
+		"",
+	}
+
+	for i := p.offset; i < p.count() && i < p.offset+listHeight; i++ {
+		lines = append(lines, p.renderMatch(i, contentWidth))
+	}
+	switch {
+	case p.message != "":
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(p.message))
+	case p.count() == 0 && p.indexing && !p.indexed:
+		lines = append(lines, dimStyle.Render("Indexing workspace…"))
+	case p.count() == 0:
+		lines = append(lines, dimStyle.Render("No matches"))
+	}
+	for len(lines) < listHeight+3 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, "", lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Render("[↑↓] Navigate | [Enter] Open | [Tab] Files/Symbols | [Esc] Cancel"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 2).
+		Width(p.boxWidth() - 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderMatch renders match i on one line of at most width columns, with
+// the matched runes highlighted.
+func (p *Palette) renderMatch(i, width int) string {
+	base := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	if i == p.selected {
+		base = base.Foreground(lipgloss.Color("15")).Background(lipgloss.Color("62"))
+		dim = dim.Foreground(lipgloss.Color("250")).Background(lipgloss.Color("62"))
+	}
+	hit := base.Foreground(lipgloss.Color("214")).Bold(true)
+
+	var line string
+	if p.mode == paletteFiles {
+		m := p.files[i]
+		line = base.Render(" ") + highlightRunes(m.Path, m.Positions, base, hit)
+	} else {
+		m := p.symbols[i]
+		line = dim.Render(fmt.Sprintf(" %-9s ", m.Kind)) +
+			highlightRunes(m.FullName(), m.Positions, base, hit) +
+			dim.Render(fmt.Sprintf("  %s:%d", m.Path, m.Line))
+	}
+	line = truncateToWidth(line, width)
+	if pad := width - lipgloss.Width(line); pad > 0 {
+		line += base.Render(strings.Repeat(" ", pad))
+	}
+	return line
+}
+
+// highlightRunes renders text with style, and the runes at positions with
+// hit.
+func highlightRunes(text string, positions []int, style, hit lipgloss.Style) string {
+	marked := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		marked[pos] = true
+	}
+	var b strings.Builder
+	var run []rune
+	runHit := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runHit {
+			b.WriteString(hit.Render(string(run)))
+		} else {
+			b.WriteString(style.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if marked[i] != runHit {
+			flush()
+			runHit = marked[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
+
+// openPalette opens the palette in the given mode with the editor's
+// recently shown files.
+func (a *App) openPalette(mode paletteMode) tea.Cmd {
+	return a.palette.Open(mode, a.editorPane.RecentFiles())
+}
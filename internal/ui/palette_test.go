@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// paletteWorkspace holds the files of the palette tests.
+var paletteWorkspace = []string{
+	"README.md", "# demo\n",
+	"internal/config/config.go", "package config\n\n// Load reads the config\nfunc Load() error { return nil }\n",
+	"internal/server/server.go", "package server\n\ntype Server struct{}\n\nfunc (s *Server) Start() error { return nil }\n",
+	"web/app.js", "export function renderPage() {\n}\n",
+}
+
+// newTestPalette indexes a workspace with the palette test files and opens
+// the palette on it.
+func newTestPalette(t *testing.T, mode paletteMode, recent ...string) (*Palette, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, paletteWorkspace...)
+	p := NewPalette(dir)
+	p.SetSize(120, 40)
+	for i, path := range recent {
+		recent[i] = filepath.Join(dir, path)
+	}
+	indexPalette(t, p, p.Open(mode, recent))
+	return p, dir
+}
+
+// indexPalette runs the index update command of the palette.
+func indexPalette(t *testing.T, p *Palette, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected an index update command")
+	}
+	msg, ok := cmd().(PaletteIndexedMsg)
+	if !ok {
+		t.Fatalf("expected PaletteIndexedMsg, got %T", msg)
+	}
+	p.SetIndexed(msg)
+}
+
+// paletteQuery returns the key message of typing s.
+func paletteQuery(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// chosen presses Enter in the palette and returns the message of the
+// command it returns.
+func chosen(t *testing.T, p *Palette) OpenFileInEditorMsg {
+	t.Helper()
+	cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command opening the match")
+	}
+	msg, ok := cmd().(OpenFileInEditorMsg)
+	if !ok {
+		t.Fatalf("expected OpenFileInEditorMsg, got %T", msg)
+	}
+	return msg
+}
+
+func TestPalette_GoToFile(t *testing.T) {
+	p, dir := newTestPalette(t, paletteFiles, "web/app.js")
+	if len(p.files) != 4 || p.files[0].Path != "web/app.js" {
+		t.Fatalf("expected the recent file first, got %+v", p.files)
+	}
+
+	p.Update(paletteQuery("srvgo"))
+	if len(p.files) == 0 || p.files[0].Path != "internal/server/server.go" {
+		t.Fatalf("expected server.go to match first, got %+v", p.files)
+	}
+	view := p.View()
+	if !strings.Contains(view, "Go to file") || !strings.Contains(view, "server") {
+		t.Errorf("view missing the title or the match:\n%s", view)
+	}
+
+	msg := chosen(t, p)
+	if msg.FilePath != filepath.Join(dir, "internal", "server", "server.go") || msg.Line != 0 {
+		t.Errorf("unexpected open message %+v", msg)
+	}
+	if p.IsVisible() {
+		t.Error("choosing a match should close the palette")
+	}
+}
+
+func TestPalette_GoToSymbol(t *testing.T) {
+	p, dir := newTestPalette(t, paletteFiles)
+	p.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if p.mode != paletteSymbols || len(p.symbols) != 4 {
+		t.Fatalf("expected tab to list the 4 symbols, got %+v", p.symbols)
+	}
+
+	p.Update(paletteQuery("start"))
+	if len(p.symbols) != 1 || p.symbols[0].FullName() != "Server.Start" {
+		t.Fatalf("expected Server.Start, got %+v", p.symbols)
+	}
+	if !strings.Contains(p.View(), "internal/server/server.go:5") {
+		t.Errorf("expected the symbol location in the view:\n%s", p.View())
+	}
+
+	msg := chosen(t, p)
+	if msg.FilePath != filepath.Join(dir, "internal", "server", "server.go") || msg.Line != 5 {
+		t.Errorf("unexpected open message %+v", msg)
+	}
+}
+
+func TestPalette_NavigationAndCancel(t *testing.T) {
+	p, _ := newTestPalette(t, paletteFiles)
+	p.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if p.selected != 0 {
+		t.Errorf("selection should stay at the top, got %d", p.selected)
+	}
+	for i := 0; i < 10; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if p.selected != len(p.files)-1 {
+		t.Errorf("selection should stop at the last match, got %d", p.selected)
+	}
+
+	p.Update(paletteQuery("zzz"))
+	if len(p.files) != 0 || !strings.Contains(p.View(), "No matches") {
+		t.Error("expected no matches")
+	}
+	if cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !p.IsVisible() {
+		t.Error("enter without a match should do nothing")
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.IsVisible() {
+		t.Error("esc should close the palette")
+	}
+}
+
+func TestPalette_IndexesIncrementally(t *testing.T) {
+	p, dir := newTestPalette(t, paletteSymbols)
+	p.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "extra.py"), []byte("def helper():\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := p.Open(paletteSymbols, nil)
+	if len(p.symbols) != 4 {
+		t.Errorf("the previous index should answer while updating, got %d symbols", len(p.symbols))
+	}
+	if p.refresh() != nil {
+		t.Error("a second update should not start while one is running")
+	}
+	msg := cmd().(PaletteIndexedMsg)
+	if msg.Stats.Parsed != 1 {
+		t.Errorf("only the new file should be parsed, got %+v", msg.Stats)
+	}
+	p.SetIndexed(msg)
+	if len(p.symbols) != 5 {
+		t.Errorf("expected the new symbol after the update, got %d symbols", len(p.symbols))
+	}
+
+	// An update finishing after a workspace change is ignored
+	p.SetWorkspace(t.TempDir())
+	p.SetIndexed(msg)
+	if p.indexed {
+		t.Error("an update of the previous workspace should be ignored")
+	}
+}
+
+func TestEditorPane_RecentFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, "a.txt", "a\n", "b.txt", "b\n")
+	editor := NewEditorPane(filemanager.NewFileManager(dir))
+	for _, name := range []string{"a.txt", "b.txt", "a.txt"} {
+		if err := editor.LoadFile(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	editor.SwitchBuffer(1)
+
+	want := []string{filepath.Join(dir, "b.txt"), filepath.Join(dir, "a.txt")}
+	if got := editor.RecentFiles(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("RecentFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestApp_PaletteOpensSymbolAtLine(t *testing.T) {
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, paletteWorkspace...)
+	app := newTestApp(t, dir)
+	editor := app.editorPane
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m"), Alt: true})
+	if cmd == nil || !app.palette.IsVisible() || app.palette.mode != paletteSymbols {
+		t.Fatal("alt+m should open the symbol palette")
+	}
+	app.Update(cmd())
+	if !strings.Contains(app.View(), "Go to symbol") {
+		t.Error("expected the palette in the view")
+	}
+
+	app.Update(paletteQuery("load"))
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command opening the symbol")
+	}
+	app.Update(cmd())
+	if editor.currentFile == nil || editor.currentFile.Filepath != filepath.Join(dir, "internal", "config", "config.go") {
+		t.Fatalf("expected config.go to be open, got %+v", editor.currentFile)
+	}
+	if editor.cursorLine != 3 {
+		t.Errorf("cursor line = %d, want 3 (line 4)", editor.cursorLine)
+	}
+
+	// Ctrl+P is go to file outside the AI pane, and keeps pasting inside it
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if cmd == nil || !app.palette.IsVisible() || app.palette.mode != paletteFiles {
+		t.Fatal("ctrl+p should open the file palette")
+	}
+	if app.palette.files[0].Path != "internal/config/config.go" {
+		t.Errorf("expected the recently opened file first, got %+v", app.palette.files[0])
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app.activePane = types.AIPaneType
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if app.palette.IsVisible() {
+		t.Error("ctrl+p in the AI pane should not open the palette")
+	}
+}
@@ -0,0 +1,461 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/git"
+	"github.com/user/terminal-intelligence/internal/search"
+)
+
+// replaceFile holds the matches of one previewed file and which of them
+// will be replaced
+type replaceFile struct {
+	path         string // absolute path
+	relPath      string
+	content      string // content the matches were found in
+	matches      []search.TextMatch
+	replacements []string
+	selected     []bool
+}
+
+// selectedMatches returns the matches of f that will be replaced and their
+// replacements
+func (f *replaceFile) selectedMatches() ([]search.TextMatch, []string) {
+	var matches []search.TextMatch
+	var replacements []string
+	for i, m := range f.matches {
+		if f.selected[i] {
+			matches = append(matches, m)
+			replacements = append(replacements, f.replacements[i])
+		}
+	}
+	return matches, replacements
+}
+
+// replaceRow is a line of the preview: a file header when match is -1,
+// otherwise one match of the file
+type replaceRow struct {
+	file  int
+	match int
+}
+
+// ReplacePreviewMsg is sent when a project-wide search for a replacement
+// finished.
+type ReplacePreviewMsg struct {
+	Root     string               // Workspace that was searched
+	Find     string               // Text that was searched for, as typed
+	Results  []search.FileMatches // Files with matches, in path order
+	Finder   *search.Finder       // Pattern that was searched for
+	Template string               // Replacement text
+	Err      error                // Why the workspace could not be searched
+}
+
+// ReplaceAppliedMsg is sent when the selected replacements of a project-wide
+// replace have been written.
+type ReplaceAppliedMsg struct {
+	Written []string // absolute paths of the files written
+	Summary string   // one-line summary of what was written and skipped
+}
+
+// findInProject returns a command that searches the workspace at root for
+// the text find, compiled into finder. It skips what git ignores and the
+// backups and chats in .ti, even when .gitignore does not list them.
+func findInProject(root string, finder *search.Finder, find, template string) tea.Cmd {
+	return func() tea.Msg {
+		msg := ReplacePreviewMsg{Root: root, Find: find, Finder: finder, Template: template}
+		rules, err := git.NewClient(root).IgnoreRules()
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		ignored := func(path string, isDir bool) bool {
+			return path == ".ti" || rules.Ignored(path, isDir)
+		}
+		msg.Results, msg.Err = search.FindInFiles(root, ignored, finder)
+		return msg
+	}
+}
+
+// ReplacePane is a full-screen overlay previewing a project-wide replace.
+// Every match is listed under its file with the text it becomes; matches
+// can be unticked one by one or a file at a time, and applying writes the
+// ticked replacements through the FileManager, so the usual backups are
+// made.
+type ReplacePane struct {
+	visible bool
+	width   int
+	height  int
+
+	title    string // Pattern and replacement, shown in the title
+	files    []*replaceFile
+	rows     []replaceRow
+	selected int // Index of the selected row
+	offset   int // First row on screen
+
+	errorMessage string
+
+	fileManager *filemanager.FileManager
+}
+
+// NewReplacePane creates a hidden ReplacePane that writes through
+// fileManager.
+func NewReplacePane(fileManager *filemanager.FileManager) *ReplacePane {
+	return &ReplacePane{fileManager: fileManager}
+}
+
+// Open previews replacing the matches of a project-wide search, all of
+// them ticked. It returns false, leaving the pane hidden, when there is
+// nothing to replace.
+func (r *ReplacePane) Open(msg ReplacePreviewMsg) bool {
+	var files []*replaceFile
+	var rows []replaceRow
+	for _, res := range msg.Results {
+		if len(res.Matches) == 0 {
+			continue
+		}
+		f := &replaceFile{
+			path:         filepath.Join(msg.Root, filepath.FromSlash(res.Path)),
+			relPath:      res.Path,
+			content:      res.Content,
+			matches:      res.Matches,
+			replacements: make([]string, len(res.Matches)),
+			selected:     make([]bool, len(res.Matches)),
+		}
+		for i, m := range res.Matches {
+			f.replacements[i] = msg.Finder.Replacement(res.Content, m, msg.Template)
+			f.selected[i] = true
+		}
+		rows = append(rows, replaceRow{file: len(files), match: -1})
+		for i := range f.matches {
+			rows = append(rows, replaceRow{file: len(files), match: i})
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return false
+	}
+
+	r.files = files
+	r.rows = rows
+	r.title = fmt.Sprintf("%q → %q", msg.Find, msg.Template)
+	r.selected = 0
+	r.offset = 0
+	r.errorMessage = ""
+	r.visible = true
+	return true
+}
+
+// Close hides the pane, discarding the preview.
+func (r *ReplacePane) Close() {
+	r.visible = false
+	r.files = nil
+	r.rows = nil
+}
+
+// IsVisible returns whether the pane is shown.
+func (r *ReplacePane) IsVisible() bool {
+	return r.visible
+}
+
+// SetSize sets the terminal size the pane fills.
+func (r *ReplacePane) SetSize(width, height int) {
+	r.width = width
+	r.height = height
+}
+
+// Update handles key presses while the pane is visible. Enter returns a
+// command that writes the ticked replacements and reports a
+// ReplaceAppliedMsg.
+func (r *ReplacePane) Update(msg tea.Msg) tea.Cmd {
+	if !r.visible {
+		return nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	r.errorMessage = ""
+	row := r.rows[r.selected]
+	switch keyMsg.String() {
+	case "down", "j":
+		r.move(1)
+	case "up", "k":
+		r.move(-1)
+	case "pgdown":
+		r.move(r.bodyHeight())
+	case "pgup":
+		r.move(-r.bodyHeight())
+	case "tab":
+		r.moveFile(1)
+	case "shift+tab":
+		r.moveFile(-1)
+	case " ":
+		if row.match < 0 {
+			r.toggleFile(r.files[row.file])
+		} else {
+			file := r.files[row.file]
+			file.selected[row.match] = !file.selected[row.match]
+			r.move(1)
+		}
+	case "a":
+		r.toggleFile(r.files[row.file])
+	case "A":
+		all := r.countSelected() < r.countMatches()
+		for _, f := range r.files {
+			for i := range f.selected {
+				f.selected[i] = all
+			}
+		}
+	case "enter":
+		return r.apply()
+	case "esc", "q":
+		r.Close()
+	}
+	return nil
+}
+
+// move moves the selection by delta rows, staying in range
+func (r *ReplacePane) move(delta int) {
+	r.selected = min(max(r.selected+delta, 0), len(r.rows)-1)
+}
+
+// moveFile selects the header of the next or previous file
+func (r *ReplacePane) moveFile(delta int) {
+	file := (r.rows[r.selected].file + delta + len(r.files)) % len(r.files)
+	for i, row := range r.rows {
+		if row.file == file && row.match < 0 {
+			r.selected = i
+			return
+		}
+	}
+}
+
+// toggleFile ticks every match of f, or unticks them all when they are all
+// ticked already
+func (r *ReplacePane) toggleFile(f *replaceFile) {
+	all := true
+	for _, s := range f.selected {
+		all = all && s
+	}
+	for i := range f.selected {
+		f.selected[i] = !all
+	}
+}
+
+// countMatches returns the number of matches in the preview
+func (r *ReplacePane) countMatches() int {
+	n := 0
+	for _, f := range r.files {
+		n += len(f.matches)
+	}
+	return n
+}
+
+// countSelected returns the number of ticked matches
+func (r *ReplacePane) countSelected() int {
+	n := 0
+	for _, f := range r.files {
+		for _, s := range f.selected {
+			if s {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// apply closes the pane and returns a command that writes the ticked
+// replacements
+func (r *ReplacePane) apply() tea.Cmd {
+	if r.countSelected() == 0 {
+		r.errorMessage = "No matches ticked. Tick matches with Space, or press Esc to close without writing."
+		return nil
+	}
+
+	files := r.files
+	fm := r.fileManager
+	r.Close()
+	return func() tea.Msg {
+		return applyReplace(fm, files)
+	}
+}
+
+// applyReplace writes the ticked replacements of each file. A file is
+// skipped when it changed on disk since the preview, so edits made in the
+// meantime are never overwritten.
+func applyReplace(fm *filemanager.FileManager, files []*replaceFile) ReplaceAppliedMsg {
+	var msg ReplaceAppliedMsg
+	var skipped []string
+	replaced := 0
+	for _, f := range files {
+		matches, replacements := f.selectedMatches()
+		if len(matches) == 0 {
+			continue
+		}
+
+		current, err := os.ReadFile(f.path)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", f.relPath, err))
+			continue
+		}
+		if string(current) != f.content {
+			skipped = append(skipped, f.relPath+" changed on disk since the preview")
+			continue
+		}
+		if err := fm.WriteFile(f.path, search.Apply(f.content, matches, replacements)); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", f.relPath, err))
+			continue
+		}
+
+		msg.Written = append(msg.Written, f.path)
+		replaced += len(matches)
+	}
+
+	msg.Summary = fmt.Sprintf("Replaced %d match(es) in %d file(s)", replaced, len(msg.Written))
+	if len(skipped) > 0 {
+		msg.Summary += "; not written: " + strings.Join(skipped, ", ")
+	}
+	return msg
+}
+
+// size returns the terminal size, with a default before the first resize
+func (r *ReplacePane) size() (int, int) {
+	if r.width <= 0 || r.height <= 0 {
+		return 100, 30
+	}
+	return r.width, r.height
+}
+
+// contentWidth is the width inside the border and padding
+func (r *ReplacePane) contentWidth() int {
+	width, _ := r.size()
+	return max(width-4, 20)
+}
+
+// bodyHeight is the number of rows shown between title and footer
+func (r *ReplacePane) bodyHeight() int {
+	_, height := r.size()
+	return max(height-5, 3)
+}
+
+// View renders the matches around the selected row.
+func (r *ReplacePane) View() string {
+	if !r.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+	title := fmt.Sprintf("Replace in project — %s  (%d of %d matches in %d files ticked)",
+		r.title, r.countSelected(), r.countMatches(), len(r.files))
+
+	height := r.bodyHeight()
+	if r.selected < r.offset {
+		r.offset = r.selected
+	}
+	if r.selected >= r.offset+height {
+		r.offset = r.selected - height + 1
+	}
+	var body []string
+	for i := r.offset; i < len(r.rows) && i < r.offset+height; i++ {
+		body = append(body, r.renderRow(i))
+	}
+	for len(body) < height {
+		body = append(body, "")
+	}
+
+	help := "j/k move  Tab file  Space tick  a whole file  A all  Enter replace ticked  Esc close"
+
+	var content strings.Builder
+	content.WriteString(ansi.Truncate(titleStyle.Render(title), r.contentWidth(), "…"))
+	content.WriteString("\n")
+	content.WriteString(strings.Join(body, "\n"))
+	content.WriteString("\n")
+	if r.errorMessage != "" {
+		content.WriteString(errorStyle.Render(ansi.Truncate(r.errorMessage, r.contentWidth(), "…")))
+	} else {
+		content.WriteString(helpStyle.Render(ansi.Truncate(help, r.contentWidth(), "…")))
+	}
+
+	width, _ := r.size()
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(width - 2).
+		Render(content.String())
+}
+
+// renderRow renders row i: a file header with its tick count, or a match
+// with its line number, the matched text struck out and its replacement
+func (r *ReplacePane) renderRow(i int) string {
+	row := r.rows[i]
+	file := r.files[row.file]
+	marker := "  "
+	if i == r.selected {
+		marker = "▶ "
+	}
+
+	if row.match < 0 {
+		ticked := 0
+		for _, s := range file.selected {
+			if s {
+				ticked++
+			}
+		}
+		header := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+		if i == r.selected {
+			header = header.Reverse(true)
+		}
+		line := marker + checkbox(ticked == len(file.matches), ticked > 0) + " " + header.Render(file.relPath) +
+			fmt.Sprintf("  (%d/%d)", ticked, len(file.matches))
+		return ansi.Truncate(line, r.contentWidth(), "…")
+	}
+
+	m := file.matches[row.match]
+	lineStart := strings.LastIndexByte(file.content[:m.Start], '\n') + 1
+	lineEnd := len(file.content)
+	if end := strings.IndexByte(file.content[m.End:], '\n'); end >= 0 {
+		lineEnd = m.End + end
+	}
+	before := displayText(file.content[lineStart:m.Start])
+	after := displayText(file.content[m.End:lineEnd])
+
+	number := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(fmt.Sprintf("%5d: ", m.Line+1))
+	if i == r.selected {
+		number = lipgloss.NewStyle().Reverse(true).Render(fmt.Sprintf("%5d:", m.Line+1)) + " "
+	}
+	line := marker + "  " + checkbox(file.selected[row.match], false) + number +
+		strings.TrimLeft(before, " ") +
+		reviewDeleteStyle.Strikethrough(true).Render(oneLine(file.content[m.Start:m.End])) +
+		reviewInsertStyle.Render(oneLine(file.replacements[row.match])) +
+		after
+	return ansi.Truncate(line, r.contentWidth(), "…")
+}
+
+// checkbox renders a tick box: [x] when ticked, [-] when partly ticked
+func checkbox(ticked, partly bool) string {
+	switch {
+	case ticked:
+		return "[x]"
+	case partly:
+		return "[-]"
+	default:
+		return "[ ]"
+	}
+}
+
+// oneLine prepares text that may span lines for display on one line
+func oneLine(text string) string {
+	return displayText(strings.ReplaceAll(text, "\n", "↵"))
+}
@@ -0,0 +1,330 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/search"
+)
+
+// replaceWorkspace holds the files of the replace tests.
+var replaceWorkspace = []string{
+	".gitignore", "gen/\n",
+	"a.go", "foo := foo(1)\n",
+	"b/b.txt", "Foo\nfood\nfoo\n",
+	"gen/gen.go", "foo\n",
+	".ti/backup.go", "foo\n",
+}
+
+// previewReplace searches dir for find with the finder of p and returns the
+// preview message.
+func previewReplace(t *testing.T, dir string, p search.Pattern, template string) ReplacePreviewMsg {
+	t.Helper()
+	finder, err := search.NewFinder(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, ok := findInProject(dir, finder, p.Text, template)().(ReplacePreviewMsg)
+	if !ok || msg.Err != nil {
+		t.Fatalf("expected a preview, got %+v", msg)
+	}
+	return msg
+}
+
+// newReplace opens a preview of replacing the whole word foo with bar in
+// a workspace with the replace test files.
+func newReplace(t *testing.T) (*ReplacePane, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, replaceWorkspace...)
+	pane := NewReplacePane(filemanager.NewFileManager(dir))
+	pane.SetSize(100, 30)
+	if !pane.Open(previewReplace(t, dir, search.Pattern{Text: "foo", WholeWord: true}, "bar")) {
+		t.Fatal("expected the preview to open")
+	}
+	return pane, dir
+}
+
+// runReplaceCmd runs the command returned by the pane and returns its
+// message
+func runReplaceCmd(t *testing.T, cmd tea.Cmd) ReplaceAppliedMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg, ok := cmd().(ReplaceAppliedMsg)
+	if !ok {
+		t.Fatalf("expected ReplaceAppliedMsg, got %T", msg)
+	}
+	return msg
+}
+
+// readFile returns the content of the file at name below dir.
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReplacePane_PreviewRespectsIgnores(t *testing.T) {
+	pane, _ := newReplace(t)
+	var paths []string
+	for _, f := range pane.files {
+		paths = append(paths, f.relPath)
+	}
+	if len(paths) != 2 || paths[0] != "a.go" || paths[1] != "b/b.txt" {
+		t.Fatalf("expected only a.go and b/b.txt, got %v", paths)
+	}
+
+	view := pane.View()
+	for _, want := range []string{"Replace in project", "4 of 4 matches in 2 files", "[x] a.go", "(2/2)", "bar"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if !strings.Contains(view, "    3:") || strings.Contains(view, "food") {
+		t.Errorf("expected the line of each match, and no line without one:\n%s", view)
+	}
+}
+
+func TestReplacePane_UntickAndApply(t *testing.T) {
+	pane, dir := newReplace(t)
+
+	// Untick the second match of a.go, then the whole of b/b.txt
+	pane.Update(reviewKey("j"))
+	pane.Update(reviewKey("j"))
+	pane.Update(reviewKey(" "))
+	if f := pane.files[0]; f.selected[1] || !f.selected[0] {
+		t.Fatalf("expected only the second match of a.go unticked, got %v", f.selected)
+	}
+	if pane.selected != 3 {
+		t.Fatalf("space should move to the next row, got row %d", pane.selected)
+	}
+	pane.Update(reviewKey("a"))
+	if pane.countSelected() != 1 {
+		t.Fatalf("expected a to untick b/b.txt, got %d ticked", pane.countSelected())
+	}
+	if !strings.Contains(pane.View(), "[-] a.go") || !strings.Contains(pane.View(), "[ ] b/b.txt") {
+		t.Errorf("expected partial and empty file boxes:\n%s", pane.View())
+	}
+
+	msg := runReplaceCmd(t, pane.Update(reviewKey("enter")))
+	if pane.IsVisible() {
+		t.Error("applying should close the pane")
+	}
+	if len(msg.Written) != 1 || msg.Written[0] != filepath.Join(dir, "a.go") {
+		t.Errorf("expected only a.go to be written, got %v", msg.Written)
+	}
+	if !strings.Contains(msg.Summary, "Replaced 1 match(es) in 1 file(s)") {
+		t.Errorf("unexpected summary %q", msg.Summary)
+	}
+	if got := readFile(t, dir, "a.go"); got != "bar := foo(1)\n" {
+		t.Errorf("a.go = %q", got)
+	}
+	if got := readFile(t, dir, "b/b.txt"); got != "Foo\nfood\nfoo\n" {
+		t.Errorf("b/b.txt should be unchanged, got %q", got)
+	}
+	if got := readFile(t, dir, ".ti/backup.go"); got != "foo\n" {
+		t.Errorf("the .ti directory should not be searched, got %q", got)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, ".ti", "*a.go"))
+	if len(backups) != 1 {
+		t.Errorf("expected a backup of a.go, got %v", backups)
+	}
+}
+
+func TestReplacePane_ToggleAllAndNothingTicked(t *testing.T) {
+	pane, _ := newReplace(t)
+	pane.Update(reviewKey("A"))
+	if pane.countSelected() != 0 {
+		t.Fatalf("A should untick every match, got %d ticked", pane.countSelected())
+	}
+	if cmd := pane.Update(reviewKey("enter")); cmd != nil || !pane.IsVisible() {
+		t.Fatal("enter with nothing ticked should not apply")
+	}
+	if !strings.Contains(pane.View(), "No matches ticked") {
+		t.Errorf("expected an error in the view:\n%s", pane.View())
+	}
+
+	pane.Update(reviewKey("A"))
+	if pane.countSelected() != pane.countMatches() {
+		t.Errorf("A should tick every match again, got %d", pane.countSelected())
+	}
+	pane.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if row := pane.rows[pane.selected]; row.file != 1 || row.match != -1 {
+		t.Errorf("tab should select the next file, got %+v", row)
+	}
+	pane.Update(reviewKey("esc"))
+	if pane.IsVisible() {
+		t.Error("esc should close the pane")
+	}
+}
+
+func TestReplacePane_SkipsFilesChangedOnDisk(t *testing.T) {
+	pane, dir := newReplace(t)
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("foo = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := runReplaceCmd(t, pane.Update(reviewKey("enter")))
+	if len(msg.Written) != 1 || !strings.Contains(msg.Summary, "a.go changed on disk since the preview") {
+		t.Errorf("expected a.go to be skipped, got %+v", msg)
+	}
+	if got := readFile(t, dir, "a.go"); got != "foo = 2\n" {
+		t.Errorf("a.go should keep the change made on disk, got %q", got)
+	}
+	if got := readFile(t, dir, "b/b.txt"); got != "bar\nfood\nbar\n" {
+		t.Errorf("b/b.txt = %q", got)
+	}
+}
+
+// newFindApp returns an App on a workspace with the replace test files and
+// a.go open in the editor.
+func newFindApp(t *testing.T) (*App, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeTreeFiles(t, dir, replaceWorkspace...)
+	app := newTestApp(t, dir)
+	if err := app.editorPane.LoadFile(filepath.Join(dir, "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	return app, dir
+}
+
+// typeApp types text into the app one key at a time.
+func typeApp(app *App, text string) {
+	for _, r := range text {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestApp_FindOptions(t *testing.T) {
+	app, _ := newFindApp(t)
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	app.Update(altKey('w'))
+	typeApp(app, "foo")
+	if !strings.Contains(app.View(), "[x] Word (Alt+W)") {
+		t.Errorf("expected the whole word option ticked:\n%s", app.View())
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.findResults) != 2 || app.findResults[1].col != 7 {
+		t.Errorf("expected 2 whole word matches, got %+v", app.findResults)
+	}
+
+	// An invalid expression keeps the prompt open with the error
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	app.Update(altKey('r'))
+	typeApp(app, "foo(")
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !app.showFindPrompt || !strings.Contains(app.View(), "invalid regular expression") {
+		t.Fatalf("expected the prompt to show the error:\n%s", app.View())
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	typeApp(app, `\(\d\)`)
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.showFindPrompt || len(app.findResults) != 1 || app.findResults[0].col != 7 {
+		t.Errorf("expected the regex to match foo(1), got %+v", app.findResults)
+	}
+}
+
+func TestApp_ReplaceInFileWithGroups(t *testing.T) {
+	app, dir := newFindApp(t)
+
+	app.Update(altKey('f'))
+	if app.replaceInProject {
+		t.Fatal("replace should start on the open file")
+	}
+	app.Update(altKey('r'))
+	app.Update(altKey('c'))
+	typeApp(app, `(\w+)\((\d)\)`)
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeApp(app, "$1[$2]")
+	if _, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("replacing in the open file should not search the project")
+	}
+	if got := readFile(t, dir, "a.go"); got != "foo := foo[1]\n" {
+		t.Errorf("a.go = %q", got)
+	}
+	if app.editorPane.GetContent() != "foo := foo[1]\n" || app.statusMessage != "Replaced 1 occurrences" {
+		t.Errorf("unexpected editor content %q and status %q", app.editorPane.GetContent(), app.statusMessage)
+	}
+}
+
+func TestApp_ReplaceInProject(t *testing.T) {
+	app, dir := newFindApp(t)
+	// b/b.txt has unsaved changes and stays out of the replace
+	if err := app.editorPane.LoadFile(filepath.Join(dir, "b", "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	app.editorPane.SetContent("foo\n")
+	app.editorPane.SwitchBuffer(0)
+
+	app.Update(altKey('f'))
+	app.Update(altKey('a'))
+	if !app.replaceInProject || !strings.Contains(app.View(), "Project: all files") {
+		t.Fatalf("alt+a should switch to the project:\n%s", app.View())
+	}
+	app.Update(altKey('w'))
+	typeApp(app, "foo")
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeApp(app, "bar")
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || app.showFindReplacePrompt {
+		t.Fatal("expected the prompt to close and the project search to start")
+	}
+	app.Update(cmd())
+	if !app.replacePane.IsVisible() || len(app.replacePane.files) != 1 {
+		t.Fatalf("expected a preview of a.go only, got %+v", app.replacePane.files)
+	}
+	if !strings.Contains(app.statusMessage, "1 file(s) with unsaved changes") {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+	if !strings.Contains(app.View(), "Replace in project") {
+		t.Error("expected the preview in the view")
+	}
+
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command applying the replace")
+	}
+	app.Update(cmd())
+	if got := app.editorPane.GetContent(); got != "bar := bar(1)\n" {
+		t.Errorf("the open buffer should be reloaded, got %q", got)
+	}
+	if app.editorPane.IsUnsaved(filepath.Join(dir, "a.go")) || !app.editorPane.IsUnsaved(filepath.Join(dir, "b", "b.txt")) {
+		t.Error("expected a.go reloaded and b/b.txt still unsaved")
+	}
+	if !strings.Contains(app.statusMessage, "Replaced 2 match(es) in 1 file(s)") {
+		t.Errorf("unexpected status %q", app.statusMessage)
+	}
+}
+
+func TestApp_ReplaceWithoutOpenFileUsesProject(t *testing.T) {
+	app, _ := newFindApp(t)
+	app.editorPane.CloseAll()
+
+	app.Update(altKey('f'))
+	if !app.showFindReplacePrompt || !app.replaceInProject {
+		t.Fatal("alt+f without an open file should replace in the project")
+	}
+	app.Update(altKey('a'))
+	if !app.replaceInProject || !strings.Contains(app.View(), "No file open") {
+		t.Errorf("the scope should stay on the project without a file:\n%s", app.View())
+	}
+
+	typeApp(app, "nothing-matches-this")
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app.Update(cmd())
+	if app.replacePane.IsVisible() || !strings.Contains(app.statusMessage, "No matches found") {
+		t.Errorf("expected no preview, got status %q", app.statusMessage)
+	}
+}
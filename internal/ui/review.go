@@ -0,0 +1,573 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/user/terminal-intelligence/internal/agentic"
+	"github.com/user/terminal-intelligence/internal/diff"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+)
+
+// reviewContext is the number of unchanged lines shown around each change
+const reviewContext = 3
+
+// hunkDecision is the reviewer's verdict on a hunk
+type hunkDecision int
+
+const (
+	hunkPending hunkDecision = iota
+	hunkAccepted
+	hunkRejected
+)
+
+// reviewFile holds the hunks of one previewed file and the decisions on them
+type reviewFile struct {
+	path      string // absolute path
+	relPath   string
+	original  string // content the hunks apply to
+	hunks     []diff.Hunk
+	decisions []hunkDecision
+	edited    []bool
+}
+
+// acceptedHunks returns the hunks of f that will be written
+func (f *reviewFile) acceptedHunks() []diff.Hunk {
+	var hunks []diff.Hunk
+	for i, h := range f.hunks {
+		if f.decisions[i] == hunkAccepted {
+			hunks = append(hunks, h)
+		}
+	}
+	return hunks
+}
+
+// ReviewAppliedMsg is sent when the accepted hunks of a review have been
+// written.
+type ReviewAppliedMsg struct {
+	Written []string // absolute paths of the files written
+	Summary string   // chat summary of what was written and skipped
+}
+
+// ReviewPane is a full-screen overlay for reviewing the edits of a /preview
+// run before they are written. Each file's changes are split into hunks that
+// can be accepted, rejected or edited; applying writes only the accepted
+// hunks through the FileManager, so the usual backups are made.
+type ReviewPane struct {
+	visible bool
+	width   int
+	height  int
+
+	files      []*reviewFile
+	fileIdx    int
+	hunkIdx    int
+	sideBySide bool
+
+	editing bool           // whether the current hunk is open in the editor
+	editor  textarea.Model // edits the new side of the current hunk
+
+	errorMessage string
+
+	fileManager *filemanager.FileManager
+}
+
+// NewReviewPane creates a hidden ReviewPane that writes through fileManager.
+func NewReviewPane(fileManager *filemanager.FileManager) *ReviewPane {
+	editor := textarea.New()
+	editor.ShowLineNumbers = true
+	editor.CharLimit = 0
+	editor.MaxHeight = 0
+	return &ReviewPane{
+		fileManager: fileManager,
+		editor:      editor,
+	}
+}
+
+// Open shows the changes of a preview report for review. It returns false,
+// leaving the pane hidden, when the report has no changes to review.
+func (r *ReviewPane) Open(report *agentic.ChangeReport) bool {
+	if report == nil {
+		return false
+	}
+
+	var files []*reviewFile
+	for _, fr := range report.FilesModified {
+		hunks := diff.Compute(fr.OldContent, fr.NewContent, reviewContext)
+		if len(hunks) == 0 {
+			continue
+		}
+		relPath := fr.RelPath
+		if relPath == "" {
+			relPath = fr.Path
+		}
+		files = append(files, &reviewFile{
+			path:      fr.Path,
+			relPath:   relPath,
+			original:  fr.OldContent,
+			hunks:     hunks,
+			decisions: make([]hunkDecision, len(hunks)),
+			edited:    make([]bool, len(hunks)),
+		})
+	}
+	if len(files) == 0 {
+		return false
+	}
+
+	r.files = files
+	r.fileIdx = 0
+	r.hunkIdx = 0
+	r.editing = false
+	r.errorMessage = ""
+	r.visible = true
+	return true
+}
+
+// Close hides the pane, discarding the review.
+func (r *ReviewPane) Close() {
+	r.visible = false
+	r.editing = false
+	r.editor.Blur()
+	r.files = nil
+}
+
+// IsVisible returns whether the pane is shown.
+func (r *ReviewPane) IsVisible() bool {
+	return r.visible
+}
+
+// SetSize sets the terminal size the pane fills.
+func (r *ReviewPane) SetSize(width, height int) {
+	r.width = width
+	r.height = height
+}
+
+// Update handles key presses while the pane is visible. Enter returns a
+// command that writes the accepted hunks and reports a ReviewAppliedMsg.
+func (r *ReviewPane) Update(msg tea.Msg) tea.Cmd {
+	if !r.visible {
+		return nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	if r.editing {
+		return r.updateEditing(keyMsg)
+	}
+
+	r.errorMessage = ""
+	file := r.files[r.fileIdx]
+	switch keyMsg.String() {
+	case "down", "j":
+		r.moveHunk(1)
+	case "up", "k":
+		r.moveHunk(-1)
+	case "tab":
+		r.moveFile(1)
+	case "shift+tab":
+		r.moveFile(-1)
+	case "a":
+		file.decisions[r.hunkIdx] = hunkAccepted
+		r.moveHunk(1)
+	case "r":
+		file.decisions[r.hunkIdx] = hunkRejected
+		r.moveHunk(1)
+	case "A":
+		for i := range file.decisions {
+			file.decisions[i] = hunkAccepted
+		}
+	case "R":
+		for i := range file.decisions {
+			file.decisions[i] = hunkRejected
+		}
+	case "v":
+		r.sideBySide = !r.sideBySide
+	case "e":
+		r.startEditing()
+	case "enter":
+		return r.apply()
+	case "esc", "q":
+		r.Close()
+	}
+	return nil
+}
+
+// updateEditing handles keys while a hunk is open in the editor: Ctrl+S keeps
+// the edit and accepts the hunk, Esc discards it.
+func (r *ReviewPane) updateEditing(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+s":
+		file := r.files[r.fileIdx]
+		h := file.hunks[r.hunkIdx]
+		file.hunks[r.hunkIdx] = h.WithNewLines(editedLines(r.editor.Value(), h.NewLines()))
+		file.decisions[r.hunkIdx] = hunkAccepted
+		file.edited[r.hunkIdx] = true
+		r.editing = false
+		r.editor.Blur()
+		return nil
+	case "esc":
+		r.editing = false
+		r.editor.Blur()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	r.editor, cmd = r.editor.Update(msg)
+	return cmd
+}
+
+// startEditing opens the new side of the current hunk in the editor
+func (r *ReviewPane) startEditing() {
+	h := r.files[r.fileIdx].hunks[r.hunkIdx]
+	r.editor.SetWidth(r.contentWidth())
+	r.editor.SetHeight(r.bodyHeight() - 1)
+	r.editor.SetValue(strings.TrimSuffix(strings.Join(h.NewLines(), ""), "\n"))
+	r.editor.Focus()
+	r.editing = true
+}
+
+// editedLines splits edited text into diff lines. The last line only gets a
+// newline if the last line of the text it replaces had one.
+func editedLines(value string, replaced []string) []string {
+	if value == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(value, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	last := lines[len(lines)-1]
+	if !strings.HasSuffix(last, "\n") {
+		if len(replaced) == 0 || strings.HasSuffix(replaced[len(replaced)-1], "\n") {
+			lines[len(lines)-1] = last + "\n"
+		}
+	}
+	return lines
+}
+
+// moveHunk moves the selection by delta hunks, crossing into the next or
+// previous file at either end.
+func (r *ReviewPane) moveHunk(delta int) {
+	idx := r.hunkIdx + delta
+	switch {
+	case idx < 0:
+		if r.fileIdx > 0 {
+			r.fileIdx--
+			r.hunkIdx = len(r.files[r.fileIdx].hunks) - 1
+		}
+	case idx >= len(r.files[r.fileIdx].hunks):
+		if r.fileIdx < len(r.files)-1 {
+			r.fileIdx++
+			r.hunkIdx = 0
+		}
+	default:
+		r.hunkIdx = idx
+	}
+}
+
+// moveFile selects the first hunk of the next or previous file
+func (r *ReviewPane) moveFile(delta int) {
+	r.fileIdx = (r.fileIdx + delta + len(r.files)) % len(r.files)
+	r.hunkIdx = 0
+}
+
+// apply closes the pane and returns a command that writes the accepted hunks
+func (r *ReviewPane) apply() tea.Cmd {
+	accepted := 0
+	for _, f := range r.files {
+		accepted += len(f.acceptedHunks())
+	}
+	if accepted == 0 {
+		r.errorMessage = "No hunks accepted. Accept hunks with a, or press Esc to close without writing."
+		return nil
+	}
+
+	files := r.files
+	fm := r.fileManager
+	r.Close()
+	return func() tea.Msg {
+		return applyReview(fm, files)
+	}
+}
+
+// applyReview writes the accepted hunks of each file. A file is skipped when
+// it changed on disk since the preview, so edits made in the meantime are
+// never overwritten.
+func applyReview(fm *filemanager.FileManager, files []*reviewFile) ReviewAppliedMsg {
+	var msg ReviewAppliedMsg
+	var written, skipped []string
+	hunks := 0
+	for _, f := range files {
+		accepted := f.acceptedHunks()
+		if len(accepted) == 0 {
+			continue
+		}
+
+		current, err := os.ReadFile(f.path)
+		if err != nil && !os.IsNotExist(err) {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", f.relPath, err))
+			continue
+		}
+		if string(current) != f.original {
+			skipped = append(skipped, f.relPath+": changed on disk since the preview")
+			continue
+		}
+
+		content, err := diff.Apply(f.original, accepted)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", f.relPath, err))
+			continue
+		}
+		if err := fm.WriteFile(f.path, content); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", f.relPath, err))
+			continue
+		}
+
+		msg.Written = append(msg.Written, f.path)
+		written = append(written, fmt.Sprintf("%s (%d of %d hunks)", f.relPath, len(accepted), len(f.hunks)))
+		hunks += len(accepted)
+	}
+
+	var sb strings.Builder
+	if len(written) > 0 {
+		fmt.Fprintf(&sb, "✅ Applied %d reviewed hunk(s) to %d file(s):\n", hunks, len(written))
+		for _, w := range written {
+			sb.WriteString("- " + w + "\n")
+		}
+	} else {
+		sb.WriteString("No reviewed changes were written.\n")
+	}
+	if len(skipped) > 0 {
+		sb.WriteString("⚠️ Not written:\n")
+		for _, s := range skipped {
+			sb.WriteString("- " + s + "\n")
+		}
+	}
+	msg.Summary = strings.TrimRight(sb.String(), "\n")
+	return msg
+}
+
+// size returns the terminal size, with a default before the first resize
+func (r *ReviewPane) size() (int, int) {
+	if r.width <= 0 || r.height <= 0 {
+		return 100, 30
+	}
+	return r.width, r.height
+}
+
+// contentWidth is the width inside the border and padding
+func (r *ReviewPane) contentWidth() int {
+	width, _ := r.size()
+	return max(width-4, 20)
+}
+
+// bodyHeight is the number of diff lines shown between title and footer
+func (r *ReviewPane) bodyHeight() int {
+	_, height := r.size()
+	return max(height-6, 3)
+}
+
+// View renders the review of the current file.
+func (r *ReviewPane) View() string {
+	if !r.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+	file := r.files[r.fileIdx]
+	accepted, rejected := 0, 0
+	for _, d := range file.decisions {
+		switch d {
+		case hunkAccepted:
+			accepted++
+		case hunkRejected:
+			rejected++
+		}
+	}
+	title := fmt.Sprintf("Review changes — file %d/%d: %s  (%d accepted, %d rejected, %d pending)",
+		r.fileIdx+1, len(r.files), file.relPath, accepted, rejected, len(file.hunks)-accepted-rejected)
+
+	var body []string
+	if r.editing {
+		body = append(body, titleStyle.Render("Editing "+file.hunks[r.hunkIdx].Header()+" (new side)"))
+		body = append(body, strings.Split(r.editor.View(), "\n")...)
+	} else {
+		body = r.visibleLines(r.renderFile(file))
+	}
+	for len(body) < r.bodyHeight() {
+		body = append(body, "")
+	}
+
+	layout := "side-by-side"
+	if r.sideBySide {
+		layout = "unified"
+	}
+	help := "j/k hunk  Tab file  a/r accept/reject  A/R whole file  e edit  v " + layout + "  Enter apply accepted  Esc close"
+	if r.editing {
+		help = "Ctrl+S keep edit and accept  Esc discard edit"
+	}
+
+	var content strings.Builder
+	content.WriteString(ansi.Truncate(titleStyle.Render(title), r.contentWidth(), "…"))
+	content.WriteString("\n")
+	content.WriteString(strings.Join(body, "\n"))
+	content.WriteString("\n")
+	if r.errorMessage != "" {
+		content.WriteString(errorStyle.Render(ansi.Truncate(r.errorMessage, r.contentWidth(), "…")))
+	} else {
+		content.WriteString(helpStyle.Render(ansi.Truncate(help, r.contentWidth(), "…")))
+	}
+
+	width, _ := r.size()
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(width - 2).
+		Render(content.String())
+}
+
+// renderedFile is the diff of a file as display lines, with the line range
+// of the selected hunk
+type renderedFile struct {
+	lines         []string
+	selectedStart int
+	selectedEnd   int
+}
+
+// renderFile renders every hunk of file
+func (r *ReviewPane) renderFile(file *reviewFile) renderedFile {
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	selectedStyle := headerStyle.Bold(true).Reverse(true)
+	decisionStyles := map[hunkDecision]lipgloss.Style{
+		hunkPending:  lipgloss.NewStyle().Foreground(lipgloss.Color("226")),
+		hunkAccepted: lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+		hunkRejected: lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+	}
+	decisionLabels := map[hunkDecision]string{
+		hunkPending:  "[pending]",
+		hunkAccepted: "[accepted]",
+		hunkRejected: "[rejected]",
+	}
+
+	var out renderedFile
+	for i, h := range file.hunks {
+		label := decisionLabels[file.decisions[i]]
+		if file.edited[i] {
+			label = "[accepted, edited]"
+		}
+		header := "  " + headerStyle.Render(h.Header())
+		if i == r.hunkIdx {
+			out.selectedStart = len(out.lines)
+			header = "▶ " + selectedStyle.Render(h.Header())
+		}
+		out.lines = append(out.lines, header+" "+decisionStyles[file.decisions[i]].Render(label))
+
+		if r.sideBySide {
+			out.lines = append(out.lines, r.renderSideBySide(h)...)
+		} else {
+			out.lines = append(out.lines, r.renderUnified(h)...)
+		}
+		if i == r.hunkIdx {
+			out.selectedEnd = len(out.lines)
+		}
+		out.lines = append(out.lines, "")
+	}
+	return out
+}
+
+// visibleLines returns the part of a rendered file that fits the body,
+// scrolled so that the selected hunk is in view
+func (r *ReviewPane) visibleLines(rf renderedFile) []string {
+	height := r.bodyHeight()
+	start := 0
+	if rf.selectedEnd > height {
+		start = rf.selectedEnd - height
+	}
+	if start > rf.selectedStart {
+		start = rf.selectedStart
+	}
+	end := min(start+height, len(rf.lines))
+	return rf.lines[start:end]
+}
+
+var (
+	reviewDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	reviewInsertStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+)
+
+// renderUnified renders a hunk as unified diff lines
+func (r *ReviewPane) renderUnified(h diff.Hunk) []string {
+	width := r.contentWidth()
+	var lines []string
+	for _, l := range h.Lines {
+		text := displayText(l.Text)
+		switch l.Kind {
+		case diff.Delete:
+			lines = append(lines, reviewDeleteStyle.Render(ansi.Truncate("-"+text, width, "…")))
+		case diff.Insert:
+			lines = append(lines, reviewInsertStyle.Render(ansi.Truncate("+"+text, width, "…")))
+		default:
+			lines = append(lines, ansi.Truncate(" "+text, width, "…"))
+		}
+	}
+	return lines
+}
+
+// renderSideBySide renders a hunk with the old text on the left and the new
+// text on the right, pairing up the deleted and inserted lines of a change
+func (r *ReviewPane) renderSideBySide(h diff.Hunk) []string {
+	colWidth := (r.contentWidth() - 3) / 2
+	cell := func(prefix, text string, style *lipgloss.Style) string {
+		s := ansi.Truncate(prefix+displayText(text), colWidth, "…")
+		s += strings.Repeat(" ", max(colWidth-ansi.StringWidth(s), 0))
+		if style != nil {
+			return style.Render(s)
+		}
+		return s
+	}
+	empty := strings.Repeat(" ", colWidth)
+
+	var lines []string
+	for i := 0; i < len(h.Lines); {
+		if h.Lines[i].Kind == diff.Equal {
+			lines = append(lines, cell(" ", h.Lines[i].Text, nil)+" │ "+cell(" ", h.Lines[i].Text, nil))
+			i++
+			continue
+		}
+
+		var deleted, inserted []string
+		for ; i < len(h.Lines) && h.Lines[i].Kind != diff.Equal; i++ {
+			if h.Lines[i].Kind == diff.Delete {
+				deleted = append(deleted, h.Lines[i].Text)
+			} else {
+				inserted = append(inserted, h.Lines[i].Text)
+			}
+		}
+		for row := 0; row < max(len(deleted), len(inserted)); row++ {
+			left, right := empty, empty
+			if row < len(deleted) {
+				left = cell("-", deleted[row], &reviewDeleteStyle)
+			}
+			if row < len(inserted) {
+				right = cell("+", inserted[row], &reviewInsertStyle)
+			}
+			lines = append(lines, left+" │ "+right)
+		}
+	}
+	return lines
+}
+
+// displayText prepares a diff line for display
+func displayText(text string) string {
+	text = strings.TrimRight(text, "\r\n")
+	return strings.ReplaceAll(text, "\t", "    ")
+}
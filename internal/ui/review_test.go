@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/agentic"
+	"github.com/user/terminal-intelligence/internal/filemanager"
+)
+
+const reviewOriginal = "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+
+// newReview writes main.txt with reviewOriginal and opens a review of a
+// preview that changes its first and last lines
+func newReview(t *testing.T) (*ReviewPane, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.txt")
+	if err := os.WriteFile(path, []byte(reviewOriginal), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := &agentic.ChangeReport{
+		PreviewMode: true,
+		FilesModified: []agentic.FileResult{{
+			Path:       path,
+			RelPath:    "main.txt",
+			OldContent: reviewOriginal,
+			NewContent: strings.Replace(strings.Replace(reviewOriginal, "one", "ONE", 1), "ten", "TEN", 1),
+		}},
+	}
+	pane := NewReviewPane(filemanager.NewFileManager(dir))
+	pane.SetSize(100, 30)
+	if !pane.Open(report) {
+		t.Fatal("expected the review to open")
+	}
+	return pane, path
+}
+
+// reviewKey returns the key message of a key name
+func reviewKey(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// runReviewCmd runs the command returned by the pane and returns its message
+func runReviewCmd(t *testing.T, cmd tea.Cmd) ReviewAppliedMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg, ok := cmd().(ReviewAppliedMsg)
+	if !ok {
+		t.Fatalf("expected ReviewAppliedMsg, got %T", msg)
+	}
+	return msg
+}
+
+func TestReviewPane_OpenSplitsHunks(t *testing.T) {
+	pane, _ := newReview(t)
+	if len(pane.files) != 1 || len(pane.files[0].hunks) != 2 {
+		t.Fatalf("expected 1 file with 2 hunks, got %+v", pane.files)
+	}
+
+	view := pane.View()
+	for _, want := range []string{"main.txt", "-one", "+ONE", "[pending]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	pane.Update(reviewKey("v"))
+	if !strings.Contains(pane.View(), "│") {
+		t.Error("expected side-by-side columns")
+	}
+}
+
+func TestReviewPane_OpenWithoutChanges(t *testing.T) {
+	pane := NewReviewPane(filemanager.NewFileManager(t.TempDir()))
+	report := &agentic.ChangeReport{FilesModified: []agentic.FileResult{{Path: "/x", OldContent: "a\n", NewContent: "a\n"}}}
+	if pane.Open(report) || pane.Open(nil) || pane.IsVisible() {
+		t.Error("a report without changes should not open the review")
+	}
+}
+
+func TestReviewPane_AppliesOnlyAcceptedHunks(t *testing.T) {
+	pane, path := newReview(t)
+
+	pane.Update(reviewKey("r")) // reject first hunk
+	pane.Update(reviewKey("a")) // accept second hunk
+	msg := runReviewCmd(t, pane.Update(reviewKey("enter")))
+
+	if pane.IsVisible() {
+		t.Error("pane should close after applying")
+	}
+	if len(msg.Written) != 1 || msg.Written[0] != path {
+		t.Errorf("unexpected written files: %v", msg.Written)
+	}
+	if !strings.Contains(msg.Summary, "main.txt (1 of 2 hunks)") {
+		t.Errorf("unexpected summary: %q", msg.Summary)
+	}
+
+	got, _ := os.ReadFile(path)
+	want := strings.Replace(reviewOriginal, "ten", "TEN", 1)
+	if string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+
+	// Written through the FileManager, so the original is backed up
+	backups, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".ti", "*main.txt"))
+	if len(backups) != 1 {
+		t.Errorf("expected a backup of the original, got %v", backups)
+	}
+}
+
+func TestReviewPane_EditedHunk(t *testing.T) {
+	pane, path := newReview(t)
+
+	pane.Update(reviewKey("e"))
+	if !pane.editing {
+		t.Fatal("expected edit mode")
+	}
+	pane.editor.SetValue("Uno\ntwo\nthree\nfour")
+	pane.Update(reviewKey("ctrl+s"))
+	if pane.editing || pane.files[0].decisions[0] != hunkAccepted || !pane.files[0].edited[0] {
+		t.Fatal("saving the edit should accept the hunk")
+	}
+
+	runReviewCmd(t, pane.Update(reviewKey("enter")))
+	got, _ := os.ReadFile(path)
+	want := strings.Replace(reviewOriginal, "one", "Uno", 1)
+	if string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestReviewPane_SkipsFileChangedOnDisk(t *testing.T) {
+	pane, path := newReview(t)
+	if err := os.WriteFile(path, []byte("edited elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pane.Update(reviewKey("A"))
+	msg := runReviewCmd(t, pane.Update(reviewKey("enter")))
+	if len(msg.Written) != 0 || !strings.Contains(msg.Summary, "changed on disk") {
+		t.Errorf("expected the file to be skipped, got %+v", msg)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "edited elsewhere\n" {
+		t.Errorf("file was overwritten: %q", got)
+	}
+}
+
+func TestReviewPane_NothingAccepted(t *testing.T) {
+	pane, _ := newReview(t)
+	if cmd := pane.Update(reviewKey("enter")); cmd != nil {
+		t.Error("apply without accepted hunks should not write")
+	}
+	if !pane.IsVisible() || pane.errorMessage == "" {
+		t.Error("expected the pane to stay open with a hint")
+	}
+
+	pane.Update(reviewKey("esc"))
+	if pane.IsVisible() {
+		t.Error("esc should close the review")
+	}
+}
+
+func TestEditedLines(t *testing.T) {
+	if got := editedLines("a\nb", []string{"x\n"}); strings.Join(got, "") != "a\nb\n" {
+		t.Errorf("expected trailing newline to be kept, got %q", got)
+	}
+	if got := editedLines("a\nb", []string{"x"}); strings.Join(got, "") != "a\nb" {
+		t.Errorf("expected missing final newline to be kept, got %q", got)
+	}
+	if got := editedLines("", []string{"x\n"}); got != nil {
+		t.Errorf("expected no lines, got %q", got)
+	}
+}
+
+func TestApp_PreviewOpensReview(t *testing.T) {
+	pane, path := newReview(t)
+	report := &agentic.ChangeReport{PreviewMode: true, FilesModified: []agentic.FileResult{{
+		Path: path, RelPath: "main.txt", OldContent: reviewOriginal, NewContent: "changed\n",
+	}}}
+	pane.Close()
+	app := newTestApp(t, filepath.Dir(path))
+	app.reviewPane = pane
+
+	app.Update(ProjectCompleteMsg{Report: report, Formatted: "preview", LastPreviewRequest: "change it"})
+	if !pane.IsVisible() || !strings.Contains(app.View(), "Review changes") {
+		t.Fatal("expected a preview run to open the review screen")
+	}
+
+	app.Update(reviewKey("esc"))
+	if pane.IsVisible() {
+		t.Fatal("esc should close the review screen")
+	}
+	if cmd := app.handleAIMessage("/review"); cmd != nil || !pane.IsVisible() {
+		t.Fatal("/review should reopen the last preview")
+	}
+
+	app.Update(ReviewAppliedMsg{Written: []string{path}, Summary: "applied"})
+	if app.lastPreviewReport != nil || app.lastPreviewRequest != "" {
+		t.Error("applying the review should clear the preview")
+	}
+	cmd := app.handleAIMessage("/review")
+	if cmd == nil || !strings.Contains(cmd().(AINotificationMsg).Content, "Nothing to review") {
+		t.Error("expected /review to report that nothing is left to review")
+	}
+}
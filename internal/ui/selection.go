@@ -0,0 +1,469 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/syntax"
+)
+
+// textPos is a position in the editor content: a line and a byte column.
+type textPos struct {
+	line, col int
+}
+
+// before reports whether p comes before q.
+func (p textPos) before(q textPos) bool {
+	return p.line < q.line || p.line == q.line && p.col < q.col
+}
+
+// selectionColor is the background of selected text.
+const selectionColor = "24"
+
+// HasSelection reports whether any text is selected.
+func (e *EditorPane) HasSelection() bool {
+	start, end := e.selectionRange()
+	return e.selecting && start != end
+}
+
+// selectionRange returns the start and end of the selection, in order and
+// clamped to the content. The selection runs from the anchor to the cursor.
+func (e *EditorPane) selectionRange() (textPos, textPos) {
+	lines := strings.Split(e.content, "\n")
+	clamp := func(p textPos) textPos {
+		p.line = max(0, min(p.line, len(lines)-1))
+		p.col = max(0, min(p.col, len(lines[p.line])))
+		return p
+	}
+	anchor := clamp(e.selAnchor)
+	cursor := clamp(textPos{e.cursorLine, e.cursorCol})
+	if cursor.before(anchor) {
+		return cursor, anchor
+	}
+	return anchor, cursor
+}
+
+// SelectedText returns the selected text, or "" if nothing is selected.
+func (e *EditorPane) SelectedText() string {
+	if !e.HasSelection() {
+		return ""
+	}
+	start, end := e.selectionRange()
+	lines := strings.Split(e.content, "\n")
+	if start.line == end.line {
+		return lines[start.line][start.col:end.col]
+	}
+	parts := []string{lines[start.line][start.col:]}
+	parts = append(parts, lines[start.line+1:end.line]...)
+	parts = append(parts, lines[end.line][:end.col])
+	return strings.Join(parts, "\n")
+}
+
+// ClearSelection drops the selection, leaving the cursor where it is.
+func (e *EditorPane) ClearSelection() {
+	e.selecting = false
+}
+
+// SelectAll selects the whole content, leaving the cursor at its end.
+func (e *EditorPane) SelectAll() {
+	lines := strings.Split(e.content, "\n")
+	e.selecting = true
+	e.selAnchor = textPos{}
+	e.cursorLine = len(lines) - 1
+	e.cursorCol = len(lines[e.cursorLine])
+	e.adjustScroll()
+}
+
+// extendSelection moves the cursor with move, starting a selection at the
+// cursor if there is none, so the selection grows or shrinks with it.
+func (e *EditorPane) extendSelection(move func()) {
+	if !e.selecting {
+		e.selecting = true
+		e.selAnchor = textPos{e.cursorLine, e.cursorCol}
+	}
+	move()
+	e.adjustScroll()
+}
+
+// handleSelectionKey handles the keys that select text and edit the
+// selected block. It reports whether the key was one of them.
+func (e *EditorPane) handleSelectionKey(keyStr string) bool {
+	lines := strings.Split(e.content, "\n")
+	switch keyStr {
+	case "shift+up":
+		e.extendSelection(func() {
+			if e.cursorLine > 0 {
+				e.cursorLine--
+				e.cursorCol = min(e.cursorCol, len(lines[e.cursorLine]))
+			} else {
+				e.cursorCol = 0
+			}
+		})
+	case "shift+down":
+		e.extendSelection(func() {
+			if e.cursorLine < len(lines)-1 {
+				e.cursorLine++
+				e.cursorCol = min(e.cursorCol, len(lines[e.cursorLine]))
+			} else {
+				e.cursorCol = len(lines[e.cursorLine])
+			}
+		})
+	case "shift+left":
+		e.extendSelection(func() {
+			if e.cursorCol > 0 {
+				e.cursorCol--
+			} else if e.cursorLine > 0 {
+				e.cursorLine--
+				e.cursorCol = len(lines[e.cursorLine])
+			}
+		})
+	case "shift+right":
+		e.extendSelection(func() {
+			if e.cursorLine < len(lines) && e.cursorCol < len(lines[e.cursorLine]) {
+				e.cursorCol++
+			} else if e.cursorLine < len(lines)-1 {
+				e.cursorLine++
+				e.cursorCol = 0
+			}
+		})
+	case "shift+home":
+		e.extendSelection(func() { e.cursorCol = 0 })
+	case "shift+end":
+		e.extendSelection(func() {
+			if e.cursorLine < len(lines) {
+				e.cursorCol = len(lines[e.cursorLine])
+			}
+		})
+	case "ctrl+a":
+		e.SelectAll()
+	case "tab":
+		// Tab only reaches the editor with a selection; see App.Update
+		e.IndentSelection()
+	case "shift+tab":
+		e.OutdentSelection()
+	case "alt+/", "ctrl+_":
+		e.ToggleComment()
+	default:
+		return false
+	}
+	return true
+}
+
+// deleteSelection removes the selected text and puts the cursor where it
+// started. It reports whether there was a selection.
+func (e *EditorPane) deleteSelection() bool {
+	if !e.HasSelection() {
+		e.selecting = false
+		return false
+	}
+	e.saveSnapshot()
+	start, end := e.selectionRange()
+	lines := strings.Split(e.content, "\n")
+	joined := lines[start.line][:start.col] + lines[end.line][end.col:]
+	lines = append(append(lines[:start.line:start.line], joined), lines[end.line+1:]...)
+	e.shiftMarkers(start.line+1, start.line-end.line)
+
+	e.content = strings.Join(lines, "\n")
+	e.cursorLine, e.cursorCol = start.line, start.col
+	e.selecting = false
+	e.updateModified()
+	e.adjustScroll()
+	return true
+}
+
+// InsertText inserts text, which may span lines, at the cursor as a single
+// undo step, replacing the selection if there is one. The cursor ends up
+// after the inserted text.
+func (e *EditorPane) InsertText(text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	e.deleteSelection()
+	if text == "" {
+		return
+	}
+	e.saveSnapshot()
+
+	lines := strings.Split(e.content, "\n")
+	if e.cursorLine >= len(lines) {
+		e.cursorLine = len(lines) - 1
+	}
+	line := lines[e.cursorLine]
+	col := min(e.cursorCol, len(line))
+
+	inserted := strings.Split(text, "\n")
+	last := len(inserted) - 1
+	e.cursorCol = len(inserted[last])
+	inserted[0] = line[:col] + inserted[0]
+	inserted[last] += line[col:]
+
+	lines = append(lines[:e.cursorLine], append(inserted, lines[e.cursorLine+1:]...)...)
+	e.shiftMarkers(e.cursorLine+1, last)
+	e.cursorLine += last
+
+	e.content = strings.Join(lines, "\n")
+	e.updateModified()
+	e.adjustScroll()
+}
+
+// updateModified refreshes the modified flag of the open file.
+func (e *EditorPane) updateModified() {
+	if e.currentFile != nil {
+		e.currentFile.IsModified = (e.content != e.originalContent) || len(e.diffMarkers) > 0
+	}
+}
+
+// blockLines returns the first and last line of the selected block, or the
+// cursor line when nothing is selected. A selection ending at the start of
+// a line leaves that line out.
+func (e *EditorPane) blockLines() (int, int) {
+	if !e.HasSelection() {
+		line := min(e.cursorLine, strings.Count(e.content, "\n"))
+		return line, line
+	}
+	start, end := e.selectionRange()
+	if end.col == 0 && end.line > start.line {
+		end.line--
+	}
+	return start.line, end.line
+}
+
+// editBlock rewrites each line of the selected block, or the cursor line,
+// with edit as a single undo step. edit returns the new line and the
+// column at which bytes were added (positive delta) or removed (negative);
+// the cursor and selection anchor move with the text.
+func (e *EditorPane) editBlock(edit func(line string) (string, int, int)) {
+	first, last := e.blockLines()
+	lines := strings.Split(e.content, "\n")
+	changed := false
+	for i := first; i <= last; i++ {
+		if newLine, _, _ := edit(lines[i]); newLine != lines[i] {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	e.saveSnapshot()
+	move := func(p *textPos) {
+		if p.line < first || p.line > last || p.line >= len(lines) {
+			return
+		}
+		_, at, delta := edit(lines[p.line])
+		if p.col >= at {
+			p.col = max(at, p.col+delta)
+		}
+	}
+	cursor := textPos{e.cursorLine, e.cursorCol}
+	move(&cursor)
+	move(&e.selAnchor)
+	e.cursorLine, e.cursorCol = cursor.line, cursor.col
+
+	for i := first; i <= last; i++ {
+		lines[i], _, _ = edit(lines[i])
+	}
+	e.content = strings.Join(lines, "\n")
+	e.updateModified()
+}
+
+// IndentSelection indents the lines of the selection, or the cursor line,
+// by the tab size. Blank lines are left alone.
+func (e *EditorPane) IndentSelection() {
+	indent := strings.Repeat(" ", e.tabSize)
+	e.editBlock(func(line string) (string, int, int) {
+		if strings.TrimSpace(line) == "" {
+			return line, 0, 0
+		}
+		return indent + line, 0, len(indent)
+	})
+}
+
+// OutdentSelection removes one level of indentation, a tab or up to the tab
+// size in spaces, from the lines of the selection or the cursor line.
+func (e *EditorPane) OutdentSelection() {
+	e.editBlock(func(line string) (string, int, int) {
+		n := 0
+		if strings.HasPrefix(line, "\t") {
+			n = 1
+		} else {
+			for n < len(line) && n < e.tabSize && line[n] == ' ' {
+				n++
+			}
+		}
+		return line[n:], 0, -n
+	})
+}
+
+// commentPrefix returns the line comment marker of the active buffer's
+// language, or "" if it has none.
+func (e *EditorPane) commentPrefix() string {
+	if lang := e.language(); lang != nil {
+		return lang.LineComment
+	}
+	if e.currentFile != nil && e.currentFile.FileType == "shell" {
+		return syntax.Lookup("bash").LineComment
+	}
+	return ""
+}
+
+// ToggleComment comments out the lines of the selection, or the cursor
+// line, with the language's line comment marker, or uncomments them when
+// every non-blank line is already commented. It reports whether the
+// language has line comments.
+func (e *EditorPane) ToggleComment() bool {
+	prefix := e.commentPrefix()
+	if prefix == "" {
+		return false
+	}
+	first, last := e.blockLines()
+	lines := strings.Split(e.content, "\n")
+
+	// Comment at the smallest indentation so the block stays aligned
+	commented := true
+	indent := -1
+	for _, line := range lines[first : last+1] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, prefix) {
+			commented = false
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 0 {
+		return true
+	}
+
+	e.editBlock(func(line string) (string, int, int) {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			return line, 0, 0
+		}
+		if commented {
+			at := len(line) - len(trimmed)
+			marker := prefix
+			if strings.HasPrefix(trimmed, prefix+" ") {
+				marker += " "
+			}
+			return line[:at] + trimmed[len(marker):], at, -len(marker)
+		}
+		return line[:indent] + prefix + " " + line[indent:], indent, len(prefix) + 1
+	})
+	return true
+}
+
+// editorTextLeft is the column of the editor pane where the text starts:
+// the border, the line number and the gutter.
+const editorTextLeft = 1 + 3 + 3
+
+// positionAt returns the content position drawn at column x and row y of
+// the pane, counted from its top-left corner, as the nearest position on
+// the line when x is past its end. ok is false outside the text area.
+func (e *EditorPane) positionAt(x, y int) (textPos, bool) {
+	x -= editorTextLeft
+	if e.BlameVisible() {
+		x -= blameWidth
+	}
+	row := y - 1 + e.scrollOffset
+	if y < 1 || y > e.height-2 || row < 0 {
+		return textPos{}, false
+	}
+	x = max(x, 0)
+
+	width := e.textWidth()
+	lines := strings.Split(e.content, "\n")
+	for i, line := range lines {
+		chunks := max(1, (expandedWidth(line)+width-1)/width)
+		if row >= chunks {
+			row -= chunks
+			continue
+		}
+		return textPos{i, byteColumn(line, row*width+min(x, width-1))}, true
+	}
+	last := len(lines) - 1
+	return textPos{last, len(lines[last])}, true
+}
+
+// textWidth returns the number of text columns per visual line, as View
+// lays them out.
+func (e *EditorPane) textWidth() int {
+	width := e.width - 12
+	if e.BlameVisible() {
+		width -= blameWidth
+	}
+	return max(width, 10)
+}
+
+// expandedWidth returns the number of columns line takes with its tabs
+// expanded to four spaces.
+func expandedWidth(line string) int {
+	return len([]rune(strings.ReplaceAll(line, "\t", "    ")))
+}
+
+// byteColumn returns the byte column of line drawn at the expanded column
+// col, or the line's length when col is past its end.
+func byteColumn(line string, col int) int {
+	width := 0
+	for i, r := range line {
+		w := 1
+		if r == '\t' {
+			w = 4
+		}
+		if col < width+w {
+			return i
+		}
+		width += w
+	}
+	return len(line)
+}
+
+// HandleMouse moves the cursor to a left click at column x and row y of the
+// pane and selects the text dragged over while the button is held.
+func (e *EditorPane) HandleMouse(msg tea.MouseMsg, x, y int) {
+	if msg.Button != tea.MouseButtonLeft {
+		return
+	}
+	pos, ok := e.positionAt(x, y)
+	if !ok {
+		return
+	}
+	switch msg.Action {
+	case tea.MouseActionPress:
+		e.selecting = true
+		e.selAnchor = pos
+	case tea.MouseActionMotion:
+		if !e.selecting {
+			return
+		}
+	default:
+		return
+	}
+	e.cursorLine, e.cursorCol = pos.line, pos.col
+	e.pendingAltD = false
+	e.adjustScroll()
+}
+
+// selectedColumns returns the columns of line i, whose text is raw, that are
+// selected, with tabs expanded as View draws them. from equals to when none
+// are.
+func (e *EditorPane) selectedColumns(i int, raw string) (from, to int) {
+	if !e.HasSelection() {
+		return 0, 0
+	}
+	start, end := e.selectionRange()
+	if i < start.line || i > end.line {
+		return 0, 0
+	}
+	a, b := 0, len(raw)
+	if i == start.line {
+		a = start.col
+	}
+	if i == end.line {
+		b = end.col
+	}
+	return expandedWidth(raw[:a]), expandedWidth(raw[:b])
+}
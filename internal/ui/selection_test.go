@@ -0,0 +1,407 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/types"
+)
+
+// press sends keys of the given types to the editor
+func press(editor *EditorPane, keys ...tea.KeyType) {
+	for _, key := range keys {
+		editor.Update(tea.KeyMsg{Type: key})
+	}
+}
+
+// newSelectionEditor returns a focused editor showing file name with content
+func newSelectionEditor(t *testing.T, name, content string) *EditorPane {
+	t.Helper()
+	editor := newBufferEditor(t, name, content)
+	if err := editor.LoadFile(name); err != nil {
+		t.Fatal(err)
+	}
+	return editor
+}
+
+func TestEditorPane_ShiftArrowsSelect(t *testing.T) {
+	editor := newSelectionEditor(t, "a.txt", "hello world\nsecond line")
+
+	press(editor, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight)
+	if got := editor.SelectedText(); got != "hello" {
+		t.Fatalf("expected 'hello' selected, got %q", got)
+	}
+	press(editor, tea.KeyShiftDown)
+	if got := editor.SelectedText(); got != "hello world\nsecon" {
+		t.Errorf("expected the selection to grow to the next line, got %q", got)
+	}
+	press(editor, tea.KeyShiftUp, tea.KeyShiftLeft)
+	if got := editor.SelectedText(); got != "hell" {
+		t.Errorf("expected the selection to shrink, got %q", got)
+	}
+
+	press(editor, tea.KeyRight)
+	if editor.HasSelection() {
+		t.Error("expected moving without Shift to drop the selection")
+	}
+
+	press(editor, tea.KeyShiftEnd)
+	if got := editor.SelectedText(); got != " world" {
+		t.Errorf("expected Shift+End to select to the end of the line, got %q", got)
+	}
+	press(editor, tea.KeyShiftHome)
+	if got := editor.SelectedText(); got != "hello" {
+		t.Errorf("expected Shift+Home to select back to the line start, got %q", got)
+	}
+
+	press(editor, tea.KeyCtrlA)
+	if got := editor.SelectedText(); got != "hello world\nsecond line" {
+		t.Errorf("expected Ctrl+A to select everything, got %q", got)
+	}
+}
+
+func TestEditorPane_TypingReplacesSelection(t *testing.T) {
+	editor := newSelectionEditor(t, "a.txt", "hello world\nsecond line")
+	press(editor, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight)
+
+	typeInto(editor, "bye")
+	if got := editor.GetContent(); got != "bye world\nsecond line" {
+		t.Errorf("expected typing to replace the selection, got %q", got)
+	}
+	if editor.HasSelection() {
+		t.Error("expected no selection after typing")
+	}
+
+	// Backspace removes a selection across lines and nothing else
+	press(editor, tea.KeyShiftDown, tea.KeyBackspace)
+	if got := editor.GetContent(); got != "byeond line" {
+		t.Errorf("expected backspace to delete only the selection, got %q", got)
+	}
+	if editor.cursorLine != 0 || editor.cursorCol != 3 {
+		t.Errorf("expected the cursor at the selection start, got %d:%d", editor.cursorLine, editor.cursorCol)
+	}
+	if !editor.HasUnsavedChanges() {
+		t.Error("expected the buffer to be modified")
+	}
+}
+
+func TestEditorPane_InsertText(t *testing.T) {
+	editor := newSelectionEditor(t, "a.txt", "one\nfour")
+	press(editor, tea.KeyEnd)
+	editor.cursorCol = 3
+
+	editor.InsertText("\r\ntwo\nthree")
+	if got := editor.GetContent(); got != "one\ntwo\nthree\nfour" {
+		t.Fatalf("got %q", got)
+	}
+	if editor.cursorLine != 2 || editor.cursorCol != 5 {
+		t.Errorf("expected the cursor after the inserted text, got %d:%d", editor.cursorLine, editor.cursorCol)
+	}
+
+	editor.Update(altKey('u'))
+	if got := editor.GetContent(); got != "one\nfour" {
+		t.Errorf("expected one undo step to remove the whole insert, got %q", got)
+	}
+
+	// Bracketed paste arrives as runes and is inserted as one edit
+	editor.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("\nx\ny"), Paste: true})
+	if got := editor.GetContent(); got != "one\nx\ny\nfour" {
+		t.Errorf("expected pasted newlines to be kept, got %q", got)
+	}
+}
+
+func TestEditorPane_IndentAndOutdent(t *testing.T) {
+	editor := newSelectionEditor(t, "a.txt", "a\n\nb\nc")
+	editor.SetTabSize(2)
+	editor.cursorCol = 1
+	press(editor, tea.KeyShiftDown, tea.KeyShiftDown, tea.KeyShiftDown, tea.KeyShiftHome)
+
+	// The selection ends at the start of "c", which is left alone
+	editor.IndentSelection()
+	if got := editor.GetContent(); got != "  a\n\n  b\nc" {
+		t.Fatalf("got %q", got)
+	}
+	if got := editor.SelectedText(); got != "\n\n  b\n" {
+		t.Errorf("expected the selection to move with the text, got %q", got)
+	}
+
+	editor.Update(tea.KeyMsg{Type: tea.KeyTab})
+	press(editor, tea.KeyShiftTab)
+	if got := editor.GetContent(); got != "  a\n\n  b\nc" {
+		t.Errorf("expected Tab and Shift+Tab to cancel out, got %q", got)
+	}
+	press(editor, tea.KeyShiftTab, tea.KeyShiftTab)
+	if got := editor.GetContent(); got != "a\n\nb\nc" {
+		t.Errorf("expected outdenting to stop at the margin, got %q", got)
+	}
+
+	// Without a selection Shift+Tab outdents the cursor line; tabs count as one level
+	editor.SetContent("\t\tx")
+	editor.ClearSelection()
+	editor.cursorLine, editor.cursorCol = 0, 3
+	press(editor, tea.KeyShiftTab)
+	if got := editor.GetContent(); got != "\tx" || editor.cursorCol != 2 {
+		t.Errorf("expected one tab removed with the cursor kept on x, got %q at %d", got, editor.cursorCol)
+	}
+}
+
+func TestEditorPane_ToggleComment(t *testing.T) {
+	editor := newSelectionEditor(t, "main.go", "func f() {\n\tx := 1\n\n\t\ty := 2\n}")
+	editor.cursorLine = 1
+	press(editor, tea.KeyShiftDown, tea.KeyShiftDown, tea.KeyShiftEnd)
+
+	editor.Update(altKey('/'))
+	want := "func f() {\n\t// x := 1\n\n\t// \ty := 2\n}"
+	if got := editor.GetContent(); got != want {
+		t.Fatalf("expected the block commented at its indentation, got %q", got)
+	}
+
+	editor.Update(tea.KeyMsg{Type: tea.KeyCtrlUnderscore})
+	if got := editor.GetContent(); got != "func f() {\n\tx := 1\n\n\t\ty := 2\n}" {
+		t.Errorf("expected the block uncommented, got %q", got)
+	}
+
+	python := newSelectionEditor(t, "a.py", "#x = 1")
+	python.ToggleComment()
+	if got := python.GetContent(); got != "x = 1" {
+		t.Errorf("expected a comment without a space to be removed, got %q", got)
+	}
+
+	markdown := newSelectionEditor(t, "README.md", "# Title")
+	if markdown.ToggleComment() || markdown.GetContent() != "# Title" {
+		t.Error("expected Markdown to have no line comments")
+	}
+}
+
+func TestEditorPane_SelectionDrawn(t *testing.T) {
+	editor := newSelectionEditor(t, "a.txt", "hello\tworld")
+	press(editor, tea.KeyShiftRight, tea.KeyShiftRight)
+
+	if from, to := editor.selectedColumns(0, "hello\tworld"); from != 0 || to != 2 {
+		t.Errorf("expected columns 0-2 selected, got %d-%d", from, to)
+	}
+	editor.cursorCol = 7
+	if from, to := editor.selectedColumns(0, "hello\tworld"); from != 0 || to != 10 {
+		t.Errorf("expected the tab to count four columns, got %d-%d", from, to)
+	}
+	if from, to := editor.selectedColumns(1, ""); from != to {
+		t.Errorf("expected nothing selected past the selection, got %d-%d", from, to)
+	}
+	if !strings.Contains(editor.View(), "hello    world") {
+		t.Error("expected the selected text to be shown")
+	}
+}
+
+func TestEditorPane_MouseSelects(t *testing.T) {
+	editor := newSelectionEditor(t, "a.txt", "first\n\tsecond")
+
+	// Row 1 is the first text line; text starts after the line number gutter
+	editor.HandleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}, editorTextLeft+2, 1)
+	if editor.cursorLine != 0 || editor.cursorCol != 2 || editor.HasSelection() {
+		t.Fatalf("expected a click to move the cursor to 0:2, got %d:%d", editor.cursorLine, editor.cursorCol)
+	}
+
+	// The tab is four columns wide, so column 5 is the 's' of second
+	editor.HandleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion}, editorTextLeft+5, 2)
+	if got := editor.SelectedText(); got != "rst\n\ts" {
+		t.Errorf("expected dragging to select, got %q", got)
+	}
+
+	// Clicks past the end of the content go to its end; the border is ignored
+	editor.HandleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}, 70, 10)
+	if editor.cursorLine != 1 || editor.cursorCol != 7 {
+		t.Errorf("expected the end of the content, got %d:%d", editor.cursorLine, editor.cursorCol)
+	}
+	editor.HandleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}, 3, 0)
+	if editor.cursorLine != 1 || editor.cursorCol != 7 {
+		t.Errorf("expected a click on the border to do nothing, got %d:%d", editor.cursorLine, editor.cursorCol)
+	}
+}
+
+func TestEditorPane_SwitchingTabsDropsSelection(t *testing.T) {
+	editor := newBufferEditor(t, "a.txt", "aaa", "b.txt", "bbb")
+	editor.LoadFile("a.txt")
+	editor.LoadFile("b.txt")
+	press(editor, tea.KeyCtrlA)
+
+	editor.PrevBuffer()
+	if editor.HasSelection() {
+		t.Error("expected the selection to stay with its buffer")
+	}
+}
+
+func TestWriteOSC52(t *testing.T) {
+	t.Setenv("TMUX", "")
+	var out bytes.Buffer
+	writeOSC52(&out, "hi\n")
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("hi\n")) + "\a"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	out.Reset()
+	writeOSC52(&out, "hi\n")
+	if out.String() != "\x1bPtmux;\x1b"+want+"\x1b\\" {
+		t.Errorf("expected the sequence wrapped for tmux, got %q", out.String())
+	}
+}
+
+// newSelectionTestApp returns an App with the editor focused on file name,
+// copying to the clipboard through OSC 52 into the returned buffer
+func newSelectionTestApp(t *testing.T, name, content string) (*App, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("SSH_TTY", "/dev/pts/1")
+	t.Setenv("TMUX", "")
+	var out bytes.Buffer
+	app := newTestApp(t, t.TempDir())
+	app.editorPane = newSelectionEditor(t, name, content)
+	app.output = &out
+	return app, &out
+}
+
+// copied runs the copy command returned by the App and returns the text it
+// wrote through OSC 52. Nothing must reach the output before the command runs.
+func copied(t *testing.T, cmd tea.Cmd, out *bytes.Buffer) string {
+	t.Helper()
+	if out.Len() > 0 {
+		t.Fatalf("expected the OSC 52 sequence written by the command, not by Update: %q", out.String())
+	}
+	if cmd == nil {
+		t.Fatal("expected a command that writes the OSC 52 sequence")
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("unexpected message from the copy command: %#v", msg)
+	}
+	seq := strings.TrimSuffix(strings.TrimPrefix(out.String(), "\x1b]52;c;"), "\a")
+	text, err := base64.StdEncoding.DecodeString(seq)
+	if err != nil {
+		t.Fatalf("bad OSC 52 sequence %q: %v", out.String(), err)
+	}
+	out.Reset()
+	return string(text)
+}
+
+func TestApp_CopyAndCutSelection(t *testing.T) {
+	app, out := newSelectionTestApp(t, "a.txt", "one two\nthree")
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if got := copied(t, cmd, out); got != "one two" {
+		t.Errorf("expected the cursor line copied without a selection, got %q", got)
+	}
+
+	press(app.editorPane, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight, tea.KeyShiftRight)
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if got := copied(t, cmd, out); got != "one " {
+		t.Errorf("expected the selection copied, got %q", got)
+	}
+
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if got := copied(t, cmd, out); got != "one " {
+		t.Errorf("expected the selection cut, got %q", got)
+	}
+	if got := app.editorPane.GetContent(); got != "two\nthree" || app.statusMessage != "Cut to clipboard" {
+		t.Errorf("expected the selection removed, got %q (%s)", got, app.statusMessage)
+	}
+	if openName(app.editorPane) != "a.txt" {
+		t.Error("expected cutting to keep the file open")
+	}
+
+	// Without a selection Ctrl+X still closes the file
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if openName(app.editorPane) != "" {
+		t.Error("expected Ctrl+X to close the file")
+	}
+}
+
+func TestApp_TabIndentsSelection(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "x\ny")
+
+	press(app.editorPane, tea.KeyCtrlA)
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := app.editorPane.GetContent(); got != "    x\n    y" || app.activePane != types.EditorPaneType {
+		t.Fatalf("expected Tab to indent the selection, got %q", got)
+	}
+
+	press(app.editorPane, tea.KeyRight)
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if app.activePane != types.AIPaneType {
+		t.Error("expected Tab without a selection to move to the AI pane")
+	}
+}
+
+func TestApp_SendSelectionToAI(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "alpha\nbeta\ngamma")
+
+	app.Update(altKey('s'))
+	if app.selectionContext != "" || !strings.Contains(app.statusMessage, "Select text") {
+		t.Fatalf("expected a hint without a selection, got %q", app.statusMessage)
+	}
+
+	press(app.editorPane, tea.KeyShiftDown, tea.KeyShiftRight, tea.KeyShiftRight)
+	app.Update(altKey('s'))
+	if app.selectionContext != "alpha\nbe" || app.activePane != types.AIPaneType {
+		t.Fatalf("expected the selection attached and the AI input focused, got %q", app.selectionContext)
+	}
+	if !strings.Contains(app.statusMessage, "2 lines") {
+		t.Errorf("expected the status to mention the selection, got %q", app.statusMessage)
+	}
+
+	if got := app.takeChatContext("whole file"); got != "alpha\nbe" {
+		t.Errorf("expected the selection as context, got %q", got)
+	}
+	if got := app.takeChatContext("whole file"); got != "whole file" {
+		t.Errorf("expected the selection to be used once, got %q", got)
+	}
+}
+
+func TestApp_MouseFocusesEditor(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "one\ntwo\nthree")
+	app.editorPane.SetSize(60, 20)
+	app.activePane = types.AIPaneType
+	app.editorPane.focused = false
+
+	app.Update(tea.MouseMsg{X: editorTextLeft + 1, Y: app.editorTop() + 2, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if app.activePane != types.EditorPaneType || !app.editorPane.focused {
+		t.Fatal("expected a click in the editor to focus it")
+	}
+	if app.editorPane.cursorLine != 1 || app.editorPane.cursorCol != 1 {
+		t.Errorf("expected the cursor at 1:1, got %d:%d", app.editorPane.cursorLine, app.editorPane.cursorCol)
+	}
+
+	// The wheel scrolls like the arrow keys
+	app.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	if app.editorPane.cursorLine != 2 {
+		t.Errorf("expected the wheel to move down a line, got %d", app.editorPane.cursorLine)
+	}
+
+	// The editor starts below the header and the editor title bar
+	view := strings.Split(app.View(), "\n")
+	if top := app.editorTop(); top >= len(view) || !strings.Contains(view[top], "╭") || strings.Contains(view[top-1], "╭") {
+		t.Errorf("expected the editor border on row %d of the view", top)
+	}
+
+	// Clicks are ignored while a dialog is open
+	app.showHelp = true
+	app.Update(tea.MouseMsg{X: editorTextLeft, Y: app.editorTop() + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if app.editorPane.cursorLine != 2 {
+		t.Error("expected the click to be ignored under the help dialog")
+	}
+}
+
+func TestApp_ToggleMouse(t *testing.T) {
+	app, _ := newSelectionTestApp(t, "a.txt", "one")
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyF9})
+	if !app.mouseReleased || cmd == nil || fmt.Sprintf("%T", cmd()) != fmt.Sprintf("%T", tea.DisableMouse()) {
+		t.Fatal("expected F9 to release the mouse")
+	}
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyF9})
+	if app.mouseReleased || cmd == nil || fmt.Sprintf("%T", cmd()) != fmt.Sprintf("%T", tea.EnableMouseCellMotion()) {
+		t.Fatal("expected a second F9 to capture the mouse again")
+	}
+}
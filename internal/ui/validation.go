@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/validation"
+)
+
+// ValidationMsg carries output of the background validation pipeline: a
+// chat notification, or the session of a finished validation whose errors
+// are marked in the editor gutter.
+type ValidationMsg struct {
+	Notification string
+	Session      *validation.ValidationSession
+}
+
+// lineMarks maps 0-indexed editor lines to the validation error on them
+type lineMarks map[int]string
+
+// validationBuffer is the number of validation messages that may be pending
+// before further ones are dropped
+const validationBuffer = 100
+
+// newValidationPipeline creates a validation pipeline whose chat messages and
+// finished sessions are delivered on ch.
+func newValidationPipeline(ch chan ValidationMsg) *validation.Pipeline {
+	pipeline := validation.NewPipeline()
+	send := func(msg ValidationMsg) {
+		// Never block the validation goroutine on a busy UI
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+	pipeline.GetChatPanelIntegration().SetNotifier(func(message string) {
+		send(ValidationMsg{Notification: strings.TrimRight(message, "\n")})
+	})
+	pipeline.OnValidationComplete(func(session *validation.ValidationSession) {
+		send(ValidationMsg{Session: session})
+	})
+	return pipeline
+}
+
+// waitForValidation returns a command that delivers the next validation
+// message. Update re-issues it after handling each message.
+func waitForValidation(ch chan ValidationMsg) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// SetValidator sets the pipeline that validates files saved from the editor.
+// A nil pipeline disables validation.
+func (e *EditorPane) SetValidator(pipeline *validation.Pipeline) {
+	e.validator = pipeline
+}
+
+// ApplyValidationSession replaces the gutter marks of every file validated
+// in session with the errors it found.
+func (e *EditorPane) ApplyValidationSession(session *validation.ValidationSession) {
+	if session == nil {
+		return
+	}
+	if e.diagnostics == nil {
+		e.diagnostics = make(map[string]lineMarks)
+	}
+	for _, file := range session.Files {
+		delete(e.diagnostics, filepath.Clean(file))
+	}
+
+	for _, result := range session.Results {
+		for _, verr := range result.Errors {
+			path := resolveDiagnosticPath(verr.File, result.Files)
+			if path == "" || verr.Line < 1 {
+				continue
+			}
+			lines := e.diagnostics[path]
+			if lines == nil {
+				lines = make(lineMarks)
+				e.diagnostics[path] = lines
+			}
+			if _, exists := lines[verr.Line-1]; !exists {
+				lines[verr.Line-1] = verr.Message
+			}
+		}
+	}
+}
+
+// DiagnosticAt returns the validation error marked on a 0-indexed line of the
+// open file, or "" when there is none.
+func (e *EditorPane) DiagnosticAt(line int) string {
+	return e.currentDiagnostics()[line]
+}
+
+// currentDiagnostics returns the gutter marks of the open file
+func (e *EditorPane) currentDiagnostics() lineMarks {
+	if e.currentFile == nil || len(e.diagnostics) == 0 {
+		return nil
+	}
+	return e.diagnostics[e.currentFilePath()]
+}
+
+// currentFilePath returns the absolute path of the open file
+func (e *EditorPane) currentFilePath() string {
+	if e.currentFile == nil {
+		return ""
+	}
+	return e.fileManager.ResolvePath(e.currentFile.Filepath)
+}
+
+// shiftDiagnostics moves the open file's gutter marks when rows are inserted
+// or deleted, dropping marks on deleted rows.
+func (e *EditorPane) shiftDiagnostics(fromLine int, amount int) {
+	lines := e.currentDiagnostics()
+	if len(lines) == 0 {
+		return
+	}
+	shifted := make(lineMarks, len(lines))
+	for idx, msg := range lines {
+		switch {
+		case idx < fromLine:
+			shifted[idx] = msg
+		case amount < 0 && idx < fromLine-amount:
+			// dropped line
+		default:
+			shifted[idx+amount] = msg
+		}
+	}
+	e.diagnostics[e.currentFilePath()] = shifted
+}
+
+// resolveDiagnosticPath maps the file named in a validator error to an
+// absolute path. Validators report paths relative to the directory they ran
+// in, so relative names are matched against the validated files.
+func resolveDiagnosticPath(errFile string, validated []string) string {
+	if errFile == "" {
+		return ""
+	}
+	if filepath.IsAbs(errFile) {
+		return filepath.Clean(errFile)
+	}
+
+	rel := filepath.Clean(errFile)
+	suffix := string(filepath.Separator) + rel
+	for _, file := range validated {
+		if strings.HasSuffix(filepath.Clean(file), suffix) {
+			return filepath.Clean(file)
+		}
+	}
+	for _, file := range validated {
+		candidate := filepath.Join(filepath.Dir(file), rel)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
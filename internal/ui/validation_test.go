@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/terminal-intelligence/internal/filemanager"
+	"github.com/user/terminal-intelligence/internal/validation"
+)
+
+// newValidatedEditor returns an editor with main.go of the given content loaded
+func newValidatedEditor(t *testing.T, content string) (*EditorPane, string) {
+	t.Helper()
+	dir := t.TempDir()
+	fm := filemanager.NewFileManager(dir)
+	if err := fm.CreateFile("main.go", content); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	editor := NewEditorPane(fm)
+	if err := editor.LoadFile("main.go"); err != nil {
+		t.Fatalf("Failed to load file: %v", err)
+	}
+	editor.SetSize(80, 20)
+	return editor, filepath.Join(dir, "main.go")
+}
+
+// failedSession returns a session in which path failed with errors on lines
+func failedSession(path string, errFile string, lines ...int) *validation.ValidationSession {
+	result := validation.ValidationResult{Language: validation.LanguageGo, Files: []string{path}}
+	for _, line := range lines {
+		result.Errors = append(result.Errors, validation.ValidationError{File: errFile, Line: line, Message: "undefined: x"})
+	}
+	return &validation.ValidationSession{Files: []string{path}, Results: []validation.ValidationResult{result}}
+}
+
+func TestApplyValidationSession_MarksGutter(t *testing.T) {
+	editor, path := newValidatedEditor(t, "package main\n\nfunc main() {\n\tx()\n}\n")
+
+	editor.ApplyValidationSession(failedSession(path, "./main.go", 4))
+
+	if got := editor.DiagnosticAt(3); got != "undefined: x" {
+		t.Errorf("Expected error on line 4, got %q", got)
+	}
+	if got := editor.DiagnosticAt(0); got != "" {
+		t.Errorf("Expected no error on line 1, got %q", got)
+	}
+	if !strings.Contains(editor.View(), "●") {
+		t.Error("Expected error marker in the gutter")
+	}
+
+	// A clean validation of the same file clears the marks
+	editor.ApplyValidationSession(failedSession(path, path))
+	if got := editor.DiagnosticAt(3); got != "" {
+		t.Errorf("Expected marks to be cleared, got %q", got)
+	}
+	if strings.Contains(editor.View(), "●") {
+		t.Error("Expected no error marker after a clean validation")
+	}
+}
+
+func TestShiftDiagnostics(t *testing.T) {
+	editor, path := newValidatedEditor(t, "a\nb\nc\nd\ne\n")
+	editor.ApplyValidationSession(failedSession(path, path, 1, 3, 5))
+
+	// Two rows inserted after line 2
+	editor.shiftDiagnostics(2, 2)
+	for line, want := range map[int]bool{0: true, 2: false, 4: true, 6: true} {
+		if got := editor.DiagnosticAt(line) != ""; got != want {
+			t.Errorf("after insert, line %d marked = %v, want %v", line, got, want)
+		}
+	}
+
+	// Deleting rows 3-4 drops the mark on them
+	editor.shiftDiagnostics(3, -2)
+	for line, want := range map[int]bool{0: true, 2: false, 3: false, 4: true} {
+		if got := editor.DiagnosticAt(line) != ""; got != want {
+			t.Errorf("after delete, line %d marked = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestResolveDiagnosticPath(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "cmd", "main.go")
+	helper := filepath.Join(dir, "cmd", "helper.go")
+	if err := os.MkdirAll(filepath.Dir(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(helper, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		errFile string
+		want    string
+	}{
+		{"", ""},
+		{main, main},
+		{"./main.go", main},
+		{"cmd/main.go", main},
+		{"helper.go", helper},
+		{"missing.go", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveDiagnosticPath(tt.errFile, []string{main}); got != tt.want {
+			t.Errorf("resolveDiagnosticPath(%q) = %q, want %q", tt.errFile, got, tt.want)
+		}
+	}
+}
+
+func TestSaveFile_QueuesValidation(t *testing.T) {
+	ch := make(chan ValidationMsg, validationBuffer)
+	pipeline := newValidationPipeline(ch)
+	defer pipeline.Shutdown()
+
+	editor, path := newValidatedEditor(t, "package main\n")
+	editor.SetValidator(pipeline)
+	editor.SetContent("package main\n\nfunc main() {}\n")
+	if err := editor.SaveFile(); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	timeout := time.After(30 * time.Second)
+	for {
+		select {
+		case msg := <-ch:
+			if msg.Session == nil {
+				continue
+			}
+			if len(msg.Session.Files) != 1 || msg.Session.Files[0] != path {
+				t.Errorf("Expected %s to be validated, got %v", path, msg.Session.Files)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Expected saved file to be validated")
+		}
+	}
+}
+
+func TestWaitForValidation_NilChannel(t *testing.T) {
+	if waitForValidation(nil) != nil {
+		t.Error("Expected nil command for a nil channel")
+	}
+}
@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BashValidator implements validation for shell scripts. Syntax is checked
+// with bash -n, and scripts without syntax errors are linted with shellcheck
+// when it is installed.
+type BashValidator struct {
+	*BaseValidator
+	errorPattern *regexp.Regexp
+}
+
+// shellcheckComment is an entry of shellcheck -f json output
+type shellcheckComment struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Level   string `json:"level"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewBashValidator creates a new Bash validator
+func NewBashValidator() *BashValidator {
+	config := ValidatorConfig{
+		Command:      "bash",
+		Args:         []string{"-n"},
+		Timeout:      10 * time.Second,
+		ErrorPattern: `^(.+): line (\d+): (.+)$`,
+	}
+
+	info := ValidatorInfo{
+		Name:    "Bash",
+		Version: "1.0.0",
+		Command: "bash",
+	}
+
+	return &BashValidator{
+		BaseValidator: NewBaseValidator(config, info),
+		errorPattern:  regexp.MustCompile(config.ErrorPattern),
+	}
+}
+
+// Execute runs bash -n, then shellcheck, for each of the given files
+func (bv *BashValidator) Execute(files []string) (ValidationResult, error) {
+	if len(files) == 0 {
+		return ValidationResult{}, fmt.Errorf("no files provided for validation")
+	}
+
+	if _, err := bv.LookupTool(bv.config.Command); err != nil {
+		return ValidationResult{}, err
+	}
+	// shellcheck is optional
+	shellcheck, _ := exec.LookPath("shellcheck")
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	var allErrors []ValidationError
+	var allWarnings []ValidationError
+	var combinedStderr strings.Builder
+	overallExitCode := 0
+
+	for _, file := range files {
+		args := append(append([]string{}, bv.config.Args...), file)
+
+		_, stderr, exitCode, err := bv.BaseValidator.ExecuteCommand(ctx, bv.config.Command, args, "")
+		if err != nil {
+			return ValidationResult{}, fmt.Errorf("failed to execute bash validation for %s: %w", file, err)
+		}
+		if stderr != "" {
+			combinedStderr.WriteString(stderr)
+			combinedStderr.WriteString("\n")
+		}
+		if exitCode != 0 {
+			overallExitCode = exitCode
+		}
+
+		syntaxErrors := bv.parseErrors(stderr)
+		allErrors = append(allErrors, syntaxErrors...)
+		if len(syntaxErrors) > 0 || exitCode != 0 || shellcheck == "" {
+			continue
+		}
+
+		// shellcheck exits non-zero whenever it has comments, so only its
+		// error-level comments fail validation
+		stdout, _, _, err := bv.BaseValidator.ExecuteCommand(ctx, shellcheck, []string{"-f", "json", file}, "")
+		if err != nil {
+			return ValidationResult{}, fmt.Errorf("failed to execute shellcheck for %s: %w", file, err)
+		}
+		errors, warnings := bv.parseShellcheck(stdout)
+		allErrors = append(allErrors, errors...)
+		allWarnings = append(allWarnings, warnings...)
+	}
+
+	return bv.BaseValidator.CreateValidationResult(
+		LanguageBash,
+		files,
+		time.Since(startTime),
+		"",
+		combinedStderr.String(),
+		overallExitCode,
+		allErrors,
+		allWarnings,
+	), nil
+}
+
+// parseErrors parses bash -n output of the form
+// "script.sh: line 3: syntax error near unexpected token `fi'"
+func (bv *BashValidator) parseErrors(output string) []ValidationError {
+	var errors []ValidationError
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		matches := bv.errorPattern.FindStringSubmatch(line)
+		if len(matches) != 4 {
+			continue
+		}
+		// bash follows each error with a copy of the offending line in quotes
+		if strings.HasPrefix(matches[3], "`") {
+			continue
+		}
+
+		lineNum, _ := strconv.Atoi(matches[2])
+		errors = append(errors, ValidationError{
+			File:     matches[1],
+			Line:     lineNum,
+			Message:  matches[3],
+			Severity: SeverityError,
+		})
+	}
+
+	return errors
+}
+
+// parseShellcheck parses shellcheck -f json output. Warnings, info and style
+// comments are reported as warnings.
+func (bv *BashValidator) parseShellcheck(output string) ([]ValidationError, []ValidationError) {
+	var errors []ValidationError
+	var warnings []ValidationError
+
+	var comments []shellcheckComment
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &comments); err != nil {
+		return errors, warnings
+	}
+
+	for _, c := range comments {
+		validationError := ValidationError{
+			File:    c.File,
+			Line:    c.Line,
+			Column:  c.Column,
+			Message: c.Message,
+			Code:    fmt.Sprintf("SC%d", c.Code),
+		}
+		switch c.Level {
+		case "error":
+			validationError.Severity = SeverityError
+			errors = append(errors, validationError)
+		case "warning":
+			validationError.Severity = SeverityWarning
+			warnings = append(warnings, validationError)
+		default:
+			validationError.Severity = SeverityInfo
+			warnings = append(warnings, validationError)
+		}
+	}
+
+	return errors, warnings
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestBashValidator_ParseErrors(t *testing.T) {
+	validator := NewBashValidator()
+
+	output := "build.sh: line 3: syntax error near unexpected token `fi'\nbuild.sh: line 3: `fi fi'\n"
+	errors := validator.parseErrors(output)
+
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	want := ValidationError{File: "build.sh", Line: 3, Message: "syntax error near unexpected token `fi'", Severity: SeverityError}
+	if errors[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, errors[0])
+	}
+}
+
+func TestBashValidator_ParseShellcheck(t *testing.T) {
+	validator := NewBashValidator()
+
+	output := `[{"file":"build.sh","line":4,"endLine":4,"column":6,"endColumn":10,"level":"warning","code":2086,"message":"Double quote to prevent globbing and word splitting.","fix":null},
+{"file":"build.sh","line":7,"column":1,"level":"error","code":1089,"message":"Parsing stopped here."},
+{"file":"build.sh","line":9,"column":3,"level":"style","code":2006,"message":"Use $(...) notation instead of legacy backticks."}]`
+	errors, warnings := validator.parseShellcheck(output)
+
+	if len(errors) != 1 || errors[0].Code != "SC1089" || errors[0].Line != 7 {
+		t.Errorf("Unexpected errors: %+v", errors)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %d: %v", len(warnings), warnings)
+	}
+	want := ValidationError{File: "build.sh", Line: 4, Column: 6, Message: "Double quote to prevent globbing and word splitting.", Severity: SeverityWarning, Code: "SC2086"}
+	if warnings[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, warnings[0])
+	}
+	if warnings[1].Severity != SeverityInfo {
+		t.Errorf("Expected style comment to be info, got %s", warnings[1].Severity)
+	}
+}
+
+func TestBashValidator_Execute(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	tmpDir := t.TempDir()
+	valid := filepath.Join(tmpDir, "valid.sh")
+	invalid := filepath.Join(tmpDir, "invalid.sh")
+	if err := os.WriteFile(valid, []byte("#!/bin/bash\necho \"hi\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("#!/bin/bash\nif true; then\n  echo hi\nfi fi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewBashValidator()
+	result, err := validator.Execute([]string{valid, invalid})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected failure for invalid script")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].File != invalid || result.Errors[0].Line != 4 {
+		t.Errorf("Unexpected errors: %+v", result.Errors)
+	}
+}
@@ -3,11 +3,37 @@ package validation
 import (
 	"fmt"
 	"strings"
+	"sync"
 )
 
+// maxStoredMessages is the number of recent messages ChatPanelIntegration
+// keeps; the pipeline lives as long as the app, so older ones are dropped
+const maxStoredMessages = 100
+
 // ChatPanelIntegration handles displaying validation status and results in the AI chat interface
 type ChatPanelIntegration struct {
-	messages []string // Store messages for testing purposes
+	mu       sync.Mutex
+	messages []string     // The most recent messages, for testing purposes
+	notify   func(string) // Delivers each message to the chat panel (optional)
+}
+
+// languageNames maps languages to the names shown in the chat panel
+var languageNames = map[Language]string{
+	LanguageGo:         "Go",
+	LanguagePython:     "Python",
+	LanguageJavaScript: "JavaScript",
+	LanguageTypeScript: "TypeScript",
+	LanguageRust:       "Rust",
+	LanguageBash:       "Bash",
+	LanguageJava:       "Java",
+}
+
+// LanguageName returns the display name of a language
+func LanguageName(language Language) string {
+	if name, ok := languageNames[language]; ok {
+		return name
+	}
+	return string(language)
 }
 
 // NewChatPanelIntegration creates a new ChatPanelIntegration instance
@@ -21,12 +47,7 @@ func NewChatPanelIntegration() *ChatPanelIntegration {
 func (cpi *ChatPanelIntegration) ShowValidationStart(files []string, language Language) {
 	var message strings.Builder
 
-	languageName := string(language)
-	if language == LanguageGo {
-		languageName = "Go"
-	} else if language == LanguagePython {
-		languageName = "Python"
-	}
+	languageName := LanguageName(language)
 
 	message.WriteString(fmt.Sprintf("🔍 Validating %s code in %d file", languageName, len(files)))
 	if len(files) != 1 {
@@ -43,12 +64,7 @@ func (cpi *ChatPanelIntegration) ShowValidationStart(files []string, language La
 
 // ShowValidationProgress displays validation progress for long-running validations
 func (cpi *ChatPanelIntegration) ShowValidationProgress(language Language, duration float64) {
-	languageName := string(language)
-	if language == LanguageGo {
-		languageName = "Go"
-	} else if language == LanguagePython {
-		languageName = "Python"
-	}
+	languageName := LanguageName(language)
 
 	message := fmt.Sprintf("⏳ Compiling %s package... (%.1fs)", languageName, duration)
 	cpi.addMessage(message)
@@ -56,12 +72,7 @@ func (cpi *ChatPanelIntegration) ShowValidationProgress(language Language, durat
 
 // ShowValidationSuccess displays a success message with duration
 func (cpi *ChatPanelIntegration) ShowValidationSuccess(result ValidationResult) {
-	languageName := string(result.Language)
-	if result.Language == LanguageGo {
-		languageName = "Go"
-	} else if result.Language == LanguagePython {
-		languageName = "Python"
-	}
+	languageName := LanguageName(result.Language)
 
 	durationSeconds := result.Duration.Seconds()
 	message := fmt.Sprintf("✅ %s compilation successful (%.1fs)", languageName, durationSeconds)
@@ -72,19 +83,16 @@ func (cpi *ChatPanelIntegration) ShowValidationSuccess(result ValidationResult)
 func (cpi *ChatPanelIntegration) ShowValidationFailure(result ValidationResult) {
 	var message strings.Builder
 
-	languageName := string(result.Language)
-	if result.Language == LanguageGo {
-		languageName = "Go"
-	} else if result.Language == LanguagePython {
-		languageName = "Python"
-	}
+	languageName := LanguageName(result.Language)
 
 	durationSeconds := result.Duration.Seconds()
 	message.WriteString(fmt.Sprintf("❌ %s compilation failed (%.1fs)\n\n", languageName, durationSeconds))
 
 	// Display each error with file path, line number, and message
 	for _, err := range result.Errors {
-		if err.Column > 0 {
+		if err.File == "" {
+			message.WriteString(fmt.Sprintf("%s\n", err.Message))
+		} else if err.Column > 0 {
 			message.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", err.File, err.Line, err.Column, err.Message))
 		} else {
 			message.WriteString(fmt.Sprintf("%s:%d: %s\n", err.File, err.Line, err.Message))
@@ -112,12 +120,8 @@ func (cpi *ChatPanelIntegration) ShowUnsupportedLanguage(files []string, support
 	message.WriteString("\nSupported languages: ")
 	var langNames []string
 	for _, lang := range supportedLanguages {
-		if lang == LanguageGo {
-			langNames = append(langNames, "Go")
-		} else if lang == LanguagePython {
-			langNames = append(langNames, "Python")
-		} else if lang != LanguageUnsupported {
-			langNames = append(langNames, string(lang))
+		if lang != LanguageUnsupported {
+			langNames = append(langNames, LanguageName(lang))
 		}
 	}
 	message.WriteString(strings.Join(langNames, ", "))
@@ -125,25 +129,64 @@ func (cpi *ChatPanelIntegration) ShowUnsupportedLanguage(files []string, support
 	cpi.addMessage(message.String())
 }
 
-// GetMessages returns all messages (for testing purposes)
+// ShowToolNotInstalled reports that files were not validated because the
+// language's validator tool is missing
+func (cpi *ChatPanelIntegration) ShowToolNotInstalled(files []string, language Language, tool string) {
+	var message strings.Builder
+
+	message.WriteString(fmt.Sprintf("⚠️ Skipped %s validation: %s is not installed\n", LanguageName(language), tool))
+	for _, file := range files {
+		message.WriteString(fmt.Sprintf("- %s\n", file))
+	}
+
+	cpi.addMessage(message.String())
+}
+
+// SetNotifier sets the function that delivers messages to the chat panel.
+// It is called from the validation goroutine, so it must not touch UI state
+// directly.
+func (cpi *ChatPanelIntegration) SetNotifier(notify func(string)) {
+	cpi.mu.Lock()
+	defer cpi.mu.Unlock()
+	cpi.notify = notify
+}
+
+// GetMessages returns the stored messages, oldest first (for testing purposes)
 func (cpi *ChatPanelIntegration) GetMessages() []string {
-	return cpi.messages
+	cpi.mu.Lock()
+	defer cpi.mu.Unlock()
+	messages := make([]string, len(cpi.messages))
+	copy(messages, cpi.messages)
+	return messages
 }
 
 // ClearMessages clears all messages (for testing purposes)
 func (cpi *ChatPanelIntegration) ClearMessages() {
+	cpi.mu.Lock()
+	defer cpi.mu.Unlock()
 	cpi.messages = make([]string, 0)
 }
 
-// addMessage adds a message to the internal list and would display it in the chat panel
+// addMessage adds a message to the internal list and forwards it to the
+// chat panel when a notifier is set
 func (cpi *ChatPanelIntegration) addMessage(message string) {
+	cpi.mu.Lock()
 	cpi.messages = append(cpi.messages, message)
-	// In a real implementation, this would send the message to the chat panel UI
-	// For now, we just store it for testing
+	if len(cpi.messages) > maxStoredMessages {
+		cpi.messages = append(cpi.messages[:0], cpi.messages[len(cpi.messages)-maxStoredMessages:]...)
+	}
+	notify := cpi.notify
+	cpi.mu.Unlock()
+
+	if notify != nil {
+		notify(message)
+	}
 }
 
 // GetLastMessage returns the most recent message (for testing purposes)
 func (cpi *ChatPanelIntegration) GetLastMessage() string {
+	cpi.mu.Lock()
+	defer cpi.mu.Unlock()
 	if len(cpi.messages) == 0 {
 		return ""
 	}
@@ -22,6 +22,22 @@ func TestNewChatPanelIntegration(t *testing.T) {
 	}
 }
 
+func TestChatPanelIntegration_KeepsRecentMessages(t *testing.T) {
+	cpi := NewChatPanelIntegration()
+
+	for i := 0; i < maxStoredMessages+10; i++ {
+		cpi.ShowValidationProgress(LanguageGo, float64(i))
+	}
+
+	messages := cpi.GetMessages()
+	if len(messages) != maxStoredMessages {
+		t.Fatalf("Expected %d messages, got %d", maxStoredMessages, len(messages))
+	}
+	if !strings.Contains(messages[0], "(10.0s)") || !strings.Contains(cpi.GetLastMessage(), "(109.0s)") {
+		t.Errorf("Expected the oldest messages dropped, got %q ... %q", messages[0], cpi.GetLastMessage())
+	}
+}
+
 func TestShowValidationStart_SingleFile(t *testing.T) {
 	cpi := NewChatPanelIntegration()
 
@@ -509,3 +525,20 @@ func TestMultipleLanguageNames(t *testing.T) {
 		})
 	}
 }
+
+func TestSetNotifier_ForwardsMessages(t *testing.T) {
+	cpi := NewChatPanelIntegration()
+
+	var forwarded []string
+	cpi.SetNotifier(func(message string) {
+		forwarded = append(forwarded, message)
+	})
+	cpi.ShowValidationStart([]string{"main.go"}, LanguageGo)
+
+	if len(forwarded) != 1 {
+		t.Fatalf("Expected 1 forwarded message, got %d", len(forwarded))
+	}
+	if forwarded[0] != cpi.GetLastMessage() {
+		t.Errorf("Forwarded %q, stored %q", forwarded[0], cpi.GetLastMessage())
+	}
+}
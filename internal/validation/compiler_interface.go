@@ -21,6 +21,11 @@ func NewCompilerInterface() *CompilerInterface {
 	// Register default validators
 	ci.registerValidator(LanguageGo, NewGoValidator())
 	ci.registerValidator(LanguagePython, NewPythonValidator())
+	ci.registerValidator(LanguageJavaScript, NewJavaScriptValidator())
+	ci.registerValidator(LanguageTypeScript, NewTypeScriptValidator())
+	ci.registerValidator(LanguageRust, NewRustValidator())
+	ci.registerValidator(LanguageBash, NewBashValidator())
+	ci.registerValidator(LanguageJava, NewJavaValidator())
 
 	return ci
 }
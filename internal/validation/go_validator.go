@@ -47,6 +47,10 @@ func (gv *GoValidator) Execute(files []string) (ValidationResult, error) {
 		return ValidationResult{}, fmt.Errorf("no files provided for validation")
 	}
 
+	if _, err := gv.LookupTool(gv.config.Command); err != nil {
+		return ValidationResult{}, err
+	}
+
 	ctx := context.Background()
 	startTime := time.Now()
 
@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JavaValidator implements validation for Java code
+type JavaValidator struct {
+	*BaseValidator
+	errorPattern   *regexp.Regexp
+	packagePattern *regexp.Regexp
+}
+
+// NewJavaValidator creates a new Java validator
+func NewJavaValidator() *JavaValidator {
+	config := ValidatorConfig{
+		Command:      "javac",
+		Args:         []string{"-proc:none", "-implicit:none"},
+		Timeout:      60 * time.Second,
+		ErrorPattern: `^(.+\.java):(\d+): (error|warning): (.+)$`,
+	}
+
+	info := ValidatorInfo{
+		Name:    "Java",
+		Version: "1.0.0",
+		Command: "javac",
+	}
+
+	return &JavaValidator{
+		BaseValidator:  NewBaseValidator(config, info),
+		errorPattern:   regexp.MustCompile(config.ErrorPattern),
+		packagePattern: regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`),
+	}
+}
+
+// Execute compiles the given files with javac. Class files go to a temporary
+// directory, and the source roots of the files are put on the source path so
+// that references to other classes of the project resolve.
+func (jv *JavaValidator) Execute(files []string) (ValidationResult, error) {
+	if len(files) == 0 {
+		return ValidationResult{}, fmt.Errorf("no files provided for validation")
+	}
+
+	if _, err := jv.LookupTool(jv.config.Command); err != nil {
+		return ValidationResult{}, err
+	}
+
+	outDir, err := os.MkdirTemp("", "ti-javac-")
+	if err != nil {
+		return ValidationResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	args := append([]string{}, jv.config.Args...)
+	args = append(args, "-d", outDir, "-sourcepath", jv.sourcePath(files))
+	args = append(args, files...)
+
+	stdout, stderr, exitCode, err := jv.BaseValidator.ExecuteCommand(ctx, jv.config.Command, args, "")
+	if err != nil {
+		return ValidationResult{}, fmt.Errorf("failed to execute javac: %w", err)
+	}
+
+	errors, warnings := jv.parseErrors(stderr)
+
+	return jv.BaseValidator.CreateValidationResult(
+		LanguageJava,
+		files,
+		time.Since(startTime),
+		stdout,
+		stderr,
+		exitCode,
+		errors,
+		warnings,
+	), nil
+}
+
+// sourcePath returns the source roots of files joined for -sourcepath. The
+// root of a file is its directory minus the path of its package.
+func (jv *JavaValidator) sourcePath(files []string) string {
+	seen := make(map[string]bool)
+	var roots []string
+	for _, file := range files {
+		root := filepath.Dir(file)
+		if content, err := os.ReadFile(file); err == nil {
+			if matches := jv.packagePattern.FindSubmatch(content); matches != nil {
+				pkgDir := filepath.FromSlash(strings.ReplaceAll(string(matches[1]), ".", "/"))
+				if strings.HasSuffix(root, string(filepath.Separator)+pkgDir) {
+					root = strings.TrimSuffix(root, string(filepath.Separator)+pkgDir)
+				}
+			}
+		}
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return strings.Join(roots, string(os.PathListSeparator))
+}
+
+// parseErrors parses javac output, which gives the location and message,
+// then echoes the offending line with a caret under the column:
+//
+//	src/App.java:3: error: ';' expected
+//	        int x = 1
+//	                 ^
+func (jv *JavaValidator) parseErrors(output string) ([]ValidationError, []ValidationError) {
+	var errors []ValidationError
+	var warnings []ValidationError
+
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		matches := jv.errorPattern.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if len(matches) != 5 {
+			continue
+		}
+
+		lineNum, _ := strconv.Atoi(matches[2])
+		validationError := ValidationError{
+			File:    matches[1],
+			Line:    lineNum,
+			Message: matches[4],
+		}
+
+		// The caret line follows the echoed source line
+		if i+2 < len(lines) {
+			caret := strings.TrimRight(lines[i+2], "\r")
+			if strings.TrimSpace(caret) == "^" {
+				validationError.Column = strings.Index(caret, "^") + 1
+				i += 2
+			}
+		}
+
+		if matches[3] == "warning" {
+			validationError.Severity = SeverityWarning
+			warnings = append(warnings, validationError)
+		} else {
+			validationError.Severity = SeverityError
+			errors = append(errors, validationError)
+		}
+	}
+
+	return errors, warnings
+}
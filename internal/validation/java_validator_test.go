@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJavaValidator_ParseErrors(t *testing.T) {
+	validator := NewJavaValidator()
+
+	output := "src/App.java:3: error: ';' expected\n" +
+		"        int x = 1\n" +
+		"                 ^\n" +
+		"src/App.java:5: warning: [deprecation] Date(String) in Date has been deprecated\n" +
+		"        new java.util.Date(\"x\");\n" +
+		"        ^\n" +
+		"1 error\n1 warning\n"
+	errors, warnings := validator.parseErrors(output)
+
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	want := ValidationError{File: "src/App.java", Line: 3, Column: 18, Message: "';' expected", Severity: SeverityError}
+	if errors[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, errors[0])
+	}
+	if len(warnings) != 1 || warnings[0].Line != 5 || warnings[0].Column != 9 {
+		t.Errorf("Unexpected warnings: %+v", warnings)
+	}
+}
+
+func TestJavaValidator_SourcePath(t *testing.T) {
+	root := t.TempDir()
+	pkgDir := filepath.Join(root, "com", "example")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	packaged := filepath.Join(pkgDir, "App.java")
+	if err := os.WriteFile(packaged, []byte("package com.example;\n\npublic class App {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loose := filepath.Join(root, "Main.java")
+	if err := os.WriteFile(loose, []byte("public class Main {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewJavaValidator()
+	if got := validator.sourcePath([]string{packaged, loose}); got != root {
+		t.Errorf("Expected source path %s, got %s", root, got)
+	}
+}
@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JavaScriptValidator implements syntax validation for JavaScript code
+type JavaScriptValidator struct {
+	*BaseValidator
+	errorPattern   *regexp.Regexp
+	messagePattern *regexp.Regexp
+}
+
+// NewJavaScriptValidator creates a new JavaScript validator
+func NewJavaScriptValidator() *JavaScriptValidator {
+	config := ValidatorConfig{
+		Command:      "node",
+		Args:         []string{"--check"},
+		Timeout:      10 * time.Second,
+		ErrorPattern: `^(.+):(\d+)$`,
+	}
+
+	info := ValidatorInfo{
+		Name:    "JavaScript",
+		Version: "1.0.0",
+		Command: "node",
+	}
+
+	return &JavaScriptValidator{
+		BaseValidator:  NewBaseValidator(config, info),
+		errorPattern:   regexp.MustCompile(config.ErrorPattern),
+		messagePattern: regexp.MustCompile(`^(\w*Error): (.+)$`),
+	}
+}
+
+// Execute runs node --check for the given files
+// Each file is validated independently
+func (jv *JavaScriptValidator) Execute(files []string) (ValidationResult, error) {
+	if len(files) == 0 {
+		return ValidationResult{}, fmt.Errorf("no files provided for validation")
+	}
+
+	if _, err := jv.LookupTool(jv.config.Command); err != nil {
+		return ValidationResult{}, err
+	}
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	var allErrors []ValidationError
+	var combinedStdout strings.Builder
+	var combinedStderr strings.Builder
+	overallExitCode := 0
+
+	for _, file := range files {
+		args := append(append([]string{}, jv.config.Args...), file)
+
+		stdout, stderr, exitCode, err := jv.BaseValidator.ExecuteCommand(ctx, jv.config.Command, args, "")
+		if err != nil {
+			return ValidationResult{}, fmt.Errorf("failed to execute node validation for %s: %w", file, err)
+		}
+
+		if stdout != "" {
+			combinedStdout.WriteString(stdout)
+			combinedStdout.WriteString("\n")
+		}
+		if stderr != "" {
+			combinedStderr.WriteString(stderr)
+			combinedStderr.WriteString("\n")
+		}
+		if exitCode != 0 {
+			overallExitCode = exitCode
+		}
+
+		allErrors = append(allErrors, jv.parseErrors(stderr)...)
+	}
+
+	return jv.BaseValidator.CreateValidationResult(
+		LanguageJavaScript,
+		files,
+		time.Since(startTime),
+		combinedStdout.String(),
+		combinedStderr.String(),
+		overallExitCode,
+		allErrors,
+		nil,
+	), nil
+}
+
+// parseErrors parses node --check output, which names the location, echoes
+// the offending line with a caret under the column and then gives the error:
+//
+//	/path/app.js:3
+//	  return 1;
+//	         ^
+//
+//	SyntaxError: Unexpected number
+func (jv *JavaScriptValidator) parseErrors(output string) []ValidationError {
+	var errors []ValidationError
+
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		matches := jv.errorPattern.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if len(matches) != 3 {
+			continue
+		}
+
+		lineNum, _ := strconv.Atoi(matches[2])
+		validationError := ValidationError{
+			File:     matches[1],
+			Line:     lineNum,
+			Message:  "syntax error",
+			Severity: SeverityError,
+		}
+
+		for j := i + 1; j < len(lines); j++ {
+			next := strings.TrimRight(lines[j], "\r")
+			if validationError.Column == 0 && strings.TrimSpace(next) != "" && strings.Trim(next, " ^~") == "" {
+				validationError.Column = strings.Index(next, "^") + 1
+				continue
+			}
+			if msg := jv.messagePattern.FindStringSubmatch(next); len(msg) == 3 {
+				validationError.Code = msg[1]
+				validationError.Message = msg[2]
+				i = j
+				break
+			}
+		}
+
+		errors = append(errors, validationError)
+	}
+
+	return errors
+}
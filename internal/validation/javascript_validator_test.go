@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestJavaScriptValidator_ParseErrors(t *testing.T) {
+	validator := NewJavaScriptValidator()
+
+	output := "/work/app.js:3\n  return 1;\n         ^\n\nSyntaxError: Unexpected number\n    at wrapSafe (node:internal/modules/cjs/loader:1464:18)\n\nNode.js v20.19.5\n"
+	errors := validator.parseErrors(output)
+
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	want := ValidationError{File: "/work/app.js", Line: 3, Column: 10, Message: "Unexpected number", Severity: SeverityError, Code: "SyntaxError"}
+	if errors[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, errors[0])
+	}
+}
+
+func TestJavaScriptValidator_ParseErrors_EmptyOutput(t *testing.T) {
+	validator := NewJavaScriptValidator()
+
+	if errors := validator.parseErrors(""); len(errors) != 0 {
+		t.Errorf("Expected no errors, got %v", errors)
+	}
+}
+
+func TestJavaScriptValidator_Execute(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+
+	tmpDir := t.TempDir()
+	valid := filepath.Join(tmpDir, "valid.js")
+	invalid := filepath.Join(tmpDir, "invalid.js")
+	if err := os.WriteFile(valid, []byte("const a = 1;\nconsole.log(a);\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("const a = 1;\nfunction f( {\n  return a;\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewJavaScriptValidator()
+	result, err := validator.Execute([]string{valid})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Success || result.Language != LanguageJavaScript {
+		t.Errorf("Expected success, got %+v", result)
+	}
+
+	result, err = validator.Execute([]string{valid, invalid})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected failure for invalid code")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].File != invalid || result.Errors[0].Line == 0 || result.Errors[0].Code != "SyntaxError" {
+		t.Errorf("Unexpected errors: %+v", result.Errors)
+	}
+}
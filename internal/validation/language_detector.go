@@ -15,8 +15,19 @@ type LanguageDetector struct {
 func NewLanguageDetector() *LanguageDetector {
 	return &LanguageDetector{
 		extensionMap: map[string]Language{
-			".go": LanguageGo,
-			".py": LanguagePython,
+			".go":   LanguageGo,
+			".py":   LanguagePython,
+			".js":   LanguageJavaScript,
+			".mjs":  LanguageJavaScript,
+			".cjs":  LanguageJavaScript,
+			".ts":   LanguageTypeScript,
+			".tsx":  LanguageTypeScript,
+			".mts":  LanguageTypeScript,
+			".cts":  LanguageTypeScript,
+			".rs":   LanguageRust,
+			".sh":   LanguageBash,
+			".bash": LanguageBash,
+			".java": LanguageJava,
 		},
 		configs: make(map[Language]LanguageConfig),
 	}
@@ -154,17 +154,16 @@ func TestProperty5_UnsupportedLanguageReporting(t *testing.T) {
 }
 
 // genUnsupportedExtension generates random unsupported file extensions
-// Ensures the extension is not .go or .py (case-insensitive)
+// Ensures the extension is not one of the built-in ones (case-insensitive)
 func genUnsupportedExtension() gopter.Gen {
 	// Generate common unsupported extensions
 	commonUnsupported := gen.OneConstOf(
-		".js", ".ts", ".jsx", ".tsx",
-		".java", ".cpp", ".c", ".h",
-		".rb", ".php", ".rs", ".swift",
+		".jsx", ".cpp", ".c", ".h",
+		".rb", ".php", ".swift",
 		".kt", ".scala", ".cs", ".vb",
 		".txt", ".md", ".json", ".yaml", ".yml",
 		".xml", ".html", ".css", ".scss",
-		".sh", ".bat", ".ps1",
+		".zsh", ".bat", ".ps1",
 		".sql", ".db",
 		".jpg", ".png", ".gif", ".svg",
 		".pdf", ".doc", ".docx",
@@ -174,9 +173,8 @@ func genUnsupportedExtension() gopter.Gen {
 	randomExtension := gen.Identifier().Map(func(s string) string {
 		return "." + s
 	}).SuchThat(func(v interface{}) bool {
-		ext := strings.ToLower(v.(string))
 		// Ensure it's not a supported extension
-		return ext != ".go" && ext != ".py"
+		return NewLanguageDetector().DetectLanguage("file"+v.(string)) == LanguageUnsupported
 	})
 
 	// Combine both generators
@@ -38,8 +38,33 @@ func TestLanguageDetector_DetectLanguage(t *testing.T) {
 			want:     LanguageUnsupported,
 		},
 		{
-			name:     "JavaScript file returns unsupported",
+			name:     "JavaScript file",
 			filePath: "app.js",
+			want:     LanguageJavaScript,
+		},
+		{
+			name:     "TypeScript JSX file",
+			filePath: "src/App.tsx",
+			want:     LanguageTypeScript,
+		},
+		{
+			name:     "Rust file",
+			filePath: "src/main.rs",
+			want:     LanguageRust,
+		},
+		{
+			name:     "Shell script",
+			filePath: "scripts/build.sh",
+			want:     LanguageBash,
+		},
+		{
+			name:     "Java file",
+			filePath: "src/main/java/App.java",
+			want:     LanguageJava,
+		},
+		{
+			name:     "Ruby file returns unsupported",
+			filePath: "app.rb",
 			want:     LanguageUnsupported,
 		},
 		{
@@ -84,31 +109,22 @@ func TestLanguageDetector_GetSupportedLanguages(t *testing.T) {
 
 	languages := detector.GetSupportedLanguages()
 
-	// Should return exactly 2 languages: Go and Python
-	if len(languages) != 2 {
-		t.Errorf("GetSupportedLanguages() returned %d languages, want 2", len(languages))
+	want := []Language{LanguageGo, LanguagePython, LanguageJavaScript, LanguageTypeScript, LanguageRust, LanguageBash, LanguageJava}
+	if len(languages) != len(want) {
+		t.Errorf("GetSupportedLanguages() returned %d languages, want %d", len(languages), len(want))
 	}
 
-	// Check that both Go and Python are in the list
-	hasGo := false
-	hasPython := false
+	found := make(map[Language]bool)
 	for _, lang := range languages {
-		if lang == LanguageGo {
-			hasGo = true
-		}
-		if lang == LanguagePython {
-			hasPython = true
-		}
 		if lang == LanguageUnsupported {
 			t.Errorf("GetSupportedLanguages() should not include LanguageUnsupported")
 		}
+		found[lang] = true
 	}
-
-	if !hasGo {
-		t.Errorf("GetSupportedLanguages() missing LanguageGo")
-	}
-	if !hasPython {
-		t.Errorf("GetSupportedLanguages() missing LanguagePython")
+	for _, lang := range want {
+		if !found[lang] {
+			t.Errorf("GetSupportedLanguages() missing %s", lang)
+		}
 	}
 }
 
@@ -135,9 +151,14 @@ func TestLanguageDetector_IsSupported(t *testing.T) {
 			language: LanguageUnsupported,
 			want:     false,
 		},
+		{
+			name:     "JavaScript is supported",
+			language: LanguageJavaScript,
+			want:     true,
+		},
 		{
 			name:     "Unknown language is not supported",
-			language: Language("javascript"),
+			language: Language("ruby"),
 			want:     false,
 		},
 	}
@@ -187,32 +208,32 @@ func TestLanguageDetector_NewLanguageDetectorWithConfig(t *testing.T) {
 func TestLanguageDetector_RegisterLanguage(t *testing.T) {
 	detector := NewLanguageDetector()
 
-	// Initially, TypeScript should not be supported
-	if detector.IsSupported(Language("typescript")) {
-		t.Error("TypeScript should not be supported initially")
+	// Initially, Kotlin should not be supported
+	if detector.IsSupported(Language("kotlin")) {
+		t.Error("Kotlin should not be supported initially")
 	}
 
-	// Register TypeScript
+	// Register Kotlin
 	config := LanguageConfig{
-		Name:       "TypeScript",
-		Extensions: []string{".ts", ".tsx"},
+		Name:       "Kotlin",
+		Extensions: []string{".kt", ".kts"},
 		Validator: ValidatorConfig{
-			Command: "tsc",
-			Args:    []string{"--noEmit"},
+			Command: "kotlinc",
+			Args:    []string{},
 			Timeout: 30000000000,
 		},
 	}
 	detector.RegisterLanguage(config)
 
-	// Now TypeScript should be supported
-	if !detector.IsSupported(Language("typescript")) {
-		t.Error("TypeScript should be supported after registration")
+	// Now Kotlin should be supported
+	if !detector.IsSupported(Language("kotlin")) {
+		t.Error("Kotlin should be supported after registration")
 	}
 
 	// Test detection
-	lang := detector.DetectLanguage("app.ts")
-	if lang != Language("typescript") {
-		t.Errorf("Expected 'typescript', got '%s'", lang)
+	lang := detector.DetectLanguage("app.kt")
+	if lang != Language("kotlin") {
+		t.Errorf("Expected 'kotlin', got '%s'", lang)
 	}
 }
 
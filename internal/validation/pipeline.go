@@ -3,6 +3,7 @@ package validation
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 )
@@ -20,6 +21,9 @@ type Pipeline struct {
 	queueMu         sync.Mutex
 	isProcessing    bool
 
+	// Called after each queued validation finishes (optional)
+	onComplete func(*ValidationSession)
+
 	// Context for cancellation
 	ctx        context.Context
 	cancelFunc context.CancelFunc
@@ -82,10 +86,33 @@ func (p *Pipeline) QueueValidation(files []string) {
 	case p.validationQueue <- files:
 		// Successfully queued
 	default:
-		// Queue is full, log or handle error
-		// For now, we'll just skip this validation
-		fmt.Println("Warning: Validation queue is full, skipping validation")
+		// Queue is full; skip this validation rather than block the caller
+		p.chatPanelIntegration.addMessage("⚠️ Validation queue is full, skipping validation")
+	}
+}
+
+// ValidateChanges queues a single validation for a batch of files written
+// by a save or an AI edit. Non-code files and duplicates are dropped.
+func (p *Pipeline) ValidateChanges(files []string) {
+	seen := make(map[string]bool, len(files))
+	var batch []string
+	for _, file := range files {
+		file = filepath.Clean(file)
+		if seen[file] || p.fileChangeDetector.isNonCodeFile(file) {
+			continue
+		}
+		seen[file] = true
+		batch = append(batch, file)
 	}
+	p.QueueValidation(batch)
+}
+
+// OnValidationComplete sets a callback invoked with the session of every
+// queued validation once it finishes. It runs on the queue goroutine.
+func (p *Pipeline) OnValidationComplete(callback func(*ValidationSession)) {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+	p.onComplete = callback
 }
 
 // processValidationQueue processes validation requests from the queue
@@ -102,7 +129,7 @@ func (p *Pipeline) processValidationQueue() {
 			p.queueMu.Unlock()
 
 			// Execute validation
-			_, err := p.validationEngine.ValidateFiles(files)
+			session, err := p.validationEngine.ValidateFiles(files)
 			if err != nil {
 				// Handle validation error
 				p.handleValidationError(err)
@@ -110,7 +137,12 @@ func (p *Pipeline) processValidationQueue() {
 
 			p.queueMu.Lock()
 			p.isProcessing = false
+			onComplete := p.onComplete
 			p.queueMu.Unlock()
+
+			if onComplete != nil && session != nil {
+				onComplete(session)
+			}
 		}
 	}
 }
@@ -305,3 +305,42 @@ func TestPipeline_ErrorHandling_GenericError(t *testing.T) {
 		t.Errorf("Expected validation error message, got: %s", lastMessage)
 	}
 }
+
+func TestPipeline_ValidateChanges_FiltersAndNotifies(t *testing.T) {
+	pipeline := NewPipeline()
+	defer pipeline.Shutdown()
+
+	done := make(chan *ValidationSession, 1)
+	pipeline.OnValidationComplete(func(session *ValidationSession) {
+		done <- session
+	})
+
+	pipeline.ValidateChanges([]string{"README.md", "lib/tool.rb", "lib/./tool.rb", "notes.txt"})
+
+	select {
+	case session := <-done:
+		if len(session.Files) != 1 || session.Files[0] != "lib/tool.rb" {
+			t.Errorf("Expected only lib/tool.rb to be validated, got %v", session.Files)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected completion callback to be invoked")
+	}
+}
+
+func TestPipeline_ValidateChanges_OnlyNonCodeFiles(t *testing.T) {
+	pipeline := NewPipeline()
+	defer pipeline.Shutdown()
+
+	called := make(chan struct{}, 1)
+	pipeline.OnValidationComplete(func(*ValidationSession) {
+		called <- struct{}{}
+	})
+
+	pipeline.ValidateChanges([]string{"README.md", "docs/guide.md"})
+
+	select {
+	case <-called:
+		t.Error("Expected no validation for non-code files")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
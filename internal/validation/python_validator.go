@@ -48,6 +48,10 @@ func (pv *PythonValidator) Execute(files []string) (ValidationResult, error) {
 		return ValidationResult{}, fmt.Errorf("no files provided for validation")
 	}
 
+	if _, err := pv.LookupTool(pv.config.Command); err != nil {
+		return ValidationResult{}, err
+	}
+
 	ctx := context.Background()
 	startTime := time.Now()
 
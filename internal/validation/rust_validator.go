@@ -0,0 +1,226 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RustValidator implements validation for Rust code
+type RustValidator struct {
+	*BaseValidator
+}
+
+// rustSpan is a source location in a rustc JSON diagnostic
+type rustSpan struct {
+	FileName    string `json:"file_name"`
+	LineStart   int    `json:"line_start"`
+	ColumnStart int    `json:"column_start"`
+	IsPrimary   bool   `json:"is_primary"`
+}
+
+// rustDiagnostic is a diagnostic emitted by rustc with --error-format=json
+type rustDiagnostic struct {
+	Message string `json:"message"`
+	Level   string `json:"level"`
+	Code    *struct {
+		Code string `json:"code"`
+	} `json:"code"`
+	Spans []rustSpan `json:"spans"`
+}
+
+// cargoMessage is a line of cargo --message-format=json output
+type cargoMessage struct {
+	Reason  string          `json:"reason"`
+	Message json.RawMessage `json:"message"`
+}
+
+// NewRustValidator creates a new Rust validator
+func NewRustValidator() *RustValidator {
+	config := ValidatorConfig{
+		Command: "cargo",
+		Args:    []string{"check", "--message-format=json", "--quiet"},
+		Timeout: 120 * time.Second,
+	}
+
+	info := ValidatorInfo{
+		Name:    "Rust",
+		Version: "1.0.0",
+		Command: "cargo",
+	}
+
+	return &RustValidator{
+		BaseValidator: NewBaseValidator(config, info),
+	}
+}
+
+// Execute runs cargo check in each crate containing some of the files. Files
+// outside a Cargo project are compiled one by one with rustc as library
+// crates.
+func (rv *RustValidator) Execute(files []string) (ValidationResult, error) {
+	if len(files) == 0 {
+		return ValidationResult{}, fmt.Errorf("no files provided for validation")
+	}
+
+	startTime := time.Now()
+	var results []ValidationResult
+	for _, group := range groupByProjectRoot(files, "Cargo.toml") {
+		var result ValidationResult
+		var err error
+		if crateDir := findProjectRoot(filepath.Dir(group[0]), "Cargo.toml"); crateDir == "" {
+			result, err = rv.executeRustc(group)
+		} else {
+			result, err = rv.executeCargo(crateDir, group)
+		}
+		if err != nil {
+			return ValidationResult{}, err
+		}
+		results = append(results, result)
+	}
+	return mergeResults(results, files, time.Since(startTime)), nil
+}
+
+// executeCargo runs cargo check in the crate at crateDir
+func (rv *RustValidator) executeCargo(crateDir string, files []string) (ValidationResult, error) {
+	if _, err := rv.LookupTool(rv.config.Command); err != nil {
+		return ValidationResult{}, err
+	}
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	stdout, stderr, exitCode, err := rv.BaseValidator.ExecuteCommand(ctx, rv.config.Command, rv.config.Args, crateDir)
+	if err != nil {
+		return ValidationResult{}, fmt.Errorf("failed to execute cargo check: %w", err)
+	}
+
+	errors, warnings := rv.parseMessages(stdout, crateDir)
+
+	// The JSON messages are not useful as raw output, so keep only stderr
+	// (e.g. manifest errors) for display
+	return rv.BaseValidator.CreateValidationResult(
+		LanguageRust,
+		files,
+		time.Since(startTime),
+		"",
+		stderr,
+		exitCode,
+		errors,
+		warnings,
+	), nil
+}
+
+// executeRustc checks standalone files with rustc
+func (rv *RustValidator) executeRustc(files []string) (ValidationResult, error) {
+	if _, err := rv.LookupTool("rustc"); err != nil {
+		return ValidationResult{}, err
+	}
+
+	outDir, err := os.MkdirTemp("", "ti-rustc-")
+	if err != nil {
+		return ValidationResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	var allErrors []ValidationError
+	var allWarnings []ValidationError
+	overallExitCode := 0
+
+	for _, file := range files {
+		args := []string{"--error-format=json", "--emit=metadata", "--crate-type=lib", "--edition=2021", "--out-dir", outDir, file}
+		_, stderr, exitCode, err := rv.BaseValidator.ExecuteCommand(ctx, "rustc", args, "")
+		if err != nil {
+			return ValidationResult{}, fmt.Errorf("failed to execute rustc for %s: %w", file, err)
+		}
+		if exitCode != 0 {
+			overallExitCode = exitCode
+		}
+
+		errors, warnings := rv.parseMessages(stderr, "")
+		allErrors = append(allErrors, errors...)
+		allWarnings = append(allWarnings, warnings...)
+	}
+
+	return rv.BaseValidator.CreateValidationResult(
+		LanguageRust,
+		files,
+		time.Since(startTime),
+		"",
+		"",
+		overallExitCode,
+		allErrors,
+		allWarnings,
+	), nil
+}
+
+// parseMessages parses JSON diagnostics, either cargo compiler-message lines
+// or bare rustc diagnostics. Span paths are relative to dir.
+func (rv *RustValidator) parseMessages(output string, dir string) ([]ValidationError, []ValidationError) {
+	var errors []ValidationError
+	var warnings []ValidationError
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var msg cargoMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue
+		}
+
+		// rustc diagnostics stand alone; cargo wraps them in compiler-message
+		raw := []byte(line)
+		switch msg.Reason {
+		case "compiler-message":
+			raw = msg.Message
+		case "":
+		default:
+			continue
+		}
+		var diag rustDiagnostic
+		if err := json.Unmarshal(raw, &diag); err != nil || diag.Level == "" {
+			continue
+		}
+
+		validationError := ValidationError{Message: diag.Message}
+		if diag.Code != nil {
+			validationError.Code = diag.Code.Code
+		}
+		for _, span := range diag.Spans {
+			if span.IsPrimary {
+				validationError.File = resolveReportedPath(dir, span.FileName)
+				validationError.Line = span.LineStart
+				validationError.Column = span.ColumnStart
+				break
+			}
+		}
+
+		switch diag.Level {
+		case "error":
+			// The closing summary carries no information of its own
+			if validationError.File == "" && strings.HasPrefix(diag.Message, "aborting due to") {
+				continue
+			}
+			validationError.Severity = SeverityError
+			errors = append(errors, validationError)
+		case "warning":
+			if validationError.File == "" {
+				// Crate-level summaries such as "3 warnings emitted"
+				continue
+			}
+			validationError.Severity = SeverityWarning
+			warnings = append(warnings, validationError)
+		}
+	}
+
+	return errors, warnings
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRustValidator_ParseMessages_Cargo(t *testing.T) {
+	validator := NewRustValidator()
+
+	output := `{"reason":"compiler-artifact","package_id":"demo"}
+{"reason":"compiler-message","message":{"$message_type":"diagnostic","code":{"code":"E0308"},"level":"error","message":"mismatched types","spans":[{"file_name":"src/main.rs","line_start":2,"column_start":12,"is_primary":false},{"file_name":"src/main.rs","line_start":2,"column_start":18,"is_primary":true}]}}
+{"reason":"compiler-message","message":{"$message_type":"diagnostic","code":{"code":"dead_code"},"level":"warning","message":"function ` + "`f`" + ` is never used","spans":[{"file_name":"src/lib.rs","line_start":5,"column_start":4,"is_primary":true}]}}
+{"reason":"compiler-message","message":{"$message_type":"diagnostic","code":null,"level":"error","message":"aborting due to 1 previous error","spans":[]}}
+{"reason":"compiler-message","message":{"$message_type":"diagnostic","code":null,"level":"warning","message":"1 warning emitted","spans":[]}}
+{"reason":"build-finished","success":false}
+`
+	errors, warnings := validator.parseMessages(output, "/work")
+
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	want := ValidationError{File: filepath.Join("/work", "src/main.rs"), Line: 2, Column: 18, Message: "mismatched types", Severity: SeverityError, Code: "E0308"}
+	if errors[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, errors[0])
+	}
+	if len(warnings) != 1 || warnings[0].Code != "dead_code" || warnings[0].Line != 5 {
+		t.Errorf("Unexpected warnings: %+v", warnings)
+	}
+}
+
+func TestRustValidator_ParseMessages_Rustc(t *testing.T) {
+	validator := NewRustValidator()
+
+	output := `{"$message_type":"diagnostic","code":{"code":"E0425"},"level":"error","message":"cannot find value ` + "`y`" + ` in this scope","spans":[{"file_name":"/tmp/lib.rs","line_start":1,"column_start":20,"is_primary":true}]}`
+	errors, _ := validator.parseMessages(output, "")
+
+	if len(errors) != 1 || errors[0].File != "/tmp/lib.rs" || errors[0].Column != 20 || errors[0].Code != "E0425" {
+		t.Errorf("Unexpected errors: %+v", errors)
+	}
+}
+
+func TestRustValidator_Execute_Standalone(t *testing.T) {
+	if _, err := exec.LookPath("rustc"); err != nil {
+		t.Skip("rustc not available")
+	}
+
+	file := filepath.Join(t.TempDir(), "lib.rs")
+	if err := os.WriteFile(file, []byte("pub fn f() -> i32 {\n    \"a\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewRustValidator().Execute([]string{file})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected failure for mismatched types")
+	}
+	if len(result.Errors) == 0 || result.Errors[0].File != file || result.Errors[0].Line != 2 || result.Errors[0].Code != "E0308" {
+		t.Errorf("Unexpected errors: %+v", result.Errors)
+	}
+}
+
+func TestRustValidator_Execute_ChecksEveryCrate(t *testing.T) {
+	if _, err := exec.LookPath("cargo"); err != nil {
+		t.Skip("cargo not available")
+	}
+
+	dir := t.TempDir()
+	sources := map[string]string{
+		"good": "pub fn f() -> i32 {\n    1\n}\n",
+		"bad":  "pub fn f() -> i32 {\n    \"a\"\n}\n",
+	}
+	var files []string
+	for _, crate := range []string{"good", "bad"} {
+		if err := os.MkdirAll(filepath.Join(dir, crate, "src"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		manifest := "[package]\nname = \"" + crate + "\"\nversion = \"0.1.0\"\nedition = \"2021\"\n"
+		if err := os.WriteFile(filepath.Join(dir, crate, "Cargo.toml"), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, crate, "src", "lib.rs")
+		if err := os.WriteFile(file, []byte(sources[crate]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	result, err := NewRustValidator().Execute(files)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected failure from the second crate")
+	}
+	if len(result.Errors) == 0 || result.Errors[0].Code != "E0308" {
+		t.Errorf("Unexpected errors: %+v", result.Errors)
+	}
+	if len(result.Files) != 2 {
+		t.Errorf("Expected both files in the result, got %v", result.Files)
+	}
+}
@@ -45,6 +45,11 @@ type Language string
 const (
 	LanguageGo          Language = "go"
 	LanguagePython      Language = "python"
+	LanguageJavaScript  Language = "javascript"
+	LanguageTypeScript  Language = "typescript"
+	LanguageRust        Language = "rust"
+	LanguageBash        Language = "bash"
+	LanguageJava        Language = "java"
 	LanguageUnsupported Language = "unsupported"
 )
 
@@ -0,0 +1,158 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypeScriptValidator implements type checking for TypeScript code
+type TypeScriptValidator struct {
+	*BaseValidator
+	errorPattern  *regexp.Regexp
+	globalPattern *regexp.Regexp
+}
+
+// NewTypeScriptValidator creates a new TypeScript validator
+func NewTypeScriptValidator() *TypeScriptValidator {
+	config := ValidatorConfig{
+		Command:      "tsc",
+		Args:         []string{"--noEmit", "--pretty", "false"},
+		Timeout:      60 * time.Second,
+		ErrorPattern: `^(.+)\((\d+),(\d+)\): (error|warning) (TS\d+): (.+)$`,
+	}
+
+	info := ValidatorInfo{
+		Name:    "TypeScript",
+		Version: "1.0.0",
+		Command: "tsc",
+	}
+
+	return &TypeScriptValidator{
+		BaseValidator: NewBaseValidator(config, info),
+		errorPattern:  regexp.MustCompile(config.ErrorPattern),
+		globalPattern: regexp.MustCompile(`^(error|warning) (TS\d+): (.+)$`),
+	}
+}
+
+// Execute runs tsc --noEmit for the given files. Inside a project with a
+// tsconfig.json the whole project is checked with its settings, once for
+// each project the files belong to; files outside any project are checked
+// on their own.
+func (tv *TypeScriptValidator) Execute(files []string) (ValidationResult, error) {
+	if len(files) == 0 {
+		return ValidationResult{}, fmt.Errorf("no files provided for validation")
+	}
+
+	startTime := time.Now()
+	var results []ValidationResult
+	for _, group := range groupByProjectRoot(files, "tsconfig.json") {
+		result, err := tv.executeProject(findProjectRoot(filepath.Dir(group[0]), "tsconfig.json"), group)
+		if err != nil {
+			return ValidationResult{}, err
+		}
+		results = append(results, result)
+	}
+	return mergeResults(results, files, time.Since(startTime)), nil
+}
+
+// executeProject runs tsc for the project at projectDir, or for files on
+// their own when projectDir is ""
+func (tv *TypeScriptValidator) executeProject(projectDir string, files []string) (ValidationResult, error) {
+	ctx := context.Background()
+	startTime := time.Now()
+
+	command, err := tv.findCompiler(projectDir)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+
+	args := append([]string{}, tv.config.Args...)
+	if projectDir != "" {
+		args = append(args, "--project", projectDir)
+	} else {
+		args = append(args, "--jsx", "preserve")
+		args = append(args, files...)
+	}
+
+	stdout, stderr, exitCode, err := tv.BaseValidator.ExecuteCommand(ctx, command, args, projectDir)
+	if err != nil {
+		return ValidationResult{}, fmt.Errorf("failed to execute tsc: %w", err)
+	}
+
+	// tsc reports diagnostics on stdout
+	errors, warnings := tv.parseErrors(stdout+"\n"+stderr, projectDir)
+
+	return tv.BaseValidator.CreateValidationResult(
+		LanguageTypeScript,
+		files,
+		time.Since(startTime),
+		stdout,
+		stderr,
+		exitCode,
+		errors,
+		warnings,
+	), nil
+}
+
+// findCompiler prefers the project's own tsc from node_modules over one in
+// PATH
+func (tv *TypeScriptValidator) findCompiler(projectDir string) (string, error) {
+	if projectDir != "" {
+		local := filepath.Join(projectDir, "node_modules", ".bin", tv.config.Command)
+		if info, err := os.Stat(local); err == nil && !info.IsDir() {
+			return local, nil
+		}
+	}
+	return tv.LookupTool(tv.config.Command)
+}
+
+// parseErrors parses tsc output of the form
+// "src/app.ts(3,7): error TS2322: message". Paths are relative to dir.
+func (tv *TypeScriptValidator) parseErrors(output string, dir string) ([]ValidationError, []ValidationError) {
+	var errors []ValidationError
+	var warnings []ValidationError
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var validationError ValidationError
+		var level string
+		if matches := tv.errorPattern.FindStringSubmatch(line); len(matches) == 7 {
+			lineNum, _ := strconv.Atoi(matches[2])
+			colNum, _ := strconv.Atoi(matches[3])
+			level = matches[4]
+			validationError = ValidationError{
+				File:    resolveReportedPath(dir, matches[1]),
+				Line:    lineNum,
+				Column:  colNum,
+				Code:    matches[5],
+				Message: matches[6],
+			}
+		} else if matches := tv.globalPattern.FindStringSubmatch(line); len(matches) == 4 {
+			// Errors without a location, e.g. an invalid tsconfig.json
+			level = matches[1]
+			validationError = ValidationError{Code: matches[2], Message: matches[3]}
+		} else {
+			continue
+		}
+
+		if level == "warning" {
+			validationError.Severity = SeverityWarning
+			warnings = append(warnings, validationError)
+		} else {
+			validationError.Severity = SeverityError
+			errors = append(errors, validationError)
+		}
+	}
+
+	return errors, warnings
+}
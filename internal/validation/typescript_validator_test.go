@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestTypeScriptValidator_ParseErrors(t *testing.T) {
+	validator := NewTypeScriptValidator()
+
+	output := "src/app.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.\n" +
+		"error TS5058: The specified path does not exist: 'missing'.\n" +
+		"not a diagnostic\n"
+	errs, warnings := validator.parseErrors(output, "/work")
+
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	want := ValidationError{
+		File:     filepath.Join("/work", "src/app.ts"),
+		Line:     3,
+		Column:   7,
+		Message:  "Type 'string' is not assignable to type 'number'.",
+		Severity: SeverityError,
+		Code:     "TS2322",
+	}
+	if errs[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, errs[0])
+	}
+	if errs[1].File != "" || errs[1].Code != "TS5058" {
+		t.Errorf("Expected a global error, got %+v", errs[1])
+	}
+}
+
+func TestTypeScriptValidator_FindCompiler_PrefersProjectLocal(t *testing.T) {
+	projectDir := t.TempDir()
+	local := filepath.Join(projectDir, "node_modules", ".bin", "tsc")
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewTypeScriptValidator()
+	command, err := validator.findCompiler(projectDir)
+	if err != nil || command != local {
+		t.Errorf("Expected %s, got %q (%v)", local, command, err)
+	}
+}
+
+func TestTypeScriptValidator_Execute_NotInstalled(t *testing.T) {
+	if _, err := exec.LookPath("tsc"); err == nil {
+		t.Skip("tsc is installed")
+	}
+
+	file := filepath.Join(t.TempDir(), "app.ts")
+	if err := os.WriteFile(file, []byte("const a: number = 1;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewTypeScriptValidator().Execute([]string{file})
+	var notInstalled *ToolNotInstalledError
+	if !errors.As(err, &notInstalled) || notInstalled.Tool != "tsc" {
+		t.Errorf("Expected ToolNotInstalledError for tsc, got %v", err)
+	}
+}
+
+func TestTypeScriptValidator_Execute_ChecksEveryProject(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, project := range []string{"web", "api"} {
+		// A project-local tsc that reports the project it checked
+		tsc := filepath.Join(dir, project, "node_modules", ".bin", "tsc")
+		if err := os.MkdirAll(filepath.Dir(tsc), 0755); err != nil {
+			t.Fatal(err)
+		}
+		script := "#!/bin/sh\necho \"src/app.ts(1,1): error TS2322: checked " + project + "\"\nexit 2\n"
+		if err := os.WriteFile(tsc, []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, project, "tsconfig.json"), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, project, "src", "app.ts")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("const a: number = 1;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	result, err := NewTypeScriptValidator().Execute(files)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Success || len(result.Errors) != 2 {
+		t.Fatalf("Expected an error from each project, got %+v", result.Errors)
+	}
+	for i, project := range []string{"web", "api"} {
+		if e := result.Errors[i]; e.File != files[i] || e.Message != "checked "+project {
+			t.Errorf("Unexpected error for %s: %+v", project, e)
+		}
+	}
+	if len(result.Files) != 2 {
+		t.Errorf("Expected both files in the result, got %v", result.Files)
+	}
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -122,36 +123,24 @@ func (ve *ValidationEngine) ValidateFiles(files []string) (*ValidationSession, e
 		// Show validation start message with per-unit status
 		ve.chatPanelIntegration.ShowValidationStart(langFiles, lang)
 
-		// For Python, validate each file independently and show per-file status
+		// For Python, validate each file independently and show per-file status.
+		// Go and other languages are validated as a package.
+		groups := [][]string{langFiles}
 		if lang == LanguagePython {
+			groups = groups[:0]
 			for _, file := range langFiles {
-				// Execute validation for single file
-				result, err := ve.compilerInterface.ValidateWithContext(ctx, lang, []string{file})
-				if err != nil {
-					// Validation execution failed
-					ve.mu.Lock()
-					session.Status = StatusFailed
-					endTime := time.Now()
-					session.EndTime = &endTime
-					ve.mu.Unlock()
-					return session, fmt.Errorf("validation failed for %s: %w", lang, err)
-				}
-
-				// Add result to session
-				ve.mu.Lock()
-				session.Results = append(session.Results, result)
-				ve.mu.Unlock()
+				groups = append(groups, []string{file})
+			}
+		}
 
-				// Show validation result for this file
-				if result.Success {
-					ve.chatPanelIntegration.ShowValidationSuccess(result)
-				} else {
-					ve.chatPanelIntegration.ShowValidationFailure(result)
-				}
+		for _, group := range groups {
+			err := ve.validateGroup(ctx, session, lang, group)
+			var notInstalled *ToolNotInstalledError
+			if errors.As(err, &notInstalled) {
+				// Report the missing tool once for the whole language and move on
+				ve.chatPanelIntegration.ShowToolNotInstalled(langFiles, lang, notInstalled.Tool)
+				break
 			}
-		} else {
-			// For Go and other languages, validate as a package
-			result, err := ve.compilerInterface.ValidateWithContext(ctx, lang, langFiles)
 			if err != nil {
 				// Validation execution failed
 				ve.mu.Lock()
@@ -161,18 +150,6 @@ func (ve *ValidationEngine) ValidateFiles(files []string) (*ValidationSession, e
 				ve.mu.Unlock()
 				return session, fmt.Errorf("validation failed for %s: %w", lang, err)
 			}
-
-			// Add result to session
-			ve.mu.Lock()
-			session.Results = append(session.Results, result)
-			ve.mu.Unlock()
-
-			// Show validation result
-			if result.Success {
-				ve.chatPanelIntegration.ShowValidationSuccess(result)
-			} else {
-				ve.chatPanelIntegration.ShowValidationFailure(result)
-			}
 		}
 	}
 
@@ -189,6 +166,28 @@ func (ve *ValidationEngine) ValidateFiles(files []string) (*ValidationSession, e
 	return session, nil
 }
 
+// validateGroup validates one group of files, adds the result to the session
+// and reports it in the chat panel
+func (ve *ValidationEngine) validateGroup(ctx context.Context, session *ValidationSession, lang Language, files []string) error {
+	result, err := ve.compilerInterface.ValidateWithContext(ctx, lang, files)
+	if err != nil {
+		return err
+	}
+
+	// Add result to session
+	ve.mu.Lock()
+	session.Results = append(session.Results, result)
+	ve.mu.Unlock()
+
+	// Show validation result
+	if result.Success {
+		ve.chatPanelIntegration.ShowValidationSuccess(result)
+	} else {
+		ve.chatPanelIntegration.ShowValidationFailure(result)
+	}
+	return nil
+}
+
 // groupFilesByLanguage groups files by their detected programming language
 func (ve *ValidationEngine) groupFilesByLanguage(files []string) map[Language][]string {
 	filesByLanguage := make(map[Language][]string)
@@ -260,18 +259,22 @@ func (ve *ValidationEngine) startProgressTracking(ctx context.Context) {
 	defer ve.mu.Unlock()
 
 	// Create a ticker that fires every second
-	ve.progressTicker = time.NewTicker(1 * time.Second)
-	ve.progressDone = make(chan bool)
+	ticker := time.NewTicker(1 * time.Second)
+	done := make(chan bool)
+	ve.progressTicker = ticker
+	ve.progressDone = done
 
+	// The goroutine keeps its own references: the fields are cleared when
+	// tracking stops
 	go func() {
 		startTime := time.Now()
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ve.progressDone:
+			case <-done:
 				return
-			case <-ve.progressTicker.C:
+			case <-ticker.C:
 				elapsed := time.Since(startTime)
 				// Only show progress indicator if validation exceeds 5 seconds
 				if elapsed >= 5*time.Second {
@@ -31,6 +31,8 @@ func TestProperty_LanguageBasedFileGrouping(t *testing.T) {
 				lang = LanguageGo
 			case ".py":
 				lang = LanguagePython
+			case ".js":
+				lang = LanguageJavaScript
 			default:
 				lang = LanguageUnsupported
 			}
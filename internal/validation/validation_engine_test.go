@@ -312,3 +312,31 @@ func TestValidationEngine_Cancel(t *testing.T) {
 	// Cancel after session completes should not panic
 	engine.Cancel()
 }
+
+func TestValidationEngine_ValidateFiles_ToolNotInstalled(t *testing.T) {
+	compilerInterface := NewCompilerInterface()
+	compilerInterface.RegisterValidator(LanguageJava, &MockValidator{
+		executeFunc: func(files []string) (ValidationResult, error) {
+			return ValidationResult{}, &ToolNotInstalledError{Tool: "javac"}
+		},
+	})
+	chatPanelIntegration := NewChatPanelIntegration()
+	engine := NewValidationEngine(NewLanguageDetector(), compilerInterface, chatPanelIntegration)
+
+	session, err := engine.ValidateFiles([]string{"App.java", "Util.java"})
+	if err != nil {
+		t.Fatalf("Expected a missing tool not to fail validation, got %v", err)
+	}
+	if session.Status != StatusCompleted {
+		t.Errorf("Expected status %s, got %s", StatusCompleted, session.Status)
+	}
+	if len(session.Results) != 0 {
+		t.Errorf("Expected no validation results, got %d", len(session.Results))
+	}
+
+	lastMessage := chatPanelIntegration.GetLastMessage()
+	if !strings.Contains(lastMessage, "Skipped Java validation: javac is not installed") ||
+		!strings.Contains(lastMessage, "- Util.java") {
+		t.Errorf("Expected tool not installed notification, got: %s", lastMessage)
+	}
+}
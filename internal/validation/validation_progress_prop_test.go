@@ -162,8 +162,7 @@ func TestProperty25_SupportedLanguagesListing(t *testing.T) {
 
 		// Message should list all supported languages
 		for _, lang := range supportedLanguages {
-			langName := strings.Title(string(lang))
-			if !strings.Contains(lastMsg, langName) && !strings.Contains(lastMsg, string(lang)) {
+			if !strings.Contains(lastMsg, LanguageName(lang)) {
 				t.Errorf("Message should list supported language '%s', got: %s", lang, lastMsg)
 			}
 		}
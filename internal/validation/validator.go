@@ -4,10 +4,24 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// ToolNotInstalledError is returned by a validator whose compiler or checker
+// is not installed. The validation engine reports it and skips the language
+// instead of failing the session.
+type ToolNotInstalledError struct {
+	Tool string // Name of the missing program
+}
+
+func (e *ToolNotInstalledError) Error() string {
+	return fmt.Sprintf("%s is not installed", e.Tool)
+}
+
 // Validator defines the interface for language-specific validators
 type Validator interface {
 	// Execute runs validation for the given files and returns the result
@@ -36,6 +50,16 @@ func (bv *BaseValidator) GetInfo() ValidatorInfo {
 	return bv.info
 }
 
+// LookupTool returns the path of the named program, or a
+// ToolNotInstalledError when it cannot be found in PATH
+func (bv *BaseValidator) LookupTool(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", &ToolNotInstalledError{Tool: name}
+	}
+	return path, nil
+}
+
 // ExecuteCommand runs a command with timeout and captures stdout/stderr
 // Returns the combined output, exit code, and any error
 func (bv *BaseValidator) ExecuteCommand(ctx context.Context, command string, args []string, workingDir string) (stdout string, stderr string, exitCode int, err error) {
@@ -112,3 +136,66 @@ func (bv *BaseValidator) CreateValidationResult(
 		Warnings: warnings,
 	}
 }
+
+// findProjectRoot returns the nearest directory at or above dir that contains
+// marker (e.g. Cargo.toml), or "" when there is none
+func findProjectRoot(dir string, marker string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// groupByProjectRoot splits files by the nearest directory containing marker,
+// in the order the projects first appear. Files outside any project form one
+// group of their own.
+func groupByProjectRoot(files []string, marker string) [][]string {
+	var groups [][]string
+	index := make(map[string]int)
+	for _, file := range files {
+		root := findProjectRoot(filepath.Dir(file), marker)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], file)
+	}
+	return groups
+}
+
+// mergeResults combines the results of checking each project of a batch
+// into the result for files
+func mergeResults(results []ValidationResult, files []string, duration time.Duration) ValidationResult {
+	combined := results[0]
+	for _, result := range results[1:] {
+		combined.Success = combined.Success && result.Success
+		combined.Errors = append(combined.Errors, result.Errors...)
+		combined.Warnings = append(combined.Warnings, result.Warnings...)
+		if result.Output != "" {
+			combined.Output = strings.TrimPrefix(combined.Output+"\n"+result.Output, "\n")
+		}
+	}
+	combined.Files = files
+	combined.Duration = duration
+	return combined
+}
+
+// resolveReportedPath makes a path reported by a tool that ran in dir
+// absolute
+func resolveReportedPath(dir string, path string) string {
+	if path == "" || dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -2,6 +2,8 @@ package validation
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -402,3 +404,23 @@ func TestBaseValidator_CreateValidationResult_SuccessWithWarnings(t *testing.T)
 		t.Errorf("Expected no errors, got %d", len(result.Errors))
 	}
 }
+
+func TestGroupByProjectRoot(t *testing.T) {
+	dir := t.TempDir()
+	for _, crate := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(dir, crate, "src"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, crate, "Cargo.toml"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a1 := filepath.Join(dir, "a", "src", "lib.rs")
+	b1 := filepath.Join(dir, "b", "src", "lib.rs")
+	a2 := filepath.Join(dir, "a", "src", "util.rs")
+
+	groups := groupByProjectRoot([]string{a1, b1, a2}, "Cargo.toml")
+	if len(groups) != 2 || len(groups[0]) != 2 || groups[0][1] != a2 || len(groups[1]) != 1 || groups[1][0] != b1 {
+		t.Errorf("Unexpected groups: %v", groups)
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/terminal-intelligence/internal/cli"
 	"github.com/user/terminal-intelligence/internal/config"
 	"github.com/user/terminal-intelligence/internal/types"
 	"github.com/user/terminal-intelligence/internal/ui"
@@ -19,6 +22,10 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	// 1. Initialize Default Config
 	appCfg := types.DefaultConfig()
 
@@ -75,10 +82,24 @@ func main() {
 
 	// 6. Run Application
 	app := ui.New(appCfg, buildNumber)
-	p := tea.NewProgram(app, tea.WithAltScreen())
+	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithOutput(app.Output()))
 
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	app.Close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Application error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// runCLI runs a non-interactive subcommand. The first Ctrl+C cancels it
+// gracefully; a second one terminates the process.
+func runCLI(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return cli.Run(ctx, args, os.Stdin, os.Stdout, os.Stderr)
+}